@@ -0,0 +1,31 @@
+package migu
+
+import (
+	"github.com/naoina/migu/dialect"
+)
+
+// AnalyzeSQLs returns the SQLs to update the statistics of the tables that are rebuilt or get the new indexes by
+// the changes, so that the optimizer can use the fresh statistics immediately after the migration.
+// It returns nil if the dialect does not implement dialect.TableAnalyzer.
+func AnalyzeSQLs(d dialect.Dialect, changes []*Change) []string {
+	a, ok := d.(dialect.TableAnalyzer)
+	if !ok {
+		return nil
+	}
+	dropped := map[string]bool{}
+	for _, c := range changes {
+		if c.Kind == DropTable {
+			dropped[c.Table] = true
+		}
+	}
+	var sqls []string
+	seen := map[string]bool{}
+	for _, c := range changes {
+		if !c.IsDataAffecting() || dropped[c.Table] || seen[c.Table] {
+			continue
+		}
+		seen[c.Table] = true
+		sqls = append(sqls, a.AnalyzeTableSQL(c.Table)...)
+	}
+	return sqls
+}
@@ -7,11 +7,19 @@ import (
 	"go/ast"
 	"strconv"
 	"strings"
+
+	"github.com/naoina/migu/dialect"
 )
 
 type annotation struct {
-	Table  string
-	Option string
+	Table       string
+	Option      string
+	Encryption  string
+	Ownership   string
+	Persistence string
+	Compression string
+	Checks      []dialect.CheckConstraint
+	Tags        []string
 }
 
 func parseAnnotation(g *ast.CommentGroup) (*annotation, error) {
@@ -38,21 +46,80 @@ func parseAnnotation(g *ast.CommentGroup) (*annotation, error) {
 			case "table":
 				s, err := parseString(v)
 				if err != nil {
-					return nil, fmt.Errorf("migu: BUG: %v", err)
+					return nil, fmt.Errorf("migu: BUG: %w", err)
 				}
 				a.Table = s
 			case "option":
 				s, err := parseString(v)
 				if err != nil {
-					return nil, fmt.Errorf("migu: BUG: %v", err)
+					return nil, fmt.Errorf("migu: BUG: %w", err)
 				}
 				a.Option = s
+			case "encryption":
+				s, err := parseString(v)
+				if err != nil {
+					return nil, fmt.Errorf("migu: BUG: %w", err)
+				}
+				switch s = strings.ToUpper(s); s {
+				case encryptionYes, encryptionNo:
+				default:
+					return nil, newError(ErrInvalidAnnotation, "migu: invalid encryption annotation: %q (must be \"Y\" or \"N\")", s)
+				}
+				a.Encryption = s
+			case "ownership":
+				s, err := parseString(v)
+				if err != nil {
+					return nil, fmt.Errorf("migu: BUG: %w", err)
+				}
+				switch s {
+				case ownershipFull, ownershipPartial:
+				default:
+					return nil, newError(ErrInvalidAnnotation, "migu: invalid ownership annotation: %q (must be \"full\" or \"partial\")", s)
+				}
+				a.Ownership = s
+			case "persistence":
+				s, err := parseString(v)
+				if err != nil {
+					return nil, fmt.Errorf("migu: BUG: %w", err)
+				}
+				switch s {
+				case dialect.PersistenceTemporary, dialect.PersistenceUnlogged:
+				default:
+					return nil, newError(ErrInvalidAnnotation, "migu: invalid persistence annotation: %q (must be \"temporary\" or \"unlogged\")", s)
+				}
+				a.Persistence = s
+			case "compression":
+				s, err := parseString(v)
+				if err != nil {
+					return nil, fmt.Errorf("migu: BUG: %w", err)
+				}
+				a.Compression = strings.ToLower(s)
+			case "check":
+				s, err := parseString(v)
+				if err != nil {
+					return nil, fmt.Errorf("migu: BUG: %w", err)
+				}
+				c, err := parseCheckAnnotation(s)
+				if err != nil {
+					return nil, err
+				}
+				a.Checks = append(a.Checks, c)
+			case "tags":
+				s, err := parseString(v)
+				if err != nil {
+					return nil, fmt.Errorf("migu: BUG: %w", err)
+				}
+				tags, err := parseTagsAnnotation(s)
+				if err != nil {
+					return nil, err
+				}
+				a.Tags = append(a.Tags, tags...)
 			default:
-				return nil, fmt.Errorf("migu: unsupported annotation: %v", k)
+				return nil, newError(ErrInvalidAnnotation, "migu: unsupported annotation: %v", k)
 			}
 		}
 		if err := scanner.Err(); err != nil {
-			return nil, fmt.Errorf("%v: %v", err, c.Text)
+			return nil, newError(ErrInvalidAnnotation, "%w: %v", err, c.Text)
 		}
 		return &a, nil
 	}
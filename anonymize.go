@@ -0,0 +1,279 @@
+package migu
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/naoina/migu/dialect"
+)
+
+// Anonymizer is the method to anonymize the values of the column.
+type Anonymizer string
+
+const (
+	// AnonymizeFake replaces the value with the sample data in the same way as FakeDataSQL.
+	AnonymizeFake Anonymizer = "fake"
+
+	// AnonymizeHash replaces the value with the hex-encoded HMAC-SHA256 of it with the key. The same value is replaced
+	// with the same hash, so that the values can still be joined, while the values cannot be guessed from the hashes
+	// without the key.
+	AnonymizeHash Anonymizer = "hash"
+
+	// AnonymizeMask replaces the characters of the value with "*" except the last quarter of the characters, up to 4
+	// characters. At least one character is masked.
+	AnonymizeMask Anonymizer = "mask"
+
+	// AnonymizeNull replaces the value with NULL.
+	AnonymizeNull Anonymizer = "null"
+
+	// AnonymizeKeep keeps the value as it is.
+	AnonymizeKeep Anonymizer = "keep"
+)
+
+// defaultPIIClass is the class that is anonymized by AnonymizeFake if no anonymizer is specified for it.
+const defaultPIIClass = "pii"
+
+// ParseAnonymizer parses s in the form of "KEY=ANONYMIZER". KEY is a class or "TABLE.COLUMN".
+func ParseAnonymizer(s string) (key string, anonymizer Anonymizer, err error) {
+	kv := strings.SplitN(s, "=", 2)
+	if len(kv) != 2 || kv[0] == "" {
+		return "", "", fmt.Errorf("migu: invalid anonymizer %q: must be KEY=ANONYMIZER", s)
+	}
+	switch a := Anonymizer(kv[1]); a {
+	case AnonymizeFake, AnonymizeHash, AnonymizeMask, AnonymizeNull, AnonymizeKeep:
+		return kv[0], a, nil
+	}
+	return "", "", fmt.Errorf("migu: unknown anonymizer: %s", kv[1])
+}
+
+// ExportAnonymized reads the rows of the tables that are declared by Go's structs from the database, and writes the
+// INSERT statements of them to output with anonymizing the values of the classified columns.
+// The keys of anonymizers are the classes of `class` struct field tag or "TABLE.COLUMN", and the key of the column
+// has priority over the classes. The columns of "pii" class are anonymized by AnonymizeFake unless another
+// anonymizer is specified.
+// seed is used by AnonymizeFake, and key is used by AnonymizeHash. The dialect must implement dialect.RowReader.
+// WithTablePrefix prefixes the table names of Go's structs in the same way as Diff, and the TABLE of the keys is
+// without the prefix.
+// The filename and src parameters are treated in the same way as Diff.
+func ExportAnonymized(output io.Writer, d dialect.Dialect, filename string, src interface{}, anonymizers map[string]Anonymizer, seed int64, key []byte, opts ...Option) error {
+	w := bufio.NewWriter(output)
+	if err := anonymizedInserts(d, filename, src, anonymizers, seed, key, newOption(opts), func(sql string) error {
+		_, err := fmt.Fprintf(w, "%s;\n", sql)
+		return err
+	}); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// CopyAnonymized reads the rows of the tables in the same way as ExportAnonymized, and inserts them into the tables
+// of dst in a transaction. The tables must already exist in dst.
+func CopyAnonymized(dst, d dialect.Dialect, filename string, src interface{}, anonymizers map[string]Anonymizer, seed int64, key []byte, opts ...Option) error {
+	tx, err := dst.Begin()
+	if err != nil {
+		return err
+	}
+	if err := anonymizedInserts(d, filename, src, anonymizers, seed, key, newOption(opts), func(sql string) error {
+		return tx.Exec(sql)
+	}); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// anonymizedInserts calls fn with each INSERT statement of the anonymized rows of the tables.
+func anonymizedInserts(d dialect.Dialect, filename string, src interface{}, anonymizers map[string]Anonymizer, seed int64, key []byte, opt *option, fn func(sql string) error) error {
+	reader, ok := d.(dialect.RowReader)
+	if !ok {
+		return newError(ErrUnsupportedFeature, "migu: reading rows is not supported by the dialect")
+	}
+	structMap, err := structTables(d, filename, src)
+	if err != nil {
+		return err
+	}
+	structMap = opt.prefixTables(structMap)
+	names := make([]string, 0, len(structMap))
+	for name := range structMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	r := rand.New(rand.NewSource(seed))
+	for _, name := range names {
+		fields := structMap[name].Fields
+		columns := make([]string, len(fields))
+		quoted := make([]string, len(fields))
+		methods := make([]Anonymizer, len(fields))
+		for i, f := range fields {
+			columns[i] = f.Column
+			quoted[i] = d.Quote(f.Column)
+			methods[i] = anonymizerOf(f, anonymizers, opt.tablePrefix)
+			if methods[i] == AnonymizeNull && !f.Nullable {
+				return newError(ErrRefused, "migu: %s.%s: cannot anonymize NOT NULL column by %s", name, f.Column, AnonymizeNull)
+			}
+			if methods[i] == AnonymizeHash && len(key) == 0 {
+				return newError(ErrRefused, "migu: %s.%s: %s anonymizer requires the key", name, f.Column, AnonymizeHash)
+			}
+		}
+		head := fmt.Sprintf("INSERT INTO %s (%s) VALUES ", d.Quote(name), strings.Join(quoted, ", "))
+		var values []string
+		flush := func() error {
+			if len(values) == 0 {
+				return nil
+			}
+			sql := head + strings.Join(values, ", ")
+			values = values[:0]
+			return fn(sql)
+		}
+		n := 0
+		if err := reader.ReadRows(name, columns, func(row []*string) error {
+			literals := make([]string, len(row))
+			for i, v := range row {
+				literal, err := anonymizedLiteral(d, r, key, fields[i], methods[i], v, n)
+				if err != nil {
+					return fmt.Errorf("migu: %s.%s: %w", name, fields[i].Column, err)
+				}
+				literals[i] = literal
+			}
+			values = append(values, "("+strings.Join(literals, ", ")+")")
+			n++
+			if len(values) == fakeBatchSize {
+				return flush()
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func anonymizerOf(f *field, anonymizers map[string]Anonymizer, prefix string) Anonymizer {
+	if a, ok := anonymizers[strings.TrimPrefix(f.Table, prefix)+"."+f.Column]; ok {
+		return a
+	}
+	for _, class := range f.Classes {
+		if a, ok := anonymizers[class]; ok {
+			return a
+		}
+	}
+	for _, class := range f.Classes {
+		if class == defaultPIIClass {
+			return AnonymizeFake
+		}
+	}
+	return AnonymizeKeep
+}
+
+// anonymizedLiteral returns the SQL literal of the value v of the n-th row that is anonymized by anonymizer.
+func anonymizedLiteral(d dialect.Dialect, r *rand.Rand, key []byte, f *field, anonymizer Anonymizer, v *string, n int) (string, error) {
+	if v == nil {
+		return "NULL", nil
+	}
+	switch anonymizer {
+	case AnonymizeFake:
+		return fakeValue(d, r, f, n)
+	case AnonymizeHash, AnonymizeMask:
+		if !isStringType(f.Type) {
+			return "", fmt.Errorf("%s is supported only by the string columns", anonymizer)
+		}
+		s := *v
+		if anonymizer == AnonymizeHash {
+			mac := hmac.New(sha256.New, key)
+			mac.Write([]byte(s))
+			s = hex.EncodeToString(mac.Sum(nil))
+			if size := typeSize(f.Type); size > 0 && len(s) > size {
+				s = s[:size]
+			}
+		} else {
+			s = mask(s)
+		}
+		return d.QuoteString(s), nil
+	case AnonymizeNull:
+		return "NULL", nil
+	}
+	if isBinaryType(f.Type) {
+		return fmt.Sprintf("X'%X'", *v), nil
+	}
+	if isSpatialType(f.Type) {
+		return geometryLiteral(*v)
+	}
+	if isNumericType(f.Type) {
+		if _, err := strconv.ParseFloat(*v, 64); err == nil {
+			return *v, nil
+		}
+	}
+	return d.QuoteString(*v), nil
+}
+
+// maskKeepLength is the maximum number of the last characters that are kept by AnonymizeMask.
+const maskKeepLength = 4
+
+// mask replaces the characters of s with "*" except the last quarter of them, up to maskKeepLength characters.
+func mask(s string) string {
+	rs := []rune(s)
+	keep := len(rs) / 4
+	if keep > maskKeepLength {
+		keep = maskKeepLength
+	}
+	for i := 0; i < len(rs)-keep; i++ {
+		rs[i] = '*'
+	}
+	return string(rs)
+}
+
+func isNumericType(typ string) bool {
+	switch typeBase(typ) {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT", "INT64", "DECIMAL", "NUMERIC", "FLOAT", "DOUBLE", "REAL", "FLOAT64":
+		return true
+	}
+	return false
+}
+
+func isStringType(typ string) bool {
+	switch typeBase(typ) {
+	case "CHAR", "VARCHAR", "STRING", "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT":
+		return true
+	}
+	return false
+}
+
+func isBinaryType(typ string) bool {
+	switch typeBase(typ) {
+	case "BINARY", "VARBINARY", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB", "BYTES":
+		return true
+	}
+	return false
+}
+
+// typeBase returns the name of the column type without the parameters in upper case.
+func typeBase(typ string) string {
+	if i := strings.IndexAny(typ, "( "); i >= 0 {
+		typ = typ[:i]
+	}
+	return strings.ToUpper(typ)
+}
+
+// typeSize returns the size parameter of the column type such as VARCHAR(255), or 0 if not.
+func typeSize(typ string) int {
+	i := strings.IndexByte(typ, '(')
+	if i < 0 {
+		return 0
+	}
+	n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(typ[i+1:]), ")"))
+	if err != nil {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,372 @@
+package migu
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/naoina/migu/dialect"
+)
+
+// ExportAtlas writes the tables that are declared by Go's structs to output in Atlas HCL.
+// The tables belong to the schema named schema.
+// The filename and src parameters are treated in the same way as Diff.
+func ExportAtlas(output io.Writer, d dialect.Dialect, schema string, filename string, src interface{}) error {
+	structMap, err := structTables(d, filename, src)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(structMap))
+	for name := range structMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	w := bufio.NewWriter(output)
+	fmt.Fprintf(w, "schema %s {\n}\n", strconv.Quote(schema))
+	for _, name := range names {
+		tbl := structMap[name]
+		fmt.Fprintf(w, "\ntable %s {\n", strconv.Quote(name))
+		fmt.Fprintf(w, "  schema = %s\n", atlasRef("schema", schema))
+		for _, f := range tbl.Fields {
+			fmt.Fprintf(w, "  column %s {\n", strconv.Quote(f.Column))
+			for _, attr := range atlasColumnAttrs(f) {
+				fmt.Fprintf(w, "    %s = %s\n", attr[0], attr[1])
+			}
+			fmt.Fprintf(w, "  }\n")
+		}
+		if pks := tbl.ToTable(name).PrimaryKeys; len(pks) > 0 {
+			fmt.Fprintf(w, "  primary_key {\n    columns = %s\n  }\n", atlasColumnRefs(pks))
+		}
+		addIndexes, _ := makeIndexes(nil, tbl.Fields)
+		for _, index := range addIndexes {
+			fmt.Fprintf(w, "  index %s {\n", strconv.Quote(index.Name))
+			if index.Unique {
+				fmt.Fprintf(w, "    unique = true\n")
+			}
+			fmt.Fprintf(w, "    columns = %s\n  }\n", atlasColumnRefs(index.Columns))
+		}
+		fmt.Fprintf(w, "}\n")
+	}
+	return w.Flush()
+}
+
+func atlasColumnAttrs(f *field) [][2]string {
+	typ := strings.ToLower(f.Type)
+	unsigned := strings.HasSuffix(typ, " unsigned")
+	typ = strings.TrimSuffix(typ, " unsigned")
+	if strings.ContainsAny(typ, "'\" ") {
+		typ = atlasSQL(f.Type)
+		unsigned = false
+	}
+	attrs := [][2]string{
+		{"null", strconv.FormatBool(f.Nullable)},
+		{"type", typ},
+	}
+	if unsigned {
+		attrs = append(attrs, [2]string{"unsigned", "true"})
+	}
+	if f.Default != "" {
+		attrs = append(attrs, [2]string{"default", atlasDefault(f.Default)})
+	}
+	if f.AutoIncrement {
+		attrs = append(attrs, [2]string{"auto_increment", "true"})
+	}
+	if f.OnUpdate != "" {
+		attrs = append(attrs, [2]string{"on_update", atlasSQL(f.OnUpdate)})
+	}
+	if comment := commentWithClasses(f.Comment, f.Classes); comment != "" {
+		attrs = append(attrs, [2]string{"comment", strconv.Quote(comment)})
+	}
+	return attrs
+}
+
+func atlasDefault(def string) string {
+	if _, err := strconv.ParseFloat(def, 64); err == nil {
+		return def
+	}
+	if upper := strings.ToUpper(def); strings.HasPrefix(upper, "CURRENT_TIMESTAMP") || strings.ContainsRune(def, '(') {
+		return atlasSQL(def)
+	}
+	return strconv.Quote(def)
+}
+
+func atlasSQL(expr string) string {
+	return fmt.Sprintf("sql(%s)", strconv.Quote(expr))
+}
+
+func atlasColumnRefs(columns []string) string {
+	refs := make([]string, len(columns))
+	for i, c := range columns {
+		refs[i] = atlasRef("column", c)
+	}
+	return "[" + strings.Join(refs, ", ") + "]"
+}
+
+// hclIdentifierRE matches the identifiers of HCL.
+var hclIdentifierRE = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// atlasRef returns the reference to the object of the kind such as schema.app. The name that is not an identifier of
+// HCL is quoted as the index such as schema["my app"].
+func atlasRef(kind, name string) string {
+	if hclIdentifierRE.MatchString(name) {
+		return kind + "." + name
+	}
+	return kind + "[" + strconv.Quote(name) + "]"
+}
+
+// ImportAtlas generates Go's structs from the tables that are defined in Atlas HCL and writes to output.
+// It supports the subset of Atlas HCL for MySQL that has the table, column, primary_key and index blocks.
+// The other blocks and attributes are ignored.
+func ImportAtlas(output io.Writer, d dialect.Dialect, filename string, src interface{}) error {
+	b, err := readSource(filename, src)
+	if err != nil {
+		return err
+	}
+	tableMap, err := parseAtlas(b)
+	if err != nil {
+		return newError(ErrInvalidSource, "migu: %s: %w", filename, err)
+	}
+	return fprintTableMap(output, d, tableMap)
+}
+
+func parseAtlas(b []byte) (map[string][]dialect.ColumnSchema, error) {
+	tableMap := map[string][]dialect.ColumnSchema{}
+	var (
+		tableName string
+		columns   []*ddlColumnSchema
+		column    *ddlColumnSchema
+		index     *ddlIndex
+		unsigned  bool
+		blocks    []string
+	)
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || strings.HasPrefix(line, "//") {
+			continue
+		}
+		if line == "}" {
+			if len(blocks) == 0 {
+				return nil, fmt.Errorf("line %d: unexpected }", lineno)
+			}
+			switch blocks[len(blocks)-1] {
+			case "table":
+				if len(blocks) == 1 {
+					schemas, err := makeAtlasTable(tableName, columns)
+					if err != nil {
+						return nil, err
+					}
+					tableMap[tableName] = schemas
+					columns = nil
+				}
+			case "column":
+				if column != nil {
+					if err := column.setAtlasType(unsigned); err != nil {
+						return nil, fmt.Errorf("line %d: %v", lineno, err)
+					}
+					columns = append(columns, column)
+					column, unsigned = nil, false
+				}
+			case "primary_key", "index":
+				if index != nil {
+					if err := applyAtlasIndex(columns, index); err != nil {
+						return nil, fmt.Errorf("line %d: %s: %v", lineno, tableName, err)
+					}
+					index = nil
+				}
+			}
+			blocks = blocks[:len(blocks)-1]
+			continue
+		}
+		if strings.HasSuffix(line, "{") {
+			fields := strings.Fields(strings.TrimSpace(strings.TrimSuffix(line, "{")))
+			if len(fields) == 0 {
+				return nil, fmt.Errorf("line %d: invalid block: %s", lineno, line)
+			}
+			var label string
+			if len(fields) > 1 {
+				s, err := strconv.Unquote(fields[1])
+				if err != nil {
+					return nil, fmt.Errorf("line %d: invalid block label: %s", lineno, line)
+				}
+				label = s
+			}
+			blocks = append(blocks, fields[0])
+			switch path := strings.Join(blocks, "."); path {
+			case "table":
+				tableName = label
+			case "table.column":
+				column = &ddlColumnSchema{table: tableName, column: label}
+			case "table.primary_key":
+				index = &ddlIndex{primary: true, unique: true}
+			case "table.index":
+				index = &ddlIndex{name: label}
+			}
+			if strings.HasSuffix(line, "}") {
+				blocks = blocks[:len(blocks)-1]
+			}
+			continue
+		}
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("line %d: invalid attribute: %s", lineno, line)
+		}
+		key, value := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		switch path := strings.Join(blocks, "."); path {
+		case "table.column":
+			if err := column.setAtlasAttr(key, value, &unsigned); err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineno, err)
+			}
+		case "table.primary_key", "table.index":
+			switch key {
+			case "unique":
+				index.unique = value == "true"
+			case "columns":
+				index.columns = parseAtlasColumnRefs(value)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(blocks) > 0 {
+		return nil, fmt.Errorf("unterminated block: %s", blocks[len(blocks)-1])
+	}
+	return tableMap, nil
+}
+
+func (c *ddlColumnSchema) setAtlasAttr(key, value string, unsigned *bool) error {
+	switch key {
+	case "null":
+		c.nullable = value == "true"
+	case "type":
+		c.columnType = unquoteAtlasSQL(value)
+	case "unsigned":
+		*unsigned = value == "true"
+	case "auto_increment":
+		c.autoIncrement = value == "true"
+	case "default":
+		if s, err := strconv.Unquote(value); err == nil {
+			c.columnDefault = s
+		} else {
+			c.columnDefault = unquoteAtlasSQL(value)
+		}
+		c.hasDefault = true
+	case "on_update":
+		c.onUpdate = strings.ToUpper(unquoteAtlasSQL(value))
+	case "comment":
+		s, err := strconv.Unquote(value)
+		if err != nil {
+			return fmt.Errorf("invalid comment: %s", value)
+		}
+		c.comment = s
+	}
+	return nil
+}
+
+// setAtlasType normalizes the column type in the same way as CREATE TABLE statement.
+func (c *ddlColumnSchema) setAtlasType(unsigned bool) error {
+	typ := c.columnType
+	if unsigned {
+		typ += " unsigned"
+	}
+	tokens, err := tokenizeSQL("`" + c.column + "` " + typ)
+	if err != nil {
+		return err
+	}
+	parsed, err := parseColumnDefinition(c.table, tokens)
+	if err != nil {
+		return err
+	}
+	c.columnType, c.dataType = parsed.columnType, parsed.dataType
+	return nil
+}
+
+func unquoteAtlasSQL(value string) string {
+	if strings.HasPrefix(value, "sql(") && strings.HasSuffix(value, ")") {
+		if s, err := strconv.Unquote(value[len("sql(") : len(value)-1]); err == nil {
+			return s
+		}
+	}
+	return value
+}
+
+func parseAtlasColumnRefs(value string) []string {
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+	var columns []string
+	for {
+		if value = strings.TrimLeft(value, ", "); value == "" {
+			return columns
+		}
+		// The quoted name such as column["my column"] may have the commas.
+		if i := strings.IndexAny(value, "[,"); i >= 0 && value[i] == '[' {
+			name, n := unquoteAtlasIndex(value[i+1:])
+			columns = append(columns, name)
+			value = strings.TrimPrefix(value[i+1+n:], "]")
+			continue
+		}
+		ref := value
+		if i := strings.IndexByte(value, ','); i >= 0 {
+			ref, value = value[:i], value[i:]
+		} else {
+			value = ""
+		}
+		ref = strings.TrimSpace(ref)
+		columns = append(columns, ref[strings.LastIndexByte(ref, '.')+1:])
+	}
+}
+
+// unquoteAtlasIndex returns the quoted string at the beginning of s that is unquoted, and the length of it.
+func unquoteAtlasIndex(s string) (string, int) {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			if name, err := strconv.Unquote(s[:i+1]); err == nil {
+				return name, i + 1
+			}
+			return s[1:i], i + 1
+		}
+	}
+	return strings.TrimPrefix(s, `"`), len(s)
+}
+
+func applyAtlasIndex(columns []*ddlColumnSchema, index *ddlIndex) error {
+	for _, name := range index.columns {
+		var c *ddlColumnSchema
+		for _, column := range columns {
+			if column.column == name {
+				c = column
+				break
+			}
+		}
+		if c == nil {
+			return fmt.Errorf("unknown column %s in index", name)
+		}
+		if index.primary {
+			c.primaryKey = true
+			continue
+		}
+		if c.indexName == "" {
+			c.indexName, c.unique = index.name, index.unique
+		}
+	}
+	return nil
+}
+
+func makeAtlasTable(name string, columns []*ddlColumnSchema) ([]dialect.ColumnSchema, error) {
+	if name == "" {
+		return nil, fmt.Errorf("table has no name")
+	}
+	schemas := make([]dialect.ColumnSchema, len(columns))
+	for i, c := range columns {
+		schemas[i] = c
+	}
+	return schemas, nil
+}
@@ -0,0 +1,103 @@
+package migu
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/naoina/migu/dialect"
+)
+
+// Budget is the limits of the size of the schema that are checked against the tables declared by Go's structs during
+// planning, before the server rejects the DDL with obscure errors. The zero value of each limit means no limit.
+type Budget struct {
+	// MaxColumns is the maximum number of the columns per table.
+	MaxColumns int `yaml:"max_columns"`
+
+	// MaxIndexes is the maximum number of the indexes per table except the primary key.
+	MaxIndexes int `yaml:"max_indexes"`
+
+	// MaxRowSize is the maximum of the estimated maximum size of a row in bytes.
+	MaxRowSize int64 `yaml:"max_row_size"`
+}
+
+// BudgetViolation represents a table that exceeds a limit of Budget.
+type BudgetViolation struct {
+	Table string
+	Limit string
+	Value int64
+	Max   int64
+}
+
+func (v *BudgetViolation) String() string {
+	return fmt.Sprintf("%s: %s is %d, exceeds the budget of %d", v.Table, v.Limit, v.Value, v.Max)
+}
+
+// BudgetError is returned by the diff if any table exceeds the budget specified by WithBudget.
+type BudgetError struct {
+	Violations []*BudgetViolation
+}
+
+func (e *BudgetError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.String()
+	}
+	return "migu: schema budget exceeded: " + strings.Join(msgs, "; ")
+}
+
+// ErrorCode returns the code of BudgetError.
+func (e *BudgetError) ErrorCode() Code {
+	return "E111"
+}
+
+// WithBudget checks the tables declared by Go's structs against the budget.
+// If warn is nil, the diff returns a *BudgetError if any table exceeds the budget. Otherwise, warn is called with
+// each violation instead.
+func WithBudget(budget Budget, warn func(v *BudgetViolation)) Option {
+	return func(o *option) {
+		o.budget = &budget
+		o.budgetWarn = warn
+	}
+}
+
+func (b *Budget) check(d dialect.Dialect, tableMap map[string]*table) []*BudgetViolation {
+	names := make([]string, 0, len(tableMap))
+	for name := range tableMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var violations []*BudgetViolation
+	for _, name := range names {
+		tbl := tableMap[name]
+		if n := int64(len(tbl.Fields)); b.MaxColumns > 0 && n > int64(b.MaxColumns) {
+			violations = append(violations, &BudgetViolation{Table: name, Limit: "number of columns", Value: n, Max: int64(b.MaxColumns)})
+		}
+		addIndexes, _ := makeIndexes(nil, tbl.Fields)
+		if n := int64(len(addIndexes)); b.MaxIndexes > 0 && n > int64(b.MaxIndexes) {
+			violations = append(violations, &BudgetViolation{Table: name, Limit: "number of indexes", Value: n, Max: int64(b.MaxIndexes)})
+		}
+		if size := estimateRowSize(tbl, charWidth(d, name, tbl)); b.MaxRowSize > 0 && size > b.MaxRowSize {
+			violations = append(violations, &BudgetViolation{Table: name, Limit: "estimated row size", Value: size, Max: b.MaxRowSize})
+		}
+	}
+	return violations
+}
+
+// checkBudget checks the tables against the budget of WithBudget if it is specified.
+func checkBudget(d dialect.Dialect, tableMap map[string]*table, opt *option) error {
+	if opt.budget == nil {
+		return nil
+	}
+	violations := opt.budget.check(d, tableMap)
+	if len(violations) == 0 {
+		return nil
+	}
+	if opt.budgetWarn != nil {
+		for _, v := range violations {
+			opt.budgetWarn(v)
+		}
+		return nil
+	}
+	return &BudgetError{Violations: violations}
+}
@@ -0,0 +1,91 @@
+package migu
+
+import (
+	"github.com/naoina/migu/dialect"
+)
+
+// ChangeKind represents the kind of the schema change.
+type ChangeKind string
+
+const (
+	CreateTable       ChangeKind = "create_table"
+	DropTable         ChangeKind = "drop_table"
+	RenameTable       ChangeKind = "rename_table"
+	AddColumn         ChangeKind = "add_column"
+	DropColumn        ChangeKind = "drop_column"
+	RenameColumn      ChangeKind = "rename_column"
+	ModifyColumn      ChangeKind = "modify_column"
+	ModifyPrimaryKey  ChangeKind = "modify_primary_key"
+	ModifyEncryption  ChangeKind = "modify_encryption"
+	ModifyCompression ChangeKind = "modify_compression"
+	ModifyUser        ChangeKind = "modify_user"
+	ModifyDatabase    ChangeKind = "modify_database"
+	ConvertCharset    ChangeKind = "convert_charset"
+	CreateIndex       ChangeKind = "create_index"
+	DropIndex         ChangeKind = "drop_index"
+	AddForeignKey     ChangeKind = "add_foreign_key"
+	DropForeignKey    ChangeKind = "drop_foreign_key"
+	AddCheck          ChangeKind = "add_check"
+	DropCheck         ChangeKind = "drop_check"
+	ApplyMigration    ChangeKind = "apply_migration"
+	RollbackMigration ChangeKind = "rollback_migration"
+	FreezeSchema      ChangeKind = "freeze_schema"
+	UnfreezeSchema    ChangeKind = "unfreeze_schema"
+
+	// Statement is the statement of the SQL script that is read by ReadPlan. The kind of the change is unknown.
+	Statement ChangeKind = "statement"
+)
+
+// Change represents a schema change of the table and SQLs to apply it.
+type Change struct {
+	Kind  ChangeKind
+	Table string
+
+	// NewName is the new table name if Kind is RenameTable, or the new column name if Kind is RenameColumn.
+	NewName string
+
+	// Column is the column name if Kind is a change of the column.
+	Column string
+
+	// OldField and NewField are the definitions of the column before and after the change.
+	// They are set only if Kind is a change of the column, and OldField is nil for AddColumn and NewField is nil for
+	// DropColumn.
+	OldField *dialect.Field
+	NewField *dialect.Field
+
+	// Index is the index name if Kind is a change of the index.
+	Index string
+
+	// Unique reports whether the index is unique if Kind is a change of the index.
+	Unique bool
+
+	// IndexColumns are the column names of the index if Kind is CreateIndex.
+	IndexColumns []string
+
+	// Constraint is the constraint name if Kind is a change of the constraint such as AddForeignKey.
+	Constraint string
+
+	// ForeignKey is the foreign key if Kind is AddForeignKey or DropForeignKey.
+	ForeignKey *dialect.ForeignKey
+
+	// User is the user name or the role name if Kind is ModifyUser.
+	User string
+
+	// Migration is the version and the name of the migration such as 0001_add_user_age if Kind is ApplyMigration or
+	// RollbackMigration.
+	Migration string
+
+	SQLs []string
+
+	// Charset is the character set to convert the table to if Kind is ConvertCharset.
+	Charset string
+
+	// Encrypted reports whether the table will be encrypted at rest if Kind is ModifyEncryption.
+	Encrypted bool
+
+	// Compression is the compression algorithm of the table after the change if Kind is ModifyCompression.
+	Compression string
+
+	// EstimatedRows is the estimated number of rows to be scanned by the change. It is set by Estimate.
+	EstimatedRows int64
+}
@@ -0,0 +1,286 @@
+package migu
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// liquibaseChange is the change of a changeSet of Liquibase changelog. Only the changes of the raw SQLs are supported
+// since the other changes such as createTable are generated by Liquibase itself.
+type liquibaseChange struct {
+	Kind string
+
+	// SQL is the SQL of sql change, or the path of the file of sqlFile change.
+	SQL string
+
+	// Split reports whether the SQL is split into the statements by semicolons.
+	Split bool
+
+	// RelativeToChangelog reports whether the relative path of sqlFile change is relative to the changelog. It is
+	// relative to the current directory if false as the search path of Liquibase.
+	RelativeToChangelog bool
+}
+
+type liquibaseChangeSet struct {
+	ID       string
+	Changes  []liquibaseChange
+	Rollback []liquibaseChange
+}
+
+// readLiquibaseChangelog reads the statements of the changelog of Liquibase in XML, or in YAML if isYAML is true.
+// The down statements are the rollbacks of the changeSets in reverse order.
+func readLiquibaseChangelog(filename string, b []byte, isYAML bool) (up, down []string, err error) {
+	var changeSets []liquibaseChangeSet
+	if isYAML {
+		changeSets, err = parseLiquibaseYAML(b)
+	} else {
+		changeSets, err = parseLiquibaseXML(b)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	read := func(changes []liquibaseChange) ([]string, error) {
+		var stmts []string
+		for _, c := range changes {
+			s := c.SQL
+			switch c.Kind {
+			case "sql":
+			case "sqlFile":
+				path := c.SQL
+				if c.RelativeToChangelog && !filepath.IsAbs(path) {
+					path = filepath.Join(filepath.Dir(filename), path)
+				}
+				b, err := ioutil.ReadFile(path)
+				if err != nil {
+					return nil, err
+				}
+				s = string(b)
+			default:
+				return nil, fmt.Errorf("unsupported change type: %s", c.Kind)
+			}
+			if !c.Split {
+				if s = strings.TrimSpace(s); s != "" {
+					stmts = append(stmts, strings.TrimSpace(strings.TrimSuffix(s, ";")))
+				}
+				continue
+			}
+			ss, err := splitSQLScript(s)
+			if err != nil {
+				return nil, err
+			}
+			stmts = append(stmts, ss...)
+		}
+		return stmts, nil
+	}
+	for _, cs := range changeSets {
+		stmts, err := read(cs.Changes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("changeSet %s: %w", cs.ID, err)
+		}
+		up = append(up, stmts...)
+	}
+	for i := len(changeSets) - 1; i >= 0; i-- {
+		stmts, err := read(changeSets[i].Rollback)
+		if err != nil {
+			return nil, nil, fmt.Errorf("changeSet %s: rollback: %w", changeSets[i].ID, err)
+		}
+		down = append(down, stmts...)
+	}
+	return up, down, nil
+}
+
+// xmlNode is the element of XML whose children are not known in advance.
+type xmlNode struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Content string     `xml:",chardata"`
+	Nodes   []xmlNode  `xml:",any"`
+}
+
+func (n *xmlNode) attr(name string) string {
+	for _, a := range n.Attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func parseLiquibaseXML(b []byte) ([]liquibaseChangeSet, error) {
+	var root xmlNode
+	if err := xml.NewDecoder(bytes.NewReader(b)).Decode(&root); err != nil {
+		return nil, err
+	}
+	if root.XMLName.Local != "databaseChangeLog" {
+		return nil, fmt.Errorf("root element must be databaseChangeLog, got %s", root.XMLName.Local)
+	}
+	var changeSets []liquibaseChangeSet
+	for _, node := range root.Nodes {
+		if node.XMLName.Local != "changeSet" {
+			return nil, fmt.Errorf("unsupported element: %s", node.XMLName.Local)
+		}
+		cs := liquibaseChangeSet{ID: node.attr("id")}
+		if cs.ID == "" {
+			return nil, fmt.Errorf("changeSet has no id")
+		}
+		for _, child := range node.Nodes {
+			switch child.XMLName.Local {
+			case "comment", "validCheckSum":
+			case "rollback":
+				if len(child.Nodes) == 0 {
+					cs.Rollback = append(cs.Rollback, liquibaseChange{Kind: "sql", SQL: child.Content, Split: true})
+				}
+				for _, c := range child.Nodes {
+					change, err := xmlLiquibaseChange(&c)
+					if err != nil {
+						return nil, fmt.Errorf("changeSet %s: rollback: %w", cs.ID, err)
+					}
+					cs.Rollback = append(cs.Rollback, change)
+				}
+			default:
+				change, err := xmlLiquibaseChange(&child)
+				if err != nil {
+					return nil, fmt.Errorf("changeSet %s: %w", cs.ID, err)
+				}
+				cs.Changes = append(cs.Changes, change)
+			}
+		}
+		changeSets = append(changeSets, cs)
+	}
+	return changeSets, nil
+}
+
+func xmlLiquibaseChange(n *xmlNode) (liquibaseChange, error) {
+	c := liquibaseChange{Kind: n.XMLName.Local, Split: true, RelativeToChangelog: true}
+	switch c.Kind {
+	case "sql":
+		c.SQL = n.Content
+	case "sqlFile":
+		if c.SQL = n.attr("path"); c.SQL == "" {
+			return c, fmt.Errorf("sqlFile: path is required")
+		}
+	default:
+		return c, fmt.Errorf("unsupported change type: %s", c.Kind)
+	}
+	for _, attr := range []struct {
+		name  string
+		value *bool
+	}{
+		{"splitStatements", &c.Split},
+		{"relativeToChangelogFile", &c.RelativeToChangelog},
+	} {
+		if s := n.attr(attr.name); s != "" {
+			b, err := strconv.ParseBool(s)
+			if err != nil {
+				return c, fmt.Errorf("%s: invalid %s: %s", c.Kind, attr.name, s)
+			}
+			*attr.value = b
+		}
+	}
+	return c, nil
+}
+
+func parseLiquibaseYAML(b []byte) ([]liquibaseChangeSet, error) {
+	var root struct {
+		DatabaseChangeLog []map[string]interface{} `yaml:"databaseChangeLog"`
+	}
+	if err := yaml.Unmarshal(b, &root); err != nil {
+		return nil, err
+	}
+	var changeSets []liquibaseChangeSet
+	for _, entry := range root.DatabaseChangeLog {
+		for key, value := range entry {
+			if key != "changeSet" {
+				return nil, fmt.Errorf("unsupported element: %s", key)
+			}
+			m, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("invalid changeSet: %v", value)
+			}
+			if m["id"] == nil {
+				return nil, fmt.Errorf("changeSet has no id")
+			}
+			cs := liquibaseChangeSet{ID: fmt.Sprint(m["id"])}
+			changes, err := yamlLiquibaseChanges(m["changes"])
+			if err != nil {
+				return nil, fmt.Errorf("changeSet %s: %w", cs.ID, err)
+			}
+			cs.Changes = changes
+			switch rollback := m["rollback"].(type) {
+			case nil:
+			case string:
+				cs.Rollback = []liquibaseChange{{Kind: "sql", SQL: rollback, Split: true}}
+			default:
+				changes, err := yamlLiquibaseChanges(rollback)
+				if err != nil {
+					return nil, fmt.Errorf("changeSet %s: rollback: %w", cs.ID, err)
+				}
+				cs.Rollback = changes
+			}
+			changeSets = append(changeSets, cs)
+		}
+	}
+	return changeSets, nil
+}
+
+// yamlLiquibaseChanges returns the changes of the list such as [{sql: {sql: ...}}], or of the single change.
+func yamlLiquibaseChanges(v interface{}) ([]liquibaseChange, error) {
+	var entries []interface{}
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		entries = v
+	default:
+		entries = []interface{}{v}
+	}
+	var changes []liquibaseChange
+	for _, entry := range entries {
+		m, ok := entry.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid change: %v", entry)
+		}
+		for kind, value := range m {
+			c := liquibaseChange{Kind: kind, Split: true, RelativeToChangelog: true}
+			attrs, _ := value.(map[string]interface{})
+			var name string
+			switch kind {
+			case "sql":
+				name = "sql"
+			case "sqlFile":
+				name = "path"
+			default:
+				return nil, fmt.Errorf("unsupported change type: %s", kind)
+			}
+			s, ok := attrs[name].(string)
+			if !ok || s == "" {
+				return nil, fmt.Errorf("%s: %s is required", kind, name)
+			}
+			c.SQL = s
+			for _, attr := range []struct {
+				name  string
+				value *bool
+			}{
+				{"splitStatements", &c.Split},
+				{"relativeToChangelogFile", &c.RelativeToChangelog},
+			} {
+				if v, ok := attrs[attr.name]; ok {
+					b, err := strconv.ParseBool(fmt.Sprint(v))
+					if err != nil {
+						return nil, fmt.Errorf("%s: invalid %s: %v", kind, attr.name, v)
+					}
+					*attr.value = b
+				}
+			}
+			changes = append(changes, c)
+		}
+	}
+	return changes, nil
+}
@@ -0,0 +1,205 @@
+package migu
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/naoina/migu/dialect"
+)
+
+// ConvertCharsetChanges returns the changes to convert the tables and their columns to the character set, in order
+// of the table names. The tables that have already been converted are skipped.
+// If tables are not given, all tables in the database are converted.
+// With WithTablePrefix, tables are the names without the prefix, and only the tables whose names begin with the
+// prefix are converted.
+// It returns an error if any index would exceed the maximum key size after the conversion, with the lengths of the
+// columns to shorten them to. (e.g. VARCHAR(191) for utf8mb4)
+// The dialect must implement dialect.CharsetConverter.
+func ConvertCharsetChanges(d dialect.Dialect, charset, collation string, tables []string, opts ...Option) ([]*Change, error) {
+	c, ok := d.(dialect.CharsetConverter)
+	if !ok {
+		return nil, newError(ErrUnsupportedFeature, "migu: converting the character set is not supported by the dialect")
+	}
+	for _, s := range []string{charset, collation} {
+		if !isCharsetName(s) {
+			return nil, newError(ErrInvalidIdentifier, "migu: invalid character set or collation: %q", s)
+		}
+	}
+	width := c.CharWidth(charset)
+	if width == 0 {
+		return nil, fmt.Errorf("migu: unknown character set: %s", charset)
+	}
+	opt := newOption(opts)
+	names := make([]string, len(tables))
+	for i, name := range tables {
+		names[i] = opt.tablePrefix + name
+	}
+	charsets, err := c.TableCharsets(names...)
+	if err != nil {
+		return nil, err
+	}
+	var targets []dialect.TableCharset
+	for _, tc := range charsets {
+		if !opt.inScope(tc.Table) {
+			continue
+		}
+		if len(unconvertedColumns(tc, charset)) > 0 || !strings.EqualFold(tc.Charset, charset) {
+			targets = append(targets, tc)
+		}
+	}
+	if len(targets) == 0 {
+		return nil, nil
+	}
+	names = make([]string, len(targets))
+	for i, tc := range targets {
+		names[i] = tc.Table
+	}
+	if err := checkIndexKeySizes(d, c, targets, names, width); err != nil {
+		return nil, err
+	}
+	changes := make([]*Change, len(targets))
+	for i, tc := range targets {
+		changes[i] = &Change{
+			Kind:    ConvertCharset,
+			Table:   tc.Table,
+			Charset: charset,
+			SQLs:    c.ConvertCharsetSQL(tc.Table, charset, collation),
+		}
+	}
+	return finalizeChanges(d, changes), nil
+}
+
+// isCharsetName reports whether s consists of the characters that can be used in the names of the character sets
+// and the collations, so that s can be embedded in the SQLs without quoting. The empty string is regarded as valid.
+func isCharsetName(s string) bool {
+	for _, c := range s {
+		if !(c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')) {
+			return false
+		}
+	}
+	return true
+}
+
+// VerifyCharset returns the descriptions of the tables and the columns that are not in the character set.
+// The dialect must implement dialect.CharsetConverter.
+func VerifyCharset(d dialect.Dialect, charset string, tables ...string) ([]string, error) {
+	c, ok := d.(dialect.CharsetConverter)
+	if !ok {
+		return nil, newError(ErrUnsupportedFeature, "migu: converting the character set is not supported by the dialect")
+	}
+	charsets, err := c.TableCharsets(tables...)
+	if err != nil {
+		return nil, err
+	}
+	var remains []string
+	for _, tc := range charsets {
+		if !strings.EqualFold(tc.Charset, charset) {
+			remains = append(remains, fmt.Sprintf("%s is %s", tc.Table, tc.Charset))
+		}
+		for _, column := range unconvertedColumns(tc, charset) {
+			remains = append(remains, fmt.Sprintf("%s.%s is %s", tc.Table, column, tc.Columns[column]))
+		}
+	}
+	return remains, nil
+}
+
+// unconvertedColumns returns the names of the columns that are not in the character set.
+func unconvertedColumns(tc dialect.TableCharset, charset string) []string {
+	var columns []string
+	for column, cs := range tc.Columns {
+		if !strings.EqualFold(cs, charset) {
+			columns = append(columns, column)
+		}
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// checkIndexKeySizes returns an error if any index of the tables would exceed the maximum key size after converting
+// to the character set of width bytes per character.
+func checkIndexKeySizes(d dialect.Dialect, c dialect.CharsetConverter, targets []dialect.TableCharset, names []string, width int) error {
+	r, ok := d.(dialect.IndexReader)
+	if !ok {
+		return nil
+	}
+	indexes, err := r.Indexes(names...)
+	if err != nil {
+		return err
+	}
+	schemas, err := d.ColumnSchema(names...)
+	if err != nil {
+		return err
+	}
+	types := map[string]string{}
+	for _, s := range schemas {
+		types[s.TableName()+"."+s.ColumnName()] = s.ColumnType()
+	}
+	rowFormats := map[string]string{}
+	for _, tc := range targets {
+		rowFormats[tc.Table] = tc.RowFormat
+	}
+	var problems []string
+	for _, index := range indexes {
+		columnTypes := make([]string, len(index.Columns))
+		widths := make([]int, len(index.Columns))
+		for i, column := range index.Columns {
+			columnTypes[i] = types[index.Table+"."+column]
+			widths[i] = width
+		}
+		if p := indexKeyProblem(index, columnTypes, widths, c.MaxIndexKeySize(rowFormats[index.Table])); p != "" {
+			problems = append(problems, p)
+		}
+	}
+	if len(problems) > 0 {
+		return newError(ErrLimitExceeded, "migu: the indexes would exceed the maximum key size after the conversion:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// indexKeyProblem returns the description of the problem if the key of the index exceeds maxKeySize, or an empty
+// string if not. widths are the maximum numbers of bytes of a character of the columns. The description has the
+// length of the string columns to fit in maxKeySize.
+func indexKeyProblem(index dialect.Index, columnTypes []string, widths []int, maxKeySize int64) string {
+	var size, stringSize int64
+	var strs []string
+	var strWidth int
+	for i, typ := range columnTypes {
+		n := indexKeyColumnSize(typ, widths[i])
+		size += n
+		switch typeBase(typ) {
+		case "CHAR", "VARCHAR":
+			stringSize += n
+			strs = append(strs, index.Columns[i])
+			strWidth += widths[i]
+		}
+	}
+	if size <= maxKeySize {
+		return ""
+	}
+	msg := fmt.Sprintf("%s.%s (%s) needs %d bytes, exceeds %d bytes", index.Table, index.Name, strings.Join(index.Columns, ", "), size, maxKeySize)
+	if len(strs) > 0 {
+		if room := maxKeySize - (size - stringSize); room >= int64(strWidth) {
+			msg += fmt.Sprintf("; shorten %s to VARCHAR(%d)", strings.Join(strs, ", "), room/int64(strWidth))
+		}
+	}
+	return msg
+}
+
+// indexKeyColumnSize returns the size in bytes of the column in the key of an index.
+func indexKeyColumnSize(typ string, width int) int64 {
+	_, n, _ := parseColumnType(strings.ToUpper(typ))
+	switch typeBase(typ) {
+	case "CHAR", "VARCHAR":
+		if n < 0 {
+			n = 1
+		}
+		return int64(n * width)
+	case "BINARY", "VARBINARY":
+		if n < 0 {
+			n = 1
+		}
+		return int64(n)
+	}
+	return columnSize(typ, width)
+}
@@ -0,0 +1,189 @@
+package migu
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/naoina/go-stringutil"
+	"github.com/naoina/migu/dialect"
+)
+
+// parseCheckAnnotation parses the value of `check` annotation in the form of NAME:EXPRESSION.
+func parseCheckAnnotation(s string) (dialect.CheckConstraint, error) {
+	ss := strings.SplitN(s, ":", 2)
+	if len(ss) != 2 || strings.TrimSpace(ss[0]) == "" || strings.TrimSpace(ss[1]) == "" {
+		return dialect.CheckConstraint{}, newError(ErrInvalidAnnotation, "migu: invalid check annotation: %q (must be in the form of NAME:EXPRESSION)", s)
+	}
+	return dialect.CheckConstraint{
+		Name:       strings.TrimSpace(ss[0]),
+		Expression: strings.TrimSpace(ss[1]),
+	}, nil
+}
+
+// structChecks returns the CHECK constraints that are declared by `check` struct field tags of the fields and `check`
+// annotations of the table.
+func structChecks(name string, tbl *table) []dialect.CheckConstraint {
+	var checks []dialect.CheckConstraint
+	for _, f := range tbl.Fields {
+		if f.Check == "" {
+			continue
+		}
+		checks = append(checks, dialect.CheckConstraint{
+			Table:      name,
+			Name:       stringutil.ToSnakeCase(name) + "_" + f.Column + "_check",
+			Expression: f.Check,
+		})
+	}
+	for _, c := range tbl.TableChecks {
+		c.Table = name
+		checks = append(checks, c)
+	}
+	return checks
+}
+
+// validateChecks returns an error if the table has the CHECK constraints but the dialect does not support them.
+func validateChecks(d dialect.Dialect, name string, tbl *table) error {
+	if len(tbl.Checks) == 0 {
+		return nil
+	}
+	if _, ok := d.(dialect.CheckConstraintManager); !ok {
+		return newError(ErrUnsupportedFeature, "migu: %s: check constraints are not supported by the dialect", name)
+	}
+	return nil
+}
+
+// checkChanges returns the changes to drop the CHECK constraints of oldTbl that are not in newTbl, and to add the
+// CHECK constraints of newTbl that are not in oldTbl. The modified constraints are dropped and added again.
+// oldTbl is nil if the table is created.
+func checkChanges(d dialect.Dialect, name string, oldTbl, newTbl *table) (drops, adds []*Change) {
+	m, ok := d.(dialect.CheckConstraintManager)
+	if !ok {
+		return nil, nil
+	}
+	oldMap := map[string]dialect.CheckConstraint{}
+	if oldTbl != nil {
+		for _, c := range oldTbl.Checks {
+			oldMap[c.Name] = c
+		}
+	}
+	newMap := make(map[string]dialect.CheckConstraint, len(newTbl.Checks))
+	for _, c := range newTbl.Checks {
+		newMap[c.Name] = c
+	}
+	for _, c := range sortedChecks(oldMap) {
+		newCheck, ok := newMap[c.Name]
+		if ok && equalCheck(c, newCheck) {
+			continue
+		}
+		// The constraints of the partially owned table may be added by the other processes.
+		if !ok && newTbl.Partial {
+			continue
+		}
+		drops = append(drops, &Change{
+			Kind:       DropCheck,
+			Table:      name,
+			Constraint: c.Name,
+			SQLs:       m.DropCheckConstraintSQL(c),
+		})
+	}
+	for _, c := range sortedChecks(newMap) {
+		if oldCheck, ok := oldMap[c.Name]; ok && equalCheck(oldCheck, c) {
+			continue
+		}
+		adds = append(adds, &Change{
+			Kind:       AddCheck,
+			Table:      name,
+			Constraint: c.Name,
+			SQLs:       m.AddCheckConstraintSQL(c),
+		})
+	}
+	return drops, adds
+}
+
+func sortedChecks(m map[string]dialect.CheckConstraint) []dialect.CheckConstraint {
+	checks := make([]dialect.CheckConstraint, 0, len(m))
+	for _, c := range m {
+		checks = append(checks, c)
+	}
+	sort.Slice(checks, func(i, j int) bool {
+		return checks[i].Name < checks[j].Name
+	})
+	return checks
+}
+
+// equalCheck reports whether the expressions of the constraints are the same except for the differences that the
+// databases make when they store them. See normalizeCheckExpression.
+func equalCheck(a, b dialect.CheckConstraint) bool {
+	return normalizeCheckExpression(a.Expression) == normalizeCheckExpression(b.Expression)
+}
+
+// normalizeCheckExpression returns the tokens of the expression separated by a space, without the differences that
+// the databases make when they store the expression: the case of the keywords and the identifiers, the whitespaces,
+// the parentheses, the quotes of the identifiers, the character set introducers of the strings such as _utf8mb4 and
+// != that is stored as <>. The contents of the string literals are kept as they are, and the quotes of them that are
+// escaped by the backslashes in information_schema of MySQL are unescaped.
+func normalizeCheckExpression(expr string) string {
+	var tokens []string
+	for i := 0; i < len(expr); {
+		switch c := expr[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '(' || c == ')':
+			i++
+		case c == '\'' || (c == '\\' && i+1 < len(expr) && expr[i+1] == '\''):
+			quote := expr[i : i+1]
+			if c == '\\' {
+				quote = expr[i : i+2]
+			}
+			j := i + len(quote)
+			for j < len(expr) && !strings.HasPrefix(expr[j:], quote) {
+				if expr[j] == '\\' && quote == "'" {
+					j++
+				}
+				j++
+			}
+			if j > len(expr) {
+				j = len(expr)
+			}
+			tokens = append(tokens, "'"+expr[i+len(quote):j]+"'")
+			i = j + len(quote)
+		case c == '`' || c == '"':
+			j := strings.IndexByte(expr[i+1:], c)
+			if j < 0 {
+				j = len(expr) - i - 1
+			}
+			tokens = append(tokens, strings.ToLower(expr[i+1:i+1+j]))
+			i += j + 2
+		case strings.IndexByte("<>=!", c) >= 0:
+			j := i + 1
+			for j < len(expr) && strings.IndexByte("<>=!", expr[j]) >= 0 {
+				j++
+			}
+			op := expr[i:j]
+			if op == "!=" {
+				op = "<>"
+			}
+			tokens = append(tokens, op)
+			i = j
+		case isCheckWordChar(c):
+			j := i + 1
+			for j < len(expr) && isCheckWordChar(expr[j]) {
+				j++
+			}
+			word := strings.ToLower(expr[i:j])
+			// The character set introducer such as _utf8mb4'a' is added to the string by MySQL.
+			if word[0] == '_' && (strings.HasPrefix(expr[j:], "'") || strings.HasPrefix(expr[j:], "\\'")) {
+				i = j
+				continue
+			}
+			tokens = append(tokens, word)
+			i = j
+		default:
+			tokens = append(tokens, expr[i:i+1])
+			i++
+		}
+	}
+	return strings.Join(tokens, " ")
+}
+
+func isCheckWordChar(c byte) bool {
+	return c == '_' || c == '$' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') || c >= 0x80
+}
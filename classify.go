@@ -0,0 +1,123 @@
+package migu
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/naoina/migu/dialect"
+)
+
+// classCommentPrefix is the marker of the data classifications in the column comment.
+// The classifications are stored at the end of the comment like "user email migu:class=pii,contact".
+const classCommentPrefix = "migu:class="
+
+// ClassificationHeader is the header row of the classification report written by WriteClassifications.
+var ClassificationHeader = []string{"table", "column", "classes"}
+
+// Classification represents the data classifications of a column, which are specified by `class` struct field tag.
+type Classification struct {
+	Table   string
+	Column  string
+	Classes []string
+}
+
+// Classifications returns the classified columns from Go's structs.
+// The filename and src parameters are treated in the same way as Diff.
+func Classifications(d dialect.Dialect, filename string, src interface{}) ([]Classification, error) {
+	structMap, err := structTables(d, filename, src)
+	if err != nil {
+		return nil, err
+	}
+	return makeClassifications(structMap), nil
+}
+
+// DatabaseClassifications returns the classified columns from the column comments of the database.
+func DatabaseClassifications(d dialect.Dialect) ([]Classification, error) {
+	tableMap, err := databaseTables(d)
+	if err != nil {
+		return nil, err
+	}
+	return makeClassifications(tableMap), nil
+}
+
+// WriteClassifications writes the classifications to output as CSV with the header.
+// If comma is not 0, it is used as the field delimiter. (e.g. '\t' for TSV)
+func WriteClassifications(output io.Writer, classifications []Classification, comma rune) error {
+	w := csv.NewWriter(output)
+	if comma != 0 {
+		w.Comma = comma
+	}
+	if err := w.Write(ClassificationHeader); err != nil {
+		return err
+	}
+	for _, c := range classifications {
+		if err := w.Write([]string{c.Table, c.Column, strings.Join(c.Classes, " ")}); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func makeClassifications(tableMap map[string]*table) []Classification {
+	names := make([]string, 0, len(tableMap))
+	for name := range tableMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var classifications []Classification
+	for _, name := range names {
+		for _, f := range tableMap[name].Fields {
+			if len(f.Classes) == 0 {
+				continue
+			}
+			classifications = append(classifications, Classification{
+				Table:   name,
+				Column:  f.Column,
+				Classes: f.Classes,
+			})
+		}
+	}
+	return classifications
+}
+
+// commentWithClasses returns the column comment that the classifications are embedded.
+func commentWithClasses(comment string, classes []string) string {
+	if len(classes) == 0 {
+		return comment
+	}
+	marker := classCommentPrefix + strings.Join(classes, ",")
+	if comment == "" {
+		return marker
+	}
+	return comment + " " + marker
+}
+
+// splitClassComment splits the column comment into the comment and the classifications embedded by commentWithClasses.
+func splitClassComment(comment string) (string, []string) {
+	i := strings.LastIndex(comment, classCommentPrefix)
+	if i < 0 || (i > 0 && comment[i-1] != ' ') {
+		return comment, nil
+	}
+	value := comment[i+len(classCommentPrefix):]
+	if value == "" || strings.ContainsAny(value, " \t") {
+		return comment, nil
+	}
+	return strings.TrimRight(comment[:i], " "), strings.Split(value, ",")
+}
+
+// validateClass returns an error if the class cannot be embedded into the column comment.
+func validateClass(class string) error {
+	if class == "" {
+		return fmt.Errorf("invalid class: empty")
+	}
+	for _, c := range class {
+		if !(c == '_' || c == '-' || c == '.' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')) {
+			return fmt.Errorf("invalid class: %q", class)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/naoina/migu"
+	"github.com/naoina/migu/dialect"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	anonymize := &anonymize{}
+	anonymizeCmd := &cobra.Command{
+		Use:   "anonymize [OPTIONS] DATABASE [FILE|DIRECTORY]",
+		Short: "output or copy the data of the database with anonymizing the classified columns",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return anonymize.Execute(args, option)
+		},
+	}
+	anonymizeCmd.Flags().StringArrayVar(&anonymize.Anonymizers, "anonymizer", nil, "Anonymize the columns of the class or TABLE.COLUMN in the form of KEY=(fake|hash|mask|null|keep) (can be repeated)")
+	anonymizeCmd.Flags().Int64Var(&anonymize.Seed, "seed", 1, "Seed of the random values of the fake anonymizer")
+	anonymizeCmd.Flags().StringVar(&anonymize.HashKeyFile, "hash-key-file", "", "Read the secret key of the hash anonymizer from the file")
+	anonymizeCmd.Flags().StringVarP(&anonymize.Output, "output", "o", "", "Output to the file instead of standard output")
+	anonymizeCmd.Flags().StringVar(&anonymize.To, "to", "", "Insert the data into the database of the environment in the config file instead of the output")
+	anonymizeCmd.SetUsageTemplate(usageTemplate + "\nWith no FILE, or when FILE is -, read standard input.\n")
+	rootCmd.AddCommand(anonymizeCmd)
+}
+
+type anonymize struct {
+	Anonymizers []string
+	Seed        int64
+	HashKeyFile string
+	Output      string
+	To          string
+
+	anonymizers map[string]migu.Anonymizer
+	key         []byte
+	tablePrefix string
+}
+
+func (a *anonymize) Execute(args []string, opt *Option) (err error) {
+	a.tablePrefix = opt.global.tablePrefix
+	var dbname string
+	var file string
+	switch len(args) {
+	case 0:
+		return fmt.Errorf("too few arguments")
+	case 1:
+		dbname = args[0]
+	case 2:
+		dbname, file = args[0], args[1]
+	default:
+		return fmt.Errorf("too many arguments")
+	}
+	a.anonymizers = map[string]migu.Anonymizer{}
+	for _, s := range a.Anonymizers {
+		key, anonymizer, err := migu.ParseAnonymizer(s)
+		if err != nil {
+			return err
+		}
+		a.anonymizers[key] = anonymizer
+	}
+	if a.HashKeyFile != "" {
+		var err error
+		if a.key, err = readKeyFile(a.HashKeyFile); err != nil {
+			return err
+		}
+	}
+	dst, dstCloser, err := a.destination(dbname, opt)
+	if err != nil {
+		return err
+	}
+	defer closeDialect(dstCloser, &err)
+	d, closer, err := newDialect(dbname, opt)
+	if err != nil {
+		return err
+	}
+	defer closeDialect(closer, &err)
+	return a.run(d, dst, file)
+}
+
+// destination returns the dialect of the environment of --to to copy the anonymized data into, or nil unless
+// --to is specified. The returned function must be called to release the resources after use.
+func (a *anonymize) destination(dbname string, opt *Option) (dialect.Dialect, func() error, error) {
+	if a.To == "" {
+		return nil, func() error { return nil }, nil
+	}
+	if a.Output != "" {
+		return nil, nil, fmt.Errorf("--to cannot be used with --output")
+	}
+	env, err := opt.global.Config.environment(a.To)
+	if err != nil {
+		return nil, nil, err
+	}
+	if env.Database == "" {
+		return nil, nil, fmt.Errorf("database of environment %s is not specified", a.To)
+	}
+	if env.Database == dbname {
+		return nil, nil, fmt.Errorf("refusing to copy the database %s into itself", dbname)
+	}
+	if env.Protected || opt.global.Config.isProtectedDatabase(env.Database) {
+		return nil, nil, fmt.Errorf("refusing to copy the data into the protected environment: %s", a.To)
+	}
+	d, closer, err := newDialect(env.Database, opt)
+	if err != nil {
+		return nil, nil, err
+	}
+	return d, closer, nil
+}
+
+// run outputs the anonymized data of d, or inserts it into dst unless dst is nil.
+func (a *anonymize) run(d, dst dialect.Dialect, file string) error {
+	var src interface{}
+	switch file {
+	case "", "-":
+		file = ""
+		src = os.Stdin
+	}
+	var opts []migu.Option
+	if a.tablePrefix != "" {
+		opts = append(opts, migu.WithTablePrefix(a.tablePrefix))
+	}
+	if dst != nil {
+		return migu.CopyAnonymized(dst, d, file, src, a.anonymizers, a.Seed, a.key, opts...)
+	}
+	out := os.Stdout
+	if a.Output != "" {
+		file, err := os.Create(a.Output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+	return migu.ExportAnonymized(out, d, file, src, a.anonymizers, a.Seed, a.key, opts...)
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/naoina/migu"
+	"github.com/naoina/migu/dialect"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	apply := &apply{}
+	applyCmd := &cobra.Command{
+		Use:   "apply [OPTIONS] DATABASE [FILE|DIRECTORY]",
+		Short: "apply the plan, the SQL script or the pending migrations to the database",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return apply.Execute(args, option)
+		},
+	}
+	applyCmd.Flags().BoolVar(&apply.DryRun, "dry-run", false, "")
+	applyCmd.Flags().BoolVarP(&apply.Quiet, "quiet", "q", false, "")
+	applyCmd.Flags().DurationVar(&apply.Heartbeat.Interval, "heartbeat-interval", 10*time.Second, "Interval of the heartbeats on another connection that show the progress of the running statement (0 means no heartbeat)")
+	applyCmd.Flags().StringArrayVar(&apply.Tags, "tag", nil, "Prepend the metadata in the form of key=value to every executed statement as a comment (can be repeated)")
+	applyCmd.Flags().StringVar(&apply.SigningKey, "signing-key", "", "Verify the signature of the plan by sync --signing-key with the project key in the file")
+	applyCmd.SetUsageTemplate(usageTemplate + "\nFILE is the report of sync --report-file, or the SQL script such as the output of diff.\n" +
+		"DIRECTORY has the migration files of generate, and the migrations that are not recorded in the " + migu.MigrationsTable + " table are applied in order.\n" +
+		"With no FILE, or when FILE is -, read standard input.\n")
+	rootCmd.AddCommand(applyCmd)
+}
+
+type apply struct {
+	DryRun     bool
+	Quiet      bool
+	Tags       []string
+	SigningKey string
+	Heartbeat  migu.Heartbeat
+
+	tags      []migu.StatementTag
+	redactor  *migu.Redactor
+	protected string
+}
+
+func (a *apply) Execute(args []string, opt *Option) (err error) {
+	var dbname string
+	var file string
+	switch len(args) {
+	case 0:
+		return fmt.Errorf("too few arguments")
+	case 1:
+		dbname = args[0]
+	case 2:
+		dbname, file = args[0], args[1]
+	default:
+		return fmt.Errorf("too many arguments")
+	}
+	for _, t := range a.Tags {
+		tag, err := migu.ParseStatementTag(t)
+		if err != nil {
+			return err
+		}
+		a.tags = append(a.tags, tag)
+	}
+	var src interface{}
+	switch file {
+	case "", "-":
+		file = ""
+		src = os.Stdin
+	}
+	if fi, err := os.Stat(file); err == nil && fi.IsDir() {
+		return a.migrate(dbname, file, opt)
+	}
+	var changes []*migu.Change
+	if a.SigningKey != "" {
+		key, err := readKeyFile(a.SigningKey)
+		if err != nil {
+			return err
+		}
+		r, err := migu.ReadRunReport(file, src)
+		if err != nil {
+			return err
+		}
+		if err := migu.VerifyPlan(r, key); err != nil {
+			return err
+		}
+		a.printf("--------verified the plan of commit %s--------\n", r.Commit)
+		changes = r.Changes()
+	} else {
+		if env := opt.global.Config.databaseEnvironment(dbname); env != nil && env.RequireSignedPlan {
+			return fmt.Errorf("database %s requires the signed plan; use --signing-key", dbname)
+		}
+		var err error
+		if changes, err = migu.ReadPlan(file, src); err != nil {
+			return err
+		}
+	}
+	d, closer, err := newDialect(dbname, opt)
+	if err != nil {
+		return err
+	}
+	defer closeDialect(closer, &err)
+	a.prepare(dbname, opt)
+	return a.run(d, changes)
+}
+
+// migrate applies the pending migrations in the directory, and records them in the migrations table.
+func (a *apply) migrate(dbname, dir string, opt *Option) (err error) {
+	if a.SigningKey != "" {
+		return fmt.Errorf("--signing-key cannot be used with the directory of the migrations")
+	}
+	migrations, err := migu.ReadMigrations(dir)
+	if err != nil {
+		return err
+	}
+	d, closer, err := newDialect(dbname, opt)
+	if err != nil {
+		return err
+	}
+	defer closeDialect(closer, &err)
+	changes, err := migu.MigrateChanges(d, migrations)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		a.printf("--------no pending migrations--------\n")
+		return nil
+	}
+	a.prepare(dbname, opt)
+	return a.run(d, changes)
+}
+
+// prepare prepares for applying the changes to the database.
+func (a *apply) prepare(dbname string, opt *Option) {
+	a.protected = protectedDatabase(dbname, opt)
+	a.redactor = opt.global.redactor
+	a.Heartbeat.OnProgress = func(p dialect.Progress) {
+		a.printf("--------progress: %s--------\n", formatProgress(p))
+	}
+	a.Heartbeat.OnFailure = func(err error) {
+		fmt.Fprintf(os.Stderr, "-- warning: %v\n", a.redactor.RedactError(err))
+	}
+}
+
+func (a *apply) run(d dialect.Dialect, changes []*migu.Change) error {
+	if !a.DryRun {
+		for _, c := range changes {
+			for _, sql := range c.SQLs {
+				if strings.Contains(sql, migu.Redacted) {
+					return fmt.Errorf("refusing to apply the statement whose secrets are redacted: %s", sql)
+				}
+			}
+		}
+	}
+	// The statements of the SQL script are regarded as destructive because their kinds are unknown.
+	if err := confirmDestructive(a.protected, a.DryRun, changes); err != nil {
+		return err
+	}
+	if !a.DryRun {
+		if err := migu.CheckFreeze(d); err != nil {
+			return err
+		}
+	}
+	marker := "dry-run "
+	if !a.DryRun {
+		marker = ""
+	}
+	var tx dialect.Transactioner
+	if !a.DryRun {
+		var err error
+		if tx, err = d.Begin(); err != nil {
+			return err
+		}
+	}
+	for _, c := range changes {
+		for _, sql := range c.SQLs {
+			sql, err := migu.TagStatement(sql, a.tags)
+			if err != nil {
+				if tx != nil {
+					tx.Rollback()
+				}
+				return err
+			}
+			a.printf("--------%sapplying--------\n", marker)
+			a.printf("%s\n", a.redactor.Redact(sql))
+			start := time.Now()
+			if !a.DryRun {
+				stop := a.Heartbeat.Start(d, sql)
+				err := tx.Exec(sql)
+				stop()
+				if err != nil {
+					tx.Rollback()
+					return err
+				}
+			}
+			a.printf("--------%sdone %.3fs--------\n", marker, time.Since(start).Seconds())
+		}
+	}
+	if a.DryRun {
+		return nil
+	}
+	return tx.Commit()
+}
+
+func (a *apply) printf(format string, args ...interface{}) (int, error) {
+	if a.Quiet {
+		return 0, nil
+	}
+	return fmt.Printf(format, args...)
+}
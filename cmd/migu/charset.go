@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/naoina/migu"
+	"github.com/naoina/migu/dialect"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	convertCharset := &convertCharset{}
+	convertCharsetCmd := &cobra.Command{
+		Use:   "convert-charset [OPTIONS] --to CHARSET DATABASE [TABLE...]",
+		Short: "convert the character set of the tables and their columns",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return convertCharset.Execute(args, option)
+		},
+	}
+	convertCharsetCmd.Flags().StringVar(&convertCharset.To, "to", "", "The character set to convert to (e.g. utf8mb4)")
+	convertCharsetCmd.Flags().StringVar(&convertCharset.Collation, "collation", "", "The collation to convert to. The default collation of the character set is used if not given")
+	convertCharsetCmd.Flags().IntVar(&convertCharset.BatchSize, "batch-size", 0, "Maximum number of tables to convert in this run (0 means unlimited)")
+	convertCharsetCmd.Flags().BoolVar(&convertCharset.DryRun, "dry-run", false, "")
+	convertCharsetCmd.Flags().BoolVarP(&convertCharset.Quiet, "quiet", "q", false, "")
+	convertCharsetCmd.SetUsageTemplate(usageTemplate + "\nWith no TABLE, all tables in the database are converted.\n")
+	rootCmd.AddCommand(convertCharsetCmd)
+}
+
+type convertCharset struct {
+	To        string
+	Collation string
+	BatchSize int
+	DryRun    bool
+	Quiet     bool
+
+	tablePrefix string
+}
+
+func (c *convertCharset) Execute(args []string, opt *Option) (err error) {
+	if len(args) == 0 {
+		return fmt.Errorf("too few arguments")
+	}
+	if c.To == "" {
+		return fmt.Errorf("--to is required")
+	}
+	if c.BatchSize < 0 {
+		return fmt.Errorf("--batch-size must be greater than or equal to 0")
+	}
+	c.tablePrefix = opt.global.tablePrefix
+	d, closer, err := newDialect(args[0], opt)
+	if err != nil {
+		return err
+	}
+	defer closeDialect(closer, &err)
+	return c.run(d, args[1:])
+}
+
+func (c *convertCharset) run(d dialect.Dialect, tables []string) error {
+	var opts []migu.Option
+	if c.tablePrefix != "" {
+		opts = append(opts, migu.WithTablePrefix(c.tablePrefix))
+	}
+	changes, err := migu.ConvertCharsetChanges(d, c.To, c.Collation, tables, opts...)
+	if err != nil {
+		return err
+	}
+	if !c.DryRun {
+		if err := migu.CheckFreeze(d); err != nil {
+			return err
+		}
+	}
+	var remains int
+	if c.BatchSize > 0 && len(changes) > c.BatchSize {
+		changes, remains = changes[:c.BatchSize], len(changes)-c.BatchSize
+	}
+	marker := "dry-run "
+	if !c.DryRun {
+		marker = ""
+	}
+	// Each table is converted and verified one by one, so that the converted tables are kept even if the later one
+	// fails.
+	for _, change := range changes {
+		for _, sql := range change.SQLs {
+			c.printf("--------%sapplying--------\n", marker)
+			c.printf("%s\n", sql)
+			start := time.Now()
+			if !c.DryRun {
+				if err := c.exec(d, sql); err != nil {
+					return err
+				}
+			}
+			c.printf("--------%sdone %.3fs--------\n", marker, time.Since(start).Seconds())
+		}
+		if c.DryRun {
+			continue
+		}
+		unconverted, err := migu.VerifyCharset(d, c.To, change.Table)
+		if err != nil {
+			return err
+		}
+		if len(unconverted) > 0 {
+			return fmt.Errorf("failed to verify the conversion of %s: %s", change.Table, strings.Join(unconverted, ", "))
+		}
+	}
+	if remains > 0 {
+		c.printf("%d table(s) remain to be converted\n", remains)
+	}
+	return nil
+}
+
+func (c *convertCharset) exec(d dialect.Dialect, sql string) error {
+	tx, err := d.Begin()
+	if err != nil {
+		return err
+	}
+	if err := tx.Exec(sql); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (c *convertCharset) printf(format string, a ...interface{}) (int, error) {
+	if c.Quiet {
+		return 0, nil
+	}
+	return fmt.Printf(format, a...)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/naoina/migu"
+	"github.com/naoina/migu/dialect"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	classify := &classify{}
+	classifyCmd := &cobra.Command{
+		Use:   "classify [OPTIONS] DATABASE [FILE|DIRECTORY]",
+		Short: "output the data classifications of the columns as CSV/TSV",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return classify.Execute(args, option)
+		},
+	}
+	classifyCmd.Flags().StringVar(&classify.Format, "format", inventoryFormatCSV, "Output format (csv|tsv)")
+	classifyCmd.Flags().BoolVar(&classify.FromDatabase, "from-database", false, "Read the classifications from the column comments of the database instead of Go's structs")
+	classifyCmd.Flags().StringVarP(&classify.Output, "output", "o", "", "Output to the file instead of standard output")
+	classifyCmd.SetUsageTemplate(usageTemplate + "\nWith no FILE, or when FILE is -, read standard input.\n")
+	rootCmd.AddCommand(classifyCmd)
+}
+
+type classify struct {
+	Format       string
+	FromDatabase bool
+	Output       string
+}
+
+func (c *classify) Execute(args []string, opt *Option) (err error) {
+	var dbname string
+	var file string
+	switch len(args) {
+	case 0:
+		return fmt.Errorf("too few arguments")
+	case 1:
+		dbname = args[0]
+	case 2:
+		dbname, file = args[0], args[1]
+	default:
+		return fmt.Errorf("too many arguments")
+	}
+	var comma rune
+	switch c.Format {
+	case inventoryFormatCSV:
+		comma = ','
+	case inventoryFormatTSV:
+		comma = '\t'
+	default:
+		return fmt.Errorf("unknown format: %s", c.Format)
+	}
+	di, closer, err := newDialect(dbname, opt)
+	if err != nil {
+		return err
+	}
+	defer closeDialect(closer, &err)
+	return c.run(di, file, comma)
+}
+
+func (c *classify) run(d dialect.Dialect, file string, comma rune) error {
+	var classifications []migu.Classification
+	var err error
+	if c.FromDatabase {
+		classifications, err = migu.DatabaseClassifications(d)
+	} else {
+		var src interface{}
+		switch file {
+		case "", "-":
+			file = ""
+			src = os.Stdin
+		}
+		classifications, err = migu.Classifications(d, file, src)
+	}
+	if err != nil {
+		return err
+	}
+	out := os.Stdout
+	if c.Output != "" {
+		file, err := os.Create(c.Output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+	return migu.WriteClassifications(out, classifications, comma)
+}
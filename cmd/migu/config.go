@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/goccy/go-yaml"
+	"github.com/naoina/migu"
+	"github.com/naoina/migu/dialect"
+)
+
+// Config is the configuration file of migu that is specified by --config.
+type Config struct {
+	// Database is the options to create the database by createdb and sync --create-if-missing, which are also the
+	// settings of the database that are altered by sync when they drift.
+	Database dialect.DatabaseOptions `yaml:"database"`
+
+	// Users are the database users and the roles that are created by sync.
+	Users []dialect.User `yaml:"users"`
+
+	// Environments are the environments that are specified by --env.
+	Environments map[string]*Environment `yaml:"environments"`
+
+	// Budget is the limits of the size of the schema that are checked by sync and diff.
+	Budget *BudgetConfig `yaml:"budget"`
+
+	// Comparison is the rules to decide whether the columns are modified by sync and diff.
+	Comparison *ComparisonConfig `yaml:"comparison"`
+
+	// CostOrder is the costs of the kinds of the changes that order the changes by sync and diff.
+	CostOrder *CostOrderConfig `yaml:"cost_order"`
+
+	// TablePrefix is the prefix of the table names that are managed by migu. --table-prefix has priority over it.
+	TablePrefix string `yaml:"table_prefix"`
+
+	// Redact is the secrets that are redacted in the output, the reports and the error messages.
+	Redact RedactConfig `yaml:"redact"`
+}
+
+// RedactConfig is the secrets that are redacted in addition to the passwords of DSNs and the users.
+type RedactConfig struct {
+	// Patterns are the regular expressions of the secrets, which are added to --redact-pattern. If the pattern has
+	// subexpressions, only the parts that match them are redacted.
+	Patterns []string `yaml:"patterns"`
+}
+
+// CostOrderConfig is the costs that order the changes so that the cheap changes are applied first.
+// The changes are ordered by the costs if it is specified even without --order-by-cost.
+type CostOrderConfig struct {
+	// Costs are the costs (metadata|build|rebuild|risky) of the kinds of the changes such as add_column, which take
+	// precedence over the default costs.
+	Costs map[migu.ChangeKind]migu.Cost `yaml:"costs"`
+}
+
+// ComparisonConfig is the rules to decide whether the columns are modified.
+type ComparisonConfig struct {
+	// Strategy is the strategy of the comparison (strict|lenient). --comparison has priority over it.
+	Strategy string `yaml:"strategy"`
+
+	// EquivalentTypes are the groups of the column types that are treated as equivalent to each other.
+	EquivalentTypes [][]string `yaml:"equivalent_types"`
+}
+
+// BudgetConfig is the limits of the size of the schema.
+type BudgetConfig struct {
+	migu.Budget `yaml:",inline"`
+
+	// Warn reports whether the violations are reported as the warnings instead of the errors.
+	Warn bool `yaml:"warn"`
+}
+
+// Environment is the environment such as dev and production.
+type Environment struct {
+	// Database is the name of the database of the environment.
+	Database string `yaml:"database"`
+
+	// Protected reports whether the environment must be guarded from destructive commands.
+	Protected bool `yaml:"protected"`
+
+	// Seeds are the SQL files that are applied after reset.
+	Seeds []string `yaml:"seeds"`
+
+	// Analyze reports whether sync updates the statistics of the tables after the changes as --analyze.
+	Analyze bool `yaml:"analyze"`
+
+	// Params are the parameters of the database driver such as readTimeout of MySQL.
+	Params map[string]string `yaml:"params"`
+
+	// Session are the statements such as SET that are executed at the start of every connection to the database.
+	Session []string `yaml:"session"`
+
+	// RequireSignedPlan reports whether apply accepts only the plans that are signed by sync --signing-key.
+	RequireSignedPlan bool `yaml:"require_signed_plan"`
+}
+
+// environment returns the environment named name.
+func (c *Config) environment(name string) (*Environment, error) {
+	env, ok := c.Environments[name]
+	if !ok || env == nil {
+		return nil, fmt.Errorf("unknown environment: %s", name)
+	}
+	return env, nil
+}
+
+// databaseEnvironment returns the environment that the database belongs to, or nil if not found.
+func (c *Config) databaseEnvironment(dbname string) *Environment {
+	for _, env := range c.Environments {
+		if env != nil && env.Database == dbname {
+			return env
+		}
+	}
+	return nil
+}
+
+// isProtectedDatabase reports whether the database belongs to any protected environment.
+func (c *Config) isProtectedDatabase(dbname string) bool {
+	for _, env := range c.Environments {
+		if env != nil && env.Protected && env.Database == dbname {
+			return true
+		}
+	}
+	return false
+}
+
+func readConfigFromFile(fname string) (*Config, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	defer f.Close()
+	var config Config
+	if err := yaml.NewDecoder(f, yaml.DisallowDuplicateKey(), yaml.DisallowUnknownField()).Decode(&config); err != nil {
+		return nil, fmt.Errorf("failed to decode config file: %w", err)
+	}
+	for i := range config.Users {
+		// The password can refer to the environment variables in order not to write the secret in the file.
+		config.Users[i].Password = os.ExpandEnv(config.Users[i].Password)
+	}
+	return &config, nil
+}
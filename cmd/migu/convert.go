@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/naoina/migu"
+	"github.com/spf13/cobra"
+)
+
+const (
+	convertFormatSkeema = "skeema"
+	convertFormatAtlas  = "atlas"
+)
+
+func init() {
+	imp := &importer{}
+	importCmd := &cobra.Command{
+		Use:   "import [OPTIONS] SOURCE [FILE]",
+		Short: "generate Go's structs from the schema definitions of other tools",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return imp.Execute(args, option)
+		},
+	}
+	importCmd.Flags().StringVar(&imp.Format, "format", "", "Format of SOURCE (skeema|atlas)")
+	importCmd.SetUsageTemplate(usageTemplate + "\nSOURCE is the schema directory for skeema, or the HCL file for atlas. When SOURCE is -, read standard input.\nWith FILE, output to FILE.\n")
+	rootCmd.AddCommand(importCmd)
+
+	exp := &exporter{}
+	exportCmd := &cobra.Command{
+		Use:   "export [OPTIONS] [FILE|DIRECTORY]",
+		Short: "convert Go's structs into the schema definitions of other tools",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return exp.Execute(args, option)
+		},
+	}
+	exportCmd.Flags().StringVar(&exp.Format, "format", "", "Output format (skeema|atlas)")
+	exportCmd.Flags().StringVarP(&exp.Output, "output", "o", "", "Output to the directory for skeema (required), or the file for atlas instead of standard output")
+	exportCmd.Flags().StringVar(&exp.Schema, "schema", "", "Schema name of the tables for atlas (required for atlas)")
+	exportCmd.SetUsageTemplate(usageTemplate + "\nWith no FILE, or when FILE is -, read standard input.\n")
+	rootCmd.AddCommand(exportCmd)
+}
+
+type importer struct {
+	Format string
+}
+
+func (i *importer) Execute(args []string, opt *Option) error {
+	var source string
+	var filename string
+	switch len(args) {
+	case 0:
+		return fmt.Errorf("too few arguments")
+	case 1:
+		source = args[0]
+	case 2:
+		source, filename = args[0], args[1]
+	default:
+		return fmt.Errorf("too many arguments")
+	}
+	d, err := newOfflineDialect(opt)
+	if err != nil {
+		return err
+	}
+	out := os.Stdout
+	if filename != "" {
+		file, err := os.Create(filename)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+	w := newEOLWriter(out, opt.global.eol)
+	switch i.Format {
+	case convertFormatSkeema:
+		return migu.ImportSkeema(w, d, source)
+	case convertFormatAtlas:
+		var src interface{}
+		if source == "-" {
+			source, src = "", os.Stdin
+		}
+		return migu.ImportAtlas(w, d, source, src)
+	case "":
+		return fmt.Errorf("--format is required")
+	default:
+		return fmt.Errorf("unknown format: %s", i.Format)
+	}
+}
+
+type exporter struct {
+	Format string
+	Output string
+	Schema string
+}
+
+func (e *exporter) Execute(args []string, opt *Option) error {
+	var file string
+	switch len(args) {
+	case 0:
+	case 1:
+		file = args[0]
+	default:
+		return fmt.Errorf("too many arguments")
+	}
+	switch e.Format {
+	case convertFormatSkeema:
+		if e.Output == "" {
+			return fmt.Errorf("--output is required for %s", e.Format)
+		}
+	case convertFormatAtlas:
+		if e.Schema == "" {
+			return fmt.Errorf("--schema is required for %s", e.Format)
+		}
+	case "":
+		return fmt.Errorf("--format is required")
+	default:
+		return fmt.Errorf("unknown format: %s", e.Format)
+	}
+	d, err := newOfflineDialect(opt)
+	if err != nil {
+		return err
+	}
+	var src interface{}
+	switch file {
+	case "", "-":
+		file = ""
+		src = os.Stdin
+	}
+	if e.Format == convertFormatSkeema {
+		filenames, err := migu.ExportSkeema(d, e.Output, file, src)
+		if err != nil {
+			return err
+		}
+		for _, fname := range filenames {
+			if err := convertFileEOL(fname, opt.global.eol); err != nil {
+				return err
+			}
+			fmt.Println(fname)
+		}
+		return nil
+	}
+	out := os.Stdout
+	if e.Output != "" {
+		file, err := os.Create(e.Output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+	return migu.ExportAtlas(newEOLWriter(out, opt.global.eol), d, e.Schema, file, src)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/naoina/migu/dialect"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	createdb := &createdb{}
+	createdbCmd := &cobra.Command{
+		Use:   "createdb [OPTIONS] DATABASE",
+		Short: "create the database if it does not exist",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return createdb.Execute(args, option)
+		},
+	}
+	createdbCmd.Flags().BoolVarP(&createdb.Quiet, "quiet", "q", false, "")
+	createdbCmd.SetUsageTemplate(usageTemplate)
+	rootCmd.AddCommand(createdbCmd)
+}
+
+type createdb struct {
+	Quiet bool
+}
+
+func (c *createdb) Execute(args []string, opt *Option) error {
+	var dbname string
+	switch len(args) {
+	case 0:
+		return fmt.Errorf("too few arguments")
+	case 1:
+		dbname = args[0]
+	default:
+		return fmt.Errorf("too many arguments")
+	}
+	created, err := createDatabase(dbname, opt)
+	if err != nil {
+		return err
+	}
+	if c.Quiet {
+		return nil
+	}
+	if created {
+		fmt.Printf("created database %s\n", dbname)
+	} else {
+		fmt.Printf("database %s already exists\n", dbname)
+	}
+	return nil
+}
+
+// createDatabase creates the database with the options in the config file if it does not exist, and reports whether
+// it is created.
+func createDatabase(dbname string, opt *Option) (created bool, err error) {
+	// MySQL cannot connect to the database that does not exist yet.
+	connectName := dbname
+	switch opt.global.DatabaseType {
+	case databaseTypeMySQL, databaseTypeMariaDB, databaseTypeTiDB:
+		if opt.global.dialectPlugin == "" {
+			connectName = ""
+		}
+	}
+	d, closer, err := newDialect(connectName, opt)
+	if err != nil {
+		return false, err
+	}
+	defer closeDialect(closer, &err)
+	creator, ok := d.(dialect.DatabaseCreator)
+	if !ok {
+		return false, fmt.Errorf("creating the database is not supported by the dialect")
+	}
+	return creator.CreateDatabase(dbname, opt.global.Config.Database)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/naoina/migu"
+	"github.com/naoina/migu/dialect"
+)
+
+const (
+	dedupeFail   = "fail"
+	dedupeSkip   = "skip"
+	dedupeIgnore = "ignore"
+
+	// dedupeExecPrefix is the prefix of the strategy that runs the command to remove the duplicates.
+	dedupeExecPrefix = "exec:"
+)
+
+// duplicateLimit is the maximum number of the duplicates that are reported for each unique index.
+const duplicateLimit = 5
+
+func validateDedupeStrategy(strategy string) error {
+	switch strategy {
+	case dedupeFail, dedupeSkip, dedupeIgnore:
+		return nil
+	}
+	if strings.HasPrefix(strategy, dedupeExecPrefix) && strings.TrimSpace(strings.TrimPrefix(strategy, dedupeExecPrefix)) != "" {
+		return nil
+	}
+	return fmt.Errorf("invalid --dedupe-strategy: %q; must be one of fail, skip, ignore or exec:COMMAND", strategy)
+}
+
+// checkUniqueIndexes reports the duplicate values of the columns that the unique indexes are created on, and resolves
+// them by the strategy. It returns the changes to apply, which do not have the skipped unique indexes.
+// The strategy is not carried out if dryRun.
+func checkUniqueIndexes(d dialect.Dialect, changes []*migu.Change, strategy string, dryRun bool) ([]*migu.Change, error) {
+	violations, err := migu.UniqueViolations(d, changes, duplicateLimit)
+	if err != nil {
+		return nil, err
+	}
+	if len(violations) == 0 {
+		return changes, nil
+	}
+	for _, v := range violations {
+		fmt.Fprintf(os.Stderr, "-- %s\n", v)
+	}
+	if dryRun {
+		return changes, nil
+	}
+	switch {
+	case strategy == dedupeIgnore:
+		return changes, nil
+	case strategy == dedupeSkip:
+		return skipUniqueIndexes(changes, violations), nil
+	case strings.HasPrefix(strategy, dedupeExecPrefix):
+		command := strings.TrimPrefix(strategy, dedupeExecPrefix)
+		for _, v := range violations {
+			if err := runDedupeCommand(command, v); err != nil {
+				return nil, err
+			}
+		}
+		if violations, err = migu.UniqueViolations(d, changes, duplicateLimit); err != nil {
+			return nil, err
+		}
+		if len(violations) == 0 {
+			return changes, nil
+		}
+		for _, v := range violations {
+			fmt.Fprintf(os.Stderr, "-- %s\n", v)
+		}
+		return nil, fmt.Errorf("%d unique index(es) still have the duplicates after %s", len(violations), command)
+	}
+	return nil, fmt.Errorf("refusing to create %d unique index(es) on the duplicate values; use --dedupe-strategy to resolve them", len(violations))
+}
+
+// skipUniqueIndexes returns the changes without the creations of the unique indexes that have the duplicates.
+func skipUniqueIndexes(changes []*migu.Change, violations []*migu.UniqueViolation) []*migu.Change {
+	skipped := make(map[string]struct{}, len(violations))
+	for _, v := range violations {
+		skipped[v.Table+"."+v.Index] = struct{}{}
+	}
+	var filtered []*migu.Change
+	for _, c := range changes {
+		if _, ok := skipped[c.Table+"."+c.Index]; ok && c.Kind == migu.CreateIndex {
+			for _, sql := range c.SQLs {
+				fmt.Fprintf(os.Stderr, "-- skipped: %s\n", sql)
+			}
+			continue
+		}
+		filtered = append(filtered, c)
+	}
+	return filtered
+}
+
+// runDedupeCommand runs the command by the shell to remove the duplicates of the unique index.
+// The table, the index and the comma-separated columns are passed by the environment variables MIGU_TABLE,
+// MIGU_INDEX and MIGU_COLUMNS.
+func runDedupeCommand(command string, v *migu.UniqueViolation) error {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Env = append(os.Environ(),
+		"MIGU_TABLE="+v.Table,
+		"MIGU_INDEX="+v.Index,
+		"MIGU_COLUMNS="+strings.Join(v.Columns, ","),
+	)
+	cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run %s for unique index %s of %s: %w", command, v.Index, v.Table, err)
+	}
+	return nil
+}
@@ -0,0 +1,401 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/naoina/migu"
+	"github.com/naoina/migu/dialect"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	diff := &diff{}
+	diffCmd := &cobra.Command{
+		Use:   "diff [OPTIONS] DATABASE [FILE|DIRECTORY]",
+		Short: "output SQLs to synchronize the database schema without applying",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := diff.Execute(args, option)
+			if _, ok := err.(*exitError); ok {
+				cmd.SilenceErrors, cmd.SilenceUsage = true, true
+			}
+			return err
+		},
+	}
+	diffCmd.Flags().StringVar(&diff.Format, "format", diffFormatSQL, "Output format (sql|json). json is the structured differences for CI jobs")
+	diffCmd.Flags().BoolVar(&diff.ExitCode, "exit-code", false, "Exit with status 1 if there are differences")
+	diffCmd.Flags().StringVar(&diff.Delimiter, "delimiter", ";", "Statement terminator appended to each SQL")
+	diffCmd.Flags().StringVarP(&diff.OutputDir, "output-dir", "o", "", "Write SQL files into the directory instead of standard output")
+	diffCmd.Flags().IntVar(&diff.MaxStatementsPerFile, "max-statements-per-file", 0, "Maximum number of statements per SQL file. 0 means unlimited. The statements of a change are never split across files (requires --output-dir)")
+	diffCmd.Flags().BoolVar(&diff.SplitByTable, "split-by-table", false, "Write SQL files per table (requires --output-dir)")
+	diffCmd.Flags().BoolVar(&diff.Explain, "explain", false, "Add the estimated number of rows to be scanned by each data-affecting change as a comment")
+	diffCmd.Flags().BoolVar(&diff.ExpandContract, "expand-contract", false, "Split SQLs into the expand phase and the contract phase. With --output-dir, they are written into the expand and contract subdirectories")
+	diffCmd.Flags().StringVar(&diff.At, "at", "", "Compare against the snapshot of the schema as of the date (YYYY-MM-DD or RFC3339) instead of the database (requires --snapshot-dir)")
+	diffCmd.Flags().StringVar(&diff.SnapshotDir, "snapshot-dir", "", "The directory of the snapshots that is written by sync")
+	diffCmd.Flags().BoolVar(&diff.FromDatabase, "from-database", false, "With --at, compare the snapshot against the database instead of Go's structs")
+	diff.diffOption.addFlags(diffCmd.Flags())
+	diffCmd.SetUsageTemplate(usageTemplate + "\nWith no FILE, or when FILE is -, read standard input.\n")
+	rootCmd.AddCommand(diffCmd)
+}
+
+type diff struct {
+	diffOption
+
+	Format               string
+	ExitCode             bool
+	Delimiter            string
+	OutputDir            string
+	MaxStatementsPerFile int
+	SplitByTable         bool
+	Explain              bool
+	ExpandContract       bool
+	At                   string
+	SnapshotDir          string
+	FromDatabase         bool
+
+	at       time.Time
+	eol      string
+	database dialect.DatabaseOptions
+}
+
+func (d *diff) Execute(args []string, opt *Option) (err error) {
+	var dbname string
+	var file string
+	switch len(args) {
+	case 0:
+		return fmt.Errorf("too few arguments")
+	case 1:
+		dbname = args[0]
+	case 2:
+		dbname, file = args[0], args[1]
+	default:
+		return fmt.Errorf("too many arguments")
+	}
+	switch d.Format {
+	case diffFormatSQL:
+	case diffFormatJSON:
+		if d.OutputDir != "" || d.ExpandContract {
+			return fmt.Errorf("--format json cannot be used with --output-dir and --expand-contract")
+		}
+	default:
+		return fmt.Errorf("unknown format: %s", d.Format)
+	}
+	if d.MaxStatementsPerFile < 0 {
+		return fmt.Errorf("--max-statements-per-file must be greater than or equal to 0")
+	}
+	if d.OutputDir == "" && (d.MaxStatementsPerFile > 0 || d.SplitByTable) {
+		return fmt.Errorf("--max-statements-per-file and --split-by-table require --output-dir")
+	}
+	if d.At != "" {
+		if d.SnapshotDir == "" {
+			return fmt.Errorf("--at requires --snapshot-dir")
+		}
+		at, err := parseTime(d.At)
+		if err != nil {
+			return err
+		}
+		d.at = at
+	} else if d.FromDatabase {
+		return fmt.Errorf("--from-database requires --at")
+	}
+	d.diffOption.budget = opt.global.Config.Budget
+	d.diffOption.comparison = opt.global.Config.Comparison
+	d.diffOption.costOrder = opt.global.Config.CostOrder
+	d.diffOption.tablePrefix = opt.global.tablePrefix
+	if err := d.diffOption.validate(); err != nil {
+		return err
+	}
+	d.eol = opt.global.eol
+	d.database = opt.global.Config.Database
+	di, closer, err := newDialect(dbname, opt)
+	if err != nil {
+		return err
+	}
+	defer closeDialect(closer, &err)
+	return d.run(di, file)
+}
+
+func (d *diff) run(di dialect.Dialect, file string) error {
+	var src interface{}
+	switch file {
+	case "", "-":
+		file = ""
+		src = os.Stdin
+	}
+	changes, err := d.diff(di, file, src)
+	if err != nil {
+		return err
+	}
+	for _, w := range degradationWarnings(di) {
+		fmt.Fprintf(os.Stderr, "-- warning: %s\n", w)
+	}
+	if d.Explain {
+		if err := migu.Estimate(di, changes); err != nil {
+			return err
+		}
+	}
+	if err := d.write(changes); err != nil {
+		return err
+	}
+	if d.ExitCode && len(changes) > 0 {
+		return &exitError{code: 1}
+	}
+	return nil
+}
+
+// write writes the changes in the format.
+func (d *diff) write(changes []*migu.Change) error {
+	if d.Format == diffFormatJSON {
+		return writeDiffJSON(newEOLWriter(os.Stdout, d.eol), changes)
+	}
+	if !d.ExpandContract {
+		return d.output(changes, d.OutputDir, "")
+	}
+	expand, contract := migu.SplitPhases(changes)
+	for _, phase := range []struct {
+		name    migu.Phase
+		changes []*migu.Change
+	}{
+		{migu.PhaseExpand, expand},
+		{migu.PhaseContract, contract},
+	} {
+		var dir string
+		if d.OutputDir != "" {
+			dir = filepath.Join(d.OutputDir, string(phase.name))
+		}
+		if err := d.output(phase.changes, dir, fmt.Sprintf("-- %s phase\n", phase.name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// output writes SQLs of the changes into dir, or standard output if dir is empty.
+// The header is written only to standard output.
+func (d *diff) output(changes []*migu.Change, dir string, header string) error {
+	chunks := d.chunks(changes)
+	if dir == "" {
+		w := newEOLWriter(os.Stdout, d.eol)
+		fmt.Fprint(w, header)
+		for _, chunk := range chunks {
+			if err := d.writeStatements(w, chunk.statements); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if err := removeSQLFiles(dir); err != nil {
+		return err
+	}
+	for i, chunk := range chunks {
+		name := fmt.Sprintf("%04d.sql", i+1)
+		if d.SplitByTable {
+			name = fmt.Sprintf("%04d_%s.sql", i+1, strings.NewReplacer("/", "_", `\`, "_").Replace(chunk.table))
+		}
+		if err := d.writeFile(filepath.Join(dir, name), chunk.statements); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *diff) diff(di dialect.Dialect, file string, src interface{}) ([]*migu.Change, error) {
+	if d.At == "" {
+		changes, err := migu.DiffChanges(di, file, src, d.options()...)
+		if err != nil {
+			return nil, err
+		}
+		// The settings of the database are altered first as sync does. The snapshots have no settings of the database.
+		databaseChanges, err := migu.DatabaseChanges(di, d.database)
+		if err != nil {
+			return nil, err
+		}
+		return append(databaseChanges, changes...), nil
+	}
+	snapshot, err := migu.FindSnapshot(d.SnapshotDir, d.at)
+	if err != nil {
+		return nil, err
+	}
+	if d.FromDatabase {
+		return migu.DiffStructsToDatabase(di, snapshot, nil, d.options()...)
+	}
+	return migu.DiffStructs(di, snapshot, nil, file, src, d.options()...)
+}
+
+// sqlFileRegexp matches the names of the SQL files that are written by output.
+var sqlFileRegexp = regexp.MustCompile(`^\d{4}(?:_.*)?\.sql$`)
+
+// removeSQLFiles removes the SQL files that were written into dir by the previous run, so that the files of the
+// larger run are not left. The other files are left as they are.
+func removeSQLFiles(dir string) error {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if f.Mode().IsRegular() && sqlFileRegexp.MatchString(f.Name()) {
+			if err := os.Remove(filepath.Join(dir, f.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type statement struct {
+	change *migu.Change
+	sql    string
+}
+
+type sqlChunk struct {
+	table      string
+	statements []statement
+}
+
+// chunks splits SQLs of the changes into the chunks by table and/or by the number of statements.
+// The order of SQLs is preserved across the chunks. SQLs of a change are always in the same chunk, such as the
+// statements that rebuild the table, so that the chunk that has more statements than MaxStatementsPerFile is made
+// for the change that has them.
+func (d *diff) chunks(changes []*migu.Change) []*sqlChunk {
+	var chunks []*sqlChunk
+	var current *sqlChunk
+	for _, c := range changes {
+		if len(c.SQLs) == 0 {
+			continue
+		}
+		if current == nil ||
+			(d.SplitByTable && current.table != c.Table) ||
+			(d.MaxStatementsPerFile > 0 && len(current.statements)+len(c.SQLs) > d.MaxStatementsPerFile) {
+			current = &sqlChunk{table: c.Table}
+			chunks = append(chunks, current)
+		}
+		for _, sql := range c.SQLs {
+			current.statements = append(current.statements, statement{change: c, sql: sql})
+		}
+	}
+	return chunks
+}
+
+func (d *diff) writeFile(filename string, statements []statement) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := d.writeStatements(newEOLWriter(f, d.eol), statements); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (d *diff) writeStatements(w io.Writer, statements []statement) error {
+	for _, stmt := range statements {
+		if d.Explain && stmt.change.IsDataAffecting() {
+			if _, err := fmt.Fprintf(w, "-- estimated rows to be scanned: %d\n", stmt.change.EstimatedRows); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s%s\n", stmt.sql, d.Delimiter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const (
+	diffFormatSQL  = "sql"
+	diffFormatJSON = "json"
+)
+
+// diffResult is the JSON output of diff.
+type diffResult struct {
+	FormatVersion int           `json:"formatVersion"`
+	Changes       []*diffChange `json:"changes"`
+}
+
+// diffChange is a change in the JSON output of diff.
+type diffChange struct {
+	Kind          migu.ChangeKind `json:"kind"`
+	Table         string          `json:"table"`
+	NewName       string          `json:"newName,omitempty"`
+	Column        string          `json:"column,omitempty"`
+	Index         string          `json:"index,omitempty"`
+	Constraint    string          `json:"constraint,omitempty"`
+	User          string          `json:"user,omitempty"`
+	Old           *diffColumn     `json:"old,omitempty"`
+	New           *diffColumn     `json:"new,omitempty"`
+	Destructive   bool            `json:"destructive"`
+	SQLs          []string        `json:"sqls"`
+	EstimatedRows int64           `json:"estimatedRows,omitempty"`
+}
+
+// diffColumn is the definition of the column before or after the change in the JSON output of diff.
+type diffColumn struct {
+	Type          string `json:"type"`
+	Nullable      bool   `json:"nullable"`
+	Default       string `json:"default,omitempty"`
+	AutoIncrement bool   `json:"autoIncrement,omitempty"`
+	Extra         string `json:"extra,omitempty"`
+	Comment       string `json:"comment,omitempty"`
+}
+
+func newDiffColumn(f *dialect.Field) *diffColumn {
+	if f == nil {
+		return nil
+	}
+	return &diffColumn{
+		Type:          f.Type,
+		Nullable:      f.Nullable,
+		Default:       f.Default,
+		AutoIncrement: f.AutoIncrement,
+		Extra:         f.Extra,
+		Comment:       f.Comment,
+	}
+}
+
+// writeDiffJSON writes the changes as diffResult in JSON.
+func writeDiffJSON(w io.Writer, changes []*migu.Change) error {
+	result := &diffResult{
+		FormatVersion: migu.FormatVersion,
+		Changes:       make([]*diffChange, len(changes)),
+	}
+	for i, c := range changes {
+		result.Changes[i] = &diffChange{
+			Kind:          c.Kind,
+			Table:         c.Table,
+			NewName:       c.NewName,
+			Column:        c.Column,
+			Index:         c.Index,
+			Constraint:    c.Constraint,
+			User:          c.User,
+			Old:           newDiffColumn(c.OldField),
+			New:           newDiffColumn(c.NewField),
+			Destructive:   c.IsDestructive(),
+			SQLs:          c.SQLs,
+			EstimatedRows: c.EstimatedRows,
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(result)
+}
+
+// parseTime parses s as a date (YYYY-MM-DD) or RFC3339 time.
+func parseTime(s string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02", time.RFC3339} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time: %s", s)
+}
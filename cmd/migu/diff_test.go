@@ -0,0 +1,282 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/naoina/migu"
+	"github.com/naoina/migu/dialect"
+)
+
+func TestWriteDiffJSON(t *testing.T) {
+	for _, v := range []struct {
+		changes []*migu.Change
+		expect  string
+	}{
+		{nil, `{
+  "formatVersion": 2,
+  "changes": []
+}
+`},
+		{[]*migu.Change{
+			{
+				Kind:     migu.AddColumn,
+				Table:    "user",
+				Column:   "age",
+				NewField: &dialect.Field{Name: "age", Type: "BIGINT", Default: "0", Comment: "age"},
+				SQLs:     []string{"ALTER TABLE `user` ADD `age` BIGINT NOT NULL DEFAULT 0 COMMENT 'age'"},
+			},
+			{
+				Kind:          migu.DropColumn,
+				Table:         "user",
+				Column:        "name",
+				OldField:      &dialect.Field{Name: "name", Type: "VARCHAR(255)"},
+				SQLs:          []string{"ALTER TABLE `user` DROP `name`"},
+				EstimatedRows: 10,
+			},
+		}, `{
+  "formatVersion": 2,
+  "changes": [
+    {
+      "kind": "add_column",
+      "table": "user",
+      "column": "age",
+      "new": {
+        "type": "BIGINT",
+        "nullable": false,
+        "default": "0",
+        "comment": "age"
+      },
+      "destructive": false,
+      "sqls": [
+        "ALTER TABLE ` + "`user` ADD `age`" + ` BIGINT NOT NULL DEFAULT 0 COMMENT 'age'"
+      ]
+    },
+    {
+      "kind": "drop_column",
+      "table": "user",
+      "column": "name",
+      "old": {
+        "type": "VARCHAR(255)",
+        "nullable": false
+      },
+      "destructive": true,
+      "sqls": [
+        "ALTER TABLE ` + "`user` DROP `name`" + `"
+      ],
+      "estimatedRows": 10
+    }
+  ]
+}
+`},
+	} {
+		var buf bytes.Buffer
+		if err := writeDiffJSON(&buf, v.changes); err != nil {
+			t.Fatal(err)
+		}
+		actual := buf.String()
+		if diff := cmp.Diff(actual, v.expect); diff != "" {
+			t.Errorf("(-got +want)\n%v", diff)
+		}
+	}
+}
+
+// emptyDialect is the dialect of the database that has no tables. It has only the methods of dialect.Dialect.
+type emptyDialect struct {
+	dialect.Dialect
+}
+
+func (d *emptyDialect) ColumnSchema(tables ...string) ([]dialect.ColumnSchema, error) {
+	return nil, nil
+}
+
+func TestDiffExitCode(t *testing.T) {
+	dir, err := ioutil.TempDir("", "migu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	stdout := os.Stdout
+	defer func() {
+		os.Stdout = stdout
+	}()
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+	os.Stdout = devNull
+	for i, v := range []struct {
+		format   string
+		exitCode bool
+		src      string
+		expect   int
+	}{
+		{diffFormatSQL, true, "//+migu\ntype User struct {\n\tName string\n}\n", 1},
+		{diffFormatJSON, true, "//+migu\ntype User struct {\n\tName string\n}\n", 1},
+		{diffFormatSQL, false, "//+migu\ntype User struct {\n\tName string\n}\n", 0},
+		{diffFormatSQL, true, "", 0},
+		{diffFormatJSON, true, "", 0},
+		{diffFormatSQL, true, "//+migu\ntype User struct {\n\tName string `migu:\"charset:utf-8\"`\n}\n", 1},
+		{diffFormatSQL, false, "//+migu\ntype User struct {\n\tName string `migu:\"charset:utf-8\"`\n}\n", 1},
+	} {
+		file := filepath.Join(dir, fmt.Sprintf("schema%d.go", i))
+		if err := ioutil.WriteFile(file, []byte("package migu_test\n"+v.src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		d := &diff{Format: v.format, ExitCode: v.exitCode, Delimiter: ";"}
+		err := d.run(&emptyDialect{dialect.NewMySQL(nil)}, file)
+		if actual := exitCode(err); actual != v.expect {
+			t.Errorf("%s with --format %s and --exit-code=%v => %d (%v); want %d", v.src, v.format, v.exitCode, actual, err, v.expect)
+		}
+	}
+}
+
+// databaseDialect is emptyDialect of the database that has the settings.
+type databaseDialect struct {
+	emptyDialect
+	current dialect.DatabaseOptions
+}
+
+func (d *databaseDialect) DatabaseOptions() (dialect.DatabaseOptions, error) {
+	return d.current, nil
+}
+
+func (d *databaseDialect) AlterDatabaseSQL(opts dialect.DatabaseOptions) []string {
+	return d.Dialect.(dialect.DatabaseAlterer).AlterDatabaseSQL(opts)
+}
+
+func TestDiffDatabaseChanges(t *testing.T) {
+	dir, err := ioutil.TempDir("", "migu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "schema.go")
+	if err := ioutil.WriteFile(file, []byte("package migu_test\n//+migu\ntype User struct {\n\tName string\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	di := &databaseDialect{
+		emptyDialect: emptyDialect{dialect.NewMySQL(nil)},
+		current:      dialect.DatabaseOptions{Charset: "utf8", Collation: "utf8_general_ci"},
+	}
+	d := &diff{Format: diffFormatSQL, Delimiter: ";", database: dialect.DatabaseOptions{Charset: "utf8mb4", Collation: "utf8mb4_bin"}}
+	var runErr error
+	actual := captureStdout(t, func() {
+		runErr = d.run(di, file)
+	})
+	if runErr != nil {
+		t.Fatal(runErr)
+	}
+	expect := "ALTER DATABASE CHARACTER SET utf8mb4 COLLATE utf8mb4_bin;\nCREATE TABLE `user` (\n"
+	if !strings.HasPrefix(actual, expect) {
+		t.Errorf("diff => %q; want the prefix %q", actual, expect)
+	}
+}
+
+func TestDiffChunks(t *testing.T) {
+	rebuild := &migu.Change{Kind: migu.ModifyColumn, Table: "a", SQLs: []string{"CREATE TABLE a_new", "INSERT INTO a_new", "DROP TABLE a", "ALTER TABLE a_new RENAME TO a"}}
+	add := &migu.Change{Kind: migu.AddColumn, Table: "a", SQLs: []string{"ALTER TABLE a ADD x"}}
+	create := &migu.Change{Kind: migu.CreateTable, Table: "b", SQLs: []string{"CREATE TABLE b"}}
+	comment := &migu.Change{Kind: migu.AddColumn, Table: "b", SQLs: []string{"ALTER TABLE b ADD y", "COMMENT ON COLUMN b.y IS 'y'"}}
+	changes := []*migu.Change{rebuild, add, create, comment}
+	for _, v := range []struct {
+		max          int
+		splitByTable bool
+		expect       [][]string
+	}{
+		{0, false, [][]string{
+			{"a", "CREATE TABLE a_new", "INSERT INTO a_new", "DROP TABLE a", "ALTER TABLE a_new RENAME TO a", "ALTER TABLE a ADD x", "CREATE TABLE b", "ALTER TABLE b ADD y", "COMMENT ON COLUMN b.y IS 'y'"},
+		}},
+		{2, false, [][]string{
+			{"a", "CREATE TABLE a_new", "INSERT INTO a_new", "DROP TABLE a", "ALTER TABLE a_new RENAME TO a"},
+			{"a", "ALTER TABLE a ADD x", "CREATE TABLE b"},
+			{"b", "ALTER TABLE b ADD y", "COMMENT ON COLUMN b.y IS 'y'"},
+		}},
+		{0, true, [][]string{
+			{"a", "CREATE TABLE a_new", "INSERT INTO a_new", "DROP TABLE a", "ALTER TABLE a_new RENAME TO a", "ALTER TABLE a ADD x"},
+			{"b", "CREATE TABLE b", "ALTER TABLE b ADD y", "COMMENT ON COLUMN b.y IS 'y'"},
+		}},
+		{3, true, [][]string{
+			{"a", "CREATE TABLE a_new", "INSERT INTO a_new", "DROP TABLE a", "ALTER TABLE a_new RENAME TO a"},
+			{"a", "ALTER TABLE a ADD x"},
+			{"b", "CREATE TABLE b", "ALTER TABLE b ADD y", "COMMENT ON COLUMN b.y IS 'y'"},
+		}},
+	} {
+		d := &diff{MaxStatementsPerFile: v.max, SplitByTable: v.splitByTable}
+		var actual [][]string
+		for _, chunk := range d.chunks(changes) {
+			sqls := []string{chunk.table}
+			for _, stmt := range chunk.statements {
+				sqls = append(sqls, stmt.sql)
+			}
+			actual = append(actual, sqls)
+		}
+		if diff := cmp.Diff(actual, v.expect); diff != "" {
+			t.Errorf("--max-statements-per-file=%d --split-by-table=%v: (-got +want)\n%v", v.max, v.splitByTable, diff)
+		}
+	}
+}
+
+func TestDiffOutputDir(t *testing.T) {
+	changes := []*migu.Change{
+		{Kind: migu.CreateTable, Table: "user", SQLs: []string{"CREATE TABLE user (id INT)"}},
+		{Kind: migu.AddColumn, Table: "user", SQLs: []string{"ALTER TABLE user ADD name TEXT"}},
+		{Kind: migu.CreateTable, Table: "post", SQLs: []string{"CREATE TABLE post (id INT)"}},
+	}
+	for _, v := range []struct {
+		d      *diff
+		expect map[string]string
+	}{
+		{&diff{Delimiter: ";"}, map[string]string{
+			"0001.sql":  "CREATE TABLE user (id INT);\nALTER TABLE user ADD name TEXT;\nCREATE TABLE post (id INT);\n",
+			"notes.txt": "notes",
+		}},
+		{&diff{Delimiter: "\nGO", MaxStatementsPerFile: 2}, map[string]string{
+			"0001.sql":  "CREATE TABLE user (id INT)\nGO\nALTER TABLE user ADD name TEXT\nGO\n",
+			"0002.sql":  "CREATE TABLE post (id INT)\nGO\n",
+			"notes.txt": "notes",
+		}},
+		{&diff{Delimiter: ";", SplitByTable: true}, map[string]string{
+			"0001_user.sql": "CREATE TABLE user (id INT);\nALTER TABLE user ADD name TEXT;\n",
+			"0002_post.sql": "CREATE TABLE post (id INT);\n",
+			"notes.txt":     "notes",
+		}},
+	} {
+		dir, err := ioutil.TempDir("", "migu")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		// The files of the previous run are removed, while the other files are left.
+		for name, content := range map[string]string{"0001.sql": "stale", "0009_user.sql": "stale", "notes.txt": "notes"} {
+			if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := v.d.output(changes, dir, ""); err != nil {
+			t.Fatal(err)
+		}
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual := map[string]string{}
+		for _, f := range files {
+			b, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual[f.Name()] = string(b)
+		}
+		if diff := cmp.Diff(actual, v.expect); diff != "" {
+			t.Errorf("%+v: (-got +want)\n%v", v.d, diff)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/naoina/migu/dialect"
+	"github.com/spf13/cobra"
+)
+
+const (
+	doctorOK   = "ok"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+)
+
+// requiredPrivileges are the privileges that are needed by sync.
+var requiredPrivileges = []string{"ALTER", "CREATE", "DROP", "INDEX", "REFERENCES"}
+
+func init() {
+	doctor := &doctor{}
+	doctorCmd := &cobra.Command{
+		Use:   "doctor [OPTIONS] DATABASE",
+		Short: "check the connectivity, the privileges and the capabilities before applying the changes",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return doctor.Execute(args, option)
+		},
+	}
+	doctorCmd.SetUsageTemplate(usageTemplate)
+	rootCmd.AddCommand(doctorCmd)
+}
+
+type doctor struct{}
+
+type doctorCheck struct {
+	Name    string
+	Status  string
+	Message string
+	Details []string
+}
+
+func (d *doctor) Execute(args []string, opt *Option) (err error) {
+	var dbname string
+	switch len(args) {
+	case 0:
+		return fmt.Errorf("too few arguments")
+	case 1:
+		dbname = args[0]
+	default:
+		return fmt.Errorf("too many arguments")
+	}
+	var checks []doctorCheck
+	di, closer, err := newDialect(dbname, opt)
+	if err != nil {
+		checks = append(checks, doctorCheck{Name: "connection", Status: doctorFail, Message: err.Error()})
+	} else {
+		defer closeDialect(closer, &err)
+		checks = d.run(di)
+	}
+	var failed int
+	for _, c := range checks {
+		fmt.Printf("%-4s %s: %s\n", c.Status, c.Name, c.Message)
+		for _, detail := range c.Details {
+			fmt.Printf("       %s\n", detail)
+		}
+		if c.Status == doctorFail {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("not ready: %d check(s) failed", failed)
+	}
+	fmt.Printf("ready to apply the changes to %s\n", dbname)
+	return nil
+}
+
+func (d *doctor) run(di dialect.Dialect) []doctorCheck {
+	var checks []doctorCheck
+	if v, ok := di.(dialect.ServerVersioner); ok {
+		version, supported, err := v.ServerVersion()
+		switch {
+		case err != nil:
+			return append(checks, doctorCheck{Name: "connection", Status: doctorFail, Message: err.Error()})
+		case !supported:
+			checks = append(checks, doctorCheck{Name: "server", Status: doctorFail, Message: "unsupported version " + version})
+		default:
+			checks = append(checks, doctorCheck{Name: "server", Status: doctorOK, Message: "version " + version})
+		}
+	}
+	if _, err := di.ColumnSchema(); err != nil {
+		return append(checks, doctorCheck{Name: "columns", Status: doctorFail, Message: err.Error()})
+	}
+	checks = append(checks, doctorCheck{Name: "columns", Status: doctorOK, Message: "readable"})
+	if r, ok := di.(dialect.DegradationReporter); ok {
+		checked := map[string]bool{}
+		if e, ok := di.(dialect.TableEncrypter); ok {
+			// The encryption is introspected only on demand.
+			if _, err := e.TableEncryptions(); err != nil {
+				checked[dialect.FeatureEncryption] = true
+				checks = append(checks, doctorCheck{Name: dialect.FeatureEncryption, Status: doctorFail, Message: err.Error()})
+			}
+		}
+		for _, dg := range r.Degradations() {
+			checked[dg.Feature] = true
+			checks = append(checks, doctorCheck{Name: dg.Feature, Status: doctorWarn, Message: fmt.Sprintf("could not be introspected: %v", dg.Err)})
+		}
+		for _, feature := range []string{dialect.FeatureIndexes, dialect.FeatureEncryption} {
+			if !checked[feature] {
+				checks = append(checks, doctorCheck{Name: feature, Status: doctorOK, Message: "readable"})
+			}
+		}
+	}
+	checks = append(checks, d.checkPrivileges(di))
+	if r, ok := di.(dialect.LockBlockerReader); ok {
+		checks = append(checks, d.checkLocks(r))
+	}
+	return checks
+}
+
+func (d *doctor) checkPrivileges(di dialect.Dialect) doctorCheck {
+	check := doctorCheck{Name: "privileges"}
+	r, ok := di.(dialect.PrivilegeReader)
+	if !ok {
+		check.Status, check.Message = doctorWarn, "not checked because the dialect does not support it"
+		return check
+	}
+	privileges, err := r.Privileges()
+	if err != nil {
+		check.Status, check.Message = doctorWarn, fmt.Sprintf("could not be read: %v", err)
+		return check
+	}
+	granted := map[string]bool{}
+	for _, p := range privileges {
+		granted[p] = true
+	}
+	var missing []string
+	for _, p := range requiredPrivileges {
+		if !granted[p] {
+			missing = append(missing, p)
+		}
+	}
+	if len(missing) > 0 {
+		check.Status, check.Message = doctorFail, "missing "+strings.Join(missing, ", ")
+		return check
+	}
+	check.Status, check.Message = doctorOK, strings.Join(requiredPrivileges, ", ")
+	return check
+}
+
+func (d *doctor) checkLocks(r dialect.LockBlockerReader) doctorCheck {
+	check := doctorCheck{Name: "locks"}
+	blockers, err := r.LockBlockers()
+	if err != nil {
+		check.Status, check.Message = doctorWarn, fmt.Sprintf("could not be checked: %v", err)
+		return check
+	}
+	if len(blockers) == 0 {
+		check.Status, check.Message = doctorOK, "no open transactions"
+		return check
+	}
+	check.Status, check.Message = doctorWarn, fmt.Sprintf("%d open transaction(s) may block the DDL", len(blockers))
+	for _, b := range blockers {
+		detail := fmt.Sprintf("session %s for %v", b.Session, b.Duration)
+		if b.Query != "" {
+			detail += ": " + b.Query
+		}
+		check.Details = append(check.Details, detail)
+	}
+	return check
+}
+
+// degradationWarnings returns the warnings of the features that have been skipped by the introspection.
+func degradationWarnings(d dialect.Dialect) []string {
+	r, ok := d.(dialect.DegradationReporter)
+	if !ok {
+		return nil
+	}
+	var warnings []string
+	for _, dg := range r.Degradations() {
+		warnings = append(warnings, fmt.Sprintf("%s could not be introspected and is left unchanged: %v", dg.Feature, dg.Err))
+	}
+	return warnings
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/naoina/migu/dialect"
+)
+
+// doctorDialect is the dialect that has only the methods of dialect.Dialect.
+type doctorDialect struct {
+	dialect.Dialect
+	columnsErr error
+}
+
+func (d *doctorDialect) ColumnSchema(tables ...string) ([]dialect.ColumnSchema, error) {
+	return nil, d.columnsErr
+}
+
+// doctorServerDialect is the dialect that implements all interfaces that are checked by doctor.
+type doctorServerDialect struct {
+	doctorDialect
+	version        string
+	unsupported    bool
+	versionErr     error
+	degradations   []dialect.Degradation
+	encryptionsErr error
+	privileges     []string
+	privilegesErr  error
+	blockers       []dialect.LockBlocker
+	blockersErr    error
+}
+
+func (d *doctorServerDialect) ServerVersion() (string, bool, error) {
+	return d.version, !d.unsupported, d.versionErr
+}
+
+func (d *doctorServerDialect) Degradations() []dialect.Degradation {
+	return d.degradations
+}
+
+func (d *doctorServerDialect) TableEncryptions(tables ...string) (map[string]bool, error) {
+	return nil, d.encryptionsErr
+}
+
+func (d *doctorServerDialect) ModifyTableEncryptionSQL(table string, encrypted bool) []string {
+	return nil
+}
+
+func (d *doctorServerDialect) Privileges() ([]string, error) {
+	return d.privileges, d.privilegesErr
+}
+
+func (d *doctorServerDialect) LockBlockers() ([]dialect.LockBlocker, error) {
+	return d.blockers, d.blockersErr
+}
+
+func TestDoctorRun(t *testing.T) {
+	all := []string{"ALTER", "CREATE", "DROP", "INDEX", "REFERENCES", "SELECT"}
+	for _, v := range []struct {
+		dialect dialect.Dialect
+		expect  []doctorCheck
+	}{
+		{&doctorDialect{}, []doctorCheck{
+			{Name: "columns", Status: doctorOK, Message: "readable"},
+			{Name: "privileges", Status: doctorWarn, Message: "not checked because the dialect does not support it"},
+		}},
+		{&doctorDialect{columnsErr: errors.New("access denied")}, []doctorCheck{
+			{Name: "columns", Status: doctorFail, Message: "access denied"},
+		}},
+		{&doctorServerDialect{versionErr: errors.New("connection refused")}, []doctorCheck{
+			{Name: "connection", Status: doctorFail, Message: "connection refused"},
+		}},
+		{&doctorServerDialect{version: "8.0.32", privileges: all}, []doctorCheck{
+			{Name: "server", Status: doctorOK, Message: "version 8.0.32"},
+			{Name: "columns", Status: doctorOK, Message: "readable"},
+			{Name: "indexes", Status: doctorOK, Message: "readable"},
+			{Name: "encryption", Status: doctorOK, Message: "readable"},
+			{Name: "privileges", Status: doctorOK, Message: "ALTER, CREATE, DROP, INDEX, REFERENCES"},
+			{Name: "locks", Status: doctorOK, Message: "no open transactions"},
+		}},
+		{&doctorServerDialect{version: "5.5.62", unsupported: true, privileges: all}, []doctorCheck{
+			{Name: "server", Status: doctorFail, Message: "unsupported version 5.5.62"},
+			{Name: "columns", Status: doctorOK, Message: "readable"},
+			{Name: "indexes", Status: doctorOK, Message: "readable"},
+			{Name: "encryption", Status: doctorOK, Message: "readable"},
+			{Name: "privileges", Status: doctorOK, Message: "ALTER, CREATE, DROP, INDEX, REFERENCES"},
+			{Name: "locks", Status: doctorOK, Message: "no open transactions"},
+		}},
+		{&doctorServerDialect{
+			doctorDialect:  doctorDialect{columnsErr: errors.New("access denied")},
+			version:        "8.0.32",
+			encryptionsErr: errors.New("not reached"),
+		}, []doctorCheck{
+			{Name: "server", Status: doctorOK, Message: "version 8.0.32"},
+			{Name: "columns", Status: doctorFail, Message: "access denied"},
+		}},
+		{&doctorServerDialect{
+			version:        "8.0.32",
+			degradations:   []dialect.Degradation{{Feature: dialect.FeatureIndexes, Err: errors.New("SELECT command denied")}},
+			encryptionsErr: errors.New("access denied"),
+			privileges:     []string{"ALTER", "CREATE", "SELECT"},
+			blockers: []dialect.LockBlocker{
+				{Session: "12", Duration: 90 * time.Second, Query: "SELECT SLEEP(100)"},
+				{Session: "13", Duration: time.Hour},
+			},
+		}, []doctorCheck{
+			{Name: "server", Status: doctorOK, Message: "version 8.0.32"},
+			{Name: "columns", Status: doctorOK, Message: "readable"},
+			{Name: "encryption", Status: doctorFail, Message: "access denied"},
+			{Name: "indexes", Status: doctorWarn, Message: "could not be introspected: SELECT command denied"},
+			{Name: "privileges", Status: doctorFail, Message: "missing DROP, INDEX, REFERENCES"},
+			{Name: "locks", Status: doctorWarn, Message: "2 open transaction(s) may block the DDL", Details: []string{
+				"session 12 for 1m30s: SELECT SLEEP(100)",
+				"session 13 for 1h0m0s",
+			}},
+		}},
+		{&doctorServerDialect{
+			version:       "8.0.32",
+			privilegesErr: errors.New("SHOW GRANTS denied"),
+			blockersErr:   errors.New("performance_schema is disabled"),
+		}, []doctorCheck{
+			{Name: "server", Status: doctorOK, Message: "version 8.0.32"},
+			{Name: "columns", Status: doctorOK, Message: "readable"},
+			{Name: "indexes", Status: doctorOK, Message: "readable"},
+			{Name: "encryption", Status: doctorOK, Message: "readable"},
+			{Name: "privileges", Status: doctorWarn, Message: "could not be read: SHOW GRANTS denied"},
+			{Name: "locks", Status: doctorWarn, Message: "could not be checked: performance_schema is disabled"},
+		}},
+	} {
+		actual := (&doctor{}).run(v.dialect)
+		if diff := cmp.Diff(actual, v.expect); diff != "" {
+			t.Errorf("(-got +want)\n%v", diff)
+		}
+	}
+}
+
+func TestDegradationWarnings(t *testing.T) {
+	for _, v := range []struct {
+		dialect dialect.Dialect
+		expect  []string
+	}{
+		{&doctorDialect{}, nil},
+		{&doctorServerDialect{}, nil},
+		{&doctorServerDialect{degradations: []dialect.Degradation{
+			{Feature: dialect.FeatureIndexes, Err: errors.New("SELECT command denied")},
+		}}, []string{
+			"indexes could not be introspected and is left unchanged: SELECT command denied",
+		}},
+	} {
+		actual := degradationWarnings(v.dialect)
+		if diff := cmp.Diff(actual, v.expect); diff != "" {
+			t.Errorf("(-got +want)\n%v", diff)
+		}
+	}
+}
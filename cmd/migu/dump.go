@@ -1,9 +1,15 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
-	"path"
+	"regexp"
+	"time"
 
 	"github.com/naoina/migu"
 	"github.com/naoina/migu/dialect"
@@ -19,13 +25,39 @@ func init() {
 			return dump.Execute(args, option)
 		},
 	}
-	dumpCmd.SetUsageTemplate(usageTemplate + "\nWith FILE, output to FILE.\n")
+	dumpCmd.Flags().StringVar(&dump.FromFile, "from-file", "", "Generate Go code from the SQL file such as the output of mysqldump instead of the database")
+	dumpCmd.Flags().DurationVar(&dump.IntrospectTimeout, "introspect-timeout", 0, "Abort reading the database schema if it takes longer than the duration (0 means no limit)")
+	dumpCmd.Flags().BoolVar(&dump.Partial, "partial", false, "Output the tables that have been read instead of nothing when --introspect-timeout is exceeded")
+	dumpCmd.Flags().BoolVar(&dump.ExcludeGenerated, "exclude-generated", false, "Exclude the generated columns")
+	dumpCmd.Flags().StringVar(&dump.ExcludeColumns, "exclude-columns", "", "Exclude the columns whose names match the regular expression")
+	dumpCmd.Flags().StringArrayVar(&dump.Tagged, "tagged", nil, "Output only the tables of Go's structs in the file of --models that are tagged with the tag (can be repeated)")
+	dumpCmd.Flags().StringVar(&dump.Models, "models", "", "The Go's structs FILE or DIRECTORY that declares the tags of the tables for --tagged")
+	dumpCmd.SetUsageTemplate(usageTemplate + "\nWith FILE, output to FILE.\nWith --from-file, DATABASE is omitted. When the file of --from-file is -, read standard input.\n")
 	rootCmd.AddCommand(dumpCmd)
 }
 
-type dump struct{}
+type dump struct {
+	FromFile          string
+	IntrospectTimeout time.Duration
+	Partial           bool
+	ExcludeGenerated  bool
+	ExcludeColumns    string
+	Tagged            []string
+	Models            string
 
-func (d *dump) Execute(args []string, opt *Option) error {
+	eol         string
+	tablePrefix string
+}
+
+func (d *dump) Execute(args []string, opt *Option) (err error) {
+	d.eol = opt.global.eol
+	d.tablePrefix = opt.global.tablePrefix
+	if len(d.Tagged) > 0 && d.Models == "" {
+		return fmt.Errorf("--tagged requires --models")
+	}
+	if d.FromFile != "" {
+		return d.executeFromFile(args, opt)
+	}
 	var dbname string
 	var filename string
 	switch len(args) {
@@ -38,36 +70,100 @@ func (d *dump) Execute(args []string, opt *Option) error {
 	default:
 		return fmt.Errorf("too many arguments")
 	}
-	var opts []dialect.Option
-	if columnTypes := opt.global.ColumnTypes; len(columnTypes) != 0 {
-		opts = append(opts, dialect.WithColumnType(columnTypes))
+	di, closer, err := newDialect(dbname, opt)
+	if err != nil {
+		return err
 	}
-	var di dialect.Dialect
-	switch typ := opt.global.DatabaseType; typ {
-	case databaseTypeMySQL, databaseTypeMariaDB:
-		db, err := openDatabase(dbname)
-		if err != nil {
-			return err
-		}
-		defer db.Close()
-		di = dialect.NewMySQL(db, opts...)
-	case databaseTypeSpanner:
-		di = dialect.NewSpanner(path.Join("projects", opt.spanner.Project, "instances", opt.spanner.Instance, "databases", dbname), opts...)
+	defer closeDialect(closer, &err)
+	return d.run(di, filename)
+}
+
+func (d *dump) executeFromFile(args []string, opt *Option) error {
+	var filename string
+	switch len(args) {
+	case 0:
+	case 1:
+		filename = args[0]
 	default:
-		return fmt.Errorf("BUG: unknown database type: %s", typ)
+		return fmt.Errorf("too many arguments")
+	}
+	di, err := newOfflineDialect(opt)
+	if err != nil {
+		return err
 	}
 	return d.run(di, filename)
 }
 
 func (d *dump) run(di dialect.Dialect, filename string) error {
-	out := os.Stdout
-	if filename != "" {
-		file, err := os.Create(filename)
+	if filename == "" {
+		return d.print(di, newEOLWriter(os.Stdout, d.eol))
+	}
+	// The file is written after the whole code is generated, so that the existing file is kept on errors.
+	var buf bytes.Buffer
+	if err := d.print(di, newEOLWriter(&buf, d.eol)); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, buf.Bytes(), 0644)
+}
+
+func (d *dump) print(di dialect.Dialect, out io.Writer) error {
+	opts, err := d.options()
+	if err != nil {
+		return err
+	}
+	if len(d.Tagged) > 0 {
+		tables, err := migu.TaggedTables(di, d.Models, nil, d.Tagged...)
 		if err != nil {
 			return err
 		}
-		defer file.Close()
-		out = file
+		opts = append(opts, migu.WithTables(tables...))
+	}
+	if d.FromFile != "" {
+		var src interface{}
+		fname := d.FromFile
+		if fname == "-" {
+			fname, src = "", os.Stdin
+		}
+		return migu.FprintSQL(out, di, fname, src, opts...)
+	}
+	ctx := context.Background()
+	if d.IntrospectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.IntrospectTimeout)
+		defer cancel()
+	}
+	if d.Partial {
+		opts = append(opts, migu.WithPartialResults())
+	}
+	err = migu.FprintContext(ctx, out, di, opts...)
+	var perr *migu.PartialResultError
+	if errors.As(err, &perr) {
+		fmt.Fprintf(os.Stderr, "-- warning: %s\n", perr)
+		return nil
+	}
+	return err
+}
+
+// options returns the options of the exclusions. The excluded columns are reported to the standard error output.
+func (d *dump) options() ([]migu.Option, error) {
+	var opts []migu.Option
+	if d.ExcludeGenerated {
+		opts = append(opts, migu.WithExcludeGeneratedColumns())
+	}
+	if d.ExcludeColumns != "" {
+		pattern, err := regexp.Compile(d.ExcludeColumns)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --exclude-columns: %w", err)
+		}
+		opts = append(opts, migu.WithExcludeColumns(pattern))
+	}
+	if len(opts) > 0 {
+		opts = append(opts, migu.WithExclusionReport(func(e migu.ColumnExclusion) {
+			fmt.Fprintf(os.Stderr, "-- excluded column %s.%s: %s\n", e.Table, e.Column, e.Reason)
+		}))
+	}
+	if d.tablePrefix != "" {
+		opts = append(opts, migu.WithTablePrefix(d.tablePrefix))
 	}
-	return migu.Fprint(out, di)
+	return opts, nil
 }
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+const (
+	eolLF   = "lf"
+	eolCRLF = "crlf"
+)
+
+// newEOLWriter returns the writer that writes the line endings of the generated files in eol.
+func newEOLWriter(w io.Writer, eol string) io.Writer {
+	if eol != eolCRLF {
+		return w
+	}
+	return &crlfWriter{w: w}
+}
+
+// crlfWriter converts LF to CRLF. CRLF that is already written is kept as it is.
+type crlfWriter struct {
+	w  io.Writer
+	cr bool
+}
+
+func (w *crlfWriter) Write(p []byte) (n int, err error) {
+	var b bytes.Buffer
+	for _, c := range p {
+		if c == '\n' && !w.cr {
+			b.WriteByte('\r')
+		}
+		b.WriteByte(c)
+		w.cr = c == '\r'
+	}
+	if _, err := w.w.Write(b.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// convertFileEOL rewrites the line endings of the generated file in eol.
+func convertFileEOL(filename, eol string) error {
+	if eol != eolCRLF {
+		return nil
+	}
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if _, err := newEOLWriter(&buf, eol).Write(b); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, buf.Bytes(), 0644)
+}
+
+func validateEOL(eol string) error {
+	switch eol {
+	case eolLF, eolCRLF:
+		return nil
+	}
+	return fmt.Errorf("unknown end of line: %s", eol)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// reconnectInterval is the interval before sync reconnects to the database after the connection is lost.
+const reconnectInterval = time.Second
+
+// hostPort is the address of a database server. The port is empty if not specified.
+type hostPort struct {
+	host string
+	port string
+}
+
+// addr returns the address in the form of host:port, or the host if the port is not specified.
+func (h hostPort) addr() string {
+	if h.port == "" {
+		return h.host
+	}
+	return net.JoinHostPort(h.host, h.port)
+}
+
+// resolveHosts returns the addresses of the database servers of --host in the order of failover.
+// The hosts are separated by commas, and each one may have its own port in the form of host:port. Otherwise, port is
+// used if it is greater than 0. The host that begins with an underscore such as _mysql._tcp.db.example.com is
+// looked up as the DNS SRV record, and expands to its targets in the order of the priority and the weight.
+func resolveHosts(hosts string, port int) ([]hostPort, error) {
+	defaultPort := ""
+	if port > 0 {
+		defaultPort = strconv.Itoa(port)
+	}
+	if hosts == "" {
+		return []hostPort{{port: defaultPort}}, nil
+	}
+	var addrs []hostPort
+	for _, h := range strings.Split(hosts, ",") {
+		h = strings.TrimSpace(h)
+		switch {
+		case h == "":
+			continue
+		case strings.HasPrefix(h, "_"):
+			_, srvs, err := net.LookupSRV("", "", h)
+			if err != nil {
+				return nil, fmt.Errorf("failed to look up the SRV record of %s: %w", h, err)
+			}
+			for _, srv := range srvs {
+				addrs = append(addrs, hostPort{
+					host: strings.TrimSuffix(srv.Target, "."),
+					port: strconv.Itoa(int(srv.Port)),
+				})
+			}
+		default:
+			if host, p, err := net.SplitHostPort(h); err == nil {
+				addrs = append(addrs, hostPort{host: host, port: p})
+			} else {
+				addrs = append(addrs, hostPort{host: h, port: defaultPort})
+			}
+		}
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no host is found in %q", hosts)
+	}
+	return addrs, nil
+}
+
+// isConnectionLost reports whether err is caused by the loss of the connection to the database server rather than
+// the statement.
+func isConnectionLost(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, mysql.ErrInvalidConn) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.As(err, &netErr)
+}
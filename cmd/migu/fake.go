@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/naoina/migu"
+	"github.com/naoina/migu/dialect"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	fake := &fake{}
+	fakeCmd := &cobra.Command{
+		Use:   "fake [OPTIONS] DATABASE [FILE|DIRECTORY]",
+		Short: "insert sample data into the database",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return fake.Execute(args, option)
+		},
+	}
+	fakeCmd.Flags().IntVar(&fake.Rows, "rows", 100, "Number of rows to insert into each table")
+	fakeCmd.Flags().Int64Var(&fake.Seed, "seed", 1, "Seed of the random values. The same seed generates the same data")
+	fakeCmd.Flags().BoolVar(&fake.DryRun, "dry-run", false, "Output the INSERT statements without executing")
+	fakeCmd.Flags().BoolVarP(&fake.Quiet, "quiet", "q", false, "")
+	fakeCmd.SetUsageTemplate(usageTemplate + "\nWith no FILE, or when FILE is -, read standard input.\n")
+	rootCmd.AddCommand(fakeCmd)
+}
+
+type fake struct {
+	Rows   int
+	Seed   int64
+	DryRun bool
+	Quiet  bool
+
+	tablePrefix string
+}
+
+func (f *fake) Execute(args []string, opt *Option) (err error) {
+	f.tablePrefix = opt.global.tablePrefix
+	var dbname string
+	var file string
+	switch len(args) {
+	case 0:
+		return fmt.Errorf("too few arguments")
+	case 1:
+		dbname = args[0]
+	case 2:
+		dbname, file = args[0], args[1]
+	default:
+		return fmt.Errorf("too many arguments")
+	}
+	if f.Rows < 0 {
+		return fmt.Errorf("--rows must be greater than or equal to 0")
+	}
+	switch opt.global.DatabaseType {
+	case databaseTypeMySQL, databaseTypeMariaDB:
+	default:
+		return fmt.Errorf("fake is not supported by database type %s", opt.global.DatabaseType)
+	}
+	d, closer, err := newDialect(dbname, opt)
+	if err != nil {
+		return err
+	}
+	defer closeDialect(closer, &err)
+	return f.run(d, file)
+}
+
+func (f *fake) run(d dialect.Dialect, file string) error {
+	var src interface{}
+	switch file {
+	case "", "-":
+		file = ""
+		src = os.Stdin
+	}
+	var opts []migu.Option
+	if f.tablePrefix != "" {
+		opts = append(opts, migu.WithTablePrefix(f.tablePrefix))
+	}
+	sqls, err := migu.FakeDataSQL(d, file, src, f.Rows, f.Seed, opts...)
+	if err != nil {
+		return err
+	}
+	if f.DryRun {
+		for _, sql := range sqls {
+			fmt.Printf("%s;\n", sql)
+		}
+		return nil
+	}
+	tx, err := d.Begin()
+	if err != nil {
+		return err
+	}
+	for _, sql := range sqls {
+		if err := tx.Exec(sql); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	if !f.Quiet {
+		fmt.Printf("inserted %d row(s) into each table by %d statement(s)\n", f.Rows, len(sqls))
+	}
+	return nil
+}
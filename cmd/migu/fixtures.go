@@ -0,0 +1,128 @@
+package main
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/naoina/migu"
+	"github.com/naoina/migu/dialect"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	fixtures := &fixtures{}
+	fixturesCmd := &cobra.Command{
+		Use:   "fixtures",
+		Short: "record and replay the introspection queries for the development of dialects",
+	}
+	recordCmd := &cobra.Command{
+		Use:   "record [OPTIONS] DATABASE FIXTURE [FILE|DIRECTORY]",
+		Short: "record the queries to read the database schema and their results into the fixture",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return fixtures.Record(args, option)
+		},
+	}
+	recordCmd.SetUsageTemplate(usageTemplate + "\nThe queries of dump are recorded. With FILE, the queries of diff against FILE are also recorded.\n")
+	replayCmd := &cobra.Command{
+		Use:   "replay [OPTIONS] FIXTURE [FILE|DIRECTORY]",
+		Short: "run dump or diff against the fixture instead of the database",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return fixtures.Replay(args, option)
+		},
+	}
+	replayCmd.SetUsageTemplate(usageTemplate + "\nWith no FILE, output Go code like dump. With FILE, output SQLs like diff.\n")
+	fixturesCmd.AddCommand(recordCmd, replayCmd)
+	rootCmd.AddCommand(fixturesCmd)
+}
+
+type fixtures struct{}
+
+func (f *fixtures) Record(args []string, opt *Option) (err error) {
+	var dbname, fixture, file string
+	switch len(args) {
+	case 0, 1:
+		return fmt.Errorf("too few arguments")
+	case 2:
+		dbname, fixture = args[0], args[1]
+	case 3:
+		dbname, fixture, file = args[0], args[1], args[2]
+	default:
+		return fmt.Errorf("too many arguments")
+	}
+	var recorder *dialect.FixtureRecorder
+	opt.global.wrapConnector = func(c driver.Connector) driver.Connector {
+		recorder = dialect.NewFixtureRecorder(c)
+		return recorder
+	}
+	d, closer, err := newDialect(dbname, opt)
+	if err != nil {
+		return err
+	}
+	defer closeDialect(closer, &err)
+	if recorder == nil {
+		return fmt.Errorf("fixtures are not supported by database type %s", opt.global.DatabaseType)
+	}
+	if err := migu.Fprint(ioutil.Discard, d); err != nil {
+		return err
+	}
+	if file != "" {
+		if _, err := migu.DiffChanges(d, file, nil); err != nil {
+			return err
+		}
+	}
+	out, err := os.Create(fixture)
+	if err != nil {
+		return err
+	}
+	if err := recorder.Fixture().Write(out); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func (f *fixtures) Replay(args []string, opt *Option) error {
+	var fixture, file string
+	switch len(args) {
+	case 0:
+		return fmt.Errorf("too few arguments")
+	case 1:
+		fixture = args[0]
+	case 2:
+		fixture, file = args[0], args[1]
+	default:
+		return fmt.Errorf("too many arguments")
+	}
+	in, err := os.Open(fixture)
+	if err != nil {
+		return err
+	}
+	fx, err := dialect.ReadFixture(in)
+	in.Close()
+	if err != nil {
+		return err
+	}
+	db := sql.OpenDB(fx.Connector())
+	defer db.Close()
+	d, err := newDialectWithDB(db, opt)
+	if err != nil {
+		return err
+	}
+	out := newEOLWriter(os.Stdout, opt.global.eol)
+	if file == "" {
+		return migu.Fprint(out, d)
+	}
+	changes, err := migu.DiffChanges(d, file, nil)
+	if err != nil {
+		return err
+	}
+	for _, c := range changes {
+		for _, sql := range c.SQLs {
+			fmt.Fprintf(out, "%s;\n", sql)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/naoina/migu"
+	"github.com/naoina/migu/dialect"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	fleet := &fleet{}
+	fleetCmd := &cobra.Command{
+		Use:   "fleet [OPTIONS] [FILE|DIRECTORY]",
+		Short: "synchronize the database schemas of many environments in parallel",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return fleet.Execute(args, option)
+		},
+	}
+	fleetCmd.Flags().StringArrayVar(&fleet.Envs, "env", nil, "The environment in the config file to synchronize (can be repeated; default all environments)")
+	fleetCmd.Flags().IntVar(&fleet.Parallel, "parallel", 4, "The maximum number of the environments that are synchronized at the same time")
+	fleetCmd.Flags().Float64Var(&fleet.MaxErrorRate, "max-error-rate", 0, "Stop starting the environments once the ratio of the failed ones exceeds the value in the range of 0 to 1 (0 means stopping at the first failure)")
+	fleetCmd.Flags().StringVar(&fleet.Canary, "canary", "", "The environment that is synchronized alone before the others, which are skipped if it fails")
+	fleetCmd.Flags().DurationVar(&fleet.Bake, "bake", 0, "Wait for the duration after synchronizing the canary before the others (requires --canary)")
+	fleetCmd.Flags().StringVar(&fleet.BakeCheck, "bake-check", "", "Run the command by the shell after the bake, and skip the others if it fails (requires --canary)")
+	fleetCmd.Flags().BoolVar(&fleet.DryRun, "dry-run", false, "")
+	fleetCmd.Flags().BoolVarP(&fleet.Quiet, "quiet", "q", false, "")
+	fleetCmd.Flags().BoolVar(&fleet.AllowDestructive, "allow-destructive", false, "Apply the changes that lose the data such as DROP TABLE, DROP COLUMN and the narrowings of the column types")
+	fleetCmd.Flags().StringVar(&fleet.ReportFile, "report-file", "", "Write the consolidated report of all environments into the file in JSON")
+	fleet.diffOption.addFlags(fleetCmd.Flags())
+	fleetCmd.SetUsageTemplate(usageTemplate + "\nWith no FILE, or when FILE is -, read standard input.\nEach environment is synchronized in its own transaction, and the progress lines are prefixed by the name of the environment.\nWith --canary, the others are started after the canary succeeds, --bake passes and --bake-check exits with 0.\n")
+	rootCmd.AddCommand(fleetCmd)
+}
+
+type fleet struct {
+	diffOption
+
+	Envs             []string
+	Parallel         int
+	MaxErrorRate     float64
+	Canary           string
+	Bake             time.Duration
+	BakeCheck        string
+	DryRun           bool
+	Quiet            bool
+	AllowDestructive bool
+	ReportFile       string
+}
+
+func (f *fleet) Execute(args []string, opt *Option) error {
+	var file string
+	switch len(args) {
+	case 0:
+	case 1:
+		file = args[0]
+	default:
+		return fmt.Errorf("too many arguments")
+	}
+	if f.Parallel < 1 {
+		return fmt.Errorf("--parallel must be positive: %d", f.Parallel)
+	}
+	if f.MaxErrorRate < 0 || f.MaxErrorRate > 1 {
+		return fmt.Errorf("--max-error-rate must be in the range of 0 to 1: %v", f.MaxErrorRate)
+	}
+	if f.Canary == "" && (f.Bake != 0 || f.BakeCheck != "") {
+		return fmt.Errorf("--bake and --bake-check require --canary")
+	}
+	f.diffOption.budget = opt.global.Config.Budget
+	f.diffOption.comparison = opt.global.Config.Comparison
+	f.diffOption.costOrder = opt.global.Config.CostOrder
+	f.diffOption.tablePrefix = opt.global.tablePrefix
+	if err := f.diffOption.validate(); err != nil {
+		return err
+	}
+	targets, err := f.targets(opt)
+	if err != nil {
+		return err
+	}
+	var src interface{}
+	switch file {
+	case "", "-":
+		file = ""
+		if src, err = ioutil.ReadAll(os.Stdin); err != nil {
+			return err
+		}
+	}
+	marker := ""
+	if f.DryRun {
+		marker = "dry-run "
+	}
+	fl := &migu.Fleet{
+		Parallelism:  f.Parallel,
+		MaxErrorRate: f.MaxErrorRate,
+		DryRun:       f.DryRun,
+		Canary:       f.Canary,
+		Bake:         f.bake,
+		Check: func(t *migu.FleetTarget, d dialect.Dialect, changes []*migu.Change) error {
+			return f.check(t, changes, opt)
+		},
+		OnEvent: func(t *migu.FleetTarget, e migu.Event) {
+			switch e := e.(type) {
+			case *migu.PlanComputed:
+				f.printf("[%s] --------%s%d change(s) planned--------\n", t.Name, marker, len(e.Changes))
+				if f.DryRun {
+					for _, c := range e.Changes {
+						for _, sql := range c.SQLs {
+							f.printf("%s\n", prefixLines(t.Name, sql))
+						}
+					}
+				}
+			case *migu.StatementStarted:
+				f.printf("[%s] --------applying--------\n%s\n", t.Name, prefixLines(t.Name, e.SQL))
+			case *migu.StatementFinished:
+				if e.Err == nil {
+					f.printf("[%s] --------done %.3fs--------\n", t.Name, e.Duration.Seconds())
+				}
+			case *migu.Warning:
+				fmt.Fprintf(os.Stderr, "-- warning: [%s] %s\n", t.Name, e.Message)
+			}
+		},
+		OnDone: func(t *migu.FleetTarget, r *migu.FleetTargetReport) {
+			switch r.Status {
+			case migu.TargetFailed:
+				fmt.Fprintf(os.Stderr, "[%s] --------failed: %s--------\n", t.Name, r.Report.Error)
+			case migu.TargetSkipped:
+				f.printf("[%s] --------skipped--------\n", t.Name)
+			default:
+				f.printf("[%s] --------%ssucceeded--------\n", t.Name, marker)
+			}
+		},
+	}
+	report, err := fl.SyncContext(context.Background(), targets, file, src, append(f.options(), migu.WithRedactor(opt.global.redactor))...)
+	if report == nil {
+		return err
+	}
+	opt.global.redactor.RedactFleetReport(report)
+	f.printf("--------%s%d succeeded, %d failed, %d skipped--------\n", marker, report.Succeeded, report.Failed, report.Skipped)
+	if f.ReportFile != "" {
+		if werr := migu.WriteFleetReport(f.ReportFile, report); err == nil {
+			err = werr
+		}
+	}
+	return err
+}
+
+// targets returns the environments to synchronize in the order of the names.
+func (f *fleet) targets(opt *Option) ([]*migu.FleetTarget, error) {
+	names := f.Envs
+	if len(names) == 0 {
+		for name := range opt.global.Config.Environments {
+			names = append(names, name)
+		}
+		if len(names) == 0 {
+			return nil, fmt.Errorf("no environments in the config file")
+		}
+	}
+	sort.Strings(names)
+	targets := make([]*migu.FleetTarget, 0, len(names))
+	for i, name := range names {
+		if i > 0 && names[i-1] == name {
+			return nil, fmt.Errorf("duplicate environment: %s", name)
+		}
+		env, err := opt.global.Config.environment(name)
+		if err != nil {
+			return nil, err
+		}
+		if env.Database == "" {
+			return nil, fmt.Errorf("database of environment %s is not specified", name)
+		}
+		dbname := env.Database
+		targets = append(targets, &migu.FleetTarget{
+			Name:     name,
+			Database: dbname,
+			Open: func() (dialect.Dialect, func() error, error) {
+				return newDialect(dbname, opt)
+			},
+		})
+	}
+	return targets, nil
+}
+
+// check refuses the destructive changes unless --allow-destructive, and the destructive changes to the protected
+// environments unless --yes-i-mean-it because they cannot be confirmed on the terminal in parallel.
+func (f *fleet) check(t *migu.FleetTarget, changes []*migu.Change, opt *Option) error {
+	if f.DryRun {
+		return nil
+	}
+	if !f.AllowDestructive {
+		if blocked := destructiveChanges(changes); len(blocked) > 0 {
+			return fmt.Errorf("refusing to apply %d destructive change(s); use --allow-destructive to apply them anyway", len(blocked))
+		}
+	}
+	if env := opt.global.Config.Environments[t.Name]; env.Protected && !opt.global.yesIMeanIt {
+		if destructives := destructiveChanges(changes); len(destructives) > 0 {
+			return fmt.Errorf("environment %s is protected; use --yes-i-mean-it to apply %d destructive change(s)", t.Name, len(destructives))
+		}
+	}
+	return nil
+}
+
+// bake waits for --bake after synchronizing the canary, and runs --bake-check.
+// The name and the database of the canary are passed to the command by the environment variables MIGU_ENV and
+// MIGU_DATABASE.
+func (f *fleet) bake(ctx context.Context, t *migu.FleetTarget) error {
+	if f.Bake > 0 {
+		f.printf("[%s] --------baking %s--------\n", t.Name, f.Bake)
+		select {
+		case <-time.After(f.Bake):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if f.BakeCheck != "" {
+		f.printf("[%s] --------checking--------\n", t.Name)
+		cmd := exec.CommandContext(ctx, "sh", "-c", f.BakeCheck)
+		cmd.Env = append(os.Environ(),
+			"MIGU_ENV="+t.Name,
+			"MIGU_DATABASE="+t.Database,
+		)
+		cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s: %w", f.BakeCheck, err)
+		}
+	}
+	f.printf("[%s] --------baked--------\n", t.Name)
+	return nil
+}
+
+// prefixLines prefixes every line of s by the name of the environment so that the lines of the environments can be
+// told apart when they are interleaved.
+func prefixLines(name, s string) string {
+	prefix := "[" + name + "] "
+	return prefix + strings.Replace(s, "\n", "\n"+prefix, -1)
+}
+
+func (f *fleet) printf(format string, a ...interface{}) (int, error) {
+	if f.Quiet {
+		return 0, nil
+	}
+	return fmt.Printf(format, a...)
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+
+	"github.com/naoina/migu"
+	"github.com/naoina/migu/dialect"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	freeze := &freeze{}
+	freezeCmd := &cobra.Command{
+		Use:   "freeze [OPTIONS] --reason REASON DATABASE",
+		Short: "block the schema changes of the database until unfreeze",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return freeze.Execute(args, option)
+		},
+	}
+	freezeCmd.Flags().StringVar(&freeze.Reason, "reason", "", "The reason of the freeze that is shown to the blocked commands")
+	freezeCmd.Flags().StringVar(&freeze.By, "by", "", "The name of who freezes the database (default the current user)")
+	freezeCmd.SetUsageTemplate(usageTemplate + "\nThe freeze marker is recorded in the " + migu.FreezeTable + " table, and sync, apply, rollback, convert-charset and reset refuse to apply the changes while it exists.\n")
+	rootCmd.AddCommand(freezeCmd)
+
+	unfreeze := &unfreeze{}
+	unfreezeCmd := &cobra.Command{
+		Use:   "unfreeze [OPTIONS] DATABASE",
+		Short: "allow the schema changes of the database that is frozen by freeze",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return unfreeze.Execute(args, option)
+		},
+	}
+	unfreezeCmd.SetUsageTemplate(usageTemplate)
+	rootCmd.AddCommand(unfreezeCmd)
+}
+
+type freeze struct {
+	Reason string
+	By     string
+}
+
+func (f *freeze) Execute(args []string, opt *Option) (err error) {
+	switch len(args) {
+	case 0:
+		return fmt.Errorf("too few arguments")
+	case 1:
+	default:
+		return fmt.Errorf("too many arguments")
+	}
+	if f.Reason == "" {
+		return fmt.Errorf("--reason is required")
+	}
+	by := f.By
+	if by == "" {
+		u, err := user.Current()
+		if err != nil {
+			return fmt.Errorf("cannot get the current user; use --by: %w", err)
+		}
+		by = u.Username
+	}
+	d, closer, err := newDialect(args[0], opt)
+	if err != nil {
+		return err
+	}
+	defer closeDialect(closer, &err)
+	changes, err := migu.FreezeChanges(d, f.Reason, by)
+	if err != nil {
+		return err
+	}
+	if err := execChanges(d, changes); err != nil {
+		return err
+	}
+	fmt.Printf("--------froze database %s--------\n", args[0])
+	return nil
+}
+
+type unfreeze struct{}
+
+func (u *unfreeze) Execute(args []string, opt *Option) (err error) {
+	switch len(args) {
+	case 0:
+		return fmt.Errorf("too few arguments")
+	case 1:
+	default:
+		return fmt.Errorf("too many arguments")
+	}
+	d, closer, err := newDialect(args[0], opt)
+	if err != nil {
+		return err
+	}
+	defer closeDialect(closer, &err)
+	changes, err := migu.UnfreezeChanges(d)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		fmt.Fprintf(os.Stderr, "-- warning: database %s is not frozen\n", args[0])
+		return nil
+	}
+	if err := execChanges(d, changes); err != nil {
+		return err
+	}
+	fmt.Printf("--------unfroze database %s--------\n", args[0])
+	return nil
+}
+
+// execChanges applies the changes in a transaction.
+func execChanges(d dialect.Dialect, changes []*migu.Change) error {
+	tx, err := d.Begin()
+	if err != nil {
+		return err
+	}
+	for _, c := range changes {
+		for _, sql := range c.SQLs {
+			if err := tx.Exec(sql); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/naoina/migu"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	generate := &generate{}
+	generateCmd := &cobra.Command{
+		Use:   "generate [OPTIONS] DATABASE NAME [FILE|DIRECTORY]",
+		Short: "write the versioned migration files of the schema changes without applying",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return generate.Execute(args, option)
+		},
+	}
+	generateCmd.Flags().StringVarP(&generate.Dir, "dir", "d", "migrations", "The directory to write the migration files into")
+	generateCmd.Flags().BoolVar(&generate.Seq, "seq", false, "Use the sequential version such as 0001 instead of the timestamp")
+	generateCmd.Flags().StringVar(&generate.Format, "format", generateFormatGolangMigrate, "The format of the migration files (golang-migrate|sql-migrate|goose|flyway|liquibase-xml|liquibase-yaml)")
+	generate.diffOption.addFlags(generateCmd.Flags())
+	generateCmd.SetUsageTemplate(usageTemplate + "\nNAME is the description of the migration such as add_user_age.\n" +
+		"VERSION_NAME.up.sql has the changes from the database to FILE, and VERSION_NAME.down.sql has the changes to revert them.\n" +
+		"With --format sql-migrate or goose, VERSION_NAME.sql has both of them in the Up and Down sections.\n" +
+		"With --format flyway, VVERSION__NAME.sql has the changes and UVERSION__NAME.sql is the undo migration.\n" +
+		"With --format liquibase-xml or liquibase-yaml, VERSION_NAME.xml or VERSION_NAME.yaml is the changelog that has\n" +
+		"a changeSet of the changes and their rollback.\n" +
+		"With no FILE, or when FILE is -, read standard input.\n")
+	rootCmd.AddCommand(generateCmd)
+}
+
+const (
+	generateFormatGolangMigrate = "golang-migrate"
+	generateFormatSQLMigrate    = "sql-migrate"
+	generateFormatGoose         = "goose"
+	generateFormatFlyway        = "flyway"
+	generateFormatLiquibaseXML  = "liquibase-xml"
+	generateFormatLiquibaseYAML = "liquibase-yaml"
+)
+
+type generate struct {
+	diffOption
+
+	Dir    string
+	Seq    bool
+	Format string
+
+	eol string
+}
+
+func (g *generate) Execute(args []string, opt *Option) (err error) {
+	var dbname, name, file string
+	switch len(args) {
+	case 0, 1:
+		return fmt.Errorf("too few arguments")
+	case 2:
+		dbname, name = args[0], args[1]
+	case 3:
+		dbname, name, file = args[0], args[1], args[2]
+	default:
+		return fmt.Errorf("too many arguments")
+	}
+	if name = migrationName(name); name == "" {
+		return fmt.Errorf("NAME must contain letters or digits")
+	}
+	switch g.Format {
+	case generateFormatGolangMigrate, generateFormatSQLMigrate, generateFormatGoose, generateFormatFlyway, generateFormatLiquibaseXML, generateFormatLiquibaseYAML:
+	default:
+		return fmt.Errorf("unknown format: %s", g.Format)
+	}
+	g.diffOption.budget = opt.global.Config.Budget
+	g.diffOption.comparison = opt.global.Config.Comparison
+	g.diffOption.costOrder = opt.global.Config.CostOrder
+	g.diffOption.tablePrefix = opt.global.tablePrefix
+	if err := g.diffOption.validate(); err != nil {
+		return err
+	}
+	g.eol = opt.global.eol
+	var src interface{}
+	switch file {
+	case "", "-":
+		// Read all at once since it is read twice for up and down.
+		file = ""
+		b, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		src = b
+	}
+	d, closer, err := newDialect(dbname, opt)
+	if err != nil {
+		return err
+	}
+	defer closeDialect(closer, &err)
+	up, err := migu.DiffChanges(d, file, src, g.options()...)
+	if err != nil {
+		return err
+	}
+	// The settings of the database are altered first as sync does, and they are restored last by the down migration.
+	databaseUp, err := migu.DatabaseChanges(d, opt.global.Config.Database)
+	if err != nil {
+		return err
+	}
+	up = append(databaseUp, up...)
+	for _, w := range degradationWarnings(d) {
+		fmt.Fprintf(os.Stderr, "-- warning: %s\n", w)
+	}
+	if len(up) == 0 {
+		fmt.Fprintln(os.Stderr, "-- no changes")
+		return nil
+	}
+	down, err := migu.DiffStructsToDatabase(d, file, src, g.options()...)
+	if err != nil {
+		return err
+	}
+	databaseDown, err := migu.DatabaseRevertChanges(d, opt.global.Config.Database)
+	if err != nil {
+		return err
+	}
+	down = append(down, databaseDown...)
+	if err := os.MkdirAll(g.Dir, 0755); err != nil {
+		return err
+	}
+	version, err := g.version()
+	if err != nil {
+		return err
+	}
+	files, err := g.write(version, name, up, down)
+	if err != nil {
+		return err
+	}
+	for _, filename := range files {
+		fmt.Println(filename)
+	}
+	return nil
+}
+
+// write writes the migration files of the version and the name in the format, and returns their names.
+func (g *generate) write(version, name string, up, down []*migu.Change) ([]string, error) {
+	prefix := filepath.Join(g.Dir, version+"_"+name)
+	switch g.Format {
+	case generateFormatSQLMigrate, generateFormatGoose:
+		// Both of them split the statements by semicolons except between StatementBegin and StatementEnd.
+		annotation := "-- +migrate"
+		if g.Format == generateFormatGoose {
+			annotation = "-- +goose"
+		}
+		filename := prefix + ".sql"
+		begin, end := annotation+" StatementBegin", annotation+" StatementEnd"
+		return []string{filename}, g.writeFile(filename, func(w io.Writer) error {
+			if _, err := fmt.Fprintln(w, annotation+" Up"); err != nil {
+				return err
+			}
+			if err := writeMigration(w, up, begin, end); err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintln(w, "\n"+annotation+" Down"); err != nil {
+				return err
+			}
+			return writeMigration(w, down, begin, end)
+		})
+	case generateFormatFlyway:
+		// Flyway runs each file as it is, and the undo migrations are run by `flyway undo`.
+		return g.writePair(filepath.Join(g.Dir, "V"+version+"__"+name+".sql"), filepath.Join(g.Dir, "U"+version+"__"+name+".sql"), up, down)
+	case generateFormatLiquibaseXML, generateFormatLiquibaseYAML:
+		filename := prefix + ".xml"
+		marshal := marshalLiquibaseXML
+		if g.Format == generateFormatLiquibaseYAML {
+			filename, marshal = prefix+".yaml", marshalLiquibaseYAML
+		}
+		b, err := marshal(version+"_"+name, up, down)
+		if err != nil {
+			return nil, err
+		}
+		return []string{filename}, g.writeFile(filename, func(w io.Writer) error {
+			_, err := w.Write(b)
+			return err
+		})
+	default:
+		// golang-migrate runs each file as it is.
+		return g.writePair(prefix+".up.sql", prefix+".down.sql", up, down)
+	}
+}
+
+// writePair writes the up and the down migration files, and returns their names. The up file is removed if the down
+// file cannot be written, so that the migration is not applied without the way to revert it.
+func (g *generate) writePair(upFile, downFile string, up, down []*migu.Change) ([]string, error) {
+	if err := g.writeFile(upFile, func(w io.Writer) error {
+		return writeMigration(w, up, "", "")
+	}); err != nil {
+		return nil, err
+	}
+	if err := g.writeFile(downFile, func(w io.Writer) error {
+		return writeMigration(w, down, "", "")
+	}); err != nil {
+		os.Remove(upFile)
+		return nil, err
+	}
+	return []string{upFile, downFile}, nil
+}
+
+var migrationVersionRegexp = regexp.MustCompile(`^[VU]?(\d+)_`)
+
+// version returns the version of the new migration. It is the next number of the latest version in the directory
+// with --seq, otherwise the current time in UTC such as 20060102150405.
+func (g *generate) version() (string, error) {
+	if !g.Seq {
+		return time.Now().UTC().Format("20060102150405"), nil
+	}
+	files, err := ioutil.ReadDir(g.Dir)
+	if err != nil {
+		return "", err
+	}
+	var latest uint64
+	for _, f := range files {
+		m := migrationVersionRegexp.FindStringSubmatch(f.Name())
+		if m == nil {
+			continue
+		}
+		if n, err := strconv.ParseUint(m[1], 10, 64); err == nil && n > latest {
+			latest = n
+		}
+	}
+	return fmt.Sprintf("%04d", latest+1), nil
+}
+
+// writeFile creates the new file and writes the content by fn. It does not overwrite the existing file, and the new
+// file is removed if the content cannot be written.
+func (g *generate) writeFile(filename string, fn func(w io.Writer) error) error {
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	err = fn(newEOLWriter(f, g.eol))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(filename)
+	}
+	return err
+}
+
+// writeMigration writes the SQLs of the changes with the descriptions of the changes.
+// The SQL that contains semicolons is enclosed by the begin and the end lines if they are given, so that the tool
+// does not split it.
+func writeMigration(w io.Writer, changes []*migu.Change, begin, end string) error {
+	for _, c := range changes {
+		if _, err := fmt.Fprintf(w, "-- %s\n", describeChange(c)); err != nil {
+			return err
+		}
+		for _, sql := range c.SQLs {
+			format := "%s;\n"
+			if begin != "" && strings.Contains(sql, ";") {
+				format = begin + "\n%s;\n" + end + "\n"
+			}
+			if _, err := fmt.Fprintf(w, format, sql); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+var nonWordRegexp = regexp.MustCompile(`[^a-z0-9]+`)
+
+// migrationName returns the name of the migration for the file name such as add_user_age.
+func migrationName(name string) string {
+	return strings.Trim(nonWordRegexp.ReplaceAllString(strings.ToLower(name), "_"), "_")
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/naoina/migu"
+)
+
+func TestMigrationName(t *testing.T) {
+	for _, v := range []struct {
+		name   string
+		expect string
+	}{
+		{"add_user_age", "add_user_age"},
+		{"Add user age", "add_user_age"},
+		{"  add--user.age!  ", "add_user_age"},
+		{"ユーザー", ""},
+		{"---", ""},
+	} {
+		if actual := migrationName(v.name); actual != v.expect {
+			t.Errorf("migrationName(%q) => %q; want %q", v.name, actual, v.expect)
+		}
+	}
+}
+
+func TestGenerateVersion(t *testing.T) {
+	dir, err := ioutil.TempDir("", "migu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	g := &generate{Dir: dir, Seq: true}
+	for _, v := range []struct {
+		files  []string
+		expect string
+	}{
+		{nil, "0001"},
+		{[]string{"0001_create_user.up.sql", "0001_create_user.down.sql"}, "0002"},
+		{[]string{"V0009__add_age.sql", "U0009__add_age.sql", "README.md"}, "0010"},
+		{[]string{"20200102150405_legacy.up.sql"}, "20200102150406"},
+	} {
+		for _, name := range v.files {
+			if err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		actual, err := g.version()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != v.expect {
+			t.Errorf("version() with %q => %q; want %q", v.files, actual, v.expect)
+		}
+	}
+	g.Seq = false
+	actual, err := g.version()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actual) != len("20060102150405") {
+		t.Errorf("version() without --seq => %q; want the timestamp", actual)
+	}
+}
+
+func TestWriteMigration(t *testing.T) {
+	changes := []*migu.Change{
+		{Kind: migu.CreateTable, Table: "user", SQLs: []string{"CREATE TABLE `user` (\n  `id` BIGINT NOT NULL\n)"}},
+		{Kind: migu.AddColumn, Table: "user", Column: "age", SQLs: []string{"ALTER TABLE `user` ADD `age` INT NOT NULL", "CREATE TRIGGER `t` BEGIN SET @a = 1; END"}},
+	}
+	for _, v := range []struct {
+		begin, end string
+		expect     string
+	}{
+		{"", "", "-- create_table user\n" +
+			"CREATE TABLE `user` (\n  `id` BIGINT NOT NULL\n);\n" +
+			"-- add_column user.age\n" +
+			"ALTER TABLE `user` ADD `age` INT NOT NULL;\n" +
+			"CREATE TRIGGER `t` BEGIN SET @a = 1; END;\n"},
+		{"-- +goose StatementBegin", "-- +goose StatementEnd", "-- create_table user\n" +
+			"CREATE TABLE `user` (\n  `id` BIGINT NOT NULL\n);\n" +
+			"-- add_column user.age\n" +
+			"ALTER TABLE `user` ADD `age` INT NOT NULL;\n" +
+			"-- +goose StatementBegin\n" +
+			"CREATE TRIGGER `t` BEGIN SET @a = 1; END;\n" +
+			"-- +goose StatementEnd\n"},
+	} {
+		var buf bytes.Buffer
+		if err := writeMigration(&buf, changes, v.begin, v.end); err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(buf.String(), v.expect); diff != "" {
+			t.Errorf("(-got +want)\n%v", diff)
+		}
+	}
+}
+
+func TestGenerateWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "migu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	up := []*migu.Change{{Kind: migu.CreateTable, Table: "user", SQLs: []string{"CREATE TABLE `user` (\n  `id` BIGINT NOT NULL\n)"}}}
+	down := []*migu.Change{{Kind: migu.DropTable, Table: "user", SQLs: []string{"DROP TABLE `user`"}}}
+	g := &generate{Dir: dir, Format: generateFormatGolangMigrate}
+	files, err := g.write("0001", "create_user", up, down)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []string{filepath.Join(dir, "0001_create_user.up.sql"), filepath.Join(dir, "0001_create_user.down.sql")}
+	if diff := cmp.Diff(files, expect); diff != "" {
+		t.Errorf("(-got +want)\n%v", diff)
+	}
+	b, err := ioutil.ReadFile(expect[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(string(b), "-- drop_table user\nDROP TABLE `user`;\n"); diff != "" {
+		t.Errorf("(-got +want)\n%v", diff)
+	}
+
+	// The up file is removed if the down file cannot be written.
+	if err := ioutil.WriteFile(filepath.Join(dir, "0002_add_age.down.sql"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.write("0002", "add_age", up, down); err == nil {
+		t.Errorf("write() with the existing down file returns nil; want error")
+	}
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, info := range infos {
+		names = append(names, info.Name())
+	}
+	sort.Strings(names)
+	if diff := cmp.Diff(names, []string{"0001_create_user.down.sql", "0001_create_user.up.sql", "0002_add_age.down.sql"}); diff != "" {
+		t.Errorf("(-got +want)\n%v", diff)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// gitCommit returns the SHA of the last commit of the model file or directory.
+// It returns an error if the file is not tracked by git or has uncommitted changes, because then the commit does not
+// describe the model that the plan is made from.
+func gitCommit(path string) (string, error) {
+	if path == "" || path == "-" {
+		return "", fmt.Errorf("the model file from the standard input is not tracked by git")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	dir, target := filepath.Dir(path), filepath.Base(path)
+	if info.IsDir() {
+		dir, target = path, "."
+	}
+	status, err := git(dir, "status", "--porcelain", "--", target)
+	if err != nil {
+		return "", err
+	}
+	if status != "" {
+		return "", fmt.Errorf("%s has uncommitted changes", path)
+	}
+	sha, err := git(dir, "log", "-1", "--format=%H", "--", target)
+	if err != nil {
+		return "", err
+	}
+	if sha == "" {
+		return "", fmt.Errorf("%s is not committed to git", path)
+	}
+	return sha, nil
+}
+
+// gitModel writes the model file or the Go files of the model directory at the revision into dir, and returns the
+// path to be read by migu. If the path does not exist at the revision, the empty dir is returned as the model without
+// any table.
+func gitModel(rev, path, dir string) (string, error) {
+	if _, err := git(".", "rev-parse", "--verify", "--quiet", rev+"^{commit}"); err != nil {
+		return "", fmt.Errorf("unknown revision: %s", rev)
+	}
+	spec := rev + ":./" + filepath.ToSlash(path)
+	typ, err := git(".", "cat-file", "-t", spec)
+	if err != nil {
+		return dir, nil
+	}
+	var names []string
+	model := dir
+	switch typ {
+	case "blob":
+		names = []string{path}
+		model = filepath.Join(dir, filepath.Base(path))
+	case "tree":
+		out, err := git(".", "ls-tree", "--name-only", rev, "./"+filepath.ToSlash(path)+"/")
+		if err != nil {
+			return "", err
+		}
+		for _, name := range strings.Split(out, "\n") {
+			if strings.HasSuffix(name, ".go") {
+				names = append(names, name)
+			}
+		}
+	default:
+		return "", fmt.Errorf("%s is not a file or a directory at %s", path, rev)
+	}
+	for _, name := range names {
+		src, err := git(".", "show", rev+":./"+filepath.ToSlash(name))
+		if err != nil {
+			return "", err
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, filepath.Base(name)), []byte(src+"\n"), 0644); err != nil {
+			return "", err
+		}
+	}
+	return model, nil
+}
+
+func git(dir string, args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/naoina/migu"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	gitdiff := &gitdiff{}
+	gitdiffCmd := &cobra.Command{
+		Use:   "gitdiff [OPTIONS] REV1..REV2 [FILE|DIRECTORY]",
+		Short: "output the schema changes between the Go's structs at two git revisions",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return gitdiff.Execute(args, option)
+		},
+	}
+	gitdiffCmd.Flags().StringVar(&gitdiff.Delimiter, "delimiter", ";", "Statement terminator appended to each SQL")
+	gitdiffCmd.Flags().BoolVar(&gitdiff.SQLOnly, "sql-only", false, "Output only SQLs without the comments to describe the changes")
+	gitdiffCmd.Flags().StringVar(&gitdiff.Format, "format", reviewFormatSQL, "Output format (sql|markdown|json). markdown and json are the reviews for the bots to post to the pull requests")
+	gitdiff.diffOption.addFlags(gitdiffCmd.Flags())
+	gitdiffCmd.SetUsageTemplate(usageTemplate + "\nWith no REV2, HEAD is used. With no FILE, the current directory is read.\n" +
+		"The database is not accessed.\n")
+	rootCmd.AddCommand(gitdiffCmd)
+}
+
+type gitdiff struct {
+	diffOption
+
+	Delimiter string
+	SQLOnly   bool
+	Format    string
+}
+
+func (g *gitdiff) Execute(args []string, opt *Option) error {
+	var revs string
+	file := "."
+	switch len(args) {
+	case 0:
+		return fmt.Errorf("too few arguments")
+	case 1:
+		revs = args[0]
+	case 2:
+		revs, file = args[0], args[1]
+	default:
+		return fmt.Errorf("too many arguments")
+	}
+	i := strings.Index(revs, "..")
+	if i < 0 || strings.Contains(revs, "...") {
+		return fmt.Errorf("revisions must be in the form of REV1..REV2: %s", revs)
+	}
+	switch g.Format {
+	case reviewFormatSQL, reviewFormatMarkdown, reviewFormatJSON:
+	default:
+		return fmt.Errorf("unknown format: %s", g.Format)
+	}
+	oldRev, newRev := revs[:i], revs[i+2:]
+	if oldRev == "" {
+		return fmt.Errorf("REV1 is required: %s", revs)
+	}
+	if newRev == "" {
+		newRev = "HEAD"
+	}
+	g.diffOption.budget = opt.global.Config.Budget
+	g.diffOption.comparison = opt.global.Config.Comparison
+	g.diffOption.costOrder = opt.global.Config.CostOrder
+	g.diffOption.tablePrefix = opt.global.tablePrefix
+	if err := g.diffOption.validate(); err != nil {
+		return err
+	}
+	d, err := newOfflineDialect(opt)
+	if err != nil {
+		return err
+	}
+	tmpDir, err := ioutil.TempDir("", "migu-gitdiff")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+	var models [2]string
+	for i, rev := range []string{oldRev, newRev} {
+		dir, err := ioutil.TempDir(tmpDir, "")
+		if err != nil {
+			return err
+		}
+		if models[i], err = gitModel(rev, file, dir); err != nil {
+			return err
+		}
+	}
+	changes, err := migu.DiffStructs(d, models[0], nil, models[1], nil, g.options()...)
+	if err != nil {
+		return err
+	}
+	w := newEOLWriter(os.Stdout, opt.global.eol)
+	if g.Format != reviewFormatSQL {
+		return writeReview(w, g.Format, changes)
+	}
+	for _, c := range changes {
+		if !g.SQLOnly {
+			fmt.Fprintf(w, "-- %s\n", describeChange(c))
+		}
+		for _, sql := range c.SQLs {
+			fmt.Fprintf(w, "%s%s\n", sql, g.Delimiter)
+		}
+	}
+	return nil
+}
+
+// describeChange returns the description of the change such as "add_column user.name".
+func describeChange(c *migu.Change) string {
+	target := c.Table
+	switch {
+	case c.Column != "":
+		target += "." + c.Column
+	case c.Index != "":
+		target += " index " + c.Index
+	case c.Constraint != "":
+		target += " constraint " + c.Constraint
+	case c.User != "":
+		target = c.User
+	case c.Migration != "":
+		target = c.Migration
+	}
+	if c.NewName != "" {
+		target += " to " + c.NewName
+	}
+	if target == "" {
+		// The changes of the database have no target.
+		return string(c.Kind)
+	}
+	return fmt.Sprintf("%s %s", c.Kind, target)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/naoina/migu"
+	"github.com/naoina/migu/dialect"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	hash := &hash{}
+	hashCmd := &cobra.Command{
+		Use:   "hash [OPTIONS] DATABASE [FILE|DIRECTORY]",
+		Short: "output the stable hashes of the table definitions",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return hash.Execute(args, option)
+		},
+	}
+	hashCmd.Flags().BoolVar(&hash.FromDatabase, "from-database", false, "Compute the hashes from the database instead of Go's structs")
+	hashCmd.Flags().BoolVar(&hash.IgnoreColumnOrder, "ignore-column-order", false, "Compute the hashes independently of the order of the columns")
+	hashCmd.SetUsageTemplate(usageTemplate + "\nWith no FILE, or when FILE is -, read standard input.\n")
+	rootCmd.AddCommand(hashCmd)
+}
+
+type hash struct {
+	FromDatabase      bool
+	IgnoreColumnOrder bool
+
+	tablePrefix string
+}
+
+func (h *hash) Execute(args []string, opt *Option) (err error) {
+	h.tablePrefix = opt.global.tablePrefix
+	var dbname string
+	var file string
+	switch len(args) {
+	case 0:
+		return fmt.Errorf("too few arguments")
+	case 1:
+		dbname = args[0]
+	case 2:
+		dbname, file = args[0], args[1]
+	default:
+		return fmt.Errorf("too many arguments")
+	}
+	di, closer, err := newDialect(dbname, opt)
+	if err != nil {
+		return err
+	}
+	defer closeDialect(closer, &err)
+	return h.run(di, file)
+}
+
+func (h *hash) run(d dialect.Dialect, file string) error {
+	var opts []migu.Option
+	if h.IgnoreColumnOrder {
+		opts = append(opts, migu.WithIgnoreColumnOrder())
+	}
+	if h.tablePrefix != "" {
+		opts = append(opts, migu.WithTablePrefix(h.tablePrefix))
+	}
+	var hashes map[string]string
+	var err error
+	if h.FromDatabase {
+		hashes, err = migu.DatabaseTableHashes(d, opts...)
+	} else {
+		var src interface{}
+		switch file {
+		case "", "-":
+			file = ""
+			src = os.Stdin
+		}
+		hashes, err = migu.TableHashes(d, file, src, opts...)
+	}
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(hashes))
+	for name := range hashes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("%s\t%s\n", hashes[name], name)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/naoina/migu"
+	"github.com/naoina/migu/dialect"
+	"github.com/spf13/cobra"
+)
+
+const (
+	inventoryFormatCSV = "csv"
+	inventoryFormatTSV = "tsv"
+)
+
+func init() {
+	inventory := &inventory{}
+	inventoryCmd := &cobra.Command{
+		Use:   "inventory [OPTIONS] DATABASE [FILE|DIRECTORY]",
+		Short: "output the schema inventory as CSV/TSV",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return inventory.Execute(args, option)
+		},
+	}
+	inventoryCmd.Flags().StringVar(&inventory.Format, "format", inventoryFormatCSV, "Output format (csv|tsv)")
+	inventoryCmd.Flags().BoolVar(&inventory.FromDatabase, "from-database", false, "Generate the inventory from the database instead of Go's structs")
+	inventoryCmd.Flags().StringVarP(&inventory.Output, "output", "o", "", "Output to the file instead of standard output")
+	inventoryCmd.SetUsageTemplate(usageTemplate + "\nWith no FILE, or when FILE is -, read standard input.\n")
+	rootCmd.AddCommand(inventoryCmd)
+}
+
+type inventory struct {
+	Format       string
+	FromDatabase bool
+	Output       string
+}
+
+func (i *inventory) Execute(args []string, opt *Option) (err error) {
+	var dbname string
+	var file string
+	switch len(args) {
+	case 0:
+		return fmt.Errorf("too few arguments")
+	case 1:
+		dbname = args[0]
+	case 2:
+		dbname, file = args[0], args[1]
+	default:
+		return fmt.Errorf("too many arguments")
+	}
+	var comma rune
+	switch i.Format {
+	case inventoryFormatCSV:
+		comma = ','
+	case inventoryFormatTSV:
+		comma = '\t'
+	default:
+		return fmt.Errorf("unknown format: %s", i.Format)
+	}
+	di, closer, err := newDialect(dbname, opt)
+	if err != nil {
+		return err
+	}
+	defer closeDialect(closer, &err)
+	return i.run(di, file, comma)
+}
+
+func (i *inventory) run(d dialect.Dialect, file string, comma rune) error {
+	var entries []migu.InventoryEntry
+	var err error
+	if i.FromDatabase {
+		entries, err = migu.DatabaseInventory(d)
+	} else {
+		var src interface{}
+		switch file {
+		case "", "-":
+			file = ""
+			src = os.Stdin
+		}
+		entries, err = migu.Inventory(d, file, src)
+	}
+	if err != nil {
+		return err
+	}
+	out := os.Stdout
+	if i.Output != "" {
+		file, err := os.Create(i.Output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+	return migu.WriteInventory(out, entries, comma)
+}
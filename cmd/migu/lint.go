@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/naoina/migu"
+	"github.com/naoina/migu/dialect"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	lint := &lint{}
+	lintCmd := &cobra.Command{
+		Use:   "lint [OPTIONS] DATABASE [FILE|DIRECTORY]",
+		Short: "report problems of the schema such as redundant indexes",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return lint.Execute(args, option)
+		},
+	}
+	lintCmd.Flags().BoolVar(&lint.FromDatabase, "from-database", false, "Lint the database instead of Go's structs")
+	lintCmd.Flags().BoolVar(&lint.DropRedundant, "drop-redundant", false, "Output SQLs to drop the redundant indexes. The SQLs are not executed")
+	lintCmd.SetUsageTemplate(usageTemplate + "\nWith no FILE, or when FILE is -, read standard input.\n")
+	rootCmd.AddCommand(lintCmd)
+}
+
+type lint struct {
+	FromDatabase  bool
+	DropRedundant bool
+}
+
+func (l *lint) Execute(args []string, opt *Option) (err error) {
+	var dbname string
+	var file string
+	switch len(args) {
+	case 0:
+		return fmt.Errorf("too few arguments")
+	case 1:
+		dbname = args[0]
+	case 2:
+		dbname, file = args[0], args[1]
+	default:
+		return fmt.Errorf("too many arguments")
+	}
+	di, closer, err := newDialect(dbname, opt)
+	if err != nil {
+		return err
+	}
+	defer closeDialect(closer, &err)
+	return l.run(di, file)
+}
+
+func (l *lint) run(d dialect.Dialect, file string) error {
+	var redundants []*migu.RedundantIndex
+	var err error
+	if l.FromDatabase {
+		redundants, err = migu.LintDatabaseIndexes(d)
+	} else {
+		var src interface{}
+		switch file {
+		case "", "-":
+			file = ""
+			src = os.Stdin
+		}
+		redundants, err = migu.LintIndexes(d, file, src)
+	}
+	if err != nil {
+		return err
+	}
+	for _, r := range redundants {
+		fmt.Printf("-- %s\n", r)
+		if l.DropRedundant {
+			for _, sql := range d.DropIndexSQL(r.Index) {
+				fmt.Printf("%s;\n", sql)
+			}
+		}
+	}
+	return nil
+}
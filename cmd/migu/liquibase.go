@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/xml"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/naoina/migu"
+)
+
+// liquibaseAuthor is the author of the changeSets that are written by generate.
+const liquibaseAuthor = "migu"
+
+type liquibaseXMLChangelog struct {
+	XMLName        xml.Name              `xml:"databaseChangeLog"`
+	Xmlns          string                `xml:"xmlns,attr"`
+	XmlnsXsi       string                `xml:"xmlns:xsi,attr"`
+	SchemaLocation string                `xml:"xsi:schemaLocation,attr"`
+	ChangeSet      liquibaseXMLChangeSet `xml:"changeSet"`
+}
+
+type liquibaseXMLChangeSet struct {
+	ID       string            `xml:"id,attr"`
+	Author   string            `xml:"author,attr"`
+	Comment  liquibaseXMLText  `xml:"comment"`
+	SQLs     []liquibaseXMLSQL `xml:"sql"`
+	Rollback []liquibaseXMLSQL `xml:"rollback>sql"`
+}
+
+type liquibaseXMLText struct {
+	Text string `xml:",cdata"`
+}
+
+type liquibaseXMLSQL struct {
+	SplitStatements bool   `xml:"splitStatements,attr"`
+	SQL             string `xml:",cdata"`
+}
+
+// marshalLiquibaseXML returns the XML changelog of Liquibase that has the changeSet of the changes of up, and the
+// rollback of the changes of down.
+func marshalLiquibaseXML(id string, up, down []*migu.Change) ([]byte, error) {
+	changelog := liquibaseXMLChangelog{
+		Xmlns:          "http://www.liquibase.org/xml/ns/dbchangelog",
+		XmlnsXsi:       "http://www.w3.org/2001/XMLSchema-instance",
+		SchemaLocation: "http://www.liquibase.org/xml/ns/dbchangelog http://www.liquibase.org/xml/ns/dbchangelog/dbchangelog-3.8.xsd",
+		ChangeSet: liquibaseXMLChangeSet{
+			ID:      id,
+			Author:  liquibaseAuthor,
+			Comment: liquibaseXMLText{Text: describeChanges(up)},
+		},
+	}
+	// Each SQL is a single statement that may contain semicolons such as CREATE TRIGGER.
+	for _, sql := range changeSQLs(up) {
+		changelog.ChangeSet.SQLs = append(changelog.ChangeSet.SQLs, liquibaseXMLSQL{SQL: sql})
+	}
+	for _, sql := range changeSQLs(down) {
+		changelog.ChangeSet.Rollback = append(changelog.ChangeSet.Rollback, liquibaseXMLSQL{SQL: sql})
+	}
+	b, err := xml.MarshalIndent(changelog, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte(xml.Header), b...), '\n'), nil
+}
+
+type liquibaseYAMLChangelog struct {
+	DatabaseChangeLog []liquibaseYAMLEntry `yaml:"databaseChangeLog"`
+}
+
+type liquibaseYAMLEntry struct {
+	ChangeSet liquibaseYAMLChangeSet `yaml:"changeSet"`
+}
+
+type liquibaseYAMLChangeSet struct {
+	ID       string                `yaml:"id"`
+	Author   string                `yaml:"author"`
+	Comment  string                `yaml:"comment"`
+	Changes  []liquibaseYAMLChange `yaml:"changes"`
+	Rollback []liquibaseYAMLChange `yaml:"rollback,omitempty"`
+}
+
+type liquibaseYAMLChange struct {
+	SQL liquibaseYAMLSQL `yaml:"sql"`
+}
+
+type liquibaseYAMLSQL struct {
+	SplitStatements bool   `yaml:"splitStatements"`
+	SQL             string `yaml:"sql"`
+}
+
+// marshalLiquibaseYAML is the same as marshalLiquibaseXML except that it returns the changelog in YAML.
+func marshalLiquibaseYAML(id string, up, down []*migu.Change) ([]byte, error) {
+	cs := liquibaseYAMLChangeSet{
+		ID:      id,
+		Author:  liquibaseAuthor,
+		Comment: describeChanges(up),
+	}
+	for _, sql := range changeSQLs(up) {
+		cs.Changes = append(cs.Changes, liquibaseYAMLChange{SQL: liquibaseYAMLSQL{SQL: sql}})
+	}
+	for _, sql := range changeSQLs(down) {
+		cs.Rollback = append(cs.Rollback, liquibaseYAMLChange{SQL: liquibaseYAMLSQL{SQL: sql}})
+	}
+	return yaml.Marshal(liquibaseYAMLChangelog{
+		DatabaseChangeLog: []liquibaseYAMLEntry{{ChangeSet: cs}},
+	})
+}
+
+func describeChanges(changes []*migu.Change) string {
+	descs := make([]string, len(changes))
+	for i, c := range changes {
+		descs[i] = describeChange(c)
+	}
+	return strings.Join(descs, "\n")
+}
+
+func changeSQLs(changes []*migu.Change) []string {
+	var sqls []string
+	for _, c := range changes {
+		sqls = append(sqls, c.SQLs...)
+	}
+	return sqls
+}
@@ -1,14 +1,18 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
-	"net"
 	"os"
+	"path"
+	"time"
 
 	"github.com/go-sql-driver/mysql"
 	"github.com/goccy/go-yaml"
 	"github.com/howeyc/gopass"
+	"github.com/naoina/migu"
 	"github.com/naoina/migu/dialect"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -17,9 +21,16 @@ import (
 const (
 	progName = "migu"
 
-	databaseTypeMySQL   = "mysql"
-	databaseTypeMariaDB = "mariadb"
-	databaseTypeSpanner = "spanner"
+	databaseTypeMySQL    = "mysql"
+	databaseTypeMariaDB  = "mariadb"
+	databaseTypeSpanner  = "spanner"
+	databaseTypePostgres = "postgres"
+	databaseTypeSQLite   = "sqlite"
+	databaseTypeMSSQL    = "mssql"
+
+	databaseTypeCockroachDB = "cockroachdb"
+	databaseTypeTiDB        = "tidb"
+	databaseTypeBigQuery    = "bigquery"
 )
 
 var (
@@ -30,6 +41,11 @@ var (
 			if err := validateFlags(option); err != nil {
 				return err
 			}
+			redactor, err := migu.NewRedactor(option.global.redactPatterns...)
+			if err != nil {
+				return err
+			}
+			option.global.redactor = redactor
 			if fname := option.global.columnTypeFile; fname != "" {
 				columnTypes, err := readColumnTypeFromFile(option.global.columnTypeFile)
 				if err != nil {
@@ -37,6 +53,28 @@ var (
 				}
 				option.global.ColumnTypes = columnTypes
 			}
+			option.global.Config = &Config{}
+			if fname := option.global.configFile; fname != "" {
+				config, err := readConfigFromFile(fname)
+				if err != nil {
+					return err
+				}
+				option.global.Config = config
+			}
+			if patterns := option.global.Config.Redact.Patterns; len(patterns) > 0 {
+				if option.global.redactor, err = migu.NewRedactor(append(option.global.redactPatterns, patterns...)...); err != nil {
+					return err
+				}
+			}
+			for _, u := range option.global.Config.Users {
+				option.global.redactor.AddSecrets(u.Password)
+			}
+			if password := option.mysql.Password; password != "PASS" {
+				option.global.redactor.AddSecrets(password)
+			}
+			if option.global.tablePrefix == "" {
+				option.global.tablePrefix = option.global.Config.TablePrefix
+			}
 			return nil
 		},
 	}
@@ -51,8 +89,17 @@ type Option struct {
 	global struct {
 		DatabaseType string
 		ColumnTypes  []*dialect.ColumnType
+		Config       *Config
 
 		columnTypeFile string
+		configFile     string
+		dialectPlugin  string
+		tablePrefix    string
+		redactPatterns []string
+		redactor       *migu.Redactor
+		wrapConnector  func(driver.Connector) driver.Connector
+		yesIMeanIt     bool
+		eol            string
 	}
 	mysql struct {
 		User     string
@@ -65,22 +112,36 @@ type Option struct {
 		Project  string
 		Instance string
 	}
+	postgres struct {
+		SSLMode string
+		Schema  string
+	}
 }
 
 func init() {
 	flagsForGlobal := pflag.NewFlagSet("Global", pflag.ContinueOnError)
-	flagsForGlobal.StringVarP(&option.global.DatabaseType, "type", "t", databaseTypeMySQL, "Specify the database type (mysql|mariadb|spanner)")
+	flagsForGlobal.StringVarP(&option.global.DatabaseType, "type", "t", databaseTypeMySQL, "Specify the database type (mysql|mariadb|tidb|postgres|cockroachdb|sqlite|mssql|spanner|bigquery"+registeredDatabaseTypes()+")")
 	flagsForGlobal.StringVar(&option.global.columnTypeFile, "column-type-file", "", "Use the definition file of custom column types. Supported format is YAML")
+	flagsForGlobal.StringVar(&option.global.configFile, "config", "", "Use the configuration file. Supported format is YAML")
+	flagsForGlobal.StringVar(&option.global.dialectPlugin, "dialect-plugin", "", "Use the external dialect plugin instead of --type")
+	flagsForGlobal.StringVar(&option.global.tablePrefix, "table-prefix", "", "Manage only the tables whose names begin with the prefix, and prefix the tables of Go's structs with it")
+	flagsForGlobal.StringVar(&option.global.eol, "eol", eolLF, "The line endings of the generated SQL and Go files (lf|crlf)")
+	flagsForGlobal.StringArrayVar(&option.global.redactPatterns, "redact-pattern", nil, "Redact the matches of the regular expression in the output, the reports and the error messages in addition to the passwords of DSNs (can be repeated)")
+	flagsForGlobal.BoolVar(&option.global.yesIMeanIt, "yes-i-mean-it", false, "Apply the destructive changes to the protected database without the confirmation")
 
-	flagsForMySQL := pflag.NewFlagSet("MySQL/MariaDB", pflag.ContinueOnError)
-	flagsForMySQL.StringVarP(&option.mysql.Host, "host", "h", "", "Connect to host of database")
+	flagsForMySQL := pflag.NewFlagSet("MySQL/MariaDB/TiDB/PostgreSQL/CockroachDB/SQL Server", pflag.ContinueOnError)
+	flagsForMySQL.StringVarP(&option.mysql.Host, "host", "h", "", "Connect to host of database. Multiple hosts separated by commas are tried in order, and the host beginning with _ is looked up as DNS SRV record")
 	flagsForMySQL.StringVarP(&option.mysql.User, "user", "u", "", "User for login to database if not current user")
 	flagsForMySQL.StringVarP(&option.mysql.Password, "password", "p", "", "Password to use when connecting to server.\nIf password is not given, it's asked from the tty")
 	flagsForMySQL.Lookup("password").NoOptDefVal = "PASS"
 	flagsForMySQL.IntVarP(&option.mysql.Port, "port", "P", 0, "Port number to use for connection")
 	flagsForMySQL.StringVar(&option.mysql.Protocol, "protocol", "tcp", "The protocol to use for connection (tcp, socket)")
 
-	flagsForSpanner := pflag.NewFlagSet("Cloud Spanner", pflag.ContinueOnError)
+	flagsForPostgres := pflag.NewFlagSet("PostgreSQL/CockroachDB/SQL Server", pflag.ContinueOnError)
+	flagsForPostgres.StringVar(&option.postgres.SSLMode, "sslmode", "", "The SSL mode of the connection to PostgreSQL (disable|require|verify-ca|verify-full) (default require)")
+	flagsForPostgres.StringVar(&option.postgres.Schema, "schema", "", "The schema of the tables (default the current schema of the connection)")
+
+	flagsForSpanner := pflag.NewFlagSet("Cloud Spanner/BigQuery", pflag.ContinueOnError)
 	flagsForSpanner.StringVar(&option.spanner.Project, "project", os.Getenv("SPANNER_PROJECT_ID"), "The Google Cloud Platform project name")
 	if flag := flagsForSpanner.Lookup("project"); flag.DefValue == "" {
 		flag.DefValue = "$SPANNER_PROJECT_ID"
@@ -96,6 +157,7 @@ func init() {
 
 	rootCmd.PersistentFlags().AddFlagSet(flagsForGlobal)
 	rootCmd.PersistentFlags().AddFlagSet(flagsForMySQL)
+	rootCmd.PersistentFlags().AddFlagSet(flagsForPostgres)
 	rootCmd.PersistentFlags().AddFlagSet(flagsForSpanner)
 	rootCmd.PersistentFlags().Bool("help", false, "Display this help and exit")
 	rootCmd.PersistentFlags().Lookup("help").Hidden = true
@@ -113,11 +175,15 @@ func init() {
 					Flags: flagsForGlobal,
 				},
 				{
-					Name:  "MySQL/MariaDB",
+					Name:  "MySQL/MariaDB/TiDB/PostgreSQL/CockroachDB/SQL Server",
 					Flags: flagsForMySQL,
 				},
 				{
-					Name:  "Cloud Spanner",
+					Name:  "PostgreSQL/CockroachDB/SQL Server",
+					Flags: flagsForPostgres,
+				},
+				{
+					Name:  "Cloud Spanner/BigQuery",
 					Flags: flagsForSpanner,
 				},
 			}
@@ -125,34 +191,358 @@ func init() {
 	})
 }
 
-func openDatabase(dbname string) (db *sql.DB, err error) {
-	opt := option.mysql
-	config := mysql.NewConfig()
-	config.User = opt.User
-	if config.User == "" {
-		if config.User = os.Getenv("USERNAME"); config.User == "" {
-			if config.User = os.Getenv("USER"); config.User == "" {
-				return nil, fmt.Errorf("user is not specified and current user cannot be detected")
+const (
+	comparisonStrict  = "strict"
+	comparisonLenient = "lenient"
+)
+
+// diffOption is the options for computing differences of schemas that are shared by the commands.
+type diffOption struct {
+	ArchiveOrphans   bool
+	ArchiveRetention time.Duration
+	TwoPhaseDrop     bool
+	DropGracePeriod  time.Duration
+	Phase            string
+	AllowDecryption  bool
+	AllowPKChange    bool
+	Comparison       string
+	OrderByCost      bool
+	Tagged           []string
+
+	budget      *BudgetConfig
+	comparison  *ComparisonConfig
+	costOrder   *CostOrderConfig
+	tablePrefix string
+}
+
+func (o *diffOption) addFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(&o.ArchiveOrphans, "archive-orphans", false, "Rename the tables that are not defined by Go's structs with the archived prefix instead of dropping them")
+	flags.DurationVar(&o.ArchiveRetention, "archive-retention", 30*24*time.Hour, "Drop the archived tables after the retention period passed (requires --archive-orphans)")
+	flags.BoolVar(&o.TwoPhaseDrop, "two-phase-drop", false, "Rename the columns that are removed from Go's structs with the deprecated prefix instead of dropping them")
+	flags.StringVar(&o.Phase, "phase", "", "Apply only the changes of the phase (expand|contract|indexes|constraints)")
+	flags.DurationVar(&o.DropGracePeriod, "drop-grace-period", 7*24*time.Hour, "Drop the deprecated columns after the grace period passed (requires --two-phase-drop)")
+	flags.BoolVar(&o.AllowDecryption, "allow-decryption", false, "Allow decrypting the encrypted tables by encryption:\"N\" annotation")
+	flags.BoolVar(&o.AllowPKChange, "allow-pk-change", false, "Allow modifying the primary keys of the existing tables")
+	flags.StringVar(&o.Comparison, "comparison", "", "The strategy to decide whether the columns are modified (strict|lenient) (default strict)")
+	flags.BoolVar(&o.OrderByCost, "order-by-cost", false, "Apply the cheap changes such as the metadata-only changes first and the expensive and risky changes last")
+	flags.StringArrayVar(&o.Tagged, "tagged", nil, "Manage only the tables of Go's structs that are tagged with the tag by tags annotation (can be repeated)")
+}
+
+func (o *diffOption) validate() error {
+	switch migu.Phase(o.Phase) {
+	case "", migu.PhaseExpand, migu.PhaseContract, migu.PhaseIndexes, migu.PhaseConstraints:
+	default:
+		return fmt.Errorf("unknown phase: %s", o.Phase)
+	}
+	if o.Comparison == "" && o.comparison != nil {
+		o.Comparison = o.comparison.Strategy
+	}
+	switch o.Comparison {
+	case "", comparisonStrict, comparisonLenient:
+	default:
+		return fmt.Errorf("unknown comparison strategy: %s", o.Comparison)
+	}
+	if o.costOrder != nil {
+		for kind, cost := range o.costOrder.Costs {
+			if _, err := migu.ParseCost(string(cost)); err != nil {
+				return fmt.Errorf("cost_order of %s: %w", kind, err)
 			}
 		}
 	}
-	config.Passwd = opt.Password
-	if config.Passwd != "" {
-		if config.Passwd == "PASS" {
-			p, err := gopass.GetPasswdPrompt("Enter password: ", false, os.Stdin, os.Stderr)
-			if err != nil {
-				return nil, err
+	return nil
+}
+
+// comparisonStrategy returns the strategy of the comparison that is specified by --comparison and the config file.
+func (o *diffOption) comparisonStrategy() migu.ComparisonStrategy {
+	base := migu.StrictComparison()
+	if o.Comparison == comparisonLenient {
+		base = migu.LenientComparison()
+	}
+	if o.comparison == nil || len(o.comparison.EquivalentTypes) == 0 {
+		return base
+	}
+	c := migu.NewCustomComparison(base)
+	for _, types := range o.comparison.EquivalentTypes {
+		c.Register(migu.AttributeType, migu.EquivalentTypes(types...))
+	}
+	return c
+}
+
+func (o *diffOption) options() []migu.Option {
+	var opts []migu.Option
+	if o.ArchiveOrphans {
+		opts = append(opts, migu.WithArchiveOrphans(o.ArchiveRetention))
+	}
+	if o.TwoPhaseDrop {
+		opts = append(opts, migu.WithTwoPhaseColumnDrop(o.DropGracePeriod))
+	}
+	if o.Phase != "" {
+		opts = append(opts, migu.WithPhase(migu.Phase(o.Phase)))
+	}
+	if o.AllowDecryption {
+		opts = append(opts, migu.WithAllowDecryption())
+	}
+	if o.AllowPKChange {
+		opts = append(opts, migu.WithAllowPrimaryKeyChange())
+	}
+	if b := o.budget; b != nil {
+		var warn func(v *migu.BudgetViolation)
+		if b.Warn {
+			warn = func(v *migu.BudgetViolation) {
+				fmt.Fprintf(os.Stderr, "-- warning: %s\n", v)
 			}
-			config.Passwd = string(p)
 		}
+		opts = append(opts, migu.WithBudget(b.Budget, warn))
+	}
+	if o.Comparison != "" || o.comparison != nil {
+		opts = append(opts, migu.WithComparison(o.comparisonStrategy()))
+	}
+	if o.OrderByCost || o.costOrder != nil {
+		var costs map[migu.ChangeKind]migu.Cost
+		if o.costOrder != nil {
+			costs = o.costOrder.Costs
+		}
+		opts = append(opts, migu.WithCostOrder(costs))
+	}
+	if o.tablePrefix != "" {
+		opts = append(opts, migu.WithTablePrefix(o.tablePrefix))
+	}
+	if len(o.Tagged) > 0 {
+		opts = append(opts, migu.WithTags(o.Tagged...))
+	}
+	return opts
+}
+
+// newDialect returns the dialect for the database type specified by the options.
+// The returned function must be called to release the resources after use.
+func newDialect(dbname string, opt *Option) (dialect.Dialect, func() error, error) {
+	var opts []dialect.Option
+	if columnTypes := opt.global.ColumnTypes; len(columnTypes) != 0 {
+		opts = append(opts, dialect.WithColumnType(columnTypes))
+	}
+	if plugin := opt.global.dialectPlugin; plugin != "" {
+		d, closer, err := dialect.NewPlugin(plugin, dbname, opts...)
+		if err != nil {
+			return nil, nil, err
+		}
+		return d, closer, nil
+	}
+	switch typ := opt.global.DatabaseType; typ {
+	case databaseTypeMySQL, databaseTypeMariaDB, databaseTypeTiDB:
+		db, err := openDatabase(dbname, opt.global.Config.databaseEnvironment(dbname))
+		if err != nil {
+			return nil, nil, err
+		}
+		if typ == databaseTypeTiDB {
+			return dialect.NewTiDB(db, opts...), db.Close, nil
+		}
+		return dialect.NewMySQL(db, opts...), db.Close, nil
+	case databaseTypePostgres, databaseTypeCockroachDB:
+		if schema := opt.postgres.Schema; schema != "" {
+			opts = append(opts, dialect.WithSchema(schema))
+		}
+		db, err := openPostgres(dbname, opt.global.Config.databaseEnvironment(dbname))
+		if err != nil {
+			return nil, nil, err
+		}
+		if typ == databaseTypeCockroachDB {
+			return dialect.NewCockroachDB(db, opts...), db.Close, nil
+		}
+		return dialect.NewPostgres(db, opts...), db.Close, nil
+	case databaseTypeSQLite:
+		db, err := openSQLite(dbname, opt.global.Config.databaseEnvironment(dbname))
+		if err != nil {
+			return nil, nil, err
+		}
+		return dialect.NewSQLite(db, opts...), db.Close, nil
+	case databaseTypeMSSQL:
+		if schema := opt.postgres.Schema; schema != "" {
+			opts = append(opts, dialect.WithSchema(schema))
+		}
+		db, err := openMSSQL(dbname, opt.global.Config.databaseEnvironment(dbname))
+		if err != nil {
+			return nil, nil, err
+		}
+		return dialect.NewMSSQL(db, opts...), db.Close, nil
+	case databaseTypeSpanner:
+		return dialect.NewSpanner(path.Join("projects", opt.spanner.Project, "instances", opt.spanner.Instance, "databases", dbname), opts...), func() error { return nil }, nil
+	case databaseTypeBigQuery:
+		return dialect.NewBigQuery(path.Join("projects", opt.spanner.Project, "datasets", dbname), opts...), func() error { return nil }, nil
+	default:
+		factory, ok := dialect.Get(typ)
+		if !ok {
+			return nil, nil, fmt.Errorf("BUG: unknown database type: %s", typ)
+		}
+		db, err := openRegistered(typ, dbname, opt.global.Config.databaseEnvironment(dbname))
+		if err != nil {
+			return nil, nil, err
+		}
+		return factory(db), db.Close, nil
+	}
+}
+
+// closeDialect calls closer that is returned by newDialect, and stores the error of it to err unless err already has
+// an error.
+func closeDialect(closer func() error, err *error) {
+	if cerr := closer(); *err == nil {
+		*err = cerr
+	}
+}
+
+// newOfflineDialect returns the dialect that is used without connecting to the database.
+func newOfflineDialect(opt *Option) (dialect.Dialect, error) {
+	return newDialectWithDB(nil, opt)
+}
+
+// newDialectWithDB returns the dialect for the database type specified by the options that uses db, such as the
+// replay of a fixture. db may be nil.
+func newDialectWithDB(db *sql.DB, opt *Option) (dialect.Dialect, error) {
+	var opts []dialect.Option
+	if columnTypes := opt.global.ColumnTypes; len(columnTypes) != 0 {
+		opts = append(opts, dialect.WithColumnType(columnTypes))
+	}
+	switch typ := opt.global.DatabaseType; typ {
+	case databaseTypeMySQL, databaseTypeMariaDB:
+		return dialect.NewMySQL(db, opts...), nil
+	case databaseTypeTiDB:
+		return dialect.NewTiDB(db, opts...), nil
+	case databaseTypePostgres, databaseTypeCockroachDB, databaseTypeMSSQL:
+		if schema := opt.postgres.Schema; schema != "" {
+			opts = append(opts, dialect.WithSchema(schema))
+		}
+		switch typ {
+		case databaseTypeCockroachDB:
+			return dialect.NewCockroachDB(db, opts...), nil
+		case databaseTypeMSSQL:
+			return dialect.NewMSSQL(db, opts...), nil
+		}
+		return dialect.NewPostgres(db, opts...), nil
+	case databaseTypeSQLite:
+		return dialect.NewSQLite(db, opts...), nil
+	default:
+		if factory, ok := dialect.Get(typ); ok {
+			return factory(db), nil
+		}
+		return nil, fmt.Errorf("database type %s is not supported without connecting to the database", typ)
+	}
+}
+
+// openDatabase opens the database with the driver parameters and the session statements of env if not nil.
+// The new connections fail over to the next host of --host if the current one is unavailable.
+func openDatabase(dbname string, env *Environment) (db *sql.DB, err error) {
+	opt := option.mysql
+	config := mysql.NewConfig()
+	if config.User, err = loginUser(); err != nil {
+		return nil, err
+	}
+	if config.Passwd, err = loginPassword(); err != nil {
+		return nil, err
 	}
 	config.Net = protocolMap[opt.Protocol]
-	config.Addr = opt.Host
+	config.DBName = dbname
+	var stmts []string
+	if env != nil {
+		if len(env.Params) > 0 {
+			config.Params = map[string]string{}
+			for k, v := range env.Params {
+				config.Params[k] = v
+			}
+		}
+		stmts = env.Session
+	}
+	addrs := []hostPort{{host: opt.Host}}
 	if opt.Port > 0 {
-		config.Addr = net.JoinHostPort(config.Addr, fmt.Sprintf("%d", opt.Port))
+		addrs[0].port = fmt.Sprintf("%d", opt.Port)
 	}
-	config.DBName = dbname
-	return sql.Open("mysql", config.FormatDSN())
+	if config.Net == "tcp" {
+		if addrs, err = resolveHosts(opt.Host, opt.Port); err != nil {
+			return nil, err
+		}
+	}
+	connectors := make([]driver.Connector, len(addrs))
+	for i, addr := range addrs {
+		c := config.Clone()
+		c.Addr = addr.addr()
+		if connectors[i], err = mysql.NewConnector(c); err != nil {
+			return nil, err
+		}
+	}
+	return openDB(dialect.NewFailoverConnector(connectors...), stmts), nil
+}
+
+// openRegistered opens the database for the dialect that is registered by dialect.Register. The database/sql
+// driver that is registered by the same name as the dialect is used, and dbname is passed to the driver as the data
+// source name. The session statements of env are executed if not nil.
+func openRegistered(name, dbname string, env *Environment) (*sql.DB, error) {
+	db, err := sql.Open(name, dbname)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the database for the dialect %s: %w", name, err)
+	}
+	connector := &dsnConnector{dsn: dbname, driver: db.Driver()}
+	db.Close()
+	var stmts []string
+	if env != nil {
+		stmts = env.Session
+	}
+	return openDB(connector, stmts), nil
+}
+
+// openDB opens the database of the connector that executes the session statements on every new connection.
+// The connector is wrapped by the wrapper of the options such as the recorder of `migu fixtures record` if any.
+func openDB(connector driver.Connector, stmts []string) *sql.DB {
+	connector = dialect.NewSessionConnector(connector, stmts...)
+	if wrap := option.global.wrapConnector; wrap != nil {
+		connector = wrap(connector)
+	}
+	return sql.OpenDB(connector)
+}
+
+// dsnConnector is the driver.Connector for the driver that does not implement driver.DriverContext.
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c *dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c *dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// loginUser returns the user for login to database, or the current user if not specified.
+func loginUser() (string, error) {
+	if user := option.mysql.User; user != "" {
+		return user, nil
+	}
+	if user := os.Getenv("USERNAME"); user != "" {
+		return user, nil
+	}
+	if user := os.Getenv("USER"); user != "" {
+		return user, nil
+	}
+	return "", fmt.Errorf("user is not specified and current user cannot be detected")
+}
+
+// loginPassword returns the password for login to database. It is asked from the tty if --password is given without
+// the value.
+func loginPassword() (string, error) {
+	if password := option.mysql.Password; password != "PASS" {
+		return password, nil
+	}
+	// Ask from the terminal because the standard input may be the schema or the plan in the pipeline.
+	in := os.Stdin
+	if tty, err := os.Open(ttyFile); err == nil {
+		defer tty.Close()
+		in = tty
+	}
+	p, err := gopass.GetPasswdPrompt("Enter password: ", false, in, os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	option.global.redactor.AddSecrets(string(p))
+	return string(p), nil
 }
 
 func readColumnTypeFromFile(fname string) ([]*dialect.ColumnType, error) {
@@ -168,15 +558,30 @@ func readColumnTypeFromFile(fname string) ([]*dialect.ColumnType, error) {
 	return columnTypes, nil
 }
 
+// registeredDatabaseTypes returns the names of the dialects that are registered by dialect.Register for the help of
+// --type.
+func registeredDatabaseTypes() string {
+	var s string
+	for _, name := range dialect.Registered() {
+		s += "|" + name
+	}
+	return s
+}
+
 func validateFlags(opt *Option) error {
 	if opt.global.DatabaseType == "" {
 		return fmt.Errorf("database type is required")
 	}
 	switch typ := opt.global.DatabaseType; typ {
-	case databaseTypeMySQL, databaseTypeMariaDB, databaseTypeSpanner:
+	case databaseTypeMySQL, databaseTypeMariaDB, databaseTypeTiDB, databaseTypePostgres, databaseTypeCockroachDB, databaseTypeSQLite, databaseTypeMSSQL, databaseTypeSpanner, databaseTypeBigQuery:
 		// do nothing.
 	default:
-		return fmt.Errorf("unknown database type: %s", opt.global.DatabaseType)
+		if _, ok := dialect.Get(typ); !ok {
+			return fmt.Errorf("unknown database type: %s", opt.global.DatabaseType)
+		}
+	}
+	if err := validateEOL(opt.global.eol); err != nil {
+		return err
 	}
 	switch opt.global.DatabaseType {
 	case databaseTypeMySQL, databaseTypeMariaDB:
@@ -193,6 +598,10 @@ func validateFlags(opt *Option) error {
 		if opt.spanner.Instance == "" {
 			return fmt.Errorf("instance is required")
 		}
+	case databaseTypeBigQuery:
+		if opt.spanner.Project == "" {
+			return fmt.Errorf("project is required")
+		}
 	}
 	return nil
 }
@@ -201,5 +610,50 @@ func main() {
 	for _, cmd := range rootCmd.Commands() {
 		cmd.DisableFlagsInUseLine = true
 	}
-	rootCmd.Execute()
+	redactErrors(rootCmd)
+	if err := rootCmd.Execute(); err != nil {
+		if _, ok := err.(*exitError); !ok {
+			if code := migu.ErrorCode(err); code != "" {
+				fmt.Fprintf(os.Stderr, "See %s for the error %s.\n", code.URL(), code)
+			}
+		}
+		os.Exit(exitCode(err))
+	}
+}
+
+// exitCode returns the exit status of the command that returned err.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	if e, ok := err.(*exitError); ok {
+		return e.code
+	}
+	return 1
+}
+
+// redactErrors redacts the secrets in the error messages of the command and its subcommands.
+func redactErrors(cmd *cobra.Command) {
+	if run := cmd.RunE; run != nil {
+		cmd.RunE = func(cmd *cobra.Command, args []string) error {
+			return option.global.redactor.RedactError(run(cmd, args))
+		}
+	}
+	if run := cmd.PersistentPreRunE; run != nil {
+		cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+			return option.global.redactor.RedactError(run(cmd, args))
+		}
+	}
+	for _, c := range cmd.Commands() {
+		redactErrors(c)
+	}
+}
+
+// exitError is the error to exit with the status code without any message, such as diff --exit-code.
+type exitError struct {
+	code int
+}
+
+func (e *exitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
 }
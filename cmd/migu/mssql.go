@@ -0,0 +1,56 @@
+//go:build !nomssql
+// +build !nomssql
+
+package main
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"net/url"
+
+	mssql "github.com/denisenkom/go-mssqldb"
+	"github.com/naoina/migu/dialect"
+)
+
+// openMSSQL opens the database of SQL Server with the connection parameters and the session statements of env if
+// not nil. The new connections fail over to the next host of --host if the current one is unavailable.
+func openMSSQL(dbname string, env *Environment) (*sql.DB, error) {
+	opt := option.mysql
+	user, err := loginUser()
+	if err != nil {
+		return nil, err
+	}
+	password, err := loginPassword()
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := resolveHosts(opt.Host, opt.Port)
+	if err != nil {
+		return nil, err
+	}
+	params := url.Values{}
+	params.Set("database", dbname)
+	var stmts []string
+	if env != nil {
+		for k, v := range env.Params {
+			params.Set(k, v)
+		}
+		stmts = env.Session
+	}
+	connectors := make([]driver.Connector, len(addrs))
+	for i, addr := range addrs {
+		if addr.host == "" {
+			addr.host = "localhost"
+		}
+		dsn := url.URL{
+			Scheme:   "sqlserver",
+			User:     url.UserPassword(user, password),
+			Host:     addr.addr(),
+			RawQuery: params.Encode(),
+		}
+		if connectors[i], err = mssql.NewConnector(dsn.String()); err != nil {
+			return nil, err
+		}
+	}
+	return openDB(dialect.NewFailoverConnector(connectors...), stmts), nil
+}
@@ -0,0 +1,14 @@
+//go:build nomssql
+// +build nomssql
+
+package main
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// openMSSQL returns the error since the driver of SQL Server is excluded by nomssql build tag.
+func openMSSQL(dbname string, env *Environment) (*sql.DB, error) {
+	return nil, fmt.Errorf("SQL Server is not supported by this build of migu (built with nomssql tag)")
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/naoina/migu"
+	"github.com/naoina/migu/dialect"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	orphans := &orphans{}
+	orphansCmd := &cobra.Command{
+		Use:   "orphans [OPTIONS] DATABASE [FILE|DIRECTORY]",
+		Short: "list the tables that are not defined by Go's structs",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return orphans.Execute(args, option)
+		},
+	}
+	orphansCmd.SetUsageTemplate(usageTemplate + "\nWith no FILE, or when FILE is -, read standard input.\n")
+	rootCmd.AddCommand(orphansCmd)
+}
+
+type orphans struct {
+	tablePrefix string
+}
+
+func (o *orphans) Execute(args []string, opt *Option) (err error) {
+	o.tablePrefix = opt.global.tablePrefix
+	var dbname string
+	var file string
+	switch len(args) {
+	case 0:
+		return fmt.Errorf("too few arguments")
+	case 1:
+		dbname = args[0]
+	case 2:
+		dbname, file = args[0], args[1]
+	default:
+		return fmt.Errorf("too many arguments")
+	}
+	di, closer, err := newDialect(dbname, opt)
+	if err != nil {
+		return err
+	}
+	defer closeDialect(closer, &err)
+	return o.run(di, file)
+}
+
+func (o *orphans) run(d dialect.Dialect, file string) error {
+	var src interface{}
+	switch file {
+	case "", "-":
+		file = ""
+		src = os.Stdin
+	}
+	var opts []migu.Option
+	if o.tablePrefix != "" {
+		opts = append(opts, migu.WithTablePrefix(o.tablePrefix))
+	}
+	names, err := migu.Orphans(d, file, src, opts...)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		fmt.Println(name)
+	}
+	return nil
+}
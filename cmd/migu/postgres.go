@@ -0,0 +1,65 @@
+//go:build !nopostgres
+// +build !nopostgres
+
+package main
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/naoina/migu/dialect"
+)
+
+// openPostgres opens the database of PostgreSQL or CockroachDB with the connection parameters and the session statements of env
+// if not nil. The new connections fail over to the next host of --host if the current one is unavailable.
+func openPostgres(dbname string, env *Environment) (db *sql.DB, err error) {
+	opt := option.mysql
+	params := map[string]string{
+		"dbname": dbname,
+	}
+	if params["user"], err = loginUser(); err != nil {
+		return nil, err
+	}
+	if params["password"], err = loginPassword(); err != nil {
+		return nil, err
+	}
+	if option.postgres.SSLMode != "" {
+		params["sslmode"] = option.postgres.SSLMode
+	}
+	var stmts []string
+	if env != nil {
+		for k, v := range env.Params {
+			params[k] = v
+		}
+		stmts = env.Session
+	}
+	addrs, err := resolveHosts(opt.Host, opt.Port)
+	if err != nil {
+		return nil, err
+	}
+	connectors := make([]driver.Connector, len(addrs))
+	for i, addr := range addrs {
+		var dsn []string
+		for k, v := range map[string]string{"host": addr.host, "port": addr.port} {
+			if _, ok := params[k]; !ok && v != "" {
+				dsn = append(dsn, k+"="+quotePostgresParam(v))
+			}
+		}
+		for k, v := range params {
+			if v != "" {
+				dsn = append(dsn, k+"="+quotePostgresParam(v))
+			}
+		}
+		if connectors[i], err = pq.NewConnector(strings.Join(dsn, " ")); err != nil {
+			return nil, err
+		}
+	}
+	return openDB(dialect.NewFailoverConnector(connectors...), stmts), nil
+}
+
+// quotePostgresParam quotes the value of the connection parameter of PostgreSQL.
+func quotePostgresParam(v string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(v) + "'"
+}
@@ -0,0 +1,14 @@
+//go:build nopostgres
+// +build nopostgres
+
+package main
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// openPostgres returns the error since the driver of PostgreSQL and CockroachDB is excluded by nopostgres build tag.
+func openPostgres(dbname string, env *Environment) (*sql.DB, error) {
+	return nil, fmt.Errorf("PostgreSQL and CockroachDB are not supported by this build of migu (built with nopostgres tag)")
+}
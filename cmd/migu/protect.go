@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/naoina/migu"
+)
+
+// ttyFile is the terminal that the confirmation phrase is read from.
+// It is used instead of the standard input because the standard input may be the source of Go's structs.
+var ttyFile = "/dev/tty"
+
+// protectedDatabase returns dbname if it belongs to any protected environment, or empty if it doesn't or if
+// --yes-i-mean-it is specified.
+func protectedDatabase(dbname string, opt *Option) string {
+	if opt.global.Config.isProtectedDatabase(dbname) && !opt.global.yesIMeanIt {
+		return dbname
+	}
+	return ""
+}
+
+// confirmDestructive asks to confirm the destructive changes before applying them to the protected database.
+// Nothing is asked if protected is empty, in dry-run, or if there are no destructive changes.
+func confirmDestructive(protected string, dryRun bool, changes []*migu.Change) error {
+	if protected == "" || dryRun {
+		return nil
+	}
+	if destructives := destructiveChanges(changes); len(destructives) > 0 {
+		return confirmProtected(protected, destructives)
+	}
+	return nil
+}
+
+// destructiveChanges returns the changes that lose the data in the same way as --allow-destructive.
+func destructiveChanges(changes []*migu.Change) []*migu.Change {
+	var destructives []*migu.Change
+	for _, c := range changes {
+		if c.IsDestructive() {
+			destructives = append(destructives, c)
+		}
+	}
+	return destructives
+}
+
+// confirmProtected asks to type the name of the protected database before applying the destructive changes.
+// It returns an error if the name does not match, or if the terminal is not available.
+func confirmProtected(dbname string, changes []*migu.Change) error {
+	tty, err := os.OpenFile(ttyFile, os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("database %s is protected; use --yes-i-mean-it to apply the destructive changes without the terminal", dbname)
+	}
+	defer tty.Close()
+	return promptConfirmation(tty, tty, dbname, changes)
+}
+
+// promptConfirmation writes the destructive changes to w, and reads the name of the protected database from r.
+func promptConfirmation(r io.Reader, w io.Writer, dbname string, changes []*migu.Change) error {
+	fmt.Fprintf(w, "database %s is protected and the following changes are destructive:\n", dbname)
+	for _, c := range changes {
+		for _, sql := range c.SQLs {
+			fmt.Fprintf(w, "  %s\n", sql)
+		}
+	}
+	fmt.Fprintf(w, "type the name of the database to continue: ")
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && line == "" {
+		return fmt.Errorf("failed to read the confirmation: %w", err)
+	}
+	if strings.TrimSpace(line) != dbname {
+		return fmt.Errorf("confirmation failed: the name does not match database %s", dbname)
+	}
+	return nil
+}
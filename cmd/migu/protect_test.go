@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/naoina/migu"
+)
+
+func TestDestructiveChanges(t *testing.T) {
+	add := &migu.Change{Kind: migu.AddColumn, Table: "user", Column: "age", SQLs: []string{"ALTER TABLE `user` ADD `age` INT NOT NULL"}}
+	drop := &migu.Change{Kind: migu.DropTable, Table: "post", SQLs: []string{"DROP TABLE `post`"}}
+	rename := &migu.Change{Kind: migu.RenameTable, Table: "comment", NewName: "reply", SQLs: []string{"RENAME TABLE `comment` TO `reply`"}}
+	stmt := &migu.Change{Kind: migu.Statement, SQLs: []string{"DELETE FROM `user`"}}
+	for _, v := range []struct {
+		changes []*migu.Change
+		expect  []*migu.Change
+	}{
+		{nil, nil},
+		{[]*migu.Change{add}, nil},
+		{[]*migu.Change{add, drop}, []*migu.Change{drop}},
+		{[]*migu.Change{rename, stmt}, []*migu.Change{stmt}},
+	} {
+		actual := destructiveChanges(v.changes)
+		if diff := cmp.Diff(actual, v.expect); diff != "" {
+			t.Errorf("(-got +want)\n%v", diff)
+		}
+	}
+}
+
+func TestProtectedDatabase(t *testing.T) {
+	for _, v := range []struct {
+		dbname     string
+		yesIMeanIt bool
+		expect     string
+	}{
+		{"prod", false, "prod"},
+		{"prod", true, ""},
+		{"dev", false, ""},
+		{"unknown", false, ""},
+	} {
+		opt := &Option{}
+		opt.global.Config = &Config{Environments: map[string]*Environment{
+			"production":  {Database: "prod", Protected: true},
+			"development": {Database: "dev"},
+		}}
+		opt.global.yesIMeanIt = v.yesIMeanIt
+		if actual := protectedDatabase(v.dbname, opt); actual != v.expect {
+			t.Errorf("protectedDatabase(%q) with --yes-i-mean-it=%v => %q; want %q", v.dbname, v.yesIMeanIt, actual, v.expect)
+		}
+	}
+}
+
+func TestConfirmDestructive(t *testing.T) {
+	defer func(tty string) {
+		ttyFile = tty
+	}(ttyFile)
+	ttyFile = filepath.Join(os.TempDir(), "migu-missing-tty")
+	add := &migu.Change{Kind: migu.AddColumn, Table: "user", Column: "age", SQLs: []string{"ALTER TABLE `user` ADD `age` INT NOT NULL"}}
+	drop := &migu.Change{Kind: migu.DropTable, Table: "post", SQLs: []string{"DROP TABLE `post`"}}
+	for _, v := range []struct {
+		protected string
+		dryRun    bool
+		changes   []*migu.Change
+		expect    string
+	}{
+		{"prod", false, []*migu.Change{add, drop}, "database prod is protected; use --yes-i-mean-it to apply the destructive changes without the terminal"},
+		{"prod", false, []*migu.Change{add}, ""},
+		{"prod", false, nil, ""},
+		{"prod", true, []*migu.Change{add, drop}, ""},
+		{"", false, []*migu.Change{add, drop}, ""},
+	} {
+		var actual string
+		if err := confirmDestructive(v.protected, v.dryRun, v.changes); err != nil {
+			actual = err.Error()
+		}
+		if actual != v.expect {
+			t.Errorf("confirmDestructive(%q, %v, %d changes) => %q; want %q", v.protected, v.dryRun, len(v.changes), actual, v.expect)
+		}
+	}
+}
+
+func TestPromptConfirmation(t *testing.T) {
+	changes := []*migu.Change{{Kind: migu.DropTable, Table: "post", SQLs: []string{"DROP TABLE `post`"}}}
+	for _, v := range []struct {
+		input  string
+		expect string
+	}{
+		{"prod\n", ""},
+		{"  prod  \n", ""},
+		{"prod", ""},
+		{"dev\n", "confirmation failed: the name does not match database prod"},
+		{"\n", "confirmation failed: the name does not match database prod"},
+		{"", "failed to read the confirmation: EOF"},
+	} {
+		var buf bytes.Buffer
+		var actual string
+		if err := promptConfirmation(strings.NewReader(v.input), &buf, "prod", changes); err != nil {
+			actual = err.Error()
+		}
+		if actual != v.expect {
+			t.Errorf("promptConfirmation(%q) => %q; want %q", v.input, actual, v.expect)
+		}
+		prompt := "database prod is protected and the following changes are destructive:\n" +
+			"  DROP TABLE `post`\n" +
+			"type the name of the database to continue: "
+		if diff := cmp.Diff(buf.String(), prompt); diff != "" {
+			t.Errorf("(-got +want)\n%v", diff)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/naoina/migu"
+	"github.com/naoina/migu/dialect"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	reset := &reset{}
+	resetCmd := &cobra.Command{
+		Use:   "reset [OPTIONS] --env ENV [FILE|DIRECTORY]",
+		Short: "drop and re-create the tables of the development database",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return reset.Execute(args, option)
+		},
+	}
+	resetCmd.Flags().StringVar(&reset.Env, "env", "", "The environment in the config file to reset")
+	resetCmd.Flags().BoolVar(&reset.All, "all", false, "Drop all tables in the database including the tables that are not declared by Go's structs")
+	resetCmd.Flags().BoolVar(&reset.DryRun, "dry-run", false, "")
+	resetCmd.Flags().BoolVarP(&reset.Quiet, "quiet", "q", false, "")
+	resetCmd.SetUsageTemplate(usageTemplate + "\nWith no FILE, or when FILE is -, read standard input.\n")
+	rootCmd.AddCommand(resetCmd)
+}
+
+type reset struct {
+	Env    string
+	All    bool
+	DryRun bool
+	Quiet  bool
+
+	redactor    *migu.Redactor
+	tablePrefix string
+}
+
+func (r *reset) Execute(args []string, opt *Option) (err error) {
+	var file string
+	switch len(args) {
+	case 0:
+	case 1:
+		file = args[0]
+	default:
+		return fmt.Errorf("too many arguments")
+	}
+	if r.Env == "" {
+		return fmt.Errorf("--env is required")
+	}
+	env, err := opt.global.Config.environment(r.Env)
+	if err != nil {
+		return err
+	}
+	if env.Database == "" {
+		return fmt.Errorf("database of environment %s is not specified", r.Env)
+	}
+	if env.Protected || opt.global.Config.isProtectedDatabase(env.Database) {
+		return fmt.Errorf("refusing to reset the protected environment: %s", r.Env)
+	}
+	r.redactor = opt.global.redactor
+	r.tablePrefix = opt.global.tablePrefix
+	d, closer, err := newDialect(env.Database, opt)
+	if err != nil {
+		return err
+	}
+	defer closeDialect(closer, &err)
+	return r.run(d, file, env)
+}
+
+func (r *reset) run(d dialect.Dialect, file string, env *Environment) error {
+	var src interface{}
+	switch file {
+	case "", "-":
+		file = ""
+		src = os.Stdin
+	}
+	var opts []migu.Option
+	if r.tablePrefix != "" {
+		opts = append(opts, migu.WithTablePrefix(r.tablePrefix))
+	}
+	changes, err := migu.ResetChanges(d, file, src, r.All, opts...)
+	if err != nil {
+		return err
+	}
+	var sqls []string
+	for _, c := range changes {
+		sqls = append(sqls, c.SQLs...)
+	}
+	for _, seed := range env.Seeds {
+		stmts, err := migu.SeedStatements(seed, nil)
+		if err != nil {
+			return err
+		}
+		sqls = append(sqls, stmts...)
+	}
+	if !r.DryRun {
+		if err := migu.CheckFreeze(d); err != nil {
+			return err
+		}
+	}
+	marker := "dry-run "
+	if !r.DryRun {
+		marker = ""
+	}
+	var tx dialect.Transactioner
+	if !r.DryRun {
+		if tx, err = d.Begin(); err != nil {
+			return err
+		}
+	}
+	for _, sql := range sqls {
+		r.printf("--------%sapplying--------\n", marker)
+		r.printf("%s\n", r.redactor.Redact(sql))
+		start := time.Now()
+		if !r.DryRun {
+			if err := tx.Exec(sql); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+		r.printf("--------%sdone %.3fs--------\n", marker, time.Since(start).Seconds())
+	}
+	if r.DryRun {
+		return nil
+	}
+	return tx.Commit()
+}
+
+func (r *reset) printf(format string, a ...interface{}) (int, error) {
+	if r.Quiet {
+		return 0, nil
+	}
+	return fmt.Printf(format, a...)
+}
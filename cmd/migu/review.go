@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/naoina/migu"
+)
+
+const (
+	reviewFormatSQL      = "sql"
+	reviewFormatMarkdown = "markdown"
+	reviewFormatJSON     = "json"
+)
+
+// review is the summary of the schema changes for the review of the pull request.
+type review struct {
+	Tables      []*reviewTable  `json:"tables"`
+	Destructive []*reviewChange `json:"destructive"`
+}
+
+// reviewTable is the changes of a table in review.
+type reviewTable struct {
+	Table   string          `json:"table"`
+	Changes []*reviewChange `json:"changes"`
+}
+
+// reviewChange is a change in review.
+type reviewChange struct {
+	Kind        migu.ChangeKind `json:"kind"`
+	Description string          `json:"description"`
+	Destructive bool            `json:"destructive"`
+	SQLs        []string        `json:"sqls"`
+}
+
+// newReview returns the review of the changes. The tables are in the order of their first changes.
+func newReview(changes []*migu.Change) *review {
+	r := &review{
+		Tables:      []*reviewTable{},
+		Destructive: []*reviewChange{},
+	}
+	tables := map[string]*reviewTable{}
+	for _, c := range changes {
+		t, ok := tables[c.Table]
+		if !ok {
+			t = &reviewTable{Table: c.Table}
+			tables[c.Table] = t
+			r.Tables = append(r.Tables, t)
+		}
+		rc := &reviewChange{
+			Kind:        c.Kind,
+			Description: describeChange(c),
+			Destructive: c.IsDestructive(),
+			SQLs:        c.SQLs,
+		}
+		t.Changes = append(t.Changes, rc)
+		if rc.Destructive {
+			r.Destructive = append(r.Destructive, rc)
+		}
+	}
+	return r
+}
+
+// writeReview writes the review of the changes in the format for the bots to post to the pull request.
+// The markdown format is the comment for GitHub and GitLab, and the JSON format is for the bots that make their
+// own comments.
+func writeReview(w io.Writer, format string, changes []*migu.Change) error {
+	r := newReview(changes)
+	switch format {
+	case reviewFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case reviewFormatMarkdown:
+		return r.writeMarkdown(w)
+	}
+	return fmt.Errorf("unknown format: %s", format)
+}
+
+func (r *review) writeMarkdown(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("### Schema changes\n\n")
+	if len(r.Tables) == 0 {
+		b.WriteString("No schema changes.\n")
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+	if len(r.Destructive) > 0 {
+		fmt.Fprintf(&b, "> :warning: **%d destructive change(s)** that may lose the data or break the running application:\n", len(r.Destructive))
+		for _, c := range r.Destructive {
+			fmt.Fprintf(&b, "> - `%s`\n", c.Description)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("| Table | Changes |\n")
+	b.WriteString("| --- | --- |\n")
+	for _, t := range r.Tables {
+		kinds := make([]string, 0, len(t.Changes))
+		counts := map[migu.ChangeKind]int{}
+		for _, c := range t.Changes {
+			if counts[c.Kind]++; counts[c.Kind] == 1 {
+				kinds = append(kinds, string(c.Kind))
+			}
+		}
+		for i, kind := range kinds {
+			if n := counts[migu.ChangeKind(kind)]; n > 1 {
+				kinds[i] = fmt.Sprintf("%s ×%d", kind, n)
+			}
+		}
+		fmt.Fprintf(&b, "| `%s` | %s |\n", t.Table, strings.Join(kinds, ", "))
+	}
+	for _, t := range r.Tables {
+		fmt.Fprintf(&b, "\n<details><summary><code>%s</code></summary>\n\n```sql\n", t.Table)
+		for _, c := range t.Changes {
+			fmt.Fprintf(&b, "-- %s\n", c.Description)
+			for _, sql := range c.SQLs {
+				fmt.Fprintf(&b, "%s;\n", sql)
+			}
+		}
+		b.WriteString("```\n\n</details>\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/naoina/migu"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rollback := &rollback{}
+	rollbackCmd := &cobra.Command{
+		Use:   "rollback [OPTIONS] DATABASE DIRECTORY",
+		Short: "revert the last applied migrations by their down migrations",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return rollback.Execute(args, option)
+		},
+	}
+	rollbackCmd.Flags().IntVarP(&rollback.Steps, "steps", "n", 1, "The number of the migrations to revert")
+	rollbackCmd.Flags().BoolVar(&rollback.DryRun, "dry-run", false, "")
+	rollbackCmd.Flags().BoolVarP(&rollback.Quiet, "quiet", "q", false, "")
+	rollbackCmd.Flags().DurationVar(&rollback.Heartbeat.Interval, "heartbeat-interval", 10*time.Second, "Interval of the heartbeats on another connection that show the progress of the running statement (0 means no heartbeat)")
+	rollbackCmd.SetUsageTemplate(usageTemplate + "\nDIRECTORY has the migration files of generate, and the migrations are reverted in the reverse order of the versions recorded in the " + migu.MigrationsTable + " table.\n")
+	rootCmd.AddCommand(rollbackCmd)
+}
+
+type rollback struct {
+	apply
+
+	Steps int
+}
+
+func (r *rollback) Execute(args []string, opt *Option) (err error) {
+	var dbname, dir string
+	switch len(args) {
+	case 0, 1:
+		return fmt.Errorf("too few arguments")
+	case 2:
+		dbname, dir = args[0], args[1]
+	default:
+		return fmt.Errorf("too many arguments")
+	}
+	if r.Steps < 1 {
+		return fmt.Errorf("--steps must be greater than 0")
+	}
+	migrations, err := migu.ReadMigrations(dir)
+	if err != nil {
+		return err
+	}
+	d, closer, err := newDialect(dbname, opt)
+	if err != nil {
+		return err
+	}
+	defer closeDialect(closer, &err)
+	changes, err := migu.RollbackChanges(d, migrations, r.Steps)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		r.printf("--------no applied migrations--------\n")
+		return nil
+	}
+	r.prepare(dbname, opt)
+	return r.run(d, changes)
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/naoina/migu"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	serve := &serve{}
+	serveCmd := &cobra.Command{
+		Use:   "serve [OPTIONS] [FILE|DIRECTORY]",
+		Short: "run the schema registry that shows the environments which drift from Go's structs",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return serve.Execute(args, option)
+		},
+	}
+	serveCmd.Flags().StringVar(&serve.Listen, "listen", ":8080", "The address to listen on")
+	serveCmd.Flags().DurationVar(&serve.StaleAfter, "stale-after", time.Hour, "Show the environments that have not reported for the duration as stale (0 means never)")
+	serveCmd.Flags().BoolVar(&serve.IgnoreColumnOrder, "ignore-column-order", false, "Compare the tables independently of the order of the columns (requires report-schema --ignore-column-order)")
+	serveCmd.SetUsageTemplate(usageTemplate + "\nWith no FILE, or when FILE is -, read standard input.\nThe environments report the hashes of their tables by report-schema.\n")
+	rootCmd.AddCommand(serveCmd)
+
+	reportSchema := &reportSchema{}
+	reportSchemaCmd := &cobra.Command{
+		Use:   "report-schema [OPTIONS] --registry URL --env ENV",
+		Short: "report the hashes of the tables of the environment to the schema registry",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return reportSchema.Execute(args, option)
+		},
+	}
+	reportSchemaCmd.Flags().StringVar(&reportSchema.Registry, "registry", "", "The URL of the schema registry that is run by serve")
+	reportSchemaCmd.Flags().StringVar(&reportSchema.Env, "env", "", "The environment in the config file to report")
+	reportSchemaCmd.Flags().DurationVar(&reportSchema.Interval, "interval", 0, "Report repeatedly at the interval (0 means only once)")
+	reportSchemaCmd.Flags().BoolVar(&reportSchema.IgnoreColumnOrder, "ignore-column-order", false, "Compute the hashes independently of the order of the columns")
+	reportSchemaCmd.SetUsageTemplate(usageTemplate)
+	rootCmd.AddCommand(reportSchemaCmd)
+}
+
+type serve struct {
+	Listen            string
+	StaleAfter        time.Duration
+	IgnoreColumnOrder bool
+}
+
+func (s *serve) Execute(args []string, opt *Option) error {
+	var file string
+	switch len(args) {
+	case 0:
+	case 1:
+		file = args[0]
+	default:
+		return fmt.Errorf("too many arguments")
+	}
+	d, err := newOfflineDialect(opt)
+	if err != nil {
+		return err
+	}
+	var src interface{}
+	switch file {
+	case "", "-":
+		file = ""
+		src = os.Stdin
+	}
+	hashes, err := migu.TableHashes(d, file, src, hashOptions(s.IgnoreColumnOrder, opt)...)
+	if err != nil {
+		return err
+	}
+	registry := migu.NewRegistry(hashes)
+	registry.StaleAfter = s.StaleAfter
+	fmt.Fprintf(os.Stderr, "--------serving the schema registry of %d table(s) on %s--------\n", len(hashes), s.Listen)
+	return http.ListenAndServe(s.Listen, registry)
+}
+
+type reportSchema struct {
+	Registry          string
+	Env               string
+	Interval          time.Duration
+	IgnoreColumnOrder bool
+}
+
+func (r *reportSchema) Execute(args []string, opt *Option) (err error) {
+	if len(args) > 0 {
+		return fmt.Errorf("too many arguments")
+	}
+	if r.Registry == "" {
+		return fmt.Errorf("--registry is required")
+	}
+	if r.Env == "" {
+		return fmt.Errorf("--env is required")
+	}
+	env, err := opt.global.Config.environment(r.Env)
+	if err != nil {
+		return err
+	}
+	if env.Database == "" {
+		return fmt.Errorf("database of environment %s is not specified", r.Env)
+	}
+	d, closer, err := newDialect(env.Database, opt)
+	if err != nil {
+		return err
+	}
+	defer closeDialect(closer, &err)
+	for {
+		hashes, err := migu.DatabaseTableHashes(d, hashOptions(r.IgnoreColumnOrder, opt)...)
+		if err == nil {
+			err = migu.PostSchemaReport(r.Registry, &migu.SchemaReport{
+				Environment: r.Env,
+				Database:    opt.global.redactor.Redact(env.Database),
+				Hashes:      hashes,
+				ReportedAt:  time.Now(),
+			})
+		}
+		if r.Interval <= 0 {
+			return err
+		}
+		// The failures are retried at the next interval since the registry or the database may be temporarily down.
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "-- warning: %v\n", opt.global.redactor.RedactError(err))
+		}
+		time.Sleep(r.Interval)
+	}
+}
+
+// hashOptions returns the options of the table hashes that must be the same between serve and report-schema.
+func hashOptions(ignoreColumnOrder bool, opt *Option) []migu.Option {
+	var opts []migu.Option
+	if ignoreColumnOrder {
+		opts = append(opts, migu.WithIgnoreColumnOrder())
+	}
+	if prefix := opt.global.tablePrefix; prefix != "" {
+		opts = append(opts, migu.WithTablePrefix(prefix))
+	}
+	return opts
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+)
+
+// readKeyFile reads the secret key from the file, such as the project key to sign and verify the plans and the key of
+// the hash anonymizer.
+func readKeyFile(filename string) ([]byte, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	key := bytes.TrimSpace(b)
+	if len(key) == 0 {
+		return nil, fmt.Errorf("key file %s is empty", filename)
+	}
+	return key, nil
+}
@@ -0,0 +1,30 @@
+//go:build cgo && !nosqlite
+// +build cgo,!nosqlite
+
+package main
+
+import (
+	"database/sql"
+	"net/url"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// openSQLite opens the database file of SQLite with the connection parameters and the session statements of env
+// if not nil. The parameters are the ones of mattn/go-sqlite3 such as _foreign_keys.
+func openSQLite(dbname string, env *Environment) (*sql.DB, error) {
+	dsn := dbname
+	var stmts []string
+	if env != nil {
+		params := url.Values{}
+		for k, v := range env.Params {
+			params.Set(k, v)
+		}
+		if len(params) > 0 {
+			dsn = "file:" + dbname + "?" + params.Encode()
+		}
+		stmts = env.Session
+	}
+	connector := &dsnConnector{dsn: dsn, driver: &sqlite3.SQLiteDriver{}}
+	return openDB(connector, stmts), nil
+}
@@ -0,0 +1,15 @@
+//go:build !cgo || nosqlite
+// +build !cgo nosqlite
+
+package main
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// openSQLite returns the error since the driver of SQLite requires cgo, and it is excluded without cgo or by nosqlite
+// build tag.
+func openSQLite(dbname string, env *Environment) (*sql.DB, error) {
+	return nil, fmt.Errorf("SQLite is not supported by this build of migu (built without cgo or with nosqlite tag)")
+}
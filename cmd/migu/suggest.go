@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/naoina/migu"
+	"github.com/naoina/migu/dialect"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	suggest := &suggest{}
+	suggestCmd := &cobra.Command{
+		Use:   "suggest-indexes [OPTIONS] DATABASE [FILE|DIRECTORY]",
+		Short: "suggest additions and removals of indexes (advisory only)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return suggest.Execute(args, option)
+		},
+	}
+	suggestCmd.Flags().StringVar(&suggest.DigestFile, "digest-file", "", "Read additional query digests (e.g. from the slow query log) from the file. One query per line, lines starting with # are ignored")
+	suggestCmd.SetUsageTemplate(usageTemplate + "\nWith no FILE, or when FILE is -, read standard input.\nThe suggestions are never applied.\n")
+	rootCmd.AddCommand(suggestCmd)
+}
+
+type suggest struct {
+	DigestFile string
+}
+
+func (s *suggest) Execute(args []string, opt *Option) (err error) {
+	var dbname string
+	var file string
+	switch len(args) {
+	case 0:
+		return fmt.Errorf("too few arguments")
+	case 1:
+		dbname = args[0]
+	case 2:
+		dbname, file = args[0], args[1]
+	default:
+		return fmt.Errorf("too many arguments")
+	}
+	var queries []string
+	if s.DigestFile != "" {
+		var err error
+		if queries, err = readDigestFile(s.DigestFile); err != nil {
+			return err
+		}
+	}
+	di, closer, err := newDialect(dbname, opt)
+	if err != nil {
+		return err
+	}
+	defer closeDialect(closer, &err)
+	return s.run(di, file, queries)
+}
+
+func (s *suggest) run(d dialect.Dialect, file string, queries []string) error {
+	var src interface{}
+	switch file {
+	case "", "-":
+		file = ""
+		src = os.Stdin
+	}
+	suggestions, err := migu.SuggestIndexes(d, file, src, queries)
+	if err != nil {
+		return err
+	}
+	if len(suggestions) == 0 {
+		fmt.Println("no suggestions")
+		return nil
+	}
+	for _, suggestion := range suggestions {
+		fmt.Println(suggestion)
+	}
+	return nil
+}
+
+func readDigestFile(fname string) ([]string, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read digest file: %w", err)
+	}
+	defer f.Close()
+	var queries []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		queries = append(queries, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read digest file: %w", err)
+	}
+	return queries, nil
+}
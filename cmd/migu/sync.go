@@ -2,8 +2,9 @@ package main
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
-	"path"
+	"strings"
 	"time"
 
 	"github.com/naoina/migu"
@@ -26,16 +27,63 @@ func init() {
 	}
 	syncCmd.Flags().BoolVar(&sync.DryRun, "dry-run", false, "")
 	syncCmd.Flags().BoolVarP(&sync.Quiet, "quiet", "q", false, "")
+	syncCmd.Flags().BoolVar(&sync.Explain, "explain", false, "Show the estimated number of rows to be scanned by each data-affecting change")
+	syncCmd.Flags().StringVar(&sync.SnapshotDir, "snapshot-dir", "", "Write the snapshot of the schema into the directory after applying")
+	syncCmd.Flags().BoolVar(&sync.ForceIndexDrop, "force-index-drop", false, "Drop the indexes even if they look actively used")
+	syncCmd.Flags().StringVar(&sync.DedupeStrategy, "dedupe-strategy", dedupeFail, "How to resolve the duplicate values of the columns before creating the unique indexes on them (fail|skip|ignore|exec:COMMAND)")
+	syncCmd.Flags().BoolVar(&sync.AllowDestructive, "allow-destructive", false, "Apply the changes that lose the data such as DROP TABLE, DROP COLUMN and the narrowings of the column types")
+	syncCmd.Flags().Int64Var(&sync.HealthCheck.MaxThreadsRunning, "max-threads-running", 0, "Pause applying while Threads_running exceeds the value (0 means no limit)")
+	syncCmd.Flags().DurationVar(&sync.HealthCheck.MaxReplicationLag, "max-replication-lag", 0, "Pause applying while the replication lag of the server exceeds the value (0 means no limit)")
+	syncCmd.Flags().Float64Var(&sync.HealthCheck.MaxCPUUtilization, "max-cpu-utilization", 0, "Pause applying while the CPU utilization of Cloud Spanner instance exceeds the value in the range of 0 to 1 (0 means no limit)")
+	syncCmd.Flags().DurationVar(&sync.HealthCheck.Interval, "health-check-interval", 5*time.Second, "Interval of the health checks while the database is unhealthy")
+	syncCmd.Flags().DurationVar(&sync.HealthCheck.Timeout, "health-check-timeout", 10*time.Minute, "Abort if the database does not get healthy within the duration")
+	syncCmd.Flags().BoolVar(&sync.CommitPerStatement, "commit-per-statement", false, "Apply and commit each statement in its own transaction, and run the health checks between the statements")
+	syncCmd.Flags().BoolVar(&sync.CreateIfMissing, "create-if-missing", false, "Create the database before synchronizing if it does not exist")
+	syncCmd.Flags().StringVar(&sync.ReportFile, "report-file", "", "Write the report of the run into the file in JSON")
+	syncCmd.Flags().StringVar(&sync.SigningKey, "signing-key", "", "Sign the plan in the report with the project key in the file, and record the git commit of FILE")
+	syncCmd.Flags().BoolVar(&sync.Analyze, "analyze", false, "Update the statistics of the tables that are rebuilt or get the new indexes after applying")
+	syncCmd.Flags().IntVar(&sync.Reconnect, "reconnect", 3, "The number of times to reconnect and resume synchronizing when the connection to the database is lost")
+	syncCmd.Flags().DurationVar(&sync.Heartbeat.Interval, "heartbeat-interval", 10*time.Second, "Interval of the heartbeats on another connection that show the progress of the running statement (0 means no heartbeat)")
+	syncCmd.Flags().StringArrayVar(&sync.Tags, "tag", nil, "Prepend the metadata in the form of key=value to every executed statement as a comment (can be repeated)")
+	sync.diffOption.addFlags(syncCmd.Flags())
 	syncCmd.SetUsageTemplate(usageTemplate + "\nWith no FILE, or when FILE is -, read standard input.\n")
 	rootCmd.AddCommand(syncCmd)
 }
 
 type sync struct {
-	DryRun bool
-	Quiet  bool
+	diffOption
+
+	DryRun           bool
+	Quiet            bool
+	Explain          bool
+	SnapshotDir      string
+	ForceIndexDrop   bool
+	AllowDestructive bool
+	DedupeStrategy   string
+	HealthCheck      migu.HealthCheck
+	Heartbeat        migu.Heartbeat
+	Tags             []string
+	ReportFile       string
+	SigningKey       string
+	CreateIfMissing  bool
+	Analyze          bool
+	Reconnect        int
+
+	// CommitPerStatement applies each statement in its own transaction so that the transaction is not held open
+	// while waiting for the database to get healthy.
+	CommitPerStatement bool
+
+	tags      []migu.StatementTag
+	users     []dialect.User
+	database  dialect.DatabaseOptions
+	redactor  *migu.Redactor
+	report    *migu.RunReport
+	key       []byte
+	commit    string
+	protected string
 }
 
-func (s *sync) Execute(args []string, opt *Option) error {
+func (s *sync) Execute(args []string, opt *Option) (err error) {
 	var dbname string
 	var file string
 	switch len(args) {
@@ -48,65 +96,313 @@ func (s *sync) Execute(args []string, opt *Option) error {
 	default:
 		return fmt.Errorf("too many arguments")
 	}
-	var opts []dialect.Option
-	if columnTypes := opt.global.ColumnTypes; len(columnTypes) != 0 {
-		opts = append(opts, dialect.WithColumnType(columnTypes))
+	s.diffOption.budget = opt.global.Config.Budget
+	s.diffOption.comparison = opt.global.Config.Comparison
+	s.diffOption.costOrder = opt.global.Config.CostOrder
+	s.diffOption.tablePrefix = opt.global.tablePrefix
+	if err := s.diffOption.validate(); err != nil {
+		return err
 	}
-	var di dialect.Dialect
-	switch typ := opt.global.DatabaseType; typ {
-	case databaseTypeMySQL, databaseTypeMariaDB:
-		db, err := openDatabase(dbname)
+	if err := validateDedupeStrategy(s.DedupeStrategy); err != nil {
+		return err
+	}
+	for _, t := range s.Tags {
+		tag, err := migu.ParseStatementTag(t)
 		if err != nil {
 			return err
 		}
-		defer db.Close()
-		di = dialect.NewMySQL(db, opts...)
-	case databaseTypeSpanner:
-		di = dialect.NewSpanner(path.Join("projects", opt.spanner.Project, "instances", opt.spanner.Instance, "databases", dbname), opts...)
-	default:
-		return fmt.Errorf("BUG: unknown database type: %s", typ)
+		s.tags = append(s.tags, tag)
+	}
+	if s.SigningKey != "" {
+		if s.ReportFile == "" {
+			return fmt.Errorf("--signing-key requires --report-file")
+		}
+		var err error
+		if s.key, err = readKeyFile(s.SigningKey); err != nil {
+			return err
+		}
+		if s.commit, err = gitCommit(file); err != nil {
+			return fmt.Errorf("cannot sign the plan: %w", err)
+		}
 	}
 	if !s.DryRun {
 		dryRunMarker = ""
+		if s.CreateIfMissing {
+			created, err := createDatabase(dbname, opt)
+			if err != nil {
+				return err
+			}
+			if created {
+				s.printf("--------created database %s--------\n", dbname)
+			}
+		}
+	}
+	di, closer, err := newDialect(dbname, opt)
+	if err != nil {
+		return err
+	}
+	defer closeDialect(closer, &err)
+	s.protected = protectedDatabase(dbname, opt)
+	if env := opt.global.Config.databaseEnvironment(dbname); env != nil && env.Analyze {
+		s.Analyze = true
+	}
+	if s.Phase == "" || migu.Phase(s.Phase) == migu.PhaseExpand {
+		s.users = opt.global.Config.Users
+		s.database = opt.global.Config.Database
+	}
+	s.redactor = opt.global.redactor
+	s.report = migu.NewRunReport("sync", dbname, s.DryRun)
+	s.report.Commit = s.commit
+	s.Heartbeat.OnProgress = func(p dialect.Progress) {
+		s.printf("--------progress: %s--------\n", formatProgress(p))
+	}
+	s.Heartbeat.OnFailure = func(err error) {
+		s.report.Warn(err.Error())
+		fmt.Fprintf(os.Stderr, "-- warning: %v\n", s.redactor.RedactError(err))
+	}
+	s.HealthCheck.OnUnhealthy = func(reason string) {
+		s.report.Warn("pausing: " + reason)
+		s.printf("--------pausing: %s--------\n", reason)
 	}
-	return s.run(di, file)
-}
-
-func (s *sync) run(d dialect.Dialect, file string) error {
 	var src interface{}
 	switch file {
 	case "", "-":
+		// Read all at once since it may be read again to resume.
 		file = ""
-		src = os.Stdin
+		if src, err = ioutil.ReadAll(os.Stdin); err != nil {
+			return err
+		}
 	}
-	sqls, err := migu.Diff(d, file, src)
+	for reconnects := 0; ; reconnects++ {
+		// Resuming is safe because the differences are computed again from the current schema of the database.
+		if err = s.run(di, file, src); err == nil || s.DryRun || reconnects >= s.Reconnect || !isConnectionLost(err) {
+			break
+		}
+		s.report.Warn(fmt.Sprintf("connection lost: %v", err))
+		s.printf("--------connection lost: %v; reconnecting (%d/%d)--------\n", s.redactor.RedactError(err), reconnects+1, s.Reconnect)
+		time.Sleep(reconnectInterval)
+	}
+	if s.ReportFile != "" {
+		s.report.Finish(err)
+		// The plan is signed after redacting since the report file is what is verified by apply.
+		s.redactor.RedactReport(s.report)
+		if s.key != nil && err == nil {
+			err = migu.SignPlan(s.report, s.key)
+		}
+		if werr := migu.WriteRunReport(s.ReportFile, s.report); err == nil {
+			err = werr
+		}
+	}
+	return err
+}
+
+func (s *sync) run(d dialect.Dialect, file string, src interface{}) error {
+	changes, err := migu.DiffChanges(d, file, src, append(s.options(), migu.WithRedactor(s.redactor))...)
 	if err != nil {
 		return err
 	}
-	var tx dialect.Transactioner
+	// The settings of the database are altered first so that the tables to be created inherit them.
+	databaseChanges, err := migu.DatabaseChanges(d, s.database)
+	if err != nil {
+		return err
+	}
+	changes = append(databaseChanges, changes...)
+	for _, w := range degradationWarnings(d) {
+		s.report.Warn(w)
+		fmt.Fprintf(os.Stderr, "-- warning: %s\n", w)
+	}
+	userChanges, err := migu.UserChanges(d, s.users)
+	if err != nil {
+		return err
+	}
+	changes = append(changes, userChanges...)
+	if s.Explain {
+		if err := migu.Estimate(d, changes); err != nil {
+			return err
+		}
+	}
+	if changes, err = checkUniqueIndexes(d, changes, s.DedupeStrategy, s.DryRun); err != nil {
+		return err
+	}
+	if err := checkForeignKeys(d, changes, s.DryRun); err != nil {
+		return err
+	}
+	s.report.SetPlan(changes)
+	if !s.ForceIndexDrop {
+		if err := checkIndexDrops(d, changes); err != nil {
+			return err
+		}
+	}
+	if !s.AllowDestructive {
+		if err := checkDestructiveChanges(changes, s.DryRun, s.redactor); err != nil {
+			return err
+		}
+	}
+	if err := confirmDestructive(s.protected, s.DryRun, changes); err != nil {
+		return err
+	}
 	if !s.DryRun {
-		if tx, err = d.Begin(); err != nil {
+		if err := migu.CheckFreeze(d); err != nil {
+			return err
+		}
+	}
+	var tx dialect.Transactioner
+	begin := func() (err error) {
+		if !s.DryRun && tx == nil {
+			tx, err = d.Begin()
+		}
+		return err
+	}
+	commit := func() error {
+		if tx == nil {
+			return nil
+		}
+		err := tx.Commit()
+		tx = nil
+		return err
+	}
+	rollback := func() {
+		if tx != nil {
+			tx.Rollback()
+			tx = nil
+		}
+	}
+	perStatement := s.CommitPerStatement
+	if !perStatement && !s.DryRun && s.HealthCheck.IsEnabled() {
+		// All the changes are applied in one transaction, so that the health is checked only before it begins.
+		w := "the health is checked only before applying since --commit-per-statement is not specified"
+		s.report.Warn(w)
+		fmt.Fprintf(os.Stderr, "-- warning: %s\n", w)
+		if err := s.HealthCheck.Wait(d); err != nil {
 			return err
 		}
 	}
-	for _, sql := range sqls {
-		s.printf("--------%sapplying--------\n", dryRunMarker)
-		s.printf("%s\n", sql)
-		start := time.Now()
-		if !s.DryRun {
-			if err := tx.Exec(sql); err != nil {
-				tx.Rollback()
+	if !perStatement {
+		if err := begin(); err != nil {
+			return err
+		}
+	}
+	for _, c := range changes {
+		for _, sql := range c.SQLs {
+			if sql, err = migu.TagStatement(sql, s.tags); err != nil {
+				rollback()
+				return err
+			}
+			s.printf("--------%sapplying--------\n", dryRunMarker)
+			if s.Explain && c.IsDataAffecting() {
+				s.printf("-- estimated rows to be scanned: %d\n", c.EstimatedRows)
+			}
+			s.printf("%s\n", s.redactor.Redact(sql))
+			start := time.Now()
+			if !s.DryRun {
+				if perStatement {
+					if err := commit(); err != nil {
+						return err
+					}
+					if err := s.HealthCheck.Wait(d); err != nil {
+						return err
+					}
+					if err := begin(); err != nil {
+						return err
+					}
+				}
+				stop := s.Heartbeat.Start(d, sql)
+				err := tx.Exec(sql)
+				stop()
+				if err != nil {
+					s.report.AddStatement(c, sql, start, err)
+					rollback()
+					return err
+				}
+			}
+			s.report.AddStatement(c, sql, start, nil)
+			d := time.Since(start)
+			s.printf("--------%sdone %.3fs--------\n", dryRunMarker, d.Seconds()/time.Second.Seconds())
+		}
+	}
+	if s.Analyze {
+		for _, sql := range migu.AnalyzeSQLs(d, changes) {
+			if sql, err = migu.TagStatement(sql, s.tags); err != nil {
+				rollback()
 				return err
 			}
+			s.printf("--------%sanalyzing--------\n", dryRunMarker)
+			s.printf("%s\n", s.redactor.Redact(sql))
+			start := time.Now()
+			if !s.DryRun {
+				if err := begin(); err != nil {
+					return err
+				}
+				if err := tx.Exec(sql); err != nil {
+					rollback()
+					return err
+				}
+			}
+			s.printf("--------%sdone %.3fs--------\n", dryRunMarker, time.Since(start).Seconds())
 		}
-		d := time.Since(start)
-		s.printf("--------%sdone %.3fs--------\n", dryRunMarker, d.Seconds()/time.Second.Seconds())
 	}
 	if s.DryRun {
 		return nil
-	} else {
-		return tx.Commit()
 	}
+	if err := commit(); err != nil {
+		return err
+	}
+	if s.SnapshotDir != "" {
+		if _, err := migu.WriteSnapshot(d, s.SnapshotDir, time.Now()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// orphanLimit is the maximum number of the values of the orphan rows that are reported for each foreign key.
+const orphanLimit = 5
+
+// checkForeignKeys prints the foreign keys to be added on the rows that reference no rows, and returns an error
+// unless dryRun.
+func checkForeignKeys(d dialect.Dialect, changes []*migu.Change, dryRun bool) error {
+	violations, err := migu.ForeignKeyViolations(d, changes, orphanLimit)
+	if err != nil {
+		return err
+	}
+	var orphans int64
+	for _, v := range violations {
+		fmt.Fprintf(os.Stderr, "-- %s\n", v)
+		orphans += v.Orphans.Count
+	}
+	if len(violations) == 0 || dryRun {
+		return nil
+	}
+	return fmt.Errorf("refusing to add %d foreign key(s) on %d orphan row(s); delete or fix the rows first", len(violations), orphans)
+}
+
+// checkIndexDrops returns an error if any index to be dropped looks actively used.
+func checkIndexDrops(d dialect.Dialect, changes []*migu.Change) error {
+	actives, err := migu.ActiveIndexDrops(d, changes)
+	if err != nil {
+		return err
+	}
+	if len(actives) == 0 {
+		return nil
+	}
+	for _, a := range actives {
+		fmt.Fprintf(os.Stderr, "-- %s\n", a)
+	}
+	return fmt.Errorf("refusing to drop %d actively used index(es); use --force-index-drop to drop them anyway", len(actives))
+}
+
+// checkDestructiveChanges prints the statements of the changes that lose the data, and returns an error unless dryRun.
+func checkDestructiveChanges(changes []*migu.Change, dryRun bool, redactor *migu.Redactor) error {
+	blocked := destructiveChanges(changes)
+	for _, c := range blocked {
+		for _, sql := range c.SQLs {
+			fmt.Fprintf(os.Stderr, "-- blocked: %s\n", redactor.Redact(sql))
+		}
+	}
+	if len(blocked) == 0 || dryRun {
+		return nil
+	}
+	return fmt.Errorf("refusing to apply %d destructive change(s); use --allow-destructive to apply them anyway", len(blocked))
 }
 
 func (s *sync) printf(format string, a ...interface{}) (int, error) {
@@ -115,3 +411,12 @@ func (s *sync) printf(format string, a ...interface{}) (int, error) {
 	}
 	return fmt.Printf(format, a...)
 }
+
+// formatProgress returns the progress of the running statement for the progress output.
+func formatProgress(p dialect.Progress) string {
+	stage := strings.TrimPrefix(p.Stage, "stage/")
+	if percent := p.Percent(); percent >= 0 {
+		return fmt.Sprintf("%s %.1f%% (%d/%d) in %.0fs", stage, percent, p.WorkCompleted, p.WorkEstimated, p.Elapsed.Seconds())
+	}
+	return fmt.Sprintf("%s (%d) in %.0fs", stage, p.WorkCompleted, p.Elapsed.Seconds())
+}
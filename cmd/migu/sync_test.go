@@ -0,0 +1,169 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/naoina/migu"
+	"github.com/naoina/migu/dialect"
+)
+
+// analyzeDialect is the dialect that can update the statistics of the tables.
+type analyzeDialect struct {
+	columnsDialect
+}
+
+func (d *analyzeDialect) AnalyzeTableSQL(table string) []string {
+	return []string{"ANALYZE TABLE " + d.Quote(table)}
+}
+
+func TestSyncAnalyzeRedacted(t *testing.T) {
+	redactor, err := migu.NewRedactor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	redactor.AddSecrets("s3cret")
+	s := &sync{
+		DryRun:   true,
+		Analyze:  true,
+		redactor: redactor,
+		report:   &migu.RunReport{},
+	}
+	src := strings.Join([]string{
+		"package migu_test",
+		"//+migu table:s3cret_user",
+		"type User struct {",
+		"	Name string `migu:\"index\"`",
+		"}",
+	}, "\n")
+	d := &analyzeDialect{columnsDialect{
+		Dialect: dialect.NewMySQL(nil),
+		schemas: []dialect.ColumnSchema{
+			&dialect.PluginColumnSchema{Table: "s3cret_user", Column: "name", Type: "varchar(255)", Data: "varchar"},
+		},
+	}}
+	output := captureStdout(t, func() {
+		if err := s.run(d, "", src); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if !strings.Contains(output, "ANALYZE TABLE `"+migu.Redacted+"_user`") || strings.Contains(output, "s3cret") {
+		t.Errorf("sync --analyze writes %q; want the secret redacted from ANALYZE", output)
+	}
+}
+
+// columnsDialect is the dialect of the database that has the tables of the schemas.
+type columnsDialect struct {
+	dialect.Dialect
+	schemas []dialect.ColumnSchema
+}
+
+func (d *columnsDialect) ColumnSchema(tables ...string) ([]dialect.ColumnSchema, error) {
+	return d.schemas, nil
+}
+
+// captureStdout returns what fn writes to the standard output.
+func captureStdout(t *testing.T, fn func()) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() {
+		os.Stdout = stdout
+	}()
+	done := make(chan string)
+	go func() {
+		b, _ := ioutil.ReadAll(r)
+		done <- string(b)
+	}()
+	fn()
+	w.Close()
+	return <-done
+}
+
+// txDialect is the dialect that records the transactions and the health checks.
+type txDialect struct {
+	columnsDialect
+	log []string
+}
+
+func (d *txDialect) Begin() (dialect.Transactioner, error) {
+	d.log = append(d.log, "BEGIN")
+	return d, nil
+}
+
+func (d *txDialect) Exec(sql string, args ...interface{}) error {
+	d.log = append(d.log, strings.Fields(sql)[0])
+	return nil
+}
+
+func (d *txDialect) Commit() error {
+	d.log = append(d.log, "COMMIT")
+	return nil
+}
+
+func (d *txDialect) Rollback() error {
+	d.log = append(d.log, "ROLLBACK")
+	return nil
+}
+
+func (d *txDialect) Health() (dialect.Health, error) {
+	d.log = append(d.log, "HEALTH")
+	return dialect.Health{ThreadsRunning: 1, ReplicationLag: -1, CPUUtilization: -1}, nil
+}
+
+func TestSyncCommitPerStatement(t *testing.T) {
+	stderr := os.Stderr
+	defer func() {
+		os.Stderr = stderr
+	}()
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devNull.Close()
+	os.Stderr = devNull
+	src := strings.Join([]string{
+		"package migu_test",
+		"//+migu",
+		"type User struct {",
+		"	Name string",
+		"}",
+		"//+migu",
+		"type Post struct {",
+		"	Title string",
+		"}",
+	}, "\n")
+	for _, v := range []struct {
+		commitPerStatement bool
+		expect             []string
+	}{
+		{false, []string{"HEALTH", "BEGIN", "CREATE", "CREATE", "COMMIT"}},
+		{true, []string{"HEALTH", "BEGIN", "CREATE", "COMMIT", "HEALTH", "BEGIN", "CREATE", "COMMIT"}},
+	} {
+		redactor, err := migu.NewRedactor()
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := &sync{
+			Quiet:              true,
+			HealthCheck:        migu.HealthCheck{MaxThreadsRunning: 10},
+			CommitPerStatement: v.commitPerStatement,
+			redactor:           redactor,
+			report:             &migu.RunReport{},
+		}
+		d := &txDialect{columnsDialect: columnsDialect{Dialect: dialect.NewMySQL(nil)}}
+		if err := s.run(d, "", src); err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(d.log, v.expect); diff != "" {
+			t.Errorf("sync --commit-per-statement=%v: (-got +want)\n%v", v.commitPerStatement, diff)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package migu
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/naoina/migu/dialect"
+)
+
+// Attribute is the attribute of the column that is compared by ComparisonStrategy.
+type Attribute string
+
+const (
+	AttributeType          Attribute = "type"
+	AttributeNullable      Attribute = "nullable"
+	AttributeDefault       Attribute = "default"
+	AttributeExtra         Attribute = "extra"
+	AttributeComment       Attribute = "comment"
+	AttributeAutoIncrement Attribute = "auto_increment"
+	AttributeCompression   Attribute = "compression"
+	AttributeCollation     Attribute = "collation"
+)
+
+// attributes are the attributes that are compared in this order.
+var attributes = []Attribute{
+	AttributeType,
+	AttributeNullable,
+	AttributeDefault,
+	AttributeExtra,
+	AttributeComment,
+	AttributeAutoIncrement,
+	AttributeCompression,
+	AttributeCollation,
+}
+
+func parseAttribute(s string) (Attribute, error) {
+	for _, attr := range attributes {
+		if Attribute(s) == attr {
+			return attr, nil
+		}
+	}
+	return "", fmt.Errorf("unknown attribute: %s", s)
+}
+
+// ComparisonStrategy decides whether the column in the database and the column declared by Go's struct are
+// equivalent. The column is modified if any attribute is not equivalent.
+type ComparisonStrategy interface {
+	// Equal reports whether the attribute of the old column and the new column are equivalent.
+	Equal(attr Attribute, oldField, newField *dialect.Field) bool
+}
+
+// Comparator reports whether the attribute of the old column and the new column are equivalent.
+type Comparator func(oldField, newField *dialect.Field) bool
+
+type strictComparison struct{}
+
+// StrictComparison returns the strategy that compares the attributes as they are. It is the default.
+func StrictComparison() ComparisonStrategy {
+	return strictComparison{}
+}
+
+func (strictComparison) Equal(attr Attribute, oldField, newField *dialect.Field) bool {
+	switch attr {
+	case AttributeType:
+		return oldField.Type == newField.Type
+	case AttributeNullable:
+		return oldField.Nullable == newField.Nullable
+	case AttributeDefault:
+		return oldField.Default == newField.Default
+	case AttributeExtra:
+		return oldField.Extra == newField.Extra && oldField.OnUpdate == newField.OnUpdate
+	case AttributeComment:
+		return oldField.Comment == newField.Comment
+	case AttributeAutoIncrement:
+		return oldField.AutoIncrement == newField.AutoIncrement
+	case AttributeCompression:
+		return oldField.Compressed == newField.Compressed
+	case AttributeCollation:
+		// The character set and the collation are compared only if they are declared by Go's struct, since the
+		// columns inherit them from the table.
+		return (newField.Charset == "" || normalizeCharset(oldField.Charset) == normalizeCharset(newField.Charset)) &&
+			(newField.Collation == "" || normalizeCharset(oldField.Collation) == normalizeCharset(newField.Collation))
+	}
+	return true
+}
+
+// normalizeCharset returns the name of the character set or the collation in lower case. utf8 is replaced with
+// utf8mb3 since MySQL 8.0 reports utf8 as utf8mb3. (e.g. utf8_bin is utf8mb3_bin)
+func normalizeCharset(s string) string {
+	s = strings.ToLower(s)
+	if s == "utf8" || strings.HasPrefix(s, "utf8_") {
+		s = "utf8mb3" + s[len("utf8"):]
+	}
+	return s
+}
+
+type lenientComparison struct{}
+
+// LenientComparison returns the strategy that ignores the differences that do not change the data.
+// The types and the extras are compared case-insensitively and the display widths of the integer types such as
+// INT(11) are ignored. The comments including the classifications are not compared.
+func LenientComparison() ComparisonStrategy {
+	return lenientComparison{}
+}
+
+func (lenientComparison) Equal(attr Attribute, oldField, newField *dialect.Field) bool {
+	switch attr {
+	case AttributeType:
+		return normalizeType(oldField.Type) == normalizeType(newField.Type)
+	case AttributeExtra:
+		return strings.EqualFold(strings.Join(strings.Fields(oldField.Extra), " "), strings.Join(strings.Fields(newField.Extra), " ")) &&
+			strings.EqualFold(oldField.OnUpdate, newField.OnUpdate)
+	case AttributeComment:
+		return true
+	}
+	return strictComparison{}.Equal(attr, oldField, newField)
+}
+
+// normalizeType returns the column type in upper case without the display width of the integer types and the
+// redundant spaces.
+func normalizeType(typ string) string {
+	typ = strings.ToUpper(strings.Join(strings.Fields(typ), " "))
+	if isIntegerType(typ) {
+		if i := strings.IndexByte(typ, '('); i >= 0 {
+			if j := strings.IndexByte(typ[i:], ')'); j >= 0 {
+				typ = strings.TrimSpace(typ[:i]) + typ[i+j+1:]
+			}
+		}
+	}
+	return typ
+}
+
+func isIntegerType(typ string) bool {
+	switch typeBase(typ) {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT":
+		return true
+	}
+	return false
+}
+
+// CustomComparison is the strategy that has the organization-specific equivalence rules in addition to its base
+// strategy. The attribute is equivalent if the base strategy or any registered comparator of it reports so.
+type CustomComparison struct {
+	base        ComparisonStrategy
+	comparators map[Attribute][]Comparator
+}
+
+// NewCustomComparison returns a new CustomComparison. If base is nil, StrictComparison is used.
+func NewCustomComparison(base ComparisonStrategy) *CustomComparison {
+	if base == nil {
+		base = StrictComparison()
+	}
+	return &CustomComparison{
+		base:        base,
+		comparators: map[Attribute][]Comparator{},
+	}
+}
+
+// Register registers the comparator of the attribute.
+func (c *CustomComparison) Register(attr Attribute, comparator Comparator) {
+	c.comparators[attr] = append(c.comparators[attr], comparator)
+}
+
+func (c *CustomComparison) Equal(attr Attribute, oldField, newField *dialect.Field) bool {
+	if c.base.Equal(attr, oldField, newField) {
+		return true
+	}
+	for _, comparator := range c.comparators[attr] {
+		if comparator(oldField, newField) {
+			return true
+		}
+	}
+	return false
+}
+
+// EquivalentTypes returns the comparator of AttributeType that treats the types as equivalent to each other.
+// The types are compared case-insensitively. (e.g. EquivalentTypes("DATETIME", "TIMESTAMP"))
+func EquivalentTypes(types ...string) Comparator {
+	set := make(map[string]struct{}, len(types))
+	for _, t := range types {
+		set[normalizeType(t)] = struct{}{}
+	}
+	return func(oldField, newField *dialect.Field) bool {
+		_, ok1 := set[normalizeType(oldField.Type)]
+		_, ok2 := set[normalizeType(newField.Type)]
+		return ok1 && ok2
+	}
+}
+
+// WithComparison specifies the strategy to decide whether the columns are modified. The default is
+// StrictComparison.
+func WithComparison(s ComparisonStrategy) Option {
+	return func(o *option) {
+		o.comparison = s
+	}
+}
+
+// isDifferent reports whether the columns are different by the strategy.
+// The attributes of `nodiff` struct field tag of newF are not compared.
+func isDifferent(s ComparisonStrategy, oldF, newF *field) bool {
+	if oldF == nil || newF == nil {
+		return oldF != newF
+	}
+	if oldF.Column != newF.Column {
+		return true
+	}
+	oldField, newField := oldF.ToField(), newF.ToField()
+	if oldF.inheritedCollation {
+		// The declared character set and collation are compared with the defaults of the table in the database.
+		oldField.Charset, oldField.Collation = oldF.Charset, oldF.Collation
+	}
+	skip := make(map[Attribute]bool, len(newF.NoDiff))
+	for _, attr := range newF.NoDiff {
+		skip[attr] = true
+	}
+	for _, attr := range attributes {
+		if !skip[attr] && !s.Equal(attr, &oldField, &newField) {
+			return true
+		}
+	}
+	return false
+}
+
+// withNoDiff returns the copy of newF that has the attributes of `nodiff` struct field tag of oldF, so that the
+// modification of the column keeps them as they are in the database.
+func withNoDiff(oldF, newF *field) *field {
+	if oldF == nil || len(newF.NoDiff) == 0 {
+		return newF
+	}
+	f := *newF
+	for _, attr := range newF.NoDiff {
+		switch attr {
+		case AttributeType:
+			f.Type = oldF.Type
+		case AttributeNullable:
+			f.Nullable = oldF.Nullable
+		case AttributeDefault:
+			f.Default = oldF.Default
+		case AttributeExtra:
+			f.Extra, f.OnUpdate = oldF.Extra, oldF.OnUpdate
+		case AttributeComment:
+			f.Comment, f.Classes = oldF.Comment, oldF.Classes
+		case AttributeAutoIncrement:
+			f.AutoIncrement = oldF.AutoIncrement
+		case AttributeCompression:
+			f.Compressed = oldF.Compressed
+		case AttributeCollation:
+			f.Charset, f.Collation = oldF.Charset, oldF.Collation
+		}
+	}
+	return &f
+}
@@ -0,0 +1,31 @@
+package migu
+
+import (
+	"github.com/naoina/migu/dialect"
+)
+
+// validateCompression returns an error if the table has the compression annotation but the dialect does not support
+// it.
+func validateCompression(d dialect.Dialect, name string, tbl *table) error {
+	if tbl.Compression == "" {
+		return nil
+	}
+	if _, ok := d.(dialect.TableCompressor); !ok {
+		return newError(ErrUnsupportedFeature, "migu: %s: compression annotation is not supported by the dialect", name)
+	}
+	return nil
+}
+
+// compressionChange returns the change of the compression algorithm of the table, or nil if it is not changed.
+func compressionChange(d dialect.Dialect, name string, oldTbl, newTbl *table) *Change {
+	c, ok := d.(dialect.TableCompressor)
+	if !ok || newTbl.Compression == "" || oldTbl.Compression == "" || oldTbl.Compression == newTbl.Compression {
+		return nil
+	}
+	return &Change{
+		Kind:        ModifyCompression,
+		Table:       name,
+		Compression: newTbl.Compression,
+		SQLs:        c.ModifyTableCompressionSQL(name, newTbl.Compression),
+	}
+}
@@ -0,0 +1,274 @@
+// Package testcontainer runs a disposable MySQL or PostgreSQL server in a Docker container for the integration tests,
+// and applies Go's structs to it by migu.Sync.
+//
+//	func TestUser(t *testing.T) {
+//		db := testcontainer.MySQL(t, "schema.go", nil)
+//		if _, err := db.Exec("INSERT INTO user (name) VALUES (?)", "alice"); err != nil {
+//			t.Fatal(err)
+//		}
+//	}
+//
+// The containers are run by the docker command, so that the Docker daemon must be reachable from the test.
+package testcontainer
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	"github.com/naoina/migu"
+	"github.com/naoina/migu/dialect"
+)
+
+const (
+	// DefaultMySQLImage is the image of the MySQL container unless WithImage is specified.
+	DefaultMySQLImage = "mysql:8.0"
+
+	// DefaultPostgresImage is the image of the PostgreSQL container unless WithImage is specified.
+	DefaultPostgresImage = "postgres:13"
+
+	// DefaultStartupTimeout is the time to wait for the server to accept the connections unless WithStartupTimeout is
+	// specified. The first run may take longer since the image is pulled.
+	DefaultStartupTimeout = 2 * time.Minute
+
+	// DatabaseName is the name of the database that is created in the container.
+	DatabaseName = "migu_test"
+
+	password = "migu"
+
+	// removeTimeout is the time to wait for the container to be removed by Close.
+	removeTimeout = 30 * time.Second
+)
+
+// Container is the database server that is running in a Docker container.
+type Container struct {
+	// ID is the ID of the Docker container.
+	ID string
+
+	// DSN is the data source name to connect to the database of the container from the host.
+	DSN string
+
+	// DB is the connection to the database that Go's structs are applied to.
+	DB *sql.DB
+
+	// Dialect is the dialect of the database.
+	Dialect dialect.Dialect
+}
+
+// Option represents an option for the container.
+type Option func(*config)
+
+type config struct {
+	image          string
+	startupTimeout time.Duration
+	syncOptions    []migu.Option
+}
+
+// WithImage runs the image such as mysql:5.7 or mariadb:10.5 instead of the default image.
+func WithImage(image string) Option {
+	return func(c *config) {
+		c.image = image
+	}
+}
+
+// WithStartupTimeout changes the time to wait for the server to accept the connections.
+func WithStartupTimeout(d time.Duration) Option {
+	return func(c *config) {
+		c.startupTimeout = d
+	}
+}
+
+// WithSyncOptions specifies the options of migu.Sync that applies Go's structs to the database.
+func WithSyncOptions(opts ...migu.Option) Option {
+	return func(c *config) {
+		c.syncOptions = append(c.syncOptions, opts...)
+	}
+}
+
+// server is the kind of the database server in the container.
+type server struct {
+	image   string
+	port    string
+	env     []string
+	driver  string
+	dsn     func(addr string) string
+	dialect func(db *sql.DB) dialect.Dialect
+}
+
+var (
+	mysqlServer = server{
+		image:  DefaultMySQLImage,
+		port:   "3306",
+		env:    []string{"MYSQL_ROOT_PASSWORD=" + password, "MYSQL_DATABASE=" + DatabaseName},
+		driver: "mysql",
+		dsn: func(addr string) string {
+			return fmt.Sprintf("root:%s@tcp(%s)/%s", password, addr, DatabaseName)
+		},
+		dialect: func(db *sql.DB) dialect.Dialect {
+			return dialect.NewMySQL(db)
+		},
+	}
+	postgresServer = server{
+		image:  DefaultPostgresImage,
+		port:   "5432",
+		env:    []string{"POSTGRES_PASSWORD=" + password, "POSTGRES_DB=" + DatabaseName},
+		driver: "postgres",
+		dsn: func(addr string) string {
+			return fmt.Sprintf("postgres://postgres:%s@%s/%s?sslmode=disable", password, addr, DatabaseName)
+		},
+		dialect: func(db *sql.DB) dialect.Dialect {
+			return dialect.NewPostgres(db)
+		},
+	}
+)
+
+// StartMySQL runs a MySQL container, and applies Go's structs of filename or src to the database by migu.Sync.
+// The arguments filename and src are the same as migu.Sync. The container is removed by Container.Close.
+func StartMySQL(ctx context.Context, filename string, src interface{}, opts ...Option) (*Container, error) {
+	return start(ctx, mysqlServer, filename, src, opts)
+}
+
+// StartPostgres is the same as StartMySQL except that it runs a PostgreSQL container.
+func StartPostgres(ctx context.Context, filename string, src interface{}, opts ...Option) (*Container, error) {
+	return start(ctx, postgresServer, filename, src, opts)
+}
+
+// MySQL runs a MySQL container for the test, and returns the connection to the database that Go's structs are
+// applied to. The container is removed when the test and its subtests complete. The test is skipped if the docker
+// command is not found.
+func MySQL(t testing.TB, filename string, src interface{}, opts ...Option) *sql.DB {
+	t.Helper()
+	return startForTest(t, mysqlServer, filename, src, opts)
+}
+
+// Postgres is the same as MySQL except that it runs a PostgreSQL container.
+func Postgres(t testing.TB, filename string, src interface{}, opts ...Option) *sql.DB {
+	t.Helper()
+	return startForTest(t, postgresServer, filename, src, opts)
+}
+
+func startForTest(t testing.TB, s server, filename string, src interface{}, opts []Option) *sql.DB {
+	t.Helper()
+	if _, err := exec.LookPath("docker"); err != nil {
+		t.Skipf("testcontainer: %v", err)
+	}
+	c, err := start(context.Background(), s, filename, src, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := c.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	return c.DB
+}
+
+func start(ctx context.Context, s server, filename string, src interface{}, opts []Option) (*Container, error) {
+	cfg := &config{
+		image:          s.image,
+		startupTimeout: DefaultStartupTimeout,
+	}
+	for _, o := range opts {
+		o(cfg)
+	}
+	ctx, cancel := context.WithTimeout(ctx, cfg.startupTimeout)
+	defer cancel()
+	args := []string{"run", "--detach", "--publish", "127.0.0.1::" + s.port}
+	for _, env := range s.env {
+		args = append(args, "--env", env)
+	}
+	id, err := docker(ctx, append(args, cfg.image)...)
+	if err != nil {
+		return nil, err
+	}
+	c := &Container{ID: id}
+	if err := c.setup(ctx, s, cfg, filename, src); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// setup connects to the server of the container when it is ready, and applies Go's structs to the database.
+func (c *Container) setup(ctx context.Context, s server, cfg *config, filename string, src interface{}) error {
+	out, err := docker(ctx, "port", c.ID, s.port+"/tcp")
+	if err != nil {
+		return err
+	}
+	addr, err := publishedAddr(out)
+	if err != nil {
+		return fmt.Errorf("testcontainer: %s: %w", c.ID, err)
+	}
+	c.DSN = s.dsn(addr)
+	if c.DB, err = sql.Open(s.driver, c.DSN); err != nil {
+		return err
+	}
+	if err := waitForServer(ctx, c.DB); err != nil {
+		return fmt.Errorf("testcontainer: %s is not ready in %v: %w", cfg.image, cfg.startupTimeout, err)
+	}
+	c.Dialect = s.dialect(c.DB)
+	return migu.SyncContext(ctx, c.Dialect, filename, src, cfg.syncOptions...)
+}
+
+// Close closes the connection to the database, and removes the container with its volumes.
+func (c *Container) Close() error {
+	if c.DB != nil {
+		c.DB.Close()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), removeTimeout)
+	defer cancel()
+	_, err := docker(ctx, "rm", "--force", "--volumes", c.ID)
+	return err
+}
+
+// waitForServer pings the server until it accepts the connections or the context is done. The servers of the
+// official images accept the TCP connections only after the initialization of the database is finished.
+func waitForServer(ctx context.Context, db *sql.DB) error {
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		err := db.PingContext(ctx)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-ticker.C:
+		}
+	}
+}
+
+// docker runs the docker command with the arguments, and returns the standard output without the surrounding spaces.
+func docker(ctx context.Context, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("testcontainer: docker %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// publishedAddr returns the address on the host from the output of `docker port` such as 127.0.0.1:49153.
+// The output may have the addresses of IPv4 and IPv6 on multiple lines, and the first one is used.
+func publishedAddr(out string) (string, error) {
+	line := strings.TrimSpace(strings.SplitN(out, "\n", 2)[0])
+	host, port, err := net.SplitHostPort(line)
+	if err != nil {
+		return "", fmt.Errorf("unexpected output of docker port: %q", out)
+	}
+	if host == "0.0.0.0" || host == "::" || host == "" {
+		host = "127.0.0.1"
+	}
+	return net.JoinHostPort(host, port), nil
+}
@@ -0,0 +1,104 @@
+package testcontainer_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/naoina/migu/contrib/testcontainer"
+)
+
+const schema = `package model
+
+//+migu
+type User struct {
+	ID   int64 ` + "`migu:\"pk,autoincrement\"`" + `
+	Name string
+}
+`
+
+// fakeDocker replaces the docker command by the script that records the arguments and prints the output of
+// `docker port`, and returns the function that returns the recorded commands.
+func fakeDocker(t *testing.T, portOutput string) func() []string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake docker command requires sh")
+	}
+	dir, err := ioutil.TempDir("", "migu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := filepath.Join(dir, "log")
+	script := strings.Join([]string{
+		"#!/bin/sh",
+		`echo "$1" >> ` + log,
+		`case "$1" in`,
+		`run) echo 0123456789ab ;;`,
+		`port) echo "` + portOutput + `" ;;`,
+		`esac`,
+		"",
+	}, "\n")
+	if err := ioutil.WriteFile(filepath.Join(dir, "docker"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := os.Getenv("PATH")
+	os.Setenv("PATH", dir+string(filepath.ListSeparator)+path)
+	t.Cleanup(func() {
+		os.Setenv("PATH", path)
+		os.RemoveAll(dir)
+	})
+	return func() []string {
+		b, err := ioutil.ReadFile(log)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.Fields(string(b))
+	}
+}
+
+func TestStartMySQLRemovesContainerOnError(t *testing.T) {
+	commands := fakeDocker(t, "unexpected")
+	c, err := testcontainer.StartMySQL(context.Background(), "", schema)
+	if err == nil || !strings.Contains(err.Error(), "unexpected output of docker port") {
+		t.Fatalf("StartMySQL() => %v, %v; want the error of docker port", c, err)
+	}
+	if actual, expect := strings.Join(commands(), " "), "run port rm"; actual != expect {
+		t.Errorf("docker commands => %q; want %q", actual, expect)
+	}
+}
+
+func TestStartPostgresTimeout(t *testing.T) {
+	commands := fakeDocker(t, "0.0.0.0:1\n:::1")
+	c, err := testcontainer.StartPostgres(context.Background(), "", schema, testcontainer.WithStartupTimeout(time.Second))
+	if err == nil || !strings.Contains(err.Error(), "is not ready in 1s") {
+		t.Fatalf("StartPostgres() => %v, %v; want the error of the timeout", c, err)
+	}
+	if actual, expect := strings.Join(commands(), " "), "run port rm"; actual != expect {
+		t.Errorf("docker commands => %q; want %q", actual, expect)
+	}
+}
+
+func TestMySQL(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping the container test in short mode")
+	}
+	db := testcontainer.MySQL(t, "", schema)
+	if _, err := db.Exec("INSERT INTO user (name) VALUES (?)", "alice"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPostgres(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping the container test in short mode")
+	}
+	db := testcontainer.Postgres(t, "", schema)
+	if _, err := db.Exec(`INSERT INTO "user" (name) VALUES ($1)`, "alice"); err != nil {
+		t.Fatal(err)
+	}
+}
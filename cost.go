@@ -0,0 +1,177 @@
+package migu
+
+import (
+	"fmt"
+
+	"github.com/naoina/migu/dialect"
+)
+
+// Cost represents the cost and the risk of applying the change, which decides the order of the changes by
+// WithCostOrder.
+type Cost string
+
+const (
+	// CostMetadata is the cost of the changes that only change the metadata, such as the creations of the tables, the
+	// renames and the drops of the indexes and the constraints.
+	CostMetadata Cost = "metadata"
+
+	// CostBuild is the cost of the changes that scan the existing rows to build the non-unique indexes.
+	CostBuild Cost = "build"
+
+	// CostRebuild is the cost of the changes that may rebuild the whole table, such as the additions and the
+	// modifications of the columns.
+	CostRebuild Cost = "rebuild"
+
+	// CostRisky is the cost of the changes that may fail on the existing rows or lose the data, such as the unique
+	// indexes, the foreign keys, the CHECK constraints, the narrowings of the column types and the drops of the tables
+	// and the columns.
+	CostRisky Cost = "risky"
+)
+
+// costs is the list of the costs in ascending order.
+var costs = []Cost{CostMetadata, CostBuild, CostRebuild, CostRisky}
+
+// ParseCost returns the cost of the name, or an error if it is unknown.
+func ParseCost(s string) (Cost, error) {
+	for _, c := range costs {
+		if Cost(s) == c {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("unknown cost: %s", s)
+}
+
+func (c Cost) rank() int {
+	for i, cost := range costs {
+		if c == cost {
+			return i
+		}
+	}
+	return len(costs)
+}
+
+// Cost returns the cost of applying the change.
+func (c *Change) Cost() Cost {
+	if c.IsDestructive() {
+		return CostRisky
+	}
+	switch c.Kind {
+	case CreateTable, RenameTable, RenameColumn, DropIndex, DropForeignKey, DropCheck, ModifyUser, ModifyDatabase, FreezeSchema, UnfreezeSchema:
+		return CostMetadata
+	case CreateIndex:
+		if c.Unique {
+			return CostRisky
+		}
+		return CostBuild
+	case AddForeignKey, AddCheck, ModifyPrimaryKey:
+		return CostRisky
+	}
+	return CostRebuild
+}
+
+// WithCostOrder orders the changes so that the cheap changes are applied first and the expensive and risky changes
+// are applied last, in order to lose the least time of the maintenance window when a change fails. The changes that
+// depend on each other, such as the changes of the same column, keep their order. All the changes of the same table
+// keep their order unless the dialect implements dialect.StatelessGenerator.
+// The costs of the kinds of the changes in overrides take precedence over Change.Cost.
+func WithCostOrder(overrides map[ChangeKind]Cost) Option {
+	return func(o *option) {
+		o.costOrder = true
+		o.costOverrides = overrides
+	}
+}
+
+// orderByCost returns the changes in ascending order of the costs without reordering the dependent changes. The
+// changes of the same table keep their order unless the dialect implements dialect.StatelessGenerator.
+func orderByCost(d dialect.Dialect, changes []*Change, overrides map[ChangeKind]Cost) []*Change {
+	g, ok := d.(dialect.StatelessGenerator)
+	stateless := ok && g.IsStateless()
+	ranks := make([]int, len(changes))
+	preds := make([][]int, len(changes))
+	for i, c := range changes {
+		cost, ok := overrides[c.Kind]
+		if !ok {
+			cost = c.Cost()
+		}
+		ranks[i] = cost.rank()
+		for j := 0; j < i; j++ {
+			if dependsOn(c, changes[j], stateless) {
+				preds[i] = append(preds[i], j)
+			}
+		}
+	}
+	ordered := make([]*Change, 0, len(changes))
+	done := make([]bool, len(changes))
+	for len(ordered) < len(changes) {
+		next := -1
+	candidates:
+		for i := range changes {
+			if done[i] || (next >= 0 && ranks[i] >= ranks[next]) {
+				continue
+			}
+			for _, j := range preds[i] {
+				if !done[j] {
+					continue candidates
+				}
+			}
+			next = i
+		}
+		done[next] = true
+		ordered = append(ordered, changes[next])
+	}
+	return ordered
+}
+
+// dependsOn reports whether the change c must be applied after the preceding change p. The changes of the same
+// table depend on each other unless their SQLs are stateless.
+func dependsOn(c, p *Change, stateless bool) bool {
+	if isBarrierChange(c) || isBarrierChange(p) {
+		return true
+	}
+	if c.Table != p.Table {
+		return false
+	}
+	if !stateless || isTableChange(c) || isTableChange(p) {
+		return true
+	}
+	if c.Index != "" && c.Index == p.Index {
+		return true
+	}
+	for _, col := range changeColumns(c) {
+		for _, pcol := range changeColumns(p) {
+			if col == pcol {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isBarrierChange reports whether the change may depend on the other tables or its dependencies are unknown, so that
+// it is never reordered.
+func isBarrierChange(c *Change) bool {
+	switch c.Kind {
+	case RenameTable, AddForeignKey, DropForeignKey, ApplyMigration, RollbackMigration, FreezeSchema, UnfreezeSchema, ModifyUser, ModifyDatabase, Statement:
+		return true
+	}
+	return false
+}
+
+// isTableChange reports whether the change affects the whole table or the columns of the change are unknown.
+func isTableChange(c *Change) bool {
+	switch c.Kind {
+	case AddColumn, DropColumn, ModifyColumn, CreateIndex, DropIndex:
+		return false
+	}
+	return true
+}
+
+func changeColumns(c *Change) []string {
+	if c.Kind == CreateIndex {
+		return c.IndexColumns
+	}
+	if c.Column != "" {
+		return []string{c.Column}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package migu
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/naoina/migu/dialect"
+)
+
+// DatabaseChanges returns the change to alter the settings of the database such as the default character set to
+// opts, or nothing if the database already has them. The settings that are empty in opts and the ones that the
+// dialect does not have are not changed, and all settings are ignored unless the dialect implements
+// dialect.DatabaseAlterer in the same way as creating the database.
+func DatabaseChanges(d dialect.Dialect, opts dialect.DatabaseOptions) ([]*Change, error) {
+	return databaseChanges(d, opts, func(current, drifts dialect.DatabaseOptions) dialect.DatabaseOptions {
+		return drifts
+	})
+}
+
+// DatabaseRevertChanges returns the change to restore the current settings of the database that are altered by the
+// changes of DatabaseChanges, or nothing if they are not altered. The options that the database does not have yet
+// are not restored since they cannot be unset.
+func DatabaseRevertChanges(d dialect.Dialect, opts dialect.DatabaseOptions) ([]*Change, error) {
+	return databaseChanges(d, opts, func(current, drifts dialect.DatabaseOptions) dialect.DatabaseOptions {
+		var revert dialect.DatabaseOptions
+		if drifts.Charset != "" {
+			revert.Charset = current.Charset
+		}
+		if drifts.Collation != "" {
+			revert.Collation = current.Collation
+		}
+		if drifts.Encryption != nil {
+			revert.Encryption = current.Encryption
+		}
+		for name := range drifts.Options {
+			if v, ok := current.Options[name]; ok {
+				if revert.Options == nil {
+					revert.Options = map[string]string{}
+				}
+				revert.Options[name] = v
+			}
+		}
+		return revert
+	})
+}
+
+// databaseChanges returns the change to alter the database to the settings that are returned by fn from the current
+// settings and the drifted ones of opts, or nothing if they do not drift.
+func databaseChanges(d dialect.Dialect, opts dialect.DatabaseOptions, fn func(current, drifts dialect.DatabaseOptions) dialect.DatabaseOptions) ([]*Change, error) {
+	if isEmptyDatabaseOptions(opts) {
+		return nil, nil
+	}
+	a, ok := d.(dialect.DatabaseAlterer)
+	if !ok {
+		return nil, nil
+	}
+	if err := validateDatabaseOptions(opts); err != nil {
+		return nil, err
+	}
+	current, err := a.DatabaseOptions()
+	if err != nil {
+		return nil, err
+	}
+	alter := fn(current, databaseDrifts(current, opts))
+	if isEmptyDatabaseOptions(alter) {
+		return nil, nil
+	}
+	changes := finalizeChanges(d, []*Change{{
+		Kind: ModifyDatabase,
+		SQLs: a.AlterDatabaseSQL(alter),
+	}})
+	if err := dialectError(d); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func isEmptyDatabaseOptions(opts dialect.DatabaseOptions) bool {
+	return opts.Charset == "" && opts.Collation == "" && opts.Encryption == nil && len(opts.Options) == 0
+}
+
+// databaseDrifts returns the settings of want that differ from current. The settings that the dialect does not have
+// are empty in current, and they are never regarded as drifted.
+func databaseDrifts(current, want dialect.DatabaseOptions) dialect.DatabaseOptions {
+	var drifts dialect.DatabaseOptions
+	if current.Charset != "" && want.Charset != "" && normalizeCharset(current.Charset) != normalizeCharset(want.Charset) {
+		drifts.Charset = want.Charset
+	}
+	if current.Collation != "" && want.Collation != "" && normalizeCharset(current.Collation) != normalizeCharset(want.Collation) {
+		drifts.Collation = want.Collation
+	}
+	if drifts.Charset != "" && drifts.Collation == "" && want.Collation != "" {
+		// The collation must be specified with the character set, or it is reset to the default of the character set.
+		drifts.Collation = want.Collation
+	}
+	if current.Encryption != nil && want.Encryption != nil && *current.Encryption != *want.Encryption {
+		drifts.Encryption = want.Encryption
+	}
+	if current.Options != nil {
+		for name, value := range want.Options {
+			if v, ok := current.Options[name]; !ok || !strings.EqualFold(v, value) {
+				if drifts.Options == nil {
+					drifts.Options = map[string]string{}
+				}
+				drifts.Options[name] = value
+			}
+		}
+	}
+	return drifts
+}
+
+func validateDatabaseOptions(opts dialect.DatabaseOptions) error {
+	for _, s := range []string{opts.Charset, opts.Collation} {
+		if !isCharsetName(s) {
+			return newError(ErrInvalidIdentifier, "migu: invalid character set or collation of the database: %q", s)
+		}
+	}
+	names := make([]string, 0, len(opts.Options))
+	for name := range opts.Options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, c := range name {
+			if !(c == '_' || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9')) {
+				return newError(ErrInvalidIdentifier, "migu: invalid option name of the database: %q", name)
+			}
+		}
+		if err := dialect.ValidateLiteral(opts.Options[name]); err != nil {
+			return fmt.Errorf("migu: database option %s: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,667 @@
+package migu
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/naoina/migu/dialect"
+)
+
+type sqlTokenKind int
+
+const (
+	sqlIdent sqlTokenKind = iota
+	sqlString
+	sqlNumber
+	sqlSymbol
+)
+
+type sqlToken struct {
+	kind   sqlTokenKind
+	value  string
+	quoted bool
+}
+
+// is reports whether the token is the unquoted keyword or the symbol s.
+func (t sqlToken) is(s string) bool {
+	switch t.kind {
+	case sqlIdent:
+		return !t.quoted && strings.EqualFold(t.value, s)
+	case sqlSymbol:
+		return t.value == s
+	}
+	return false
+}
+
+func (t sqlToken) String() string {
+	switch t.kind {
+	case sqlString:
+		return "'" + strings.Replace(t.value, "'", "''", -1) + "'"
+	case sqlIdent:
+		if t.quoted {
+			return "`" + strings.Replace(t.value, "`", "``", -1) + "`"
+		}
+	}
+	return t.value
+}
+
+// utf8BOM is the byte order mark that is prepended to the files by some editors on Windows.
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// readSource returns the content of src if src is not nil, otherwise the content of the file.
+// The leading byte order mark is removed.
+// The src parameter is treated in the same way as Diff.
+func readSource(filename string, src interface{}) ([]byte, error) {
+	var b []byte
+	switch s := src.(type) {
+	case nil:
+		var err error
+		if b, err = ioutil.ReadFile(filename); err != nil {
+			return nil, err
+		}
+	case string:
+		b = []byte(s)
+	case []byte:
+		b = s
+	case *bytes.Buffer:
+		b = s.Bytes()
+	case io.Reader:
+		var err error
+		if b, err = ioutil.ReadAll(s); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, newError(ErrInvalidSource, "migu: invalid source type: %T", src)
+	}
+	return bytes.TrimPrefix(b, utf8BOM), nil
+}
+
+// tokenizeSQL splits the SQL into tokens. Comments are discarded.
+func tokenizeSQL(s string) ([]sqlToken, error) {
+	var tokens []sqlToken
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case c == '#' || (c == '-' && strings.HasPrefix(s[i:], "-- ")) || strings.HasPrefix(s[i:], "--\n"):
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		case c == '/' && strings.HasPrefix(s[i:], "/*"):
+			end := strings.Index(s[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i += 2 + end + 2
+		case c == '\'' || c == '"' || c == '`':
+			value, n, err := unquoteSQL(s[i:])
+			if err != nil {
+				return nil, err
+			}
+			if c == '`' {
+				tokens = append(tokens, sqlToken{kind: sqlIdent, value: value, quoted: true})
+			} else {
+				tokens = append(tokens, sqlToken{kind: sqlString, value: value})
+			}
+			i += n
+		case c >= '0' && c <= '9':
+			start := i
+			for i < len(s) && (isSQLIdentByte(s[i]) || s[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, sqlToken{kind: sqlNumber, value: s[start:i]})
+		case isSQLIdentByte(c):
+			start := i
+			for i < len(s) && isSQLIdentByte(s[i]) {
+				i++
+			}
+			tokens = append(tokens, sqlToken{kind: sqlIdent, value: s[start:i]})
+		default:
+			tokens = append(tokens, sqlToken{kind: sqlSymbol, value: string(c)})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+func isSQLIdentByte(c byte) bool {
+	return c == '_' || c == '$' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') || c >= 0x80
+}
+
+// unquoteSQL returns the unquoted value of the quoted string at the beginning of s and the length of the quoted string.
+func unquoteSQL(s string) (string, int, error) {
+	quote := s[0]
+	var buf strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == quote:
+			if i+1 < len(s) && s[i+1] == quote {
+				buf.WriteByte(c)
+				i++
+				continue
+			}
+			return buf.String(), i + 1, nil
+		case c == '\\' && quote != '`' && i+1 < len(s):
+			i++
+			switch s[i] {
+			case '0':
+				buf.WriteByte(0)
+			case 'n':
+				buf.WriteByte('\n')
+			case 'r':
+				buf.WriteByte('\r')
+			case 't':
+				buf.WriteByte('\t')
+			case 'Z':
+				buf.WriteByte(0x1a)
+			default:
+				buf.WriteByte(s[i])
+			}
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated quoted string")
+}
+
+// splitSQLStatements splits the tokens into statements by semicolons.
+func splitSQLStatements(tokens []sqlToken) [][]sqlToken {
+	var stmts [][]sqlToken
+	start := 0
+	for i, t := range tokens {
+		if t.is(";") {
+			if i > start {
+				stmts = append(stmts, tokens[start:i])
+			}
+			start = i + 1
+		}
+	}
+	if start < len(tokens) {
+		stmts = append(stmts, tokens[start:])
+	}
+	return stmts
+}
+
+// parseCreateTables parses the CREATE TABLE statements of MySQL in the SQL and returns the column schemas of the tables.
+// The statements other than CREATE TABLE are ignored.
+func parseCreateTables(filename string, src interface{}) (map[string][]dialect.ColumnSchema, error) {
+	b, err := readSource(filename, src)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := tokenizeSQL(stripDelimiterBlocks(string(b)))
+	if err != nil {
+		return nil, newError(ErrInvalidSource, "migu: %s: %w", filename, err)
+	}
+	tableMap := map[string][]dialect.ColumnSchema{}
+	for _, stmt := range splitSQLStatements(tokens) {
+		if !isCreateTable(stmt) {
+			continue
+		}
+		name, columns, err := parseCreateTable(stmt)
+		if err != nil {
+			return nil, newError(ErrInvalidSource, "migu: %s: %w", filename, err)
+		}
+		tableMap[name] = columns
+	}
+	return tableMap, nil
+}
+
+// stripDelimiterBlocks removes the blocks that change the statement delimiter by DELIMITER command of mysql client.
+// mysqldump outputs such blocks for the triggers and the stored routines, which are not related to the tables.
+func stripDelimiterBlocks(s string) string {
+	lines := strings.SplitAfter(s, "\n")
+	var buf strings.Builder
+	var inBlock bool
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && strings.EqualFold(fields[0], "DELIMITER") {
+			inBlock = fields[1] != ";"
+			continue
+		}
+		if !inBlock {
+			buf.WriteString(line)
+		}
+	}
+	return buf.String()
+}
+
+func isCreateTable(stmt []sqlToken) bool {
+	if len(stmt) < 2 || !stmt[0].is("CREATE") {
+		return false
+	}
+	if stmt[1].is("TEMPORARY") {
+		stmt = stmt[1:]
+	}
+	return len(stmt) > 1 && stmt[1].is("TABLE")
+}
+
+func parseCreateTable(stmt []sqlToken) (string, []dialect.ColumnSchema, error) {
+	i := 2
+	if stmt[1].is("TEMPORARY") {
+		i++
+	}
+	if i+2 < len(stmt) && stmt[i].is("IF") && stmt[i+1].is("NOT") && stmt[i+2].is("EXISTS") {
+		i += 3
+	}
+	var name string
+	for ; i < len(stmt) && stmt[i].kind == sqlIdent; i++ {
+		name = stmt[i].value
+		if i+1 < len(stmt) && stmt[i+1].is(".") {
+			i++
+			continue
+		}
+		i++
+		break
+	}
+	if name == "" || i >= len(stmt) || !stmt[i].is("(") {
+		return "", nil, fmt.Errorf("unsupported CREATE TABLE statement: %s", joinSQLTokens(stmt))
+	}
+	end := matchParen(stmt, i)
+	if end < 0 {
+		return "", nil, fmt.Errorf("unbalanced parentheses in CREATE TABLE %s", name)
+	}
+	var columns []*ddlColumnSchema
+	var indexes []ddlIndex
+	for _, def := range splitSQLList(stmt[i+1 : end]) {
+		if len(def) == 0 {
+			continue
+		}
+		if index, ok := parseIndexDefinition(def); ok {
+			if index != nil {
+				indexes = append(indexes, *index)
+			}
+			continue
+		}
+		column, err := parseColumnDefinition(name, def)
+		if err != nil {
+			return "", nil, fmt.Errorf("%s: %v", name, err)
+		}
+		columns = append(columns, column)
+	}
+	columnMap := make(map[string]*ddlColumnSchema, len(columns))
+	for _, c := range columns {
+		columnMap[c.column] = c
+	}
+	for _, index := range indexes {
+		for _, column := range index.columns {
+			c := columnMap[column]
+			if c == nil {
+				return "", nil, fmt.Errorf("%s: unknown column %s in index", name, column)
+			}
+			if index.primary {
+				c.primaryKey = true
+				c.nullable = false
+				continue
+			}
+			// NOTE: ColumnSchema can have only one index for each column, so the first one is used.
+			if c.indexName == "" {
+				c.indexName, c.unique = index.name, index.unique
+			}
+		}
+	}
+	schemas := make([]dialect.ColumnSchema, len(columns))
+	for i, c := range columns {
+		schemas[i] = c
+	}
+	return name, schemas, nil
+}
+
+// matchParen returns the position of the closing parenthesis that matches with the opening parenthesis at start.
+func matchParen(tokens []sqlToken, start int) int {
+	depth := 0
+	for i := start; i < len(tokens); i++ {
+		switch {
+		case tokens[i].is("("):
+			depth++
+		case tokens[i].is(")"):
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitSQLList splits the tokens by the commas that are not in parentheses.
+func splitSQLList(tokens []sqlToken) [][]sqlToken {
+	var list [][]sqlToken
+	depth, start := 0, 0
+	for i, t := range tokens {
+		switch {
+		case t.is("("):
+			depth++
+		case t.is(")"):
+			depth--
+		case t.is(",") && depth == 0:
+			list = append(list, tokens[start:i])
+			start = i + 1
+		}
+	}
+	return append(list, tokens[start:])
+}
+
+func joinSQLTokens(tokens []sqlToken) string {
+	var buf strings.Builder
+	for i, t := range tokens {
+		if i > 0 && !t.is(")") && !t.is(",") && !t.is("(") && !tokens[i-1].is("(") {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(t.String())
+	}
+	return buf.String()
+}
+
+type ddlIndex struct {
+	name    string
+	columns []string
+	primary bool
+	unique  bool
+}
+
+// parseIndexDefinition parses the index definition in CREATE TABLE.
+// It returns false if the definition is not for an index.
+// It returns nil index if the definition is for an index or a constraint that is not supported by Migu.
+func parseIndexDefinition(def []sqlToken) (*ddlIndex, bool) {
+	i := 0
+	if def[0].is("CONSTRAINT") {
+		i++
+		if i < len(def) && def[i].kind == sqlIdent && !def[i].is("PRIMARY") && !def[i].is("UNIQUE") && !def[i].is("FOREIGN") && !def[i].is("CHECK") {
+			i++
+		}
+		if i >= len(def) {
+			return nil, true
+		}
+	}
+	var index ddlIndex
+	switch t := def[i]; {
+	case t.is("PRIMARY"):
+		index.primary, index.unique = true, true
+		i++
+	case t.is("UNIQUE"):
+		index.unique = true
+		i++
+	case t.is("KEY"), t.is("INDEX"):
+	case t.is("FULLTEXT"), t.is("SPATIAL"), t.is("FOREIGN"), t.is("CHECK"):
+		return nil, true
+	default:
+		if i > 0 {
+			return nil, true
+		}
+		return nil, false
+	}
+	if i < len(def) && (def[i].is("KEY") || def[i].is("INDEX")) {
+		i++
+	}
+	if i < len(def) && def[i].kind == sqlIdent && !def[i].is("USING") {
+		index.name = def[i].value
+		i++
+	}
+	for i < len(def) && !def[i].is("(") {
+		i++
+	}
+	end := matchParen(def, i)
+	if end < 0 {
+		return nil, true
+	}
+	for _, part := range splitSQLList(def[i+1 : end]) {
+		if len(part) == 0 || part[0].kind != sqlIdent {
+			// functional key parts are not supported.
+			return nil, true
+		}
+		index.columns = append(index.columns, part[0].value)
+	}
+	if index.name == "" && !index.primary {
+		index.name = index.columns[0]
+	}
+	return &index, true
+}
+
+func parseColumnDefinition(table string, def []sqlToken) (*ddlColumnSchema, error) {
+	if len(def) < 2 || def[0].kind != sqlIdent || def[1].kind != sqlIdent {
+		return nil, fmt.Errorf("invalid column definition: %s", joinSQLTokens(def))
+	}
+	c := &ddlColumnSchema{
+		table:    table,
+		column:   def[0].value,
+		dataType: strings.ToLower(def[1].value),
+		nullable: true,
+	}
+	typ := c.dataType
+	i := 2
+	if i < len(def) && def[i].is("(") {
+		end := matchParen(def, i)
+		if end < 0 {
+			return nil, fmt.Errorf("unbalanced parentheses in column %s", c.column)
+		}
+		args := make([]string, 0, end-i)
+		for _, t := range def[i+1 : end] {
+			args = append(args, t.String())
+		}
+		typ += "(" + strings.Join(args, "") + ")"
+		i = end + 1
+	}
+	for ; i < len(def) && (def[i].is("UNSIGNED") || def[i].is("SIGNED") || def[i].is("ZEROFILL")); i++ {
+		if !def[i].is("SIGNED") {
+			typ += " " + strings.ToLower(def[i].value)
+		}
+	}
+	switch c.dataType {
+	case "tinyint", "smallint", "mediumint", "int", "integer", "bigint":
+		if !strings.HasPrefix(typ, "tinyint(1)") {
+			typ = trimSQLParens(typ)
+		}
+		if c.dataType == "integer" {
+			c.dataType, typ = "int", "int"+strings.TrimPrefix(typ, "integer")
+		}
+	}
+	c.columnType = typ
+	for i < len(def) {
+		t := def[i]
+		i++
+		switch {
+		case t.is("NOT") && i < len(def) && def[i].is("NULL"):
+			c.nullable = false
+			i++
+		case t.is("NULL"):
+			c.nullable = true
+		case t.is("DEFAULT"):
+			var err error
+			if i, err = c.parseDefault(def, i); err != nil {
+				return nil, err
+			}
+		case t.is("AUTO_INCREMENT"):
+			c.autoIncrement = true
+		case t.is("COMMENT") && i < len(def) && def[i].kind == sqlString:
+			c.comment = def[i].value
+			i++
+		case t.is("ON") && i+1 < len(def) && def[i].is("UPDATE"):
+			value := strings.ToUpper(def[i+1].value)
+			i += 2
+			if i < len(def) && def[i].is("(") {
+				end := matchParen(def, i)
+				if end < 0 {
+					return nil, fmt.Errorf("unbalanced parentheses in column %s", c.column)
+				}
+				if end > i+1 {
+					value += strings.ToUpper(joinSQLTokens(def[i : end+1]))
+				}
+				i = end + 1
+			}
+			c.onUpdate = value
+		case t.is("PRIMARY"), t.is("KEY"):
+			c.primaryKey = true
+			c.nullable = false
+			if t.is("PRIMARY") && i < len(def) && def[i].is("KEY") {
+				i++
+			}
+		case t.is("UNIQUE"):
+			c.indexName, c.unique = c.column, true
+			if i < len(def) && def[i].is("KEY") {
+				i++
+			}
+		case t.is("CHARACTER") && i < len(def) && def[i].is("SET"), t.is("CHARSET"), t.is("COLLATE"):
+			if t.is("CHARACTER") {
+				i++
+			}
+			i++
+		case t.is("("):
+			// skip the expressions like GENERATED ALWAYS AS (...).
+			if i >= 2 && def[i-2].is("AS") {
+				c.generated = true
+			}
+			if end := matchParen(def, i-1); end > 0 {
+				i = end + 1
+			}
+		}
+	}
+	if c.primaryKey {
+		c.nullable = false
+	}
+	return c, nil
+}
+
+// parseDefault parses the value of DEFAULT clause at def[i], and returns the position of the next token.
+func (c *ddlColumnSchema) parseDefault(def []sqlToken, i int) (int, error) {
+	if i >= len(def) {
+		return i, fmt.Errorf("DEFAULT of column %s has no value", c.column)
+	}
+	t := def[i]
+	i++
+	switch {
+	case t.is("NULL"):
+		c.hasDefault = false
+		return i, nil
+	case t.kind == sqlString:
+		c.columnDefault = t.value
+	case t.is("-") && i < len(def) && def[i].kind == sqlNumber:
+		c.columnDefault = "-" + def[i].value
+		i++
+	case t.kind == sqlNumber:
+		c.columnDefault = t.value
+	case t.is("("):
+		end := matchParen(def, i-1)
+		if end < 0 {
+			return i, fmt.Errorf("unbalanced parentheses in DEFAULT of column %s", c.column)
+		}
+		c.columnDefault = joinSQLTokens(def[i:end])
+		i = end + 1
+	case t.kind == sqlIdent && strings.HasPrefix(t.value, "_") && i < len(def) && def[i].kind == sqlString:
+		// string with the character set introducer. e.g. _utf8mb4'value'
+		c.columnDefault = def[i].value
+		i++
+	case t.kind == sqlIdent:
+		c.columnDefault = strings.ToUpper(t.value)
+		if i < len(def) && def[i].is("(") {
+			end := matchParen(def, i)
+			if end < 0 {
+				return i, fmt.Errorf("unbalanced parentheses in DEFAULT of column %s", c.column)
+			}
+			if end > i+1 {
+				c.columnDefault += joinSQLTokens(def[i : end+1])
+			}
+			i = end + 1
+		}
+	default:
+		return i, fmt.Errorf("unsupported DEFAULT of column %s: %s", c.column, t)
+	}
+	c.hasDefault = true
+	return i, nil
+}
+
+func trimSQLParens(s string) string {
+	start := strings.IndexByte(s, '(')
+	end := strings.IndexByte(s, ')')
+	if start < 0 || end < start {
+		return s
+	}
+	return s[:start] + s[end+1:]
+}
+
+var (
+	_ dialect.ColumnSchema          = &ddlColumnSchema{}
+	_ dialect.GeneratedColumnSchema = &ddlColumnSchema{}
+	_ dialect.OnUpdateColumnSchema  = &ddlColumnSchema{}
+)
+
+// ddlColumnSchema is the column schema that is parsed from CREATE TABLE statement of MySQL.
+type ddlColumnSchema struct {
+	table         string
+	column        string
+	columnType    string
+	dataType      string
+	primaryKey    bool
+	autoIncrement bool
+	indexName     string
+	unique        bool
+	columnDefault string
+	hasDefault    bool
+	nullable      bool
+	extra         string
+	onUpdate      string
+	comment       string
+	generated     bool
+}
+
+func (c *ddlColumnSchema) TableName() string {
+	return c.table
+}
+
+func (c *ddlColumnSchema) ColumnName() string {
+	return c.column
+}
+
+func (c *ddlColumnSchema) ColumnType() string {
+	return c.columnType
+}
+
+func (c *ddlColumnSchema) DataType() string {
+	return c.dataType
+}
+
+func (c *ddlColumnSchema) IsPrimaryKey() bool {
+	return c.primaryKey
+}
+
+func (c *ddlColumnSchema) IsAutoIncrement() bool {
+	return c.autoIncrement
+}
+
+func (c *ddlColumnSchema) Index() (name string, unique bool, ok bool) {
+	return c.indexName, c.unique, c.indexName != ""
+}
+
+func (c *ddlColumnSchema) Default() (string, bool) {
+	if !c.hasDefault {
+		return "", false
+	}
+	if c.dataType == "datetime" && c.columnDefault == "0000-00-00 00:00:00" {
+		return "", false
+	}
+	return strings.TrimSuffix(c.columnDefault, "()"), true
+}
+
+func (c *ddlColumnSchema) IsNullable() bool {
+	return c.nullable
+}
+
+func (c *ddlColumnSchema) Extra() (string, bool) {
+	return c.extra, c.extra != ""
+}
+
+func (c *ddlColumnSchema) OnUpdate() (string, bool) {
+	return c.onUpdate, c.onUpdate != ""
+}
+
+func (c *ddlColumnSchema) Comment() (string, bool) {
+	return c.comment, c.comment != ""
+}
+
+func (c *ddlColumnSchema) IsGenerated() bool {
+	return c.generated
+}
@@ -0,0 +1,72 @@
+package migu
+
+import (
+	"strings"
+	"time"
+
+	"github.com/naoina/migu/dialect"
+)
+
+const (
+	// DeprecatedColumnPrefix is the prefix of the column name that is deprecated by WithTwoPhaseColumnDrop.
+	DeprecatedColumnPrefix = "deleted_"
+
+	deprecatedCommentPrefix = "migu:deleted_at="
+	deprecatedTimeLayout    = "20060102150405"
+)
+
+// dropColumnChanges returns the changes for the column that is removed from Go's struct.
+// If WithTwoPhaseColumnDrop is given, the column will be renamed with DeprecatedColumnPrefix in the first phase, and
+// will be dropped after the grace period passed in the second phase. The deprecated time is recorded in the column
+// comment.
+func dropColumnChanges(d dialect.Dialect, f *field, opt *option) ([]*Change, error) {
+	oldField := f.ToField()
+	drop := &Change{
+		Kind:     DropColumn,
+		Table:    f.Table,
+		Column:   f.Column,
+		OldField: &oldField,
+		SQLs:     d.DropColumnSQL(oldField),
+	}
+	if !opt.twoPhaseDrop {
+		return []*Change{drop}, nil
+	}
+	now := opt.now()
+	if deprecatedAt, ok := parseDeprecatedColumn(f); ok {
+		if now.Before(deprecatedAt.Add(opt.dropGracePeriod)) {
+			return nil, nil
+		}
+		return []*Change{drop}, nil
+	}
+	r, ok := d.(dialect.ColumnRenamer)
+	if !ok {
+		return nil, newError(ErrUnsupportedFeature, "migu: two-phase column drop is not supported by the dialect")
+	}
+	newField := oldField
+	newField.Name = DeprecatedColumnPrefix + f.Column
+	newField.Comment = deprecatedCommentPrefix + now.UTC().Format(deprecatedTimeLayout)
+	// The application that does not know the deprecated column anymore must be able to insert rows.
+	newField.Nullable = true
+	newField.AutoIncrement = false
+	return []*Change{{
+		Kind:     RenameColumn,
+		Table:    f.Table,
+		Column:   f.Column,
+		NewName:  newField.Name,
+		OldField: &oldField,
+		NewField: &newField,
+		SQLs:     r.RenameColumnSQL(oldField, newField),
+	}}, nil
+}
+
+// parseDeprecatedColumn returns the deprecated time if the column is deprecated by WithTwoPhaseColumnDrop.
+func parseDeprecatedColumn(f *field) (deprecatedAt time.Time, ok bool) {
+	if !strings.HasPrefix(f.Column, DeprecatedColumnPrefix) || !strings.HasPrefix(f.Comment, deprecatedCommentPrefix) {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(deprecatedTimeLayout, f.Comment[len(deprecatedCommentPrefix):])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
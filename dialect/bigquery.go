@@ -0,0 +1,384 @@
+package dialect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/naoina/go-stringutil"
+	bigquery "google.golang.org/api/bigquery/v2"
+)
+
+var (
+	bigqueryColumnTypes = []*ColumnType{
+		{
+			Types:           []string{"STRING", "GEOGRAPHY", "JSON"},
+			GoTypes:         []string{"string"},
+			GoNullableTypes: []string{"*string"},
+		},
+		{
+			Types:           []string{"BYTES"},
+			GoTypes:         []string{"[]byte"},
+			GoNullableTypes: []string{"[]byte"},
+		},
+		{
+			Types:           []string{"BOOL"},
+			GoTypes:         []string{"bool"},
+			GoNullableTypes: []string{"*bool"},
+		},
+		{
+			Types:           []string{"INT64"},
+			GoTypes:         []string{"int64", "int", "int8", "int16", "int32", "uint8", "uint16", "uint32"},
+			GoNullableTypes: []string{"*int64"},
+		},
+		{
+			Types:           []string{"FLOAT64"},
+			GoTypes:         []string{"float64", "float32"},
+			GoNullableTypes: []string{"*float64"},
+		},
+		{
+			Types:           []string{"NUMERIC", "BIGNUMERIC"},
+			GoTypes:         []string{"big.Rat"},
+			GoNullableTypes: []string{"*big.Rat"},
+		},
+		{
+			Types:           []string{"TIMESTAMP"},
+			GoTypes:         []string{"time.Time"},
+			GoNullableTypes: []string{"*time.Time"},
+		},
+		{
+			Types:           []string{"DATE"},
+			GoTypes:         []string{"civil.Date"},
+			GoNullableTypes: []string{"*civil.Date"},
+		},
+		{
+			Types:           []string{"TIME"},
+			GoTypes:         []string{"civil.Time"},
+			GoNullableTypes: []string{"*civil.Time"},
+		},
+		{
+			Types:           []string{"DATETIME"},
+			GoTypes:         []string{"civil.DateTime"},
+			GoNullableTypes: []string{"*civil.DateTime"},
+		},
+	}
+
+	// bigqueryLegacyTypes maps the names of the types in the legacy SQL that are returned by the BigQuery API to
+	// the ones in the standard SQL.
+	bigqueryLegacyTypes = map[string]string{
+		"INTEGER": "INT64",
+		"FLOAT":   "FLOAT64",
+		"BOOLEAN": "BOOL",
+		"RECORD":  "STRUCT",
+	}
+)
+
+// errBigQueryReadOnly is returned when the schema of BigQuery is about to be modified.
+var errBigQueryReadOnly = errors.New("BigQuery dialect is read-only and can only be used to dump the schema")
+
+// BigQuery is the read-only dialect for BigQuery.
+// It reads the schema of the tables in the dataset so that `migu dump` can generate Go's structs from them.
+// The SQL generators return only comments and Begin returns an error because the dialect does not modify the schema.
+type BigQuery struct {
+	s               *bigquery.Service
+	dataset         string
+	opt             *option
+	columnTypeMap   map[string]*ColumnType
+	nullableTypeMap map[string]struct{}
+}
+
+// NewBigQuery returns the BigQuery dialect for the dataset in the form of projects/PROJECT/datasets/DATASET.
+func NewBigQuery(dataset string, opts ...Option) Dialect {
+	d := &BigQuery{
+		dataset:         dataset,
+		opt:             newOption(),
+		columnTypeMap:   map[string]*ColumnType{},
+		nullableTypeMap: map[string]struct{}{},
+	}
+	for _, o := range opts {
+		o(d.opt)
+	}
+	for _, types := range [][]*ColumnType{bigqueryColumnTypes, d.opt.columnTypes} {
+		for _, t := range types {
+			for _, tt := range t.allGoTypes() {
+				d.columnTypeMap[tt] = t
+			}
+			for _, tt := range t.filteredNullableGoTypes() {
+				d.nullableTypeMap[tt] = struct{}{}
+			}
+		}
+	}
+	return d
+}
+
+func (d *BigQuery) ColumnSchema(tables ...string) ([]ColumnSchema, error) {
+	// The dataset is in the form of projects/PROJECT/datasets/DATASET.
+	parts := strings.Split(d.dataset, "/")
+	if len(parts) != 4 || parts[0] != "projects" || parts[2] != "datasets" {
+		return nil, fmt.Errorf("invalid dataset name: %s", d.dataset)
+	}
+	project, dataset := parts[1], parts[3]
+	ctx := context.Background()
+	s, err := d.service()
+	if err != nil {
+		return nil, err
+	}
+	if len(tables) == 0 {
+		if err := s.Tables.List(project, dataset).Pages(ctx, func(list *bigquery.TableList) error {
+			for _, t := range list.Tables {
+				if t.Type == "TABLE" {
+					tables = append(tables, t.TableReference.TableId)
+				}
+			}
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+	var schemas []ColumnSchema
+	for _, name := range tables {
+		t, err := s.Tables.Get(project, dataset, name).Context(ctx).Do()
+		if err != nil {
+			return nil, err
+		}
+		if t.Schema == nil {
+			continue
+		}
+		for _, f := range t.Schema.Fields {
+			schemas = append(schemas, &bigqueryColumnSchema{
+				tableName: name,
+				field:     f,
+			})
+		}
+	}
+	return schemas, nil
+}
+
+func (d *BigQuery) ColumnType(name string) string {
+	name = strings.TrimLeft(name, "*")
+	if t, ok := d.columnTypeMap[name]; ok {
+		n, _, _, _ := t.findType(name)
+		return n
+	}
+	if strings.HasPrefix(name, "[]") {
+		return fmt.Sprintf("ARRAY<%s>", d.ColumnType(name[2:]))
+	}
+	return strings.ToUpper(name)
+}
+
+// GoType returns the Go's type of the type of BigQuery.
+// ARRAY<T> is mapped to the slice of T and STRUCT<...> is mapped to the anonymous struct that has the fields of it.
+func (d *BigQuery) GoType(name string, nullable bool) string {
+	name = strings.TrimSpace(name)
+	upper := strings.ToUpper(name)
+	if prefix := "ARRAY<"; strings.HasPrefix(upper, prefix) && strings.HasSuffix(name, ">") {
+		return "[]" + d.GoType(name[len(prefix):len(name)-1], false)
+	}
+	if prefix := "STRUCT<"; strings.HasPrefix(upper, prefix) && strings.HasSuffix(name, ">") {
+		var fields []string
+		for _, f := range bigquerySplitFields(name[len(prefix) : len(name)-1]) {
+			var fieldName, typ string
+			if i := strings.IndexByte(f, ' '); i >= 0 {
+				fieldName, typ = f[:i], strings.TrimSpace(f[i+1:])
+			}
+			fieldNullable := true
+			if suffix := " NOT NULL"; strings.HasSuffix(strings.ToUpper(typ), suffix) {
+				typ, fieldNullable = strings.TrimSpace(typ[:len(typ)-len(suffix)]), false
+			}
+			goName := stringutil.ToUpperCamelCase(fieldName)
+			field := goName + " " + d.GoType(typ, fieldNullable)
+			if !strings.EqualFold(goName, fieldName) {
+				field += fmt.Sprintf(" `bigquery:%q`", fieldName)
+			}
+			fields = append(fields, field)
+		}
+		typ := "struct{}"
+		if len(fields) > 0 {
+			typ = "struct{ " + strings.Join(fields, "; ") + " }"
+		}
+		if nullable {
+			return "*" + typ
+		}
+		return typ
+	}
+	if i := strings.IndexByte(upper, '('); i >= 0 {
+		upper = strings.TrimSpace(upper[:i])
+	}
+	if t, ok := bigqueryLegacyTypes[upper]; ok {
+		upper = t
+	}
+	for _, types := range [][]*ColumnType{d.opt.columnTypes, bigqueryColumnTypes} {
+		for _, t := range types {
+			if typ, found := t.findGoType(upper, nullable, false); found {
+				return typ
+			}
+		}
+	}
+	return "interface{}"
+}
+
+func (d *BigQuery) IsNullable(name string) bool {
+	_, ok := d.nullableTypeMap[name]
+	return ok
+}
+
+func (d *BigQuery) ImportPackage(schema ColumnSchema) string {
+	t := d.GoType(schema.ColumnType(), schema.IsNullable())
+	switch {
+	case strings.Contains(t, "time.Time"):
+		return "time"
+	case strings.Contains(t, "civil."):
+		return "cloud.google.com/go/civil"
+	case strings.Contains(t, "big.Rat"):
+		return "math/big"
+	}
+	return ""
+}
+
+func (d *BigQuery) GoTypeImports() ([]Import, error) {
+	return goTypeImports(d.opt.columnTypes)
+}
+
+func (d *BigQuery) Quote(s string) string {
+	return quoteByBackslash(s, "`")
+}
+
+func (d *BigQuery) QuoteString(s string) string {
+	return quoteByBackslash(s, "'")
+}
+
+func (d *BigQuery) CreateTableSQL(table Table) []string {
+	return []string{fmt.Sprintf("-- table %s cannot be created because BigQuery dialect is read-only", d.Quote(table.Name))}
+}
+
+func (d *BigQuery) AddColumnSQL(field Field) []string {
+	return []string{fmt.Sprintf("-- column %s of table %s cannot be added because BigQuery dialect is read-only", d.Quote(field.Name), d.Quote(field.Table))}
+}
+
+func (d *BigQuery) DropColumnSQL(field Field) []string {
+	return []string{fmt.Sprintf("-- column %s of table %s cannot be dropped because BigQuery dialect is read-only", d.Quote(field.Name), d.Quote(field.Table))}
+}
+
+func (d *BigQuery) ModifyColumnSQL(oldField, newField Field) []string {
+	return []string{fmt.Sprintf("-- column %s of table %s cannot be modified because BigQuery dialect is read-only", d.Quote(oldField.Name), d.Quote(oldField.Table))}
+}
+
+func (d *BigQuery) CreateIndexSQL(index Index) []string {
+	return []string{fmt.Sprintf("-- index %s of table %s cannot be created because BigQuery dialect is read-only", d.Quote(index.Name), d.Quote(index.Table))}
+}
+
+func (d *BigQuery) DropIndexSQL(index Index) []string {
+	return []string{fmt.Sprintf("-- index %s of table %s cannot be dropped because BigQuery dialect is read-only", d.Quote(index.Name), d.Quote(index.Table))}
+}
+
+func (d *BigQuery) Begin() (Transactioner, error) {
+	return nil, errBigQueryReadOnly
+}
+
+func (d *BigQuery) service() (*bigquery.Service, error) {
+	if d.s != nil {
+		return d.s, nil
+	}
+	s, err := bigquery.NewService(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	d.s = s
+	return s, nil
+}
+
+// bigquerySplitFields splits the fields of STRUCT<...> by the commas that are not in the nested types.
+func bigquerySplitFields(s string) []string {
+	var fields []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '<', '(':
+			depth++
+		case '>', ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				fields = append(fields, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if f := strings.TrimSpace(s[start:]); f != "" {
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// bigqueryFieldType returns the type of the field in the standard SQL.
+// The type of the repeated field is ARRAY<T> and the one of the record is STRUCT<...>.
+func bigqueryFieldType(f *bigquery.TableFieldSchema) string {
+	typ := strings.ToUpper(f.Type)
+	if t, ok := bigqueryLegacyTypes[typ]; ok {
+		typ = t
+	}
+	if typ == "STRUCT" {
+		fields := make([]string, len(f.Fields))
+		for i, ff := range f.Fields {
+			fields[i] = ff.Name + " " + bigqueryFieldType(ff)
+			if ff.Mode == "REQUIRED" {
+				fields[i] += " NOT NULL"
+			}
+		}
+		typ = "STRUCT<" + strings.Join(fields, ", ") + ">"
+	}
+	if f.Mode == "REPEATED" {
+		typ = "ARRAY<" + typ + ">"
+	}
+	return typ
+}
+
+type bigqueryColumnSchema struct {
+	tableName string
+	field     *bigquery.TableFieldSchema
+}
+
+func (schema *bigqueryColumnSchema) TableName() string {
+	return schema.tableName
+}
+
+func (schema *bigqueryColumnSchema) ColumnName() string {
+	return schema.field.Name
+}
+
+func (schema *bigqueryColumnSchema) ColumnType() string {
+	return bigqueryFieldType(schema.field)
+}
+
+func (schema *bigqueryColumnSchema) DataType() string {
+	return schema.ColumnType()
+}
+
+func (schema *bigqueryColumnSchema) IsPrimaryKey() bool {
+	return false
+}
+
+func (schema *bigqueryColumnSchema) IsAutoIncrement() bool {
+	return false
+}
+
+func (schema *bigqueryColumnSchema) Index() (name string, unique bool, ok bool) {
+	return "", false, false
+}
+
+func (schema *bigqueryColumnSchema) Default() (string, bool) {
+	return "", false
+}
+
+func (schema *bigqueryColumnSchema) IsNullable() bool {
+	return schema.field.Mode == "" || schema.field.Mode == "NULLABLE"
+}
+
+func (schema *bigqueryColumnSchema) Extra() (string, bool) {
+	return "", false
+}
+
+func (schema *bigqueryColumnSchema) Comment() (string, bool) {
+	return schema.field.Description, schema.field.Description != ""
+}
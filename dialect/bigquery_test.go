@@ -0,0 +1,58 @@
+package dialect_test
+
+import (
+	"testing"
+
+	"github.com/naoina/migu/dialect"
+)
+
+func TestBigQueryColumnType(t *testing.T) {
+	d := dialect.NewBigQuery("projects/p/datasets/d")
+	for _, v := range []struct {
+		name   string
+		expect string
+	}{
+		{"string", "STRING"},
+		{"int", "INT64"},
+		{"*float64", "FLOAT64"},
+		{"big.Rat", "NUMERIC"},
+		{"civil.Date", "DATE"},
+		{"[]string", "ARRAY<STRING>"},
+	} {
+		if actual := d.ColumnType(v.name); actual != v.expect {
+			t.Errorf("ColumnType(%q) => %q; want %q", v.name, actual, v.expect)
+		}
+	}
+	for _, v := range []struct {
+		name     string
+		nullable bool
+		expect   string
+	}{
+		{"STRING", false, "string"},
+		{"STRING", true, "*string"},
+		{"INTEGER", false, "int64"},
+		{"NUMERIC(10, 2)", true, "*big.Rat"},
+		{"BIGNUMERIC", false, "big.Rat"},
+		{"DATETIME", false, "civil.DateTime"},
+		{"ARRAY<INT64>", false, "[]int64"},
+		{"STRUCT<city STRING NOT NULL, zip_code STRING>", true, "*struct{ City string; ZipCode *string `bigquery:\"zip_code\"` }"},
+		{"ARRAY<STRUCT<tags ARRAY<STRING>, at TIMESTAMP NOT NULL>>", false, "[]struct{ Tags []string; At time.Time }"},
+		{"INTERVAL", false, "interface{}"},
+	} {
+		if actual := d.GoType(v.name, v.nullable); actual != v.expect {
+			t.Errorf("GoType(%q, %v) => %q; want %q", v.name, v.nullable, actual, v.expect)
+		}
+	}
+}
+
+func TestBigQuerySQL(t *testing.T) {
+	d := dialect.NewBigQuery("projects/p/datasets/d")
+	if _, err := d.Begin(); err == nil {
+		t.Errorf("Begin() => nil; want error")
+	}
+	actual := d.AddColumnSQL(dialect.Field{Table: "user", Name: "name", Type: "STRING"})
+	expect := "-- column `name` of table `user` cannot be added because BigQuery dialect is read-only"
+	if len(actual) != 1 || actual[0] != expect {
+		t.Errorf("AddColumnSQL => %q; want [%q]", actual, expect)
+	}
+}
@@ -0,0 +1,632 @@
+package dialect
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+var (
+	_ PrimaryKeyModifier = &ClickHouse{}
+	_ TableRenamer       = &ClickHouse{}
+	_ ColumnRenamer      = &ClickHouse{}
+)
+
+const (
+	// clickhouseDefaultEngine is the engine of the table that has no ENGINE clause in the table option.
+	clickhouseDefaultEngine = "MergeTree()"
+
+	// clickhouseIndexType is the type of the data skipping indexes that are created by CreateIndexSQL.
+	clickhouseIndexType = "minmax"
+)
+
+var (
+	clickhouseColumnTypes = []*ColumnType{
+		{
+			Types:           []string{"String", "FixedString"},
+			GoTypes:         []string{"string", "[]byte"},
+			GoNullableTypes: []string{"*string", "sql.NullString"},
+		},
+		{
+			Types:           []string{"Int64"},
+			GoTypes:         []string{"int", "int64"},
+			GoNullableTypes: []string{"*int64", "sql.NullInt64"},
+		},
+		{
+			Types:           []string{"Int32"},
+			GoTypes:         []string{"int32"},
+			GoNullableTypes: []string{"*int32", "sql.NullInt32"},
+		},
+		{
+			Types:   []string{"Int16"},
+			GoTypes: []string{"int16"},
+		},
+		{
+			Types:   []string{"Int8"},
+			GoTypes: []string{"int8"},
+		},
+		{
+			Types:   []string{"UInt64"},
+			GoTypes: []string{"uint64", "uint"},
+		},
+		{
+			Types:   []string{"UInt32"},
+			GoTypes: []string{"uint32"},
+		},
+		{
+			Types:   []string{"UInt16"},
+			GoTypes: []string{"uint16"},
+		},
+		{
+			Types:   []string{"UInt8"},
+			GoTypes: []string{"uint8"},
+		},
+		{
+			Types:           []string{"Bool"},
+			GoTypes:         []string{"bool"},
+			GoNullableTypes: []string{"*bool", "sql.NullBool"},
+		},
+		{
+			Types:           []string{"Float64", "Decimal"},
+			GoTypes:         []string{"float64"},
+			GoNullableTypes: []string{"*float64", "sql.NullFloat64"},
+		},
+		{
+			Types:   []string{"Float32"},
+			GoTypes: []string{"float32"},
+		},
+		{
+			Types:           []string{"DateTime64(6)", "DateTime64", "DateTime", "Date32", "Date"},
+			GoTypes:         []string{"time.Time"},
+			GoNullableTypes: []string{"*time.Time", "sql.NullTime"},
+		},
+	}
+
+	// clickhouseTypeNames are the names of the types of ClickHouse that are case-sensitive.
+	clickhouseTypeNames = []string{
+		"String", "FixedString", "UUID", "Bool", "Decimal", "Float32", "Float64",
+		"Int8", "Int16", "Int32", "Int64", "Int128", "Int256", "UInt8", "UInt16", "UInt32", "UInt64", "UInt128", "UInt256",
+		"Date", "Date32", "DateTime", "DateTime64", "Enum8", "Enum16", "IPv4", "IPv6", "JSON",
+		"Array", "Map", "Tuple", "Nullable", "LowCardinality",
+	}
+
+	// clickhouseTypeAliases are the case-insensitive aliases of the types of ClickHouse for the compatibility with
+	// the other databases.
+	clickhouseTypeAliases = map[string]string{
+		"TEXT":      "String",
+		"VARCHAR":   "String",
+		"CHAR":      "String",
+		"BLOB":      "String",
+		"BOOLEAN":   "Bool",
+		"TINYINT":   "Int8",
+		"SMALLINT":  "Int16",
+		"INT":       "Int32",
+		"INTEGER":   "Int32",
+		"BIGINT":    "Int64",
+		"FLOAT":     "Float32",
+		"REAL":      "Float32",
+		"DOUBLE":    "Float64",
+		"TIMESTAMP": "DateTime",
+	}
+)
+
+// ClickHouse is the dialect of ClickHouse.
+// The tables are in the database that is specified by WithSchema, or the current database of the connection.
+//
+// The tables are created with the engine and its clauses that are specified by the table option such as
+// "ENGINE = ReplacingMergeTree(version) PARTITION BY toYYYYMM(created_at)". The engine is MergeTree() if the table
+// option has no ENGINE clause, and the sorting key of the MergeTree family is the primary keys if the table option
+// has no ORDER BY clause.
+// The nullable columns are Nullable(T), and LowCardinality(T) can be specified by the type tag. The indexes are the
+// data skipping indexes of minmax.
+// ClickHouse cannot change the sorting key, the unique indexes and the auto-increment columns, so that such changes
+// are returned as the comments instead of SQLs. The schema changes are not executed in a transaction.
+type ClickHouse struct {
+	db              *sql.DB
+	opt             *option
+	dbName          string
+	columnTypeMap   map[string]*ColumnType
+	nullableTypeMap map[string]struct{}
+}
+
+// NewClickHouse returns a new dialect of ClickHouse. db must be opened by the driver of ClickHouse such as
+// clickhouse-go.
+func NewClickHouse(db *sql.DB, opts ...Option) Dialect {
+	d := &ClickHouse{
+		db:              db,
+		opt:             newOption(),
+		columnTypeMap:   map[string]*ColumnType{},
+		nullableTypeMap: map[string]struct{}{},
+	}
+	for _, o := range opts {
+		o(d.opt)
+	}
+	for _, types := range [][]*ColumnType{clickhouseColumnTypes, d.opt.columnTypes} {
+		for _, t := range types {
+			for _, tt := range t.allGoTypes() {
+				d.columnTypeMap[tt] = t
+			}
+			for _, tt := range t.filteredNullableGoTypes() {
+				d.nullableTypeMap[tt] = struct{}{}
+			}
+		}
+	}
+	return d
+}
+
+func (d *ClickHouse) ColumnSchema(tables ...string) ([]ColumnSchema, error) {
+	dbname, err := d.currentDBName()
+	if err != nil {
+		return nil, err
+	}
+	indexMap, err := d.getIndexMap(dbname)
+	if err != nil {
+		return nil, err
+	}
+	parts := []string{
+		"SELECT",
+		"  c.table,",
+		"  c.name,",
+		"  c.type,",
+		"  c.default_kind,",
+		"  c.default_expression,",
+		"  c.comment,",
+		"  c.is_in_primary_key",
+		"FROM system.columns c",
+		"JOIN system.tables t ON t.database = c.database AND t.name = c.table",
+		"WHERE c.database = ? AND t.is_temporary = 0 AND t.engine NOT IN ('View', 'MaterializedView', 'LiveView')",
+	}
+	args := []interface{}{dbname}
+	if len(tables) > 0 {
+		parts = append(parts, fmt.Sprintf("AND c.table IN (%s)", strings.Repeat(",?", len(tables))[1:]))
+		for _, t := range tables {
+			args = append(args, t)
+		}
+	}
+	parts = append(parts, "ORDER BY c.table, c.position")
+	rows, err := d.db.Query(strings.Join(parts, "\n"), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var schemas []ColumnSchema
+	for rows.Next() {
+		schema := &clickhouseColumnSchema{}
+		if err := rows.Scan(
+			&schema.tableName,
+			&schema.columnName,
+			&schema.columnType,
+			&schema.defaultKind,
+			&schema.defaultExpression,
+			&schema.comment,
+			&schema.isInPrimaryKey,
+		); err != nil {
+			return nil, err
+		}
+		schema.indexName = indexMap[schema.tableName][schema.columnName]
+		schemas = append(schemas, schema)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return schemas, nil
+}
+
+func (d *ClickHouse) ColumnType(name string) string {
+	if t, ok := d.columnTypeMap[name]; ok {
+		name, _, _, _ = t.findType(name)
+	}
+	return clickhouseCanonicalType(name)
+}
+
+func (d *ClickHouse) GoType(name string, nullable bool) string {
+	name = clickhouseCanonicalType(name)
+	for base, param := clickhouseSplitType(name); base == "LowCardinality" || base == "Nullable"; base, param = clickhouseSplitType(name) {
+		name, nullable = param, nullable || base == "Nullable"
+	}
+	if base, param := clickhouseSplitType(name); base == "Array" {
+		return "[]" + d.GoType(param, false)
+	}
+	for _, types := range [][]*ColumnType{d.opt.columnTypes, clickhouseColumnTypes} {
+		for _, t := range types {
+			if typ, found := t.findGoType(name, nullable, false); found {
+				return typ
+			}
+		}
+	}
+	if strings.IndexByte(name, '(') >= 0 {
+		return d.GoType(trimParens(name), nullable)
+	}
+	return "interface{}"
+}
+
+func (d *ClickHouse) IsNullable(name string) bool {
+	_, ok := d.nullableTypeMap[name]
+	return ok
+}
+
+func (d *ClickHouse) ImportPackage(schema ColumnSchema) string {
+	switch schema.DataType() {
+	case "Date", "Date32", "DateTime", "DateTime64":
+		return "time"
+	}
+	return ""
+}
+
+func (d *ClickHouse) GoTypeImports() ([]Import, error) {
+	return goTypeImports(d.opt.columnTypes)
+}
+
+func (d *ClickHouse) Quote(s string) string {
+	return quoteByBackslash(s, "`")
+}
+
+func (d *ClickHouse) QuoteString(s string) string {
+	return quoteByBackslash(s, "'")
+}
+
+func (d *ClickHouse) CreateTableSQL(table Table) []string {
+	columns := make([]string, len(table.Fields))
+	for i, f := range table.Fields {
+		columns[i] = d.columnSQL(f)
+	}
+	query := fmt.Sprintf("CREATE TABLE %s (\n"+
+		"  %s\n"+
+		") %s", d.table(table.Name), strings.Join(columns, ",\n  "), d.tableOption(table))
+	return []string{query}
+}
+
+func (d *ClickHouse) AddColumnSQL(field Field) []string {
+	return []string{fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", d.table(field.Table), d.columnSQL(field))}
+}
+
+func (d *ClickHouse) DropColumnSQL(field Field) []string {
+	return []string{fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", d.table(field.Table), d.Quote(field.Name))}
+}
+
+func (d *ClickHouse) ModifyColumnSQL(oldField, newField Field) []string {
+	var sqls []string
+	tableName := d.table(newField.Table)
+	if oldField.Name != newField.Name {
+		sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", tableName, d.Quote(oldField.Name), d.Quote(newField.Name)))
+	}
+	column := d.Quote(newField.Name)
+	if oldField.AutoIncrement != newField.AutoIncrement {
+		sqls = append(sqls, fmt.Sprintf("-- auto-increment of column %s.%s is not supported by ClickHouse", tableName, column))
+	}
+	switch {
+	case oldField.Type != newField.Type || oldField.Nullable != newField.Nullable || oldField.Default != newField.Default || oldField.Extra != newField.Extra:
+		// MODIFY COLUMN keeps the default value that is not specified.
+		if oldField.Default != "" && newField.Default == "" {
+			sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s REMOVE DEFAULT", tableName, column))
+		}
+		sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s", tableName, d.columnSQL(newField)))
+	case oldField.Comment != newField.Comment:
+		sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s COMMENT COLUMN %s %s", tableName, column, d.QuoteString(newField.Comment)))
+	}
+	return sqls
+}
+
+func (d *ClickHouse) RenameColumnSQL(oldField, newField Field) []string {
+	return d.ModifyColumnSQL(oldField, newField)
+}
+
+// ModifyPrimaryKeySQL returns the comment because the primary key of ClickHouse cannot be modified.
+func (d *ClickHouse) ModifyPrimaryKeySQL(oldPrimaryKeys, newPrimaryKeys []Field) []string {
+	var tableName string
+	if len(newPrimaryKeys) > 0 {
+		tableName = newPrimaryKeys[0].Table
+	} else {
+		tableName = oldPrimaryKeys[0].Table
+	}
+	return []string{fmt.Sprintf("-- primary key of table %s cannot be modified by ALTER TABLE", d.table(tableName))}
+}
+
+func (d *ClickHouse) RenameTableSQL(oldName, newName string) []string {
+	return []string{fmt.Sprintf("RENAME TABLE %s TO %s", d.table(oldName), d.table(newName))}
+}
+
+func (d *ClickHouse) CreateIndexSQL(index Index) []string {
+	tableName := d.table(index.Table)
+	if index.Unique {
+		return []string{fmt.Sprintf("-- unique index %s of table %s is not supported by ClickHouse", d.Quote(index.Name), tableName)}
+	}
+	columns := make([]string, len(index.Columns))
+	for i, c := range index.Columns {
+		columns[i] = d.Quote(c)
+	}
+	return []string{fmt.Sprintf("ALTER TABLE %s ADD INDEX %s (%s) TYPE %s GRANULARITY 1", tableName, d.Quote(index.Name), strings.Join(columns, ", "), clickhouseIndexType)}
+}
+
+func (d *ClickHouse) DropIndexSQL(index Index) []string {
+	tableName := d.table(index.Table)
+	if index.Unique {
+		return []string{fmt.Sprintf("-- unique index %s of table %s is not supported by ClickHouse", d.Quote(index.Name), tableName)}
+	}
+	return []string{fmt.Sprintf("ALTER TABLE %s DROP INDEX %s", tableName, d.Quote(index.Name))}
+}
+
+func (d *ClickHouse) Begin() (Transactioner, error) {
+	conn, err := d.db.Conn(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return &clickhouseTransaction{
+		conn: conn,
+	}, nil
+}
+
+// table returns the quoted name of the table that is qualified by the database if WithSchema is specified.
+func (d *ClickHouse) table(name string) string {
+	if d.opt.schema == "" {
+		return d.Quote(name)
+	}
+	return d.Quote(d.opt.schema) + "." + d.Quote(name)
+}
+
+// tableOption returns the table option that has the engine and the sorting key.
+func (d *ClickHouse) tableOption(table Table) string {
+	option := table.Option
+	upper := strings.ToUpper(option)
+	if !strings.Contains(upper, "ENGINE") {
+		option = strings.TrimSpace("ENGINE = " + clickhouseDefaultEngine + " " + option)
+		upper = strings.ToUpper(option)
+	}
+	if strings.Contains(upper, "MERGETREE") && !strings.Contains(upper, "ORDER BY") {
+		orderBy := "tuple()"
+		if len(table.PrimaryKeys) > 0 {
+			pkColumns := make([]string, len(table.PrimaryKeys))
+			for i, pk := range table.PrimaryKeys {
+				pkColumns[i] = d.Quote(pk)
+			}
+			orderBy = "(" + strings.Join(pkColumns, ", ") + ")"
+		}
+		// ORDER BY must precede SETTINGS.
+		if i := strings.Index(upper, "SETTINGS"); i >= 0 {
+			option = option[:i] + "ORDER BY " + orderBy + " " + option[i:]
+		} else {
+			option += " ORDER BY " + orderBy
+		}
+	}
+	return option
+}
+
+func (d *ClickHouse) currentDBName() (string, error) {
+	if d.opt.schema != "" {
+		return d.opt.schema, nil
+	}
+	if d.dbName != "" {
+		return d.dbName, nil
+	}
+	if err := d.db.QueryRow(`SELECT currentDatabase()`).Scan(&d.dbName); err != nil {
+		return "", err
+	}
+	return d.dbName, nil
+}
+
+// getIndexMap returns the data skipping indexes of the single columns of the tables.
+func (d *ClickHouse) getIndexMap(dbname string) (map[string]map[string]string, error) {
+	rows, err := d.db.Query("SELECT table, name, expr FROM system.data_skipping_indices WHERE database = ?", dbname)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	indexMap := make(map[string]map[string]string)
+	for rows.Next() {
+		var tableName, indexName, expr string
+		if err := rows.Scan(&tableName, &indexName, &expr); err != nil {
+			return nil, err
+		}
+		column := strings.Trim(strings.TrimSpace(expr), "`")
+		if strings.ContainsAny(column, "(), ") {
+			continue
+		}
+		if _, exists := indexMap[tableName]; !exists {
+			indexMap[tableName] = make(map[string]string)
+		}
+		indexMap[tableName][column] = indexName
+	}
+	return indexMap, rows.Err()
+}
+
+func (d *ClickHouse) columnSQL(f Field) string {
+	column := []string{d.Quote(f.Name), clickhouseNullableType(f.Type, f.Nullable)}
+	if def := f.Default; def != "" {
+		if d.isTextType(f) {
+			def = d.QuoteString(def)
+		}
+		column = append(column, "DEFAULT", def)
+	}
+	if f.Comment != "" {
+		column = append(column, "COMMENT", d.QuoteString(f.Comment))
+	}
+	if f.Extra != "" {
+		column = append(column, f.Extra)
+	}
+	return strings.Join(column, " ")
+}
+
+func (d *ClickHouse) isTextType(f Field) bool {
+	base, param := clickhouseSplitType(f.Type)
+	for base == "LowCardinality" || base == "Nullable" {
+		base, param = clickhouseSplitType(param)
+	}
+	switch base {
+	case "String", "FixedString", "Enum8", "Enum16", "UUID":
+		return true
+	}
+	return false
+}
+
+// clickhouseNullableType returns Nullable(typ) if nullable is true. The Nullable is wrapped by LowCardinality
+// because LowCardinality(Nullable(T)) is allowed but Nullable(LowCardinality(T)) is not.
+func clickhouseNullableType(typ string, nullable bool) string {
+	if !nullable {
+		return typ
+	}
+	switch base, param := clickhouseSplitType(typ); base {
+	case "Nullable":
+		return typ
+	case "LowCardinality":
+		return "LowCardinality(" + clickhouseNullableType(param, true) + ")"
+	}
+	return "Nullable(" + typ + ")"
+}
+
+// clickhouseSplitType returns the name of the type and its parameters in the parentheses.
+// (e.g. "Nullable(String)" to "Nullable" and "String")
+func clickhouseSplitType(typ string) (base, param string) {
+	i := strings.IndexByte(typ, '(')
+	if i < 0 || !strings.HasSuffix(typ, ")") {
+		return typ, ""
+	}
+	return strings.TrimSpace(typ[:i]), strings.TrimSpace(typ[i+1 : len(typ)-1])
+}
+
+// clickhouseCanonicalType returns the name of the type in the same form as system.columns of ClickHouse.
+// (e.g. "string" to "String", "varchar(255)" to "String", "decimal(10,2)" to "Decimal(10, 2)")
+func clickhouseCanonicalType(name string) string {
+	base, param := clickhouseSplitType(strings.TrimSpace(name))
+	if alias, ok := clickhouseTypeAliases[strings.ToUpper(base)]; ok {
+		if alias == "String" {
+			// The length of VARCHAR(n) is ignored by ClickHouse.
+			return alias
+		}
+		base = alias
+	} else {
+		for _, t := range clickhouseTypeNames {
+			if strings.EqualFold(t, base) {
+				base = t
+				break
+			}
+		}
+	}
+	switch base {
+	case "Nullable", "LowCardinality", "Array":
+		param = clickhouseCanonicalType(param)
+	case "Decimal":
+		params := strings.Split(param, ",")
+		for i, p := range params {
+			params[i] = strings.TrimSpace(p)
+		}
+		if len(params) == 1 && params[0] != "" {
+			params = append(params, "0")
+		}
+		param = strings.Join(params, ", ")
+	}
+	if param != "" {
+		return base + "(" + param + ")"
+	}
+	return base
+}
+
+// clickhouseTransaction executes the statements one by one on a connection because ClickHouse does not support
+// the transactions of the schema changes.
+type clickhouseTransaction struct {
+	conn *sql.Conn
+}
+
+func (c *clickhouseTransaction) Exec(sql string, args ...interface{}) error {
+	_, err := c.conn.ExecContext(context.Background(), sql, args...)
+	return err
+}
+
+func (c *clickhouseTransaction) Commit() error {
+	return c.conn.Close()
+}
+
+// Rollback does not roll back the executed statements.
+func (c *clickhouseTransaction) Rollback() error {
+	return c.conn.Close()
+}
+
+var _ ColumnSchema = &clickhouseColumnSchema{}
+
+type clickhouseColumnSchema struct {
+	tableName         string
+	columnName        string
+	columnType        string
+	defaultKind       string
+	defaultExpression string
+	comment           string
+	isInPrimaryKey    int64
+	indexName         string
+}
+
+func (schema *clickhouseColumnSchema) TableName() string {
+	return schema.tableName
+}
+
+func (schema *clickhouseColumnSchema) ColumnName() string {
+	return schema.columnName
+}
+
+// ColumnType returns the type of the column without Nullable. (e.g. "LowCardinality(Nullable(String))" to
+// "LowCardinality(String)")
+func (schema *clickhouseColumnSchema) ColumnType() string {
+	typ, _ := clickhouseNotNullType(schema.columnType)
+	return typ
+}
+
+// DataType returns the name of the type without the wrappers and the parameters. (e.g. "DateTime64" for
+// "Nullable(DateTime64(3))")
+func (schema *clickhouseColumnSchema) DataType() string {
+	base, param := clickhouseSplitType(schema.columnType)
+	for base == "LowCardinality" || base == "Nullable" {
+		base, param = clickhouseSplitType(param)
+	}
+	return base
+}
+
+func (schema *clickhouseColumnSchema) IsPrimaryKey() bool {
+	return schema.isInPrimaryKey != 0
+}
+
+func (schema *clickhouseColumnSchema) IsAutoIncrement() bool {
+	return false
+}
+
+func (schema *clickhouseColumnSchema) Index() (name string, unique bool, ok bool) {
+	if schema.indexName != "" {
+		return schema.indexName, false, true
+	}
+	return "", false, false
+}
+
+func (schema *clickhouseColumnSchema) Default() (string, bool) {
+	if schema.defaultKind != "DEFAULT" {
+		return "", false
+	}
+	def := schema.defaultExpression
+	if len(def) >= 2 && def[0] == '\'' && def[len(def)-1] == '\'' {
+		def = strings.NewReplacer(`\'`, `'`, `\\`, `\`).Replace(def[1 : len(def)-1]) // unescape string
+	}
+	return def, true
+}
+
+func (schema *clickhouseColumnSchema) IsNullable() bool {
+	_, nullable := clickhouseNotNullType(schema.columnType)
+	return nullable
+}
+
+func (schema *clickhouseColumnSchema) Extra() (string, bool) {
+	return "", false
+}
+
+func (schema *clickhouseColumnSchema) Comment() (string, bool) {
+	return schema.comment, schema.comment != ""
+}
+
+// clickhouseNotNullType returns the type without Nullable and whether the type is nullable.
+func clickhouseNotNullType(typ string) (string, bool) {
+	switch base, param := clickhouseSplitType(typ); base {
+	case "Nullable":
+		return param, true
+	case "LowCardinality":
+		if t, nullable := clickhouseNotNullType(param); nullable {
+			return "LowCardinality(" + t + ")", true
+		}
+	}
+	return typ, false
+}
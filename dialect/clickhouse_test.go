@@ -0,0 +1,127 @@
+package dialect_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/naoina/migu/dialect"
+)
+
+func TestClickHouseColumnType(t *testing.T) {
+	d := dialect.NewClickHouse(nil)
+	for _, v := range []struct {
+		name   string
+		expect string
+	}{
+		{"string", "String"},
+		{"int", "Int64"},
+		{"uint8", "UInt8"},
+		{"bool", "Bool"},
+		{"float64", "Float64"},
+		{"time.Time", "DateTime64(6)"},
+		{"[]byte", "String"},
+		{"varchar(255)", "String"},
+		{"lowcardinality(string)", "LowCardinality(String)"},
+		{"array(int32)", "Array(Int32)"},
+		{"decimal(10,2)", "Decimal(10, 2)"},
+		{"Decimal(10)", "Decimal(10, 0)"},
+		{"DateTime('UTC')", "DateTime('UTC')"},
+	} {
+		if actual := d.ColumnType(v.name); actual != v.expect {
+			t.Errorf("ColumnType(%q) => %q; want %q", v.name, actual, v.expect)
+		}
+	}
+	for _, v := range []struct {
+		name     string
+		nullable bool
+		expect   string
+	}{
+		{"String", false, "string"},
+		{"String", true, "*string"},
+		{"Nullable(Int64)", false, "*int64"},
+		{"LowCardinality(String)", false, "string"},
+		{"LowCardinality(Nullable(String))", false, "*string"},
+		{"Array(Int32)", false, "[]int32"},
+		{"Decimal(10, 2)", false, "float64"},
+		{"DateTime64(3)", false, "time.Time"},
+		{"UUID", false, "interface{}"},
+	} {
+		if actual := d.GoType(v.name, v.nullable); actual != v.expect {
+			t.Errorf("GoType(%q, %v) => %q; want %q", v.name, v.nullable, actual, v.expect)
+		}
+	}
+}
+
+func TestClickHouseSQL(t *testing.T) {
+	d := dialect.NewClickHouse(nil, dialect.WithSchema("analytics"))
+	id := dialect.Field{Table: "event", Name: "id", Type: "UInt64"}
+	kind := dialect.Field{Table: "event", Name: "kind", Type: "LowCardinality(String)", Nullable: true, Default: "it's", Comment: "kind of the event"}
+	at := dialect.Field{Table: "event", Name: "at", Type: "DateTime64(6)", Default: "now64()", Extra: "CODEC(Delta, ZSTD)"}
+	for _, v := range []struct {
+		actual []string
+		expect []string
+	}{
+		{
+			d.CreateTableSQL(dialect.Table{Name: "event", Fields: []dialect.Field{id, kind, at}, PrimaryKeys: []string{"id", "at"}}),
+			[]string{
+				"CREATE TABLE `analytics`.`event` (\n" +
+					"  `id` UInt64,\n" +
+					"  `kind` LowCardinality(Nullable(String)) DEFAULT 'it\\'s' COMMENT 'kind of the event',\n" +
+					"  `at` DateTime64(6) DEFAULT now64() CODEC(Delta, ZSTD)\n" +
+					") ENGINE = MergeTree() ORDER BY (`id`, `at`)",
+			},
+		},
+		{
+			d.CreateTableSQL(dialect.Table{Name: "event", Fields: []dialect.Field{id}, Option: "ENGINE = ReplacingMergeTree PARTITION BY toYYYYMM(at) ORDER BY id"}),
+			[]string{
+				"CREATE TABLE `analytics`.`event` (\n" +
+					"  `id` UInt64\n" +
+					") ENGINE = ReplacingMergeTree PARTITION BY toYYYYMM(at) ORDER BY id",
+			},
+		},
+		{
+			d.CreateTableSQL(dialect.Table{Name: "event", Fields: []dialect.Field{id}, Option: "SETTINGS index_granularity = 4096"}),
+			[]string{
+				"CREATE TABLE `analytics`.`event` (\n" +
+					"  `id` UInt64\n" +
+					") ENGINE = MergeTree() ORDER BY tuple() SETTINGS index_granularity = 4096",
+			},
+		},
+		{
+			d.ModifyColumnSQL(kind, dialect.Field{Table: "event", Name: "category", Type: "String", Comment: "kind of the event"}),
+			[]string{
+				"ALTER TABLE `analytics`.`event` RENAME COLUMN `kind` TO `category`",
+				"ALTER TABLE `analytics`.`event` MODIFY COLUMN `category` REMOVE DEFAULT",
+				"ALTER TABLE `analytics`.`event` MODIFY COLUMN `category` String COMMENT 'kind of the event'",
+			},
+		},
+		{
+			d.ModifyColumnSQL(id, dialect.Field{Table: "event", Name: "id", Type: "UInt64", Comment: "ID"}),
+			[]string{
+				"ALTER TABLE `analytics`.`event` COMMENT COLUMN `id` 'ID'",
+			},
+		},
+		{
+			d.(dialect.PrimaryKeyModifier).ModifyPrimaryKeySQL([]dialect.Field{id}, []dialect.Field{id, at}),
+			[]string{
+				"-- primary key of table `analytics`.`event` cannot be modified by ALTER TABLE",
+			},
+		},
+		{
+			d.CreateIndexSQL(dialect.Index{Table: "event", Name: "event_at", Columns: []string{"at"}}),
+			[]string{
+				"ALTER TABLE `analytics`.`event` ADD INDEX `event_at` (`at`) TYPE minmax GRANULARITY 1",
+			},
+		},
+		{
+			d.CreateIndexSQL(dialect.Index{Table: "event", Name: "event_kind", Columns: []string{"kind"}, Unique: true}),
+			[]string{
+				"-- unique index `event_kind` of table `analytics`.`event` is not supported by ClickHouse",
+			},
+		},
+	} {
+		if diff := cmp.Diff(v.actual, v.expect); diff != "" {
+			t.Errorf("(-got +want)\n%v", diff)
+		}
+	}
+}
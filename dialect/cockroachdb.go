@@ -0,0 +1,320 @@
+package dialect
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+var (
+	_ PrimaryKeyModifier = &CockroachDB{}
+	_ IndexReader        = &CockroachDB{}
+	_ RowReader          = &CockroachDB{}
+	_ TableRenamer       = &CockroachDB{}
+	_ ColumnRenamer      = &CockroachDB{}
+	_ TableAnalyzer      = &CockroachDB{}
+)
+
+// cockroachUniqueRowID is the default value of the auto-increment columns that is the same as SERIAL of CockroachDB.
+const cockroachUniqueRowID = "unique_rowid()"
+
+var (
+	cockroachColumnTypes = []*ColumnType{
+		{
+			Types:           []string{"TEXT", "CHARACTER VARYING", "CHARACTER"},
+			GoTypes:         []string{"string"},
+			GoNullableTypes: []string{"*string", "sql.NullString"},
+		},
+		{
+			Types:           []string{"BYTEA"},
+			GoTypes:         []string{"[]byte"},
+			GoNullableTypes: []string{"[]byte"},
+		},
+		{
+			// INT of CockroachDB is a 64-bit integer.
+			Types:           []string{"BIGINT"},
+			GoTypes:         []string{"int", "int64", "uint32"},
+			GoNullableTypes: []string{"*int64", "sql.NullInt64"},
+		},
+		{
+			// INTEGER returned by format_type() is INT4 though INTEGER in the DDLs is INT8.
+			Types:           []string{"INT4", "INTEGER"},
+			GoTypes:         []string{"int32", "uint16"},
+			GoNullableTypes: []string{"*int32", "sql.NullInt32"},
+		},
+		{
+			Types:   []string{"SMALLINT"},
+			GoTypes: []string{"int16", "int8", "uint8"},
+		},
+		{
+			// NUMERIC(20,0) can hold all the values of uint64.
+			Types:   []string{"NUMERIC(20,0)"},
+			GoTypes: []string{"uint64", "uint"},
+		},
+		{
+			Types:           []string{"BOOLEAN"},
+			GoTypes:         []string{"bool"},
+			GoNullableTypes: []string{"*bool", "sql.NullBool"},
+		},
+		{
+			Types:           []string{"DOUBLE PRECISION", "NUMERIC"},
+			GoTypes:         []string{"float64"},
+			GoNullableTypes: []string{"*float64", "sql.NullFloat64"},
+		},
+		{
+			Types:   []string{"REAL"},
+			GoTypes: []string{"float32"},
+		},
+		{
+			Types:           []string{"TIMESTAMP WITH TIME ZONE", "TIMESTAMP WITHOUT TIME ZONE", "DATE"},
+			GoTypes:         []string{"time.Time"},
+			GoNullableTypes: []string{"*time.Time", "sql.NullTime", "pq.NullTime"},
+		},
+		{
+			Types:           []string{"JSONB"},
+			GoTypes:         []string{"json.RawMessage"},
+			GoNullableTypes: []string{"json.RawMessage"},
+		},
+	}
+
+	// cockroachTypeAliases are the names of the types of CockroachDB that are different from the ones of
+	// PostgreSQL.
+	cockroachTypeAliases = map[string]string{
+		"INT":     "BIGINT",
+		"INTEGER": "BIGINT",
+		"INT64":   "BIGINT",
+		"STRING":  "TEXT",
+		"BYTES":   "BYTEA",
+		"JSON":    "JSONB",
+	}
+)
+
+// CockroachDB is the dialect of CockroachDB.
+// It is the same as the dialect of PostgreSQL except the following differences.
+//
+// The auto-increment columns have unique_rowid() as their defaults in the same way as SERIAL of CockroachDB.
+// The hidden columns such as rowid are not read from information_schema.
+// The schema changes are not executed in a transaction because CockroachDB runs them online as the background jobs
+// and does not support some of them in an explicit transaction. It means that the executed statements are not
+// rolled back on an error.
+type CockroachDB struct {
+	*Postgres
+}
+
+// NewCockroachDB returns a new dialect of CockroachDB. db must be opened by the driver of PostgreSQL such as lib/pq.
+func NewCockroachDB(db *sql.DB, opts ...Option) Dialect {
+	return &CockroachDB{
+		Postgres: newPostgres(db, cockroachColumnTypes, opts...),
+	}
+}
+
+// SchemaLimits returns no limits since CockroachDB does not truncate the identifiers unlike PostgreSQL.
+func (d *CockroachDB) SchemaLimits(table Table) SchemaLimits {
+	return SchemaLimits{}
+}
+
+func (d *CockroachDB) ColumnSchema(tables ...string) ([]ColumnSchema, error) {
+	schemas, err := d.Postgres.columnSchema("c.is_hidden = 'NO'", tables)
+	if err != nil {
+		return nil, err
+	}
+	for i, schema := range schemas {
+		schemas[i] = &cockroachColumnSchema{postgresColumnSchema: schema.(*postgresColumnSchema)}
+	}
+	return schemas, nil
+}
+
+func (d *CockroachDB) ColumnType(name string) string {
+	if t, ok := d.columnTypeMap[name]; ok {
+		name, _, _, _ = t.findType(name)
+	}
+	return cockroachCanonicalType(name)
+}
+
+func (d *CockroachDB) CreateTableSQL(table Table) []string {
+	fields := make([]Field, len(table.Fields))
+	for i, f := range table.Fields {
+		fields[i] = cockroachField(f)
+	}
+	table.Fields = fields
+	return d.Postgres.CreateTableSQL(table)
+}
+
+func (d *CockroachDB) AddColumnSQL(field Field) []string {
+	return d.Postgres.AddColumnSQL(cockroachField(field))
+}
+
+func (d *CockroachDB) ModifyColumnSQL(oldField, newField Field) []string {
+	oldField, newField = cockroachField(oldField), cockroachField(newField)
+	var sqls []string
+	tableName := d.table(newField.Table)
+	if oldField.Name != newField.Name {
+		sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", tableName, d.Quote(oldField.Name), d.Quote(newField.Name)))
+		oldField.Name = newField.Name
+	}
+	if oldField.Type != newField.Type {
+		// ALTER COLUMN TYPE that rewrites the column cannot be combined with the other specifications.
+		column := d.Quote(newField.Name)
+		sqls = append(sqls,
+			"SET enable_experimental_alter_column_type_general = true",
+			fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s USING %s::%s", tableName, column, newField.Type, column, newField.Type),
+		)
+		oldField.Type = newField.Type
+	}
+	return append(sqls, d.Postgres.ModifyColumnSQL(oldField, newField)...)
+}
+
+func (d *CockroachDB) RenameColumnSQL(oldField, newField Field) []string {
+	return d.ModifyColumnSQL(oldField, newField)
+}
+
+func (d *CockroachDB) ModifyPrimaryKeySQL(oldPrimaryKeys, newPrimaryKeys []Field) []string {
+	if len(newPrimaryKeys) == 0 {
+		return d.Postgres.ModifyPrimaryKeySQL(oldPrimaryKeys, newPrimaryKeys)
+	}
+	pkColumns := make([]string, len(newPrimaryKeys))
+	for i, pk := range newPrimaryKeys {
+		pkColumns[i] = d.Quote(pk.Name)
+	}
+	// ALTER PRIMARY KEY changes the primary key online and keeps the old one as a unique index.
+	return []string{fmt.Sprintf("ALTER TABLE %s ALTER PRIMARY KEY USING COLUMNS (%s)", d.table(newPrimaryKeys[0].Table), strings.Join(pkColumns, ", "))}
+}
+
+func (d *CockroachDB) RenameTableSQL(oldName, newName string) []string {
+	// The new name is qualified so that the table is not moved to the current schema.
+	return []string{fmt.Sprintf("ALTER TABLE %s RENAME TO %s", d.table(oldName), d.table(newName))}
+}
+
+func (d *CockroachDB) DropIndexSQL(index Index) []string {
+	return []string{fmt.Sprintf("DROP INDEX %s@%s", d.table(index.Table), d.Quote(index.Name))}
+}
+
+func (d *CockroachDB) Begin() (Transactioner, error) {
+	conn, err := d.db.Conn(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return &cockroachTransaction{
+		conn: conn,
+	}, nil
+}
+
+// Indexes returns the indexes except the ones that have the hidden columns such as the primary key of rowid.
+func (d *CockroachDB) Indexes(tables ...string) ([]Index, error) {
+	indexes, err := d.Postgres.Indexes(tables...)
+	if err != nil {
+		return nil, err
+	}
+	hidden, err := d.hiddenColumns()
+	if err != nil {
+		return nil, err
+	}
+	result := indexes[:0]
+	for _, index := range indexes {
+		visible := true
+		for _, c := range index.Columns {
+			visible = visible && !hidden[index.Table][c]
+		}
+		if visible {
+			result = append(result, index)
+		}
+	}
+	return result, nil
+}
+
+// hiddenColumns returns the hidden columns of the tables.
+func (d *CockroachDB) hiddenColumns() (map[string]map[string]bool, error) {
+	schema, err := d.currentSchema()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := d.db.Query("SELECT table_name, column_name FROM information_schema.columns WHERE table_schema = $1 AND is_hidden = 'YES'", schema)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	hidden := make(map[string]map[string]bool)
+	for rows.Next() {
+		var tableName, columnName string
+		if err := rows.Scan(&tableName, &columnName); err != nil {
+			return nil, err
+		}
+		if _, exists := hidden[tableName]; !exists {
+			hidden[tableName] = make(map[string]bool)
+		}
+		hidden[tableName][columnName] = true
+	}
+	return hidden, rows.Err()
+}
+
+// cockroachField returns the field that has unique_rowid() as the default instead of the identity if it is an
+// auto-increment column.
+func cockroachField(f Field) Field {
+	if f.AutoIncrement && f.Default == "" {
+		f.AutoIncrement, f.Default = false, cockroachUniqueRowID
+	}
+	return f
+}
+
+// cockroachCanonicalType returns the name of the type in the same form as format_type() of CockroachDB in upper
+// case. (e.g. "int" to "BIGINT", "string(255)" to "CHARACTER VARYING(255)")
+func cockroachCanonicalType(name string) string {
+	name = strings.ToUpper(strings.Join(strings.Fields(name), " "))
+	base, rest := name, ""
+	if i := strings.IndexAny(name, "([ "); i >= 0 {
+		base, rest = name[:i], name[i:]
+	}
+	switch alias, ok := cockroachTypeAliases[base]; {
+	case base == "STRING" && strings.HasPrefix(rest, "("):
+		base = "CHARACTER VARYING"
+	case ok:
+		base = alias
+	}
+	return postgresCanonicalType(base + rest)
+}
+
+// cockroachTransaction executes the statements one by one on a connection without a transaction because the
+// schema changes of CockroachDB are not transactional. The connection keeps the session variables between the
+// statements.
+type cockroachTransaction struct {
+	conn *sql.Conn
+}
+
+func (c *cockroachTransaction) Exec(sql string, args ...interface{}) error {
+	_, err := c.conn.ExecContext(context.Background(), sql, args...)
+	return err
+}
+
+func (c *cockroachTransaction) Commit() error {
+	return c.conn.Close()
+}
+
+// Rollback does not roll back the executed statements.
+func (c *cockroachTransaction) Rollback() error {
+	return c.conn.Close()
+}
+
+var _ ColumnSchema = &cockroachColumnSchema{}
+
+type cockroachColumnSchema struct {
+	*postgresColumnSchema
+}
+
+// IsAutoIncrement reports whether the column is an identity column or has unique_rowid() as its default such as
+// SERIAL.
+func (schema *cockroachColumnSchema) IsAutoIncrement() bool {
+	return schema.postgresColumnSchema.IsAutoIncrement() || schema.columnDefault.String == cockroachUniqueRowID
+}
+
+func (schema *cockroachColumnSchema) Default() (string, bool) {
+	if schema.IsAutoIncrement() {
+		return "", false
+	}
+	// The type cast of CockroachDB is like "'x':::STRING".
+	s := *schema.postgresColumnSchema
+	if i := strings.LastIndex(s.columnDefault.String, ":::"); i >= 0 {
+		s.columnDefault.String = s.columnDefault.String[:i] + s.columnDefault.String[i+1:]
+	}
+	return s.Default()
+}
@@ -0,0 +1,108 @@
+package dialect_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/naoina/migu/dialect"
+)
+
+func TestCockroachDBColumnType(t *testing.T) {
+	d := dialect.NewCockroachDB(nil)
+	for _, v := range []struct {
+		name   string
+		expect string
+	}{
+		{"string", "TEXT"},
+		{"int", "BIGINT"},
+		{"int32", "INTEGER"},
+		{"uint64", "NUMERIC(20,0)"},
+		{"INT", "BIGINT"},
+		{"integer", "BIGINT"},
+		{"int4", "INTEGER"},
+		{"STRING", "TEXT"},
+		{"string(255)", "CHARACTER VARYING(255)"},
+		{"bytes", "BYTEA"},
+		{"timestamptz", "TIMESTAMP WITH TIME ZONE"},
+		{"int[]", "BIGINT[]"},
+		{"json", "JSONB"},
+	} {
+		if actual := d.ColumnType(v.name); actual != v.expect {
+			t.Errorf("ColumnType(%q) => %q; want %q", v.name, actual, v.expect)
+		}
+	}
+	for _, v := range []struct {
+		name     string
+		nullable bool
+		expect   string
+	}{
+		{"BIGINT", false, "int"},
+		{"INTEGER", true, "*int32"},
+		{"TEXT", false, "string"},
+		{"CHARACTER VARYING(255)", true, "*string"},
+		{"BYTEA", false, "[]byte"},
+		{"JSONB", true, "json.RawMessage"},
+	} {
+		if actual := d.GoType(v.name, v.nullable); actual != v.expect {
+			t.Errorf("GoType(%q, %v) => %q; want %q", v.name, v.nullable, actual, v.expect)
+		}
+	}
+}
+
+func TestCockroachDBSQL(t *testing.T) {
+	d := dialect.NewCockroachDB(nil, dialect.WithSchema("app"))
+	id := dialect.Field{Table: "user", Name: "id", Type: "BIGINT", AutoIncrement: true}
+	name := dialect.Field{Table: "user", Name: "name", Type: "CHARACTER VARYING(255)", Default: "it's"}
+	for _, v := range []struct {
+		actual []string
+		expect []string
+	}{
+		{
+			d.CreateTableSQL(dialect.Table{Name: "user", Fields: []dialect.Field{id, name}, PrimaryKeys: []string{"id"}}),
+			[]string{
+				"CREATE TABLE \"app\".\"user\" (\n" +
+					"  \"id\" BIGINT NOT NULL DEFAULT unique_rowid(),\n" +
+					"  \"name\" CHARACTER VARYING(255) NOT NULL DEFAULT 'it''s',\n" +
+					"  PRIMARY KEY (\"id\")\n" +
+					")",
+			},
+		},
+		{
+			d.ModifyColumnSQL(name, dialect.Field{Table: "user", Name: "full_name", Type: "TEXT", Nullable: true}),
+			[]string{
+				"ALTER TABLE \"app\".\"user\" RENAME COLUMN \"name\" TO \"full_name\"",
+				"SET enable_experimental_alter_column_type_general = true",
+				"ALTER TABLE \"app\".\"user\" ALTER COLUMN \"full_name\" TYPE TEXT USING \"full_name\"::TEXT",
+				"ALTER TABLE \"app\".\"user\" ALTER COLUMN \"full_name\" DROP NOT NULL, ALTER COLUMN \"full_name\" DROP DEFAULT",
+			},
+		},
+		{
+			d.ModifyColumnSQL(dialect.Field{Table: "user", Name: "id", Type: "BIGINT"}, id),
+			[]string{
+				"ALTER TABLE \"app\".\"user\" ALTER COLUMN \"id\" SET DEFAULT unique_rowid()",
+			},
+		},
+		{
+			d.(dialect.PrimaryKeyModifier).ModifyPrimaryKeySQL([]dialect.Field{id}, []dialect.Field{id, name}),
+			[]string{
+				"ALTER TABLE \"app\".\"user\" ALTER PRIMARY KEY USING COLUMNS (\"id\", \"name\")",
+			},
+		},
+		{
+			d.(dialect.TableRenamer).RenameTableSQL("user", "member"),
+			[]string{
+				"ALTER TABLE \"app\".\"user\" RENAME TO \"app\".\"member\"",
+			},
+		},
+		{
+			d.DropIndexSQL(dialect.Index{Table: "user", Name: "user_name"}),
+			[]string{
+				"DROP INDEX \"app\".\"user\"@\"user_name\"",
+			},
+		},
+	} {
+		if diff := cmp.Diff(v.actual, v.expect); diff != "" {
+			t.Errorf("(-got +want)\n%v", diff)
+		}
+	}
+}
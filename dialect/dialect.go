@@ -1,5 +1,14 @@
 package dialect
 
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
 type Dialect interface {
 	ColumnSchema(tables ...string) ([]ColumnSchema, error)
 	ColumnType(name string) string
@@ -39,15 +48,520 @@ type Transactioner interface {
 	Rollback() error
 }
 
+// ColumnSchemaStreamer is implemented by dialects that can read the column schemas table by table without loading
+// the ones of all the tables into memory.
+type ColumnSchemaStreamer interface {
+	// StreamColumnSchema calls fn with the column schemas of each table in the order of the table names.
+	// It stops reading with the error of ctx when ctx is done.
+	StreamColumnSchema(ctx context.Context, fn func(columns []ColumnSchema) error) error
+}
+
 type PrimaryKeyModifier interface {
 	ModifyPrimaryKeySQL(oldPrimaryKeys, newPrimaryKeys []Field) []string
 }
 
+// PrimaryKeyChecker is implemented by dialects that cannot modify the primary keys of some tables.
+type PrimaryKeyChecker interface {
+	// CheckModifyPrimaryKey returns an error if the existing primary key of the table cannot be modified by
+	// ModifyPrimaryKeySQL.
+	CheckModifyPrimaryKey(table string) error
+}
+
+// TableRecreator is implemented by dialects that cannot modify the primary keys of the existing tables, but can
+// migrate them by recreating the tables.
+type TableRecreator interface {
+	// RecreateTableSQL returns the SQLs to create the table with the new primary keys, copy the rows from the old
+	// table and replace it.
+	RecreateTableSQL(oldTable, newTable Table) []string
+}
+
+// StatementFinalizer is implemented by dialects that post-process the SQLs of each schema change, such as splitting
+// the statement that cannot be combined or wrapping the statements, so that the diff stays generic.
+type StatementFinalizer interface {
+	// FinalizeStatement returns the final SQLs of the schema change.
+	FinalizeStatement(stmt Statement) []string
+}
+
+// StatelessGenerator is implemented by dialects that build the SQLs of each change only from the change itself. The
+// SQLs of the other dialects may depend on the state of the table that the preceding changes have modified, such as
+// the rebuilds of the tables of SQLite, so that the changes of the same table are never reordered.
+type StatelessGenerator interface {
+	// IsStateless reports whether the SQLs of the changes do not depend on each other.
+	IsStateless() bool
+}
+
+// Statement is the schema change that is passed to StatementFinalizer.
+type Statement struct {
+	// Kind is the kind of the change such as "add_column". It is the same as migu.ChangeKind.
+	Kind string
+
+	// Table is the table name of the change, or empty if the change is not of a table.
+	Table string
+
+	// SQLs are the SQLs of the change that are built by the other methods of the dialect.
+	SQLs []string
+}
+
+// Estimator is implemented by dialects that can estimate the cost of the changes.
+type Estimator interface {
+	// EstimateRows returns the estimated number of rows to be scanned by a full scan of the table.
+	EstimateRows(table string) (int64, error)
+}
+
+// IndexAdvisor is implemented by dialects that can provide the statistics of index usage.
+type IndexAdvisor interface {
+	// UnusedIndexes returns the indexes that have never been used since the server started.
+	UnusedIndexes() ([]Index, error)
+
+	// NoIndexQueries returns the query texts that have been executed without using any good index.
+	NoIndexQueries() ([]string, error)
+}
+
+// IndexStatistician is implemented by dialects that can provide the statistics of the index.
+type IndexStatistician interface {
+	IndexStatistics(table, name string) (IndexStatistics, error)
+}
+
+// IndexStatistics represents the statistics of the index.
+type IndexStatistics struct {
+	// Cardinality is the estimated number of the unique values in the index.
+	Cardinality int64
+
+	// Size is the size of the index in bytes. It is -1 if the size is unknown.
+	Size int64
+
+	// Reads is the number of the reads using the index since the server started.
+	Reads int64
+}
+
+// DuplicateReader is implemented by dialects that can find the duplicate values of the columns.
+type DuplicateReader interface {
+	// Duplicates returns at most limit values of the columns that appear in more than one row, in descending order of
+	// the number of the rows. The rows that have NULL in any of the columns are not counted because they do not
+	// violate the unique index.
+	Duplicates(table string, columns []string, limit int) ([]Duplicate, error)
+}
+
+// Duplicate represents the values of the columns that appear in more than one row.
+type Duplicate struct {
+	Values []string
+	Count  int64
+}
+
+// OrphanReader is implemented by dialects that can find the rows that violate the foreign key.
+type OrphanReader interface {
+	// Orphans returns the number of the rows of the table of the foreign key that reference no rows of the referenced
+	// table, and at most limit distinct values of the columns of them. The rows that have NULL in any of the columns are
+	// not counted because they do not violate the foreign key.
+	Orphans(fk ForeignKey, limit int) (Orphans, error)
+}
+
+// Orphans represents the rows that reference no rows by the foreign key.
+type Orphans struct {
+	Count  int64
+	Values [][]string
+}
+
+// HealthChecker is implemented by dialects that can report the load of the database.
+type HealthChecker interface {
+	Health() (Health, error)
+}
+
+// Health represents the load of the database. The negative value of each field means that it is unknown.
+type Health struct {
+	// ThreadsRunning is the number of the threads that are not sleeping.
+	ThreadsRunning int64
+
+	// ReplicationLag is the replication lag of the connected server.
+	ReplicationLag time.Duration
+
+	// CPUUtilization is the CPU utilization of the database in the range of 0 to 1.
+	CPUUtilization float64
+}
+
+// ProgressReporter is implemented by dialects that can report the progress of the running DDL statements on the
+// server. Progress is called on another connection than the one that executes the statements.
+type ProgressReporter interface {
+	Progress(ctx context.Context) ([]Progress, error)
+}
+
+// Progress represents the progress of a running DDL statement that is reported by the server.
+type Progress struct {
+	// Statement is the statement being executed. It may be truncated by the server.
+	Statement string
+
+	// Stage is the name of the current stage of the statement.
+	Stage string
+
+	// WorkCompleted and WorkEstimated are the amount of the completed work and the estimated total work of the
+	// stage. WorkEstimated is 0 if it is unknown.
+	WorkCompleted int64
+	WorkEstimated int64
+
+	// Elapsed is the elapsed time of the stage.
+	Elapsed time.Duration
+}
+
+// Percent returns the percentage of the completed work, or -1 if the estimated total work is unknown.
+func (p Progress) Percent() float64 {
+	if p.WorkEstimated <= 0 {
+		return -1
+	}
+	return float64(p.WorkCompleted) * 100 / float64(p.WorkEstimated)
+}
+
+// IndexReader is implemented by dialects that can read all the indexes of the tables including the primary keys.
+// The primary key is represented as the unique index named "PRIMARY".
+type IndexReader interface {
+	Indexes(tables ...string) ([]Index, error)
+}
+
+// ForeignKeyManager is implemented by dialects that support the foreign key constraints.
+type ForeignKeyManager interface {
+	// ForeignKeys returns the foreign keys of the tables, or of all the tables if no table is given.
+	ForeignKeys(tables ...string) ([]ForeignKey, error)
+
+	AddForeignKeySQL(fk ForeignKey) []string
+	DropForeignKeySQL(fk ForeignKey) []string
+}
+
+// CheckConstraintManager is implemented by dialects that support the CHECK constraints.
+type CheckConstraintManager interface {
+	// CheckConstraints returns the CHECK constraints of the tables, or of all the tables if no table is given.
+	CheckConstraints(tables ...string) ([]CheckConstraint, error)
+
+	AddCheckConstraintSQL(c CheckConstraint) []string
+	DropCheckConstraintSQL(c CheckConstraint) []string
+}
+
+// RowReader is implemented by dialects that can read the rows of the tables.
+// fn is called for each row with the values of the columns, and a nil value represents NULL.
+type RowReader interface {
+	ReadRows(table string, columns []string, fn func(values []*string) error) error
+}
+
+// TableEncrypter is implemented by dialects that support the encryption at rest of the tables.
+type TableEncrypter interface {
+	// TableEncryptions returns whether each table is encrypted.
+	TableEncryptions(tables ...string) (map[string]bool, error)
+
+	// ModifyTableEncryptionSQL returns SQLs to encrypt or decrypt the table.
+	ModifyTableEncryptionSQL(table string, encrypted bool) []string
+}
+
+// TableCompressor is implemented by dialects that support the page compression of the tables.
+type TableCompressor interface {
+	// TableCompressions returns the compression algorithm of each table such as "zlib", or "none" if the table is
+	// not compressed.
+	TableCompressions(tables ...string) (map[string]string, error)
+
+	// ModifyTableCompressionSQL returns SQLs to change the compression algorithm of the table.
+	ModifyTableCompressionSQL(table string, compression string) []string
+}
+
+// CompressedColumnSchema is implemented by the column schemas that can report whether the column is compressed.
+type CompressedColumnSchema interface {
+	IsCompressed() bool
+}
+
+// CollatedColumnSchema is implemented by the column schemas that can report the character set and the collation of
+// the string column.
+type CollatedColumnSchema interface {
+	// Collation returns the character set and the collation of the column, or empty if it is not a string column,
+	// and whether they are the defaults of the table.
+	Collation() (charset, collation string, isDefault bool)
+}
+
+// OnUpdateColumnSchema is implemented by the column schemas that can report ON UPDATE clause of the column apart from
+// Extra.
+type OnUpdateColumnSchema interface {
+	// OnUpdate returns the expression that the column is set to whenever the row is updated.
+	OnUpdate() (string, bool)
+}
+
+// SpatialColumnSchema is implemented by the column schemas that can report the spatial reference system of the
+// spatial column.
+type SpatialColumnSchema interface {
+	// SRID returns the ID of the spatial reference system of the column, or false if it is not restricted.
+	SRID() (uint32, bool)
+}
+
+// GeneratedColumnSchema is implemented by the column schemas that can report whether the column is a generated column
+// that is computed from the expression.
+type GeneratedColumnSchema interface {
+	IsGenerated() bool
+}
+
+// The persistences of the tables that are not persisted normally.
+const (
+	// PersistenceTemporary is the persistence of the temporary tables that exist only in the session.
+	PersistenceTemporary = "temporary"
+
+	// PersistenceUnlogged is the persistence of the tables that are not written to the write-ahead log.
+	PersistenceUnlogged = "unlogged"
+)
+
+// TablePersister is implemented by dialects that support the temporary or the unlogged tables.
+type TablePersister interface {
+	// SupportsPersistence reports whether the tables of the persistence can be created.
+	SupportsPersistence(persistence string) bool
+}
+
+// UserManager is implemented by dialects that can manage the database users, the roles and the grants.
+// The returned SQLs must be idempotent.
+type UserManager interface {
+	CreateUserSQL(user User) []string
+	GrantSQL(user User, grant Grant) []string
+}
+
+// UserReader is implemented by the UserManager dialects that can read the existing users, so that only the changes of
+// the users are applied.
+type UserReader interface {
+	// ReadUser returns the current state of the user or the role, or nil if it does not exist.
+	ReadUser(user User) (*UserState, error)
+
+	// AlterUserSQL returns the SQLs to change the password of the existing user if changePassword is true, and to grant
+	// the roles of user to it.
+	AlterUserSQL(user User, changePassword bool) []string
+}
+
+// UserState is the current state of the existing user or role.
+type UserState struct {
+	// PasswordMatches reports whether the password of the user is the same as the one of the given user. It is false
+	// if the password cannot be compared.
+	PasswordMatches bool
+
+	// Roles are the roles that are granted to the user.
+	Roles []string
+
+	// Grants are the privileges that are granted to the user in upper case. "ALL" and "ALL PRIVILEGES" are also in
+	// the privileges if all of them are granted.
+	Grants []Grant
+}
+
+// DatabaseCreator is implemented by dialects that can create the database itself.
+type DatabaseCreator interface {
+	// CreateDatabase creates the database named name if it does not exist, and reports whether it is created.
+	CreateDatabase(name string, opts DatabaseOptions) (bool, error)
+}
+
+// DatabaseAlterer is implemented by dialects that can alter the settings of the database of the dialect.
+type DatabaseAlterer interface {
+	// DatabaseOptions returns the current settings of the database. The settings that the dialect does not have are
+	// empty, that is, Encryption is nil and Options is nil unless the dialect has them.
+	DatabaseOptions() (DatabaseOptions, error)
+
+	// AlterDatabaseSQL returns the SQLs to change the settings of the database to the non-empty ones of opts.
+	AlterDatabaseSQL(opts DatabaseOptions) []string
+}
+
+// TableRenamer is implemented by dialects that can rename the tables.
+type TableRenamer interface {
+	RenameTableSQL(oldName, newName string) []string
+}
+
+// ColumnRenamer is implemented by dialects that can rename the columns.
+// newField has the new name and the definition of the column after renaming.
+type ColumnRenamer interface {
+	RenameColumnSQL(oldField, newField Field) []string
+}
+
+// Features of the introspection that can be degraded.
+const (
+	FeatureIndexes          = "indexes"
+	FeatureEncryption       = "encryption"
+	FeatureCompression      = "compression"
+	FeatureCheckConstraints = "check_constraints"
+)
+
+// Degradation represents the feature of the introspection that is skipped because the connecting user lacks the
+// privileges to read it.
+type Degradation struct {
+	Feature string
+	Err     error
+}
+
+// DegradationReporter is implemented by dialects that continue the introspection without the features that cannot
+// be read because of the lack of the privileges.
+type DegradationReporter interface {
+	// Degradations returns the features that have been skipped by the introspection so far.
+	Degradations() []Degradation
+}
+
+// ErrorKeeper is implemented by dialects whose methods that cannot return an error can fail, such as the plugin. The
+// SQLs that are generated after the failure are incomplete, so the caller must check the error after generating them.
+type ErrorKeeper interface {
+	// Err returns the first error of the methods that cannot return an error, or nil.
+	Err() error
+}
+
+// PrivilegeReader is implemented by dialects that can read the privileges of the connecting user on the database.
+type PrivilegeReader interface {
+	// Privileges returns the names of the privileges in upper case. (e.g. "ALTER")
+	Privileges() ([]string, error)
+}
+
+// ServerVersioner is implemented by dialects that can report the version of the database server.
+type ServerVersioner interface {
+	// ServerVersion returns the version of the server, and reports whether the version is supported by the dialect.
+	ServerVersion() (version string, supported bool, err error)
+}
+
+// LockBlockerReader is implemented by dialects that can find the sessions that may block the DDL.
+type LockBlockerReader interface {
+	// LockBlockers returns the sessions that have the open transactions. They hold the metadata locks of the tables,
+	// and the DDL on the tables waits for them.
+	LockBlockers() ([]LockBlocker, error)
+}
+
+// LockBlocker represents a session that may block the DDL.
+type LockBlocker struct {
+	// Session is the identifier of the session. (e.g. the connection ID on MySQL)
+	Session string
+
+	// Duration is the elapsed time since the transaction started.
+	Duration time.Duration
+
+	// Query is the statement that is being executed by the session, or empty if it is idle.
+	Query string
+}
+
+// RowSizeLimiter is implemented by dialects that limit the size of a row.
+type RowSizeLimiter interface {
+	RowSizeLimit(table Table) RowSizeLimit
+}
+
+// RowSizeLimit represents the limits of the size of a row of the table.
+type RowSizeLimit struct {
+	// CharWidth is the maximum number of bytes of a character in the character set of the table.
+	CharWidth int
+
+	// MaxRowSize is the maximum size of a row in bytes. The large objects such as TEXT are counted as the pointers.
+	MaxRowSize int64
+
+	// MaxInlineRowSize is the maximum size in bytes of the part of a row that is stored in the page, or 0 if there is
+	// no such limit.
+	MaxInlineRowSize int64
+
+	// MaxInlineColumnSize is the size in bytes of the part of a long variable-length column that is stored in the
+	// page when the column is stored off-page.
+	MaxInlineColumnSize int64
+
+	// MaxIndexKeySize is the maximum size in bytes of the key of an index, or 0 if there is no such limit.
+	MaxIndexKeySize int64
+}
+
+// SchemaLimiter is implemented by dialects that limit the names and the numbers of the objects of a table, so that
+// the tables are validated before the server rejects the DDL.
+type SchemaLimiter interface {
+	SchemaLimits(table Table) SchemaLimits
+}
+
+// SchemaLimits represents the limits of the objects of a table. The limits that are 0 are not checked.
+type SchemaLimits struct {
+	// MaxIdentifierLength is the maximum length of the names of the table, the columns, the indexes and the
+	// constraints.
+	MaxIdentifierLength int
+
+	// IdentifierLengthInBytes reports whether MaxIdentifierLength is counted in bytes instead of characters.
+	IdentifierLengthInBytes bool
+
+	// MaxColumns is the maximum number of the columns of the table.
+	MaxColumns int
+
+	// MaxIndexes is the maximum number of the indexes of the table except the primary key.
+	MaxIndexes int
+
+	// MaxIndexColumns is the maximum number of the columns of an index.
+	MaxIndexColumns int
+
+	// MaxEnumElements and MaxSetElements are the maximum numbers of the elements of ENUM and SET types.
+	MaxEnumElements int
+	MaxSetElements  int
+}
+
+// EnumTyper is implemented by dialects that support ENUM and SET column types.
+type EnumTyper interface {
+	// EnumColumnType returns the column type of ENUM, or SET if set is true, that consists of the elements.
+	EnumColumnType(elements []string, set bool) string
+}
+
+// SpatialTypes is the spatial column types of OpenGIS. GEOMCOLLECTION is the synonym of GEOMETRYCOLLECTION as of
+// MySQL 8.0.11.
+var SpatialTypes = map[string]bool{
+	"GEOMETRY":           true,
+	"POINT":              true,
+	"LINESTRING":         true,
+	"POLYGON":            true,
+	"MULTIPOINT":         true,
+	"MULTILINESTRING":    true,
+	"MULTIPOLYGON":       true,
+	"GEOMETRYCOLLECTION": true,
+	"GEOMCOLLECTION":     true,
+}
+
+// SpatialTyper is implemented by dialects that support the spatial reference systems of the spatial columns.
+type SpatialTyper interface {
+	// SpatialColumnType returns the column type of the spatial type typ such as POINT whose values are restricted to
+	// the spatial reference system srid. It returns false if typ is not a spatial type.
+	SpatialColumnType(typ string, srid uint32) (string, bool)
+}
+
+// OnUpdater is implemented by dialects that can set the columns to the expression whenever the rows are updated.
+type OnUpdater interface {
+	// OnUpdateClause returns the clause of the column definition that sets the column to expr such as
+	// CURRENT_TIMESTAMP whenever the row is updated.
+	OnUpdateClause(expr string) string
+}
+
+// CharsetConverter is implemented by dialects that can convert the character set of the tables.
+type CharsetConverter interface {
+	// TableCharsets returns the character sets of the tables and their columns.
+	TableCharsets(tables ...string) ([]TableCharset, error)
+
+	// ConvertCharsetSQL returns SQLs to convert the table and all its columns to the character set.
+	// The collation may be empty to use the default collation of the character set.
+	ConvertCharsetSQL(table, charset, collation string) []string
+
+	// CharWidth returns the maximum number of bytes of a character of the character set, or 0 if it is unknown.
+	CharWidth(charset string) int
+
+	// MaxIndexKeySize returns the maximum size in bytes of the key of an index of the table with the row format.
+	MaxIndexKeySize(rowFormat string) int64
+}
+
+// TableCharset represents the character sets of a table.
+type TableCharset struct {
+	Table     string
+	Charset   string
+	RowFormat string
+
+	// Columns are the character sets of the string columns of the table by the column names.
+	Columns map[string]string
+}
+
+// TableAnalyzer is implemented by dialects that can update the statistics of the tables for the optimizer.
+type TableAnalyzer interface {
+	AnalyzeTableSQL(table string) []string
+}
+
 type Table struct {
 	Name        string
 	Fields      []Field
 	PrimaryKeys []string
 	Option      string
+
+	// Encrypted reports whether the table is encrypted at rest. It is used only if the dialect implements
+	// TableEncrypter.
+	Encrypted bool
+
+	// Persistence is PersistenceTemporary, PersistenceUnlogged, or empty for the normal table. It is used only if
+	// the dialect implements TablePersister.
+	Persistence string
+
+	// Compression is the compression algorithm of the pages of the table such as "zlib", or empty if it is
+	// unmanaged. It is used only if the dialect implements TableCompressor.
+	Compression string
 }
 
 type Field struct {
@@ -59,6 +573,19 @@ type Field struct {
 	Default       string
 	Extra         string
 	Nullable      bool
+
+	// Compressed reports whether the values of the column are compressed. It is used only by the dialects that
+	// support the compressed columns.
+	Compressed bool
+
+	// Charset and Collation are the character set and the collation of the string column, or empty if they are the
+	// defaults of the table. They are used only by the dialects that implement CharsetConverter.
+	Charset   string
+	Collation string
+
+	// OnUpdate is the expression such as CURRENT_TIMESTAMP that the column is set to whenever the row is updated, or
+	// empty. It is used only by the dialects that implement OnUpdater.
+	OnUpdate string
 }
 
 type Index struct {
@@ -68,11 +595,145 @@ type Index struct {
 	Unique  bool
 }
 
+// ForeignKey represents the foreign key constraint of the columns that reference the columns of RefTable.
+type ForeignKey struct {
+	Table      string
+	Name       string
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+}
+
+// CheckConstraint represents the CHECK constraint of the table. Expression is the boolean expression of the
+// constraint without the surrounding parentheses.
+type CheckConstraint struct {
+	Table      string
+	Name       string
+	Expression string
+}
+
+// DatabaseOptions is the options to create the database, which are also the settings of the database that are
+// altered by DatabaseAlterer.
+type DatabaseOptions struct {
+	// Charset and Collation are the default character set and collation of the database. They are ignored by the
+	// dialects that have no such options.
+	Charset   string `yaml:"charset"`
+	Collation string `yaml:"collation"`
+
+	// Encryption reports whether the tables of the database are encrypted at rest by default, or nil if it is not
+	// specified. It is ignored by the dialects that have no such option.
+	Encryption *bool `yaml:"encryption"`
+
+	// Options are the options of the database such as version_retention_period of Cloud Spanner by the names. They
+	// are ignored by the dialects that have no such options.
+	Options map[string]string `yaml:"options"`
+}
+
+// User is the database user or the role that is needed by the application.
+type User struct {
+	Name string `yaml:"name"`
+
+	// Host is the host that the user connects from. It is ignored for roles.
+	Host string `yaml:"host"`
+
+	Password string `yaml:"password"`
+
+	// Role reports whether the user is a role.
+	Role bool `yaml:"role"`
+
+	// Roles are the roles that are granted to the user.
+	Roles []string `yaml:"roles"`
+
+	Grants []Grant `yaml:"grants"`
+}
+
+// Grant is the privileges on the database objects.
+type Grant struct {
+	Privileges []string `yaml:"privileges"`
+
+	// On is the table name, or "*" for all tables in the database.
+	On string `yaml:"on"`
+}
+
 type ColumnType struct {
 	Types           []string `yaml:"types"`
 	GoTypes         []string `yaml:"goTypes"`
 	GoNullableTypes []string `yaml:"goNullableTypes"`
 	GoUnsignedTypes []string `yaml:"goUnsignedTypes"`
+
+	// Imports are the import paths of the packages of the Go types such as "github.com/google/uuid". The path may be
+	// preceded by the name of the import and a space such as "dec github.com/shopspring/decimal" if the Go types are
+	// qualified by the name other than the one of the package.
+	Imports []string `yaml:"imports"`
+}
+
+// GoTypeImporter is implemented by dialects that know the packages of the Go types of the custom column types.
+type GoTypeImporter interface {
+	// GoTypeImports returns the imports of the packages of the Go types of the custom column types.
+	GoTypeImports() ([]Import, error)
+}
+
+// Import is the import of the package of the Go types.
+type Import struct {
+	// Name is the name of the import, or empty if the package is referred by its own name.
+	Name string
+	Path string
+}
+
+// PackageName returns the name that the Go types of the package are qualified by.
+func (i Import) PackageName() string {
+	if i.Name != "" {
+		return i.Name
+	}
+	return ImportName(i.Path)
+}
+
+// ImportName returns the name of the package of the import path in the same way as goimports guesses it. The major
+// version suffixes such as "/v2" and ".v3", and "go-" prefix and "-go" suffix of the last element are removed.
+func ImportName(path string) string {
+	elems := strings.Split(path, "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && isMajorVersion(name) {
+		name = elems[len(elems)-2]
+	}
+	name = strings.TrimSuffix(strings.TrimPrefix(name, "go-"), "-go")
+	for i, r := range name {
+		if !(unicode.IsLetter(r) || r == '_' || (i > 0 && unicode.IsDigit(r))) {
+			return name[:i]
+		}
+	}
+	return name
+}
+
+func isMajorVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	_, err := strconv.ParseUint(s[1:], 10, 64)
+	return err == nil
+}
+
+// goTypeImports returns the imports of the custom column types.
+func goTypeImports(columnTypes []*ColumnType) ([]Import, error) {
+	var imports []Import
+	for _, t := range columnTypes {
+		for _, s := range t.Imports {
+			var imp Import
+			switch fields := strings.Fields(s); len(fields) {
+			case 1:
+				imp.Path = fields[0]
+			case 2:
+				imp.Name, imp.Path = fields[0], fields[1]
+			default:
+				return nil, fmt.Errorf("invalid import of column type %v: %q (must be PATH or NAME PATH)", t.Types, s)
+			}
+			if imp.PackageName() == "" {
+				return nil, fmt.Errorf("invalid import of column type %v: %q", t.Types, s)
+			}
+			imports = append(imports, imp)
+		}
+	}
+	return imports, nil
 }
 
 func (c *ColumnType) findType(t string) (name string, nullable, unsigned, found bool) {
@@ -128,12 +789,21 @@ func (c *ColumnType) allGoTypes() []string {
 	return append(append(append(ret, c.GoTypes...), c.GoNullableTypes...), c.GoUnsignedTypes...)
 }
 
+// filteredNullableGoTypes returns the nullable Go types that make the columns nullable by themselves, except the
+// pointers, the slices and the types that are also GoTypes such as json.RawMessage.
 func (c *ColumnType) filteredNullableGoTypes() []string {
 	ret := make([]string, 0, len(c.GoNullableTypes))
+nullable:
 	for _, t := range c.GoNullableTypes {
-		if c := t[0]; c != '*' && c != '[' {
-			ret = append(ret, t)
+		if c := t[0]; c == '*' || c == '[' {
+			continue
+		}
+		for _, tt := range c.GoTypes {
+			if t == tt {
+				continue nullable
+			}
 		}
+		ret = append(ret, t)
 	}
 	return ret
 }
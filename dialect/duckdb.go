@@ -0,0 +1,693 @@
+package dialect
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+var (
+	_ PrimaryKeyModifier = &DuckDB{}
+	_ TableRenamer       = &DuckDB{}
+	_ ColumnRenamer      = &DuckDB{}
+	_ TableAnalyzer      = &DuckDB{}
+	_ TablePersister     = &DuckDB{}
+)
+
+var (
+	duckdbColumnTypes = []*ColumnType{
+		{
+			Types:           []string{"VARCHAR"},
+			GoTypes:         []string{"string"},
+			GoNullableTypes: []string{"*string", "sql.NullString"},
+		},
+		{
+			Types:           []string{"BLOB"},
+			GoTypes:         []string{"[]byte"},
+			GoNullableTypes: []string{"[]byte"},
+		},
+		{
+			Types:           []string{"INTEGER"},
+			GoTypes:         []string{"int32", "int"},
+			GoNullableTypes: []string{"*int32", "sql.NullInt32"},
+		},
+		{
+			Types:           []string{"SMALLINT"},
+			GoTypes:         []string{"int16"},
+			GoNullableTypes: []string{"*int16", "sql.NullInt16"},
+		},
+		{
+			Types:           []string{"TINYINT"},
+			GoTypes:         []string{"int8"},
+			GoNullableTypes: []string{"*int8"},
+		},
+		{
+			Types:           []string{"BIGINT"},
+			GoTypes:         []string{"int64"},
+			GoNullableTypes: []string{"*int64", "sql.NullInt64"},
+		},
+		{
+			Types:           []string{"UINTEGER"},
+			GoTypes:         []string{"uint32"},
+			GoNullableTypes: []string{"*uint32"},
+		},
+		{
+			Types:           []string{"USMALLINT"},
+			GoTypes:         []string{"uint16"},
+			GoNullableTypes: []string{"*uint16"},
+		},
+		{
+			Types:           []string{"UTINYINT"},
+			GoTypes:         []string{"uint8"},
+			GoNullableTypes: []string{"*uint8"},
+		},
+		{
+			Types:           []string{"UBIGINT"},
+			GoTypes:         []string{"uint64", "uint"},
+			GoNullableTypes: []string{"*uint64"},
+		},
+		{
+			Types:           []string{"BOOLEAN"},
+			GoTypes:         []string{"bool"},
+			GoNullableTypes: []string{"*bool", "sql.NullBool"},
+		},
+		{
+			Types:           []string{"DOUBLE", "DECIMAL"},
+			GoTypes:         []string{"float64"},
+			GoNullableTypes: []string{"*float64", "sql.NullFloat64"},
+		},
+		{
+			Types:           []string{"FLOAT"},
+			GoTypes:         []string{"float32"},
+			GoNullableTypes: []string{"*float32"},
+		},
+		{
+			Types:           []string{"TIMESTAMP WITH TIME ZONE", "TIMESTAMP", "DATE"},
+			GoTypes:         []string{"time.Time"},
+			GoNullableTypes: []string{"*time.Time", "sql.NullTime"},
+		},
+	}
+
+	// duckdbTypeAliases are the canonical names of the types that are returned by duckdb_columns() of DuckDB.
+	duckdbTypeAliases = map[string]string{
+		"TEXT":        "VARCHAR",
+		"STRING":      "VARCHAR",
+		"CHAR":        "VARCHAR",
+		"BPCHAR":      "VARCHAR",
+		"BYTEA":       "BLOB",
+		"BINARY":      "BLOB",
+		"VARBINARY":   "BLOB",
+		"INT":         "INTEGER",
+		"INT4":        "INTEGER",
+		"SIGNED":      "INTEGER",
+		"INT2":        "SMALLINT",
+		"SHORT":       "SMALLINT",
+		"INT1":        "TINYINT",
+		"INT8":        "BIGINT",
+		"LONG":        "BIGINT",
+		"BOOL":        "BOOLEAN",
+		"LOGICAL":     "BOOLEAN",
+		"FLOAT8":      "DOUBLE",
+		"FLOAT4":      "FLOAT",
+		"REAL":        "FLOAT",
+		"NUMERIC":     "DECIMAL",
+		"DATETIME":    "TIMESTAMP",
+		"TIMESTAMPTZ": "TIMESTAMP WITH TIME ZONE",
+	}
+)
+
+// DuckDB is the dialect of DuckDB.
+// The tables are in the schema that is specified by WithSchema, or the current schema of the connection.
+type DuckDB struct {
+	db              *sql.DB
+	opt             *option
+	schemaName      string
+	columnTypeMap   map[string]*ColumnType
+	nullableTypeMap map[string]struct{}
+}
+
+// NewDuckDB returns a new dialect of DuckDB. db must be opened by the driver of DuckDB such as go-duckdb.
+func NewDuckDB(db *sql.DB, opts ...Option) Dialect {
+	d := &DuckDB{
+		db:              db,
+		opt:             newOption(),
+		columnTypeMap:   map[string]*ColumnType{},
+		nullableTypeMap: map[string]struct{}{},
+	}
+	for _, o := range opts {
+		o(d.opt)
+	}
+	for _, types := range [][]*ColumnType{duckdbColumnTypes, d.opt.columnTypes} {
+		for _, t := range types {
+			for _, tt := range t.allGoTypes() {
+				d.columnTypeMap[tt] = t
+			}
+			for _, tt := range t.filteredNullableGoTypes() {
+				d.nullableTypeMap[tt] = struct{}{}
+			}
+		}
+	}
+	return d
+}
+
+func (d *DuckDB) ColumnSchema(tables ...string) ([]ColumnSchema, error) {
+	schema, err := d.currentSchema()
+	if err != nil {
+		return nil, err
+	}
+	primaryKeys, indexMap, err := d.getIndexMap(schema)
+	if err != nil {
+		return nil, err
+	}
+	parts := []string{
+		"SELECT",
+		"  c.table_name,",
+		"  c.column_name,",
+		"  c.column_default,",
+		"  c.is_nullable,",
+		"  c.data_type,",
+		"  COALESCE(c.comment, '')",
+		"FROM duckdb_columns() c",
+		"JOIN duckdb_tables() t ON t.database_name = c.database_name AND t.schema_name = c.schema_name AND t.table_name = c.table_name",
+		"WHERE c.database_name = current_database() AND c.schema_name = ? AND NOT t.temporary",
+	}
+	args := []interface{}{schema}
+	if len(tables) > 0 {
+		parts = append(parts, fmt.Sprintf("AND c.table_name IN (%s)", strings.TrimSuffix(strings.Repeat("?,", len(tables)), ",")))
+		for _, t := range tables {
+			args = append(args, t)
+		}
+	}
+	parts = append(parts, "ORDER BY c.table_name, c.column_index")
+	rows, err := d.db.Query(strings.Join(parts, "\n"), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var schemas []ColumnSchema
+	for rows.Next() {
+		schema := &duckdbColumnSchema{}
+		if err := rows.Scan(
+			&schema.tableName,
+			&schema.columnName,
+			&schema.columnDefault,
+			&schema.isNullable,
+			&schema.dataType,
+			&schema.comment,
+		); err != nil {
+			return nil, err
+		}
+		schema.primaryKey = primaryKeys[schema.tableName][schema.columnName]
+		if info, exists := indexMap[schema.tableName][schema.columnName]; exists {
+			schema.indexName = info.name
+			schema.unique = info.unique
+		}
+		schemas = append(schemas, schema)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return schemas, nil
+}
+
+func (d *DuckDB) ColumnType(name string) string {
+	if t, ok := d.columnTypeMap[name]; ok {
+		name, _, _, _ = t.findType(name)
+	}
+	if strings.HasPrefix(name, "[]") {
+		return d.ColumnType(name[2:]) + "[]"
+	}
+	return duckdbCanonicalType(name)
+}
+
+func (d *DuckDB) GoType(name string, nullable bool) string {
+	return d.goType(duckdbCanonicalType(name), nullable)
+}
+
+func (d *DuckDB) goType(name string, nullable bool) string {
+	if strings.HasSuffix(name, "[]") {
+		return "[]" + d.goType(strings.TrimSuffix(name, "[]"), false)
+	}
+	for _, types := range [][]*ColumnType{d.opt.columnTypes, duckdbColumnTypes} {
+		for _, t := range types {
+			if typ, found := t.findGoType(name, nullable, false); found {
+				return typ
+			}
+		}
+	}
+	if strings.IndexByte(name, '(') >= 0 {
+		return d.goType(trimParens(name), nullable)
+	}
+	return "interface{}"
+}
+
+func (d *DuckDB) IsNullable(name string) bool {
+	_, ok := d.nullableTypeMap[name]
+	return ok
+}
+
+func (d *DuckDB) ImportPackage(schema ColumnSchema) string {
+	switch typ := schema.ColumnType(); {
+	case strings.HasPrefix(typ, "TIMESTAMP"), strings.HasPrefix(typ, "DATE"):
+		return "time"
+	}
+	return ""
+}
+
+func (d *DuckDB) GoTypeImports() ([]Import, error) {
+	return goTypeImports(d.opt.columnTypes)
+}
+
+func (d *DuckDB) Quote(s string) string {
+	return quoteByDoubling(s, `"`, false)
+}
+
+func (d *DuckDB) QuoteString(s string) string {
+	return quoteByDoubling(s, "'", false)
+}
+
+func (d *DuckDB) CreateTableSQL(table Table) []string {
+	var sqls []string
+	columns := make([]string, len(table.Fields))
+	for i, f := range table.Fields {
+		if f.AutoIncrement {
+			sqls = append(sqls, d.createSequenceSQL(f))
+		}
+		columns[i] = d.columnSQL(f)
+	}
+	if len(table.PrimaryKeys) > 0 {
+		pkColumns := make([]string, len(table.PrimaryKeys))
+		for i, pk := range table.PrimaryKeys {
+			pkColumns[i] = d.Quote(pk)
+		}
+		columns = append(columns, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(pkColumns, ", ")))
+	}
+	create, tableName := "CREATE TABLE", d.table(table.Name)
+	if table.Persistence == PersistenceTemporary {
+		// The temporary table is created in the temporary schema regardless of WithSchema.
+		create, tableName = "CREATE TEMPORARY TABLE", d.Quote(table.Name)
+	}
+	query := fmt.Sprintf("%s %s (\n"+
+		"  %s\n"+
+		")", create, tableName, strings.Join(columns, ",\n  "))
+	if table.Option != "" {
+		query += " " + table.Option
+	}
+	sqls = append(sqls, query)
+	for _, f := range table.Fields {
+		if f.Comment != "" {
+			sqls = append(sqls, d.columnCommentSQL(tableName, f))
+		}
+	}
+	return sqls
+}
+
+func (d *DuckDB) AddColumnSQL(field Field) []string {
+	var sqls []string
+	if field.AutoIncrement {
+		sqls = append(sqls, d.createSequenceSQL(field))
+	}
+	sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", d.table(field.Table), d.columnSQL(field)))
+	if field.Comment != "" {
+		sqls = append(sqls, d.commentSQL(field))
+	}
+	return sqls
+}
+
+func (d *DuckDB) DropColumnSQL(field Field) []string {
+	return []string{fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", d.table(field.Table), d.Quote(field.Name))}
+}
+
+// ModifyColumnSQL returns SQLs to modify the column. DuckDB allows only one change in an ALTER TABLE statement, so
+// that each change is a separate statement.
+func (d *DuckDB) ModifyColumnSQL(oldField, newField Field) []string {
+	var sqls []string
+	tableName := d.table(newField.Table)
+	if oldField.Name != newField.Name {
+		sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", tableName, d.Quote(oldField.Name), d.Quote(newField.Name)))
+	}
+	column := d.Quote(newField.Name)
+	if oldField.Type != newField.Type {
+		sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s", tableName, column, newField.Type))
+	}
+	if oldField.Nullable != newField.Nullable {
+		if newField.Nullable {
+			sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s DROP NOT NULL", tableName, column))
+		} else {
+			sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET NOT NULL", tableName, column))
+		}
+	}
+	switch {
+	case !oldField.AutoIncrement && newField.AutoIncrement:
+		sqls = append(sqls,
+			d.createSequenceSQL(newField),
+			fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET DEFAULT %s", tableName, column, d.nextvalSQL(newField)),
+		)
+	case oldField.Default != newField.Default || (oldField.AutoIncrement && !newField.AutoIncrement):
+		if newField.Default == "" {
+			sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s DROP DEFAULT", tableName, column))
+		} else {
+			sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET DEFAULT %s", tableName, column, d.defaultSQL(newField)))
+		}
+	}
+	if oldField.Comment != newField.Comment {
+		sqls = append(sqls, d.commentSQL(newField))
+	}
+	return sqls
+}
+
+func (d *DuckDB) RenameColumnSQL(oldField, newField Field) []string {
+	return d.ModifyColumnSQL(oldField, newField)
+}
+
+func (d *DuckDB) ModifyPrimaryKeySQL(oldPrimaryKeys, newPrimaryKeys []Field) []string {
+	var tableName string
+	if len(newPrimaryKeys) > 0 {
+		tableName = newPrimaryKeys[0].Table
+	} else {
+		tableName = oldPrimaryKeys[0].Table
+	}
+	return []string{fmt.Sprintf("-- primary key of table %s cannot be modified by ALTER TABLE", d.table(tableName))}
+}
+
+func (d *DuckDB) RenameTableSQL(oldName, newName string) []string {
+	return []string{fmt.Sprintf("ALTER TABLE %s RENAME TO %s", d.table(oldName), d.Quote(newName))}
+}
+
+func (d *DuckDB) AnalyzeTableSQL(table string) []string {
+	return []string{fmt.Sprintf("ANALYZE %s", d.table(table))}
+}
+
+func (d *DuckDB) CreateIndexSQL(index Index) []string {
+	columns := make([]string, len(index.Columns))
+	for i, c := range index.Columns {
+		columns[i] = d.Quote(c)
+	}
+	indexName := d.Quote(index.Name)
+	tableName := d.table(index.Table)
+	column := strings.Join(columns, ",")
+	if index.Unique {
+		return []string{fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (%s)", indexName, tableName, column)}
+	}
+	return []string{fmt.Sprintf("CREATE INDEX %s ON %s (%s)", indexName, tableName, column)}
+}
+
+func (d *DuckDB) DropIndexSQL(index Index) []string {
+	// The index belongs to the schema of the table.
+	return []string{fmt.Sprintf("DROP INDEX %s", d.table(index.Name))}
+}
+
+func (d *DuckDB) Begin() (Transactioner, error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &duckdbTransaction{
+		tx: tx,
+	}, nil
+}
+
+// SupportsPersistence reports whether the persistence is PersistenceTemporary, because DuckDB does not write the
+// write-ahead log per table.
+func (d *DuckDB) SupportsPersistence(persistence string) bool {
+	return persistence == PersistenceTemporary
+}
+
+// table returns the quoted name of the table that is qualified by the schema if WithSchema is specified.
+func (d *DuckDB) table(name string) string {
+	if d.opt.schema == "" {
+		return d.Quote(name)
+	}
+	return d.Quote(d.opt.schema) + "." + d.Quote(name)
+}
+
+func (d *DuckDB) currentSchema() (string, error) {
+	if d.opt.schema != "" {
+		return d.opt.schema, nil
+	}
+	if d.schemaName != "" {
+		return d.schemaName, nil
+	}
+	if err := d.db.QueryRow(`SELECT current_schema()`).Scan(&d.schemaName); err != nil {
+		return "", err
+	}
+	return d.schemaName, nil
+}
+
+// getIndexMap returns the primary key columns and the indexes of the columns except the primary keys of the tables.
+func (d *DuckDB) getIndexMap(schema string) (primaryKeys map[string]map[string]bool, indexMap map[string]map[string]postgresIndexInfo, err error) {
+	primaryKeys = make(map[string]map[string]bool)
+	rows, err := d.db.Query(strings.Join([]string{
+		"SELECT table_name, unnest(constraint_column_names)",
+		"FROM duckdb_constraints()",
+		"WHERE database_name = current_database() AND schema_name = ? AND constraint_type = 'PRIMARY KEY'",
+	}, "\n"), schema)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tableName, columnName string
+		if err := rows.Scan(&tableName, &columnName); err != nil {
+			return nil, nil, err
+		}
+		if _, exists := primaryKeys[tableName]; !exists {
+			primaryKeys[tableName] = make(map[string]bool)
+		}
+		primaryKeys[tableName][columnName] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	indexMap = make(map[string]map[string]postgresIndexInfo)
+	// The columns of the index are read from the SQL that created it because duckdb_indexes() does not have them.
+	irows, err := d.db.Query(strings.Join([]string{
+		"SELECT table_name, index_name, is_unique, sql",
+		"FROM duckdb_indexes()",
+		"WHERE database_name = current_database() AND schema_name = ? AND NOT is_primary",
+	}, "\n"), schema)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer irows.Close()
+	for irows.Next() {
+		var (
+			tableName string
+			index     postgresIndexInfo
+			query     sql.NullString
+		)
+		if err := irows.Scan(&tableName, &index.name, &index.unique, &query); err != nil {
+			return nil, nil, err
+		}
+		for _, columnName := range duckdbIndexColumns(query.String) {
+			if _, exists := indexMap[tableName]; !exists {
+				indexMap[tableName] = make(map[string]postgresIndexInfo)
+			}
+			indexMap[tableName][columnName] = index
+		}
+	}
+	return primaryKeys, indexMap, irows.Err()
+}
+
+func (d *DuckDB) columnSQL(f Field) string {
+	column := []string{d.Quote(f.Name), f.Type}
+	if !f.Nullable {
+		column = append(column, "NOT NULL")
+	}
+	switch {
+	case f.AutoIncrement:
+		column = append(column, "DEFAULT", d.nextvalSQL(f))
+	case f.Default != "":
+		column = append(column, "DEFAULT", d.defaultSQL(f))
+	}
+	if f.Extra != "" {
+		column = append(column, f.Extra)
+	}
+	return strings.Join(column, " ")
+}
+
+func (d *DuckDB) defaultSQL(f Field) string {
+	if strings.HasPrefix(strings.ToUpper(f.Type), "VARCHAR") {
+		return d.QuoteString(f.Default)
+	}
+	return f.Default
+}
+
+// sequence returns the quoted name of the sequence of the auto-increment column, which is TABLE_COLUMN_seq in the
+// same way as the serial columns of PostgreSQL.
+func (d *DuckDB) sequence(f Field) string {
+	return d.table(f.Table + "_" + f.Name + "_seq")
+}
+
+func (d *DuckDB) createSequenceSQL(f Field) string {
+	return fmt.Sprintf("CREATE SEQUENCE IF NOT EXISTS %s", d.sequence(f))
+}
+
+func (d *DuckDB) nextvalSQL(f Field) string {
+	return fmt.Sprintf("nextval(%s)", d.QuoteString(d.sequence(f)))
+}
+
+func (d *DuckDB) commentSQL(f Field) string {
+	return d.columnCommentSQL(d.table(f.Table), f)
+}
+
+func (d *DuckDB) columnCommentSQL(tableName string, f Field) string {
+	comment := "NULL"
+	if f.Comment != "" {
+		comment = d.QuoteString(f.Comment)
+	}
+	return fmt.Sprintf("COMMENT ON COLUMN %s.%s IS %s", tableName, d.Quote(f.Name), comment)
+}
+
+// duckdbCanonicalType returns the name of the type in the same form as duckdb_columns() of DuckDB in upper case.
+// (e.g. "varchar(255)" to "VARCHAR", "int4[]" to "INTEGER[]", "numeric(10)" to "DECIMAL(10,0)")
+func duckdbCanonicalType(name string) string {
+	name = strings.ToUpper(strings.Join(strings.Fields(name), " "))
+	var array string
+	for strings.HasSuffix(name, "[]") {
+		name, array = strings.TrimSpace(strings.TrimSuffix(name, "[]")), array+"[]"
+	}
+	base, params := name, ""
+	if i := strings.IndexByte(name, '('); i >= 0 {
+		if j := strings.IndexByte(name[i:], ')'); j >= 0 {
+			base, params = strings.TrimSpace(name[:i]), strings.Replace(name[i+1:i+j], " ", "", -1)
+		}
+	}
+	if alias, ok := duckdbTypeAliases[base]; ok {
+		base = alias
+	}
+	switch base {
+	case "VARCHAR":
+		// The length of VARCHAR is not enforced and not stored by DuckDB.
+		params = ""
+	case "DECIMAL":
+		switch {
+		case params == "":
+			params = "18,3"
+		case !strings.Contains(params, ","):
+			params += ",0"
+		}
+	}
+	if params != "" {
+		base += "(" + params + ")"
+	}
+	return base + array
+}
+
+// duckdbIndexColumns returns the names of the columns of the index from the CREATE INDEX statement.
+func duckdbIndexColumns(query string) []string {
+	upper := strings.ToUpper(query)
+	on := strings.Index(upper, " ON ")
+	if on < 0 {
+		return nil
+	}
+	start := strings.IndexByte(query[on:], '(')
+	end := strings.LastIndexByte(query, ')')
+	if start < 0 || on+start >= end {
+		return nil
+	}
+	var columns []string
+	for _, c := range strings.Split(query[on+start+1:end], ",") {
+		c = strings.TrimSpace(c)
+		if len(c) >= 2 && c[0] == '"' && c[len(c)-1] == '"' {
+			c = strings.Replace(c[1:len(c)-1], `""`, `"`, -1)
+		}
+		columns = append(columns, c)
+	}
+	return columns
+}
+
+type duckdbTransaction struct {
+	tx *sql.Tx
+}
+
+func (t *duckdbTransaction) Exec(sql string, args ...interface{}) error {
+	_, err := t.tx.Exec(sql, args...)
+	return err
+}
+
+func (t *duckdbTransaction) Commit() error {
+	return t.tx.Commit()
+}
+
+func (t *duckdbTransaction) Rollback() error {
+	return t.tx.Rollback()
+}
+
+var _ ColumnSchema = &duckdbColumnSchema{}
+
+type duckdbColumnSchema struct {
+	tableName     string
+	columnName    string
+	columnDefault sql.NullString
+	isNullable    bool
+	dataType      string
+	comment       string
+	primaryKey    bool
+	indexName     string
+	unique        bool
+}
+
+func (schema *duckdbColumnSchema) TableName() string {
+	return schema.tableName
+}
+
+func (schema *duckdbColumnSchema) ColumnName() string {
+	return schema.columnName
+}
+
+func (schema *duckdbColumnSchema) ColumnType() string {
+	return duckdbCanonicalType(schema.dataType)
+}
+
+func (schema *duckdbColumnSchema) DataType() string {
+	return schema.dataType
+}
+
+func (schema *duckdbColumnSchema) IsPrimaryKey() bool {
+	return schema.primaryKey
+}
+
+// IsAutoIncrement reports whether the default value of the column is the next value of the sequence.
+func (schema *duckdbColumnSchema) IsAutoIncrement() bool {
+	return strings.HasPrefix(schema.columnDefault.String, "nextval(")
+}
+
+func (schema *duckdbColumnSchema) Index() (name string, unique bool, ok bool) {
+	if schema.indexName != "" {
+		return schema.indexName, schema.unique, true
+	}
+	return "", false, false
+}
+
+func (schema *duckdbColumnSchema) Default() (string, bool) {
+	if !schema.columnDefault.Valid || schema.IsAutoIncrement() {
+		return "", false
+	}
+	def := schema.columnDefault.String
+	// Trim the type cast from like "CAST('x' AS VARCHAR)".
+	if strings.HasPrefix(def, "CAST(") && strings.HasSuffix(def, ")") {
+		if i := strings.LastIndex(def, " AS "); i >= 0 {
+			def = def[len("CAST("):i]
+		}
+	}
+	if def == "NULL" {
+		return "", false
+	}
+	if len(def) >= 2 && def[0] == '\'' && def[len(def)-1] == '\'' {
+		def = strings.Replace(def[1:len(def)-1], "''", "'", -1) // unescape string
+	}
+	return def, true
+}
+
+func (schema *duckdbColumnSchema) IsNullable() bool {
+	return schema.isNullable
+}
+
+func (schema *duckdbColumnSchema) Extra() (string, bool) {
+	return "", false
+}
+
+func (schema *duckdbColumnSchema) Comment() (string, bool) {
+	return schema.comment, schema.comment != ""
+}
@@ -0,0 +1,117 @@
+package dialect_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/naoina/migu/dialect"
+)
+
+func TestDuckDBColumnType(t *testing.T) {
+	d := dialect.NewDuckDB(nil)
+	for _, v := range []struct {
+		name   string
+		expect string
+	}{
+		{"string", "VARCHAR"},
+		{"int", "INTEGER"},
+		{"int64", "BIGINT"},
+		{"uint64", "UBIGINT"},
+		{"bool", "BOOLEAN"},
+		{"float32", "FLOAT"},
+		{"time.Time", "TIMESTAMP WITH TIME ZONE"},
+		{"[]byte", "BLOB"},
+		{"[]int64", "BIGINT[]"},
+		{"varchar(255)", "VARCHAR"},
+		{"numeric", "DECIMAL(18,3)"},
+		{"decimal(10)", "DECIMAL(10,0)"},
+		{"timestamptz", "TIMESTAMP WITH TIME ZONE"},
+		{"int4[]", "INTEGER[]"},
+		{"uuid", "UUID"},
+	} {
+		if actual := d.ColumnType(v.name); actual != v.expect {
+			t.Errorf("ColumnType(%q) => %q; want %q", v.name, actual, v.expect)
+		}
+	}
+	for _, v := range []struct {
+		name     string
+		nullable bool
+		expect   string
+	}{
+		{"VARCHAR", false, "string"},
+		{"VARCHAR", true, "*string"},
+		{"INTEGER", false, "int32"},
+		{"UTINYINT", false, "uint8"},
+		{"BIGINT", true, "*int64"},
+		{"DECIMAL(10,2)", false, "float64"},
+		{"TIMESTAMP", false, "time.Time"},
+		{"VARCHAR[]", false, "[]string"},
+		{"UUID", false, "interface{}"},
+	} {
+		if actual := d.GoType(v.name, v.nullable); actual != v.expect {
+			t.Errorf("GoType(%q, %v) => %q; want %q", v.name, v.nullable, actual, v.expect)
+		}
+	}
+}
+
+func TestDuckDBSQL(t *testing.T) {
+	d := dialect.NewDuckDB(nil, dialect.WithSchema("app"))
+	id := dialect.Field{Table: "user", Name: "id", Type: "BIGINT", AutoIncrement: true}
+	name := dialect.Field{Table: "user", Name: "name", Type: "VARCHAR", Default: "it's", Comment: "user's name"}
+	for _, v := range []struct {
+		actual []string
+		expect []string
+	}{
+		{
+			d.CreateTableSQL(dialect.Table{Name: "user", Fields: []dialect.Field{id, name}, PrimaryKeys: []string{"id"}}),
+			[]string{
+				"CREATE SEQUENCE IF NOT EXISTS \"app\".\"user_id_seq\"",
+				"CREATE TABLE \"app\".\"user\" (\n" +
+					"  \"id\" BIGINT NOT NULL DEFAULT nextval('\"app\".\"user_id_seq\"'),\n" +
+					"  \"name\" VARCHAR NOT NULL DEFAULT 'it''s',\n" +
+					"  PRIMARY KEY (\"id\")\n" +
+					")",
+				"COMMENT ON COLUMN \"app\".\"user\".\"name\" IS 'user''s name'",
+			},
+		},
+		{
+			d.ModifyColumnSQL(name, dialect.Field{Table: "user", Name: "full_name", Type: "TEXT", Nullable: true}),
+			[]string{
+				"ALTER TABLE \"app\".\"user\" RENAME COLUMN \"name\" TO \"full_name\"",
+				"ALTER TABLE \"app\".\"user\" ALTER COLUMN \"full_name\" TYPE TEXT",
+				"ALTER TABLE \"app\".\"user\" ALTER COLUMN \"full_name\" DROP NOT NULL",
+				"ALTER TABLE \"app\".\"user\" ALTER COLUMN \"full_name\" DROP DEFAULT",
+				"COMMENT ON COLUMN \"app\".\"user\".\"full_name\" IS NULL",
+			},
+		},
+		{
+			d.ModifyColumnSQL(dialect.Field{Table: "user", Name: "id", Type: "BIGINT"}, id),
+			[]string{
+				"CREATE SEQUENCE IF NOT EXISTS \"app\".\"user_id_seq\"",
+				"ALTER TABLE \"app\".\"user\" ALTER COLUMN \"id\" SET DEFAULT nextval('\"app\".\"user_id_seq\"')",
+			},
+		},
+		{
+			d.(dialect.PrimaryKeyModifier).ModifyPrimaryKeySQL([]dialect.Field{id}, []dialect.Field{id, name}),
+			[]string{
+				"-- primary key of table \"app\".\"user\" cannot be modified by ALTER TABLE",
+			},
+		},
+		{
+			d.CreateIndexSQL(dialect.Index{Table: "user", Name: "user_name", Columns: []string{"name"}, Unique: true}),
+			[]string{
+				"CREATE UNIQUE INDEX \"user_name\" ON \"app\".\"user\" (\"name\")",
+			},
+		},
+		{
+			d.DropIndexSQL(dialect.Index{Table: "user", Name: "user_name"}),
+			[]string{
+				"DROP INDEX \"app\".\"user_name\"",
+			},
+		},
+	} {
+		if diff := cmp.Diff(v.actual, v.expect); diff != "" {
+			t.Errorf("(-got +want)\n%v", diff)
+		}
+	}
+}
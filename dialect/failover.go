@@ -0,0 +1,55 @@
+package dialect
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// NewFailoverConnector returns the connector that connects to the first available one of the connectors.
+// The connector that has succeeded last is tried first, and the others are tried in order if it fails, so that the new
+// connections of the connection pool follow the primary when it moves to another host.
+// The connectors must be of the same driver.
+func NewFailoverConnector(connectors ...driver.Connector) driver.Connector {
+	if len(connectors) == 1 {
+		return connectors[0]
+	}
+	return &failoverConnector{
+		connectors: connectors,
+	}
+}
+
+type failoverConnector struct {
+	connectors []driver.Connector
+
+	mu      sync.Mutex
+	current int
+}
+
+func (c *failoverConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	c.mu.Lock()
+	current := c.current
+	c.mu.Unlock()
+	var errs []string
+	for i := range c.connectors {
+		n := (current + i) % len(c.connectors)
+		conn, err := c.connectors[n].Connect(ctx)
+		if err == nil {
+			c.mu.Lock()
+			c.current = n
+			c.mu.Unlock()
+			return conn, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		errs = append(errs, err.Error())
+	}
+	return nil, fmt.Errorf("failed to connect to any of %d hosts: %s", len(c.connectors), strings.Join(errs, "; "))
+}
+
+func (c *failoverConnector) Driver() driver.Driver {
+	return c.connectors[0].Driver()
+}
@@ -0,0 +1,48 @@
+package dialect_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/naoina/migu/dialect"
+)
+
+type failingConnector struct {
+	recordConnector
+	down     bool
+	attempts int
+}
+
+func (c *failingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	c.attempts++
+	if c.down {
+		return nil, errors.New("connection refused")
+	}
+	return c.recordConnector.Connect(ctx)
+}
+
+func TestNewFailoverConnector(t *testing.T) {
+	primary, secondary := &failingConnector{}, &failingConnector{}
+	c := dialect.NewFailoverConnector(primary, secondary)
+	for _, v := range []struct {
+		primaryDown, secondaryDown bool
+		primary, secondary         int
+		err                        bool
+	}{
+		{false, false, 1, 0, false},
+		{true, false, 2, 1, false},
+		{false, false, 2, 2, false},
+		{true, true, 3, 3, true},
+	} {
+		primary.down, secondary.down = v.primaryDown, v.secondaryDown
+		_, err := c.Connect(context.Background())
+		if (err != nil) != v.err {
+			t.Errorf("Connect() with the primary down %v and the secondary down %v => %v; want error %v", v.primaryDown, v.secondaryDown, err, v.err)
+		}
+		if primary.attempts != v.primary || secondary.attempts != v.secondary {
+			t.Errorf("attempts => (%d, %d); want (%d, %d)", primary.attempts, secondary.attempts, v.primary, v.secondary)
+		}
+	}
+}
@@ -0,0 +1,479 @@
+package dialect
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// FixtureVersion is the version of the format of the fixture.
+const FixtureVersion = 1
+
+// Fixture is the recorded queries to the database and their results. It is written by FixtureRecorder against the
+// real database, and is replayed by the connector of Connector in order to run the dialect offline, e.g. in the
+// tests of the dialect for a new version of the database.
+type Fixture struct {
+	Version int             `json:"version"`
+	Queries []*FixtureQuery `json:"queries"`
+}
+
+// FixtureQuery is a recorded query or statement and its result.
+type FixtureQuery struct {
+	Query        string           `json:"query"`
+	Args         []FixtureValue   `json:"args,omitempty"`
+	Columns      []string         `json:"columns,omitempty"`
+	Rows         [][]FixtureValue `json:"rows,omitempty"`
+	RowsAffected int64            `json:"rows_affected,omitempty"`
+
+	// Error is the message of the error that is returned by the database, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// FixtureValue is a value of the argument or the column in the fixture.
+// It is encoded with its type such as {"int": 1} so that the type is restored by replaying.
+type FixtureValue struct {
+	Value driver.Value
+}
+
+type fixtureValueJSON struct {
+	Int    *int64   `json:"int,omitempty"`
+	Float  *float64 `json:"float,omitempty"`
+	Bool   *bool    `json:"bool,omitempty"`
+	String *string  `json:"string,omitempty"`
+	Bytes  *string  `json:"bytes,omitempty"`
+	Base64 *string  `json:"base64,omitempty"`
+	Time   *string  `json:"time,omitempty"`
+}
+
+func (v FixtureValue) MarshalJSON() ([]byte, error) {
+	var j fixtureValueJSON
+	switch x := v.Value.(type) {
+	case nil:
+		return []byte("null"), nil
+	case int64:
+		j.Int = &x
+	case float64:
+		j.Float = &x
+	case bool:
+		j.Bool = &x
+	case string:
+		j.String = &x
+	case []byte:
+		s := string(x)
+		if utf8.Valid(x) {
+			j.Bytes = &s
+		} else {
+			s = base64.StdEncoding.EncodeToString(x)
+			j.Base64 = &s
+		}
+	case time.Time:
+		s := x.Format(time.RFC3339Nano)
+		j.Time = &s
+	default:
+		return nil, fmt.Errorf("dialect: unsupported value of fixture: %T", v.Value)
+	}
+	return json.Marshal(j)
+}
+
+func (v *FixtureValue) UnmarshalJSON(b []byte) error {
+	var j *fixtureValueJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+	switch {
+	case j == nil:
+		v.Value = nil
+	case j.Int != nil:
+		v.Value = *j.Int
+	case j.Float != nil:
+		v.Value = *j.Float
+	case j.Bool != nil:
+		v.Value = *j.Bool
+	case j.String != nil:
+		v.Value = *j.String
+	case j.Bytes != nil:
+		v.Value = []byte(*j.Bytes)
+	case j.Base64 != nil:
+		b, err := base64.StdEncoding.DecodeString(*j.Base64)
+		if err != nil {
+			return err
+		}
+		v.Value = b
+	case j.Time != nil:
+		t, err := time.Parse(time.RFC3339Nano, *j.Time)
+		if err != nil {
+			return err
+		}
+		v.Value = t
+	default:
+		return fmt.Errorf("dialect: unknown value of fixture: %s", b)
+	}
+	return nil
+}
+
+// ReadFixture reads the fixture in JSON from r.
+func ReadFixture(r io.Reader) (*Fixture, error) {
+	var f Fixture
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return nil, fmt.Errorf("failed to decode the fixture: %w", err)
+	}
+	if f.Version != FixtureVersion {
+		return nil, fmt.Errorf("unsupported version of the fixture: %d", f.Version)
+	}
+	return &f, nil
+}
+
+// Write writes the fixture in JSON to w.
+func (f *Fixture) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(f)
+}
+
+// Connector returns the connector that replays the queries of the fixture instead of connecting to the database.
+// The same query with the same arguments returns the recorded results in the recorded order, and the last one is
+// returned repeatedly after that. The query that is not recorded returns an error, and the transactions are not
+// supported.
+func (f *Fixture) Connector() driver.Connector {
+	c := &fixtureConnector{
+		queries: map[string][]*FixtureQuery{},
+	}
+	for _, q := range f.Queries {
+		key := fixtureKey(q.Query, q.Args)
+		c.queries[key] = append(c.queries[key], q)
+	}
+	return c
+}
+
+func fixtureKey(query string, args []FixtureValue) string {
+	b, err := json.Marshal(args)
+	if err != nil {
+		// The key of the unsupported arguments never matches.
+		return ""
+	}
+	return query + "\x00" + string(b)
+}
+
+func fixtureArgs(args []driver.NamedValue) []FixtureValue {
+	if len(args) == 0 {
+		return nil
+	}
+	values := make([]FixtureValue, len(args))
+	for i, arg := range args {
+		values[i] = FixtureValue{Value: arg.Value}
+	}
+	return values
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return named
+}
+
+func plainValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	return values
+}
+
+// FixtureRecorder is the connector that records the queries to the database and their results as the fixture.
+type FixtureRecorder struct {
+	driver.Connector
+
+	mu      sync.Mutex
+	queries []*FixtureQuery
+}
+
+// NewFixtureRecorder returns the connector that records the queries through the connections of c.
+// It is used with sql.OpenDB.
+func NewFixtureRecorder(c driver.Connector) *FixtureRecorder {
+	return &FixtureRecorder{
+		Connector: c,
+	}
+}
+
+func (r *FixtureRecorder) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := r.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &recordingConn{Conn: conn, r: r}, nil
+}
+
+// Fixture returns the fixture of the queries that have been recorded.
+func (r *FixtureRecorder) Fixture() *Fixture {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &Fixture{
+		Version: FixtureVersion,
+		Queries: append([]*FixtureQuery(nil), r.queries...),
+	}
+}
+
+func (r *FixtureRecorder) recordQuery(query string, args []driver.NamedValue, rows driver.Rows, err error) (driver.Rows, error) {
+	q := &FixtureQuery{
+		Query: query,
+		Args:  fixtureArgs(args),
+	}
+	if err == nil {
+		q.Columns = rows.Columns()
+		err = readFixtureRows(q, rows)
+		rows.Close()
+	}
+	if err != nil {
+		q.Error = err.Error()
+	}
+	r.mu.Lock()
+	r.queries = append(r.queries, q)
+	r.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return &fixtureRows{q: q}, nil
+}
+
+func (r *FixtureRecorder) recordExec(query string, args []driver.NamedValue, result driver.Result, err error) (driver.Result, error) {
+	q := &FixtureQuery{
+		Query: query,
+		Args:  fixtureArgs(args),
+	}
+	if err == nil {
+		// The number of the affected rows is unknown on some drivers.
+		q.RowsAffected, _ = result.RowsAffected()
+	} else {
+		q.Error = err.Error()
+	}
+	r.mu.Lock()
+	r.queries = append(r.queries, q)
+	r.mu.Unlock()
+	return result, err
+}
+
+func readFixtureRows(q *FixtureQuery, rows driver.Rows) error {
+	for {
+		dest := make([]driver.Value, len(q.Columns))
+		if err := rows.Next(dest); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		row := make([]FixtureValue, len(dest))
+		for i, v := range dest {
+			// The driver may reuse the buffer for the next row.
+			if b, ok := v.([]byte); ok {
+				v = append([]byte(nil), b...)
+			}
+			row[i] = FixtureValue{Value: v}
+		}
+		q.Rows = append(q.Rows, row)
+	}
+}
+
+type recordingConn struct {
+	driver.Conn
+	r *FixtureRecorder
+}
+
+func (c *recordingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	rows, err := queryer.QueryContext(ctx, query, args)
+	if err == driver.ErrSkip {
+		return nil, err
+	}
+	return c.r.recordQuery(query, args, rows, err)
+}
+
+func (c *recordingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	result, err := execer.ExecContext(ctx, query, args)
+	if err == driver.ErrSkip {
+		return nil, err
+	}
+	return c.r.recordExec(query, args, result, err)
+}
+
+func (c *recordingConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &recordingStmt{Stmt: stmt, query: query, r: c.r}, nil
+}
+
+type recordingStmt struct {
+	driver.Stmt
+	query string
+	r     *FixtureRecorder
+}
+
+func (s *recordingStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	var rows driver.Rows
+	var err error
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		rows, err = s.Stmt.Query(plainValues(args))
+	}
+	return s.r.recordQuery(s.query, args, rows, err)
+}
+
+func (s *recordingStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	var result driver.Result
+	var err error
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		result, err = execer.ExecContext(ctx, args)
+	} else {
+		result, err = s.Stmt.Exec(plainValues(args))
+	}
+	return s.r.recordExec(s.query, args, result, err)
+}
+
+type fixtureConnector struct {
+	mu      sync.Mutex
+	queries map[string][]*FixtureQuery
+}
+
+func (c *fixtureConnector) Connect(context.Context) (driver.Conn, error) {
+	return &fixtureConn{c: c}, nil
+}
+
+func (c *fixtureConnector) Driver() driver.Driver {
+	return fixtureDriver{}
+}
+
+// lookup returns the next recorded result of the query with the arguments.
+func (c *fixtureConnector) lookup(query string, args []driver.NamedValue) (*FixtureQuery, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := fixtureKey(query, fixtureArgs(args))
+	queries := c.queries[key]
+	if len(queries) == 0 {
+		return nil, fmt.Errorf("dialect: query is not recorded in the fixture: %s", query)
+	}
+	q := queries[0]
+	if len(queries) > 1 {
+		c.queries[key] = queries[1:]
+	}
+	if q.Error != "" {
+		return nil, errors.New(q.Error)
+	}
+	return q, nil
+}
+
+type fixtureDriver struct{}
+
+func (fixtureDriver) Open(name string) (driver.Conn, error) {
+	return nil, fmt.Errorf("dialect: fixture driver cannot be opened by name")
+}
+
+var errFixtureTransaction = errors.New("dialect: transactions are not supported by the fixture")
+
+type fixtureConn struct {
+	c *fixtureConnector
+}
+
+func (c *fixtureConn) Prepare(query string) (driver.Stmt, error) {
+	return &fixtureStmt{c: c.c, query: query}, nil
+}
+
+func (c *fixtureConn) Close() error {
+	return nil
+}
+
+func (c *fixtureConn) Begin() (driver.Tx, error) {
+	return nil, errFixtureTransaction
+}
+
+func (c *fixtureConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, err := c.c.lookup(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &fixtureRows{q: q}, nil
+}
+
+func (c *fixtureConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	q, err := c.c.lookup(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(q.RowsAffected), nil
+}
+
+type fixtureStmt struct {
+	c     *fixtureConnector
+	query string
+}
+
+func (s *fixtureStmt) Close() error {
+	return nil
+}
+
+func (s *fixtureStmt) NumInput() int {
+	return -1
+}
+
+func (s *fixtureStmt) Exec(args []driver.Value) (driver.Result, error) {
+	q, err := s.c.lookup(s.query, namedValues(args))
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(q.RowsAffected), nil
+}
+
+func (s *fixtureStmt) Query(args []driver.Value) (driver.Rows, error) {
+	q, err := s.c.lookup(s.query, namedValues(args))
+	if err != nil {
+		return nil, err
+	}
+	return &fixtureRows{q: q}, nil
+}
+
+// fixtureRows is the rows of the recorded query.
+type fixtureRows struct {
+	q   *FixtureQuery
+	pos int
+}
+
+func (r *fixtureRows) Columns() []string {
+	return r.q.Columns
+}
+
+func (r *fixtureRows) Close() error {
+	return nil
+}
+
+func (r *fixtureRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.q.Rows) {
+		return io.EOF
+	}
+	for i, v := range r.q.Rows[r.pos] {
+		dest[i] = v.Value
+	}
+	r.pos++
+	return nil
+}
@@ -0,0 +1,82 @@
+package dialect_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mattn/go-sqlite3"
+	"github.com/naoina/migu/dialect"
+)
+
+type sqliteConnector struct {
+	dsn string
+}
+
+func (c *sqliteConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.Driver().Open(c.dsn)
+}
+
+func (c *sqliteConnector) Driver() driver.Driver {
+	return &sqlite3.SQLiteDriver{}
+}
+
+func TestFixture(t *testing.T) {
+	dir, err := ioutil.TempDir("", "migu-fixture")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	recorder := dialect.NewFixtureRecorder(&sqliteConnector{dsn: filepath.Join(dir, "test.db")})
+	db := sql.OpenDB(recorder)
+	defer db.Close()
+	for _, q := range []string{
+		`CREATE TABLE "user" ("id" INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT, "name" VARCHAR(255) NOT NULL DEFAULT 'x', "age" INTEGER)`,
+		`CREATE UNIQUE INDEX "user_name" ON "user" ("name")`,
+	} {
+		if _, err := db.Exec(q); err != nil {
+			t.Fatal(err)
+		}
+	}
+	describe := func(d dialect.Dialect) []string {
+		schemas, err := d.ColumnSchema("user")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var result []string
+		for _, s := range schemas {
+			def, _ := s.Default()
+			index, unique, _ := s.Index()
+			result = append(result, fmt.Sprintf("%s.%s %s pk:%v null:%v default:%q index:%s,%v", s.TableName(), s.ColumnName(), s.ColumnType(), s.IsPrimaryKey(), s.IsNullable(), def, index, unique))
+		}
+		return result
+	}
+	expect := describe(dialect.NewSQLite(db))
+	var buf bytes.Buffer
+	if err := recorder.Fixture().Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	fixture, err := dialect.ReadFixture(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay := sql.OpenDB(fixture.Connector())
+	defer replay.Close()
+	d := dialect.NewSQLite(replay)
+	if diff := cmp.Diff(describe(d), expect); diff != "" {
+		t.Errorf("(-got +want)\n%v", diff)
+	}
+	if _, err := d.ColumnSchema("post"); err == nil {
+		t.Errorf("ColumnSchema of the query that is not recorded => nil; want error")
+	}
+	if _, err := d.Begin(); err == nil {
+		t.Errorf("Begin() => nil; want error")
+	}
+}
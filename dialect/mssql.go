@@ -0,0 +1,848 @@
+package dialect
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+var (
+	_ PrimaryKeyModifier = &MSSQL{}
+	_ StatelessGenerator = &MSSQL{}
+	_ IndexReader        = &MSSQL{}
+	_ RowReader          = &MSSQL{}
+	_ TableRenamer       = &MSSQL{}
+	_ ColumnRenamer      = &MSSQL{}
+	_ TableAnalyzer      = &MSSQL{}
+)
+
+const (
+	// mssqlPrimaryKeyIndex is the name of the primary key that is returned by Indexes in the same way as MySQL.
+	mssqlPrimaryKeyIndex = "PRIMARY"
+
+	// mssqlDefaultDatetimePrecision is the precision of the time types that is omitted from the column types.
+	mssqlDefaultDatetimePrecision = 7
+)
+
+var (
+	mssqlColumnTypes = []*ColumnType{
+		{
+			// NVARCHAR stores the strings in UTF-16 regardless of the collation of the database.
+			Types:           []string{"NVARCHAR(255)", "NVARCHAR", "NCHAR", "VARCHAR", "CHAR", "NTEXT", "TEXT"},
+			GoTypes:         []string{"string"},
+			GoNullableTypes: []string{"*string", "sql.NullString"},
+		},
+		{
+			Types:           []string{"VARBINARY(MAX)", "VARBINARY", "BINARY", "IMAGE"},
+			GoTypes:         []string{"[]byte"},
+			GoNullableTypes: []string{"[]byte"},
+		},
+		{
+			Types:           []string{"INT"},
+			GoTypes:         []string{"int", "int32", "uint16"},
+			GoNullableTypes: []string{"*int32", "sql.NullInt32"},
+		},
+		{
+			Types:   []string{"SMALLINT"},
+			GoTypes: []string{"int16", "int8"},
+		},
+		{
+			// TINYINT is unsigned in SQL Server.
+			Types:   []string{"TINYINT"},
+			GoTypes: []string{"uint8"},
+		},
+		{
+			Types:           []string{"BIGINT"},
+			GoTypes:         []string{"int64", "uint32"},
+			GoNullableTypes: []string{"*int64", "sql.NullInt64"},
+		},
+		{
+			// DECIMAL(20,0) can hold all the values of uint64.
+			Types:   []string{"DECIMAL(20,0)"},
+			GoTypes: []string{"uint64", "uint"},
+		},
+		{
+			Types:           []string{"BIT"},
+			GoTypes:         []string{"bool"},
+			GoNullableTypes: []string{"*bool", "sql.NullBool"},
+		},
+		{
+			Types:           []string{"FLOAT", "DECIMAL", "NUMERIC", "MONEY"},
+			GoTypes:         []string{"float64"},
+			GoNullableTypes: []string{"*float64", "sql.NullFloat64"},
+		},
+		{
+			Types:   []string{"REAL"},
+			GoTypes: []string{"float32"},
+		},
+		{
+			Types:           []string{"DATETIME2", "DATETIMEOFFSET", "DATETIME", "SMALLDATETIME", "DATE"},
+			GoTypes:         []string{"time.Time"},
+			GoNullableTypes: []string{"*time.Time", "sql.NullTime"},
+		},
+	}
+
+	// mssqlTypeAliases are the names of the types that are returned by INFORMATION_SCHEMA of SQL Server.
+	mssqlTypeAliases = map[string]string{
+		"INTEGER":                    "INT",
+		"DEC":                        "DECIMAL",
+		"DOUBLE PRECISION":           "FLOAT",
+		"CHARACTER":                  "CHAR",
+		"CHARACTER VARYING":          "VARCHAR",
+		"NATIONAL CHARACTER":         "NCHAR",
+		"NATIONAL CHAR":              "NCHAR",
+		"NATIONAL CHARACTER VARYING": "NVARCHAR",
+		"NATIONAL CHAR VARYING":      "NVARCHAR",
+		"ROWVERSION":                 "TIMESTAMP",
+	}
+)
+
+// MSSQL is the dialect of Microsoft SQL Server.
+// The tables are in the schema that is specified by WithSchema, or the default schema of the connecting user.
+//
+// IDENTITY cannot be added to or removed from the existing columns by ALTER TABLE of SQL Server, so that such
+// changes are returned as the comments instead of SQLs.
+type MSSQL struct {
+	db              *sql.DB
+	opt             *option
+	schemaName      string
+	columnTypeMap   map[string]*ColumnType
+	nullableTypeMap map[string]struct{}
+}
+
+// NewMSSQL returns a new dialect of SQL Server. db must be opened by the driver of SQL Server such as go-mssqldb.
+func NewMSSQL(db *sql.DB, opts ...Option) Dialect {
+	d := &MSSQL{
+		db:              db,
+		opt:             newOption(),
+		columnTypeMap:   map[string]*ColumnType{},
+		nullableTypeMap: map[string]struct{}{},
+	}
+	for _, o := range opts {
+		o(d.opt)
+	}
+	for _, types := range [][]*ColumnType{mssqlColumnTypes, d.opt.columnTypes} {
+		for _, t := range types {
+			for _, tt := range t.allGoTypes() {
+				d.columnTypeMap[tt] = t
+			}
+			for _, tt := range t.filteredNullableGoTypes() {
+				d.nullableTypeMap[tt] = struct{}{}
+			}
+		}
+	}
+	return d
+}
+
+func (d *MSSQL) ColumnSchema(tables ...string) ([]ColumnSchema, error) {
+	schema, err := d.currentSchema()
+	if err != nil {
+		return nil, err
+	}
+	primaryKeys, indexMap, err := d.getIndexMap(schema)
+	if err != nil {
+		return nil, err
+	}
+	parts := []string{
+		"SELECT",
+		"  c.TABLE_NAME,",
+		"  c.COLUMN_NAME,",
+		"  c.COLUMN_DEFAULT,",
+		"  c.IS_NULLABLE,",
+		"  c.DATA_TYPE,",
+		"  c.CHARACTER_MAXIMUM_LENGTH,",
+		"  c.NUMERIC_PRECISION,",
+		"  c.NUMERIC_SCALE,",
+		"  c.DATETIME_PRECISION,",
+		"  COLUMNPROPERTY(OBJECT_ID(QUOTENAME(c.TABLE_SCHEMA) + '.' + QUOTENAME(c.TABLE_NAME)), c.COLUMN_NAME, 'IsIdentity'),",
+		"  COALESCE(CAST(ep.value AS NVARCHAR(MAX)), '')",
+		"FROM INFORMATION_SCHEMA.COLUMNS c",
+		"JOIN INFORMATION_SCHEMA.TABLES t ON t.TABLE_SCHEMA = c.TABLE_SCHEMA AND t.TABLE_NAME = c.TABLE_NAME",
+		"LEFT JOIN sys.extended_properties ep ON ep.class = 1 AND ep.name = 'MS_Description'",
+		"  AND ep.major_id = OBJECT_ID(QUOTENAME(c.TABLE_SCHEMA) + '.' + QUOTENAME(c.TABLE_NAME))",
+		"  AND ep.minor_id = COLUMNPROPERTY(ep.major_id, c.COLUMN_NAME, 'ColumnId')",
+		"WHERE c.TABLE_SCHEMA = @p1 AND t.TABLE_TYPE = 'BASE TABLE'",
+	}
+	args := []interface{}{schema}
+	if len(tables) > 0 {
+		parts = append(parts, fmt.Sprintf("AND c.TABLE_NAME IN (%s)", mssqlPlaceholders(2, len(tables))))
+		for _, t := range tables {
+			args = append(args, t)
+		}
+	}
+	parts = append(parts, "ORDER BY c.TABLE_NAME, c.ORDINAL_POSITION")
+	rows, err := d.db.Query(strings.Join(parts, "\n"), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var schemas []ColumnSchema
+	for rows.Next() {
+		schema := &mssqlColumnSchema{}
+		if err := rows.Scan(
+			&schema.tableName,
+			&schema.columnName,
+			&schema.columnDefault,
+			&schema.isNullable,
+			&schema.dataType,
+			&schema.characterMaximumLength,
+			&schema.numericPrecision,
+			&schema.numericScale,
+			&schema.datetimePrecision,
+			&schema.isIdentity,
+			&schema.comment,
+		); err != nil {
+			return nil, err
+		}
+		schema.primaryKey = primaryKeys[schema.tableName][schema.columnName]
+		if info, exists := indexMap[schema.tableName][schema.columnName]; exists {
+			schema.indexName = info.name
+			schema.unique = info.unique
+		}
+		schemas = append(schemas, schema)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return schemas, nil
+}
+
+func (d *MSSQL) ColumnType(name string) string {
+	if t, ok := d.columnTypeMap[name]; ok {
+		name, _, _, _ = t.findType(name)
+	}
+	return mssqlCanonicalType(name)
+}
+
+func (d *MSSQL) GoType(name string, nullable bool) string {
+	return d.goType(mssqlCanonicalType(name), nullable)
+}
+
+func (d *MSSQL) goType(name string, nullable bool) string {
+	for _, types := range [][]*ColumnType{d.opt.columnTypes, mssqlColumnTypes} {
+		for _, t := range types {
+			if typ, found := t.findGoType(name, nullable, false); found {
+				return typ
+			}
+		}
+	}
+	if strings.IndexByte(name, '(') >= 0 {
+		return d.goType(trimParens(name), nullable)
+	}
+	return "interface{}"
+}
+
+func (d *MSSQL) IsNullable(name string) bool {
+	_, ok := d.nullableTypeMap[name]
+	return ok
+}
+
+func (d *MSSQL) ImportPackage(schema ColumnSchema) string {
+	switch schema.DataType() {
+	case "date", "datetime", "datetime2", "datetimeoffset", "smalldatetime":
+		return "time"
+	}
+	return ""
+}
+
+func (d *MSSQL) GoTypeImports() ([]Import, error) {
+	return goTypeImports(d.opt.columnTypes)
+}
+
+func (d *MSSQL) Quote(s string) string {
+	return "[" + strings.Replace(s, "]", "]]", -1) + "]"
+}
+
+// QuoteString returns the Unicode string literal of s.
+func (d *MSSQL) QuoteString(s string) string {
+	return "N" + quoteByDoubling(s, "'", false)
+}
+
+// IsStateless returns true since the SQLs of each change are built only from the change.
+func (d *MSSQL) IsStateless() bool {
+	return true
+}
+
+func (d *MSSQL) CreateTableSQL(table Table) []string {
+	columns := make([]string, len(table.Fields))
+	for i, f := range table.Fields {
+		columns[i] = d.columnSQL(f)
+	}
+	if len(table.PrimaryKeys) > 0 {
+		pkColumns := make([]string, len(table.PrimaryKeys))
+		for i, pk := range table.PrimaryKeys {
+			pkColumns[i] = d.Quote(pk)
+		}
+		columns = append(columns, fmt.Sprintf("CONSTRAINT %s PRIMARY KEY (%s)", d.Quote("PK_"+table.Name), strings.Join(pkColumns, ", ")))
+	}
+	query := fmt.Sprintf("CREATE TABLE %s (\n"+
+		"  %s\n"+
+		")", d.table(table.Name), strings.Join(columns, ",\n  "))
+	if table.Option != "" {
+		query += " " + table.Option
+	}
+	sqls := []string{query}
+	for _, f := range table.Fields {
+		if f.Comment != "" {
+			sqls = append(sqls, d.commentSQL("", f))
+		}
+	}
+	return sqls
+}
+
+func (d *MSSQL) AddColumnSQL(field Field) []string {
+	sqls := []string{fmt.Sprintf("ALTER TABLE %s ADD %s", d.table(field.Table), d.columnSQL(field))}
+	if field.Comment != "" {
+		sqls = append(sqls, d.commentSQL("", field))
+	}
+	return sqls
+}
+
+// DropColumnSQL returns SQLs to drop the column. The default constraint and the indexes of the column are dropped
+// before the column because SQL Server does not drop them with the column.
+func (d *MSSQL) DropColumnSQL(field Field) []string {
+	return []string{
+		d.dropDefaultSQL(field),
+		d.dropColumnIndexesSQL(field),
+		fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", d.table(field.Table), d.Quote(field.Name)),
+	}
+}
+
+func (d *MSSQL) ModifyColumnSQL(oldField, newField Field) []string {
+	var sqls []string
+	tableName := d.table(newField.Table)
+	if oldField.Name != newField.Name {
+		sqls = append(sqls, d.renameColumnSQL(oldField, newField))
+	}
+	column := d.Quote(newField.Name)
+	if oldField.AutoIncrement != newField.AutoIncrement {
+		sqls = append(sqls, fmt.Sprintf("-- IDENTITY of column %s.%s cannot be modified by ALTER TABLE", tableName, column))
+	}
+	if oldField.Type != newField.Type || oldField.Nullable != newField.Nullable {
+		nullable := "NOT NULL"
+		if newField.Nullable {
+			nullable = "NULL"
+		}
+		sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s %s %s", tableName, column, newField.Type, nullable))
+	}
+	if oldField.Default != newField.Default {
+		sqls = append(sqls, d.dropDefaultSQL(newField))
+		if newField.Default != "" {
+			sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s DEFAULT %s FOR %s", tableName, d.Quote(d.defaultName(newField)), d.defaultSQL(newField), column))
+		}
+	}
+	if oldField.Comment != newField.Comment {
+		sqls = append(sqls, d.commentSQL(oldField.Comment, newField))
+	}
+	return sqls
+}
+
+func (d *MSSQL) RenameColumnSQL(oldField, newField Field) []string {
+	return d.ModifyColumnSQL(oldField, newField)
+}
+
+func (d *MSSQL) ModifyPrimaryKeySQL(oldPrimaryKeys, newPrimaryKeys []Field) []string {
+	var tableName string
+	if len(newPrimaryKeys) > 0 {
+		tableName = newPrimaryKeys[0].Table
+	} else {
+		tableName = oldPrimaryKeys[0].Table
+	}
+	var sqls []string
+	if len(oldPrimaryKeys) > 0 {
+		// The name of the primary key constraint may be generated by SQL Server.
+		sqls = append(sqls, d.dropConstraintsSQL(tableName, "SELECT name FROM sys.key_constraints WHERE type = 'PK' AND parent_object_id = OBJECT_ID("+d.objectName(tableName)+")"))
+	}
+	if len(newPrimaryKeys) > 0 {
+		pkColumns := make([]string, len(newPrimaryKeys))
+		for i, pk := range newPrimaryKeys {
+			pkColumns[i] = d.Quote(pk.Name)
+		}
+		sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s PRIMARY KEY (%s)", d.table(tableName), d.Quote("PK_"+tableName), strings.Join(pkColumns, ", ")))
+	}
+	return sqls
+}
+
+func (d *MSSQL) RenameTableSQL(oldName, newName string) []string {
+	return []string{fmt.Sprintf("EXEC sp_rename %s, %s", d.objectName(oldName), d.QuoteString(newName))}
+}
+
+func (d *MSSQL) AnalyzeTableSQL(table string) []string {
+	return []string{fmt.Sprintf("UPDATE STATISTICS %s", d.table(table))}
+}
+
+func (d *MSSQL) CreateIndexSQL(index Index) []string {
+	columns := make([]string, len(index.Columns))
+	for i, c := range index.Columns {
+		columns[i] = d.Quote(c)
+	}
+	indexName := d.Quote(index.Name)
+	tableName := d.table(index.Table)
+	column := strings.Join(columns, ", ")
+	if index.Unique {
+		return []string{fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (%s)", indexName, tableName, column)}
+	}
+	return []string{fmt.Sprintf("CREATE INDEX %s ON %s (%s)", indexName, tableName, column)}
+}
+
+func (d *MSSQL) DropIndexSQL(index Index) []string {
+	return []string{fmt.Sprintf("DROP INDEX %s ON %s", d.Quote(index.Name), d.table(index.Table))}
+}
+
+// SchemaLimits returns the limits of SQL Server 2016 or later, where an index can have 32 key columns.
+func (d *MSSQL) SchemaLimits(table Table) SchemaLimits {
+	return SchemaLimits{
+		MaxIdentifierLength: 128,
+		MaxColumns:          1024,
+		MaxIndexes:          999,
+		MaxIndexColumns:     32,
+	}
+}
+
+func (d *MSSQL) Begin() (Transactioner, error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &mssqlTransaction{
+		tx: tx,
+	}, nil
+}
+
+func (d *MSSQL) Indexes(tables ...string) ([]Index, error) {
+	schema, err := d.currentSchema()
+	if err != nil {
+		return nil, err
+	}
+	parts := append(mssqlIndexQuery(), "WHERE s.name = @p1 AND i.type > 0 AND ic.is_included_column = 0")
+	args := []interface{}{schema}
+	if len(tables) > 0 {
+		parts = append(parts, fmt.Sprintf("AND t.name IN (%s)", mssqlPlaceholders(2, len(tables))))
+		for _, t := range tables {
+			args = append(args, t)
+		}
+	}
+	parts = append(parts, "ORDER BY t.name, i.name, ic.key_ordinal")
+	rows, err := d.db.Query(strings.Join(parts, "\n"), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var indexes []Index
+	for rows.Next() {
+		var (
+			tableName  string
+			indexName  string
+			columnName string
+			unique     bool
+			primary    bool
+		)
+		if err := rows.Scan(&tableName, &indexName, &columnName, &unique, &primary); err != nil {
+			return nil, err
+		}
+		if primary {
+			indexName = mssqlPrimaryKeyIndex
+		}
+		if n := len(indexes); n > 0 && indexes[n-1].Table == tableName && indexes[n-1].Name == indexName {
+			indexes[n-1].Columns = append(indexes[n-1].Columns, columnName)
+			continue
+		}
+		indexes = append(indexes, Index{
+			Table:   tableName,
+			Name:    indexName,
+			Columns: []string{columnName},
+			Unique:  unique,
+		})
+	}
+	return indexes, rows.Err()
+}
+
+func (d *MSSQL) ReadRows(table string, columns []string, fn func(values []*string) error) error {
+	quoted := make([]string, len(columns))
+	for i, c := range columns {
+		// The values are read as text so that all the types can be scanned into strings.
+		quoted[i] = fmt.Sprintf("CAST(%s AS NVARCHAR(MAX))", d.Quote(c))
+	}
+	rows, err := d.db.Query(fmt.Sprintf("SELECT %s FROM %s", strings.Join(quoted, ", "), d.table(table)))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	raws := make([]sql.NullString, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range raws {
+		dest[i] = &raws[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+		values := make([]*string, len(raws))
+		for i, raw := range raws {
+			if raw.Valid {
+				v := raw.String
+				values[i] = &v
+			}
+		}
+		if err := fn(values); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// table returns the quoted name of the table that is qualified by the schema if WithSchema is specified.
+func (d *MSSQL) table(name string) string {
+	if d.opt.schema == "" {
+		return d.Quote(name)
+	}
+	return d.Quote(d.opt.schema) + "." + d.Quote(name)
+}
+
+// objectName returns the string literal of the name of the table for OBJECT_ID and sp_rename.
+func (d *MSSQL) objectName(table string) string {
+	return d.QuoteString(d.table(table))
+}
+
+func (d *MSSQL) currentSchema() (string, error) {
+	if d.opt.schema != "" {
+		return d.opt.schema, nil
+	}
+	if d.schemaName != "" {
+		return d.schemaName, nil
+	}
+	if err := d.db.QueryRow(`SELECT SCHEMA_NAME()`).Scan(&d.schemaName); err != nil {
+		return "", err
+	}
+	return d.schemaName, nil
+}
+
+// getIndexMap returns the primary key columns and the indexes of the columns except the primary keys of the tables.
+func (d *MSSQL) getIndexMap(schema string) (primaryKeys map[string]map[string]bool, indexMap map[string]map[string]mssqlIndexInfo, err error) {
+	query := strings.Join(append(mssqlIndexQuery(), "WHERE s.name = @p1 AND i.type > 0 AND ic.is_included_column = 0"), "\n")
+	rows, err := d.db.Query(query, schema)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	primaryKeys = make(map[string]map[string]bool)
+	indexMap = make(map[string]map[string]mssqlIndexInfo)
+	for rows.Next() {
+		var (
+			tableName  string
+			columnName string
+			index      mssqlIndexInfo
+			primary    bool
+		)
+		if err := rows.Scan(&tableName, &index.name, &columnName, &index.unique, &primary); err != nil {
+			return nil, nil, err
+		}
+		if primary {
+			if _, exists := primaryKeys[tableName]; !exists {
+				primaryKeys[tableName] = make(map[string]bool)
+			}
+			primaryKeys[tableName][columnName] = true
+			continue
+		}
+		if _, exists := indexMap[tableName]; !exists {
+			indexMap[tableName] = make(map[string]mssqlIndexInfo)
+		}
+		indexMap[tableName][columnName] = index
+	}
+	return primaryKeys, indexMap, rows.Err()
+}
+
+func (d *MSSQL) columnSQL(f Field) string {
+	column := []string{d.Quote(f.Name), f.Type}
+	if f.AutoIncrement {
+		column = append(column, "IDENTITY(1,1)")
+	}
+	if f.Nullable {
+		column = append(column, "NULL")
+	} else {
+		column = append(column, "NOT NULL")
+	}
+	if f.Default != "" {
+		column = append(column, "CONSTRAINT", d.Quote(d.defaultName(f)), "DEFAULT", d.defaultSQL(f))
+	}
+	if f.Extra != "" {
+		column = append(column, f.Extra)
+	}
+	return strings.Join(column, " ")
+}
+
+// defaultName returns the name of the default constraint of the column.
+func (d *MSSQL) defaultName(f Field) string {
+	return fmt.Sprintf("DF_%s_%s", f.Table, f.Name)
+}
+
+func (d *MSSQL) defaultSQL(f Field) string {
+	if d.isTextType(f) {
+		return d.QuoteString(f.Default)
+	}
+	return f.Default
+}
+
+// dropDefaultSQL returns the SQL to drop the default constraint of the column if exists.
+func (d *MSSQL) dropDefaultSQL(f Field) string {
+	object := d.objectName(f.Table)
+	return d.dropConstraintsSQL(f.Table, fmt.Sprintf("SELECT name FROM sys.default_constraints WHERE parent_object_id = OBJECT_ID(%s) AND parent_column_id = COLUMNPROPERTY(OBJECT_ID(%s), %s, 'ColumnId')", object, object, d.QuoteString(f.Name)))
+}
+
+// dropConstraintsSQL returns the SQL to drop the constraints of the table that are selected by the query, because
+// the names of the constraints that are generated by SQL Server are unknown until executed.
+func (d *MSSQL) dropConstraintsSQL(table, query string) string {
+	return fmt.Sprintf("DECLARE @sql NVARCHAR(MAX) = N''; SELECT @sql += N'ALTER TABLE %s DROP CONSTRAINT ' + QUOTENAME(name) + N';' FROM (%s) c; EXEC sp_executesql @sql", strings.Replace(d.table(table), "'", "''", -1), query)
+}
+
+// dropColumnIndexesSQL returns the SQL to drop the indexes of the column except the primary key.
+func (d *MSSQL) dropColumnIndexesSQL(f Field) string {
+	object := d.objectName(f.Table)
+	return fmt.Sprintf("DECLARE @sql NVARCHAR(MAX) = N''; SELECT DISTINCT @sql += N'DROP INDEX ' + QUOTENAME(i.name) + N' ON %s;' FROM sys.indexes i JOIN sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id WHERE i.object_id = OBJECT_ID(%s) AND ic.column_id = COLUMNPROPERTY(OBJECT_ID(%s), %s, 'ColumnId') AND i.is_primary_key = 0; EXEC sp_executesql @sql",
+		strings.Replace(d.table(f.Table), "'", "''", -1), object, object, d.QuoteString(f.Name))
+}
+
+func (d *MSSQL) renameColumnSQL(oldField, newField Field) string {
+	return fmt.Sprintf("EXEC sp_rename %s, %s, 'COLUMN'", d.QuoteString(d.table(oldField.Table)+"."+d.Quote(oldField.Name)), d.QuoteString(newField.Name))
+}
+
+// commentSQL returns the SQL to set the comment of the column to the MS_Description extended property.
+// oldComment is the comment before the change that decides whether the property is added, updated or dropped.
+func (d *MSSQL) commentSQL(oldComment string, f Field) string {
+	level := fmt.Sprintf("@level0type = N'SCHEMA', @level0name = %s, @level1type = N'TABLE', @level1name = %s, @level2type = N'COLUMN', @level2name = %s",
+		d.schemaLiteral(), d.QuoteString(f.Table), d.QuoteString(f.Name))
+	switch {
+	case f.Comment == "":
+		return fmt.Sprintf("EXEC sp_dropextendedproperty @name = N'MS_Description', %s", level)
+	case oldComment == "":
+		return fmt.Sprintf("EXEC sp_addextendedproperty @name = N'MS_Description', @value = %s, %s", d.QuoteString(f.Comment), level)
+	}
+	return fmt.Sprintf("EXEC sp_updateextendedproperty @name = N'MS_Description', @value = %s, %s", d.QuoteString(f.Comment), level)
+}
+
+// schemaLiteral returns the schema of the tables for the extended properties.
+func (d *MSSQL) schemaLiteral() string {
+	if d.opt.schema != "" {
+		return d.QuoteString(d.opt.schema)
+	}
+	return "SCHEMA_NAME()"
+}
+
+func (d *MSSQL) isTextType(f Field) bool {
+	typ := strings.ToUpper(f.Type)
+	for _, t := range []string{"NVARCHAR", "VARCHAR", "NCHAR", "CHAR", "NTEXT", "TEXT"} {
+		if strings.HasPrefix(typ, t) {
+			return true
+		}
+	}
+	return false
+}
+
+func mssqlIndexQuery() []string {
+	return []string{
+		"SELECT",
+		"  t.name,",
+		"  i.name,",
+		"  c.name,",
+		"  i.is_unique,",
+		"  i.is_primary_key",
+		"FROM sys.indexes i",
+		"JOIN sys.tables t ON t.object_id = i.object_id",
+		"JOIN sys.schemas s ON s.schema_id = t.schema_id",
+		"JOIN sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id",
+		"JOIN sys.columns c ON c.object_id = ic.object_id AND c.column_id = ic.column_id",
+	}
+}
+
+// mssqlCanonicalType returns the name of the type in the same form as the column type that is read from
+// INFORMATION_SCHEMA in upper case. (e.g. "nvarchar" to "NVARCHAR(1)", "datetime2(7)" to "DATETIME2")
+func mssqlCanonicalType(name string) string {
+	name = strings.ToUpper(strings.Join(strings.Fields(name), " "))
+	base, params := name, ""
+	if i := strings.IndexByte(name, '('); i >= 0 {
+		if j := strings.IndexByte(name[i:], ')'); j >= 0 {
+			base, params = strings.TrimSpace(name[:i]), strings.Replace(name[i+1:i+j], " ", "", -1)
+		}
+	}
+	if alias, ok := mssqlTypeAliases[base]; ok {
+		base = alias
+	}
+	switch base {
+	case "CHAR", "VARCHAR", "NCHAR", "NVARCHAR", "BINARY", "VARBINARY":
+		if params == "" {
+			// The length is 1 if it is not specified in the column definition.
+			params = "1"
+		}
+	case "DECIMAL", "NUMERIC":
+		switch {
+		case params == "":
+			params = "18,0"
+		case !strings.Contains(params, ","):
+			params += ",0"
+		}
+	case "FLOAT":
+		// FLOAT(1) to FLOAT(24) is REAL, and FLOAT(25) to FLOAT(53) is FLOAT.
+		if params != "" {
+			var n int
+			fmt.Sscanf(params, "%d", &n)
+			if n > 0 && n <= 24 {
+				base = "REAL"
+			}
+			params = ""
+		}
+	case "DATETIME2", "DATETIMEOFFSET", "TIME":
+		if params == fmt.Sprint(mssqlDefaultDatetimePrecision) {
+			params = ""
+		}
+	}
+	if params != "" {
+		return base + "(" + params + ")"
+	}
+	return base
+}
+
+// mssqlPlaceholders returns n placeholders that start from @p<start> separated by commas.
+func mssqlPlaceholders(start, n int) string {
+	placeholders := make([]string, n)
+	for i := range placeholders {
+		placeholders[i] = fmt.Sprintf("@p%d", start+i)
+	}
+	return strings.Join(placeholders, ",")
+}
+
+type mssqlIndexInfo struct {
+	name   string
+	unique bool
+}
+
+type mssqlTransaction struct {
+	tx *sql.Tx
+}
+
+func (m *mssqlTransaction) Exec(sql string, args ...interface{}) error {
+	_, err := m.tx.Exec(sql, args...)
+	return err
+}
+
+func (m *mssqlTransaction) Commit() error {
+	return m.tx.Commit()
+}
+
+func (m *mssqlTransaction) Rollback() error {
+	return m.tx.Rollback()
+}
+
+var _ ColumnSchema = &mssqlColumnSchema{}
+
+type mssqlColumnSchema struct {
+	tableName              string
+	columnName             string
+	columnDefault          sql.NullString
+	isNullable             string
+	dataType               string
+	characterMaximumLength sql.NullInt64
+	numericPrecision       sql.NullInt64
+	numericScale           sql.NullInt64
+	datetimePrecision      sql.NullInt64
+	isIdentity             sql.NullInt64
+	comment                string
+	primaryKey             bool
+	indexName              string
+	unique                 bool
+}
+
+func (schema *mssqlColumnSchema) TableName() string {
+	return schema.tableName
+}
+
+func (schema *mssqlColumnSchema) ColumnName() string {
+	return schema.columnName
+}
+
+func (schema *mssqlColumnSchema) ColumnType() string {
+	typ := strings.ToUpper(schema.dataType)
+	switch typ {
+	case "CHAR", "VARCHAR", "NCHAR", "NVARCHAR", "BINARY", "VARBINARY":
+		if length := schema.characterMaximumLength.Int64; length >= 0 {
+			return fmt.Sprintf("%s(%d)", typ, length)
+		}
+		return typ + "(MAX)"
+	case "DECIMAL", "NUMERIC":
+		return fmt.Sprintf("%s(%d,%d)", typ, schema.numericPrecision.Int64, schema.numericScale.Int64)
+	case "DATETIME2", "DATETIMEOFFSET", "TIME":
+		if p := schema.datetimePrecision; p.Valid && p.Int64 != mssqlDefaultDatetimePrecision {
+			return fmt.Sprintf("%s(%d)", typ, p.Int64)
+		}
+	}
+	return typ
+}
+
+func (schema *mssqlColumnSchema) DataType() string {
+	return schema.dataType
+}
+
+func (schema *mssqlColumnSchema) IsPrimaryKey() bool {
+	return schema.primaryKey
+}
+
+func (schema *mssqlColumnSchema) IsAutoIncrement() bool {
+	return schema.isIdentity.Int64 == 1
+}
+
+func (schema *mssqlColumnSchema) Index() (name string, unique bool, ok bool) {
+	if schema.indexName != "" {
+		return schema.indexName, schema.unique, true
+	}
+	return "", false, false
+}
+
+// Default returns the default value of the column without the parentheses that SQL Server adds.
+// (e.g. "((0))" to "0", "(N'x')" to "x")
+func (schema *mssqlColumnSchema) Default() (string, bool) {
+	if !schema.columnDefault.Valid {
+		return "", false
+	}
+	def := schema.columnDefault.String
+	for len(def) >= 2 && def[0] == '(' && def[len(def)-1] == ')' && isEnclosedByParens(def) {
+		def = def[1 : len(def)-1]
+	}
+	if def == "NULL" {
+		return "", false
+	}
+	if s := strings.TrimPrefix(def, "N"); len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		def = strings.Replace(s[1:len(s)-1], "''", "'", -1) // unescape string
+	}
+	return def, true
+}
+
+func (schema *mssqlColumnSchema) IsNullable() bool {
+	return strings.ToUpper(schema.isNullable) == "YES"
+}
+
+func (schema *mssqlColumnSchema) Extra() (string, bool) {
+	return "", false
+}
+
+func (schema *mssqlColumnSchema) Comment() (string, bool) {
+	return schema.comment, schema.comment != ""
+}
+
+// isEnclosedByParens reports whether the whole of s is enclosed by a pair of the parentheses.
+func isEnclosedByParens(s string) bool {
+	depth := 0
+	inString := false
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\'':
+			inString = !inString
+		case inString:
+		case c == '(':
+			depth++
+		case c == ')':
+			if depth--; depth == 0 && i != len(s)-1 {
+				return false
+			}
+		}
+	}
+	return depth == 0
+}
@@ -0,0 +1,113 @@
+package dialect_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/naoina/migu/dialect"
+)
+
+func TestMSSQLColumnType(t *testing.T) {
+	d := dialect.NewMSSQL(nil)
+	for _, v := range []struct {
+		name   string
+		expect string
+	}{
+		{"string", "NVARCHAR(255)"},
+		{"int", "INT"},
+		{"int64", "BIGINT"},
+		{"uint8", "TINYINT"},
+		{"uint64", "DECIMAL(20,0)"},
+		{"bool", "BIT"},
+		{"float64", "FLOAT"},
+		{"time.Time", "DATETIME2"},
+		{"[]byte", "VARBINARY(MAX)"},
+		{"nvarchar", "NVARCHAR(1)"},
+		{"nvarchar(max)", "NVARCHAR(MAX)"},
+		{"decimal", "DECIMAL(18,0)"},
+		{"decimal(10, 2)", "DECIMAL(10,2)"},
+		{"float(24)", "REAL"},
+		{"datetime2(7)", "DATETIME2"},
+		{"datetime2(3)", "DATETIME2(3)"},
+		{"integer", "INT"},
+	} {
+		if actual := d.ColumnType(v.name); actual != v.expect {
+			t.Errorf("ColumnType(%q) => %q; want %q", v.name, actual, v.expect)
+		}
+	}
+	for _, v := range []struct {
+		name     string
+		nullable bool
+		expect   string
+	}{
+		{"NVARCHAR(255)", false, "string"},
+		{"NVARCHAR(MAX)", true, "*string"},
+		{"INT", false, "int"},
+		{"BIGINT", true, "*int64"},
+		{"DECIMAL(20,0)", false, "uint64"},
+		{"DECIMAL(10,2)", false, "float64"},
+		{"DATETIME2(3)", false, "time.Time"},
+		{"UNIQUEIDENTIFIER", false, "interface{}"},
+	} {
+		if actual := d.GoType(v.name, v.nullable); actual != v.expect {
+			t.Errorf("GoType(%q, %v) => %q; want %q", v.name, v.nullable, actual, v.expect)
+		}
+	}
+}
+
+func TestMSSQLSQL(t *testing.T) {
+	d := dialect.NewMSSQL(nil, dialect.WithSchema("app"))
+	id := dialect.Field{Table: "user", Name: "id", Type: "BIGINT", AutoIncrement: true}
+	name := dialect.Field{Table: "user", Name: "name", Type: "NVARCHAR(255)", Default: "it's", Comment: "user's name"}
+	for _, v := range []struct {
+		actual []string
+		expect []string
+	}{
+		{
+			d.CreateTableSQL(dialect.Table{Name: "user", Fields: []dialect.Field{id, name}, PrimaryKeys: []string{"id"}}),
+			[]string{
+				"CREATE TABLE [app].[user] (\n" +
+					"  [id] BIGINT IDENTITY(1,1) NOT NULL,\n" +
+					"  [name] NVARCHAR(255) NOT NULL CONSTRAINT [DF_user_name] DEFAULT N'it''s',\n" +
+					"  CONSTRAINT [PK_user] PRIMARY KEY ([id])\n" +
+					")",
+				"EXEC sp_addextendedproperty @name = N'MS_Description', @value = N'user''s name', @level0type = N'SCHEMA', @level0name = N'app', @level1type = N'TABLE', @level1name = N'user', @level2type = N'COLUMN', @level2name = N'name'",
+			},
+		},
+		{
+			d.ModifyColumnSQL(name, dialect.Field{Table: "user", Name: "full_name", Type: "NVARCHAR(MAX)", Nullable: true}),
+			[]string{
+				"EXEC sp_rename N'[app].[user].[name]', N'full_name', 'COLUMN'",
+				"ALTER TABLE [app].[user] ALTER COLUMN [full_name] NVARCHAR(MAX) NULL",
+				"DECLARE @sql NVARCHAR(MAX) = N''; SELECT @sql += N'ALTER TABLE [app].[user] DROP CONSTRAINT ' + QUOTENAME(name) + N';' FROM (SELECT name FROM sys.default_constraints WHERE parent_object_id = OBJECT_ID(N'[app].[user]') AND parent_column_id = COLUMNPROPERTY(OBJECT_ID(N'[app].[user]'), N'full_name', 'ColumnId')) c; EXEC sp_executesql @sql",
+				"EXEC sp_dropextendedproperty @name = N'MS_Description', @level0type = N'SCHEMA', @level0name = N'app', @level1type = N'TABLE', @level1name = N'user', @level2type = N'COLUMN', @level2name = N'full_name'",
+			},
+		},
+		{
+			d.(dialect.PrimaryKeyModifier).ModifyPrimaryKeySQL([]dialect.Field{id}, []dialect.Field{id, name}),
+			[]string{
+				"DECLARE @sql NVARCHAR(MAX) = N''; SELECT @sql += N'ALTER TABLE [app].[user] DROP CONSTRAINT ' + QUOTENAME(name) + N';' FROM (SELECT name FROM sys.key_constraints WHERE type = 'PK' AND parent_object_id = OBJECT_ID(N'[app].[user]')) c; EXEC sp_executesql @sql",
+				"ALTER TABLE [app].[user] ADD CONSTRAINT [PK_user] PRIMARY KEY ([id], [name])",
+			},
+		},
+		{
+			d.DropIndexSQL(dialect.Index{Table: "user", Name: "user_name"}),
+			[]string{
+				"DROP INDEX [user_name] ON [app].[user]",
+			},
+		},
+		{
+			d.(dialect.TableRenamer).RenameTableSQL("user", "member"),
+			[]string{
+				"EXEC sp_rename N'[app].[user]', N'member'",
+			},
+		},
+	} {
+		if diff := cmp.Diff(v.actual, v.expect); diff != "" {
+			t.Errorf("(-got +want)\n%v", diff)
+		}
+	}
+	if actual, expect := d.Quote("a]b"), "[a]]b]"; actual != expect {
+		t.Errorf("Quote(%q) => %q; want %q", "a]b", actual, expect)
+	}
+}
@@ -1,13 +1,75 @@
 package dialect
 
 import (
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+var (
+	_ PrimaryKeyModifier   = &MySQL{}
+	_ StatelessGenerator   = &MySQL{}
+	_ ColumnSchemaStreamer = &MySQL{}
+	_ Estimator            = &MySQL{}
+	_ IndexAdvisor         = &MySQL{}
+	_ IndexReader          = &MySQL{}
+	_ IndexStatistician    = &MySQL{}
+	_ HealthChecker        = &MySQL{}
+	_ RowReader            = &MySQL{}
+	_ TableEncrypter       = &MySQL{}
+	_ TablePersister       = &MySQL{}
+	_ TableCompressor      = &MySQL{}
+	_ ProgressReporter     = &MySQL{}
+	_ UserManager          = &MySQL{}
+	_ UserReader           = &MySQL{}
+	_ DatabaseCreator      = &MySQL{}
+	_ DatabaseAlterer      = &MySQL{}
+	_ TableRenamer         = &MySQL{}
+	_ ColumnRenamer        = &MySQL{}
+	_ TableAnalyzer        = &MySQL{}
+	_ DegradationReporter  = &MySQL{}
+	_ PrivilegeReader      = &MySQL{}
+	_ ServerVersioner      = &MySQL{}
+	_ LockBlockerReader    = &MySQL{}
+	_ RowSizeLimiter       = &MySQL{}
+	_ CharsetConverter     = &MySQL{}
+	_ EnumTyper            = &MySQL{}
+	_ SpatialTyper         = &MySQL{}
+	_ GoTypeImporter       = &MySQL{}
+	_ OnUpdater            = &MySQL{}
+	_ OrphanReader         = &MySQL{}
 )
 
-var _ PrimaryKeyModifier = &MySQL{}
+const (
+	// mysqlMaxRowSize is the maximum row size of MySQL regardless of the storage engine.
+	mysqlMaxRowSize = 65535
+
+	// innodbMaxInlineRowSize is the maximum size of a row in a page of InnoDB with the default 16KB page size.
+	innodbMaxInlineRowSize = 8126
+)
+
+// mysqlCharWidths is the maximum number of bytes of a character of the character sets.
+var mysqlCharWidths = map[string]int{
+	"ascii":   1,
+	"binary":  1,
+	"latin1":  1,
+	"ucs2":    2,
+	"utf8":    3,
+	"utf8mb3": 3,
+	"utf8mb4": 4,
+	"utf16":   4,
+	"utf32":   4,
+}
 
 var (
 	mysqlColumnTypes = []*ColumnType{
@@ -57,6 +119,11 @@ var (
 			GoTypes:         []string{"time.Time"},
 			GoNullableTypes: []string{"*time.Time", "mysql.NullTime", "gorp.NullTime"},
 		},
+		{
+			Types:           []string{"JSON"},
+			GoTypes:         []string{"json.RawMessage"},
+			GoNullableTypes: []string{"json.RawMessage"},
+		},
 	}
 )
 
@@ -67,9 +134,14 @@ type MySQL struct {
 	opt             *option
 	columnTypeMap   map[string]*ColumnType
 	nullableTypeMap map[string]struct{}
+	degradations    []Degradation
 }
 
 func NewMySQL(db *sql.DB, opts ...Option) Dialect {
+	return newMySQL(db, opts...)
+}
+
+func newMySQL(db *sql.DB, opts ...Option) *MySQL {
 	d := &MySQL{
 		db:              db,
 		opt:             newOption(),
@@ -93,17 +165,66 @@ func NewMySQL(db *sql.DB, opts ...Option) Dialect {
 }
 
 func (d *MySQL) ColumnSchema(tables ...string) ([]ColumnSchema, error) {
+	var schemas []ColumnSchema
+	if err := d.columnSchema(context.Background(), tables, func(schema *mysqlColumnSchema) error {
+		schemas = append(schemas, schema)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return schemas, nil
+}
+
+func (d *MySQL) StreamColumnSchema(ctx context.Context, fn func(columns []ColumnSchema) error) error {
+	var columns []ColumnSchema
+	if err := d.columnSchema(ctx, nil, func(schema *mysqlColumnSchema) error {
+		if len(columns) > 0 && columns[0].TableName() != schema.tableName {
+			if err := fn(columns); err != nil {
+				return err
+			}
+			columns = nil
+		}
+		columns = append(columns, schema)
+		return nil
+	}); err != nil {
+		return err
+	}
+	if len(columns) > 0 {
+		return fn(columns)
+	}
+	return nil
+}
+
+// columnSchema calls fn with the schema of each column of the tables in the order of the table names.
+func (d *MySQL) columnSchema(ctx context.Context, tables []string, fn func(schema *mysqlColumnSchema) error) error {
 	dbname, err := d.currentDBName()
 	if err != nil {
-		return nil, err
+		return err
 	}
 	version, err := d.dbVersion()
 	if err != nil {
-		return nil, err
+		return err
 	}
 	indexMap, err := d.getIndexMap()
 	if err != nil {
-		return nil, err
+		if !isMySQLAccessDenied(err) {
+			return err
+		}
+		d.degrade(FeatureIndexes, err)
+	}
+	var jsonColumns map[string]map[string]bool
+	if version.isMariaDB() && version.atLeast(10, 2, 22) {
+		if jsonColumns, err = d.mariaDBJSONColumns(dbname); err != nil {
+			if !isMySQLAccessDenied(err) {
+				return err
+			}
+			d.degrade(FeatureCheckConstraints, err)
+		}
+	}
+	// The spatial reference systems of the columns are supported as of MySQL 8.0.3.
+	srsID := "NULL"
+	if !version.isMariaDB() && version.atLeast(8, 0, 3) {
+		srsID = "SRS_ID"
 	}
 	parts := []string{
 		"SELECT",
@@ -120,11 +241,20 @@ func (d *MySQL) ColumnSchema(tables ...string) ([]ColumnSchema, error) {
 		"  COLUMN_TYPE,",
 		"  COLUMN_KEY,",
 		"  EXTRA,",
-		"  COLUMN_COMMENT",
+		"  COLUMN_COMMENT,",
+		"  CHARACTER_SET_NAME,",
+		"  COLLATION_NAME,",
+		"  (SELECT TABLE_COLLATION FROM information_schema.TABLES t WHERE t.TABLE_SCHEMA = COLUMNS.TABLE_SCHEMA AND t.TABLE_NAME = COLUMNS.TABLE_NAME),",
+		"  " + srsID,
 		"FROM information_schema.COLUMNS",
 		"WHERE TABLE_SCHEMA = ?",
 	}
 	args := []interface{}{dbname}
+	if version.isMariaDB() && version.atLeast(10, 3, 0) {
+		// The sequences of MariaDB are the tables that have the fixed columns.
+		parts = append(parts, "AND TABLE_NAME NOT IN (SELECT TABLE_NAME FROM information_schema.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_TYPE = 'SEQUENCE')")
+		args = append(args, dbname)
+	}
 	if len(tables) > 0 {
 		placeholder := strings.Repeat(",?", len(tables))
 		placeholder = placeholder[1:] // truncate the heading comma.
@@ -135,12 +265,11 @@ func (d *MySQL) ColumnSchema(tables ...string) ([]ColumnSchema, error) {
 	}
 	parts = append(parts, "ORDER BY TABLE_NAME, ORDINAL_POSITION")
 	query := strings.Join(parts, "\n")
-	rows, err := d.db.Query(query, args...)
+	rows, err := d.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
-	var schemas []ColumnSchema
 	for rows.Next() {
 		schema := &mysqlColumnSchema{
 			version: version,
@@ -160,8 +289,17 @@ func (d *MySQL) ColumnSchema(tables ...string) ([]ColumnSchema, error) {
 			&schema.columnKey,
 			&schema.extra,
 			&schema.columnComment,
+			&schema.characterSetName,
+			&schema.collationName,
+			&schema.tableCollation,
+			&schema.srsID,
 		); err != nil {
-			return nil, err
+			return err
+		}
+		schema.json = jsonColumns[schema.tableName][schema.columnName]
+		if indexMap == nil && schema.columnKey == "PRI" {
+			// The primary key is guessed from the column key if the indexes cannot be read.
+			schema.indexName = "PRIMARY"
 		}
 		if tableIndex, exists := indexMap[schema.tableName]; exists {
 			if info, exists := tableIndex[schema.columnName]; exists {
@@ -169,12 +307,11 @@ func (d *MySQL) ColumnSchema(tables ...string) ([]ColumnSchema, error) {
 				schema.indexName = info.IndexName
 			}
 		}
-		schemas = append(schemas, schema)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+		if err := fn(schema); err != nil {
+			return err
+		}
 	}
-	return schemas, nil
+	return rows.Err()
 }
 
 func (d *MySQL) ColumnType(name string) string {
@@ -192,12 +329,22 @@ func (d *MySQL) ColumnType(name string) string {
 func (d *MySQL) GoType(name string, nullable bool) string {
 	name = strings.ToUpper(name)
 	var unsigned bool
-	if i := strings.IndexByte(name, ' '); i >= 0 {
+	// The elements of ENUM and SET may contain the spaces.
+	if i := strings.IndexByte(name[strings.LastIndexByte(name, ')')+1:], ' '); i >= 0 {
+		i += strings.LastIndexByte(name, ')') + 1
 		name, unsigned = name[:i], name[i+1:] == "UNSIGNED"
 	}
-	for _, t := range mysqlColumnTypes {
-		if typ, found := t.findGoType(name, nullable, unsigned); found {
-			return typ
+	if SpatialTypes[name] {
+		// The values of the spatial columns are read in the internal format of MySQL, which is the SRID in 4 bytes
+		// followed by WKB.
+		return "[]byte"
+	}
+	// The custom column types have priority over the built-in ones.
+	for _, types := range [][]*ColumnType{d.opt.columnTypes, mysqlColumnTypes} {
+		for _, t := range types {
+			if typ, found := t.findGoType(name, nullable, unsigned); found {
+				return typ
+			}
 		}
 	}
 	if strings.IndexByte(name, '(') >= 0 {
@@ -216,15 +363,23 @@ func (d *MySQL) ImportPackage(schema ColumnSchema) string {
 	case "datetime":
 		return "time"
 	}
+	// JSON of MariaDB is LONGTEXT whose column type is json.
+	if schema.ColumnType() == "json" {
+		return "encoding/json"
+	}
 	return ""
 }
 
+func (d *MySQL) GoTypeImports() ([]Import, error) {
+	return goTypeImports(d.opt.columnTypes)
+}
+
 func (d *MySQL) Quote(s string) string {
-	return fmt.Sprintf("`%s`", strings.Replace(s, "`", "``", -1))
+	return quoteByDoubling(s, "`", false)
 }
 
 func (d *MySQL) QuoteString(s string) string {
-	return fmt.Sprintf("'%s'", strings.Replace(s, "'", "''", -1))
+	return quoteByDoubling(s, "'", true)
 }
 
 func (d *MySQL) CreateTableSQL(table Table) []string {
@@ -239,9 +394,19 @@ func (d *MySQL) CreateTableSQL(table Table) []string {
 		}
 		columns = append(columns, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(pkColumns, ", ")))
 	}
-	query := fmt.Sprintf("CREATE TABLE %s (\n"+
+	create := "CREATE TABLE"
+	if table.Persistence == PersistenceTemporary {
+		create = "CREATE TEMPORARY TABLE"
+	}
+	query := fmt.Sprintf("%s %s (\n"+
 		"  %s\n"+
-		")", d.Quote(table.Name), strings.Join(columns, ",\n  "))
+		")", create, d.Quote(table.Name), strings.Join(columns, ",\n  "))
+	if table.Encrypted {
+		query += " ENCRYPTION='Y'"
+	}
+	if table.Compression != "" {
+		query += " COMPRESSION=" + d.QuoteString(table.Compression)
+	}
 	if table.Option != "" {
 		query += " " + table.Option
 	}
@@ -260,6 +425,10 @@ func (d *MySQL) ModifyColumnSQL(oldField, newField Field) []string {
 	return []string{fmt.Sprintf("ALTER TABLE %s CHANGE %s %s", d.Quote(newField.Table), d.Quote(oldField.Name), d.columnSQL(newField))}
 }
 
+func (d *MySQL) RenameColumnSQL(oldField, newField Field) []string {
+	return d.ModifyColumnSQL(oldField, newField)
+}
+
 func (d *MySQL) ModifyPrimaryKeySQL(oldPrimaryKeys, newPrimaryKeys []Field) []string {
 	var tableName string
 	if len(newPrimaryKeys) > 0 {
@@ -279,6 +448,19 @@ func (d *MySQL) ModifyPrimaryKeySQL(oldPrimaryKeys, newPrimaryKeys []Field) []st
 	return []string{fmt.Sprintf("ALTER TABLE %s %s", d.Quote(tableName), strings.Join(specs, ", "))}
 }
 
+func (d *MySQL) RenameTableSQL(oldName, newName string) []string {
+	return []string{fmt.Sprintf("RENAME TABLE %s TO %s", d.Quote(oldName), d.Quote(newName))}
+}
+
+// IsStateless returns true since the SQLs of each change are built only from the change.
+func (d *MySQL) IsStateless() bool {
+	return true
+}
+
+func (d *MySQL) AnalyzeTableSQL(table string) []string {
+	return []string{fmt.Sprintf("ANALYZE TABLE %s", d.Quote(table))}
+}
+
 func (d *MySQL) CreateIndexSQL(index Index) []string {
 	columns := make([]string, len(index.Columns))
 	for i, c := range index.Columns {
@@ -299,30 +481,63 @@ func (d *MySQL) DropIndexSQL(index Index) []string {
 
 func (d *MySQL) columnSQL(f Field) string {
 	column := []string{d.Quote(f.Name), f.Type}
+	if f.Charset != "" {
+		column = append(column, "CHARACTER SET", f.Charset)
+	}
+	if f.Collation != "" {
+		column = append(column, "COLLATE", f.Collation)
+	}
+	if f.Compressed && d.version.isMariaDB() {
+		// The compressed columns of MariaDB have the attribute right after the type.
+		column = append(column, "COMPRESSED")
+	}
 	if !f.Nullable {
 		column = append(column, "NOT NULL")
 	}
-	if def := f.Default; def != "" {
-		if d.isTextType(f) {
-			def = d.QuoteString(def)
-		}
-		column = append(column, "DEFAULT", def)
+	if f.Default != "" {
+		column = append(column, "DEFAULT", d.defaultSQL(f))
 	}
 	if f.AutoIncrement {
 		column = append(column, "AUTO_INCREMENT")
 	}
+	if f.OnUpdate != "" {
+		column = append(column, d.OnUpdateClause(f.OnUpdate))
+	}
 	if f.Extra != "" {
 		column = append(column, f.Extra)
 	}
+	if f.Compressed && !d.version.isMariaDB() {
+		// The compressed columns of Percona Server.
+		column = append(column, "COLUMN_FORMAT COMPRESSED")
+	}
 	if f.Comment != "" {
 		column = append(column, "COMMENT", d.QuoteString(f.Comment))
 	}
 	return strings.Join(column, " ")
 }
 
+func (d *MySQL) OnUpdateClause(expr string) string {
+	return "ON UPDATE " + expr
+}
+
+// mysqlDefaultExpressionRE matches the defaults that are embedded into SQL as they are: the numbers, the bit-value and
+// the hexadecimal literals, the quoted strings, NULL, TRUE, FALSE, the current timestamps and the expressions in
+// parentheses of MySQL 8.0.13 or later.
+var mysqlDefaultExpressionRE = regexp.MustCompile(`(?i)^(?:[+-]?(?:\d+(?:\.\d*)?|\.\d+)(?:e[+-]?\d+)?|0x[0-9a-f]+|[bx]'[0-9a-f]*'|'(?:[^'\\]|''|\\.)*'|NULL|TRUE|FALSE|(?:CURRENT_TIMESTAMP|NOW|LOCALTIME|LOCALTIMESTAMP|CURRENT_DATE|CURDATE|CURRENT_TIME|CURTIME)(?:\(\s*\d*\s*\))?|\(.*\))$`)
+
+// defaultSQL returns the default value of the column in SQL. The defaults of the string, the enum and the set types
+// are always quoted, and the ones of the other types are quoted unless they are the numbers or the expressions, such
+// as the dates of the temporal types.
+func (d *MySQL) defaultSQL(f Field) string {
+	if d.isTextType(f) || !mysqlDefaultExpressionRE.MatchString(f.Default) {
+		return d.QuoteString(f.Default)
+	}
+	return f.Default
+}
+
 func (d *MySQL) isTextType(f Field) bool {
 	typ := strings.ToUpper(f.Type)
-	for _, t := range []string{"VARCHAR", "CHAR", "TEXT", "MIDIUMTEXT", "LONGTEXT"} {
+	for _, t := range []string{"VARCHAR", "CHAR", "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT", "ENUM", "SET"} {
 		if strings.HasPrefix(typ, t) {
 			return true
 		}
@@ -340,156 +555,1484 @@ func (d *MySQL) Begin() (Transactioner, error) {
 	}, nil
 }
 
-func (d *MySQL) defaultColumnType(name string) string {
-	switch name := strings.ToUpper(name); name {
-	case "BIT":
-		return "BIT(1)"
-	case "DECIMAL":
-		return "DECIMAL(10,0)"
-	case "VARCHAR":
-		return "VARCHAR(255)"
-	case "VARBINARY":
-		return "VARBINARY(255)"
-	case "CHAR":
-		return "CHAR(1)"
-	case "BINARY":
-		return "BINARY(1)"
-	case "YEAR":
-		return "YEAR(4)"
+// Progress returns the progress of the running ALTER TABLE statements of InnoDB from the stage events of
+// performance_schema. The stage instruments "stage/innodb/alter%" and the consumers "events_stages_%" must be
+// enabled to report the progress, otherwise it returns nothing.
+func (d *MySQL) Progress(ctx context.Context) ([]Progress, error) {
+	query := strings.Join([]string{
+		"SELECT",
+		"  t.PROCESSLIST_INFO,",
+		"  s.EVENT_NAME,",
+		"  s.WORK_COMPLETED,",
+		"  s.WORK_ESTIMATED,",
+		"  s.TIMER_WAIT",
+		"FROM performance_schema.events_stages_current AS s",
+		"INNER JOIN performance_schema.threads AS t ON t.THREAD_ID = s.THREAD_ID",
+		"WHERE s.EVENT_NAME LIKE 'stage/innodb/alter%'",
+		"ORDER BY s.THREAD_ID",
+	}, "\n")
+	rows, err := d.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
 	}
-	return name
-}
-
-func (d *MySQL) currentDBName() (string, error) {
-	if d.dbName != "" {
-		return d.dbName, nil
+	defer rows.Close()
+	var progresses []Progress
+	for rows.Next() {
+		var (
+			p                          Progress
+			info                       sql.NullString
+			completed, estimated, wait sql.NullInt64
+		)
+		if err := rows.Scan(&info, &p.Stage, &completed, &estimated, &wait); err != nil {
+			return nil, err
+		}
+		p.Statement = info.String
+		p.WorkCompleted, p.WorkEstimated = completed.Int64, estimated.Int64
+		// TIMER_WAIT is in picoseconds.
+		p.Elapsed = time.Duration(wait.Int64/1000) * time.Nanosecond
+		progresses = append(progresses, p)
 	}
-	err := d.db.QueryRow(`SELECT DATABASE()`).Scan(&d.dbName)
-	return d.dbName, err
+	return progresses, rows.Err()
 }
 
-func (d *MySQL) dbVersion() (*mysqlVersion, error) {
-	if d.version != nil {
-		return d.version, nil
-	}
-	var version string
-	if err := d.db.QueryRow(`SELECT VERSION()`).Scan(&version); err != nil {
+func (d *MySQL) TableEncryptions(tables ...string) (map[string]bool, error) {
+	dbname, err := d.currentDBName()
+	if err != nil {
 		return nil, err
 	}
-	vs := strings.Split(version, "-")
-	vStr := vs[0]
-	var v mysqlVersion
-	if len(vs) > 1 {
-		v.Name = vs[1]
+	parts := []string{
+		"SELECT",
+		"  TABLE_NAME,",
+		"  CREATE_OPTIONS",
+		"FROM information_schema.TABLES",
+		"WHERE TABLE_SCHEMA = ? AND TABLE_TYPE = 'BASE TABLE'",
 	}
-	versions := strings.Split(vStr, ".")
-	var err error
-	if v.Major, err = strconv.Atoi(versions[0]); err != nil {
-		return nil, err
+	args := []interface{}{dbname}
+	if len(tables) > 0 {
+		placeholder := strings.Repeat(",?", len(tables))
+		placeholder = placeholder[1:] // truncate the heading comma.
+		parts = append(parts, fmt.Sprintf("AND TABLE_NAME IN (%s)", placeholder))
+		for _, t := range tables {
+			args = append(args, t)
+		}
 	}
-	if v.Minor, err = strconv.Atoi(versions[1]); err != nil {
+	rows, err := d.db.Query(strings.Join(parts, "\n"), args...)
+	if err != nil {
+		if isMySQLAccessDenied(err) {
+			d.degrade(FeatureEncryption, err)
+			return map[string]bool{}, nil
+		}
 		return nil, err
 	}
-	if v.Patch, err = strconv.Atoi(versions[2]); err != nil {
-		return nil, err
+	defer rows.Close()
+	encryptions := map[string]bool{}
+	for rows.Next() {
+		var (
+			tableName     string
+			createOptions sql.NullString
+		)
+		if err := rows.Scan(&tableName, &createOptions); err != nil {
+			return nil, err
+		}
+		encryptions[tableName] = strings.Contains(strings.ToUpper(createOptions.String), "ENCRYPTION='Y'")
 	}
-	d.version = &v
-	return d.version, err
+	return encryptions, rows.Err()
 }
 
-func (d *MySQL) getIndexMap() (map[string]map[string]mysqlIndexInfo, error) {
+func (d *MySQL) ModifyTableEncryptionSQL(table string, encrypted bool) []string {
+	encryption := "N"
+	if encrypted {
+		encryption = "Y"
+	}
+	return []string{fmt.Sprintf("ALTER TABLE %s ENCRYPTION=%s", d.Quote(table), d.QuoteString(encryption))}
+}
+
+// TableCompressions returns the algorithm of the InnoDB page compression of each table.
+func (d *MySQL) TableCompressions(tables ...string) (map[string]string, error) {
 	dbname, err := d.currentDBName()
 	if err != nil {
 		return nil, err
 	}
-	query := strings.Join([]string{
+	parts := []string{
 		"SELECT",
 		"  TABLE_NAME,",
-		"  COLUMN_NAME,",
-		"  NON_UNIQUE,",
-		"  INDEX_NAME",
-		"FROM information_schema.STATISTICS",
-		"WHERE TABLE_SCHEMA = ?",
-	}, "\n")
-	rows, err := d.db.Query(query, dbname)
+		"  CREATE_OPTIONS",
+		"FROM information_schema.TABLES",
+		"WHERE TABLE_SCHEMA = ? AND TABLE_TYPE = 'BASE TABLE'",
+	}
+	args := []interface{}{dbname}
+	if len(tables) > 0 {
+		placeholder := strings.Repeat(",?", len(tables))
+		placeholder = placeholder[1:] // truncate the heading comma.
+		parts = append(parts, fmt.Sprintf("AND TABLE_NAME IN (%s)", placeholder))
+		for _, t := range tables {
+			args = append(args, t)
+		}
+	}
+	rows, err := d.db.Query(strings.Join(parts, "\n"), args...)
 	if err != nil {
+		if isMySQLAccessDenied(err) {
+			d.degrade(FeatureCompression, err)
+			return map[string]string{}, nil
+		}
 		return nil, err
 	}
 	defer rows.Close()
-	indexMap := make(map[string]map[string]mysqlIndexInfo)
+	compressions := map[string]string{}
 	for rows.Next() {
 		var (
-			tableName  string
-			columnName string
-			index      mysqlIndexInfo
+			tableName     string
+			createOptions sql.NullString
 		)
-		if err := rows.Scan(&tableName, &columnName, &index.NonUnique, &index.IndexName); err != nil {
+		if err := rows.Scan(&tableName, &createOptions); err != nil {
 			return nil, err
 		}
-		if _, exists := indexMap[tableName]; !exists {
-			indexMap[tableName] = make(map[string]mysqlIndexInfo)
-		}
-		indexMap[tableName][columnName] = index
+		compressions[tableName] = mysqlCompression(createOptions.String)
 	}
-	return indexMap, rows.Err()
+	return compressions, rows.Err()
 }
 
-type mysqlIndexInfo struct {
-	NonUnique int64
-	IndexName string
+func (d *MySQL) ModifyTableCompressionSQL(table string, compression string) []string {
+	return []string{fmt.Sprintf("ALTER TABLE %s COMPRESSION=%s", d.Quote(table), d.QuoteString(compression))}
 }
 
-type mysqlVersion struct {
-	Major int
-	Minor int
-	Patch int
-	Name  string
+// SupportsPersistence reports whether the persistence is PersistenceTemporary, because MySQL does not have the
+// unlogged tables.
+func (d *MySQL) SupportsPersistence(persistence string) bool {
+	return persistence == PersistenceTemporary
 }
 
-type mysqlTransaction struct {
-	tx *sql.Tx
+// CreateDatabase creates the database. The connection of the dialect does not need to select the database.
+func (d *MySQL) CreateDatabase(name string, opts DatabaseOptions) (bool, error) {
+	var n int64
+	if err := d.db.QueryRow("SELECT COUNT(*) FROM information_schema.SCHEMATA WHERE SCHEMA_NAME = ?", name).Scan(&n); err != nil {
+		return false, err
+	}
+	if n > 0 {
+		return false, nil
+	}
+	for _, s := range []string{opts.Charset, opts.Collation} {
+		for _, c := range s {
+			if !(c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')) {
+				return false, fmt.Errorf("invalid character set or collation: %q", s)
+			}
+		}
+	}
+	query := fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s", d.Quote(name))
+	if _, err := d.db.Exec(query + d.databaseOptionsSQL(opts)); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
-func (m *mysqlTransaction) Exec(sql string, args ...interface{}) error {
-	_, err := m.tx.Exec(sql, args...)
-	return err
+// DatabaseOptions returns the default character set, collation and encryption of the database. Encryption is nil for
+// MariaDB and the versions of MySQL before 8.0.16, which have no default encryption of the databases.
+func (d *MySQL) DatabaseOptions() (DatabaseOptions, error) {
+	version, err := d.dbVersion()
+	if err != nil {
+		return DatabaseOptions{}, err
+	}
+	var opts DatabaseOptions
+	query := "SELECT DEFAULT_CHARACTER_SET_NAME, DEFAULT_COLLATION_NAME FROM information_schema.SCHEMATA WHERE SCHEMA_NAME = DATABASE()"
+	if version.isMariaDB() || !version.atLeast(8, 0, 16) {
+		err := d.db.QueryRow(query).Scan(&opts.Charset, &opts.Collation)
+		return opts, err
+	}
+	query = strings.Replace(query, "DEFAULT_COLLATION_NAME", "DEFAULT_COLLATION_NAME, DEFAULT_ENCRYPTION", 1)
+	var encryption string
+	if err := d.db.QueryRow(query).Scan(&opts.Charset, &opts.Collation, &encryption); err != nil {
+		return DatabaseOptions{}, err
+	}
+	encrypted := encryption == "YES"
+	opts.Encryption = &encrypted
+	return opts, nil
 }
 
-func (m *mysqlTransaction) Commit() error {
-	return m.tx.Commit()
+// AlterDatabaseSQL returns ALTER DATABASE statement of the database that the connection selects.
+func (d *MySQL) AlterDatabaseSQL(opts DatabaseOptions) []string {
+	return []string{"ALTER DATABASE" + d.databaseOptionsSQL(opts)}
 }
 
-func (m *mysqlTransaction) Rollback() error {
-	return m.tx.Rollback()
+func (d *MySQL) databaseOptionsSQL(opts DatabaseOptions) string {
+	var sql string
+	if opts.Charset != "" {
+		sql += " CHARACTER SET " + opts.Charset
+	}
+	if opts.Collation != "" {
+		sql += " COLLATE " + opts.Collation
+	}
+	if opts.Encryption != nil {
+		encryption := "N"
+		if *opts.Encryption {
+			encryption = "Y"
+		}
+		sql += " ENCRYPTION " + d.QuoteString(encryption)
+	}
+	return sql
 }
 
-func trimParens(s string) string {
-	start, end := -1, -1
-	for i := 0; i < len(s); i++ {
-		c := s[i]
-		if c == '(' {
-			start = i
-			continue
+func (d *MySQL) CreateUserSQL(user User) []string {
+	if user.Role {
+		return []string{fmt.Sprintf("CREATE ROLE IF NOT EXISTS %s", d.userName(user))}
+	}
+	sqls := []string{fmt.Sprintf("CREATE USER IF NOT EXISTS %s IDENTIFIED BY %s", d.userName(user), d.QuoteString(user.Password))}
+	return append(sqls, d.AlterUserSQL(user, true)...)
+}
+
+func (d *MySQL) AlterUserSQL(user User, changePassword bool) []string {
+	name := d.userName(user)
+	var sqls []string
+	if changePassword && !user.Role {
+		sqls = append(sqls, fmt.Sprintf("ALTER USER %s IDENTIFIED BY %s", name, d.QuoteString(user.Password)))
+	}
+	if len(user.Roles) > 0 {
+		roles := make([]string, len(user.Roles))
+		for i, role := range user.Roles {
+			roles[i] = d.QuoteString(role)
 		}
-		if c == ')' {
-			end = i
-			break
+		sqls = append(sqls,
+			fmt.Sprintf("GRANT %s TO %s", strings.Join(roles, ", "), name),
+			fmt.Sprintf("SET DEFAULT ROLE ALL TO %s", name))
+	}
+	return sqls
+}
+
+// ReadUser reads the user from mysql.user, the roles from mysql.role_edges, and the privileges on the current
+// database and its tables from information_schema.
+func (d *MySQL) ReadUser(user User) (*UserState, error) {
+	host := user.Host
+	if user.Role || host == "" {
+		host = "%"
+	}
+	var plugin string
+	var auth sql.NullString
+	if err := d.db.QueryRow("SELECT plugin, authentication_string FROM mysql.user WHERE User = ? AND Host = ?", user.Name, host).Scan(&plugin, &auth); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
 		}
+		return nil, err
 	}
-	if start < 0 || end < 0 {
-		return s
+	state := &UserState{PasswordMatches: user.Role || mysqlPasswordMatches(plugin, auth.String, user.Password)}
+	if len(user.Roles) > 0 {
+		rows, err := d.db.Query("SELECT FROM_USER FROM mysql.role_edges WHERE TO_USER = ? AND TO_HOST = ?", user.Name, host)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var role string
+			if err := rows.Scan(&role); err != nil {
+				return nil, err
+			}
+			state.Roles = append(state.Roles, role)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
 	}
-	return s[:start] + s[end+1:]
+	grantee := "'" + user.Name + "'@'" + host + "'"
+	privileges := map[string][]string{}
+	var targets []string
+	for _, query := range []string{
+		"SELECT '*', PRIVILEGE_TYPE FROM information_schema.SCHEMA_PRIVILEGES WHERE GRANTEE = ? AND TABLE_SCHEMA = DATABASE()",
+		"SELECT TABLE_NAME, PRIVILEGE_TYPE FROM information_schema.TABLE_PRIVILEGES WHERE GRANTEE = ? AND TABLE_SCHEMA = DATABASE() ORDER BY TABLE_NAME",
+	} {
+		if err := func() error {
+			rows, err := d.db.Query(query, grantee)
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+			for rows.Next() {
+				var on, privilege string
+				if err := rows.Scan(&on, &privilege); err != nil {
+					return err
+				}
+				if _, ok := privileges[on]; !ok {
+					targets = append(targets, on)
+				}
+				privileges[on] = append(privileges[on], strings.ToUpper(privilege))
+			}
+			return rows.Err()
+		}(); err != nil {
+			return nil, err
+		}
+	}
+	for _, on := range targets {
+		all := mysqlTablePrivileges
+		if on == "*" {
+			all = mysqlDatabasePrivileges
+		}
+		if containsAll(privileges[on], all) {
+			privileges[on] = append(privileges[on], "ALL", "ALL PRIVILEGES")
+		}
+		state.Grants = append(state.Grants, Grant{Privileges: privileges[on], On: on})
+	}
+	return state, nil
 }
 
-var _ ColumnSchema = &mysqlColumnSchema{}
+func (d *MySQL) GrantSQL(user User, grant Grant) []string {
+	on := "*"
+	if grant.On != "*" {
+		on = d.Quote(grant.On)
+	}
+	return []string{fmt.Sprintf("GRANT %s ON %s TO %s", strings.Join(grant.Privileges, ", "), on, d.userName(user))}
+}
 
-type mysqlColumnSchema struct {
-	tableName              string
-	columnName             string
-	ordinalPosition        int64
-	columnDefault          sql.NullString
-	isNullable             string
+var (
+	// mysqlDatabasePrivileges are the privileges that are granted by GRANT ALL on the database.
+	mysqlDatabasePrivileges = []string{
+		"SELECT", "INSERT", "UPDATE", "DELETE", "CREATE", "DROP", "REFERENCES", "INDEX", "ALTER", "CREATE TEMPORARY TABLES",
+		"LOCK TABLES", "EXECUTE", "CREATE VIEW", "SHOW VIEW", "CREATE ROUTINE", "ALTER ROUTINE", "EVENT", "TRIGGER",
+	}
+
+	// mysqlTablePrivileges are the privileges that are granted by GRANT ALL on the table.
+	mysqlTablePrivileges = []string{
+		"SELECT", "INSERT", "UPDATE", "DELETE", "CREATE", "DROP", "REFERENCES", "INDEX", "ALTER", "CREATE VIEW", "SHOW VIEW",
+		"TRIGGER",
+	}
+)
+
+// containsAll reports whether ss contains all of the elements.
+func containsAll(ss, elements []string) bool {
+	m := make(map[string]struct{}, len(ss))
+	for _, s := range ss {
+		m[s] = struct{}{}
+	}
+	for _, e := range elements {
+		if _, ok := m[e]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// mysqlPasswordMatches reports whether the authentication string of the authentication plugin is of the password.
+// Only mysql_native_password and caching_sha2_password can be compared.
+func mysqlPasswordMatches(plugin, auth, password string) bool {
+	if auth == "" {
+		return password == ""
+	}
+	switch plugin {
+	case "mysql_native_password":
+		h := sha1.Sum([]byte(password))
+		h = sha1.Sum(h[:])
+		return auth == "*"+strings.ToUpper(hex.EncodeToString(h[:]))
+	case "caching_sha2_password":
+		// The authentication string is "$A$" + the rounds / 1000 in 3 hexadecimal digits + "$" + the salt of 20 bytes +
+		// the digest of SHA-256 crypt.
+		const saltLen = 20
+		if len(auth) < 7+saltLen || !strings.HasPrefix(auth, "$A$") || auth[6] != '$' {
+			return false
+		}
+		n, err := strconv.ParseUint(auth[3:6], 16, 32)
+		if err != nil {
+			return false
+		}
+		salt := auth[7 : 7+saltLen]
+		return auth[7+saltLen:] == sha256Crypt([]byte(password), []byte(salt), int(n)*1000)
+	}
+	return false
+}
+
+// sha256Crypt returns the digest of the key by SHA-256 crypt (https://www.akkadia.org/drepper/SHA-crypt.txt) encoded
+// in the base64 of crypt.
+func sha256Crypt(key, salt []byte, rounds int) string {
+	b := sha256.New()
+	b.Write(key)
+	b.Write(salt)
+	b.Write(key)
+	digestB := b.Sum(nil)
+	a := sha256.New()
+	a.Write(key)
+	a.Write(salt)
+	for n := len(key); n > 0; n -= sha256.Size {
+		if n > sha256.Size {
+			a.Write(digestB)
+		} else {
+			a.Write(digestB[:n])
+		}
+	}
+	for n := len(key); n > 0; n >>= 1 {
+		if n&1 != 0 {
+			a.Write(digestB)
+		} else {
+			a.Write(key)
+		}
+	}
+	digestA := a.Sum(nil)
+	repeat := func(digest []byte, n int) []byte {
+		seq := make([]byte, n)
+		for i := 0; i < n; i += len(digest) {
+			copy(seq[i:], digest)
+		}
+		return seq
+	}
+	dp := sha256.New()
+	for range key {
+		dp.Write(key)
+	}
+	p := repeat(dp.Sum(nil), len(key))
+	ds := sha256.New()
+	for i := 0; i < 16+int(digestA[0]); i++ {
+		ds.Write(salt)
+	}
+	s := repeat(ds.Sum(nil), len(salt))
+	digest := digestA
+	for i := 0; i < rounds; i++ {
+		c := sha256.New()
+		if i%2 != 0 {
+			c.Write(p)
+		} else {
+			c.Write(digest)
+		}
+		if i%3 != 0 {
+			c.Write(s)
+		}
+		if i%7 != 0 {
+			c.Write(p)
+		}
+		if i%2 != 0 {
+			c.Write(digest)
+		} else {
+			c.Write(p)
+		}
+		digest = c.Sum(nil)
+	}
+	const itoa64 = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	var buf strings.Builder
+	encode := func(b2, b1, b0 byte, n int) {
+		w := uint(b2)<<16 | uint(b1)<<8 | uint(b0)
+		for ; n > 0; n-- {
+			buf.WriteByte(itoa64[w&0x3f])
+			w >>= 6
+		}
+	}
+	for i := 0; i < 10; i++ {
+		// The bytes are permuted as (0, 10, 20), (21, 1, 11), (12, 22, 2), ...
+		j := i * 21
+		encode(digest[j%30], digest[(j+10)%30], digest[(j+20)%30], 4)
+	}
+	encode(0, digest[31], digest[30], 3)
+	return buf.String()
+}
+
+// userName returns the account name of the user such as 'app'@'%'.
+func (d *MySQL) userName(user User) string {
+	if user.Role {
+		return d.QuoteString(user.Name)
+	}
+	host := user.Host
+	if host == "" {
+		host = "%"
+	}
+	return d.QuoteString(user.Name) + "@" + d.QuoteString(host)
+}
+
+func (d *MySQL) RowSizeLimit(table Table) RowSizeLimit {
+	limit := RowSizeLimit{
+		CharWidth:           4,
+		MaxRowSize:          mysqlMaxRowSize,
+		MaxInlineRowSize:    innodbMaxInlineRowSize,
+		MaxInlineColumnSize: 40,
+	}
+	option := strings.ToUpper(table.Option)
+	if engine := tableOptionValue(option, "ENGINE"); engine != "" && engine != "INNODB" {
+		limit.MaxInlineRowSize = 0
+	}
+	charset := tableOptionValue(option, "CHARSET")
+	if charset == "" {
+		charset = tableOptionValue(option, "CHARACTER SET")
+	}
+	if w := d.CharWidth(charset); w > 0 {
+		limit.CharWidth = w
+	}
+	rowFormat := tableOptionValue(option, "ROW_FORMAT")
+	switch rowFormat {
+	case "REDUNDANT", "COMPACT":
+		// The prefix of 768 bytes and the pointer of 20 bytes are stored in the page.
+		limit.MaxInlineColumnSize = 768 + 20
+	}
+	limit.MaxIndexKeySize = d.MaxIndexKeySize(rowFormat)
+	return limit
+}
+
+// SchemaLimits returns the limits of InnoDB. The number of the columns of the other engines is limited only by the
+// row size.
+func (d *MySQL) SchemaLimits(table Table) SchemaLimits {
+	limit := SchemaLimits{
+		MaxIdentifierLength: 64,
+		MaxColumns:          1017,
+		MaxIndexes:          64,
+		MaxIndexColumns:     16,
+		MaxEnumElements:     65535,
+		MaxSetElements:      64,
+	}
+	if engine := tableOptionValue(strings.ToUpper(table.Option), "ENGINE"); engine != "" && engine != "INNODB" {
+		limit.MaxColumns = 4096
+	}
+	return limit
+}
+
+func (d *MySQL) EnumColumnType(elements []string, set bool) string {
+	quoted := make([]string, len(elements))
+	for i, e := range elements {
+		quoted[i] = d.QuoteString(e)
+	}
+	typ := "ENUM"
+	if set {
+		typ = "SET"
+	}
+	return typ + "(" + strings.Join(quoted, ",") + ")"
+}
+
+// SpatialColumnType returns the spatial column type with SRID attribute such as POINT SRID 4326, which is supported as of
+// MySQL 8.0.3.
+func (d *MySQL) SpatialColumnType(typ string, srid uint32) (string, bool) {
+	if !SpatialTypes[strings.ToUpper(typ)] {
+		return "", false
+	}
+	return fmt.Sprintf("%s SRID %d", strings.ToUpper(typ), srid), true
+}
+
+func (d *MySQL) TableCharsets(tables ...string) ([]TableCharset, error) {
+	dbname, err := d.currentDBName()
+	if err != nil {
+		return nil, err
+	}
+	var placeholder string
+	args := []interface{}{dbname}
+	if len(tables) > 0 {
+		placeholder = fmt.Sprintf("AND t.TABLE_NAME IN (%s)", strings.Repeat(",?", len(tables))[1:])
+		for _, t := range tables {
+			args = append(args, t)
+		}
+	}
+	rows, err := d.db.Query(strings.Join([]string{
+		"SELECT",
+		"  t.TABLE_NAME,",
+		"  c.CHARACTER_SET_NAME,",
+		"  t.ROW_FORMAT",
+		"FROM information_schema.TABLES t",
+		"JOIN information_schema.COLLATION_CHARACTER_SET_APPLICABILITY c ON c.COLLATION_NAME = t.TABLE_COLLATION",
+		"WHERE t.TABLE_SCHEMA = ? AND t.TABLE_TYPE = 'BASE TABLE'",
+		placeholder,
+		"ORDER BY t.TABLE_NAME",
+	}, "\n"), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var charsets []TableCharset
+	index := map[string]int{}
+	for rows.Next() {
+		var (
+			c         TableCharset
+			rowFormat sql.NullString
+		)
+		if err := rows.Scan(&c.Table, &c.Charset, &rowFormat); err != nil {
+			return nil, err
+		}
+		c.RowFormat = strings.ToUpper(rowFormat.String)
+		c.Columns = map[string]string{}
+		index[c.Table] = len(charsets)
+		charsets = append(charsets, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+	rows, err = d.db.Query(strings.Join([]string{
+		"SELECT",
+		"  t.TABLE_NAME,",
+		"  t.COLUMN_NAME,",
+		"  t.CHARACTER_SET_NAME",
+		"FROM information_schema.COLUMNS t",
+		"WHERE t.TABLE_SCHEMA = ? AND t.CHARACTER_SET_NAME IS NOT NULL",
+		placeholder,
+	}, "\n"), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var table, column, charset string
+		if err := rows.Scan(&table, &column, &charset); err != nil {
+			return nil, err
+		}
+		if i, ok := index[table]; ok {
+			charsets[i].Columns[column] = charset
+		}
+	}
+	return charsets, rows.Err()
+}
+
+func (d *MySQL) ConvertCharsetSQL(table, charset, collation string) []string {
+	query := fmt.Sprintf("ALTER TABLE %s CONVERT TO CHARACTER SET %s", d.Quote(table), charset)
+	if collation != "" {
+		query += " COLLATE " + collation
+	}
+	return []string{query}
+}
+
+func (d *MySQL) CharWidth(charset string) int {
+	return mysqlCharWidths[strings.ToLower(charset)]
+}
+
+func (d *MySQL) MaxIndexKeySize(rowFormat string) int64 {
+	switch strings.ToUpper(rowFormat) {
+	case "REDUNDANT", "COMPACT":
+		return 767
+	}
+	return 3072
+}
+
+// tableOptionValue returns the value of the key in the table option in upper case. (e.g. ENGINE=InnoDB)
+func tableOptionValue(option, key string) string {
+	i := strings.Index(option, key)
+	if i < 0 {
+		return ""
+	}
+	s := strings.TrimLeft(option[i+len(key):], " =")
+	if j := strings.IndexAny(s, " ,"); j >= 0 {
+		s = s[:j]
+	}
+	return strings.Trim(s, "'\"")
+}
+
+func (d *MySQL) Degradations() []Degradation {
+	return append([]Degradation(nil), d.degradations...)
+}
+
+func (d *MySQL) degrade(feature string, err error) {
+	for _, dg := range d.degradations {
+		if dg.Feature == feature {
+			return
+		}
+	}
+	d.degradations = append(d.degradations, Degradation{Feature: feature, Err: err})
+}
+
+func (d *MySQL) Privileges() ([]string, error) {
+	dbname, err := d.currentDBName()
+	if err != nil {
+		return nil, err
+	}
+	// The grantee is in the form of 'user'@'host' in information_schema while CURRENT_USER() returns user@host.
+	query := strings.Join([]string{
+		"SELECT PRIVILEGE_TYPE FROM information_schema.USER_PRIVILEGES",
+		"WHERE GRANTEE = CONCAT('''', REPLACE(CURRENT_USER(), '@', '''@'''), '''')",
+		"UNION",
+		"SELECT PRIVILEGE_TYPE FROM information_schema.SCHEMA_PRIVILEGES",
+		"WHERE GRANTEE = CONCAT('''', REPLACE(CURRENT_USER(), '@', '''@'''), '''') AND TABLE_SCHEMA = ?",
+	}, "\n")
+	rows, err := d.db.Query(query, dbname)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var privileges []string
+	for rows.Next() {
+		var privilege string
+		if err := rows.Scan(&privilege); err != nil {
+			return nil, err
+		}
+		privileges = append(privileges, strings.ToUpper(privilege))
+	}
+	return privileges, rows.Err()
+}
+
+func (d *MySQL) EstimateRows(table string) (int64, error) {
+	rows, err := d.db.Query(fmt.Sprintf("EXPLAIN SELECT * FROM %s", d.Quote(table)))
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for rows.Next() {
+		var n sql.NullInt64
+		dest := make([]interface{}, len(columns))
+		for i, c := range columns {
+			if c == "rows" {
+				dest[i] = &n
+			} else {
+				dest[i] = new(sql.RawBytes)
+			}
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return 0, err
+		}
+		total += n.Int64
+	}
+	return total, rows.Err()
+}
+
+func (d *MySQL) Indexes(tables ...string) ([]Index, error) {
+	dbname, err := d.currentDBName()
+	if err != nil {
+		return nil, err
+	}
+	parts := []string{
+		"SELECT",
+		"  TABLE_NAME,",
+		"  INDEX_NAME,",
+		"  COLUMN_NAME,",
+		"  NON_UNIQUE",
+		"FROM information_schema.STATISTICS",
+		"WHERE TABLE_SCHEMA = ?",
+	}
+	args := []interface{}{dbname}
+	if len(tables) > 0 {
+		placeholder := strings.Repeat(",?", len(tables))
+		placeholder = placeholder[1:] // truncate the heading comma.
+		parts = append(parts, fmt.Sprintf("AND TABLE_NAME IN (%s)", placeholder))
+		for _, t := range tables {
+			args = append(args, t)
+		}
+	}
+	parts = append(parts, "ORDER BY TABLE_NAME, INDEX_NAME, SEQ_IN_INDEX")
+	rows, err := d.db.Query(strings.Join(parts, "\n"), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var indexes []Index
+	for rows.Next() {
+		var (
+			tableName  string
+			indexName  string
+			columnName string
+			nonUnique  int64
+		)
+		if err := rows.Scan(&tableName, &indexName, &columnName, &nonUnique); err != nil {
+			return nil, err
+		}
+		if n := len(indexes); n > 0 && indexes[n-1].Table == tableName && indexes[n-1].Name == indexName {
+			indexes[n-1].Columns = append(indexes[n-1].Columns, columnName)
+			continue
+		}
+		indexes = append(indexes, Index{
+			Table:   tableName,
+			Name:    indexName,
+			Columns: []string{columnName},
+			Unique:  nonUnique == 0,
+		})
+	}
+	return indexes, rows.Err()
+}
+
+func (d *MySQL) ForeignKeys(tables ...string) ([]ForeignKey, error) {
+	dbname, err := d.currentDBName()
+	if err != nil {
+		return nil, err
+	}
+	parts := []string{
+		"SELECT",
+		"  TABLE_NAME,",
+		"  CONSTRAINT_NAME,",
+		"  COLUMN_NAME,",
+		"  REFERENCED_TABLE_NAME,",
+		"  REFERENCED_COLUMN_NAME",
+		"FROM information_schema.KEY_COLUMN_USAGE",
+		"WHERE TABLE_SCHEMA = ?",
+		"AND REFERENCED_TABLE_NAME IS NOT NULL",
+	}
+	args := []interface{}{dbname}
+	if len(tables) > 0 {
+		placeholder := strings.Repeat(",?", len(tables))
+		placeholder = placeholder[1:] // truncate the heading comma.
+		parts = append(parts, fmt.Sprintf("AND TABLE_NAME IN (%s)", placeholder))
+		for _, t := range tables {
+			args = append(args, t)
+		}
+	}
+	parts = append(parts, "ORDER BY TABLE_NAME, CONSTRAINT_NAME, ORDINAL_POSITION")
+	rows, err := d.db.Query(strings.Join(parts, "\n"), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var fks []ForeignKey
+	for rows.Next() {
+		var tableName, name, columnName, refTableName, refColumnName string
+		if err := rows.Scan(&tableName, &name, &columnName, &refTableName, &refColumnName); err != nil {
+			return nil, err
+		}
+		if n := len(fks); n > 0 && fks[n-1].Table == tableName && fks[n-1].Name == name {
+			fks[n-1].Columns = append(fks[n-1].Columns, columnName)
+			fks[n-1].RefColumns = append(fks[n-1].RefColumns, refColumnName)
+			continue
+		}
+		fks = append(fks, ForeignKey{
+			Table:      tableName,
+			Name:       name,
+			Columns:    []string{columnName},
+			RefTable:   refTableName,
+			RefColumns: []string{refColumnName},
+		})
+	}
+	return fks, rows.Err()
+}
+
+func (d *MySQL) AddForeignKeySQL(fk ForeignKey) []string {
+	columns := make([]string, len(fk.Columns))
+	for i, c := range fk.Columns {
+		columns[i] = d.Quote(c)
+	}
+	refColumns := make([]string, len(fk.RefColumns))
+	for i, c := range fk.RefColumns {
+		refColumns[i] = d.Quote(c)
+	}
+	return []string{fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+		d.Quote(fk.Table), d.Quote(fk.Name), strings.Join(columns, ","), d.Quote(fk.RefTable), strings.Join(refColumns, ","))}
+}
+
+func (d *MySQL) DropForeignKeySQL(fk ForeignKey) []string {
+	return []string{fmt.Sprintf("ALTER TABLE %s DROP FOREIGN KEY %s", d.Quote(fk.Table), d.Quote(fk.Name))}
+}
+
+// CheckConstraints returns the CHECK constraints of the tables. It returns nothing for the versions of MySQL before
+// 8.0.16, which parse the CHECK constraints but ignore them, and MariaDB before 10.2.22, which has no table names of
+// the CHECK constraints in information_schema.
+// The CHECK constraints of MariaDB that validate the JSON columns are excluded since they are not declared by the
+// users.
+func (d *MySQL) CheckConstraints(tables ...string) ([]CheckConstraint, error) {
+	version, err := d.dbVersion()
+	if err != nil {
+		return nil, err
+	}
+	join := "JOIN information_schema.CHECK_CONSTRAINTS cc ON cc.CONSTRAINT_SCHEMA = tc.CONSTRAINT_SCHEMA AND cc.CONSTRAINT_NAME = tc.CONSTRAINT_NAME"
+	switch {
+	case version.isMariaDB() && version.atLeast(10, 2, 22):
+		// The names of the CHECK constraints of MariaDB are unique only in the table.
+		join += " AND cc.TABLE_NAME = tc.TABLE_NAME"
+	case !version.isMariaDB() && version.atLeast(8, 0, 16):
+	default:
+		return nil, nil
+	}
+	dbname, err := d.currentDBName()
+	if err != nil {
+		return nil, err
+	}
+	parts := []string{
+		"SELECT",
+		"  tc.TABLE_NAME,",
+		"  tc.CONSTRAINT_NAME,",
+		"  cc.CHECK_CLAUSE",
+		"FROM information_schema.TABLE_CONSTRAINTS tc",
+		join,
+		"WHERE tc.TABLE_SCHEMA = ?",
+		"AND tc.CONSTRAINT_TYPE = 'CHECK'",
+	}
+	args := []interface{}{dbname}
+	if len(tables) > 0 {
+		placeholder := strings.Repeat(",?", len(tables))
+		placeholder = placeholder[1:] // truncate the heading comma.
+		parts = append(parts, fmt.Sprintf("AND tc.TABLE_NAME IN (%s)", placeholder))
+		for _, t := range tables {
+			args = append(args, t)
+		}
+	}
+	parts = append(parts, "ORDER BY tc.TABLE_NAME, tc.CONSTRAINT_NAME")
+	rows, err := d.db.Query(strings.Join(parts, "\n"), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var checks []CheckConstraint
+	for rows.Next() {
+		var c CheckConstraint
+		if err := rows.Scan(&c.Table, &c.Name, &c.Expression); err != nil {
+			return nil, err
+		}
+		if version.isMariaDB() && strings.HasPrefix(c.Expression, "json_valid(") {
+			continue
+		}
+		checks = append(checks, c)
+	}
+	return checks, rows.Err()
+}
+
+func (d *MySQL) AddCheckConstraintSQL(c CheckConstraint) []string {
+	return []string{fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s CHECK (%s)", d.Quote(c.Table), d.Quote(c.Name), c.Expression)}
+}
+
+func (d *MySQL) DropCheckConstraintSQL(c CheckConstraint) []string {
+	// MariaDB does not support DROP CHECK, and MySQL before 8.0.19 does not support DROP CONSTRAINT.
+	if d.version.isMariaDB() {
+		return []string{fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s", d.Quote(c.Table), d.Quote(c.Name))}
+	}
+	return []string{fmt.Sprintf("ALTER TABLE %s DROP CHECK %s", d.Quote(c.Table), d.Quote(c.Name))}
+}
+
+func (d *MySQL) ReadRows(table string, columns []string, fn func(values []*string) error) error {
+	quoted := make([]string, len(columns))
+	for i, c := range columns {
+		quoted[i] = d.Quote(c)
+	}
+	rows, err := d.db.Query(fmt.Sprintf("SELECT %s FROM %s", strings.Join(quoted, ", "), d.Quote(table)))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	raws := make([]sql.RawBytes, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range raws {
+		dest[i] = &raws[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+		values := make([]*string, len(raws))
+		for i, raw := range raws {
+			if raw != nil {
+				v := string(raw)
+				values[i] = &v
+			}
+		}
+		if err := fn(values); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (d *MySQL) Duplicates(table string, columns []string, limit int) ([]Duplicate, error) {
+	quoted := make([]string, len(columns))
+	conds := make([]string, len(columns))
+	for i, c := range columns {
+		quoted[i] = d.Quote(c)
+		conds[i] = d.Quote(c) + " IS NOT NULL"
+	}
+	return readDuplicates(d.db, fmt.Sprintf("SELECT %s, COUNT(*) FROM %s WHERE %s GROUP BY %s HAVING COUNT(*) > 1 ORDER BY COUNT(*) DESC LIMIT %d",
+		strings.Join(quoted, ", "), d.Quote(table), strings.Join(conds, " AND "), strings.Join(quoted, ", "), limit), len(columns))
+}
+
+// readDuplicates reads the duplicates by the query that selects the values of n columns and the number of the rows.
+func readDuplicates(db *sql.DB, query string, n int) ([]Duplicate, error) {
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var duplicates []Duplicate
+	for rows.Next() {
+		dup := Duplicate{Values: make([]string, n)}
+		dest := make([]interface{}, n+1)
+		for i := range dup.Values {
+			dest[i] = &dup.Values[i]
+		}
+		dest[n] = &dup.Count
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		duplicates = append(duplicates, dup)
+	}
+	return duplicates, rows.Err()
+}
+
+func (d *MySQL) Orphans(fk ForeignKey, limit int) (Orphans, error) {
+	columns := make([]string, len(fk.Columns))
+	joins := make([]string, len(fk.Columns))
+	conds := make([]string, len(fk.Columns))
+	for i, c := range fk.Columns {
+		columns[i] = "c." + d.Quote(c)
+		joins[i] = fmt.Sprintf("c.%s = p.%s", d.Quote(c), d.Quote(fk.RefColumns[i]))
+		conds[i] = "c." + d.Quote(c) + " IS NOT NULL"
+	}
+	from := fmt.Sprintf("FROM %s AS c LEFT JOIN %s AS p ON %s WHERE %s AND p.%s IS NULL",
+		d.Quote(fk.Table), d.Quote(fk.RefTable), strings.Join(joins, " AND "), strings.Join(conds, " AND "), d.Quote(fk.RefColumns[0]))
+	var orphans Orphans
+	if err := d.db.QueryRow("SELECT COUNT(*) " + from).Scan(&orphans.Count); err != nil {
+		return Orphans{}, err
+	}
+	if orphans.Count == 0 {
+		return orphans, nil
+	}
+	rows, err := d.db.Query(fmt.Sprintf("SELECT DISTINCT %s %s LIMIT %d", strings.Join(columns, ", "), from, limit))
+	if err != nil {
+		return Orphans{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		values := make([]string, len(columns))
+		dest := make([]interface{}, len(values))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return Orphans{}, err
+		}
+		orphans.Values = append(orphans.Values, values)
+	}
+	return orphans, rows.Err()
+}
+
+func (d *MySQL) UnusedIndexes() ([]Index, error) {
+	dbname, err := d.currentDBName()
+	if err != nil {
+		return nil, err
+	}
+	query := strings.Join([]string{
+		"SELECT",
+		"  OBJECT_NAME,",
+		"  INDEX_NAME",
+		"FROM performance_schema.table_io_waits_summary_by_index_usage",
+		"WHERE OBJECT_SCHEMA = ?",
+		"AND INDEX_NAME IS NOT NULL",
+		"AND INDEX_NAME != 'PRIMARY'",
+		"AND COUNT_STAR = 0",
+		"ORDER BY OBJECT_NAME, INDEX_NAME",
+	}, "\n")
+	rows, err := d.db.Query(query, dbname)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var indexes []Index
+	for rows.Next() {
+		var index Index
+		if err := rows.Scan(&index.Table, &index.Name); err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes, rows.Err()
+}
+
+func (d *MySQL) IndexStatistics(table, name string) (IndexStatistics, error) {
+	dbname, err := d.currentDBName()
+	if err != nil {
+		return IndexStatistics{}, err
+	}
+	var stats IndexStatistics
+	if err := d.db.QueryRow(strings.Join([]string{
+		"SELECT",
+		"  COALESCE(MAX(CARDINALITY), 0)",
+		"FROM information_schema.STATISTICS",
+		"WHERE TABLE_SCHEMA = ?",
+		"AND TABLE_NAME = ?",
+		"AND INDEX_NAME = ?",
+	}, "\n"), dbname, table, name).Scan(&stats.Cardinality); err != nil {
+		return IndexStatistics{}, err
+	}
+	if err := d.db.QueryRow(strings.Join([]string{
+		"SELECT",
+		"  COALESCE(SUM(COUNT_READ), 0)",
+		"FROM performance_schema.table_io_waits_summary_by_index_usage",
+		"WHERE OBJECT_SCHEMA = ?",
+		"AND OBJECT_NAME = ?",
+		"AND INDEX_NAME = ?",
+	}, "\n"), dbname, table, name).Scan(&stats.Reads); err != nil {
+		return IndexStatistics{}, err
+	}
+	// NOTE: mysql.innodb_index_stats requires the privilege for mysql schema, so the size is treated as unknown
+	// if it cannot be read.
+	if err := d.db.QueryRow(strings.Join([]string{
+		"SELECT",
+		"  COALESCE(SUM(stat_value), 0) * @@innodb_page_size",
+		"FROM mysql.innodb_index_stats",
+		"WHERE database_name = ?",
+		"AND table_name = ?",
+		"AND index_name = ?",
+		"AND stat_name = 'size'",
+	}, "\n"), dbname, table, name).Scan(&stats.Size); err != nil {
+		stats.Size = -1
+	}
+	return stats, nil
+}
+
+func (d *MySQL) Health() (Health, error) {
+	health := Health{
+		ReplicationLag: -1,
+		CPUUtilization: -1,
+	}
+	var name string
+	if err := d.db.QueryRow("SHOW GLOBAL STATUS LIKE 'Threads_running'").Scan(&name, &health.ThreadsRunning); err != nil {
+		return Health{}, err
+	}
+	version, err := d.dbVersion()
+	if err != nil {
+		return Health{}, err
+	}
+	// SHOW SLAVE STATUS is deprecated as of MySQL 8.0.22, and is removed in MySQL 8.4.
+	query := "SHOW SLAVE STATUS"
+	if !version.isMariaDB() && version.atLeast(8, 0, 22) {
+		query = "SHOW REPLICA STATUS"
+	}
+	rows, err := d.db.Query(query)
+	if err != nil {
+		return Health{}, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return Health{}, err
+	}
+	for rows.Next() {
+		var lag sql.NullInt64
+		dest := make([]interface{}, len(columns))
+		for i, c := range columns {
+			if c == "Seconds_Behind_Master" || c == "Seconds_Behind_Source" {
+				dest[i] = &lag
+			} else {
+				dest[i] = new(sql.RawBytes)
+			}
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return Health{}, err
+		}
+		// NULL means that the replication is stopped, so the lag is unknown.
+		if lag.Valid {
+			if l := time.Duration(lag.Int64) * time.Second; l > health.ReplicationLag {
+				health.ReplicationLag = l
+			}
+		}
+	}
+	return health, rows.Err()
+}
+
+func (d *MySQL) ServerVersion() (version string, supported bool, err error) {
+	v, err := d.dbVersion()
+	if err != nil {
+		return "", false, err
+	}
+	version = fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Name != "" {
+		version += "-" + v.Name
+	}
+	if v.isMariaDB() {
+		return version, v.atLeast(10, 2, 0), nil
+	}
+	return version, v.atLeast(5, 7, 0), nil
+}
+
+func (d *MySQL) LockBlockers() ([]LockBlocker, error) {
+	query := strings.Join([]string{
+		"SELECT",
+		"  trx_mysql_thread_id,",
+		"  TIMESTAMPDIFF(SECOND, trx_started, NOW()),",
+		"  trx_query",
+		"FROM information_schema.INNODB_TRX",
+		"WHERE trx_mysql_thread_id <> CONNECTION_ID()",
+		"ORDER BY trx_started",
+	}, "\n")
+	rows, err := d.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var blockers []LockBlocker
+	for rows.Next() {
+		var (
+			id      int64
+			seconds int64
+			q       sql.NullString
+		)
+		if err := rows.Scan(&id, &seconds, &q); err != nil {
+			return nil, err
+		}
+		blockers = append(blockers, LockBlocker{
+			Session:  strconv.FormatInt(id, 10),
+			Duration: time.Duration(seconds) * time.Second,
+			Query:    q.String,
+		})
+	}
+	return blockers, rows.Err()
+}
+
+func (d *MySQL) NoIndexQueries() ([]string, error) {
+	dbname, err := d.currentDBName()
+	if err != nil {
+		return nil, err
+	}
+	query := strings.Join([]string{
+		"SELECT",
+		"  DIGEST_TEXT",
+		"FROM performance_schema.events_statements_summary_by_digest",
+		"WHERE SCHEMA_NAME = ?",
+		"AND DIGEST_TEXT IS NOT NULL",
+		"AND (SUM_NO_INDEX_USED > 0 OR SUM_NO_GOOD_INDEX_USED > 0)",
+		"ORDER BY SUM_TIMER_WAIT DESC",
+		"LIMIT 100",
+	}, "\n")
+	rows, err := d.db.Query(query, dbname)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var queries []string
+	for rows.Next() {
+		var q string
+		if err := rows.Scan(&q); err != nil {
+			return nil, err
+		}
+		queries = append(queries, q)
+	}
+	return queries, rows.Err()
+}
+
+func (d *MySQL) defaultColumnType(name string) string {
+	switch name := strings.ToUpper(name); name {
+	case "BIT":
+		return "BIT(1)"
+	case "DECIMAL":
+		return "DECIMAL(10,0)"
+	case "VARCHAR":
+		return "VARCHAR(255)"
+	case "VARBINARY":
+		return "VARBINARY(255)"
+	case "CHAR":
+		return "CHAR(1)"
+	case "BINARY":
+		return "BINARY(1)"
+	case "YEAR":
+		return "YEAR(4)"
+	}
+	return name
+}
+
+func (d *MySQL) currentDBName() (string, error) {
+	if d.dbName != "" {
+		return d.dbName, nil
+	}
+	err := d.db.QueryRow(`SELECT DATABASE()`).Scan(&d.dbName)
+	return d.dbName, err
+}
+
+func (d *MySQL) dbVersion() (*mysqlVersion, error) {
+	if d.version != nil {
+		return d.version, nil
+	}
+	var version string
+	if err := d.db.QueryRow(`SELECT VERSION()`).Scan(&version); err != nil {
+		return nil, err
+	}
+	v, err := parseMySQLVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	d.version = v
+	return d.version, nil
+}
+
+// mariaDBJSONColumns returns the JSON columns of the tables of MariaDB. It requires MariaDB 10.2.22 or later.
+// JSON of MariaDB is an alias of LONGTEXT that has the CHECK constraint of json_valid() named after the column.
+func (d *MySQL) mariaDBJSONColumns(dbname string) (map[string]map[string]bool, error) {
+	query := strings.Join([]string{
+		"SELECT",
+		"  TABLE_NAME,",
+		"  CONSTRAINT_NAME,",
+		"  CHECK_CLAUSE",
+		"FROM information_schema.CHECK_CONSTRAINTS",
+		"WHERE CONSTRAINT_SCHEMA = ?",
+	}, "\n")
+	rows, err := d.db.Query(query, dbname)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns := make(map[string]map[string]bool)
+	for rows.Next() {
+		var tableName, name, clause string
+		if err := rows.Scan(&tableName, &name, &clause); err != nil {
+			return nil, err
+		}
+		if clause != fmt.Sprintf("json_valid(%s)", d.Quote(name)) {
+			continue
+		}
+		if _, exists := columns[tableName]; !exists {
+			columns[tableName] = make(map[string]bool)
+		}
+		columns[tableName][name] = true
+	}
+	return columns, rows.Err()
+}
+
+func (d *MySQL) getIndexMap() (map[string]map[string]mysqlIndexInfo, error) {
+	dbname, err := d.currentDBName()
+	if err != nil {
+		return nil, err
+	}
+	query := strings.Join([]string{
+		"SELECT",
+		"  TABLE_NAME,",
+		"  COLUMN_NAME,",
+		"  NON_UNIQUE,",
+		"  INDEX_NAME",
+		"FROM information_schema.STATISTICS",
+		"WHERE TABLE_SCHEMA = ?",
+	}, "\n")
+	rows, err := d.db.Query(query, dbname)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	indexMap := make(map[string]map[string]mysqlIndexInfo)
+	for rows.Next() {
+		var (
+			tableName  string
+			columnName string
+			index      mysqlIndexInfo
+		)
+		if err := rows.Scan(&tableName, &columnName, &index.NonUnique, &index.IndexName); err != nil {
+			return nil, err
+		}
+		if _, exists := indexMap[tableName]; !exists {
+			indexMap[tableName] = make(map[string]mysqlIndexInfo)
+		}
+		indexMap[tableName][columnName] = index
+	}
+	return indexMap, rows.Err()
+}
+
+// isMySQLAccessDenied reports whether err is caused by the lack of the privileges.
+func isMySQLAccessDenied(err error) bool {
+	var merr *mysql.MySQLError
+	if !errors.As(err, &merr) {
+		return false
+	}
+	switch merr.Number {
+	case 1044, 1142, 1143, 1227: // ER_DBACCESS_DENIED_ERROR, ER_TABLEACCESS_DENIED_ERROR, ER_COLUMNACCESS_DENIED_ERROR, ER_SPECIFIC_ACCESS_DENIED_ERROR
+		return true
+	}
+	return false
+}
+
+type mysqlIndexInfo struct {
+	NonUnique int64
+	IndexName string
+}
+
+type mysqlVersion struct {
+	Major int
+	Minor int
+	Patch int
+	Name  string
+}
+
+// parseMySQLVersion parses the result of VERSION() such as "8.0.32" and "10.6.12-MariaDB-1:10.6.12+maria~ubu2004".
+func parseMySQLVersion(version string) (*mysqlVersion, error) {
+	// MariaDB before 11.0 may be prefixed with "5.5.5-" for the compatibility of the replication protocol.
+	if strings.HasPrefix(version, "5.5.5-") && strings.Contains(version, "-MariaDB") {
+		version = version[len("5.5.5-"):]
+	}
+	vs := strings.Split(version, "-")
+	var v mysqlVersion
+	if len(vs) > 1 {
+		v.Name = vs[1]
+	}
+	versions := strings.Split(vs[0], ".")
+	if len(versions) < 3 {
+		return nil, fmt.Errorf("unknown version of MySQL: %s", version)
+	}
+	var err error
+	if v.Major, err = strconv.Atoi(versions[0]); err != nil {
+		return nil, err
+	}
+	if v.Minor, err = strconv.Atoi(versions[1]); err != nil {
+		return nil, err
+	}
+	if v.Patch, err = strconv.Atoi(versions[2]); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// isMariaDB reports whether the server is MariaDB. It is false if the version is not read yet.
+func (v *mysqlVersion) isMariaDB() bool {
+	return v != nil && v.Name == "MariaDB"
+}
+
+// atLeast reports whether the version is major.minor.patch or later.
+func (v *mysqlVersion) atLeast(major, minor, patch int) bool {
+	if v.Major != major {
+		return v.Major > major
+	}
+	if v.Minor != minor {
+		return v.Minor > minor
+	}
+	return v.Patch >= patch
+}
+
+type mysqlTransaction struct {
+	tx *sql.Tx
+}
+
+func (m *mysqlTransaction) Exec(sql string, args ...interface{}) error {
+	_, err := m.tx.Exec(sql, args...)
+	return err
+}
+
+func (m *mysqlTransaction) Commit() error {
+	return m.tx.Commit()
+}
+
+func (m *mysqlTransaction) Rollback() error {
+	return m.tx.Rollback()
+}
+
+func trimParens(s string) string {
+	start, end := -1, -1
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '(' {
+			start = i
+			continue
+		}
+		if c == ')' {
+			end = i
+			break
+		}
+	}
+	if start < 0 || end < 0 {
+		return s
+	}
+	return s[:start] + s[end+1:]
+}
+
+var (
+	_ ColumnSchema           = &mysqlColumnSchema{}
+	_ CompressedColumnSchema = &mysqlColumnSchema{}
+	_ GeneratedColumnSchema  = &mysqlColumnSchema{}
+	_ SpatialColumnSchema    = &mysqlColumnSchema{}
+	_ CollatedColumnSchema   = &mysqlColumnSchema{}
+	_ OnUpdateColumnSchema   = &mysqlColumnSchema{}
+)
+
+const (
+	// mysqlMariaDBCompressed is the attribute of the compressed columns in COLUMN_TYPE of MariaDB.
+	mysqlMariaDBCompressed = "/*M!100301 COMPRESSED*/"
+
+	// mysqlPerconaCompressed is the attribute of the compressed columns in EXTRA of Percona Server.
+	mysqlPerconaCompressed = "COLUMN_FORMAT_COMPRESSED"
+)
+
+// mysqlCompression returns the compression algorithm in CREATE_OPTIONS of information_schema.TABLES such as
+// `COMPRESSION="zlib"`, or "none" if it is not specified.
+func mysqlCompression(createOptions string) string {
+	for _, opt := range strings.Fields(createOptions) {
+		kv := strings.SplitN(opt, "=", 2)
+		if len(kv) == 2 && strings.EqualFold(kv[0], "COMPRESSION") {
+			if v := strings.ToLower(strings.Trim(kv[1], `"'`)); v != "" {
+				return v
+			}
+		}
+	}
+	return "none"
+}
+
+type mysqlColumnSchema struct {
+	tableName              string
+	columnName             string
+	ordinalPosition        int64
+	columnDefault          sql.NullString
+	isNullable             string
 	dataType               string
 	characterMaximumLength *uint64
 	characterOctetLength   sql.NullInt64
@@ -502,6 +2045,11 @@ type mysqlColumnSchema struct {
 	columnComment          string
 	nonUnique              int64
 	indexName              string
+	json                   bool
+	srsID                  sql.NullInt64
+	characterSetName       sql.NullString
+	collationName          sql.NullString
+	tableCollation         sql.NullString
 
 	version *mysqlVersion
 }
@@ -515,7 +2063,13 @@ func (schema *mysqlColumnSchema) ColumnName() string {
 }
 
 func (schema *mysqlColumnSchema) ColumnType() string {
+	if schema.json {
+		return "json"
+	}
 	typ := schema.columnType
+	if i := strings.Index(typ, mysqlMariaDBCompressed); i >= 0 {
+		typ = strings.TrimSpace(typ[:i] + typ[i+len(mysqlMariaDBCompressed):])
+	}
 	switch schema.dataType {
 	case "tinyint", "smallint", "mediumint", "int", "bigint":
 		if typ == "tinyint(1)" {
@@ -524,6 +2078,9 @@ func (schema *mysqlColumnSchema) ColumnType() string {
 		// NOTE: As of MySQL 8.0.17, the display width attribute is deprecated for integer data types.
 		//		 See https://dev.mysql.com/doc/refman/8.0/en/numeric-type-syntax.html
 		return trimParens(typ)
+	case "geomcollection":
+		// MySQL 8.0 reports GEOMETRYCOLLECTION as its synonym.
+		return "geometrycollection"
 	}
 	return typ
 }
@@ -552,17 +2109,15 @@ func (schema *mysqlColumnSchema) Default() (string, bool) {
 		return "", false
 	}
 	def := schema.columnDefault.String
-	v := schema.version
 	// See https://mariadb.com/kb/en/library/information-schema-columns-table/
-	if v.Name == "MariaDB" && v.Major >= 10 && v.Minor >= 2 && v.Patch >= 7 {
-		// unquote string
-		if len(def) > 0 && def[0] == '\'' {
-			def = def[1:]
+	if v := schema.version; v.isMariaDB() && v.atLeast(10, 2, 7) {
+		// The string literals are quoted and the others are the expressions such as NULL and current_timestamp().
+		if len(def) >= 2 && def[0] == '\'' && def[len(def)-1] == '\'' {
+			return strings.Replace(def[1:len(def)-1], "''", "'", -1), true // unescape string
 		}
-		if len(def) > 0 && def[len(def)-1] == '\'' {
-			def = def[:len(def)-1]
+		if strings.EqualFold(def, "current_timestamp()") {
+			def = "CURRENT_TIMESTAMP"
 		}
-		def = strings.Replace(def, "''", "'", -1) // unescape string
 	}
 	if def == "NULL" {
 		return "", false
@@ -583,10 +2138,59 @@ func (schema *mysqlColumnSchema) Extra() (string, bool) {
 	if schema.extra == "" || schema.IsAutoIncrement() {
 		return "", false
 	}
+	extra, _ := schema.splitExtra()
+	return extra, extra != ""
+}
+
+// OnUpdate returns the expression of ON UPDATE clause such as CURRENT_TIMESTAMP(3), which is not included in Extra.
+func (schema *mysqlColumnSchema) OnUpdate() (string, bool) {
+	_, onUpdate := schema.splitExtra()
+	return onUpdate, onUpdate != ""
+}
+
+// splitExtra returns EXTRA in upper case without ON UPDATE clause and the attributes that are reported by the other
+// methods, and the expression of ON UPDATE clause.
+func (schema *mysqlColumnSchema) splitExtra() (extra, onUpdate string) {
 	// Trim parenthesis from like "on update current_timestamp()".
-	extra := strings.TrimSuffix(schema.extra, "()")
-	extra = strings.ToUpper(extra)
-	return extra, true
+	extra = strings.ToUpper(strings.TrimSuffix(schema.extra, "()"))
+	// The compression is reported by IsCompressed instead.
+	extra = strings.Replace(extra, mysqlPerconaCompressed, "", 1)
+	// MySQL 8.0 reports DEFAULT_GENERATED for the expression defaults such as CURRENT_TIMESTAMP, which are reported
+	// by Default.
+	extra = strings.Replace(extra, "DEFAULT_GENERATED", "", 1)
+	if i := strings.Index(extra, "ON UPDATE "); i >= 0 {
+		fields := strings.Fields(extra[i+len("ON UPDATE "):])
+		if len(fields) > 0 {
+			onUpdate = fields[0]
+			extra = extra[:i] + strings.Join(fields[1:], " ")
+		}
+	}
+	return strings.Join(strings.Fields(extra), " "), onUpdate
+}
+
+// IsCompressed reports whether the column is the compressed column of MariaDB or Percona Server.
+func (schema *mysqlColumnSchema) IsCompressed() bool {
+	return strings.Contains(schema.columnType, mysqlMariaDBCompressed) || strings.Contains(strings.ToUpper(schema.extra), mysqlPerconaCompressed)
+}
+
+// SRID returns the ID of the spatial reference system of the spatial column of MySQL 8.0.3 or later.
+func (schema *mysqlColumnSchema) SRID() (uint32, bool) {
+	if !schema.srsID.Valid {
+		return 0, false
+	}
+	return uint32(schema.srsID.Int64), true
+}
+
+// Collation returns the character set and the collation of the string column, and whether the collation is the
+// default collation of the table.
+func (schema *mysqlColumnSchema) Collation() (charset, collation string, isDefault bool) {
+	return schema.characterSetName.String, schema.collationName.String, schema.collationName.String == schema.tableCollation.String
+}
+
+// IsGenerated reports whether the column is a virtual or stored generated column.
+func (schema *mysqlColumnSchema) IsGenerated() bool {
+	extra := strings.ToUpper(schema.extra)
+	return strings.Contains(extra, "VIRTUAL GENERATED") || strings.Contains(extra, "STORED GENERATED")
 }
 
 func (schema *mysqlColumnSchema) Comment() (string, bool) {
@@ -0,0 +1,243 @@
+package dialect_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/naoina/migu/dialect"
+)
+
+func TestMySQLServerVersion(t *testing.T) {
+	for _, v := range []struct {
+		version   string
+		expect    string
+		supported bool
+		err       bool
+	}{
+		{"5.6.51", "5.6.51", false, false},
+		{"5.7.44-log", "5.7.44-log", true, false},
+		{"8.0.32", "8.0.32", true, false},
+		{"8.0.32-0ubuntu0.20.04.2", "8.0.32-0ubuntu0.20.04.2", true, false},
+		{"10.1.48-MariaDB", "10.1.48-MariaDB", false, false},
+		{"10.6.12-MariaDB-1:10.6.12+maria~ubu2004", "10.6.12-MariaDB", true, false},
+		{"5.5.5-10.6.12-MariaDB-1:10.6.12+maria~ubu2004", "10.6.12-MariaDB", true, false},
+		{"8.0", "", false, true},
+		{"8.x.32", "", false, true},
+	} {
+		db := sql.OpenDB(&queryConnector{results: []queryResult{
+			{key: "SELECT VERSION()", columns: []string{"VERSION()"}, rows: [][]driver.Value{{v.version}}},
+		}})
+		version, supported, err := dialect.NewMySQL(db).(dialect.ServerVersioner).ServerVersion()
+		db.Close()
+		if (err != nil) != v.err {
+			t.Errorf("ServerVersion() of %q returns error %v; want error %v", v.version, err, v.err)
+			continue
+		}
+		if version != v.expect || supported != v.supported {
+			t.Errorf("ServerVersion() of %q => (%q, %v); want (%q, %v)", v.version, version, supported, v.expect, v.supported)
+		}
+	}
+}
+
+func TestMySQLColumnSchemaDefault(t *testing.T) {
+	column := func(name, typ, def string) []driver.Value {
+		var d driver.Value
+		if def != "<nil>" {
+			d = def
+		}
+		return []driver.Value{"user", name, d, "NO", typ, nil, nil, nil, nil, nil, typ, "", "", "", nil, nil, nil, nil}
+	}
+	for _, v := range []struct {
+		version string
+		rows    [][]driver.Value
+		expect  []string
+	}{
+		{"8.0.32", [][]driver.Value{
+			column("name", "varchar(255)", "it's"),
+			column("quoted", "varchar(255)", "'x'"),
+			column("created_at", "datetime", "CURRENT_TIMESTAMP"),
+			column("note", "text", "<nil>"),
+		}, []string{
+			`name "it's" true`,
+			`quoted "'x'" true`,
+			`created_at "CURRENT_TIMESTAMP" true`,
+			`note "" false`,
+		}},
+		{"5.5.5-10.6.12-MariaDB", [][]driver.Value{
+			column("name", "varchar(255)", "'it''s'"),
+			column("quoted", "varchar(255)", "'''x'''"),
+			column("created_at", "datetime", "current_timestamp()"),
+			column("note", "text", "NULL"),
+			column("age", "int", "0"),
+		}, []string{
+			`name "it's" true`,
+			`quoted "'x'" true`,
+			`created_at "CURRENT_TIMESTAMP" true`,
+			`note "" false`,
+			`age "0" true`,
+		}},
+		{"10.2.6-MariaDB", [][]driver.Value{
+			column("name", "varchar(255)", "it's"),
+		}, []string{
+			`name "it's" true`,
+		}},
+	} {
+		db := sql.OpenDB(&queryConnector{results: []queryResult{
+			{key: "SELECT DATABASE()", columns: []string{"DATABASE()"}, rows: [][]driver.Value{{"test"}}},
+			{key: "SELECT VERSION()", columns: []string{"VERSION()"}, rows: [][]driver.Value{{v.version}}},
+			{key: "FROM information_schema.STATISTICS", columns: []string{"TABLE_NAME", "COLUMN_NAME", "NON_UNIQUE", "INDEX_NAME"}},
+			{key: "FROM information_schema.CHECK_CONSTRAINTS", columns: []string{"TABLE_NAME", "CONSTRAINT_NAME"}},
+			{key: "FROM information_schema.COLUMNS", columns: make([]string, 18), rows: v.rows},
+		}})
+		schemas, err := dialect.NewMySQL(db).ColumnSchema()
+		db.Close()
+		if err != nil {
+			t.Errorf("%s: %v", v.version, err)
+			continue
+		}
+		var actual []string
+		for _, s := range schemas {
+			def, ok := s.Default()
+			actual = append(actual, fmt.Sprintf("%s %q %v", s.ColumnName(), def, ok))
+		}
+		if diff := cmp.Diff(actual, v.expect); diff != "" {
+			t.Errorf("%s: (-got +want)\n%v", v.version, diff)
+		}
+	}
+}
+
+func TestMySQLColumnSQL(t *testing.T) {
+	d := dialect.NewMySQL(nil)
+	for _, v := range []struct {
+		field  dialect.Field
+		expect string
+	}{
+		{dialect.Field{Name: "age", Type: "INT", Default: "-1"}, "`age` INT NOT NULL DEFAULT -1"},
+		{dialect.Field{Name: "rate", Type: "DOUBLE", Default: "1.5e3"}, "`rate` DOUBLE NOT NULL DEFAULT 1.5e3"},
+		{dialect.Field{Name: "flags", Type: "BIT(4)", Default: "b'0101'"}, "`flags` BIT(4) NOT NULL DEFAULT b'0101'"},
+		{dialect.Field{Name: "active", Type: "TINYINT(1)", Default: "TRUE"}, "`active` TINYINT(1) NOT NULL DEFAULT TRUE"},
+		{dialect.Field{Name: "created_at", Type: "DATETIME(3)", Default: "CURRENT_TIMESTAMP(3)"}, "`created_at` DATETIME(3) NOT NULL DEFAULT CURRENT_TIMESTAMP(3)"},
+		{dialect.Field{Name: "born_on", Type: "DATE", Default: "2000-01-01"}, "`born_on` DATE NOT NULL DEFAULT '2000-01-01'"},
+		{dialect.Field{Name: "id", Type: "BINARY(16)", Default: "(UUID_TO_BIN(UUID()))"}, "`id` BINARY(16) NOT NULL DEFAULT (UUID_TO_BIN(UUID()))"},
+		{dialect.Field{Name: "age", Type: "INT", Default: "1; DROP TABLE user"}, "`age` INT NOT NULL DEFAULT '1; DROP TABLE user'"},
+		{dialect.Field{Name: "status", Type: "ENUM('1','2')", Default: "2"}, "`status` ENUM('1','2') NOT NULL DEFAULT '2'"},
+		{dialect.Field{Name: "bio", Type: "MEDIUMTEXT", Default: "NULL"}, "`bio` MEDIUMTEXT NOT NULL DEFAULT 'NULL'"},
+		{dialect.Field{Name: "name", Type: "VARCHAR(255)", Comment: "first line\n\tsecond line"}, "`name` VARCHAR(255) NOT NULL COMMENT 'first line\\n\\tsecond line'"},
+	} {
+		v.field.Table = "user"
+		actual := d.AddColumnSQL(v.field)
+		expect := []string{"ALTER TABLE `user` ADD " + v.expect}
+		if diff := cmp.Diff(actual, expect); diff != "" {
+			t.Errorf("(-got +want)\n%v", diff)
+		}
+	}
+}
+
+func TestMySQLReadUser(t *testing.T) {
+	const (
+		nativeSecret = "*14E65567ABDB5135D0CFD9A70B3032C179A49EE7"
+		sha2Secret   = "$A$005$01234567890123456789" + "5fkz3MBocEd.OaaR71/j.w9RXSrKttgCSpI2fGX1100"
+	)
+	privileges := func(on string, privileges ...string) [][]driver.Value {
+		var rows [][]driver.Value
+		for _, p := range privileges {
+			rows = append(rows, []driver.Value{on, p})
+		}
+		return rows
+	}
+	all := []string{
+		"SELECT", "INSERT", "UPDATE", "DELETE", "CREATE", "DROP", "REFERENCES", "INDEX", "ALTER", "CREATE TEMPORARY TABLES",
+		"LOCK TABLES", "EXECUTE", "CREATE VIEW", "SHOW VIEW", "CREATE ROUTINE", "ALTER ROUTINE", "EVENT", "TRIGGER",
+	}
+	for _, v := range []struct {
+		user   dialect.User
+		users  [][]driver.Value
+		roles  [][]driver.Value
+		schema [][]driver.Value
+		tables [][]driver.Value
+		expect *dialect.UserState
+	}{
+		{
+			user:   dialect.User{Name: "app", Password: "secret"},
+			expect: nil,
+		},
+		{
+			user:   dialect.User{Name: "app", Password: "secret", Roles: []string{"app_read"}},
+			users:  [][]driver.Value{{"caching_sha2_password", sha2Secret}},
+			roles:  [][]driver.Value{{"app_read"}},
+			tables: append(privileges("user", "Select", "Insert"), privileges("post", "SELECT")...),
+			expect: &dialect.UserState{
+				PasswordMatches: true,
+				Roles:           []string{"app_read"},
+				Grants: []dialect.Grant{
+					{Privileges: []string{"SELECT", "INSERT"}, On: "user"},
+					{Privileges: []string{"SELECT"}, On: "post"},
+				},
+			},
+		},
+		{
+			user:   dialect.User{Name: "app", Password: "changed"},
+			users:  [][]driver.Value{{"caching_sha2_password", sha2Secret}},
+			expect: &dialect.UserState{},
+		},
+		{
+			user:   dialect.User{Name: "app", Password: "secret"},
+			users:  [][]driver.Value{{"mysql_native_password", nativeSecret}},
+			schema: privileges("*", all...),
+			expect: &dialect.UserState{
+				PasswordMatches: true,
+				Grants:          []dialect.Grant{{Privileges: append(append([]string{}, all...), "ALL", "ALL PRIVILEGES"), On: "*"}},
+			},
+		},
+		{
+			user:   dialect.User{Name: "app", Password: "secret"},
+			users:  [][]driver.Value{{"sha256_password", "$5$salt$digest"}},
+			schema: privileges("*", "SELECT"),
+			expect: &dialect.UserState{Grants: []dialect.Grant{{Privileges: []string{"SELECT"}, On: "*"}}},
+		},
+		{
+			user:   dialect.User{Name: "app_read", Role: true},
+			users:  [][]driver.Value{{"caching_sha2_password", ""}},
+			expect: &dialect.UserState{PasswordMatches: true},
+		},
+	} {
+		db := sql.OpenDB(&queryConnector{results: []queryResult{
+			{key: "FROM mysql.user", columns: []string{"plugin", "authentication_string"}, rows: v.users},
+			{key: "FROM mysql.role_edges", columns: []string{"FROM_USER"}, rows: v.roles},
+			{key: "FROM information_schema.SCHEMA_PRIVILEGES", columns: []string{"*", "PRIVILEGE_TYPE"}, rows: v.schema},
+			{key: "FROM information_schema.TABLE_PRIVILEGES", columns: []string{"TABLE_NAME", "PRIVILEGE_TYPE"}, rows: v.tables},
+		}})
+		actual, err := dialect.NewMySQL(db).(dialect.UserReader).ReadUser(v.user)
+		db.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(actual, v.expect); diff != "" {
+			t.Errorf("ReadUser(%+v) (-got +want)\n%v", v.user, diff)
+		}
+	}
+}
+
+func TestMySQLAlterUserSQL(t *testing.T) {
+	d := dialect.NewMySQL(nil).(dialect.UserReader)
+	for _, v := range []struct {
+		user           dialect.User
+		changePassword bool
+		expect         []string
+	}{
+		{dialect.User{Name: "app", Password: "pa'ss"}, true, []string{"ALTER USER 'app'@'%' IDENTIFIED BY 'pa''ss'"}},
+		{dialect.User{Name: "app", Host: "10.0.0.%", Roles: []string{"app_read"}}, false, []string{
+			"GRANT 'app_read' TO 'app'@'10.0.0.%'",
+			"SET DEFAULT ROLE ALL TO 'app'@'10.0.0.%'",
+		}},
+		{dialect.User{Name: "app_read", Role: true}, true, nil},
+	} {
+		actual := d.AlterUserSQL(v.user, v.changePassword)
+		if diff := cmp.Diff(actual, v.expect); diff != "" {
+			t.Errorf("AlterUserSQL(%+v, %v) (-got +want)\n%v", v.user, v.changePassword, diff)
+		}
+	}
+}
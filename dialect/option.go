@@ -5,6 +5,8 @@ type Option func(*option)
 
 type option struct {
 	columnTypes []*ColumnType
+	pluginArgs  []string
+	schema      string
 }
 
 func newOption() *option {
@@ -17,3 +19,18 @@ func WithColumnType(columnTypes []*ColumnType) Option {
 		o.columnTypes = columnTypes
 	}
 }
+
+// WithPluginArgs specifies the command-line arguments of the plugin process for NewPlugin.
+func WithPluginArgs(args ...string) Option {
+	return func(o *option) {
+		o.pluginArgs = args
+	}
+}
+
+// WithSchema specifies the schema of the tables for the dialects that have the schemas in a database such as
+// PostgreSQL. The current schema of the connection is used by default.
+func WithSchema(schema string) Option {
+	return func(o *option) {
+		o.schema = schema
+	}
+}
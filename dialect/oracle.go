@@ -0,0 +1,761 @@
+package dialect
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	_ PrimaryKeyModifier = &Oracle{}
+	_ IndexReader        = &Oracle{}
+	_ TableRenamer       = &Oracle{}
+	_ ColumnRenamer      = &Oracle{}
+	_ TableAnalyzer      = &Oracle{}
+)
+
+const (
+	// oraclePrimaryKeyIndex is the name of the primary key that is returned by Indexes in the same way as MySQL.
+	oraclePrimaryKeyIndex = "PRIMARY"
+
+	// oracleDefaultTimestampPrecision is the precision of TIMESTAMP that is used if it is omitted.
+	oracleDefaultTimestampPrecision = "6"
+)
+
+var (
+	oracleColumnTypes = []*ColumnType{
+		{
+			Types:           []string{"VARCHAR2(255)", "VARCHAR2", "NVARCHAR2", "CHAR", "NCHAR", "CLOB", "NCLOB"},
+			GoTypes:         []string{"string"},
+			GoNullableTypes: []string{"*string", "sql.NullString"},
+		},
+		{
+			Types:           []string{"BLOB", "RAW"},
+			GoTypes:         []string{"[]byte"},
+			GoNullableTypes: []string{"[]byte"},
+		},
+		{
+			// NUMBER(19) can hold all the values of int64.
+			Types:           []string{"NUMBER(19)"},
+			GoTypes:         []string{"int64", "int"},
+			GoNullableTypes: []string{"*int64", "sql.NullInt64"},
+		},
+		{
+			Types:           []string{"NUMBER(10)"},
+			GoTypes:         []string{"int32", "uint32"},
+			GoNullableTypes: []string{"*int32", "sql.NullInt32"},
+		},
+		{
+			Types:   []string{"NUMBER(5)"},
+			GoTypes: []string{"int16", "uint16"},
+		},
+		{
+			Types:   []string{"NUMBER(3)"},
+			GoTypes: []string{"int8", "uint8"},
+		},
+		{
+			// NUMBER(20) can hold all the values of uint64.
+			Types:   []string{"NUMBER(20)"},
+			GoTypes: []string{"uint64", "uint"},
+		},
+		{
+			Types:           []string{"NUMBER(1)"},
+			GoTypes:         []string{"bool"},
+			GoNullableTypes: []string{"*bool", "sql.NullBool"},
+		},
+		{
+			Types:           []string{"BINARY_DOUBLE", "NUMBER", "FLOAT"},
+			GoTypes:         []string{"float64"},
+			GoNullableTypes: []string{"*float64", "sql.NullFloat64"},
+		},
+		{
+			Types:   []string{"BINARY_FLOAT"},
+			GoTypes: []string{"float32"},
+		},
+		{
+			Types:           []string{"TIMESTAMP(6) WITH TIME ZONE", "TIMESTAMP WITH TIME ZONE", "TIMESTAMP WITH LOCAL TIME ZONE", "TIMESTAMP", "DATE"},
+			GoTypes:         []string{"time.Time"},
+			GoNullableTypes: []string{"*time.Time", "sql.NullTime"},
+		},
+	}
+
+	// oracleTypeAliases are the names of the ANSI types that are converted to the types of Oracle.
+	oracleTypeAliases = map[string]string{
+		"VARCHAR":           "VARCHAR2",
+		"CHARACTER VARYING": "VARCHAR2",
+		"CHARACTER":         "CHAR",
+		"INTEGER":           "NUMBER(38)",
+		"INT":               "NUMBER(38)",
+		"SMALLINT":          "NUMBER(38)",
+		"DECIMAL":           "NUMBER",
+		"DEC":               "NUMBER",
+		"NUMERIC":           "NUMBER",
+		"DOUBLE PRECISION":  "FLOAT(126)",
+		"REAL":              "FLOAT(63)",
+	}
+)
+
+// Oracle is the dialect of Oracle Database 12c or later.
+// The tables are in the schema that is specified by WithSchema, or the current schema of the session.
+//
+// The integers are NUMBER(p) that can hold all the values of the Go's type, and NUMBER(p) is read as the smallest
+// Go's integer type that can hold all the values of it. The auto-increment columns are the identity columns.
+// The identity cannot be added to the existing columns by ALTER TABLE, so that such changes are returned as the
+// comments instead of SQLs. Oracle commits the transaction implicitly before and after each DDL, so that the schema
+// changes are not rolled back on an error.
+type Oracle struct {
+	db              *sql.DB
+	opt             *option
+	schemaName      string
+	columnTypeMap   map[string]*ColumnType
+	nullableTypeMap map[string]struct{}
+}
+
+// NewOracle returns a new dialect of Oracle. db must be opened by the driver of Oracle such as godror or go-ora.
+func NewOracle(db *sql.DB, opts ...Option) Dialect {
+	d := &Oracle{
+		db:              db,
+		opt:             newOption(),
+		columnTypeMap:   map[string]*ColumnType{},
+		nullableTypeMap: map[string]struct{}{},
+	}
+	for _, o := range opts {
+		o(d.opt)
+	}
+	for _, types := range [][]*ColumnType{oracleColumnTypes, d.opt.columnTypes} {
+		for _, t := range types {
+			for _, tt := range t.allGoTypes() {
+				d.columnTypeMap[tt] = t
+			}
+			for _, tt := range t.filteredNullableGoTypes() {
+				d.nullableTypeMap[tt] = struct{}{}
+			}
+		}
+	}
+	return d
+}
+
+func (d *Oracle) ColumnSchema(tables ...string) ([]ColumnSchema, error) {
+	schema, err := d.currentSchema()
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := d.Indexes(tables...)
+	if err != nil {
+		return nil, err
+	}
+	primaryKeys := make(map[string]map[string]bool)
+	indexMap := make(map[string]map[string]Index)
+	for _, index := range indexes {
+		if index.Name == oraclePrimaryKeyIndex {
+			for _, c := range index.Columns {
+				if _, exists := primaryKeys[index.Table]; !exists {
+					primaryKeys[index.Table] = make(map[string]bool)
+				}
+				primaryKeys[index.Table][c] = true
+			}
+			continue
+		}
+		for _, c := range index.Columns {
+			if _, exists := indexMap[index.Table]; !exists {
+				indexMap[index.Table] = make(map[string]Index)
+			}
+			indexMap[index.Table][c] = index
+		}
+	}
+	parts := []string{
+		"SELECT",
+		"  c.TABLE_NAME,",
+		"  c.COLUMN_NAME,",
+		"  c.DATA_TYPE,",
+		"  c.CHAR_LENGTH,",
+		"  c.DATA_LENGTH,",
+		"  c.DATA_PRECISION,",
+		"  c.DATA_SCALE,",
+		"  c.NULLABLE,",
+		"  c.DATA_DEFAULT,",
+		"  c.IDENTITY_COLUMN,",
+		"  cc.COMMENTS",
+		"FROM ALL_TAB_COLUMNS c",
+		"JOIN ALL_TABLES t ON t.OWNER = c.OWNER AND t.TABLE_NAME = c.TABLE_NAME",
+		"LEFT JOIN ALL_COL_COMMENTS cc ON cc.OWNER = c.OWNER AND cc.TABLE_NAME = c.TABLE_NAME AND cc.COLUMN_NAME = c.COLUMN_NAME",
+		"WHERE c.OWNER = :1",
+	}
+	args := []interface{}{schema}
+	if len(tables) > 0 {
+		parts = append(parts, fmt.Sprintf("AND c.TABLE_NAME IN (%s)", oraclePlaceholders(2, len(tables))))
+		for _, t := range tables {
+			args = append(args, t)
+		}
+	}
+	parts = append(parts, "ORDER BY c.TABLE_NAME, c.COLUMN_ID")
+	rows, err := d.db.Query(strings.Join(parts, "\n"), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var schemas []ColumnSchema
+	for rows.Next() {
+		schema := &oracleColumnSchema{}
+		if err := rows.Scan(
+			&schema.tableName,
+			&schema.columnName,
+			&schema.dataType,
+			&schema.charLength,
+			&schema.dataLength,
+			&schema.dataPrecision,
+			&schema.dataScale,
+			&schema.nullable,
+			&schema.dataDefault,
+			&schema.identityColumn,
+			&schema.comments,
+		); err != nil {
+			return nil, err
+		}
+		schema.primaryKey = primaryKeys[schema.tableName][schema.columnName]
+		if index, exists := indexMap[schema.tableName][schema.columnName]; exists {
+			schema.indexName = index.Name
+			schema.unique = index.Unique
+		}
+		schemas = append(schemas, schema)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return schemas, nil
+}
+
+func (d *Oracle) ColumnType(name string) string {
+	if t, ok := d.columnTypeMap[name]; ok {
+		name, _, _, _ = t.findType(name)
+	}
+	return oracleCanonicalType(name)
+}
+
+func (d *Oracle) GoType(name string, nullable bool) string {
+	return d.goType(oracleCanonicalType(name), nullable)
+}
+
+func (d *Oracle) goType(name string, nullable bool) string {
+	for _, types := range [][]*ColumnType{d.opt.columnTypes, oracleColumnTypes} {
+		for _, t := range types {
+			if typ, found := t.findGoType(name, nullable, false); found {
+				return typ
+			}
+		}
+	}
+	if typ := oracleNumberGoType(name); typ != "" {
+		return d.goType(typ, nullable)
+	}
+	if strings.IndexByte(name, '(') >= 0 {
+		return d.goType(trimParens(name), nullable)
+	}
+	return "interface{}"
+}
+
+func (d *Oracle) IsNullable(name string) bool {
+	_, ok := d.nullableTypeMap[name]
+	return ok
+}
+
+func (d *Oracle) ImportPackage(schema ColumnSchema) string {
+	if typ := schema.DataType(); typ == "DATE" || strings.HasPrefix(typ, "TIMESTAMP") {
+		return "time"
+	}
+	return ""
+}
+
+func (d *Oracle) GoTypeImports() ([]Import, error) {
+	return goTypeImports(d.opt.columnTypes)
+}
+
+func (d *Oracle) Quote(s string) string {
+	return quoteByDoubling(s, `"`, false)
+}
+
+func (d *Oracle) QuoteString(s string) string {
+	return quoteByDoubling(s, "'", false)
+}
+
+func (d *Oracle) CreateTableSQL(table Table) []string {
+	columns := make([]string, len(table.Fields))
+	for i, f := range table.Fields {
+		columns[i] = d.columnSQL(f)
+	}
+	if len(table.PrimaryKeys) > 0 {
+		pkColumns := make([]string, len(table.PrimaryKeys))
+		for i, pk := range table.PrimaryKeys {
+			pkColumns[i] = d.Quote(pk)
+		}
+		columns = append(columns, fmt.Sprintf("CONSTRAINT %s PRIMARY KEY (%s)", d.Quote("PK_"+table.Name), strings.Join(pkColumns, ", ")))
+	}
+	query := fmt.Sprintf("CREATE TABLE %s (\n"+
+		"  %s\n"+
+		")", d.table(table.Name), strings.Join(columns, ",\n  "))
+	if table.Option != "" {
+		query += " " + table.Option
+	}
+	sqls := []string{query}
+	for _, f := range table.Fields {
+		if f.Comment != "" {
+			sqls = append(sqls, d.commentSQL(f))
+		}
+	}
+	return sqls
+}
+
+func (d *Oracle) AddColumnSQL(field Field) []string {
+	sqls := []string{fmt.Sprintf("ALTER TABLE %s ADD (%s)", d.table(field.Table), d.columnSQL(field))}
+	if field.Comment != "" {
+		sqls = append(sqls, d.commentSQL(field))
+	}
+	return sqls
+}
+
+func (d *Oracle) DropColumnSQL(field Field) []string {
+	return []string{fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", d.table(field.Table), d.Quote(field.Name))}
+}
+
+func (d *Oracle) ModifyColumnSQL(oldField, newField Field) []string {
+	var sqls []string
+	tableName := d.table(newField.Table)
+	if oldField.Name != newField.Name {
+		sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", tableName, d.Quote(oldField.Name), d.Quote(newField.Name)))
+	}
+	column := d.Quote(newField.Name)
+	switch {
+	case !oldField.AutoIncrement && newField.AutoIncrement:
+		sqls = append(sqls, fmt.Sprintf("-- identity cannot be added to column %s.%s by ALTER TABLE", tableName, column))
+	case oldField.AutoIncrement && !newField.AutoIncrement:
+		sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s MODIFY (%s DROP IDENTITY)", tableName, column))
+	}
+	// MODIFY fails if the column already has the same nullability, so that only the changes are specified.
+	var specs []string
+	if oldField.Type != newField.Type {
+		specs = append(specs, newField.Type)
+	}
+	if oldField.Default != newField.Default {
+		if newField.Default == "" {
+			specs = append(specs, "DEFAULT NULL")
+		} else {
+			specs = append(specs, "DEFAULT", d.defaultSQL(newField))
+		}
+	}
+	if oldField.Nullable != newField.Nullable {
+		if newField.Nullable {
+			specs = append(specs, "NULL")
+		} else {
+			specs = append(specs, "NOT NULL")
+		}
+	}
+	if len(specs) > 0 {
+		sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s MODIFY (%s %s)", tableName, column, strings.Join(specs, " ")))
+	}
+	if oldField.Comment != newField.Comment {
+		sqls = append(sqls, d.commentSQL(newField))
+	}
+	return sqls
+}
+
+func (d *Oracle) RenameColumnSQL(oldField, newField Field) []string {
+	return d.ModifyColumnSQL(oldField, newField)
+}
+
+func (d *Oracle) ModifyPrimaryKeySQL(oldPrimaryKeys, newPrimaryKeys []Field) []string {
+	var tableName string
+	if len(newPrimaryKeys) > 0 {
+		tableName = newPrimaryKeys[0].Table
+	} else {
+		tableName = oldPrimaryKeys[0].Table
+	}
+	var sqls []string
+	if len(oldPrimaryKeys) > 0 {
+		sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s DROP PRIMARY KEY DROP INDEX", d.table(tableName)))
+	}
+	if len(newPrimaryKeys) > 0 {
+		pkColumns := make([]string, len(newPrimaryKeys))
+		for i, pk := range newPrimaryKeys {
+			pkColumns[i] = d.Quote(pk.Name)
+		}
+		sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s PRIMARY KEY (%s)", d.table(tableName), d.Quote("PK_"+tableName), strings.Join(pkColumns, ", ")))
+	}
+	return sqls
+}
+
+// RenameTableSQL returns the SQL to rename the table. The new name cannot be qualified by the schema in Oracle.
+func (d *Oracle) RenameTableSQL(oldName, newName string) []string {
+	return []string{fmt.Sprintf("ALTER TABLE %s RENAME TO %s", d.table(oldName), d.Quote(newName))}
+}
+
+func (d *Oracle) AnalyzeTableSQL(table string) []string {
+	return []string{fmt.Sprintf("BEGIN DBMS_STATS.GATHER_TABLE_STATS(ownname => %s, tabname => %s); END;", d.schemaLiteral(), d.QuoteString(table))}
+}
+
+func (d *Oracle) CreateIndexSQL(index Index) []string {
+	columns := make([]string, len(index.Columns))
+	for i, c := range index.Columns {
+		columns[i] = d.Quote(c)
+	}
+	// The index is in the same schema as the table because the names of the indexes are unique in the schema.
+	indexName := d.table(index.Name)
+	tableName := d.table(index.Table)
+	column := strings.Join(columns, ", ")
+	if index.Unique {
+		return []string{fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (%s)", indexName, tableName, column)}
+	}
+	return []string{fmt.Sprintf("CREATE INDEX %s ON %s (%s)", indexName, tableName, column)}
+}
+
+func (d *Oracle) DropIndexSQL(index Index) []string {
+	return []string{fmt.Sprintf("DROP INDEX %s", d.table(index.Name))}
+}
+
+// SchemaLimits returns the limits of Oracle Database 12.2 or later, where the identifiers can be 128 bytes long.
+func (d *Oracle) SchemaLimits(table Table) SchemaLimits {
+	return SchemaLimits{
+		MaxIdentifierLength:     128,
+		IdentifierLengthInBytes: true,
+		MaxColumns:              1000,
+		MaxIndexColumns:         32,
+	}
+}
+
+func (d *Oracle) Begin() (Transactioner, error) {
+	conn, err := d.db.Conn(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return &oracleTransaction{
+		conn: conn,
+	}, nil
+}
+
+func (d *Oracle) Indexes(tables ...string) ([]Index, error) {
+	schema, err := d.currentSchema()
+	if err != nil {
+		return nil, err
+	}
+	parts := []string{
+		"SELECT",
+		"  i.TABLE_NAME,",
+		"  i.INDEX_NAME,",
+		"  ic.COLUMN_NAME,",
+		"  i.UNIQUENESS,",
+		"  CASE WHEN pk.CONSTRAINT_NAME IS NULL THEN 0 ELSE 1 END",
+		"FROM ALL_INDEXES i",
+		"JOIN ALL_IND_COLUMNS ic ON ic.INDEX_OWNER = i.OWNER AND ic.INDEX_NAME = i.INDEX_NAME",
+		"LEFT JOIN ALL_CONSTRAINTS pk ON pk.OWNER = i.TABLE_OWNER AND pk.TABLE_NAME = i.TABLE_NAME",
+		"  AND pk.INDEX_NAME = i.INDEX_NAME AND pk.CONSTRAINT_TYPE = 'P'",
+		"WHERE i.TABLE_OWNER = :1",
+	}
+	args := []interface{}{schema}
+	if len(tables) > 0 {
+		parts = append(parts, fmt.Sprintf("AND i.TABLE_NAME IN (%s)", oraclePlaceholders(2, len(tables))))
+		for _, t := range tables {
+			args = append(args, t)
+		}
+	}
+	parts = append(parts, "ORDER BY i.TABLE_NAME, i.INDEX_NAME, ic.COLUMN_POSITION")
+	rows, err := d.db.Query(strings.Join(parts, "\n"), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var indexes []Index
+	for rows.Next() {
+		var (
+			tableName  string
+			indexName  string
+			columnName string
+			uniqueness string
+			primary    int64
+		)
+		if err := rows.Scan(&tableName, &indexName, &columnName, &uniqueness, &primary); err != nil {
+			return nil, err
+		}
+		if primary != 0 {
+			indexName = oraclePrimaryKeyIndex
+		}
+		if n := len(indexes); n > 0 && indexes[n-1].Table == tableName && indexes[n-1].Name == indexName {
+			indexes[n-1].Columns = append(indexes[n-1].Columns, columnName)
+			continue
+		}
+		indexes = append(indexes, Index{
+			Table:   tableName,
+			Name:    indexName,
+			Columns: []string{columnName},
+			Unique:  uniqueness == "UNIQUE",
+		})
+	}
+	return indexes, rows.Err()
+}
+
+// table returns the quoted name of the table that is qualified by the schema if WithSchema is specified.
+func (d *Oracle) table(name string) string {
+	if d.opt.schema == "" {
+		return d.Quote(name)
+	}
+	return d.Quote(d.opt.schema) + "." + d.Quote(name)
+}
+
+func (d *Oracle) currentSchema() (string, error) {
+	if d.opt.schema != "" {
+		return d.opt.schema, nil
+	}
+	if d.schemaName != "" {
+		return d.schemaName, nil
+	}
+	if err := d.db.QueryRow(`SELECT SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA') FROM DUAL`).Scan(&d.schemaName); err != nil {
+		return "", err
+	}
+	return d.schemaName, nil
+}
+
+// schemaLiteral returns the schema of the tables for the PL/SQL procedures.
+func (d *Oracle) schemaLiteral() string {
+	if d.opt.schema != "" {
+		return d.QuoteString(d.opt.schema)
+	}
+	return "SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA')"
+}
+
+func (d *Oracle) columnSQL(f Field) string {
+	column := []string{d.Quote(f.Name), f.Type}
+	if f.Default != "" {
+		column = append(column, "DEFAULT", d.defaultSQL(f))
+	}
+	if f.AutoIncrement {
+		column = append(column, "GENERATED BY DEFAULT ON NULL AS IDENTITY")
+	}
+	if !f.Nullable {
+		column = append(column, "NOT NULL")
+	}
+	if f.Extra != "" {
+		column = append(column, f.Extra)
+	}
+	return strings.Join(column, " ")
+}
+
+func (d *Oracle) defaultSQL(f Field) string {
+	if d.isTextType(f) {
+		return d.QuoteString(f.Default)
+	}
+	return f.Default
+}
+
+// commentSQL returns the SQL to set the comment of the column. The comment is removed if it is empty.
+func (d *Oracle) commentSQL(f Field) string {
+	return fmt.Sprintf("COMMENT ON COLUMN %s.%s IS %s", d.table(f.Table), d.Quote(f.Name), d.QuoteString(f.Comment))
+}
+
+func (d *Oracle) isTextType(f Field) bool {
+	typ := strings.ToUpper(f.Type)
+	for _, t := range []string{"VARCHAR2", "NVARCHAR2", "CHAR", "NCHAR", "CLOB", "NCLOB"} {
+		if strings.HasPrefix(typ, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// oracleCanonicalType returns the name of the type in the same form as the column type that is read from
+// ALL_TAB_COLUMNS in upper case. (e.g. "integer" to "NUMBER(38)", "number(10, 0)" to "NUMBER(10)", "timestamp" to
+// "TIMESTAMP(6)")
+func oracleCanonicalType(name string) string {
+	name = strings.ToUpper(strings.Join(strings.Fields(name), " "))
+	base, params, suffix := name, "", ""
+	if i := strings.IndexByte(name, '('); i >= 0 {
+		if j := strings.IndexByte(name[i:], ')'); j >= 0 {
+			base, params, suffix = strings.TrimSpace(name[:i]), strings.Replace(name[i+1:i+j], " ", "", -1), strings.TrimSpace(name[i+j+1:])
+		}
+	}
+	if strings.HasPrefix(base, "TIMESTAMP ") {
+		base, suffix = "TIMESTAMP", strings.TrimPrefix(base, "TIMESTAMP ")
+	}
+	if alias, ok := oracleTypeAliases[base]; ok {
+		base = alias
+		if i := strings.IndexByte(alias, '('); i >= 0 {
+			base, params = alias[:i], alias[i+1:len(alias)-1]
+		}
+	}
+	switch base {
+	case "VARCHAR2", "NVARCHAR2", "CHAR", "NCHAR":
+		// The length semantics are not distinguished.
+		params = strings.TrimSuffix(strings.TrimSuffix(params, "CHAR"), "BYTE")
+		if params == "" {
+			params = "1"
+		}
+	case "NUMBER":
+		switch {
+		case strings.HasPrefix(params, "*,"):
+			params = "38" + params[1:]
+		case params == "*":
+			params = ""
+		}
+		params = strings.TrimSuffix(params, ",0")
+	case "TIMESTAMP":
+		if params == "" {
+			params = oracleDefaultTimestampPrecision
+		}
+	}
+	if params != "" {
+		base += "(" + params + ")"
+	}
+	if suffix != "" {
+		base += " " + suffix
+	}
+	return base
+}
+
+// oracleNumberGoType returns the type of NUMBER(p) that is the smallest one of the integer types of oracleColumnTypes
+// that can hold all the values of NUMBER(p), or the empty string if name is not NUMBER(p).
+func oracleNumberGoType(name string) string {
+	if !strings.HasPrefix(name, "NUMBER(") || strings.IndexByte(name, ',') >= 0 {
+		return ""
+	}
+	p, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, "NUMBER("), ")"))
+	if err != nil {
+		return ""
+	}
+	switch {
+	case p <= 2:
+		return "NUMBER(3)"
+	case p <= 4:
+		return "NUMBER(5)"
+	case p <= 9:
+		return "NUMBER(10)"
+	case p <= 19:
+		return "NUMBER(19)"
+	}
+	return "NUMBER"
+}
+
+// oraclePlaceholders returns n placeholders that start from :<start> separated by commas.
+func oraclePlaceholders(start, n int) string {
+	placeholders := make([]string, n)
+	for i := range placeholders {
+		placeholders[i] = fmt.Sprintf(":%d", start+i)
+	}
+	return strings.Join(placeholders, ",")
+}
+
+// oracleTransaction executes the statements one by one on a connection without a transaction because Oracle
+// commits implicitly for each DDL.
+type oracleTransaction struct {
+	conn *sql.Conn
+}
+
+func (o *oracleTransaction) Exec(sql string, args ...interface{}) error {
+	_, err := o.conn.ExecContext(context.Background(), sql, args...)
+	return err
+}
+
+func (o *oracleTransaction) Commit() error {
+	return o.conn.Close()
+}
+
+// Rollback does not roll back the executed statements.
+func (o *oracleTransaction) Rollback() error {
+	return o.conn.Close()
+}
+
+var _ ColumnSchema = &oracleColumnSchema{}
+
+type oracleColumnSchema struct {
+	tableName      string
+	columnName     string
+	dataType       string
+	charLength     sql.NullInt64
+	dataLength     sql.NullInt64
+	dataPrecision  sql.NullInt64
+	dataScale      sql.NullInt64
+	nullable       string
+	dataDefault    sql.NullString
+	identityColumn sql.NullString
+	comments       sql.NullString
+	primaryKey     bool
+	indexName      string
+	unique         bool
+}
+
+func (schema *oracleColumnSchema) TableName() string {
+	return schema.tableName
+}
+
+func (schema *oracleColumnSchema) ColumnName() string {
+	return schema.columnName
+}
+
+func (schema *oracleColumnSchema) ColumnType() string {
+	typ := schema.dataType
+	switch typ {
+	case "VARCHAR2", "NVARCHAR2", "CHAR", "NCHAR":
+		return fmt.Sprintf("%s(%d)", typ, schema.charLength.Int64)
+	case "RAW":
+		return fmt.Sprintf("%s(%d)", typ, schema.dataLength.Int64)
+	case "NUMBER":
+		switch {
+		case schema.dataPrecision.Valid && schema.dataScale.Int64 != 0:
+			return fmt.Sprintf("NUMBER(%d,%d)", schema.dataPrecision.Int64, schema.dataScale.Int64)
+		case schema.dataPrecision.Valid:
+			return fmt.Sprintf("NUMBER(%d)", schema.dataPrecision.Int64)
+		case schema.dataScale.Valid && schema.dataScale.Int64 == 0:
+			// NUMBER(*,0) such as INTEGER.
+			return "NUMBER(38)"
+		}
+	case "FLOAT":
+		if schema.dataPrecision.Valid {
+			return fmt.Sprintf("FLOAT(%d)", schema.dataPrecision.Int64)
+		}
+	}
+	return typ
+}
+
+func (schema *oracleColumnSchema) DataType() string {
+	return schema.dataType
+}
+
+func (schema *oracleColumnSchema) IsPrimaryKey() bool {
+	return schema.primaryKey
+}
+
+func (schema *oracleColumnSchema) IsAutoIncrement() bool {
+	return schema.identityColumn.String == "YES"
+}
+
+func (schema *oracleColumnSchema) Index() (name string, unique bool, ok bool) {
+	if schema.indexName != "" {
+		return schema.indexName, schema.unique, true
+	}
+	return "", false, false
+}
+
+// Default returns the default value of the column. The default value of the identity column is the sequence that
+// is generated by Oracle, so that it is ignored.
+func (schema *oracleColumnSchema) Default() (string, bool) {
+	if !schema.dataDefault.Valid || schema.IsAutoIncrement() {
+		return "", false
+	}
+	def := strings.TrimSpace(schema.dataDefault.String)
+	if def == "" || strings.ToUpper(def) == "NULL" {
+		return "", false
+	}
+	if len(def) >= 2 && def[0] == '\'' && def[len(def)-1] == '\'' {
+		def = strings.Replace(def[1:len(def)-1], "''", "'", -1) // unescape string
+	}
+	return def, true
+}
+
+func (schema *oracleColumnSchema) IsNullable() bool {
+	return schema.nullable == "Y"
+}
+
+func (schema *oracleColumnSchema) Extra() (string, bool) {
+	return "", false
+}
+
+func (schema *oracleColumnSchema) Comment() (string, bool) {
+	return schema.comments.String, schema.comments.String != ""
+}
@@ -0,0 +1,118 @@
+package dialect_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/naoina/migu/dialect"
+)
+
+func TestOracleColumnType(t *testing.T) {
+	d := dialect.NewOracle(nil)
+	for _, v := range []struct {
+		name   string
+		expect string
+	}{
+		{"string", "VARCHAR2(255)"},
+		{"int", "NUMBER(19)"},
+		{"int32", "NUMBER(10)"},
+		{"uint64", "NUMBER(20)"},
+		{"bool", "NUMBER(1)"},
+		{"float64", "BINARY_DOUBLE"},
+		{"time.Time", "TIMESTAMP(6) WITH TIME ZONE"},
+		{"[]byte", "BLOB"},
+		{"varchar2(100 char)", "VARCHAR2(100)"},
+		{"varchar(100)", "VARCHAR2(100)"},
+		{"integer", "NUMBER(38)"},
+		{"number(10, 0)", "NUMBER(10)"},
+		{"number(10, 2)", "NUMBER(10,2)"},
+		{"timestamp", "TIMESTAMP(6)"},
+		{"timestamp(3) with time zone", "TIMESTAMP(3) WITH TIME ZONE"},
+	} {
+		if actual := d.ColumnType(v.name); actual != v.expect {
+			t.Errorf("ColumnType(%q) => %q; want %q", v.name, actual, v.expect)
+		}
+	}
+	for _, v := range []struct {
+		name     string
+		nullable bool
+		expect   string
+	}{
+		{"VARCHAR2(100)", false, "string"},
+		{"CLOB", true, "*string"},
+		{"NUMBER(1)", false, "bool"},
+		{"NUMBER(2)", false, "int8"},
+		{"NUMBER(4)", false, "int16"},
+		{"NUMBER(9)", true, "*int32"},
+		{"NUMBER(19)", false, "int64"},
+		{"NUMBER(20)", false, "uint64"},
+		{"NUMBER(38)", false, "float64"},
+		{"NUMBER(10,2)", false, "float64"},
+		{"NUMBER", false, "float64"},
+		{"TIMESTAMP(3)", false, "time.Time"},
+		{"DATE", true, "*time.Time"},
+	} {
+		if actual := d.GoType(v.name, v.nullable); actual != v.expect {
+			t.Errorf("GoType(%q, %v) => %q; want %q", v.name, v.nullable, actual, v.expect)
+		}
+	}
+}
+
+func TestOracleSQL(t *testing.T) {
+	d := dialect.NewOracle(nil, dialect.WithSchema("APP"))
+	id := dialect.Field{Table: "user", Name: "id", Type: "NUMBER(19)", AutoIncrement: true}
+	name := dialect.Field{Table: "user", Name: "name", Type: "VARCHAR2(255)", Default: "it's", Comment: "user's name"}
+	for _, v := range []struct {
+		actual []string
+		expect []string
+	}{
+		{
+			d.CreateTableSQL(dialect.Table{Name: "user", Fields: []dialect.Field{id, name}, PrimaryKeys: []string{"id"}}),
+			[]string{
+				"CREATE TABLE \"APP\".\"user\" (\n" +
+					"  \"id\" NUMBER(19) GENERATED BY DEFAULT ON NULL AS IDENTITY NOT NULL,\n" +
+					"  \"name\" VARCHAR2(255) DEFAULT 'it''s' NOT NULL,\n" +
+					"  CONSTRAINT \"PK_user\" PRIMARY KEY (\"id\")\n" +
+					")",
+				"COMMENT ON COLUMN \"APP\".\"user\".\"name\" IS 'user''s name'",
+			},
+		},
+		{
+			d.ModifyColumnSQL(name, dialect.Field{Table: "user", Name: "full_name", Type: "CLOB", Nullable: true}),
+			[]string{
+				"ALTER TABLE \"APP\".\"user\" RENAME COLUMN \"name\" TO \"full_name\"",
+				"ALTER TABLE \"APP\".\"user\" MODIFY (\"full_name\" CLOB DEFAULT NULL NULL)",
+				"COMMENT ON COLUMN \"APP\".\"user\".\"full_name\" IS ''",
+			},
+		},
+		{
+			d.ModifyColumnSQL(id, dialect.Field{Table: "user", Name: "id", Type: "NUMBER(19)"}),
+			[]string{
+				"ALTER TABLE \"APP\".\"user\" MODIFY (\"id\" DROP IDENTITY)",
+			},
+		},
+		{
+			d.(dialect.PrimaryKeyModifier).ModifyPrimaryKeySQL([]dialect.Field{id}, []dialect.Field{id, name}),
+			[]string{
+				"ALTER TABLE \"APP\".\"user\" DROP PRIMARY KEY DROP INDEX",
+				"ALTER TABLE \"APP\".\"user\" ADD CONSTRAINT \"PK_user\" PRIMARY KEY (\"id\", \"name\")",
+			},
+		},
+		{
+			d.CreateIndexSQL(dialect.Index{Table: "user", Name: "user_name", Columns: []string{"name"}, Unique: true}),
+			[]string{
+				"CREATE UNIQUE INDEX \"APP\".\"user_name\" ON \"APP\".\"user\" (\"name\")",
+			},
+		},
+		{
+			d.(dialect.TableRenamer).RenameTableSQL("user", "account"),
+			[]string{
+				"ALTER TABLE \"APP\".\"user\" RENAME TO \"account\"",
+			},
+		},
+	} {
+		if diff := cmp.Diff(v.actual, v.expect); diff != "" {
+			t.Errorf("(-got +want)\n%v", diff)
+		}
+	}
+}
@@ -0,0 +1,585 @@
+package dialect
+
+import (
+	"fmt"
+	"io"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"os/exec"
+	"sync"
+)
+
+// PluginProtocolVersion is the version of the plugin protocol.
+//
+// The plugin protocol is JSON-RPC 1.0 over the standard input and output of the plugin process. The methods are
+// in the "Dialect" service (e.g. "Dialect.ColumnType"), and are the same as the Dialect interface except the following.
+//
+//	Dialect.Describe(PluginDescribeArgs) PluginDescribeReply
+//		is called at first to negotiate the protocol version and the capabilities.
+//	Dialect.GoType(PluginGoTypeArgs) string
+//	Dialect.ModifyColumnSQL(PluginModifyColumnArgs) []string
+//	Dialect.ModifyPrimaryKeySQL(PluginModifyPrimaryKeyArgs) []string
+//		is called only if the plugin has the "PrimaryKeyModifier" capability.
+//	Dialect.Begin(struct{}) int64
+//		returns the ID of the transaction.
+//	Dialect.Exec(PluginExecArgs) bool
+//	Dialect.Commit(int64) bool
+//	Dialect.Rollback(int64) bool
+//		take the ID of the transaction.
+//
+// The ColumnSchema is represented by PluginColumnSchema, and the other types are the same as the types in this
+// package. All fields are encoded with the Go's field names.
+// The plugin can write the logs to the standard error output.
+const PluginProtocolVersion = 1
+
+const pluginService = "Dialect"
+
+// PluginDescribeArgs is the arguments of Dialect.Describe in the plugin protocol.
+type PluginDescribeArgs struct {
+	ProtocolVersion int
+	Database        string
+	ColumnTypes     []*ColumnType
+}
+
+// PluginDescribeReply is the reply of Dialect.Describe in the plugin protocol.
+type PluginDescribeReply struct {
+	ProtocolVersion int
+	Name            string
+	Capabilities    []string
+}
+
+// PluginGoTypeArgs is the arguments of Dialect.GoType in the plugin protocol.
+type PluginGoTypeArgs struct {
+	Name     string
+	Nullable bool
+}
+
+// PluginModifyColumnArgs is the arguments of Dialect.ModifyColumnSQL in the plugin protocol.
+type PluginModifyColumnArgs struct {
+	OldField Field
+	NewField Field
+}
+
+// PluginModifyPrimaryKeyArgs is the arguments of Dialect.ModifyPrimaryKeySQL in the plugin protocol.
+type PluginModifyPrimaryKeyArgs struct {
+	OldPrimaryKeys []Field
+	NewPrimaryKeys []Field
+}
+
+// PluginExecArgs is the arguments of Dialect.Exec in the plugin protocol.
+type PluginExecArgs struct {
+	Tx   int64
+	SQL  string
+	Args []interface{}
+}
+
+var _ ColumnSchema = &PluginColumnSchema{}
+
+// PluginColumnSchema is the ColumnSchema in the plugin protocol.
+type PluginColumnSchema struct {
+	Table         string
+	Column        string
+	Type          string
+	Data          string
+	PrimaryKey    bool
+	AutoIncrement bool
+	IndexName     string
+	Unique        bool
+	DefaultValue  *string
+	Nullable      bool
+	ExtraValue    string
+	CommentValue  string
+}
+
+func newPluginColumnSchema(s ColumnSchema) *PluginColumnSchema {
+	ps := &PluginColumnSchema{
+		Table:         s.TableName(),
+		Column:        s.ColumnName(),
+		Type:          s.ColumnType(),
+		Data:          s.DataType(),
+		PrimaryKey:    s.IsPrimaryKey(),
+		AutoIncrement: s.IsAutoIncrement(),
+		Nullable:      s.IsNullable(),
+	}
+	if name, unique, ok := s.Index(); ok {
+		ps.IndexName, ps.Unique = name, unique
+	}
+	if v, ok := s.Default(); ok {
+		ps.DefaultValue = &v
+	}
+	ps.ExtraValue, _ = s.Extra()
+	ps.CommentValue, _ = s.Comment()
+	return ps
+}
+
+func (s *PluginColumnSchema) TableName() string {
+	return s.Table
+}
+
+func (s *PluginColumnSchema) ColumnName() string {
+	return s.Column
+}
+
+func (s *PluginColumnSchema) ColumnType() string {
+	return s.Type
+}
+
+func (s *PluginColumnSchema) DataType() string {
+	return s.Data
+}
+
+func (s *PluginColumnSchema) IsPrimaryKey() bool {
+	return s.PrimaryKey
+}
+
+func (s *PluginColumnSchema) IsAutoIncrement() bool {
+	return s.AutoIncrement
+}
+
+func (s *PluginColumnSchema) Index() (name string, unique bool, ok bool) {
+	return s.IndexName, s.Unique, s.IndexName != ""
+}
+
+func (s *PluginColumnSchema) Default() (string, bool) {
+	if s.DefaultValue == nil {
+		return "", false
+	}
+	return *s.DefaultValue, true
+}
+
+func (s *PluginColumnSchema) IsNullable() bool {
+	return s.Nullable
+}
+
+func (s *PluginColumnSchema) Extra() (string, bool) {
+	return s.ExtraValue, s.ExtraValue != ""
+}
+
+func (s *PluginColumnSchema) Comment() (string, bool) {
+	return s.CommentValue, s.CommentValue != ""
+}
+
+// Plugin is the dialect that is served by the plugin process.
+//
+// The methods of Dialect that cannot return an error return the zero value if the call of the plugin fails, and
+// the error is returned by Err and the subsequent calls of the methods that can return an error.
+type Plugin struct {
+	name   string
+	client *rpc.Client
+	cmd    *exec.Cmd
+
+	// columnTypes are the custom column types that are also passed to the plugin process.
+	columnTypes []*ColumnType
+
+	mu  sync.Mutex
+	err error
+}
+
+type pluginPrimaryKeyModifier struct {
+	*Plugin
+}
+
+var (
+	_ ErrorKeeper        = &Plugin{}
+	_ PrimaryKeyModifier = &pluginPrimaryKeyModifier{}
+)
+
+// NewPlugin starts the plugin process of the path, and returns the dialect that is served by the plugin.
+// The database is passed to the plugin to connect.
+// The returned function must be called to stop the plugin process after use.
+func NewPlugin(path string, database string, opts ...Option) (Dialect, func() error, error) {
+	opt := newOption()
+	for _, o := range opts {
+		o(opt)
+	}
+	cmd := exec.Command(path, opt.pluginArgs...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, nil, fmt.Errorf("failed to start the dialect plugin: %w", err)
+	}
+	p := &Plugin{
+		client:      jsonrpc.NewClient(&pluginConn{Reader: stdout, WriteCloser: stdin}),
+		cmd:         cmd,
+		columnTypes: opt.columnTypes,
+	}
+	var reply PluginDescribeReply
+	if err := p.client.Call(pluginService+".Describe", PluginDescribeArgs{
+		ProtocolVersion: PluginProtocolVersion,
+		Database:        database,
+		ColumnTypes:     opt.columnTypes,
+	}, &reply); err != nil {
+		p.Close()
+		return nil, nil, fmt.Errorf("failed to describe the dialect plugin: %w", err)
+	}
+	if reply.ProtocolVersion != PluginProtocolVersion {
+		p.Close()
+		return nil, nil, fmt.Errorf("unsupported protocol version of the dialect plugin: %d", reply.ProtocolVersion)
+	}
+	p.name = reply.Name
+	for _, c := range reply.Capabilities {
+		if c == "PrimaryKeyModifier" {
+			return &pluginPrimaryKeyModifier{p}, p.Close, nil
+		}
+	}
+	return p, p.Close, nil
+}
+
+// Name returns the name of the plugin that is reported by the plugin.
+func (p *Plugin) Name() string {
+	return p.name
+}
+
+// Close stops the plugin process.
+func (p *Plugin) Close() error {
+	err := p.client.Close()
+	if werr := p.cmd.Wait(); err == nil {
+		err = werr
+	}
+	return err
+}
+
+// Err returns the first error of the calls of the plugin that have failed in the methods that cannot return an
+// error, or the error of the broken connection to the plugin.
+func (p *Plugin) Err() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.err
+}
+
+func (p *Plugin) call(method string, args interface{}, reply interface{}) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	if err := p.client.Call(pluginService+"."+method, args, reply); err != nil {
+		if _, ok := err.(rpc.ServerError); !ok {
+			// The connection to the plugin is broken.
+			p.err = fmt.Errorf("dialect plugin: %s: %w", method, err)
+		}
+		return fmt.Errorf("dialect plugin: %s: %w", method, err)
+	}
+	return nil
+}
+
+// mustCall is like call, but the error is kept to be returned by the subsequent calls.
+func (p *Plugin) mustCall(method string, args interface{}, reply interface{}) {
+	if err := p.call(method, args, reply); err != nil {
+		p.mu.Lock()
+		if p.err == nil {
+			p.err = err
+		}
+		p.mu.Unlock()
+	}
+}
+
+func (p *Plugin) ColumnSchema(tables ...string) ([]ColumnSchema, error) {
+	var columns []*PluginColumnSchema
+	if err := p.call("ColumnSchema", tables, &columns); err != nil {
+		return nil, err
+	}
+	schemas := make([]ColumnSchema, len(columns))
+	for i, c := range columns {
+		schemas[i] = c
+	}
+	return schemas, nil
+}
+
+func (p *Plugin) ColumnType(name string) (typ string) {
+	p.mustCall("ColumnType", name, &typ)
+	return typ
+}
+
+func (p *Plugin) GoType(name string, nullable bool) (typ string) {
+	p.mustCall("GoType", PluginGoTypeArgs{Name: name, Nullable: nullable}, &typ)
+	return typ
+}
+
+func (p *Plugin) IsNullable(name string) (nullable bool) {
+	p.mustCall("IsNullable", name, &nullable)
+	return nullable
+}
+
+func (p *Plugin) ImportPackage(schema ColumnSchema) (pkg string) {
+	p.mustCall("ImportPackage", newPluginColumnSchema(schema), &pkg)
+	return pkg
+}
+
+// GoTypeImports returns the imports of the custom column types without calling the plugin, since they are given by
+// the host.
+func (p *Plugin) GoTypeImports() ([]Import, error) {
+	return goTypeImports(p.columnTypes)
+}
+
+func (p *Plugin) Quote(s string) (quoted string) {
+	p.mustCall("Quote", s, &quoted)
+	return quoted
+}
+
+func (p *Plugin) QuoteString(s string) (quoted string) {
+	p.mustCall("QuoteString", s, &quoted)
+	return quoted
+}
+
+func (p *Plugin) CreateTableSQL(table Table) (sqls []string) {
+	p.mustCall("CreateTableSQL", table, &sqls)
+	return sqls
+}
+
+func (p *Plugin) AddColumnSQL(field Field) (sqls []string) {
+	p.mustCall("AddColumnSQL", field, &sqls)
+	return sqls
+}
+
+func (p *Plugin) DropColumnSQL(field Field) (sqls []string) {
+	p.mustCall("DropColumnSQL", field, &sqls)
+	return sqls
+}
+
+func (p *Plugin) ModifyColumnSQL(oldField, newField Field) (sqls []string) {
+	p.mustCall("ModifyColumnSQL", PluginModifyColumnArgs{OldField: oldField, NewField: newField}, &sqls)
+	return sqls
+}
+
+func (p *Plugin) CreateIndexSQL(index Index) (sqls []string) {
+	p.mustCall("CreateIndexSQL", index, &sqls)
+	return sqls
+}
+
+func (p *Plugin) DropIndexSQL(index Index) (sqls []string) {
+	p.mustCall("DropIndexSQL", index, &sqls)
+	return sqls
+}
+
+func (p *Plugin) Begin() (Transactioner, error) {
+	var tx int64
+	if err := p.call("Begin", struct{}{}, &tx); err != nil {
+		return nil, err
+	}
+	return &pluginTransaction{p: p, tx: tx}, nil
+}
+
+func (p *pluginPrimaryKeyModifier) ModifyPrimaryKeySQL(oldPrimaryKeys, newPrimaryKeys []Field) (sqls []string) {
+	p.mustCall("ModifyPrimaryKeySQL", PluginModifyPrimaryKeyArgs{OldPrimaryKeys: oldPrimaryKeys, NewPrimaryKeys: newPrimaryKeys}, &sqls)
+	return sqls
+}
+
+type pluginTransaction struct {
+	p  *Plugin
+	tx int64
+}
+
+func (t *pluginTransaction) Exec(sql string, args ...interface{}) error {
+	var ok bool
+	return t.p.call("Exec", PluginExecArgs{Tx: t.tx, SQL: sql, Args: args}, &ok)
+}
+
+func (t *pluginTransaction) Commit() error {
+	var ok bool
+	return t.p.call("Commit", t.tx, &ok)
+}
+
+func (t *pluginTransaction) Rollback() error {
+	var ok bool
+	return t.p.call("Rollback", t.tx, &ok)
+}
+
+type pluginConn struct {
+	io.Reader
+	io.WriteCloser
+}
+
+// ServePlugin serves the dialect as a plugin over the standard input and output.
+// newDialect is called with the database and the options that are given by NewPlugin.
+// It is a helper to write the plugin in Go.
+func ServePlugin(name string, newDialect func(database string, opts ...Option) (Dialect, error)) error {
+	s := rpc.NewServer()
+	if err := s.RegisterName(pluginService, &pluginServer{
+		name:       name,
+		newDialect: newDialect,
+		txs:        map[int64]Transactioner{},
+	}); err != nil {
+		return err
+	}
+	s.ServeCodec(jsonrpc.NewServerCodec(&pluginConn{Reader: os.Stdin, WriteCloser: os.Stdout}))
+	return nil
+}
+
+// pluginServer is the RPC service of the plugin protocol.
+type pluginServer struct {
+	name       string
+	newDialect func(database string, opts ...Option) (Dialect, error)
+	d          Dialect
+
+	mu     sync.Mutex
+	txs    map[int64]Transactioner
+	nextTx int64
+}
+
+func (s *pluginServer) Describe(args PluginDescribeArgs, reply *PluginDescribeReply) error {
+	d, err := s.newDialect(args.Database, WithColumnType(args.ColumnTypes))
+	if err != nil {
+		return err
+	}
+	s.d = d
+	reply.ProtocolVersion = PluginProtocolVersion
+	reply.Name = s.name
+	if _, ok := d.(PrimaryKeyModifier); ok {
+		reply.Capabilities = append(reply.Capabilities, "PrimaryKeyModifier")
+	}
+	return nil
+}
+
+func (s *pluginServer) ColumnSchema(tables []string, reply *[]*PluginColumnSchema) error {
+	schemas, err := s.d.ColumnSchema(tables...)
+	if err != nil {
+		return err
+	}
+	for _, schema := range schemas {
+		*reply = append(*reply, newPluginColumnSchema(schema))
+	}
+	return nil
+}
+
+func (s *pluginServer) ColumnType(name string, reply *string) error {
+	*reply = s.d.ColumnType(name)
+	return nil
+}
+
+func (s *pluginServer) GoType(args PluginGoTypeArgs, reply *string) error {
+	*reply = s.d.GoType(args.Name, args.Nullable)
+	return nil
+}
+
+func (s *pluginServer) IsNullable(name string, reply *bool) error {
+	*reply = s.d.IsNullable(name)
+	return nil
+}
+
+func (s *pluginServer) ImportPackage(schema PluginColumnSchema, reply *string) error {
+	*reply = s.d.ImportPackage(&schema)
+	return nil
+}
+
+func (s *pluginServer) Quote(v string, reply *string) error {
+	*reply = s.d.Quote(v)
+	return nil
+}
+
+func (s *pluginServer) QuoteString(v string, reply *string) error {
+	*reply = s.d.QuoteString(v)
+	return nil
+}
+
+func (s *pluginServer) CreateTableSQL(table Table, reply *[]string) error {
+	*reply = s.d.CreateTableSQL(table)
+	return nil
+}
+
+func (s *pluginServer) AddColumnSQL(field Field, reply *[]string) error {
+	*reply = s.d.AddColumnSQL(field)
+	return nil
+}
+
+func (s *pluginServer) DropColumnSQL(field Field, reply *[]string) error {
+	*reply = s.d.DropColumnSQL(field)
+	return nil
+}
+
+func (s *pluginServer) ModifyColumnSQL(args PluginModifyColumnArgs, reply *[]string) error {
+	*reply = s.d.ModifyColumnSQL(args.OldField, args.NewField)
+	return nil
+}
+
+func (s *pluginServer) ModifyPrimaryKeySQL(args PluginModifyPrimaryKeyArgs, reply *[]string) error {
+	m, ok := s.d.(PrimaryKeyModifier)
+	if !ok {
+		return fmt.Errorf("the dialect does not support modifying primary keys")
+	}
+	*reply = m.ModifyPrimaryKeySQL(args.OldPrimaryKeys, args.NewPrimaryKeys)
+	return nil
+}
+
+func (s *pluginServer) CreateIndexSQL(index Index, reply *[]string) error {
+	*reply = s.d.CreateIndexSQL(index)
+	return nil
+}
+
+func (s *pluginServer) DropIndexSQL(index Index, reply *[]string) error {
+	*reply = s.d.DropIndexSQL(index)
+	return nil
+}
+
+func (s *pluginServer) Begin(_ struct{}, reply *int64) error {
+	tx, err := s.d.Begin()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextTx++
+	s.txs[s.nextTx] = tx
+	*reply = s.nextTx
+	return nil
+}
+
+func (s *pluginServer) tx(id int64) (Transactioner, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tx, ok := s.txs[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown transaction: %d", id)
+	}
+	return tx, nil
+}
+
+func (s *pluginServer) Exec(args PluginExecArgs, reply *bool) error {
+	tx, err := s.tx(args.Tx)
+	if err != nil {
+		return err
+	}
+	if err := tx.Exec(args.SQL, args.Args...); err != nil {
+		return err
+	}
+	*reply = true
+	return nil
+}
+
+func (s *pluginServer) Commit(id int64, reply *bool) error {
+	tx, err := s.tx(id)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	delete(s.txs, id)
+	s.mu.Unlock()
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	*reply = true
+	return nil
+}
+
+func (s *pluginServer) Rollback(id int64, reply *bool) error {
+	tx, err := s.tx(id)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	delete(s.txs, id)
+	s.mu.Unlock()
+	if err := tx.Rollback(); err != nil {
+		return err
+	}
+	*reply = true
+	return nil
+}
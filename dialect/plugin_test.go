@@ -0,0 +1,79 @@
+package dialect_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/naoina/migu/dialect"
+)
+
+// TestHelperPlugin is not a real test. It serves MySQL dialect as a plugin process for TestPlugin.
+func TestHelperPlugin(t *testing.T) {
+	if os.Getenv("MIGU_TEST_HELPER_PLUGIN") != "1" {
+		t.Skip("helper process")
+	}
+	if err := dialect.ServePlugin("mysql", func(database string, opts ...dialect.Option) (dialect.Dialect, error) {
+		return dialect.NewMySQL(nil, opts...), nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	os.Exit(0)
+}
+
+func TestPlugin(t *testing.T) {
+	os.Setenv("MIGU_TEST_HELPER_PLUGIN", "1")
+	defer os.Unsetenv("MIGU_TEST_HELPER_PLUGIN")
+	d, closer, err := dialect.NewPlugin(os.Args[0], "testdb", dialect.WithPluginArgs("-test.run=^TestHelperPlugin$"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mysql := dialect.NewMySQL(nil)
+	if p, ok := d.(*dialect.Plugin); ok {
+		t.Errorf("dialect is %T; want PrimaryKeyModifier", p)
+	}
+	field := dialect.Field{
+		Table:    "user",
+		Name:     "name",
+		Type:     "VARCHAR(255)",
+		Comment:  "user's name",
+		Default:  "x",
+		Nullable: true,
+	}
+	for _, v := range []struct {
+		name      string
+		got, want interface{}
+	}{
+		{"ColumnType", d.ColumnType("int"), mysql.ColumnType("int")},
+		{"GoType", d.GoType("VARCHAR", true), mysql.GoType("VARCHAR", true)},
+		{"IsNullable", d.IsNullable("*int"), mysql.IsNullable("*int")},
+		{"Quote", d.Quote("a`b"), mysql.Quote("a`b")},
+		{"QuoteString", d.QuoteString("a'b"), mysql.QuoteString("a'b")},
+		{"CreateTableSQL", d.CreateTableSQL(dialect.Table{Name: "user", Fields: []dialect.Field{field}}), mysql.CreateTableSQL(dialect.Table{Name: "user", Fields: []dialect.Field{field}})},
+		{"AddColumnSQL", d.AddColumnSQL(field), mysql.AddColumnSQL(field)},
+		{"DropColumnSQL", d.DropColumnSQL(field), mysql.DropColumnSQL(field)},
+		{"ModifyColumnSQL", d.ModifyColumnSQL(field, dialect.Field{Table: "user", Name: "name", Type: "TEXT"}), mysql.ModifyColumnSQL(field, dialect.Field{Table: "user", Name: "name", Type: "TEXT"})},
+		{"CreateIndexSQL", d.CreateIndexSQL(dialect.Index{Table: "user", Name: "name", Columns: []string{"name"}, Unique: true}), mysql.CreateIndexSQL(dialect.Index{Table: "user", Name: "name", Columns: []string{"name"}, Unique: true})},
+		{"DropIndexSQL", d.DropIndexSQL(dialect.Index{Table: "user", Name: "name"}), mysql.DropIndexSQL(dialect.Index{Table: "user", Name: "name"})},
+		{"ModifyPrimaryKeySQL", d.(dialect.PrimaryKeyModifier).ModifyPrimaryKeySQL([]dialect.Field{field}, nil), mysql.(dialect.PrimaryKeyModifier).ModifyPrimaryKeySQL([]dialect.Field{field}, nil)},
+	} {
+		if diff := cmp.Diff(v.got, v.want); diff != "" {
+			t.Errorf("%s: (-got +want)\n%v", v.name, diff)
+		}
+	}
+	if err := d.(dialect.ErrorKeeper).Err(); err != nil {
+		t.Errorf("Err() = %v; want nil", err)
+	}
+	if err := closer(); err != nil {
+		t.Fatal(err)
+	}
+	if got := d.ColumnType("int"); got != "" {
+		t.Errorf("ColumnType(%q) after close = %q; want empty", "int", got)
+	}
+	if err := d.(dialect.ErrorKeeper).Err(); err == nil {
+		t.Errorf("Err() after close returns nil error; want error")
+	}
+	if _, err := d.Begin(); err == nil {
+		t.Errorf("Begin() after close returns nil error; want error")
+	}
+}
@@ -0,0 +1,924 @@
+package dialect
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+var (
+	_ PrimaryKeyModifier = &Postgres{}
+	_ StatelessGenerator = &Postgres{}
+	_ IndexReader        = &Postgres{}
+	_ RowReader          = &Postgres{}
+	_ TableRenamer       = &Postgres{}
+	_ ColumnRenamer      = &Postgres{}
+	_ TableAnalyzer      = &Postgres{}
+	_ TablePersister     = &Postgres{}
+	_ GoTypeImporter     = &Postgres{}
+)
+
+// postgresPrimaryKeyIndex is the name of the primary key that is returned by Indexes in the same way as MySQL.
+const postgresPrimaryKeyIndex = "PRIMARY"
+
+var (
+	postgresColumnTypes = []*ColumnType{
+		{
+			Types:           []string{"TEXT", "CHARACTER VARYING", "CHARACTER"},
+			GoTypes:         []string{"string"},
+			GoNullableTypes: []string{"*string", "sql.NullString"},
+		},
+		{
+			Types:           []string{"BYTEA"},
+			GoTypes:         []string{"[]byte"},
+			GoNullableTypes: []string{"[]byte"},
+		},
+		{
+			Types:           []string{"INTEGER"},
+			GoTypes:         []string{"int", "int32", "uint16"},
+			GoNullableTypes: []string{"*int32", "sql.NullInt32"},
+		},
+		{
+			Types:   []string{"SMALLINT"},
+			GoTypes: []string{"int16", "int8", "uint8"},
+		},
+		{
+			Types:           []string{"BIGINT"},
+			GoTypes:         []string{"int64", "uint32"},
+			GoNullableTypes: []string{"*int64", "sql.NullInt64"},
+		},
+		{
+			// NUMERIC(20,0) can hold all the values of uint64.
+			Types:   []string{"NUMERIC(20,0)"},
+			GoTypes: []string{"uint64", "uint"},
+		},
+		{
+			Types:           []string{"BOOLEAN"},
+			GoTypes:         []string{"bool"},
+			GoNullableTypes: []string{"*bool", "sql.NullBool"},
+		},
+		{
+			Types:           []string{"DOUBLE PRECISION", "NUMERIC"},
+			GoTypes:         []string{"float64"},
+			GoNullableTypes: []string{"*float64", "sql.NullFloat64"},
+		},
+		{
+			Types:   []string{"REAL"},
+			GoTypes: []string{"float32"},
+		},
+		{
+			Types:           []string{"TIMESTAMP WITH TIME ZONE", "TIMESTAMP WITHOUT TIME ZONE", "DATE"},
+			GoTypes:         []string{"time.Time"},
+			GoNullableTypes: []string{"*time.Time", "sql.NullTime", "pq.NullTime"},
+		},
+		{
+			Types:           []string{"JSONB", "JSON"},
+			GoTypes:         []string{"json.RawMessage"},
+			GoNullableTypes: []string{"json.RawMessage"},
+		},
+	}
+
+	// postgresTypeAliases are the canonical names of the types that are returned by format_type() of PostgreSQL.
+	postgresTypeAliases = map[string]string{
+		"VARCHAR": "CHARACTER VARYING",
+		"CHAR":    "CHARACTER",
+		"BPCHAR":  "CHARACTER",
+		"INT":     "INTEGER",
+		"INT4":    "INTEGER",
+		"INT2":    "SMALLINT",
+		"INT8":    "BIGINT",
+		"BOOL":    "BOOLEAN",
+		"FLOAT":   "DOUBLE PRECISION",
+		"FLOAT8":  "DOUBLE PRECISION",
+		"FLOAT4":  "REAL",
+		"DECIMAL": "NUMERIC",
+		"VARBIT":  "BIT VARYING",
+	}
+
+	// postgresTimeZoneTypes are the time types and the time zone clauses of their aliases.
+	postgresTimeZoneTypes = map[string][2]string{
+		"TIMESTAMP":   {"TIMESTAMP", "WITHOUT TIME ZONE"},
+		"TIMESTAMPTZ": {"TIMESTAMP", "WITH TIME ZONE"},
+		"TIME":        {"TIME", "WITHOUT TIME ZONE"},
+		"TIMETZ":      {"TIME", "WITH TIME ZONE"},
+	}
+)
+
+// Postgres is the dialect of PostgreSQL.
+// The tables are in the schema that is specified by WithSchema, or the current schema of the connection.
+type Postgres struct {
+	db              *sql.DB
+	opt             *option
+	schemaName      string
+	columnTypes     []*ColumnType
+	columnTypeMap   map[string]*ColumnType
+	nullableTypeMap map[string]struct{}
+}
+
+// NewPostgres returns a new dialect of PostgreSQL. db must be opened by the driver of PostgreSQL such as lib/pq.
+func NewPostgres(db *sql.DB, opts ...Option) Dialect {
+	return newPostgres(db, postgresColumnTypes, opts...)
+}
+
+// newPostgres returns a new dialect of PostgreSQL that has the built-in column types.
+func newPostgres(db *sql.DB, columnTypes []*ColumnType, opts ...Option) *Postgres {
+	d := &Postgres{
+		db:              db,
+		opt:             newOption(),
+		columnTypes:     columnTypes,
+		columnTypeMap:   map[string]*ColumnType{},
+		nullableTypeMap: map[string]struct{}{},
+	}
+	for _, o := range opts {
+		o(d.opt)
+	}
+	for _, types := range [][]*ColumnType{columnTypes, d.opt.columnTypes} {
+		for _, t := range types {
+			for _, tt := range t.allGoTypes() {
+				d.columnTypeMap[tt] = t
+			}
+			for _, tt := range t.filteredNullableGoTypes() {
+				d.nullableTypeMap[tt] = struct{}{}
+			}
+		}
+	}
+	return d
+}
+
+func (d *Postgres) ColumnSchema(tables ...string) ([]ColumnSchema, error) {
+	return d.columnSchema("", tables)
+}
+
+// columnSchema returns the schemas of the columns that also match cond if not empty.
+func (d *Postgres) columnSchema(cond string, tables []string) ([]ColumnSchema, error) {
+	schema, err := d.currentSchema()
+	if err != nil {
+		return nil, err
+	}
+	primaryKeys, indexMap, err := d.getIndexMap(schema)
+	if err != nil {
+		return nil, err
+	}
+	parts := []string{
+		"SELECT",
+		"  c.table_name,",
+		"  c.column_name,",
+		"  c.column_default,",
+		"  c.is_nullable,",
+		"  c.data_type,",
+		"  pg_catalog.format_type(a.atttypid, a.atttypmod),",
+		"  c.is_identity,",
+		"  COALESCE(pg_catalog.col_description(a.attrelid, a.attnum), '')",
+		"FROM information_schema.columns c",
+		"JOIN information_schema.tables t ON t.table_schema = c.table_schema AND t.table_name = c.table_name",
+		"JOIN pg_catalog.pg_namespace n ON n.nspname = c.table_schema",
+		"JOIN pg_catalog.pg_class r ON r.relnamespace = n.oid AND r.relname = c.table_name",
+		"JOIN pg_catalog.pg_attribute a ON a.attrelid = r.oid AND a.attname = c.column_name",
+		"WHERE c.table_schema = $1 AND t.table_type = 'BASE TABLE'",
+	}
+	if cond != "" {
+		parts = append(parts, "AND "+cond)
+	}
+	args := []interface{}{schema}
+	if len(tables) > 0 {
+		parts = append(parts, fmt.Sprintf("AND c.table_name IN (%s)", postgresPlaceholders(2, len(tables))))
+		for _, t := range tables {
+			args = append(args, t)
+		}
+	}
+	parts = append(parts, "ORDER BY c.table_name, c.ordinal_position")
+	rows, err := d.db.Query(strings.Join(parts, "\n"), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var schemas []ColumnSchema
+	for rows.Next() {
+		schema := &postgresColumnSchema{}
+		if err := rows.Scan(
+			&schema.tableName,
+			&schema.columnName,
+			&schema.columnDefault,
+			&schema.isNullable,
+			&schema.dataType,
+			&schema.formattedType,
+			&schema.isIdentity,
+			&schema.comment,
+		); err != nil {
+			return nil, err
+		}
+		schema.primaryKey = primaryKeys[schema.tableName][schema.columnName]
+		if info, exists := indexMap[schema.tableName][schema.columnName]; exists {
+			schema.indexName = info.name
+			schema.unique = info.unique
+		}
+		schemas = append(schemas, schema)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return schemas, nil
+}
+
+func (d *Postgres) ColumnType(name string) string {
+	if t, ok := d.columnTypeMap[name]; ok {
+		name, _, _, _ = t.findType(name)
+	}
+	return postgresCanonicalType(name)
+}
+
+func (d *Postgres) GoType(name string, nullable bool) string {
+	return d.goType(postgresCanonicalType(name), nullable)
+}
+
+func (d *Postgres) goType(name string, nullable bool) string {
+	for _, types := range [][]*ColumnType{d.opt.columnTypes, d.columnTypes} {
+		for _, t := range types {
+			if typ, found := t.findGoType(name, nullable, false); found {
+				return typ
+			}
+		}
+	}
+	if strings.IndexByte(name, '(') >= 0 {
+		return d.goType(trimParens(name), nullable)
+	}
+	return "interface{}"
+}
+
+func (d *Postgres) IsNullable(name string) bool {
+	_, ok := d.nullableTypeMap[name]
+	return ok
+}
+
+func (d *Postgres) ImportPackage(schema ColumnSchema) string {
+	switch typ := schema.DataType(); {
+	case typ == "date", strings.HasPrefix(typ, "timestamp"):
+		return "time"
+	case typ == "json", typ == "jsonb":
+		return "encoding/json"
+	}
+	return ""
+}
+
+func (d *Postgres) GoTypeImports() ([]Import, error) {
+	return goTypeImports(d.opt.columnTypes)
+}
+
+func (d *Postgres) Quote(s string) string {
+	return quoteByDoubling(s, `"`, false)
+}
+
+func (d *Postgres) QuoteString(s string) string {
+	// The backslashes are not escaped because standard_conforming_strings is on by default.
+	return quoteByDoubling(s, "'", false)
+}
+
+// IsStateless returns true since the SQLs of each change are built only from the change.
+func (d *Postgres) IsStateless() bool {
+	return true
+}
+
+func (d *Postgres) CreateTableSQL(table Table) []string {
+	columns := make([]string, len(table.Fields))
+	for i, f := range table.Fields {
+		columns[i] = d.columnSQL(f)
+	}
+	if len(table.PrimaryKeys) > 0 {
+		pkColumns := make([]string, len(table.PrimaryKeys))
+		for i, pk := range table.PrimaryKeys {
+			pkColumns[i] = d.Quote(pk)
+		}
+		columns = append(columns, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(pkColumns, ", ")))
+	}
+	create, tableName := "CREATE TABLE", d.table(table.Name)
+	switch table.Persistence {
+	case PersistenceTemporary:
+		// The temporary table is created in the temporary schema of the session regardless of WithSchema.
+		create, tableName = "CREATE TEMPORARY TABLE", d.Quote(table.Name)
+	case PersistenceUnlogged:
+		create = "CREATE UNLOGGED TABLE"
+	}
+	query := fmt.Sprintf("%s %s (\n"+
+		"  %s\n"+
+		")", create, tableName, strings.Join(columns, ",\n  "))
+	if table.Option != "" {
+		query += " " + table.Option
+	}
+	sqls := []string{query}
+	for _, f := range table.Fields {
+		if f.Comment != "" {
+			sqls = append(sqls, d.columnCommentSQL(tableName, f))
+		}
+	}
+	return sqls
+}
+
+func (d *Postgres) AddColumnSQL(field Field) []string {
+	sqls := []string{fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", d.table(field.Table), d.columnSQL(field))}
+	if field.Comment != "" {
+		sqls = append(sqls, d.commentSQL(field))
+	}
+	return sqls
+}
+
+func (d *Postgres) DropColumnSQL(field Field) []string {
+	return []string{fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", d.table(field.Table), d.Quote(field.Name))}
+}
+
+func (d *Postgres) ModifyColumnSQL(oldField, newField Field) []string {
+	var sqls []string
+	tableName := d.table(newField.Table)
+	if oldField.Name != newField.Name {
+		sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", tableName, d.Quote(oldField.Name), d.Quote(newField.Name)))
+	}
+	column := d.Quote(newField.Name)
+	var specs []string
+	var sequence string
+	if oldField.AutoIncrement && !newField.AutoIncrement {
+		var serial bool
+		if serial, sequence = d.serialSequence(newField.Table, oldField.Name); serial {
+			// The serial column has nextval() of its sequence as the default instead of the identity.
+			if newField.Default == "" {
+				specs = append(specs, fmt.Sprintf("ALTER COLUMN %s DROP DEFAULT", column))
+			}
+		} else {
+			specs = append(specs, fmt.Sprintf("ALTER COLUMN %s DROP IDENTITY IF EXISTS", column))
+		}
+	}
+	if oldField.Type != newField.Type {
+		specs = append(specs, fmt.Sprintf("ALTER COLUMN %s TYPE %s USING %s::%s", column, newField.Type, column, newField.Type))
+	}
+	if oldField.Nullable != newField.Nullable {
+		if newField.Nullable {
+			specs = append(specs, fmt.Sprintf("ALTER COLUMN %s DROP NOT NULL", column))
+		} else {
+			specs = append(specs, fmt.Sprintf("ALTER COLUMN %s SET NOT NULL", column))
+		}
+	}
+	if oldField.Default != newField.Default {
+		if newField.Default == "" {
+			specs = append(specs, fmt.Sprintf("ALTER COLUMN %s DROP DEFAULT", column))
+		} else {
+			specs = append(specs, fmt.Sprintf("ALTER COLUMN %s SET DEFAULT %s", column, d.defaultSQL(newField)))
+		}
+	}
+	if !oldField.AutoIncrement && newField.AutoIncrement {
+		specs = append(specs, fmt.Sprintf("ALTER COLUMN %s ADD GENERATED BY DEFAULT AS IDENTITY", column))
+	}
+	if len(specs) > 0 {
+		sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s %s", tableName, strings.Join(specs, ", ")))
+	}
+	if sequence != "" {
+		sqls = append(sqls, fmt.Sprintf("DROP SEQUENCE IF EXISTS %s", sequence))
+	}
+	if oldField.Comment != newField.Comment {
+		sqls = append(sqls, d.commentSQL(newField))
+	}
+	return sqls
+}
+
+// serialSequence reports whether the column is a serial column whose default is nextval() of the sequence, and
+// returns the sequence that is owned by the column, which is empty if the column does not own it. The column is
+// regarded as an identity column if its default cannot be read, such as without connecting to the database.
+func (d *Postgres) serialSequence(table, column string) (serial bool, sequence string) {
+	if d.db == nil {
+		return false, ""
+	}
+	schema, err := d.currentSchema()
+	if err != nil {
+		return false, ""
+	}
+	var def, seq sql.NullString
+	if err := d.db.QueryRow(strings.Join([]string{
+		"SELECT c.column_default, pg_catalog.pg_get_serial_sequence(pg_catalog.quote_ident(c.table_schema) || '.' || pg_catalog.quote_ident(c.table_name), c.column_name)",
+		"FROM information_schema.columns c",
+		"WHERE c.table_schema = $1 AND c.table_name = $2 AND c.column_name = $3",
+	}, "\n"), schema, table, column).Scan(&def, &seq); err != nil {
+		return false, ""
+	}
+	if !strings.HasPrefix(def.String, "nextval(") {
+		return false, ""
+	}
+	return true, seq.String
+}
+
+func (d *Postgres) RenameColumnSQL(oldField, newField Field) []string {
+	return d.ModifyColumnSQL(oldField, newField)
+}
+
+func (d *Postgres) ModifyPrimaryKeySQL(oldPrimaryKeys, newPrimaryKeys []Field) []string {
+	var tableName string
+	if len(newPrimaryKeys) > 0 {
+		tableName = newPrimaryKeys[0].Table
+	} else {
+		tableName = oldPrimaryKeys[0].Table
+	}
+	var specs []string
+	if len(oldPrimaryKeys) > 0 {
+		specs = append(specs, fmt.Sprintf("DROP CONSTRAINT %s", d.Quote(d.primaryKeyName(tableName))))
+	}
+	if len(newPrimaryKeys) > 0 {
+		pkColumns := make([]string, len(newPrimaryKeys))
+		for i, pk := range newPrimaryKeys {
+			pkColumns[i] = d.Quote(pk.Name)
+		}
+		specs = append(specs, fmt.Sprintf("ADD PRIMARY KEY (%s)", strings.Join(pkColumns, ", ")))
+	}
+	return []string{fmt.Sprintf("ALTER TABLE %s %s", d.table(tableName), strings.Join(specs, ", "))}
+}
+
+// primaryKeyName returns the name of the primary key constraint of the table in pg_constraint. It returns TABLE_pkey,
+// which is the default name, if the name cannot be read such as without connecting to the database.
+func (d *Postgres) primaryKeyName(table string) string {
+	name := table + "_pkey"
+	if d.db == nil {
+		return name
+	}
+	schema, err := d.currentSchema()
+	if err != nil {
+		return name
+	}
+	var conname string
+	if err := d.db.QueryRow(strings.Join([]string{
+		"SELECT con.conname",
+		"FROM pg_catalog.pg_constraint con",
+		"JOIN pg_catalog.pg_class r ON r.oid = con.conrelid",
+		"JOIN pg_catalog.pg_namespace n ON n.oid = r.relnamespace",
+		"WHERE n.nspname = $1 AND r.relname = $2 AND con.contype = 'p'",
+	}, "\n"), schema, table).Scan(&conname); err != nil {
+		return name
+	}
+	return conname
+}
+
+func (d *Postgres) RenameTableSQL(oldName, newName string) []string {
+	return []string{fmt.Sprintf("ALTER TABLE %s RENAME TO %s", d.table(oldName), d.Quote(newName))}
+}
+
+func (d *Postgres) AnalyzeTableSQL(table string) []string {
+	return []string{fmt.Sprintf("ANALYZE %s", d.table(table))}
+}
+
+func (d *Postgres) CreateIndexSQL(index Index) []string {
+	columns := make([]string, len(index.Columns))
+	for i, c := range index.Columns {
+		columns[i] = d.Quote(c)
+	}
+	indexName := d.Quote(index.Name)
+	tableName := d.table(index.Table)
+	column := strings.Join(columns, ",")
+	if index.Unique {
+		return []string{fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (%s)", indexName, tableName, column)}
+	}
+	return []string{fmt.Sprintf("CREATE INDEX %s ON %s (%s)", indexName, tableName, column)}
+}
+
+func (d *Postgres) DropIndexSQL(index Index) []string {
+	// The index belongs to the schema of the table.
+	return []string{fmt.Sprintf("DROP INDEX %s", d.table(index.Name))}
+}
+
+func (d *Postgres) Begin() (Transactioner, error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &postgresTransaction{
+		tx: tx,
+	}, nil
+}
+
+func (d *Postgres) Indexes(tables ...string) ([]Index, error) {
+	schema, err := d.currentSchema()
+	if err != nil {
+		return nil, err
+	}
+	parts := []string{
+		"SELECT",
+		"  t.relname,",
+		"  i.relname,",
+		"  a.attname,",
+		"  ix.indisunique,",
+		"  ix.indisprimary",
+		"FROM pg_catalog.pg_index ix",
+		"JOIN pg_catalog.pg_class t ON t.oid = ix.indrelid",
+		"JOIN pg_catalog.pg_class i ON i.oid = ix.indexrelid",
+		"JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace",
+		"JOIN pg_catalog.pg_attribute a ON a.attrelid = t.oid AND a.attnum = ANY(ix.indkey)",
+		"WHERE n.nspname = $1",
+	}
+	args := []interface{}{schema}
+	if len(tables) > 0 {
+		parts = append(parts, fmt.Sprintf("AND t.relname IN (%s)", postgresPlaceholders(2, len(tables))))
+		for _, t := range tables {
+			args = append(args, t)
+		}
+	}
+	parts = append(parts, "ORDER BY t.relname, i.relname, array_position(ix.indkey::int2[], a.attnum)")
+	rows, err := d.db.Query(strings.Join(parts, "\n"), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var indexes []Index
+	for rows.Next() {
+		var (
+			tableName  string
+			indexName  string
+			columnName string
+			unique     bool
+			primary    bool
+		)
+		if err := rows.Scan(&tableName, &indexName, &columnName, &unique, &primary); err != nil {
+			return nil, err
+		}
+		if primary {
+			indexName = postgresPrimaryKeyIndex
+		}
+		if n := len(indexes); n > 0 && indexes[n-1].Table == tableName && indexes[n-1].Name == indexName {
+			indexes[n-1].Columns = append(indexes[n-1].Columns, columnName)
+			continue
+		}
+		indexes = append(indexes, Index{
+			Table:   tableName,
+			Name:    indexName,
+			Columns: []string{columnName},
+			Unique:  unique,
+		})
+	}
+	return indexes, rows.Err()
+}
+
+// SchemaLimits returns the limits of PostgreSQL that is built with the default NAMEDATALEN and INDEX_MAX_KEYS.
+// The identifiers that are longer than the maximum length are truncated silently by PostgreSQL.
+func (d *Postgres) SchemaLimits(table Table) SchemaLimits {
+	return SchemaLimits{
+		MaxIdentifierLength:     63,
+		IdentifierLengthInBytes: true,
+		MaxColumns:              1600,
+		MaxIndexColumns:         32,
+	}
+}
+
+// CheckConstraints returns the CHECK constraints of the tables. The expressions are the ones that are deparsed by
+// PostgreSQL such as "(price >= 0)". The NOT NULL constraints are not included.
+func (d *Postgres) CheckConstraints(tables ...string) ([]CheckConstraint, error) {
+	schema, err := d.currentSchema()
+	if err != nil {
+		return nil, err
+	}
+	parts := []string{
+		"SELECT",
+		"  t.relname,",
+		"  c.conname,",
+		"  pg_catalog.pg_get_constraintdef(c.oid)",
+		"FROM pg_catalog.pg_constraint c",
+		"JOIN pg_catalog.pg_class t ON t.oid = c.conrelid",
+		"JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace",
+		"WHERE n.nspname = $1",
+		"AND c.contype = 'c'",
+	}
+	args := []interface{}{schema}
+	if len(tables) > 0 {
+		parts = append(parts, fmt.Sprintf("AND t.relname IN (%s)", postgresPlaceholders(2, len(tables))))
+		for _, t := range tables {
+			args = append(args, t)
+		}
+	}
+	parts = append(parts, "ORDER BY t.relname, c.conname")
+	rows, err := d.db.Query(strings.Join(parts, "\n"), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var checks []CheckConstraint
+	for rows.Next() {
+		var c CheckConstraint
+		if err := rows.Scan(&c.Table, &c.Name, &c.Expression); err != nil {
+			return nil, err
+		}
+		// The definition is in the form of "CHECK (expression)", followed by " NOT VALID" if it is not validated.
+		c.Expression = strings.TrimSuffix(strings.TrimPrefix(c.Expression, "CHECK "), " NOT VALID")
+		checks = append(checks, c)
+	}
+	return checks, rows.Err()
+}
+
+func (d *Postgres) AddCheckConstraintSQL(c CheckConstraint) []string {
+	return []string{fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s CHECK (%s)", d.table(c.Table), d.Quote(c.Name), c.Expression)}
+}
+
+func (d *Postgres) DropCheckConstraintSQL(c CheckConstraint) []string {
+	return []string{fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s", d.table(c.Table), d.Quote(c.Name))}
+}
+
+func (d *Postgres) ReadRows(table string, columns []string, fn func(values []*string) error) error {
+	quoted := make([]string, len(columns))
+	for i, c := range columns {
+		// The values are read as text so that all the types can be scanned into strings.
+		quoted[i] = d.Quote(c) + "::text"
+	}
+	rows, err := d.db.Query(fmt.Sprintf("SELECT %s FROM %s", strings.Join(quoted, ", "), d.table(table)))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	raws := make([]sql.NullString, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range raws {
+		dest[i] = &raws[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+		values := make([]*string, len(raws))
+		for i, raw := range raws {
+			if raw.Valid {
+				v := raw.String
+				values[i] = &v
+			}
+		}
+		if err := fn(values); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (d *Postgres) Duplicates(table string, columns []string, limit int) ([]Duplicate, error) {
+	quoted := make([]string, len(columns))
+	values := make([]string, len(columns))
+	conds := make([]string, len(columns))
+	for i, c := range columns {
+		quoted[i] = d.Quote(c)
+		values[i] = d.Quote(c) + "::text"
+		conds[i] = d.Quote(c) + " IS NOT NULL"
+	}
+	return readDuplicates(d.db, fmt.Sprintf("SELECT %s, COUNT(*) FROM %s WHERE %s GROUP BY %s HAVING COUNT(*) > 1 ORDER BY COUNT(*) DESC LIMIT %d",
+		strings.Join(values, ", "), d.table(table), strings.Join(conds, " AND "), strings.Join(quoted, ", "), limit), len(columns))
+}
+
+// SupportsPersistence reports whether the persistence is PersistenceTemporary or PersistenceUnlogged.
+func (d *Postgres) SupportsPersistence(persistence string) bool {
+	return persistence == PersistenceTemporary || persistence == PersistenceUnlogged
+}
+
+// table returns the quoted name of the table that is qualified by the schema if WithSchema is specified.
+func (d *Postgres) table(name string) string {
+	if d.opt.schema == "" {
+		return d.Quote(name)
+	}
+	return d.Quote(d.opt.schema) + "." + d.Quote(name)
+}
+
+func (d *Postgres) currentSchema() (string, error) {
+	if d.opt.schema != "" {
+		return d.opt.schema, nil
+	}
+	if d.schemaName != "" {
+		return d.schemaName, nil
+	}
+	if err := d.db.QueryRow(`SELECT current_schema()`).Scan(&d.schemaName); err != nil {
+		return "", err
+	}
+	return d.schemaName, nil
+}
+
+// getIndexMap returns the primary key columns and the indexes of the columns except the primary keys of the tables.
+func (d *Postgres) getIndexMap(schema string) (primaryKeys map[string]map[string]bool, indexMap map[string]map[string]postgresIndexInfo, err error) {
+	query := strings.Join([]string{
+		"SELECT",
+		"  t.relname,",
+		"  a.attname,",
+		"  i.relname,",
+		"  ix.indisunique,",
+		"  ix.indisprimary",
+		"FROM pg_catalog.pg_index ix",
+		"JOIN pg_catalog.pg_class t ON t.oid = ix.indrelid",
+		"JOIN pg_catalog.pg_class i ON i.oid = ix.indexrelid",
+		"JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace",
+		"JOIN pg_catalog.pg_attribute a ON a.attrelid = t.oid AND a.attnum = ANY(ix.indkey)",
+		"WHERE n.nspname = $1",
+	}, "\n")
+	rows, err := d.db.Query(query, schema)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	primaryKeys = make(map[string]map[string]bool)
+	indexMap = make(map[string]map[string]postgresIndexInfo)
+	for rows.Next() {
+		var (
+			tableName  string
+			columnName string
+			index      postgresIndexInfo
+			primary    bool
+		)
+		if err := rows.Scan(&tableName, &columnName, &index.name, &index.unique, &primary); err != nil {
+			return nil, nil, err
+		}
+		if primary {
+			if _, exists := primaryKeys[tableName]; !exists {
+				primaryKeys[tableName] = make(map[string]bool)
+			}
+			primaryKeys[tableName][columnName] = true
+			continue
+		}
+		if _, exists := indexMap[tableName]; !exists {
+			indexMap[tableName] = make(map[string]postgresIndexInfo)
+		}
+		indexMap[tableName][columnName] = index
+	}
+	return primaryKeys, indexMap, rows.Err()
+}
+
+func (d *Postgres) columnSQL(f Field) string {
+	column := []string{d.Quote(f.Name), f.Type}
+	if !f.Nullable {
+		column = append(column, "NOT NULL")
+	}
+	if f.Default != "" {
+		column = append(column, "DEFAULT", d.defaultSQL(f))
+	}
+	if f.AutoIncrement {
+		column = append(column, "GENERATED BY DEFAULT AS IDENTITY")
+	}
+	if f.Extra != "" {
+		column = append(column, f.Extra)
+	}
+	return strings.Join(column, " ")
+}
+
+func (d *Postgres) defaultSQL(f Field) string {
+	if d.isTextType(f) {
+		return d.QuoteString(f.Default)
+	}
+	return f.Default
+}
+
+func (d *Postgres) commentSQL(f Field) string {
+	return d.columnCommentSQL(d.table(f.Table), f)
+}
+
+func (d *Postgres) columnCommentSQL(tableName string, f Field) string {
+	comment := "NULL"
+	if f.Comment != "" {
+		comment = d.QuoteString(f.Comment)
+	}
+	return fmt.Sprintf("COMMENT ON COLUMN %s.%s IS %s", tableName, d.Quote(f.Name), comment)
+}
+
+func (d *Postgres) isTextType(f Field) bool {
+	typ := strings.ToUpper(f.Type)
+	for _, t := range []string{"CHARACTER", "TEXT", "VARCHAR", "CHAR"} {
+		if strings.HasPrefix(typ, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// postgresCanonicalType returns the name of the type in the same form as format_type() of PostgreSQL in upper case.
+// (e.g. "varchar(255)" to "CHARACTER VARYING(255)", "timestamptz" to "TIMESTAMP WITH TIME ZONE")
+func postgresCanonicalType(name string) string {
+	name = strings.ToUpper(strings.Join(strings.Fields(name), " "))
+	var array string
+	for strings.HasSuffix(name, "[]") {
+		name, array = strings.TrimSpace(strings.TrimSuffix(name, "[]")), array+"[]"
+	}
+	base, params, rest := name, "", ""
+	if i := strings.IndexByte(name, '('); i >= 0 {
+		if j := strings.IndexByte(name[i:], ')'); j >= 0 {
+			base, params, rest = strings.TrimSpace(name[:i]), strings.Replace(name[i+1:i+j], " ", "", -1), strings.TrimSpace(name[i+j+1:])
+		}
+	}
+	if alias, ok := postgresTypeAliases[base]; ok {
+		base = alias
+	}
+	if t, ok := postgresTimeZoneTypes[base]; ok && (rest == "" || base != t[0]) {
+		base, rest = t[0], t[1]
+	}
+	switch {
+	case base == "CHARACTER" && params == "":
+		params = "1"
+	case base == "NUMERIC" && params != "" && !strings.Contains(params, ","):
+		params += ",0"
+	}
+	if params != "" {
+		base += "(" + params + ")"
+	}
+	if rest != "" {
+		base += " " + rest
+	}
+	return base + array
+}
+
+// postgresPlaceholders returns n placeholders that start from $start separated by commas.
+func postgresPlaceholders(start, n int) string {
+	placeholders := make([]string, n)
+	for i := range placeholders {
+		placeholders[i] = fmt.Sprintf("$%d", start+i)
+	}
+	return strings.Join(placeholders, ",")
+}
+
+type postgresIndexInfo struct {
+	name   string
+	unique bool
+}
+
+type postgresTransaction struct {
+	tx *sql.Tx
+}
+
+func (p *postgresTransaction) Exec(sql string, args ...interface{}) error {
+	_, err := p.tx.Exec(sql, args...)
+	return err
+}
+
+func (p *postgresTransaction) Commit() error {
+	return p.tx.Commit()
+}
+
+func (p *postgresTransaction) Rollback() error {
+	return p.tx.Rollback()
+}
+
+var _ ColumnSchema = &postgresColumnSchema{}
+
+type postgresColumnSchema struct {
+	tableName     string
+	columnName    string
+	columnDefault sql.NullString
+	isNullable    string
+	dataType      string
+	formattedType string
+	isIdentity    string
+	comment       string
+	primaryKey    bool
+	indexName     string
+	unique        bool
+}
+
+func (schema *postgresColumnSchema) TableName() string {
+	return schema.tableName
+}
+
+func (schema *postgresColumnSchema) ColumnName() string {
+	return schema.columnName
+}
+
+func (schema *postgresColumnSchema) ColumnType() string {
+	return postgresCanonicalType(schema.formattedType)
+}
+
+func (schema *postgresColumnSchema) DataType() string {
+	return schema.dataType
+}
+
+func (schema *postgresColumnSchema) IsPrimaryKey() bool {
+	return schema.primaryKey
+}
+
+// IsAutoIncrement reports whether the column is an identity column or a serial column.
+func (schema *postgresColumnSchema) IsAutoIncrement() bool {
+	return schema.isIdentity == "YES" || strings.HasPrefix(schema.columnDefault.String, "nextval(")
+}
+
+func (schema *postgresColumnSchema) Index() (name string, unique bool, ok bool) {
+	if schema.indexName != "" {
+		return schema.indexName, schema.unique, true
+	}
+	return "", false, false
+}
+
+func (schema *postgresColumnSchema) Default() (string, bool) {
+	if !schema.columnDefault.Valid || schema.IsAutoIncrement() {
+		return "", false
+	}
+	def := schema.columnDefault.String
+	// Trim the type cast from like "'x'::character varying".
+	if i := strings.LastIndex(def, "::"); i >= 0 && !strings.Contains(def[i:], "'") && !strings.Contains(def[i:], ")") {
+		def = def[:i]
+	}
+	if def == "NULL" {
+		return "", false
+	}
+	if len(def) >= 2 && def[0] == '\'' && def[len(def)-1] == '\'' {
+		def = strings.Replace(def[1:len(def)-1], "''", "'", -1) // unescape string
+	}
+	return def, true
+}
+
+func (schema *postgresColumnSchema) IsNullable() bool {
+	return strings.ToUpper(schema.isNullable) == "YES"
+}
+
+func (schema *postgresColumnSchema) Extra() (string, bool) {
+	return "", false
+}
+
+func (schema *postgresColumnSchema) Comment() (string, bool) {
+	return schema.comment, schema.comment != ""
+}
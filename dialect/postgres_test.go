@@ -0,0 +1,152 @@
+package dialect_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/naoina/migu/dialect"
+)
+
+func TestPostgresColumnType(t *testing.T) {
+	d := dialect.NewPostgres(nil)
+	for _, v := range []struct {
+		name   string
+		expect string
+	}{
+		{"string", "TEXT"},
+		{"int", "INTEGER"},
+		{"int64", "BIGINT"},
+		{"uint64", "NUMERIC(20,0)"},
+		{"bool", "BOOLEAN"},
+		{"float64", "DOUBLE PRECISION"},
+		{"time.Time", "TIMESTAMP WITH TIME ZONE"},
+		{"[]byte", "BYTEA"},
+		{"varchar(255)", "CHARACTER VARYING(255)"},
+		{"char", "CHARACTER(1)"},
+		{"decimal(10)", "NUMERIC(10,0)"},
+		{"numeric(10, 2)", "NUMERIC(10,2)"},
+		{"timestamp", "TIMESTAMP WITHOUT TIME ZONE"},
+		{"timestamptz(3)", "TIMESTAMP(3) WITH TIME ZONE"},
+		{"timestamp(3) with time zone", "TIMESTAMP(3) WITH TIME ZONE"},
+		{"int4[]", "INTEGER[]"},
+		{"jsonb", "JSONB"},
+	} {
+		if actual := d.ColumnType(v.name); actual != v.expect {
+			t.Errorf("ColumnType(%q) => %q; want %q", v.name, actual, v.expect)
+		}
+	}
+	for _, v := range []struct {
+		name     string
+		nullable bool
+		expect   string
+	}{
+		{"CHARACTER VARYING(255)", false, "string"},
+		{"TEXT", true, "*string"},
+		{"CHARACTER(10)", false, "string"},
+		{"INTEGER", false, "int"},
+		{"BIGINT", true, "*int64"},
+		{"NUMERIC(20,0)", false, "uint64"},
+		{"NUMERIC(10,2)", false, "float64"},
+		{"TIMESTAMP(3) WITH TIME ZONE", false, "time.Time"},
+		{"JSONB", false, "json.RawMessage"},
+		{"JSON", true, "json.RawMessage"},
+	} {
+		if actual := d.GoType(v.name, v.nullable); actual != v.expect {
+			t.Errorf("GoType(%q, %v) => %q; want %q", v.name, v.nullable, actual, v.expect)
+		}
+	}
+}
+
+func TestPostgresSQL(t *testing.T) {
+	d := dialect.NewPostgres(nil, dialect.WithSchema("app"))
+	id := dialect.Field{Table: "user", Name: "id", Type: "BIGINT", AutoIncrement: true}
+	name := dialect.Field{Table: "user", Name: "name", Type: "CHARACTER VARYING(255)", Default: "it's", Comment: "user's name"}
+	for _, v := range []struct {
+		actual []string
+		expect []string
+	}{
+		{
+			d.CreateTableSQL(dialect.Table{Name: "user", Fields: []dialect.Field{id, name}, PrimaryKeys: []string{"id"}}),
+			[]string{
+				"CREATE TABLE \"app\".\"user\" (\n" +
+					"  \"id\" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,\n" +
+					"  \"name\" CHARACTER VARYING(255) NOT NULL DEFAULT 'it''s',\n" +
+					"  PRIMARY KEY (\"id\")\n" +
+					")",
+				"COMMENT ON COLUMN \"app\".\"user\".\"name\" IS 'user''s name'",
+			},
+		},
+		{
+			d.ModifyColumnSQL(name, dialect.Field{Table: "user", Name: "full_name", Type: "TEXT", Nullable: true}),
+			[]string{
+				"ALTER TABLE \"app\".\"user\" RENAME COLUMN \"name\" TO \"full_name\"",
+				"ALTER TABLE \"app\".\"user\" ALTER COLUMN \"full_name\" TYPE TEXT USING \"full_name\"::TEXT, ALTER COLUMN \"full_name\" DROP NOT NULL, ALTER COLUMN \"full_name\" DROP DEFAULT",
+				"COMMENT ON COLUMN \"app\".\"user\".\"full_name\" IS NULL",
+			},
+		},
+		{
+			d.ModifyColumnSQL(id, dialect.Field{Table: "user", Name: "id", Type: "BIGINT"}),
+			[]string{
+				"ALTER TABLE \"app\".\"user\" ALTER COLUMN \"id\" DROP IDENTITY IF EXISTS",
+			},
+		},
+		{
+			d.(dialect.PrimaryKeyModifier).ModifyPrimaryKeySQL([]dialect.Field{id}, []dialect.Field{id, name}),
+			[]string{
+				"ALTER TABLE \"app\".\"user\" DROP CONSTRAINT \"user_pkey\", ADD PRIMARY KEY (\"id\", \"name\")",
+			},
+		},
+		{
+			d.DropIndexSQL(dialect.Index{Table: "user", Name: "user_name"}),
+			[]string{
+				"DROP INDEX \"app\".\"user_name\"",
+			},
+		},
+	} {
+		if diff := cmp.Diff(v.actual, v.expect); diff != "" {
+			t.Errorf("(-got +want)\n%v", diff)
+		}
+	}
+}
+
+func TestPostgresSQLWithDatabase(t *testing.T) {
+	db := sql.OpenDB(&queryConnector{results: []queryResult{
+		{key: "FROM pg_catalog.pg_constraint", columns: []string{"conname"}, rows: [][]driver.Value{{"user_id_key"}}},
+		{key: "pg_get_serial_sequence", columns: []string{"column_default", "pg_get_serial_sequence"}, rows: [][]driver.Value{{"nextval('app.user_id_seq'::regclass)", "app.user_id_seq"}}},
+	}})
+	defer db.Close()
+	d := dialect.NewPostgres(db, dialect.WithSchema("app"))
+	id := dialect.Field{Table: "user", Name: "id", Type: "INTEGER", AutoIncrement: true}
+	name := dialect.Field{Table: "user", Name: "name", Type: "TEXT"}
+	for _, v := range []struct {
+		actual []string
+		expect []string
+	}{
+		{
+			d.(dialect.PrimaryKeyModifier).ModifyPrimaryKeySQL([]dialect.Field{id}, []dialect.Field{id, name}),
+			[]string{
+				"ALTER TABLE \"app\".\"user\" DROP CONSTRAINT \"user_id_key\", ADD PRIMARY KEY (\"id\", \"name\")",
+			},
+		},
+		{
+			d.ModifyColumnSQL(id, dialect.Field{Table: "user", Name: "id", Type: "INTEGER"}),
+			[]string{
+				"ALTER TABLE \"app\".\"user\" ALTER COLUMN \"id\" DROP DEFAULT",
+				"DROP SEQUENCE IF EXISTS app.user_id_seq",
+			},
+		},
+		{
+			d.ModifyColumnSQL(id, dialect.Field{Table: "user", Name: "id", Type: "INTEGER", Default: "0"}),
+			[]string{
+				"ALTER TABLE \"app\".\"user\" ALTER COLUMN \"id\" SET DEFAULT 0",
+				"DROP SEQUENCE IF EXISTS app.user_id_seq",
+			},
+		},
+	} {
+		if diff := cmp.Diff(v.actual, v.expect); diff != "" {
+			t.Errorf("(-got +want)\n%v", diff)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package dialect
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidateIdentifier returns an error if s cannot be used as an identifier such as a table, column or index name.
+func ValidateIdentifier(s string) error {
+	if s == "" {
+		return fmt.Errorf("identifier must not be empty")
+	}
+	return validateControlCharacters(s)
+}
+
+// ValidateLiteral returns an error if s cannot be embedded into SQL as a string literal or a raw fragment such as
+// a default value or an extra clause.
+func ValidateLiteral(s string) error {
+	return validateControlCharacters(s)
+}
+
+// ValidateString returns an error if s cannot be embedded into SQL as a string literal by QuoteString of Dialect,
+// such as a comment. Unlike ValidateLiteral, the tabs and the newlines are valid since they are escaped by QuoteString
+// if the dialect needs it.
+func ValidateString(s string) error {
+	for i, r := range s {
+		switch r {
+		case '\t', '\n', '\r':
+			continue
+		}
+		if r < 0x20 || r == 0x7f {
+			return fmt.Errorf("%q contains the control character %U at %d", s, r, i)
+		}
+	}
+	return nil
+}
+
+func validateControlCharacters(s string) error {
+	for i, r := range s {
+		if r < 0x20 || r == 0x7f {
+			return fmt.Errorf("%q contains the control character %U at %d", s, r, i)
+		}
+	}
+	return nil
+}
+
+// quoteByDoubling surrounds s with quote and escapes quote in s by doubling it.
+// Backslashes, tabs and newlines in s are also escaped by a backslash if escapeBackslash is true.
+func quoteByDoubling(s string, quote string, escapeBackslash bool) string {
+	if escapeBackslash {
+		s = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`).Replace(s)
+	}
+	return quote + strings.Replace(s, quote, quote+quote, -1) + quote
+}
+
+// quoteByBackslash surrounds s with quote and escapes quote, backslashes, tabs and newlines in s by a backslash.
+func quoteByBackslash(s string, quote string) string {
+	return quote + strings.NewReplacer(`\`, `\\`, quote, `\`+quote, "\t", `\t`, "\n", `\n`, "\r", `\r`).Replace(s) + quote
+}
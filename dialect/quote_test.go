@@ -0,0 +1,120 @@
+//go:build go1.18
+// +build go1.18
+
+package dialect_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/naoina/migu/dialect"
+)
+
+// unquote parses the quoted token from the head of s and returns its value and the rest of s.
+func unquote(t *testing.T, s string, backslash bool) (value string, rest string) {
+	t.Helper()
+	if s == "" {
+		t.Fatalf("empty quoted string")
+	}
+	quote := s[0]
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && backslash:
+			if i+1 >= len(s) {
+				t.Fatalf("%q: unterminated escape sequence", s)
+			}
+			i++
+			switch s[i] {
+			case 't':
+				b.WriteByte('\t')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			default:
+				b.WriteByte(s[i])
+			}
+		case c == quote:
+			if i+1 < len(s) && s[i+1] == quote {
+				i++
+				b.WriteByte(quote)
+				continue
+			}
+			return b.String(), s[i+1:]
+		default:
+			b.WriteByte(c)
+		}
+	}
+	t.Fatalf("%q: unterminated quoted string", s)
+	return "", ""
+}
+
+func FuzzQuote(f *testing.F) {
+	for _, s := range []string{"", "user", "a`b", "a'b", `a\`, `\'`, "``", "'; DROP TABLE user; --", "multi\nline", "tab\tcharacter"} {
+		f.Add(s)
+	}
+	mysql, spanner := dialect.NewMySQL(nil), dialect.NewSpanner("")
+	f.Fuzz(func(t *testing.T, s string) {
+		if dialect.ValidateString(s) != nil {
+			t.Skip()
+		}
+		for _, v := range []struct {
+			name      string
+			quoted    string
+			backslash bool
+		}{
+			{"MySQL.Quote", mysql.Quote(s), false},
+			{"MySQL.QuoteString", mysql.QuoteString(s), true},
+			{"Spanner.Quote", spanner.Quote(s), true},
+			{"Spanner.QuoteString", spanner.QuoteString(s), true},
+		} {
+			actual, rest := unquote(t, v.quoted, v.backslash)
+			if actual != s {
+				t.Errorf("%s: unquote(%q) => %q; want %q", v.name, v.quoted, actual, s)
+			}
+			if rest != "" {
+				t.Errorf("%s: %q has trailing characters after the quoted token: %q", v.name, v.quoted, rest)
+			}
+		}
+	})
+}
+
+func TestValidateLiteral(t *testing.T) {
+	for _, v := range []struct {
+		s       string
+		invalid bool
+	}{
+		{"", false},
+		{"comment", false},
+		{"it's a \\ comment", false},
+		{"multi\nline", true},
+		{"null\x00byte", true},
+		{"tab\tcharacter", true},
+		{"del\x7f", true},
+	} {
+		if err := dialect.ValidateLiteral(v.s); (err != nil) != v.invalid {
+			t.Errorf("ValidateLiteral(%q) => %v; want invalid %v", v.s, err, v.invalid)
+		}
+	}
+}
+
+func TestValidateString(t *testing.T) {
+	for _, v := range []struct {
+		s       string
+		invalid bool
+	}{
+		{"", false},
+		{"it's a \\ comment", false},
+		{"multi\nline", false},
+		{"crlf\r\n", false},
+		{"tab\tcharacter", false},
+		{"null\x00byte", true},
+		{"escape\x1b", true},
+		{"del\x7f", true},
+	} {
+		if err := dialect.ValidateString(v.s); (err != nil) != v.invalid {
+			t.Errorf("ValidateString(%q) => %v; want invalid %v", v.s, err, v.invalid)
+		}
+	}
+}
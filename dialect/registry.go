@@ -0,0 +1,50 @@
+package dialect
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]func(*sql.DB) Dialect{}
+)
+
+// Register makes the dialect factory available by the name.
+// It is intended to be called from the init function of the package that provides a third-party dialect, so that
+// the dialect is plugged in by a blank import.
+// If Register is called twice with the same name or if factory is nil, it panics.
+func Register(name string, factory func(*sql.DB) Dialect) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if factory == nil {
+		panic("dialect: Register factory is nil")
+	}
+	if _, dup := registry[name]; dup {
+		panic(fmt.Sprintf("dialect: Register called twice for dialect %s", name))
+	}
+	registry[name] = factory
+}
+
+// Get returns the dialect factory that is registered by the name.
+// The second result reports whether the dialect is registered.
+func Get(name string) (factory func(*sql.DB) Dialect, ok bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	factory, ok = registry[name]
+	return factory, ok
+}
+
+// Registered returns the sorted names of the registered dialects.
+func Registered() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
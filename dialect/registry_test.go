@@ -0,0 +1,36 @@
+package dialect_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/naoina/migu/dialect"
+)
+
+func TestRegister(t *testing.T) {
+	dialect.Register("test-registry", func(db *sql.DB) dialect.Dialect {
+		return dialect.NewMySQL(db)
+	})
+	factory, ok := dialect.Get("test-registry")
+	if !ok {
+		t.Fatalf("Get(%q) => _, false; want true", "test-registry")
+	}
+	if d, ok := factory(nil).(*dialect.MySQL); !ok {
+		t.Errorf("factory(nil) => %T; want *dialect.MySQL", d)
+	}
+	if _, ok := dialect.Get("unknown"); ok {
+		t.Errorf("Get(%q) => _, true; want false", "unknown")
+	}
+	if diff := cmp.Diff(dialect.Registered(), []string{"test-registry"}); diff != "" {
+		t.Errorf("(-got +want)\n%v", diff)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Register with the duplicate name doesn't panic")
+			}
+		}()
+		dialect.Register("test-registry", func(db *sql.DB) dialect.Dialect { return nil })
+	}()
+}
@@ -0,0 +1,55 @@
+package dialect
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+)
+
+// NewSessionConnector returns the connector that executes the statements such as
+// "SET SESSION innodb_lock_wait_timeout = 5" on every new connection of c before it is used.
+// It is used with sql.OpenDB to set up the sessions of the connection pool.
+func NewSessionConnector(c driver.Connector, stmts ...string) driver.Connector {
+	if len(stmts) == 0 {
+		return c
+	}
+	return &sessionConnector{
+		Connector: c,
+		stmts:     stmts,
+	}
+}
+
+type sessionConnector struct {
+	driver.Connector
+	stmts []string
+}
+
+func (c *sessionConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, stmt := range c.stmts {
+		if err := execConn(ctx, conn, stmt); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to initialize the session by %q: %w", stmt, err)
+		}
+	}
+	return conn, nil
+}
+
+func execConn(ctx context.Context, conn driver.Conn, query string) error {
+	if execer, ok := conn.(driver.ExecerContext); ok {
+		_, err := execer.ExecContext(ctx, query, nil)
+		if err != driver.ErrSkip {
+			return err
+		}
+	}
+	stmt, err := conn.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	_, err = stmt.Exec(nil)
+	return err
+}
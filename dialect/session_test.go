@@ -0,0 +1,55 @@
+package dialect_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/naoina/migu/dialect"
+)
+
+type recordConnector struct {
+	queries []string
+}
+
+func (c *recordConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &recordConn{c: c}, nil
+}
+
+func (c *recordConnector) Driver() driver.Driver {
+	return nil
+}
+
+type recordConn struct {
+	driver.Conn
+	c *recordConnector
+}
+
+func (c *recordConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.c.queries = append(c.c.queries, query)
+	return driver.RowsAffected(0), nil
+}
+
+func (c *recordConn) Close() error {
+	return nil
+}
+
+func TestNewSessionConnector(t *testing.T) {
+	c := &recordConnector{}
+	db := sql.OpenDB(dialect.NewSessionConnector(c, "SET SESSION innodb_lock_wait_timeout = 5", "SET SESSION transaction_isolation = 'READ-COMMITTED'"))
+	defer db.Close()
+	if _, err := db.Exec("DO 1"); err != nil {
+		t.Fatal(err)
+	}
+	actual := c.queries
+	expect := []string{
+		"SET SESSION innodb_lock_wait_timeout = 5",
+		"SET SESSION transaction_isolation = 'READ-COMMITTED'",
+		"DO 1",
+	}
+	if diff := cmp.Diff(actual, expect); diff != "" {
+		t.Errorf("(-got +want)\n%v", diff)
+	}
+}
@@ -3,15 +3,22 @@ package dialect
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	monitoring "cloud.google.com/go/monitoring/apiv3"
 	"cloud.google.com/go/spanner"
 	database "cloud.google.com/go/spanner/admin/database/apiv1"
 	"google.golang.org/api/iterator"
 	apioption "google.golang.org/api/option"
+	monitoringpb "google.golang.org/genproto/googleapis/monitoring/v3"
 	databasepb "google.golang.org/genproto/googleapis/spanner/admin/database/v1"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 var (
@@ -59,6 +66,15 @@ var (
 	}
 )
 
+var (
+	_ HealthChecker      = &Spanner{}
+	_ StatelessGenerator = &Spanner{}
+	_ DatabaseCreator    = &Spanner{}
+	_ DatabaseAlterer    = &Spanner{}
+	_ TableRecreator     = &Spanner{}
+	_ RowReader          = &Spanner{}
+)
+
 type Spanner struct {
 	ac              *database.DatabaseAdminClient
 	c               *spanner.Client
@@ -194,9 +210,11 @@ func (s *Spanner) GoType(name string, nullable bool) string {
 		end := strings.LastIndexByte(name, '>')
 		return fmt.Sprintf("[]%s", s.GoType(name[start:end], false))
 	}
-	for _, t := range spannerColumnTypes {
-		if typ, found := t.findGoType(name, nullable, false); found {
-			return typ
+	for _, types := range [][]*ColumnType{s.opt.columnTypes, spannerColumnTypes} {
+		for _, t := range types {
+			if typ, found := t.findGoType(name, nullable, false); found {
+				return typ
+			}
 		}
 	}
 	if end := strings.IndexByte(name, '('); end >= 0 {
@@ -224,12 +242,21 @@ func (s *Spanner) ImportPackage(schema ColumnSchema) string {
 	return ""
 }
 
+func (s *Spanner) GoTypeImports() ([]Import, error) {
+	return goTypeImports(s.opt.columnTypes)
+}
+
 func (d *Spanner) Quote(s string) string {
-	return fmt.Sprintf("`%s`", strings.Replace(s, "`", "``", -1))
+	return quoteByBackslash(s, "`")
 }
 
 func (d *Spanner) QuoteString(s string) string {
-	return fmt.Sprintf("'%s'", strings.Replace(s, "'", `\'`, -1))
+	return quoteByBackslash(s, "'")
+}
+
+// IsStateless returns true since the SQLs of each change are built only from the change.
+func (d *Spanner) IsStateless() bool {
+	return true
 }
 
 func (d *Spanner) CreateTableSQL(table Table) []string {
@@ -290,6 +317,37 @@ func (d *Spanner) ModifyColumnSQL(oldField, newField Field) []string {
 	return ret
 }
 
+// RecreateTableSQL returns the plan to recreate the table as the comments, because the primary keys of Cloud Spanner
+// are immutable and the rows cannot be copied by DDL. The statements of the plan must be run by hand, and the rows
+// of the large table should be copied in batches to stay within the mutation limit of a transaction.
+func (d *Spanner) RecreateTableSQL(oldTable, newTable Table) []string {
+	oldColumns := make(map[string]struct{}, len(oldTable.Fields))
+	for _, f := range oldTable.Fields {
+		oldColumns[f.Name] = struct{}{}
+	}
+	var columns []string
+	for _, f := range newTable.Fields {
+		if _, ok := oldColumns[f.Name]; ok {
+			columns = append(columns, d.Quote(f.Name))
+		}
+	}
+	tmpTable := newTable
+	tmpTable.Name = newTable.Name + "_migu_recreate"
+	tableName, tmpName := d.Quote(newTable.Name), d.Quote(tmpTable.Name)
+	sqls := []string{
+		fmt.Sprintf("-- primary key of table %s cannot be modified, recreate the table by the following statements", tableName),
+	}
+	for _, sql := range d.CreateTableSQL(tmpTable) {
+		sqls = append(sqls, "-- "+strings.Replace(sql, "\n", "\n-- ", -1))
+	}
+	return append(sqls,
+		fmt.Sprintf("-- INSERT INTO %s (%s) SELECT %s FROM %s", tmpName, strings.Join(columns, ", "), strings.Join(columns, ", "), tableName),
+		fmt.Sprintf("-- drop the indexes and the foreign keys of %s before dropping it", tableName),
+		fmt.Sprintf("-- DROP TABLE %s", tableName),
+		fmt.Sprintf("-- ALTER TABLE %s RENAME TO %s", tmpName, tableName),
+	)
+}
+
 func (d *Spanner) CreateIndexSQL(index Index) []string {
 	columns := make([]string, len(index.Columns))
 	for i, c := range index.Columns {
@@ -318,6 +376,44 @@ func (d *Spanner) Begin() (Transactioner, error) {
 	}, nil
 }
 
+// ReadRows reads the rows of the table. The values are cast to STRING by the query, so the columns must not be ARRAY
+// or STRUCT.
+func (d *Spanner) ReadRows(table string, columns []string, fn func(values []*string) error) error {
+	exprs := make([]string, len(columns))
+	for i, c := range columns {
+		exprs[i] = fmt.Sprintf("CAST(%s AS STRING)", d.Quote(c))
+	}
+	client, err := d.client()
+	if err != nil {
+		return err
+	}
+	iter := client.Single().Query(context.Background(), spanner.NewStatement(fmt.Sprintf("SELECT %s FROM %s", strings.Join(exprs, ", "), d.Quote(table))))
+	defer iter.Stop()
+	for {
+		row, err := iter.Next()
+		if err == iterator.Done {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		values := make([]*string, len(columns))
+		for i := range columns {
+			var v spanner.NullString
+			if err := row.Column(i, &v); err != nil {
+				return err
+			}
+			if v.Valid {
+				s := v.StringVal
+				values[i] = &s
+			}
+		}
+		if err := fn(values); err != nil {
+			return err
+		}
+	}
+}
+
 func (d *Spanner) client() (*spanner.Client, error) {
 	if d.c != nil {
 		return d.c, nil
@@ -350,11 +446,167 @@ func (d *Spanner) adminClient() (*database.DatabaseAdminClient, error) {
 	return c, nil
 }
 
+// CreateDatabase creates the database named name in the instance of the database of the dialect.
+// Only Options of opts are set because Cloud Spanner has no character sets, collations and encryption options.
+func (d *Spanner) CreateDatabase(name string, opts DatabaseOptions) (bool, error) {
+	i := strings.Index(d.database, "/databases/")
+	if i < 0 {
+		return false, fmt.Errorf("invalid database path: %s", d.database)
+	}
+	parent := d.database[:i]
+	ctx := context.Background()
+	ac, err := d.adminClient()
+	if err != nil {
+		return false, err
+	}
+	if _, err := ac.GetDatabase(ctx, &databasepb.GetDatabaseRequest{
+		Name: parent + "/databases/" + name,
+	}); err == nil {
+		return false, nil
+	} else if status.Code(err) != codes.NotFound {
+		return false, err
+	}
+	op, err := ac.CreateDatabase(ctx, &databasepb.CreateDatabaseRequest{
+		Parent:          parent,
+		CreateStatement: fmt.Sprintf("CREATE DATABASE %s", d.Quote(name)),
+		ExtraStatements: d.alterDatabaseSQL(name, opts),
+	})
+	if err != nil {
+		return false, err
+	}
+	if _, err := op.Wait(ctx); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// spannerDatabaseOptionTypes are the types of the values of the database options that are not STRING.
+var spannerDatabaseOptionTypes = map[string]string{
+	"optimizer_version":     "INT64",
+	"enable_key_visualizer": "BOOL",
+}
+
+// DatabaseOptions returns the options of the database that are set, such as version_retention_period.
+func (d *Spanner) DatabaseOptions() (DatabaseOptions, error) {
+	client, err := d.client()
+	if err != nil {
+		return DatabaseOptions{}, err
+	}
+	iter := client.Single().Query(context.Background(), spanner.Statement{
+		SQL: "SELECT option_name, option_value FROM information_schema.database_options WHERE schema_name = ''",
+	})
+	defer iter.Stop()
+	opts := DatabaseOptions{Options: map[string]string{}}
+	for {
+		row, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return DatabaseOptions{}, err
+		}
+		var name, value string
+		if err := row.Columns(&name, &value); err != nil {
+			return DatabaseOptions{}, err
+		}
+		opts.Options[name] = value
+	}
+	return opts, nil
+}
+
+// AlterDatabaseSQL returns ALTER DATABASE statement that sets Options of opts.
+func (d *Spanner) AlterDatabaseSQL(opts DatabaseOptions) []string {
+	return d.alterDatabaseSQL(d.database[strings.LastIndex(d.database, "/")+1:], opts)
+}
+
+func (d *Spanner) alterDatabaseSQL(name string, opts DatabaseOptions) []string {
+	if len(opts.Options) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(opts.Options))
+	for option := range opts.Options {
+		names = append(names, option)
+	}
+	sort.Strings(names)
+	options := make([]string, len(names))
+	for i, option := range names {
+		options[i] = option + " = " + d.databaseOptionValue(option, opts.Options[option])
+	}
+	return []string{fmt.Sprintf("ALTER DATABASE %s SET OPTIONS (%s)", d.Quote(name), strings.Join(options, ", "))}
+}
+
+// databaseOptionValue returns the value of the database option in SQL. The values that are not of the type of the
+// option are quoted as STRING so that they cannot be other than the value.
+func (d *Spanner) databaseOptionValue(name, value string) string {
+	switch spannerDatabaseOptionTypes[name] {
+	case "INT64":
+		if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return value
+		}
+	case "BOOL":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return strconv.FormatBool(b)
+		}
+	}
+	return d.QuoteString(value)
+}
+
+// Health returns the CPU utilization of the instance from Cloud Monitoring.
+func (d *Spanner) Health() (Health, error) {
+	// The database is in the form of projects/PROJECT/instances/INSTANCE/databases/DATABASE.
+	parts := strings.Split(d.database, "/")
+	if len(parts) != 6 {
+		return Health{}, fmt.Errorf("invalid database name: %s", d.database)
+	}
+	project, instance := parts[1], parts[3]
+	ctx := context.Background()
+	c, err := monitoring.NewMetricClient(ctx)
+	if err != nil {
+		return Health{}, err
+	}
+	defer c.Close()
+	now := time.Now()
+	it := c.ListTimeSeries(ctx, &monitoringpb.ListTimeSeriesRequest{
+		Name:   "projects/" + project,
+		Filter: fmt.Sprintf(`metric.type = "spanner.googleapis.com/instance/cpu/utilization" AND resource.labels.instance_id = "%s"`, instance),
+		Interval: &monitoringpb.TimeInterval{
+			StartTime: timestamppb.New(now.Add(-5 * time.Minute)),
+			EndTime:   timestamppb.New(now),
+		},
+		View: monitoringpb.ListTimeSeriesRequest_FULL,
+	})
+	health := Health{
+		ThreadsRunning: -1,
+		ReplicationLag: -1,
+		CPUUtilization: -1,
+	}
+	for {
+		ts, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return Health{}, err
+		}
+		// The time series is split by the priority of the tasks. The points are in reverse time order.
+		if points := ts.GetPoints(); len(points) > 0 {
+			if v := points[0].GetValue().GetDoubleValue(); v > health.CPUUtilization {
+				health.CPUUtilization = v
+			}
+		}
+	}
+	return health, nil
+}
+
 type spannerTransaction struct {
 	d *Spanner
 }
 
 func (s *spannerTransaction) Exec(sql string, args ...interface{}) error {
+	// The comment such as the plan of RecreateTableSQL is not a statement.
+	if strings.HasPrefix(sql, "--") {
+		return nil
+	}
 	ctx := context.Background()
 	ac, err := s.d.adminClient()
 	if err != nil {
@@ -0,0 +1,853 @@
+package dialect
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+var (
+	_ PrimaryKeyModifier = &SQLite{}
+	_ IndexReader        = &SQLite{}
+	_ RowReader          = &SQLite{}
+	_ TableRenamer       = &SQLite{}
+	_ ColumnRenamer      = &SQLite{}
+	_ TableAnalyzer      = &SQLite{}
+)
+
+const (
+	// sqlitePrimaryKeyIndex is the name of the primary key that is returned by Indexes in the same way as MySQL.
+	sqlitePrimaryKeyIndex = "PRIMARY"
+
+	// sqliteRebuildPrefix is the prefix of the name of the temporary table to rebuild the table.
+	sqliteRebuildPrefix = "_migu_rebuild_"
+)
+
+var (
+	sqliteColumnTypes = []*ColumnType{
+		{
+			Types:           []string{"TEXT", "VARCHAR", "CHAR", "CLOB"},
+			GoTypes:         []string{"string"},
+			GoNullableTypes: []string{"*string", "sql.NullString"},
+		},
+		{
+			Types:           []string{"BLOB"},
+			GoTypes:         []string{"[]byte"},
+			GoNullableTypes: []string{"[]byte"},
+		},
+		{
+			// INTEGER is a signed 64-bit integer in SQLite.
+			Types:           []string{"INTEGER", "INT", "BIGINT", "SMALLINT", "TINYINT"},
+			GoTypes:         []string{"int64", "int", "int32", "int16", "int8", "uint32", "uint16", "uint8"},
+			GoNullableTypes: []string{"*int64", "sql.NullInt64", "sql.NullInt32"},
+		},
+		{
+			Types:           []string{"BOOLEAN"},
+			GoTypes:         []string{"bool"},
+			GoNullableTypes: []string{"*bool", "sql.NullBool"},
+		},
+		{
+			Types:           []string{"REAL", "DOUBLE", "FLOAT", "NUMERIC"},
+			GoTypes:         []string{"float64", "float32"},
+			GoNullableTypes: []string{"*float64", "sql.NullFloat64"},
+		},
+		{
+			Types:           []string{"DATETIME", "TIMESTAMP", "DATE"},
+			GoTypes:         []string{"time.Time"},
+			GoNullableTypes: []string{"*time.Time", "sql.NullTime"},
+		},
+	}
+)
+
+// SQLite is the dialect of SQLite.
+//
+// SQLite cannot modify the columns and the primary keys by ALTER TABLE. They are emulated by rebuilding the table,
+// that is, creating the new table, copying the rows, dropping the old table and renaming the new table. The definition
+// of the table to rebuild is read by ColumnSchema and is kept updated by the SQLs returned by the dialect, so that the
+// multiple changes of the same table are rebuilt in order.
+type SQLite struct {
+	db              *sql.DB
+	opt             *option
+	columnTypeMap   map[string]*ColumnType
+	nullableTypeMap map[string]struct{}
+	tables          map[string]*sqliteTable
+}
+
+// NewSQLite returns a new dialect of SQLite. db must be opened by the driver of SQLite such as mattn/go-sqlite3.
+func NewSQLite(db *sql.DB, opts ...Option) Dialect {
+	d := &SQLite{
+		db:              db,
+		opt:             newOption(),
+		columnTypeMap:   map[string]*ColumnType{},
+		nullableTypeMap: map[string]struct{}{},
+		tables:          map[string]*sqliteTable{},
+	}
+	for _, o := range opts {
+		o(d.opt)
+	}
+	for _, types := range [][]*ColumnType{sqliteColumnTypes, d.opt.columnTypes} {
+		for _, t := range types {
+			for _, tt := range t.allGoTypes() {
+				d.columnTypeMap[tt] = t
+			}
+			for _, tt := range t.filteredNullableGoTypes() {
+				d.nullableTypeMap[tt] = struct{}{}
+			}
+		}
+	}
+	return d
+}
+
+func (d *SQLite) ColumnSchema(tables ...string) ([]ColumnSchema, error) {
+	query := "SELECT name, sql FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'"
+	var args []interface{}
+	if len(tables) > 0 {
+		query += fmt.Sprintf(" AND name IN (%s)", strings.TrimSuffix(strings.Repeat("?,", len(tables)), ","))
+		for _, t := range tables {
+			args = append(args, t)
+		}
+	}
+	query += " ORDER BY name"
+	rows, err := d.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var names, sqls []string
+	for rows.Next() {
+		var name, sql string
+		if err := rows.Scan(&name, &sql); err != nil {
+			return nil, err
+		}
+		names, sqls = append(names, name), append(sqls, sql)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	var schemas []ColumnSchema
+	for i, name := range names {
+		s, err := d.tableColumnSchema(name, sqls[i])
+		if err != nil {
+			return nil, err
+		}
+		t := &sqliteTable{}
+		for _, schema := range s {
+			schemas = append(schemas, schema)
+			t.fields = append(t.fields, schema.toField())
+		}
+		if t.primaryKeys, err = d.primaryKeys(name); err != nil {
+			return nil, err
+		}
+		if t.indexes, err = d.tableIndexes(name); err != nil {
+			return nil, err
+		}
+		d.tables[name] = t
+	}
+	return schemas, nil
+}
+
+func (d *SQLite) tableColumnSchema(table, createSQL string) ([]*sqliteColumnSchema, error) {
+	rows, err := d.db.Query(`SELECT name, type, "notnull", dflt_value, pk FROM pragma_table_info(?) ORDER BY cid`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var schemas []*sqliteColumnSchema
+	var pks int
+	for rows.Next() {
+		schema := &sqliteColumnSchema{tableName: table}
+		if err := rows.Scan(&schema.columnName, &schema.columnType, &schema.notNull, &schema.columnDefault, &schema.pk); err != nil {
+			return nil, err
+		}
+		if schema.pk > 0 {
+			pks++
+		}
+		schemas = append(schemas, schema)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// AUTOINCREMENT can be specified only for the single INTEGER PRIMARY KEY.
+	autoIncrement := pks == 1 && strings.Contains(strings.ToUpper(createSQL), "AUTOINCREMENT")
+	indexMap, err := d.getIndexMap(table)
+	if err != nil {
+		return nil, err
+	}
+	for _, schema := range schemas {
+		schema.autoIncrement = autoIncrement && schema.pk > 0
+		if info, exists := indexMap[schema.columnName]; exists {
+			schema.indexName = info.name
+			schema.unique = info.unique
+		}
+	}
+	return schemas, nil
+}
+
+func (d *SQLite) ColumnType(name string) string {
+	if t, ok := d.columnTypeMap[name]; ok {
+		name, _, _, _ = t.findType(name)
+	}
+	return strings.ToUpper(name)
+}
+
+func (d *SQLite) GoType(name string, nullable bool) string {
+	name = strings.ToUpper(name)
+	for _, types := range [][]*ColumnType{d.opt.columnTypes, sqliteColumnTypes} {
+		for _, t := range types {
+			if typ, found := t.findGoType(name, nullable, false); found {
+				return typ
+			}
+		}
+	}
+	if strings.IndexByte(name, '(') >= 0 {
+		return d.GoType(trimParens(name), nullable)
+	}
+	// The type that is not listed is determined by the affinity.
+	var typ string
+	switch sqliteAffinity(name) {
+	case "INTEGER":
+		typ = "int64"
+	case "TEXT":
+		typ = "string"
+	case "REAL":
+		typ = "float64"
+	default:
+		return "interface{}"
+	}
+	return d.GoType(d.ColumnType(typ), nullable)
+}
+
+func (d *SQLite) IsNullable(name string) bool {
+	_, ok := d.nullableTypeMap[name]
+	return ok
+}
+
+func (d *SQLite) ImportPackage(schema ColumnSchema) string {
+	switch trimParens(schema.DataType()) {
+	case "DATETIME", "TIMESTAMP", "DATE":
+		return "time"
+	}
+	return ""
+}
+
+func (d *SQLite) GoTypeImports() ([]Import, error) {
+	return goTypeImports(d.opt.columnTypes)
+}
+
+func (d *SQLite) Quote(s string) string {
+	return quoteByDoubling(s, `"`, false)
+}
+
+func (d *SQLite) QuoteString(s string) string {
+	return quoteByDoubling(s, "'", false)
+}
+
+func (d *SQLite) CreateTableSQL(table Table) []string {
+	d.tables[table.Name] = &sqliteTable{
+		fields:      table.Fields,
+		primaryKeys: table.PrimaryKeys,
+	}
+	return []string{d.createTableSQL(table.Name, table.Fields, table.PrimaryKeys, table.Option)}
+}
+
+func (d *SQLite) createTableSQL(name string, fields []Field, primaryKeys []string, option string) string {
+	autoIncrement := len(primaryKeys) == 1
+	if autoIncrement {
+		autoIncrement = false
+		for _, f := range fields {
+			if f.Name == primaryKeys[0] {
+				autoIncrement = f.AutoIncrement
+			}
+		}
+	}
+	columns := make([]string, len(fields))
+	for i, f := range fields {
+		columns[i] = d.columnSQL(f)
+		if autoIncrement && f.Name == primaryKeys[0] {
+			// AUTOINCREMENT must be specified in the column definition of the primary key.
+			columns[i] += " PRIMARY KEY AUTOINCREMENT"
+		}
+	}
+	if len(primaryKeys) > 0 && !autoIncrement {
+		pkColumns := make([]string, len(primaryKeys))
+		for i, pk := range primaryKeys {
+			pkColumns[i] = d.Quote(pk)
+		}
+		columns = append(columns, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(pkColumns, ", ")))
+	}
+	query := fmt.Sprintf("CREATE TABLE %s (\n"+
+		"  %s\n"+
+		")", d.Quote(name), strings.Join(columns, ",\n  "))
+	if option != "" {
+		query += " " + option
+	}
+	return query
+}
+
+// AddColumnSQL returns the SQL to add the column by ALTER TABLE, or SQLs to rebuild the table if the column cannot be
+// added by ALTER TABLE. The rows of the rebuilt table have the zero value of the type for the new NOT NULL column
+// without the default value.
+func (d *SQLite) AddColumnSQL(field Field) []string {
+	t, ok := d.tables[field.Table]
+	if !ok {
+		return []string{fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", d.Quote(field.Table), d.columnSQL(field))}
+	}
+	sources := d.sourceColumns(t)
+	newTable := t.clone()
+	newTable.fields = append(newTable.fields, field)
+	if field.Nullable || field.Default != "" {
+		d.tables[field.Table] = newTable
+		return []string{fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", d.Quote(field.Table), d.columnSQL(field))}
+	}
+	return d.rebuildTableSQL(field.Table, newTable, append(sources, sqliteZeroValue(field.Type)))
+}
+
+// DropColumnSQL returns SQLs to rebuild the table without the column. The indexes of the column are dropped as well.
+func (d *SQLite) DropColumnSQL(field Field) []string {
+	t, ok := d.tables[field.Table]
+	if !ok {
+		return []string{fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", d.Quote(field.Table), d.Quote(field.Name))}
+	}
+	newTable := t.clone()
+	newTable.fields = newTable.fields[:0]
+	var sources []string
+	for _, f := range t.fields {
+		if f.Name != field.Name {
+			newTable.fields = append(newTable.fields, f)
+			sources = append(sources, d.Quote(f.Name))
+		}
+	}
+	for i, pk := range newTable.primaryKeys {
+		if pk == field.Name {
+			newTable.primaryKeys = append(newTable.primaryKeys[:i:i], newTable.primaryKeys[i+1:]...)
+			break
+		}
+	}
+	return d.rebuildTableSQL(field.Table, newTable, sources)
+}
+
+// ModifyColumnSQL returns SQLs to rebuild the table with the modified column.
+func (d *SQLite) ModifyColumnSQL(oldField, newField Field) []string {
+	t, ok := d.tables[newField.Table]
+	if !ok {
+		return []string{d.unknownTableSQL(newField.Table)}
+	}
+	sources := d.sourceColumns(t)
+	newTable := t.clone()
+	for i, f := range newTable.fields {
+		if f.Name == oldField.Name {
+			newTable.fields[i] = newField
+		}
+	}
+	if oldField.Name != newField.Name {
+		for i, pk := range newTable.primaryKeys {
+			if pk == oldField.Name {
+				newTable.primaryKeys[i] = newField.Name
+			}
+		}
+		for i, index := range newTable.indexes {
+			newTable.indexes[i].Columns = renameColumn(index.Columns, oldField.Name, newField.Name)
+		}
+	}
+	return d.rebuildTableSQL(newField.Table, newTable, sources)
+}
+
+// RenameColumnSQL returns the SQL to rename the column by ALTER TABLE if the definition of the column is not changed,
+// or SQLs to rebuild the table otherwise.
+func (d *SQLite) RenameColumnSQL(oldField, newField Field) []string {
+	renamed := oldField
+	renamed.Name = newField.Name
+	if renamed != newField {
+		return d.ModifyColumnSQL(oldField, newField)
+	}
+	if t, ok := d.tables[newField.Table]; ok {
+		newTable := t.clone()
+		for i, f := range newTable.fields {
+			if f.Name == oldField.Name {
+				newTable.fields[i].Name = newField.Name
+			}
+		}
+		for i, pk := range newTable.primaryKeys {
+			if pk == oldField.Name {
+				newTable.primaryKeys[i] = newField.Name
+			}
+		}
+		for i, index := range newTable.indexes {
+			newTable.indexes[i].Columns = renameColumn(index.Columns, oldField.Name, newField.Name)
+		}
+		d.tables[newField.Table] = newTable
+	}
+	return []string{fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", d.Quote(newField.Table), d.Quote(oldField.Name), d.Quote(newField.Name))}
+}
+
+// ModifyPrimaryKeySQL returns SQLs to rebuild the table with the new primary keys.
+func (d *SQLite) ModifyPrimaryKeySQL(oldPrimaryKeys, newPrimaryKeys []Field) []string {
+	var tableName string
+	if len(newPrimaryKeys) > 0 {
+		tableName = newPrimaryKeys[0].Table
+	} else {
+		tableName = oldPrimaryKeys[0].Table
+	}
+	t, ok := d.tables[tableName]
+	if !ok {
+		return []string{d.unknownTableSQL(tableName)}
+	}
+	sources := d.sourceColumns(t)
+	newTable := t.clone()
+	newTable.primaryKeys = make([]string, len(newPrimaryKeys))
+	for i, pk := range newPrimaryKeys {
+		newTable.primaryKeys[i] = pk.Name
+	}
+	return d.rebuildTableSQL(tableName, newTable, sources)
+}
+
+func (d *SQLite) RenameTableSQL(oldName, newName string) []string {
+	if t, ok := d.tables[oldName]; ok {
+		delete(d.tables, oldName)
+		d.tables[newName] = t
+	}
+	return []string{fmt.Sprintf("ALTER TABLE %s RENAME TO %s", d.Quote(oldName), d.Quote(newName))}
+}
+
+func (d *SQLite) AnalyzeTableSQL(table string) []string {
+	return []string{fmt.Sprintf("ANALYZE %s", d.Quote(table))}
+}
+
+func (d *SQLite) CreateIndexSQL(index Index) []string {
+	if t, ok := d.tables[index.Table]; ok {
+		t.indexes = append(t.indexes, index)
+	}
+	return []string{d.createIndexSQL(index)}
+}
+
+func (d *SQLite) createIndexSQL(index Index) string {
+	columns := make([]string, len(index.Columns))
+	for i, c := range index.Columns {
+		columns[i] = d.Quote(c)
+	}
+	indexName := d.Quote(index.Name)
+	tableName := d.Quote(index.Table)
+	column := strings.Join(columns, ",")
+	if index.Unique {
+		return fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (%s)", indexName, tableName, column)
+	}
+	return fmt.Sprintf("CREATE INDEX %s ON %s (%s)", indexName, tableName, column)
+}
+
+func (d *SQLite) DropIndexSQL(index Index) []string {
+	if t, ok := d.tables[index.Table]; ok {
+		for i, idx := range t.indexes {
+			if idx.Name == index.Name {
+				t.indexes = append(t.indexes[:i:i], t.indexes[i+1:]...)
+				break
+			}
+		}
+	}
+	return []string{fmt.Sprintf("DROP INDEX %s", d.Quote(index.Name))}
+}
+
+func (d *SQLite) Begin() (Transactioner, error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteTransaction{
+		tx: tx,
+	}, nil
+}
+
+func (d *SQLite) Indexes(tables ...string) ([]Index, error) {
+	if len(tables) == 0 {
+		rows, err := d.db.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				return nil, err
+			}
+			tables = append(tables, name)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	var indexes []Index
+	for _, table := range tables {
+		pks, err := d.primaryKeys(table)
+		if err != nil {
+			return nil, err
+		}
+		if len(pks) > 0 {
+			indexes = append(indexes, Index{
+				Table:   table,
+				Name:    sqlitePrimaryKeyIndex,
+				Columns: pks,
+				Unique:  true,
+			})
+		}
+		idxs, err := d.tableIndexes(table)
+		if err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, idxs...)
+	}
+	return indexes, nil
+}
+
+// tableIndexes returns the indexes of the table that are created by CREATE INDEX.
+// The indexes that are created implicitly by the PRIMARY KEY and UNIQUE constraints are excluded because they
+// cannot be dropped by DROP INDEX.
+func (d *SQLite) tableIndexes(table string) ([]Index, error) {
+	rows, err := d.db.Query(`SELECT name, "unique" FROM pragma_index_list(?) WHERE origin = 'c' ORDER BY name`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var indexes []Index
+	for rows.Next() {
+		index := Index{Table: table}
+		if err := rows.Scan(&index.Name, &index.Unique); err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, index)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i, index := range indexes {
+		rows, err := d.db.Query(`SELECT name FROM pragma_index_info(?) ORDER BY seqno`, index.Name)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			indexes[i].Columns = append(indexes[i].Columns, name)
+		}
+		if err := rows.Close(); err != nil {
+			return nil, err
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return indexes, nil
+}
+
+func (d *SQLite) ReadRows(table string, columns []string, fn func(values []*string) error) error {
+	quoted := make([]string, len(columns))
+	for i, c := range columns {
+		// The values are read as text so that all the types can be scanned into strings.
+		quoted[i] = fmt.Sprintf("CAST(%s AS TEXT)", d.Quote(c))
+	}
+	rows, err := d.db.Query(fmt.Sprintf("SELECT %s FROM %s", strings.Join(quoted, ", "), d.Quote(table)))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	raws := make([]sql.NullString, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range raws {
+		dest[i] = &raws[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+		values := make([]*string, len(raws))
+		for i, raw := range raws {
+			if raw.Valid {
+				v := raw.String
+				values[i] = &v
+			}
+		}
+		if err := fn(values); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (d *SQLite) Duplicates(table string, columns []string, limit int) ([]Duplicate, error) {
+	quoted := make([]string, len(columns))
+	values := make([]string, len(columns))
+	conds := make([]string, len(columns))
+	for i, c := range columns {
+		quoted[i] = d.Quote(c)
+		values[i] = fmt.Sprintf("CAST(%s AS TEXT)", d.Quote(c))
+		conds[i] = d.Quote(c) + " IS NOT NULL"
+	}
+	return readDuplicates(d.db, fmt.Sprintf("SELECT %s, COUNT(*) FROM %s WHERE %s GROUP BY %s HAVING COUNT(*) > 1 ORDER BY COUNT(*) DESC LIMIT %d",
+		strings.Join(values, ", "), d.Quote(table), strings.Join(conds, " AND "), strings.Join(quoted, ", "), limit), len(columns))
+}
+
+// primaryKeys returns the primary key columns of the table in the order of the primary key.
+func (d *SQLite) primaryKeys(table string) ([]string, error) {
+	rows, err := d.db.Query(`SELECT name FROM pragma_table_info(?) WHERE pk > 0 ORDER BY pk`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var pks []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		pks = append(pks, name)
+	}
+	return pks, rows.Err()
+}
+
+// getIndexMap returns the indexes by the columns of the table except the primary keys.
+func (d *SQLite) getIndexMap(table string) (map[string]sqliteIndexInfo, error) {
+	indexes, err := d.tableIndexes(table)
+	if err != nil {
+		return nil, err
+	}
+	indexMap := make(map[string]sqliteIndexInfo)
+	for _, index := range indexes {
+		for _, c := range index.Columns {
+			indexMap[c] = sqliteIndexInfo{
+				name:   index.Name,
+				unique: index.Unique,
+			}
+		}
+	}
+	return indexMap, nil
+}
+
+// rebuildTableSQL returns SQLs to rebuild the table as newTable. sources are the expressions of the values of the
+// columns of newTable that are selected from the old table.
+// The indexes are recreated except the ones of the columns that no longer exist.
+func (d *SQLite) rebuildTableSQL(name string, newTable *sqliteTable, sources []string) []string {
+	tmpName := sqliteRebuildPrefix + name
+	columns := make([]string, len(newTable.fields))
+	for i, f := range newTable.fields {
+		columns[i] = d.Quote(f.Name)
+	}
+	sqls := []string{
+		d.createTableSQL(tmpName, newTable.fields, newTable.primaryKeys, ""),
+		fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s", d.Quote(tmpName), strings.Join(columns, ", "), strings.Join(sources, ", "), d.Quote(name)),
+		fmt.Sprintf("DROP TABLE %s", d.Quote(name)),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", d.Quote(tmpName), d.Quote(name)),
+	}
+	exists := make(map[string]bool, len(newTable.fields))
+	for _, f := range newTable.fields {
+		exists[f.Name] = true
+	}
+	indexes := newTable.indexes[:0]
+	for _, index := range newTable.indexes {
+		recreate := true
+		for _, c := range index.Columns {
+			recreate = recreate && exists[c]
+		}
+		if recreate {
+			indexes = append(indexes, index)
+			sqls = append(sqls, d.createIndexSQL(index))
+		}
+	}
+	newTable.indexes = indexes
+	d.tables[name] = newTable
+	return sqls
+}
+
+// sourceColumns returns the quoted names of the columns of the table.
+func (d *SQLite) sourceColumns(t *sqliteTable) []string {
+	sources := make([]string, len(t.fields))
+	for i, f := range t.fields {
+		sources[i] = d.Quote(f.Name)
+	}
+	return sources
+}
+
+// unknownTableSQL returns the comment instead of the SQLs to rebuild the table because the definition of the table
+// has not been read from the database by ColumnSchema.
+func (d *SQLite) unknownTableSQL(table string) string {
+	return fmt.Sprintf("-- cannot rebuild table %s without reading its definition from the database", d.Quote(table))
+}
+
+func (d *SQLite) columnSQL(f Field) string {
+	column := []string{d.Quote(f.Name), f.Type}
+	if !f.Nullable {
+		column = append(column, "NOT NULL")
+	}
+	if f.Default != "" {
+		column = append(column, "DEFAULT", d.defaultSQL(f))
+	}
+	if f.Extra != "" {
+		column = append(column, f.Extra)
+	}
+	return strings.Join(column, " ")
+}
+
+func (d *SQLite) defaultSQL(f Field) string {
+	if sqliteAffinity(f.Type) == "TEXT" {
+		return d.QuoteString(f.Default)
+	}
+	return f.Default
+}
+
+// sqliteAffinity returns the type affinity of the declared type by the rules of SQLite.
+func sqliteAffinity(typ string) string {
+	typ = strings.ToUpper(typ)
+	switch {
+	case strings.Contains(typ, "INT"):
+		return "INTEGER"
+	case strings.Contains(typ, "CHAR"), strings.Contains(typ, "CLOB"), strings.Contains(typ, "TEXT"):
+		return "TEXT"
+	case strings.Contains(typ, "BLOB"), typ == "":
+		return "BLOB"
+	case strings.Contains(typ, "REAL"), strings.Contains(typ, "FLOA"), strings.Contains(typ, "DOUB"):
+		return "REAL"
+	}
+	return "NUMERIC"
+}
+
+// sqliteZeroValue returns the zero value of the type for the existing rows.
+func sqliteZeroValue(typ string) string {
+	switch sqliteAffinity(typ) {
+	case "TEXT":
+		return "''"
+	case "BLOB":
+		return "X''"
+	}
+	return "0"
+}
+
+func renameColumn(columns []string, oldName, newName string) []string {
+	renamed := make([]string, len(columns))
+	for i, c := range columns {
+		if c == oldName {
+			c = newName
+		}
+		renamed[i] = c
+	}
+	return renamed
+}
+
+// sqliteTable is the definition of the table that is needed to rebuild the table.
+type sqliteTable struct {
+	fields      []Field
+	primaryKeys []string
+	indexes     []Index
+}
+
+func (t *sqliteTable) clone() *sqliteTable {
+	return &sqliteTable{
+		fields:      append([]Field(nil), t.fields...),
+		primaryKeys: append([]string(nil), t.primaryKeys...),
+		indexes:     append([]Index(nil), t.indexes...),
+	}
+}
+
+type sqliteIndexInfo struct {
+	name   string
+	unique bool
+}
+
+type sqliteTransaction struct {
+	tx *sql.Tx
+}
+
+func (s *sqliteTransaction) Exec(sql string, args ...interface{}) error {
+	_, err := s.tx.Exec(sql, args...)
+	return err
+}
+
+func (s *sqliteTransaction) Commit() error {
+	return s.tx.Commit()
+}
+
+func (s *sqliteTransaction) Rollback() error {
+	return s.tx.Rollback()
+}
+
+var _ ColumnSchema = &sqliteColumnSchema{}
+
+type sqliteColumnSchema struct {
+	tableName     string
+	columnName    string
+	columnType    string
+	notNull       bool
+	columnDefault sql.NullString
+	pk            int
+	autoIncrement bool
+	indexName     string
+	unique        bool
+}
+
+func (schema *sqliteColumnSchema) toField() Field {
+	def, _ := schema.Default()
+	return Field{
+		Table:         schema.tableName,
+		Name:          schema.columnName,
+		Type:          schema.ColumnType(),
+		AutoIncrement: schema.autoIncrement,
+		Default:       def,
+		Nullable:      schema.IsNullable(),
+	}
+}
+
+func (schema *sqliteColumnSchema) TableName() string {
+	return schema.tableName
+}
+
+func (schema *sqliteColumnSchema) ColumnName() string {
+	return schema.columnName
+}
+
+func (schema *sqliteColumnSchema) ColumnType() string {
+	return strings.ToUpper(schema.columnType)
+}
+
+func (schema *sqliteColumnSchema) DataType() string {
+	return strings.ToUpper(schema.columnType)
+}
+
+func (schema *sqliteColumnSchema) IsPrimaryKey() bool {
+	return schema.pk > 0
+}
+
+func (schema *sqliteColumnSchema) IsAutoIncrement() bool {
+	return schema.autoIncrement
+}
+
+func (schema *sqliteColumnSchema) Index() (name string, unique bool, ok bool) {
+	if schema.indexName != "" {
+		return schema.indexName, schema.unique, true
+	}
+	return "", false, false
+}
+
+func (schema *sqliteColumnSchema) Default() (string, bool) {
+	if !schema.columnDefault.Valid {
+		return "", false
+	}
+	def := schema.columnDefault.String
+	if def == "NULL" {
+		return "", false
+	}
+	if len(def) >= 2 && def[0] == '\'' && def[len(def)-1] == '\'' {
+		def = strings.Replace(def[1:len(def)-1], "''", "'", -1) // unescape string
+	}
+	return def, true
+}
+
+func (schema *sqliteColumnSchema) IsNullable() bool {
+	return !schema.notNull
+}
+
+func (schema *sqliteColumnSchema) Extra() (string, bool) {
+	return "", false
+}
+
+func (schema *sqliteColumnSchema) Comment() (string, bool) {
+	return "", false
+}
@@ -0,0 +1,192 @@
+package dialect_test
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/naoina/migu/dialect"
+)
+
+func TestSQLiteColumnType(t *testing.T) {
+	d := dialect.NewSQLite(nil)
+	for _, v := range []struct {
+		name   string
+		expect string
+	}{
+		{"string", "TEXT"},
+		{"int", "INTEGER"},
+		{"int64", "INTEGER"},
+		{"bool", "BOOLEAN"},
+		{"float64", "REAL"},
+		{"time.Time", "DATETIME"},
+		{"[]byte", "BLOB"},
+		{"varchar(255)", "VARCHAR(255)"},
+	} {
+		if actual := d.ColumnType(v.name); actual != v.expect {
+			t.Errorf("ColumnType(%q) => %q; want %q", v.name, actual, v.expect)
+		}
+	}
+	for _, v := range []struct {
+		name     string
+		nullable bool
+		expect   string
+	}{
+		{"VARCHAR(255)", false, "string"},
+		{"TEXT", true, "*string"},
+		{"INTEGER", false, "int64"},
+		{"BIGINT", true, "*int64"},
+		{"UNSIGNED BIG INT", false, "int64"},
+		{"NVARCHAR(10)", false, "string"},
+		{"DATETIME", false, "time.Time"},
+		{"JSON", false, "interface{}"},
+	} {
+		if actual := d.GoType(v.name, v.nullable); actual != v.expect {
+			t.Errorf("GoType(%q, %v) => %q; want %q", v.name, v.nullable, actual, v.expect)
+		}
+	}
+}
+
+func TestSQLiteRebuildTable(t *testing.T) {
+	dir, err := ioutil.TempDir("", "migu-sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, q := range []string{
+		`CREATE TABLE "user" ("id" INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT, "name" VARCHAR(255) NOT NULL, "age" INTEGER)`,
+		`CREATE INDEX "user_name" ON "user" ("name")`,
+		`INSERT INTO "user" ("name", "age") VALUES ('alice', 20), ('bob', NULL)`,
+	} {
+		if _, err := db.Exec(q); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d := dialect.NewSQLite(db)
+	if _, err := d.ColumnSchema(); err != nil {
+		t.Fatal(err)
+	}
+	var sqls []string
+	sqls = append(sqls, d.ModifyColumnSQL(
+		dialect.Field{Table: "user", Name: "name", Type: "VARCHAR(255)"},
+		dialect.Field{Table: "user", Name: "full_name", Type: "TEXT", Default: "it's"},
+	)...)
+	sqls = append(sqls, d.AddColumnSQL(dialect.Field{Table: "user", Name: "active", Type: "BOOLEAN"})...)
+	sqls = append(sqls, d.DropColumnSQL(dialect.Field{Table: "user", Name: "age", Type: "INTEGER", Nullable: true})...)
+	expect := []string{
+		"CREATE TABLE \"_migu_rebuild_user\" (\n" +
+			"  \"id\" INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,\n" +
+			"  \"full_name\" TEXT NOT NULL DEFAULT 'it''s',\n" +
+			"  \"age\" INTEGER\n" +
+			")",
+		`INSERT INTO "_migu_rebuild_user" ("id", "full_name", "age") SELECT "id", "name", "age" FROM "user"`,
+		`DROP TABLE "user"`,
+		`ALTER TABLE "_migu_rebuild_user" RENAME TO "user"`,
+		`CREATE INDEX "user_name" ON "user" ("full_name")`,
+		"CREATE TABLE \"_migu_rebuild_user\" (\n" +
+			"  \"id\" INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,\n" +
+			"  \"full_name\" TEXT NOT NULL DEFAULT 'it''s',\n" +
+			"  \"age\" INTEGER,\n" +
+			"  \"active\" BOOLEAN NOT NULL\n" +
+			")",
+		`INSERT INTO "_migu_rebuild_user" ("id", "full_name", "age", "active") SELECT "id", "full_name", "age", 0 FROM "user"`,
+		`DROP TABLE "user"`,
+		`ALTER TABLE "_migu_rebuild_user" RENAME TO "user"`,
+		`CREATE INDEX "user_name" ON "user" ("full_name")`,
+		"CREATE TABLE \"_migu_rebuild_user\" (\n" +
+			"  \"id\" INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,\n" +
+			"  \"full_name\" TEXT NOT NULL DEFAULT 'it''s',\n" +
+			"  \"active\" BOOLEAN NOT NULL\n" +
+			")",
+		`INSERT INTO "_migu_rebuild_user" ("id", "full_name", "active") SELECT "id", "full_name", "active" FROM "user"`,
+		`DROP TABLE "user"`,
+		`ALTER TABLE "_migu_rebuild_user" RENAME TO "user"`,
+		`CREATE INDEX "user_name" ON "user" ("full_name")`,
+	}
+	if diff := cmp.Diff(sqls, expect); diff != "" {
+		t.Fatalf("(-got +want)\n%v", diff)
+	}
+	tx, err := d.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sql := range sqls {
+		if err := tx.Exec(sql); err != nil {
+			tx.Rollback()
+			t.Fatal(err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	var rows [][]*string
+	if err := d.(dialect.RowReader).ReadRows("user", []string{"id", "full_name", "active"}, func(values []*string) error {
+		rows = append(rows, values)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	s := func(s string) *string { return &s }
+	if diff := cmp.Diff(rows, [][]*string{{s("1"), s("alice"), s("0")}, {s("2"), s("bob"), s("0")}}); diff != "" {
+		t.Errorf("(-got +want)\n%v", diff)
+	}
+	indexes, err := d.(dialect.IndexReader).Indexes("user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(indexes, []dialect.Index{
+		{Table: "user", Name: "PRIMARY", Columns: []string{"id"}, Unique: true},
+		{Table: "user", Name: "user_name", Columns: []string{"full_name"}},
+	}); diff != "" {
+		t.Errorf("(-got +want)\n%v", diff)
+	}
+}
+
+func TestSQLiteDuplicates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "migu-sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, q := range []string{
+		`CREATE TABLE "user" ("name" TEXT, "age" INTEGER)`,
+		`INSERT INTO "user" ("name", "age") VALUES ('alice', 20), ('alice', 20), ('bob', 30), ('bob', 30), ('bob', 30), ('carol', 40), (NULL, 50), (NULL, 50)`,
+	} {
+		if _, err := db.Exec(q); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d := dialect.NewSQLite(db).(dialect.DuplicateReader)
+	actual, err := d.Duplicates("user", []string{"name", "age"}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []dialect.Duplicate{
+		{Values: []string{"bob", "30"}, Count: 3},
+		{Values: []string{"alice", "20"}, Count: 2},
+	}
+	if diff := cmp.Diff(actual, expect); diff != "" {
+		t.Errorf("(-got +want)\n%v", diff)
+	}
+	actual, err = d.Duplicates("user", []string{"name"}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(actual, []dialect.Duplicate{{Values: []string{"bob"}, Count: 3}}); diff != "" {
+		t.Errorf("(-got +want)\n%v", diff)
+	}
+}
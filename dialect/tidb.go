@@ -0,0 +1,223 @@
+package dialect
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	_ PrimaryKeyModifier   = &TiDB{}
+	_ PrimaryKeyChecker    = &TiDB{}
+	_ ColumnSchemaStreamer = &TiDB{}
+	_ IndexReader          = &TiDB{}
+	_ RowReader            = &TiDB{}
+	_ TableRenamer         = &TiDB{}
+	_ ColumnRenamer        = &TiDB{}
+	_ TableAnalyzer        = &TiDB{}
+)
+
+// tidbAutoRandomBits is the prefix of TIDB_ROW_ID_SHARDING_INFO of the table that has the AUTO_RANDOM primary key.
+const tidbAutoRandomBits = "PK_AUTO_RANDOM_BITS="
+
+// TiDB is the dialect of TiDB.
+// It is the same as the dialect of MySQL except the following differences.
+//
+// Each SQL has only one schema change because TiDB before 6.2 cannot change multiple schemas in a statement.
+// The clustered primary keys cannot be changed by ALTER TABLE, so that such changes are refused.
+// The AUTO_RANDOM columns are read as the columns that have AUTO_RANDOM as the extra.
+type TiDB struct {
+	*MySQL
+
+	// tables are the tables that have been read by ColumnSchema.
+	tables map[string]*tidbTable
+}
+
+// NewTiDB returns a new dialect of TiDB. db must be opened by the driver of MySQL.
+func NewTiDB(db *sql.DB, opts ...Option) Dialect {
+	return &TiDB{
+		MySQL:  newMySQL(db, opts...),
+		tables: map[string]*tidbTable{},
+	}
+}
+
+func (d *TiDB) ColumnSchema(tables ...string) ([]ColumnSchema, error) {
+	if err := d.readTables(); err != nil {
+		return nil, err
+	}
+	schemas, err := d.MySQL.ColumnSchema(tables...)
+	if err != nil {
+		return nil, err
+	}
+	for i, schema := range schemas {
+		schemas[i] = d.columnSchema(schema)
+	}
+	return schemas, nil
+}
+
+func (d *TiDB) StreamColumnSchema(ctx context.Context, fn func(columns []ColumnSchema) error) error {
+	if err := d.readTables(); err != nil {
+		return err
+	}
+	return d.MySQL.StreamColumnSchema(ctx, func(columns []ColumnSchema) error {
+		for i, schema := range columns {
+			columns[i] = d.columnSchema(schema)
+		}
+		return fn(columns)
+	})
+}
+
+// columnSchema returns the schema of the column that has AUTO_RANDOM as the extra if it is the AUTO_RANDOM column.
+func (d *TiDB) columnSchema(schema ColumnSchema) ColumnSchema {
+	s := &tidbColumnSchema{mysqlColumnSchema: schema.(*mysqlColumnSchema)}
+	if t, ok := d.tables[s.tableName]; ok && t.autoRandomBits > 0 && s.ColumnName() == t.autoRandomColumn {
+		s.autoRandom = fmt.Sprintf("AUTO_RANDOM(%d)", t.autoRandomBits)
+	}
+	return s
+}
+
+func (d *TiDB) ModifyPrimaryKeySQL(oldPrimaryKeys, newPrimaryKeys []Field) []string {
+	var tableName string
+	if len(newPrimaryKeys) > 0 {
+		tableName = newPrimaryKeys[0].Table
+	} else {
+		tableName = oldPrimaryKeys[0].Table
+	}
+	var sqls []string
+	if len(oldPrimaryKeys) > 0 {
+		sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s DROP PRIMARY KEY", d.Quote(tableName)))
+	}
+	if len(newPrimaryKeys) > 0 {
+		pkColumns := make([]string, len(newPrimaryKeys))
+		for i, pk := range newPrimaryKeys {
+			pkColumns[i] = d.Quote(pk.Name)
+		}
+		// The primary key that is added to the existing table must be non-clustered.
+		sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s ADD PRIMARY KEY (%s) NONCLUSTERED", d.Quote(tableName), strings.Join(pkColumns, ", ")))
+	}
+	return sqls
+}
+
+// CheckModifyPrimaryKey returns an error if the primary key of the table is clustered, which cannot be modified by
+// ALTER TABLE.
+func (d *TiDB) CheckModifyPrimaryKey(table string) error {
+	if t, ok := d.tables[table]; ok && t.clustered {
+		return fmt.Errorf("the clustered primary key of table %s cannot be modified by ALTER TABLE; recreate the table to change it", table)
+	}
+	return nil
+}
+
+// readTables reads whether the primary keys of the tables are clustered and the bits of AUTO_RANDOM.
+func (d *TiDB) readTables() error {
+	dbname, err := d.currentDBName()
+	if err != nil {
+		return err
+	}
+	query := strings.Join([]string{
+		"SELECT",
+		"  TABLE_NAME,",
+		"  TIDB_PK_TYPE,",
+		"  TIDB_ROW_ID_SHARDING_INFO",
+		"FROM information_schema.TABLES",
+		"WHERE TABLE_SCHEMA = ? AND TABLE_TYPE = 'BASE TABLE'",
+	}, "\n")
+	rows, err := d.db.Query(query, dbname)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			tableName string
+			pkType    sql.NullString
+			sharding  sql.NullString
+		)
+		if err := rows.Scan(&tableName, &pkType, &sharding); err != nil {
+			return err
+		}
+		d.tables[tableName] = &tidbTable{
+			clustered:      pkType.String == "CLUSTERED",
+			autoRandomBits: tidbParseAutoRandomBits(sharding.String),
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+	for name, t := range d.tables {
+		if t.autoRandomBits == 0 || t.autoRandomColumn != "" {
+			continue
+		}
+		// The sharding info does not tell which column of the composite primary key is the AUTO_RANDOM column.
+		var table, createTable string
+		if err := d.db.QueryRow("SHOW CREATE TABLE "+d.Quote(name)).Scan(&table, &createTable); err != nil {
+			return err
+		}
+		t.autoRandomColumn = tidbAutoRandomColumn(createTable)
+	}
+	return nil
+}
+
+// tidbAutoRandomColumn returns the name of the column that has AUTO_RANDOM attribute such as
+// "`id` bigint(20) NOT NULL /*T![auto_rand] AUTO_RANDOM(5) */" in the CREATE TABLE statement, or empty if not found.
+func tidbAutoRandomColumn(createTable string) string {
+	for _, line := range strings.Split(createTable, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "`") || !strings.Contains(line, "AUTO_RANDOM(") {
+			continue
+		}
+		var name strings.Builder
+		for i := 1; i < len(line); i++ {
+			if line[i] == '`' {
+				if i+1 < len(line) && line[i+1] == '`' {
+					name.WriteByte('`')
+					i++
+					continue
+				}
+				return name.String()
+			}
+			name.WriteByte(line[i])
+		}
+	}
+	return ""
+}
+
+// tidbParseAutoRandomBits returns the bits of AUTO_RANDOM from TIDB_ROW_ID_SHARDING_INFO such as
+// "PK_AUTO_RANDOM_BITS=5", or 0 if the table has no AUTO_RANDOM column.
+func tidbParseAutoRandomBits(info string) int {
+	if !strings.HasPrefix(info, tidbAutoRandomBits) {
+		return 0
+	}
+	s := info[len(tidbAutoRandomBits):]
+	if i := strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' }); i >= 0 {
+		s = s[:i]
+	}
+	bits, err := strconv.Atoi(s)
+	if err != nil {
+		return 0
+	}
+	return bits
+}
+
+type tidbTable struct {
+	clustered        bool
+	autoRandomBits   int
+	autoRandomColumn string
+}
+
+var _ ColumnSchema = &tidbColumnSchema{}
+
+type tidbColumnSchema struct {
+	*mysqlColumnSchema
+
+	autoRandom string
+}
+
+func (schema *tidbColumnSchema) Extra() (string, bool) {
+	if schema.autoRandom != "" {
+		return schema.autoRandom, true
+	}
+	return schema.mysqlColumnSchema.Extra()
+}
@@ -0,0 +1,173 @@
+package dialect_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/naoina/migu/dialect"
+)
+
+func TestTiDBSQL(t *testing.T) {
+	d := dialect.NewTiDB(nil)
+	id := dialect.Field{Table: "user", Name: "id", Type: "BIGINT", Extra: "AUTO_RANDOM(5)"}
+	name := dialect.Field{Table: "user", Name: "name", Type: "VARCHAR(255)"}
+	for _, v := range []struct {
+		actual []string
+		expect []string
+	}{
+		{
+			d.CreateTableSQL(dialect.Table{Name: "user", Fields: []dialect.Field{id, name}, PrimaryKeys: []string{"id"}}),
+			[]string{
+				"CREATE TABLE `user` (\n" +
+					"  `id` BIGINT NOT NULL AUTO_RANDOM(5),\n" +
+					"  `name` VARCHAR(255) NOT NULL,\n" +
+					"  PRIMARY KEY (`id`)\n" +
+					")",
+			},
+		},
+		{
+			d.(dialect.PrimaryKeyModifier).ModifyPrimaryKeySQL([]dialect.Field{name}, []dialect.Field{id, name}),
+			[]string{
+				"ALTER TABLE `user` DROP PRIMARY KEY",
+				"ALTER TABLE `user` ADD PRIMARY KEY (`id`, `name`) NONCLUSTERED",
+			},
+		},
+		{
+			d.(dialect.PrimaryKeyModifier).ModifyPrimaryKeySQL(nil, []dialect.Field{name}),
+			[]string{
+				"ALTER TABLE `user` ADD PRIMARY KEY (`name`) NONCLUSTERED",
+			},
+		},
+	} {
+		if diff := cmp.Diff(v.actual, v.expect); diff != "" {
+			t.Errorf("(-got +want)\n%v", diff)
+		}
+	}
+}
+
+// queryConnector answers each query by the first result whose key is contained in the query.
+type queryConnector struct {
+	results []queryResult
+}
+
+type queryResult struct {
+	key     string
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (c *queryConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &queryConn{c: c}, nil
+}
+
+func (c *queryConnector) Driver() driver.Driver {
+	return nil
+}
+
+type queryConn struct {
+	driver.Conn
+	c *queryConnector
+}
+
+func (c *queryConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	for _, r := range c.c.results {
+		if strings.Contains(query, r.key) {
+			return &queryRows{result: r}, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected query: %s", query)
+}
+
+func (c *queryConn) Close() error {
+	return nil
+}
+
+type queryRows struct {
+	result queryResult
+	i      int
+}
+
+func (r *queryRows) Columns() []string {
+	return r.result.columns
+}
+
+func (r *queryRows) Close() error {
+	return nil
+}
+
+func (r *queryRows) Next(dest []driver.Value) error {
+	if r.i >= len(r.result.rows) {
+		return io.EOF
+	}
+	copy(dest, r.result.rows[r.i])
+	r.i++
+	return nil
+}
+
+func TestTiDBColumnSchema(t *testing.T) {
+	column := func(table, name, typ, key string) []driver.Value {
+		return []driver.Value{table, name, nil, "NO", "bigint", nil, nil, int64(19), int64(0), nil, typ, key, "", "", nil, nil, "utf8mb4_bin", nil}
+	}
+	db := sql.OpenDB(&queryConnector{results: []queryResult{
+		{key: "SELECT DATABASE()", columns: []string{"DATABASE()"}, rows: [][]driver.Value{{"test"}}},
+		{key: "SELECT VERSION()", columns: []string{"VERSION()"}, rows: [][]driver.Value{{"8.0.11-TiDB-v7.5.0"}}},
+		{key: "TIDB_PK_TYPE", columns: []string{"TABLE_NAME", "TIDB_PK_TYPE", "TIDB_ROW_ID_SHARDING_INFO"}, rows: [][]driver.Value{
+			{"post", "NONCLUSTERED", "NOT_SHARDED(PK_IS_HANDLE)"},
+			{"user", "CLUSTERED", "PK_AUTO_RANDOM_BITS=5"},
+			{"event", "CLUSTERED", "PK_AUTO_RANDOM_BITS=3, RANGE BITS=64"},
+		}},
+		{key: "SHOW CREATE TABLE `user`", columns: []string{"Table", "Create Table"}, rows: [][]driver.Value{
+			{"user", "CREATE TABLE `user` (\n  `id` bigint(20) NOT NULL /*T![auto_rand] AUTO_RANDOM(5) */,\n  PRIMARY KEY (`id`) /*T![clustered_index] CLUSTERED */\n)"},
+		}},
+		{key: "SHOW CREATE TABLE `event`", columns: []string{"Table", "Create Table"}, rows: [][]driver.Value{
+			{"event", "CREATE TABLE `event` (\n  `tenant_id` bigint(20) NOT NULL,\n  `event``id` bigint(20) NOT NULL /*T![auto_rand] AUTO_RANDOM(3) */,\n  PRIMARY KEY (`tenant_id`,`event``id`) /*T![clustered_index] CLUSTERED */\n)"},
+		}},
+		{key: "FROM information_schema.STATISTICS", columns: []string{"TABLE_NAME", "COLUMN_NAME", "NON_UNIQUE", "INDEX_NAME"}, rows: [][]driver.Value{
+			{"event", "tenant_id", int64(0), "PRIMARY"},
+			{"event", "event`id", int64(0), "PRIMARY"},
+			{"post", "id", int64(0), "PRIMARY"},
+			{"user", "id", int64(0), "PRIMARY"},
+		}},
+		{key: "FROM information_schema.COLUMNS", columns: make([]string, 18), rows: [][]driver.Value{
+			column("event", "tenant_id", "bigint(20)", "PRI"),
+			column("event", "event`id", "bigint(20)", "PRI"),
+			column("post", "id", "bigint(20)", "PRI"),
+			column("post", "user_id", "bigint(20)", ""),
+			column("user", "id", "bigint(20)", "PRI"),
+		}},
+	}})
+	defer db.Close()
+	d := dialect.NewTiDB(db)
+	schemas, err := d.ColumnSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actual []string
+	for _, s := range schemas {
+		extra, _ := s.Extra()
+		actual = append(actual, fmt.Sprintf("%s.%s %s pk:%v extra:%q", s.TableName(), s.ColumnName(), s.ColumnType(), s.IsPrimaryKey(), extra))
+	}
+	expect := []string{
+		`event.tenant_id bigint pk:true extra:""`,
+		"event.event`id bigint pk:true extra:\"AUTO_RANDOM(3)\"",
+		`post.id bigint pk:true extra:""`,
+		`post.user_id bigint pk:false extra:""`,
+		`user.id bigint pk:true extra:"AUTO_RANDOM(5)"`,
+	}
+	if diff := cmp.Diff(actual, expect); diff != "" {
+		t.Errorf("(-got +want)\n%v", diff)
+	}
+	checker := d.(dialect.PrimaryKeyChecker)
+	if err := checker.CheckModifyPrimaryKey("user"); err == nil {
+		t.Errorf("CheckModifyPrimaryKey of the clustered primary key => nil; want error")
+	}
+	if err := checker.CheckModifyPrimaryKey("post"); err != nil {
+		t.Errorf("CheckModifyPrimaryKey of the non-clustered primary key => %v; want nil", err)
+	}
+}
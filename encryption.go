@@ -0,0 +1,40 @@
+package migu
+
+import (
+	"github.com/naoina/migu/dialect"
+)
+
+const (
+	encryptionYes = "Y"
+	encryptionNo  = "N"
+)
+
+// validateEncryption returns an error if the table has the encryption annotation but the dialect does not support it.
+func validateEncryption(d dialect.Dialect, name string, tbl *table) error {
+	if tbl.Encryption == "" {
+		return nil
+	}
+	if _, ok := d.(dialect.TableEncrypter); !ok {
+		return newError(ErrUnsupportedFeature, "migu: %s: encryption annotation is not supported by the dialect", name)
+	}
+	return nil
+}
+
+// encryptionChange returns the change to encrypt or decrypt the table, or nil if the encryption is not changed.
+// It returns an error if the change decrypts the table unless WithAllowDecryption is specified.
+func encryptionChange(d dialect.Dialect, name string, oldTbl, newTbl *table, opt *option) (*Change, error) {
+	e, ok := d.(dialect.TableEncrypter)
+	if !ok || newTbl.Encryption == "" || oldTbl.Encryption == "" || oldTbl.Encryption == newTbl.Encryption {
+		return nil, nil
+	}
+	encrypted := newTbl.Encryption == encryptionYes
+	if !encrypted && !opt.allowDecryption {
+		return nil, newError(ErrRefused, "migu: %s: refusing to decrypt the encrypted table; use WithAllowDecryption to decrypt it", name)
+	}
+	return &Change{
+		Kind:      ModifyEncryption,
+		Table:     name,
+		Encrypted: encrypted,
+		SQLs:      e.ModifyTableEncryptionSQL(name, encrypted),
+	}, nil
+}
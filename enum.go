@@ -0,0 +1,220 @@
+package migu
+
+import (
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/naoina/go-stringutil"
+	"github.com/naoina/migu/dialect"
+)
+
+// Enum is the marker interface of the string types whose constants are the elements of ENUM column type.
+// The fields of the types that implement Enum are ENUM columns that consist of the values of the constants of the
+// types in the order of the declarations, unless the type is specified by `type` struct field tag.
+//
+//	type Status string
+//
+//	const (
+//		StatusDraft     Status = "draft"
+//		StatusPublished Status = "published"
+//	)
+//
+//	func (Status) MiguEnum() {}
+type Enum interface {
+	MiguEnum()
+}
+
+// Set is the same as Enum except that the fields of the types that implement Set are SET columns.
+type Set interface {
+	MiguSet()
+}
+
+const (
+	enumMarker = "MiguEnum"
+	setMarker  = "MiguSet"
+)
+
+// enumType is the string type that implements Enum or Set.
+type enumType struct {
+	Set      bool
+	Elements []string
+}
+
+// enumTypes collects the string types that implement Enum or Set and their constants from the files of the package.
+type enumTypes struct {
+	strings  map[string]bool
+	markers  map[string]string
+	elements map[string][]string
+}
+
+func newEnumTypes() *enumTypes {
+	return &enumTypes{
+		strings:  map[string]bool{},
+		markers:  map[string]string{},
+		elements: map[string][]string{},
+	}
+}
+
+func (e *enumTypes) collect(f *ast.File) {
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil || len(d.Recv.List) != 1 || (d.Name.Name != enumMarker && d.Name.Name != setMarker) {
+				continue
+			}
+			recv := d.Recv.List[0].Type
+			if star, ok := recv.(*ast.StarExpr); ok {
+				recv = star.X
+			}
+			if ident, ok := recv.(*ast.Ident); ok {
+				e.markers[ident.Name] = d.Name.Name
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					if ident, ok := s.Type.(*ast.Ident); ok && ident.Name == "string" {
+						e.strings[s.Name.Name] = true
+					}
+				case *ast.ValueSpec:
+					ident, ok := s.Type.(*ast.Ident)
+					if d.Tok != token.CONST || !ok {
+						continue
+					}
+					for _, v := range s.Values {
+						lit, ok := v.(*ast.BasicLit)
+						if !ok || lit.Kind != token.STRING {
+							continue
+						}
+						if value, err := strconv.Unquote(lit.Value); err == nil {
+							e.elements[ident.Name] = append(e.elements[ident.Name], value)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// types returns the collected types by the names.
+func (e *enumTypes) types() map[string]*enumType {
+	m := map[string]*enumType{}
+	for name, marker := range e.markers {
+		if !e.strings[name] {
+			continue
+		}
+		m[name] = &enumType{
+			Set:      marker == setMarker,
+			Elements: e.elements[name],
+		}
+	}
+	return m
+}
+
+// columnType returns the column type of the enum type for the dialect.
+func (e *enumType) columnType(d dialect.Dialect, goType string) (string, error) {
+	kind := "ENUM"
+	if e.Set {
+		kind = "SET"
+	}
+	t, ok := d.(dialect.EnumTyper)
+	if !ok {
+		return "", newError(ErrUnsupportedFeature, "migu: %s: %s column type is not supported by the dialect", goType, kind)
+	}
+	if len(e.Elements) == 0 {
+		return "", newError(ErrUnsupportedType, "migu: %s: %s type has no constants", goType, kind)
+	}
+	return t.EnumColumnType(e.Elements, e.Set), nil
+}
+
+// normalizeEnumColumnType returns the column type of ENUM or SET in the form of the dialect with the elements as they
+// are, since the dialects may change the case of the whole type.
+func normalizeEnumColumnType(d dialect.Dialect, typ string) (string, bool) {
+	t, ok := d.(dialect.EnumTyper)
+	if !ok {
+		return "", false
+	}
+	elements := enumElements(typ)
+	if len(elements) == 0 {
+		return "", false
+	}
+	switch typeBase(typ) {
+	case "ENUM":
+		return t.EnumColumnType(elements, false), true
+	case "SET":
+		return t.EnumColumnType(elements, true), true
+	}
+	return "", false
+}
+
+// enumTypeDecls returns the declarations of the string type that implements Enum or Set, its constants and the method
+// of the marker interface for the ENUM or SET column.
+func enumTypeDecls(name string, typ string) []ast.Decl {
+	marker := enumMarker
+	if typeBase(typ) == "SET" {
+		marker = setMarker
+	}
+	specs := []ast.Spec{}
+	used := map[string]bool{}
+	for i, element := range enumElements(typ) {
+		constName := name + enumConstName(element)
+		if constName == name || used[constName] {
+			constName = name + strconv.Itoa(i+1)
+		}
+		used[constName] = true
+		specs = append(specs, &ast.ValueSpec{
+			Names:  []*ast.Ident{ast.NewIdent(constName)},
+			Type:   ast.NewIdent(name),
+			Values: []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(element)}},
+		})
+	}
+	return []ast.Decl{
+		&ast.GenDecl{
+			Tok: token.TYPE,
+			Specs: []ast.Spec{
+				&ast.TypeSpec{Name: ast.NewIdent(name), Type: ast.NewIdent("string")},
+			},
+		},
+		&ast.GenDecl{
+			Tok:    token.CONST,
+			Lparen: 1,
+			Specs:  specs,
+		},
+		&ast.FuncDecl{
+			Recv: &ast.FieldList{List: []*ast.Field{{Type: ast.NewIdent(name)}}},
+			Name: ast.NewIdent(marker),
+			Type: &ast.FuncType{Params: &ast.FieldList{}},
+			Body: &ast.BlockStmt{},
+		},
+	}
+}
+
+// enumConstName returns the part of the name of the constant from the element in upper camel case.
+func enumConstName(element string) string {
+	s := strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			return r
+		case unicode.IsSpace(r) || r == '-' || r == '_' || r == '.' || r == '/':
+			return '_'
+		}
+		return -1
+	}, element)
+	return stringutil.ToUpperCamelCase(strings.ToLower(s))
+}
+
+// isEnumColumnType reports whether the column of the type is converted into the string type that implements Enum or
+// Set by the dialect.
+func isEnumColumnType(d dialect.Dialect, typ string) bool {
+	if _, ok := d.(dialect.EnumTyper); !ok {
+		return false
+	}
+	switch typeBase(typ) {
+	case "ENUM", "SET":
+		return len(enumElements(typ)) > 0
+	}
+	return false
+}
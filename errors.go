@@ -0,0 +1,86 @@
+package migu
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Code is the stable code of the kind of the error. The codes are documented in ERRORS.md.
+type Code string
+
+// URL returns the URL of the document of the code.
+func (c Code) URL() string {
+	return "https://github.com/naoina/migu/blob/master/ERRORS.md#" + strings.ToLower(string(c))
+}
+
+// Error is the error that has the code of its kind.
+// The kinds of the errors are the variables such as ErrUnsupportedType that can be compared by errors.Is.
+type Error struct {
+	Code Code
+	Err  error
+}
+
+var (
+	// ErrUnsupportedFeature is the kind of the errors that the dialect does not support the feature.
+	ErrUnsupportedFeature = &Error{Code: "E101"}
+
+	// ErrUnsupportedType is the kind of the errors that the type of the struct field is not supported.
+	ErrUnsupportedType = &Error{Code: "E102"}
+
+	// ErrInvalidAnnotation is the kind of the errors that the annotation of the struct is invalid.
+	ErrInvalidAnnotation = &Error{Code: "E103"}
+
+	// ErrInvalidTag is the kind of the errors that the struct field tag or the statement tag is invalid.
+	ErrInvalidTag = &Error{Code: "E104"}
+
+	// ErrInvalidIdentifier is the kind of the errors that the identifier or the literal cannot be embedded into SQL
+	// safely.
+	ErrInvalidIdentifier = &Error{Code: "E105"}
+
+	// ErrInvalidSource is the kind of the errors that the source such as the SQL file or the plan cannot be parsed.
+	ErrInvalidSource = &Error{Code: "E106"}
+
+	// ErrLimitExceeded is the kind of the errors that the table exceeds the limit of the database such as the
+	// maximum row size.
+	ErrLimitExceeded = &Error{Code: "E107"}
+
+	// ErrRefused is the kind of the errors that the change is refused unless it is allowed explicitly.
+	ErrRefused = &Error{Code: "E108"}
+)
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("migu: error %s", e.Code)
+	}
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the kind of e, that is, the *Error that has the same code without Err.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Err == nil && t.Code == e.Code
+}
+
+// ErrorCode returns the code of err, or the empty string if err does not have any code.
+func ErrorCode(err error) Code {
+	var c interface{ ErrorCode() Code }
+	if errors.As(err, &c) {
+		return c.ErrorCode()
+	}
+	return ""
+}
+
+// ErrorCode returns the code of e.
+func (e *Error) ErrorCode() Code {
+	return e.Code
+}
+
+// newError returns the error of the kind that has the formatted message.
+func newError(kind *Error, format string, args ...interface{}) error {
+	return &Error{Code: kind.Code, Err: fmt.Errorf(format, args...)}
+}
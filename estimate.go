@@ -0,0 +1,39 @@
+package migu
+
+import (
+	"github.com/naoina/migu/dialect"
+)
+
+// IsDataAffecting reports whether the change may scan or rebuild the existing rows of the table.
+func (c *Change) IsDataAffecting() bool {
+	switch c.Kind {
+	case AddColumn, DropColumn, ModifyColumn, RenameColumn, ModifyPrimaryKey, ModifyEncryption, ConvertCharset, CreateIndex:
+		return true
+	}
+	return false
+}
+
+// Estimate sets the estimated number of rows to be scanned to each data-affecting change.
+// It does nothing if the dialect does not implement dialect.Estimator.
+func Estimate(d dialect.Dialect, changes []*Change) error {
+	e, ok := d.(dialect.Estimator)
+	if !ok {
+		return nil
+	}
+	rowsMap := map[string]int64{}
+	for _, c := range changes {
+		if !c.IsDataAffecting() {
+			continue
+		}
+		rows, ok := rowsMap[c.Table]
+		if !ok {
+			var err error
+			if rows, err = e.EstimateRows(c.Table); err != nil {
+				return err
+			}
+			rowsMap[c.Table] = rows
+		}
+		c.EstimatedRows = rows
+	}
+	return nil
+}
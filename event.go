@@ -0,0 +1,66 @@
+package migu
+
+import (
+	"context"
+	"time"
+)
+
+// Event is the event of the progress of SyncContext that is sent to the channel specified by WithEvents.
+// The type of the event is one of *PlanComputed, *StatementStarted, *StatementFinished and *Warning.
+type Event interface {
+	event()
+}
+
+// PlanComputed is sent when the changes to be applied are computed.
+type PlanComputed struct {
+	Changes []*Change
+}
+
+// StatementStarted is sent before executing the statement of the change.
+type StatementStarted struct {
+	Change    *Change
+	SQL       string
+	StartedAt time.Time
+}
+
+// StatementFinished is sent after executing the statement of the change.
+// Err is the error of the execution, or nil if it succeeded.
+type StatementFinished struct {
+	Change   *Change
+	SQL      string
+	Duration time.Duration
+	Err      error
+}
+
+// Warning is sent for the problem that does not stop the run, such as the failure of the rollback.
+type Warning struct {
+	Message string
+}
+
+func (*PlanComputed) event()      {}
+func (*StatementStarted) event()  {}
+func (*StatementFinished) event() {}
+func (*Warning) event()           {}
+
+// WithEvents sends the events of the progress of SyncContext to ch, so that the applications can build their own
+// progress UIs and audit sinks. SyncContext blocks until each event is received or the context is done.
+// The channel is not closed by SyncContext.
+func WithEvents(ch chan<- Event) Option {
+	return func(o *option) {
+		o.events = ch
+	}
+}
+
+// sendEvent sends e to the channel of WithEvents if it is specified. The SQLs and the messages of e are redacted by
+// the redactor of WithRedactor.
+func (o *option) sendEvent(ctx context.Context, e Event) error {
+	if o.events == nil {
+		return nil
+	}
+	select {
+	case o.events <- o.redactor.redactEvent(e):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
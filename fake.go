@@ -0,0 +1,242 @@
+package migu
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/naoina/migu/dialect"
+)
+
+// fakeBatchSize is the number of rows in an INSERT statement generated by FakeDataSQL.
+const fakeBatchSize = 100
+
+var (
+	fakeFirstNames = []string{"James", "Mary", "John", "Patricia", "Robert", "Jennifer", "Michael", "Linda", "Hiroshi", "Yuki", "Ana", "Lucas"}
+	fakeLastNames  = []string{"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis", "Sato", "Suzuki", "Silva", "Santos"}
+	fakeWords      = []string{"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit", "sed", "do", "eiusmod", "tempor", "incididunt", "ut", "labore", "et", "dolore", "magna", "aliqua"}
+	fakeEpoch      = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// FakeDataSQL returns the INSERT statements of the sample data for the tables that are declared by Go's structs.
+// Each table gets the rows of plausible values that are generated by the column types and names.
+// The NOT NULL constraints and the uniqueness of the primary keys and the unique indexes are respected, and the
+// columns with AUTO_INCREMENT are left to the database.
+// The same seed generates the same data.
+// WithTablePrefix prefixes the table names of Go's structs in the same way as Diff.
+// The filename and src parameters are treated in the same way as Diff.
+func FakeDataSQL(d dialect.Dialect, filename string, src interface{}, rows int, seed int64, opts ...Option) ([]string, error) {
+	structMap, err := structTables(d, filename, src)
+	if err != nil {
+		return nil, err
+	}
+	structMap = newOption(opts).prefixTables(structMap)
+	names := make([]string, 0, len(structMap))
+	for name := range structMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	r := rand.New(rand.NewSource(seed))
+	var sqls []string
+	for _, name := range names {
+		var fields []*field
+		for _, f := range structMap[name].Fields {
+			if !f.AutoIncrement {
+				fields = append(fields, f)
+			}
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		columns := make([]string, len(fields))
+		for i, f := range fields {
+			columns[i] = d.Quote(f.Column)
+		}
+		head := fmt.Sprintf("INSERT INTO %s (%s) VALUES ", d.Quote(name), strings.Join(columns, ", "))
+		var values []string
+		for i := 0; i < rows; i++ {
+			row := make([]string, len(fields))
+			for j, f := range fields {
+				v, err := fakeValue(d, r, f, i)
+				if err != nil {
+					return nil, fmt.Errorf("migu: %s.%s: %w", name, f.Column, err)
+				}
+				row[j] = v
+			}
+			values = append(values, "("+strings.Join(row, ", ")+")")
+			if len(values) == fakeBatchSize || i == rows-1 {
+				sqls = append(sqls, head+strings.Join(values, ", "))
+				values = values[:0]
+			}
+		}
+	}
+	return sqls, nil
+}
+
+// fakeValue returns the SQL literal of the value of the field for the n-th row.
+func fakeValue(d dialect.Dialect, r *rand.Rand, f *field, n int) (string, error) {
+	unique := f.PrimaryKey || len(f.UniqueIndexes()) > 0
+	if f.Nullable && !unique && r.Intn(10) == 0 {
+		return "NULL", nil
+	}
+	typ := f.Type
+	unsigned := strings.HasSuffix(strings.ToUpper(typ), " UNSIGNED")
+	if unsigned {
+		typ = typ[:len(typ)-len(" UNSIGNED")]
+	}
+	if isSpatialType(typ) {
+		return fakeGeometry(r, f), nil
+	}
+	base, params := typ, ""
+	if i := strings.IndexByte(typ, '('); i >= 0 {
+		base, params = strings.TrimSpace(typ[:i]), strings.TrimSuffix(typ[i+1:], ")")
+	}
+	base = strings.ToUpper(base)
+	switch base {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT", "INT64":
+		if unique {
+			return strconv.Itoa(n + 1), nil
+		}
+		max := map[string]int{"TINYINT": 128, "SMALLINT": 32768}[base]
+		if base == "TINYINT" && params == "1" {
+			max = 2
+		} else if max == 0 {
+			max = 1000000
+		}
+		if !unsigned && max > 2 && r.Intn(10) == 0 {
+			return strconv.Itoa(-r.Intn(max)), nil
+		}
+		return strconv.Itoa(r.Intn(max)), nil
+	case "DECIMAL", "NUMERIC", "FLOAT", "DOUBLE", "REAL", "FLOAT64":
+		precision, scale := 10, 2
+		if params != "" {
+			ps := strings.Split(params, ",")
+			if p, err := strconv.Atoi(strings.TrimSpace(ps[0])); err == nil {
+				precision = p
+			}
+			if len(ps) > 1 {
+				if s, err := strconv.Atoi(strings.TrimSpace(ps[1])); err == nil {
+					scale = s
+				}
+			} else if base == "DECIMAL" || base == "NUMERIC" {
+				scale = 0
+			}
+		}
+		digits := precision - scale
+		if digits > 6 {
+			digits = 6
+		}
+		max := 1.0
+		for i := 0; i < digits; i++ {
+			max *= 10
+		}
+		v := r.Float64() * max
+		if unique {
+			v = float64(n + 1)
+		}
+		return strconv.FormatFloat(v, 'f', scale, 64), nil
+	case "BOOL", "BOOLEAN":
+		return strconv.FormatBool(r.Intn(2) == 0), nil
+	case "CHAR", "VARCHAR", "STRING", "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT":
+		size := map[string]int{"TINYTEXT": 255}[base]
+		if params != "" && strings.ToUpper(params) != "MAX" {
+			s, err := strconv.Atoi(params)
+			if err != nil {
+				return "", fmt.Errorf("invalid column type: %s", f.Type)
+			}
+			size = s
+		}
+		v := fakeString(r, f.Column, n, unique)
+		if size > 0 && len(v) > size {
+			if unique {
+				// Keep the sequence number at the end for the uniqueness.
+				suffix := strconv.Itoa(n + 1)
+				if len(suffix) > size {
+					return "", fmt.Errorf("column is too short for %d unique values", n+1)
+				}
+				v = v[:size-len(suffix)] + suffix
+			} else {
+				v = v[:size]
+			}
+		}
+		return d.QuoteString(v), nil
+	case "ENUM", "SET":
+		choices := strings.Split(params, ",")
+		v := strings.TrimSpace(choices[r.Intn(len(choices))])
+		if v != "" {
+			if s, _, err := unquoteSQL(v); err == nil {
+				v = s
+			}
+		}
+		return d.QuoteString(v), nil
+	case "DATE":
+		return d.QuoteString(fakeTime(r, n, unique, 24*time.Hour).Format("2006-01-02")), nil
+	case "DATETIME", "TIMESTAMP":
+		return d.QuoteString(fakeTime(r, n, unique, time.Second).Format("2006-01-02 15:04:05")), nil
+	case "TIME":
+		return d.QuoteString(fakeTime(r, n%(24*60*60), unique, time.Second).Format("15:04:05")), nil
+	case "YEAR":
+		return strconv.Itoa(fakeTime(r, n, false, 0).Year()), nil
+	case "BINARY", "VARBINARY", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB", "BYTES":
+		size := 16
+		if s, err := strconv.Atoi(params); err == nil && s < size {
+			size = s
+		}
+		b := make([]byte, size)
+		r.Read(b)
+		if unique && size >= 4 {
+			u := uint32(n)
+			b[0], b[1], b[2], b[3] = byte(u>>24), byte(u>>16), byte(u>>8), byte(u)
+		}
+		return fmt.Sprintf("X'%X'", b), nil
+	case "JSON":
+		return d.QuoteString(fmt.Sprintf(`{"id": %d, "value": %q}`, n+1, fakeWords[r.Intn(len(fakeWords))])), nil
+	}
+	if f.Nullable {
+		return "NULL", nil
+	}
+	return "", newError(ErrUnsupportedType, "cannot generate the value of type %s", f.Type)
+}
+
+// fakeString returns the plausible string from the column name.
+func fakeString(r *rand.Rand, column string, n int, unique bool) string {
+	first := fakeFirstNames[r.Intn(len(fakeFirstNames))]
+	last := fakeLastNames[r.Intn(len(fakeLastNames))]
+	var v string
+	switch name := strings.ToLower(column); {
+	case strings.Contains(name, "email"):
+		return fmt.Sprintf("%s.%s%d@example.com", strings.ToLower(first), strings.ToLower(last), n+1)
+	case strings.Contains(name, "url"):
+		return fmt.Sprintf("https://example.com/%s/%d", fakeWords[r.Intn(len(fakeWords))], n+1)
+	case strings.Contains(name, "phone"):
+		return fmt.Sprintf("+1-555-%03d-%04d", r.Intn(1000), n+1)
+	case strings.Contains(name, "first_name"):
+		v = first
+	case strings.Contains(name, "last_name"):
+		v = last
+	case strings.Contains(name, "name"):
+		v = first + " " + last
+	default:
+		words := make([]string, 3+r.Intn(5))
+		for i := range words {
+			words[i] = fakeWords[r.Intn(len(fakeWords))]
+		}
+		v = strings.Join(words, " ")
+	}
+	if unique {
+		v += " " + strconv.Itoa(n+1)
+	}
+	return v
+}
+
+// fakeTime returns the time within 3 years from 2020-01-01.
+// If unique is true, it returns the time that is different by unit for each n.
+func fakeTime(r *rand.Rand, n int, unique bool, unit time.Duration) time.Time {
+	if unique {
+		return fakeEpoch.Add(time.Duration(n) * unit)
+	}
+	return fakeEpoch.Add(time.Duration(r.Int63n(int64(3 * 365 * 24 * time.Hour))).Truncate(time.Second))
+}
@@ -0,0 +1,304 @@
+package migu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/naoina/migu/dialect"
+)
+
+// TargetStatus is the result of synchronizing a target of the fleet.
+type TargetStatus string
+
+const (
+	TargetSucceeded TargetStatus = "succeeded"
+	TargetFailed    TargetStatus = "failed"
+
+	// TargetSkipped is the status of the target that is not started because the fleet is stopped.
+	TargetSkipped TargetStatus = "skipped"
+)
+
+// FleetTarget is a database of the fleet such as an environment or a shard.
+type FleetTarget struct {
+	// Name is the name of the target that is used in the report and the callbacks.
+	Name string
+
+	// Database is the name of the database of the target.
+	Database string
+
+	// Open connects to the database. The returned function is called to close the connection after synchronizing.
+	Open func() (dialect.Dialect, func() error, error)
+}
+
+// Fleet synchronizes the schemas of many databases with bounded parallelism.
+type Fleet struct {
+	// Parallelism is the maximum number of the targets that are synchronized at the same time.
+	// 1 is used if it is not positive.
+	Parallelism int
+
+	// MaxErrorRate is the ratio of the failed targets to all targets in the range of 0 to 1. No more targets are
+	// started once the ratio exceeds it, while the running targets are completed. 0 means stopping at the first failure.
+	MaxErrorRate float64
+
+	// DryRun reports whether the changes are only computed and not applied.
+	DryRun bool
+
+	// Check is called with the changes of each target before applying them, and the target fails if it returns an
+	// error. It is used to refuse the changes such as the destructive ones. It is called even if DryRun.
+	Check func(t *FleetTarget, d dialect.Dialect, changes []*Change) error
+
+	// Canary is the name of the target that is synchronized alone before the others. The others are skipped if the
+	// canary fails or Bake returns an error.
+	Canary string
+
+	// Bake is called after the canary succeeds to find out the problems of the changes before the others, such as by
+	// waiting for a while and checking the health of the applications. It is not called if DryRun.
+	Bake func(ctx context.Context, t *FleetTarget) error
+
+	// OnEvent is called with the events of the progress of each target.
+	OnEvent func(t *FleetTarget, e Event)
+
+	// OnDone is called when each target is finished or skipped.
+	OnDone func(t *FleetTarget, r *FleetTargetReport)
+
+	// mu serializes the calls of OnEvent and OnDone.
+	mu sync.Mutex
+}
+
+// FleetReport is the consolidated report of a run of Fleet. It is encoded in JSON by WriteFleetReport with
+// FormatVersion.
+type FleetReport struct {
+	FormatVersion int `json:"formatVersion"`
+
+	DryRun     bool                 `json:"dryRun"`
+	StartedAt  time.Time            `json:"startedAt"`
+	FinishedAt time.Time            `json:"finishedAt"`
+	Duration   float64              `json:"durationSeconds"`
+	Stopped    bool                 `json:"stopped"`
+	Canary     string               `json:"canary,omitempty"`
+	BakeError  string               `json:"bakeError,omitempty"`
+	Succeeded  int                  `json:"succeeded"`
+	Failed     int                  `json:"failed"`
+	Skipped    int                  `json:"skipped"`
+	Targets    []*FleetTargetReport `json:"targets"`
+}
+
+// FleetTargetReport is the report of a target in FleetReport. Report is nil if the target is skipped.
+type FleetTargetReport struct {
+	Name   string       `json:"name"`
+	Status TargetStatus `json:"status"`
+	Report *RunReport   `json:"report,omitempty"`
+}
+
+// Err returns an error if any target failed or the bake of the canary failed.
+func (r *FleetReport) Err() error {
+	switch {
+	case r.Failed > 0:
+		return fmt.Errorf("migu: %d of %d target(s) failed", r.Failed, len(r.Targets))
+	case r.BakeError != "":
+		return fmt.Errorf("migu: the bake of canary %s failed: %s", r.Canary, r.BakeError)
+	}
+	return nil
+}
+
+// SyncContext synchronizes the schemas of the targets with Go's structs in the same way as SyncContext. The targets
+// are synchronized in the separate transactions, so that the failure of a target does not roll back the others.
+// It returns the report of all targets, and the error of FleetReport.Err.
+// If src is io.Reader, it is read before starting the targets.
+func (f *Fleet) SyncContext(ctx context.Context, targets []*FleetTarget, filename string, src interface{}, opts ...Option) (*FleetReport, error) {
+	if r, ok := src.(io.Reader); ok {
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		src = b
+	}
+	report := &FleetReport{
+		FormatVersion: FormatVersion,
+		DryRun:        f.DryRun,
+		StartedAt:     time.Now(),
+		Targets:       make([]*FleetTargetReport, len(targets)),
+	}
+	for i, t := range targets {
+		report.Targets[i] = &FleetTargetReport{Name: t.Name, Status: TargetSkipped}
+	}
+	parallelism := f.Parallelism
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	var (
+		mu      sync.Mutex
+		failed  int
+		stopped bool
+		wg      sync.WaitGroup
+	)
+	run := func(i int) {
+		t := report.Targets[i]
+		mu.Lock()
+		skip := stopped || ctx.Err() != nil
+		mu.Unlock()
+		if !skip {
+			t.Report = f.syncTarget(ctx, targets[i], filename, src, opts)
+			t.Status = TargetSucceeded
+			if t.Report.Error != "" {
+				t.Status = TargetFailed
+				mu.Lock()
+				failed++
+				if float64(failed)/float64(len(targets)) > f.MaxErrorRate {
+					stopped = true
+				}
+				mu.Unlock()
+			}
+		}
+		f.done(targets[i], t)
+	}
+	canary := -1
+	if f.Canary != "" {
+		for i, t := range targets {
+			if t.Name == f.Canary {
+				canary = i
+				break
+			}
+		}
+		if canary < 0 {
+			return nil, fmt.Errorf("migu: unknown canary target: %s", f.Canary)
+		}
+		report.Canary = f.Canary
+		run(canary)
+		switch {
+		case report.Targets[canary].Status != TargetSucceeded:
+			// The rest are not started whatever MaxErrorRate is, since the canary is to find out the failures before them.
+			stopped = true
+		case f.Bake != nil && !f.DryRun:
+			if err := f.Bake(ctx, targets[canary]); err != nil {
+				report.BakeError = err.Error()
+				stopped = true
+			}
+		}
+	}
+	jobs := make(chan int)
+	for i := 0; i < parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				run(i)
+			}
+		}()
+	}
+	for i := range targets {
+		if i != canary {
+			jobs <- i
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	for _, t := range report.Targets {
+		switch t.Status {
+		case TargetSucceeded:
+			report.Succeeded++
+		case TargetFailed:
+			report.Failed++
+		case TargetSkipped:
+			report.Skipped++
+		}
+	}
+	report.Stopped = report.Skipped > 0
+	report.FinishedAt = time.Now()
+	report.Duration = report.FinishedAt.Sub(report.StartedAt).Seconds()
+	return report, report.Err()
+}
+
+// syncTarget synchronizes a target, and returns the report of it.
+func (f *Fleet) syncTarget(ctx context.Context, t *FleetTarget, filename string, src interface{}, opts []Option) *RunReport {
+	redactor := newOption(opts).redactor
+	report := NewRunReport("sync", t.Database, f.DryRun)
+	events := make(chan Event)
+	received := make(chan struct{})
+	go func() {
+		defer close(received)
+		var start time.Time
+		for e := range events {
+			switch e := e.(type) {
+			case *PlanComputed:
+				report.SetPlan(e.Changes)
+			case *StatementStarted:
+				start = e.StartedAt
+			case *StatementFinished:
+				report.AddStatement(e.Change, e.SQL, start, e.Err)
+			case *Warning:
+				report.Warn(e.Message)
+			}
+			if f.OnEvent != nil {
+				f.mu.Lock()
+				f.OnEvent(t, e)
+				f.mu.Unlock()
+			}
+		}
+	}()
+	err := f.sync(ctx, t, filename, src, append(opts[:len(opts):len(opts)], WithEvents(events)))
+	close(events)
+	<-received
+	report.Finish(err)
+	redactor.RedactReport(report)
+	return report
+}
+
+func (f *Fleet) sync(ctx context.Context, t *FleetTarget, filename string, src interface{}, opts []Option) (err error) {
+	d, closer, err := t.Open()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := closer(); err == nil {
+			err = cerr
+		}
+	}()
+	if !f.DryRun {
+		if err := CheckFreeze(d); err != nil {
+			return err
+		}
+	}
+	changes, err := DiffChanges(d, filename, src, opts...)
+	if err != nil {
+		return err
+	}
+	opt := newOption(opts)
+	if err := opt.sendEvent(ctx, &PlanComputed{Changes: changes}); err != nil {
+		return err
+	}
+	if f.Check != nil {
+		if err := f.Check(t, d, changes); err != nil {
+			return err
+		}
+	}
+	if f.DryRun {
+		return nil
+	}
+	return applyChanges(ctx, d, changes, opt)
+}
+
+func (f *Fleet) done(t *FleetTarget, r *FleetTargetReport) {
+	if f.OnDone == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.OnDone(t, r)
+}
+
+// WriteFleetReport writes the report to the file in JSON.
+func WriteFleetReport(filename string, r *FleetReport) error {
+	v := *r
+	v.FormatVersion = FormatVersion
+	b, err := json.MarshalIndent(&v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, append(b, '\n'), 0644)
+}
@@ -0,0 +1,132 @@
+package migu
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/naoina/go-stringutil"
+	"github.com/naoina/migu/dialect"
+)
+
+// foreignKeyTagRegexp matches the parameter of `fk` struct field tag such as "user(id)".
+var foreignKeyTagRegexp = regexp.MustCompile(`^\s*([^\s(]+)\s*\(\s*([^\s)]+)\s*\)\s*$`)
+
+// parseForeignKeyTag parses the parameter of `fk` struct field tag, and returns the referenced table and column.
+func parseForeignKeyTag(s string) (table, column string, err error) {
+	m := foreignKeyTagRegexp.FindStringSubmatch(s)
+	if m == nil {
+		return "", "", newError(ErrInvalidTag, "`fk` tag must be in the form of TABLE(COLUMN): %q", s)
+	}
+	return m[1], m[2], nil
+}
+
+// structForeignKeys returns the foreign keys that are declared by `fk` struct field tags of the fields of the table.
+// The foreign keys are named TABLE_COLUMN_fkey, which is truncated with the hash if it's too long for the database.
+func structForeignKeys(name string, fields []*field) []dialect.ForeignKey {
+	var fks []dialect.ForeignKey
+	for _, f := range fields {
+		if f.RefTable == "" {
+			continue
+		}
+		fks = append(fks, dialect.ForeignKey{
+			Table:      name,
+			Name:       truncateIdentifier(stringutil.ToSnakeCase(name)+"_"+f.Column+"_fkey", generatedIdentifierLength),
+			Columns:    []string{f.Column},
+			RefTable:   f.RefTable,
+			RefColumns: []string{f.RefColumn},
+		})
+	}
+	return fks
+}
+
+// validateForeignKeys returns an error if the table has the foreign keys but the dialect does not support them.
+func validateForeignKeys(d dialect.Dialect, name string, tbl *table) error {
+	if len(tbl.ForeignKeys) == 0 {
+		return nil
+	}
+	if _, ok := d.(dialect.ForeignKeyManager); !ok {
+		return newError(ErrUnsupportedFeature, "migu: %s: fk tag is not supported by the dialect", name)
+	}
+	return nil
+}
+
+// foreignKeyChanges returns the changes to drop the foreign keys of oldTbl that are not in newTbl, and to add the
+// foreign keys of newTbl that are not in oldTbl. The modified foreign keys are dropped and added again.
+// oldTbl is nil if the table is created.
+func foreignKeyChanges(d dialect.Dialect, name string, oldTbl, newTbl *table) (drops, adds []*Change) {
+	m, ok := d.(dialect.ForeignKeyManager)
+	if !ok {
+		return nil, nil
+	}
+	oldMap := map[string]dialect.ForeignKey{}
+	if oldTbl != nil {
+		for _, fk := range oldTbl.ForeignKeys {
+			oldMap[fk.Name] = fk
+		}
+	}
+	newMap := make(map[string]dialect.ForeignKey, len(newTbl.ForeignKeys))
+	for _, fk := range newTbl.ForeignKeys {
+		newMap[fk.Name] = fk
+	}
+	for _, fk := range sortedForeignKeys(oldMap) {
+		fk := fk
+		newFK, ok := newMap[fk.Name]
+		if ok && equalForeignKey(fk, newFK) {
+			continue
+		}
+		// The foreign keys of the partially owned table may be added by the other processes.
+		if !ok && newTbl.Partial {
+			continue
+		}
+		drops = append(drops, &Change{
+			Kind:       DropForeignKey,
+			Table:      name,
+			Constraint: fk.Name,
+			ForeignKey: &fk,
+			SQLs:       m.DropForeignKeySQL(fk),
+		})
+	}
+	for _, fk := range sortedForeignKeys(newMap) {
+		fk := fk
+		if oldFK, ok := oldMap[fk.Name]; ok && equalForeignKey(oldFK, fk) {
+			continue
+		}
+		adds = append(adds, &Change{
+			Kind:       AddForeignKey,
+			Table:      name,
+			Constraint: fk.Name,
+			ForeignKey: &fk,
+			SQLs:       m.AddForeignKeySQL(fk),
+		})
+	}
+	return drops, adds
+}
+
+// isForeignKeyIndex reports whether the index is the one that is created by the database for the foreign key, such as
+// the index of MySQL that is named after the foreign key.
+func isForeignKeyIndex(tbl *table, name string) bool {
+	for _, fk := range tbl.ForeignKeys {
+		if fk.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedForeignKeys(m map[string]dialect.ForeignKey) []dialect.ForeignKey {
+	fks := make([]dialect.ForeignKey, 0, len(m))
+	for _, fk := range m {
+		fks = append(fks, fk)
+	}
+	sort.Slice(fks, func(i, j int) bool {
+		return fks[i].Name < fks[j].Name
+	})
+	return fks
+}
+
+func equalForeignKey(a, b dialect.ForeignKey) bool {
+	return a.RefTable == b.RefTable &&
+		strings.Join(a.Columns, ",") == strings.Join(b.Columns, ",") &&
+		strings.Join(a.RefColumns, ",") == strings.Join(b.RefColumns, ",")
+}
@@ -0,0 +1,20 @@
+package migu
+
+import "fmt"
+
+// FormatVersion is the version of the format of the JSON documents that are written by Migu, which are RunReport,
+// FleetReport and SchemaReport. It is incremented when the format is changed incompatibly, and the documents of the
+// previous version are still read. The documents are described by the JSON Schemas in jsonschema directory.
+const FormatVersion = 2
+
+// checkFormatVersion returns an error unless the documents of the version can be read. The documents that were
+// written before formatVersion was introduced are of version 1, which have no formatVersion.
+func checkFormatVersion(version int) error {
+	if version == 0 {
+		version = 1
+	}
+	if version < FormatVersion-1 || version > FormatVersion {
+		return fmt.Errorf("unsupported format version %d (supported versions are %d and %d)", version, FormatVersion-1, FormatVersion)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package migu
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/naoina/migu/dialect"
+)
+
+// FreezeTable is the name of the table that has the freeze marker of the schema changes.
+const FreezeTable = "migu_freeze"
+
+// Freeze is the freeze marker that blocks the schema changes of the database until it is removed.
+type Freeze struct {
+	Reason   string
+	FrozenBy string
+	FrozenAt time.Time
+}
+
+func (f *Freeze) String() string {
+	return fmt.Sprintf("frozen by %s at %s: %s", f.FrozenBy, f.FrozenAt.Format(time.RFC3339), f.Reason)
+}
+
+// FrozenError is returned by CheckFreeze when the schema changes of the database are frozen.
+type FrozenError struct {
+	Freeze *Freeze
+}
+
+func (e *FrozenError) Error() string {
+	return fmt.Sprintf("migu: the schema changes are %s", e.Freeze)
+}
+
+// ErrorCode returns the code of FrozenError.
+func (e *FrozenError) ErrorCode() Code {
+	return "E114"
+}
+
+// CurrentFreeze returns the freeze marker of the database, or nil if the schema changes are not frozen.
+// The dialect must implement dialect.RowReader.
+func CurrentFreeze(d dialect.Dialect) (*Freeze, error) {
+	reader, ok := d.(dialect.RowReader)
+	if !ok {
+		return nil, newError(ErrUnsupportedFeature, "migu: the freeze is not supported by the dialect")
+	}
+	schemas, err := d.ColumnSchema(FreezeTable)
+	if err != nil {
+		return nil, err
+	}
+	if len(schemas) == 0 {
+		return nil, nil
+	}
+	var freeze *Freeze
+	if err := reader.ReadRows(FreezeTable, []string{"reason", "frozen_by", "frozen_at"}, func(values []*string) error {
+		f := &Freeze{}
+		if values[0] != nil {
+			f.Reason = *values[0]
+		}
+		if values[1] != nil {
+			f.FrozenBy = *values[1]
+		}
+		if values[2] != nil {
+			t, err := time.Parse(time.RFC3339, *values[2])
+			if err != nil {
+				return fmt.Errorf("migu: invalid frozen_at of the freeze: %w", err)
+			}
+			f.FrozenAt = t
+		}
+		// The earliest freeze is reported if there are many.
+		if freeze == nil || f.FrozenAt.Before(freeze.FrozenAt) {
+			freeze = f
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return freeze, nil
+}
+
+// CheckFreeze returns *FrozenError if the schema changes of the database are frozen.
+// It returns nil if the freeze table does not exist. If the table exists but the dialect does not implement
+// dialect.RowReader, it returns an error since the freeze cannot be checked.
+func CheckFreeze(d dialect.Dialect) error {
+	if _, ok := d.(dialect.RowReader); !ok {
+		schemas, err := d.ColumnSchema(FreezeTable)
+		if err != nil {
+			return err
+		}
+		if len(schemas) == 0 {
+			return nil
+		}
+		return newError(ErrUnsupportedFeature, "migu: %s exists but the freeze cannot be checked by the dialect", FreezeTable)
+	}
+	freeze, err := CurrentFreeze(d)
+	if err != nil {
+		return err
+	}
+	if freeze != nil {
+		return &FrozenError{Freeze: freeze}
+	}
+	return nil
+}
+
+// FreezeChanges returns the changes to set the freeze marker with the reason and the name of who sets it. The change to
+// create the freeze table comes first if it does not exist.
+// It returns an error if the schema changes are already frozen.
+// The dialect must implement dialect.RowReader.
+func FreezeChanges(d dialect.Dialect, reason, frozenBy string, opts ...Option) ([]*Change, error) {
+	if _, ok := d.(dialect.RowReader); !ok {
+		return nil, newError(ErrUnsupportedFeature, "migu: the freeze is not supported by the dialect")
+	}
+	if err := CheckFreeze(d); err != nil {
+		return nil, err
+	}
+	schemas, err := d.ColumnSchema(FreezeTable)
+	if err != nil {
+		return nil, err
+	}
+	var changes []*Change
+	if len(schemas) == 0 {
+		changes = append(changes, &Change{
+			Kind:  CreateTable,
+			Table: FreezeTable,
+			SQLs:  d.CreateTableSQL(freezeTable(d)),
+		})
+	}
+	opt := newOption(opts)
+	return append(changes, &Change{
+		Kind:  FreezeSchema,
+		Table: FreezeTable,
+		SQLs: []string{fmt.Sprintf("INSERT INTO %s (%s, %s, %s) VALUES (%s, %s, %s)",
+			d.Quote(FreezeTable), d.Quote("reason"), d.Quote("frozen_by"), d.Quote("frozen_at"),
+			d.QuoteString(reason), d.QuoteString(frozenBy), d.QuoteString(opt.now().UTC().Format(time.RFC3339)))},
+	}), nil
+}
+
+// UnfreezeChanges returns the changes to remove the freeze marker, or nil if the schema changes are not frozen.
+// The dialect must implement dialect.RowReader.
+func UnfreezeChanges(d dialect.Dialect) ([]*Change, error) {
+	freeze, err := CurrentFreeze(d)
+	if err != nil || freeze == nil {
+		return nil, err
+	}
+	return []*Change{{
+		Kind:  UnfreezeSchema,
+		Table: FreezeTable,
+		SQLs:  []string{fmt.Sprintf("DELETE FROM %s", d.Quote(FreezeTable))},
+	}}, nil
+}
+
+// freezeTable returns the definition of the freeze table. frozen_at is in RFC 3339 in the same way as the migrations
+// table.
+func freezeTable(d dialect.Dialect) dialect.Table {
+	fields := make([]dialect.Field, 0, 3)
+	for _, name := range []string{"reason", "frozen_by", "frozen_at"} {
+		fields = append(fields, dialect.Field{
+			Table: FreezeTable,
+			Name:  name,
+			Type:  d.ColumnType("string"),
+		})
+	}
+	return dialect.Table{
+		Name:        FreezeTable,
+		Fields:      fields,
+		PrimaryKeys: []string{"frozen_at"},
+	}
+}
@@ -3,19 +3,22 @@ module github.com/naoina/migu
 go 1.14
 
 require (
-	cloud.google.com/go v0.74.0 // indirect
+	cloud.google.com/go v0.74.0
 	cloud.google.com/go/spanner v1.12.0
+	github.com/denisenkom/go-mssqldb v0.12.3
 	github.com/go-sql-driver/mysql v1.5.0
 	github.com/goccy/go-yaml v1.8.5
 	github.com/google/go-cmp v0.5.4
 	github.com/howeyc/gopass v0.0.0-20190910152052-7cb4b85ec19c
+	github.com/lib/pq v1.10.9
+	github.com/mattn/go-sqlite3 v1.14.16
 	github.com/naoina/go-stringutil v0.1.0
+	github.com/shopspring/decimal v1.4.0 // indirect
 	github.com/spf13/cobra v1.0.0
 	github.com/spf13/pflag v1.0.5
-	golang.org/x/net v0.0.0-20201216054612-986b41b23924 // indirect
-	golang.org/x/sys v0.0.0-20201221093633-bc327ba9c2f0 // indirect
 	golang.org/x/tools v0.0.0-20201223010750-3fa0e8f87c1a // indirect
 	google.golang.org/api v0.36.0
 	google.golang.org/genproto v0.0.0-20201214200347-8c77b98c765d
 	google.golang.org/grpc v1.34.0
+	google.golang.org/protobuf v1.25.0
 )
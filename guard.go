@@ -0,0 +1,196 @@
+package migu
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/naoina/migu/dialect"
+)
+
+// ActiveIndex represents an index to be dropped that looks actively used.
+type ActiveIndex struct {
+	Table      string
+	Index      string
+	Statistics dialect.IndexStatistics
+}
+
+func (a *ActiveIndex) String() string {
+	size := "unknown"
+	if a.Statistics.Size >= 0 {
+		size = fmt.Sprintf("%d bytes", a.Statistics.Size)
+	}
+	return fmt.Sprintf("%s: index %s looks actively used (reads: %d, cardinality: %d, size: %s)",
+		a.Table, a.Index, a.Statistics.Reads, a.Statistics.Cardinality, size)
+}
+
+// ActiveIndexDrops returns the indexes to be dropped by the changes that have been read since the server started.
+// It returns nothing if the dialect does not implement dialect.IndexStatistician.
+func ActiveIndexDrops(d dialect.Dialect, changes []*Change) ([]*ActiveIndex, error) {
+	s, ok := d.(dialect.IndexStatistician)
+	if !ok {
+		return nil, nil
+	}
+	var actives []*ActiveIndex
+	for _, c := range changes {
+		if c.Kind != DropIndex {
+			continue
+		}
+		stats, err := s.IndexStatistics(c.Table, c.Index)
+		if err != nil {
+			return nil, err
+		}
+		if stats.Reads > 0 {
+			actives = append(actives, &ActiveIndex{
+				Table:      c.Table,
+				Index:      c.Index,
+				Statistics: stats,
+			})
+		}
+	}
+	return actives, nil
+}
+
+// UniqueViolation represents a unique index to be created on the existing rows that have the duplicate values.
+type UniqueViolation struct {
+	Table      string
+	Index      string
+	Columns    []string
+	Duplicates []dialect.Duplicate
+
+	// More reports whether there are more duplicates than Duplicates.
+	More bool
+}
+
+func (v *UniqueViolation) String() string {
+	dups := make([]string, len(v.Duplicates))
+	for i, dup := range v.Duplicates {
+		dups[i] = fmt.Sprintf("(%s) in %d rows", strings.Join(dup.Values, ", "), dup.Count)
+	}
+	more := ""
+	if v.More {
+		more = ", ..."
+	}
+	return fmt.Sprintf("%s: unique index %s on (%s) has duplicates: %s%s",
+		v.Table, v.Index, strings.Join(v.Columns, ", "), strings.Join(dups, ", "), more)
+}
+
+// UniqueViolations returns the unique indexes to be created by the changes whose columns already have the duplicate
+// values, with at most limit duplicates for each index.
+// The indexes of the tables and the columns that are created by the changes are not checked.
+// It returns nothing if the dialect does not implement dialect.DuplicateReader.
+func UniqueViolations(d dialect.Dialect, changes []*Change, limit int) ([]*UniqueViolation, error) {
+	r, ok := d.(dialect.DuplicateReader)
+	if !ok {
+		return nil, nil
+	}
+	created := map[string]struct{}{}
+	for _, c := range changes {
+		switch c.Kind {
+		case CreateTable:
+			created[c.Table] = struct{}{}
+		case AddColumn:
+			created[c.Table+"."+c.Column] = struct{}{}
+		}
+	}
+	var violations []*UniqueViolation
+	for _, c := range changes {
+		if c.Kind != CreateIndex || !c.Unique || isCreated(created, c.Table, c.IndexColumns) {
+			continue
+		}
+		dups, err := r.Duplicates(c.Table, c.IndexColumns, limit+1)
+		if err != nil {
+			return nil, err
+		}
+		if len(dups) == 0 {
+			continue
+		}
+		v := &UniqueViolation{
+			Table:      c.Table,
+			Index:      c.Index,
+			Columns:    c.IndexColumns,
+			Duplicates: dups,
+		}
+		if len(dups) > limit {
+			v.Duplicates, v.More = dups[:limit], true
+		}
+		violations = append(violations, v)
+	}
+	return violations, nil
+}
+
+// isCreated reports whether the table or any of the columns is in created.
+func isCreated(created map[string]struct{}, table string, columns []string) bool {
+	if _, ok := created[table]; ok {
+		return true
+	}
+	for _, column := range columns {
+		if _, ok := created[table+"."+column]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ForeignKeyViolation represents a foreign key to be added on the existing rows that reference no rows.
+type ForeignKeyViolation struct {
+	ForeignKey dialect.ForeignKey
+	Orphans    dialect.Orphans
+}
+
+func (v *ForeignKeyViolation) String() string {
+	values := make([]string, len(v.Orphans.Values))
+	for i, value := range v.Orphans.Values {
+		values[i] = "(" + strings.Join(value, ", ") + ")"
+	}
+	more := ""
+	if int64(len(values)) < v.Orphans.Count {
+		more = ", ..."
+	}
+	fk := v.ForeignKey
+	return fmt.Sprintf("%s: foreign key %s on (%s) has %d row(s) that reference no rows of %s (%s): %s%s",
+		fk.Table, fk.Name, strings.Join(fk.Columns, ", "), v.Orphans.Count, fk.RefTable, strings.Join(fk.RefColumns, ", "),
+		strings.Join(values, ", "), more)
+}
+
+// ForeignKeyViolations returns the foreign keys to be added by the changes whose columns already have the values
+// that reference no rows, with at most limit values for each foreign key.
+// The foreign keys of the tables and the columns that are created by the changes are not checked, and neither are
+// the ones that reference them.
+// It returns nothing if the dialect does not implement dialect.OrphanReader.
+func ForeignKeyViolations(d dialect.Dialect, changes []*Change, limit int) ([]*ForeignKeyViolation, error) {
+	r, ok := d.(dialect.OrphanReader)
+	if !ok {
+		return nil, nil
+	}
+	created := map[string]struct{}{}
+	for _, c := range changes {
+		switch c.Kind {
+		case CreateTable:
+			created[c.Table] = struct{}{}
+		case AddColumn:
+			created[c.Table+"."+c.Column] = struct{}{}
+		}
+	}
+	var violations []*ForeignKeyViolation
+	for _, c := range changes {
+		if c.Kind != AddForeignKey || c.ForeignKey == nil {
+			continue
+		}
+		fk := *c.ForeignKey
+		if isCreated(created, fk.Table, fk.Columns) || isCreated(created, fk.RefTable, fk.RefColumns) {
+			continue
+		}
+		orphans, err := r.Orphans(fk, limit)
+		if err != nil {
+			return nil, err
+		}
+		if orphans.Count == 0 {
+			continue
+		}
+		violations = append(violations, &ForeignKeyViolation{
+			ForeignKey: fk,
+			Orphans:    orphans,
+		})
+	}
+	return violations, nil
+}
@@ -0,0 +1,145 @@
+package migu
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+
+	"github.com/naoina/migu/dialect"
+)
+
+// hashPrefix is the prefix of the hashes of the tables that represents the algorithm.
+const hashPrefix = "sha256:"
+
+// hashEscaper escapes the values in the canonical form of the tables.
+var hashEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`)
+
+// WithIgnoreColumnOrder makes the hashes of the tables independent of the order of the columns.
+// The order of the columns of the composite primary keys and indexes is still significant.
+func WithIgnoreColumnOrder() Option {
+	return func(o *option) {
+		o.ignoreColumnOrder = true
+	}
+}
+
+// TableHashes returns the hashes of the tables that are declared by Go's structs.
+// The filename and src parameters are treated in the same way as Diff.
+//
+// The hash is the same for the same definition of the table, so that it can be used to detect the changes of the
+// tables without comparing the whole of them. The algorithm is documented in README.md so that the other tools can
+// compute the same hash.
+func TableHashes(d dialect.Dialect, filename string, src interface{}, opts ...Option) (map[string]string, error) {
+	structMap, err := structTables(d, filename, src)
+	if err != nil {
+		return nil, err
+	}
+	opt := newOption(opts)
+	return makeTableHashes(opt.prefixTables(structMap), opt), nil
+}
+
+// DatabaseTableHashes returns the hashes of the tables in the database in the same way as TableHashes.
+func DatabaseTableHashes(d dialect.Dialect, opts ...Option) (map[string]string, error) {
+	tableMap, err := databaseTables(d)
+	if err != nil {
+		return nil, err
+	}
+	opt := newOption(opts)
+	return makeTableHashes(opt.scopeTables(tableMap), opt), nil
+}
+
+func makeTableHashes(tableMap map[string]*table, opt *option) map[string]string {
+	hashes := make(map[string]string, len(tableMap))
+	for name, tbl := range tableMap {
+		// The temporary tables are not hashed because they never exist in the database.
+		if tbl.Persistence == dialect.PersistenceTemporary {
+			continue
+		}
+		sum := sha256.Sum256([]byte(canonicalTable(name, tbl, opt.ignoreColumnOrder)))
+		hashes[name] = hashPrefix + hex.EncodeToString(sum[:])
+	}
+	return hashes
+}
+
+// canonicalTable returns the canonical form of the definition of the table that is hashed.
+// Each line consists of the values that are separated by a tab and ends with a line feed. A backslash, a tab and a
+// line feed in the values are escaped as \\, \t and \n.
+func canonicalTable(name string, tbl *table, ignoreColumnOrder bool) string {
+	var lines [][]string
+	lines = append(lines, []string{"table", name})
+	if tbl.Option != "" {
+		lines = append(lines, []string{"option", tbl.Option})
+	}
+	fields := make([]*field, 0, len(tbl.Fields))
+	for _, f := range tbl.Fields {
+		if !f.Ignore && !f.IsEmbedded() {
+			fields = append(fields, f)
+		}
+	}
+	columns := make([][]string, len(fields))
+	for i, f := range fields {
+		nullable, autoIncrement := "NOT NULL", ""
+		if f.Nullable {
+			nullable = "NULL"
+		}
+		if f.AutoIncrement {
+			autoIncrement = "AUTO_INCREMENT"
+		}
+		// ON UPDATE clause is a part of the extra in the same way as the older versions.
+		extra := f.Extra
+		if f.OnUpdate != "" {
+			extra = strings.TrimSpace("ON UPDATE " + f.OnUpdate + " " + extra)
+		}
+		columns[i] = []string{"column", f.Column, f.Type, nullable, f.Default, autoIncrement, extra, f.Comment}
+	}
+	if ignoreColumnOrder {
+		sort.Slice(columns, func(i, j int) bool {
+			return columns[i][1] < columns[j][1]
+		})
+	}
+	lines = append(lines, columns...)
+	var pks []string
+	var indexes [][]string
+	indexMap := map[[2]string]int{}
+	addIndex := func(kind, name, column string) {
+		key := [2]string{kind, name}
+		if i, exists := indexMap[key]; exists {
+			indexes[i] = append(indexes[i], column)
+			return
+		}
+		indexMap[key] = len(indexes)
+		indexes = append(indexes, []string{kind, name, column})
+	}
+	for _, f := range fields {
+		if f.PrimaryKey {
+			pks = append(pks, f.Column)
+		}
+		for _, name := range f.Indexes() {
+			addIndex("index", name, f.Column)
+		}
+		for _, name := range f.UniqueIndexes() {
+			addIndex("unique", name, f.Column)
+		}
+	}
+	if len(pks) > 0 {
+		lines = append(lines, append([]string{"primary"}, pks...))
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		if indexes[i][0] != indexes[j][0] {
+			return indexes[i][0] < indexes[j][0]
+		}
+		return indexes[i][1] < indexes[j][1]
+	})
+	lines = append(lines, indexes...)
+	var b strings.Builder
+	for _, line := range lines {
+		for i, v := range line {
+			if i > 0 {
+				b.WriteByte('\t')
+			}
+			b.WriteString(hashEscaper.Replace(v))
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
@@ -0,0 +1,97 @@
+package migu
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/naoina/migu/dialect"
+)
+
+const defaultHealthCheckInterval = 5 * time.Second
+
+// HealthCheck checks the load of the database during applying the changes.
+// The zero value of each threshold means no limit.
+type HealthCheck struct {
+	MaxThreadsRunning int64
+	MaxReplicationLag time.Duration
+	MaxCPUUtilization float64
+
+	// Interval is the interval of checks while the database is unhealthy. If it is 0, 5 seconds is used.
+	Interval time.Duration
+
+	// Timeout is the maximum duration to wait for the database to get healthy. If it is 0, Wait returns an error
+	// immediately when the database is unhealthy.
+	Timeout time.Duration
+
+	// OnUnhealthy is called with the reason each time the database is found to be unhealthy, if it is not nil.
+	OnUnhealthy func(reason string)
+}
+
+// UnhealthyError is returned by HealthCheck.Wait when the database does not get healthy within the timeout.
+type UnhealthyError struct {
+	Reason string
+}
+
+func (e *UnhealthyError) Error() string {
+	return fmt.Sprintf("migu: aborted because the database is unhealthy: %s", e.Reason)
+}
+
+// ErrorCode returns the code of UnhealthyError.
+func (e *UnhealthyError) ErrorCode() Code {
+	return "E112"
+}
+
+// IsEnabled reports whether any threshold is set.
+func (c *HealthCheck) IsEnabled() bool {
+	return c.MaxThreadsRunning > 0 || c.MaxReplicationLag > 0 || c.MaxCPUUtilization > 0
+}
+
+// Wait waits until the load of the database gets under the thresholds.
+// It returns an *UnhealthyError if the database does not get healthy within the timeout.
+// The dialect must implement dialect.HealthChecker.
+func (c *HealthCheck) Wait(d dialect.Dialect) error {
+	if !c.IsEnabled() {
+		return nil
+	}
+	checker, ok := d.(dialect.HealthChecker)
+	if !ok {
+		return newError(ErrUnsupportedFeature, "migu: health check is not supported by the dialect")
+	}
+	interval := c.Interval
+	if interval <= 0 {
+		interval = defaultHealthCheckInterval
+	}
+	var waited time.Duration
+	for {
+		health, err := checker.Health()
+		if err != nil {
+			return err
+		}
+		reason := c.unhealthyReason(health)
+		if reason == "" {
+			return nil
+		}
+		if c.OnUnhealthy != nil {
+			c.OnUnhealthy(reason)
+		}
+		if waited >= c.Timeout {
+			return &UnhealthyError{Reason: reason}
+		}
+		time.Sleep(interval)
+		waited += interval
+	}
+}
+
+// unhealthyReason returns the reason why the health exceeds the thresholds, or an empty string if it does not.
+// The unknown values are not checked.
+func (c *HealthCheck) unhealthyReason(h dialect.Health) string {
+	switch {
+	case c.MaxThreadsRunning > 0 && h.ThreadsRunning > c.MaxThreadsRunning:
+		return fmt.Sprintf("threads running %d exceeds %d", h.ThreadsRunning, c.MaxThreadsRunning)
+	case c.MaxReplicationLag > 0 && h.ReplicationLag > c.MaxReplicationLag:
+		return fmt.Sprintf("replication lag %v exceeds %v", h.ReplicationLag, c.MaxReplicationLag)
+	case c.MaxCPUUtilization > 0 && h.CPUUtilization > c.MaxCPUUtilization:
+		return fmt.Sprintf("CPU utilization %.2f exceeds %.2f", h.CPUUtilization, c.MaxCPUUtilization)
+	}
+	return ""
+}
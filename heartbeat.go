@@ -0,0 +1,77 @@
+package migu
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/naoina/migu/dialect"
+)
+
+// Heartbeat watches the database on another connection while a statement is being applied, so that a long DDL
+// statement such as ALTER TABLE of a large table is not a silent hang.
+type Heartbeat struct {
+	// Interval is the interval of the heartbeats. If it is 0, Start does nothing.
+	Interval time.Duration
+
+	// OnProgress is called with the progress of the statement at each heartbeat if the dialect reports it, if it is
+	// not nil.
+	OnProgress func(p dialect.Progress)
+
+	// OnFailure is called with the error when the heartbeat fails or the database does not respond within the
+	// interval, if it is not nil.
+	OnFailure func(err error)
+}
+
+// Start starts the heartbeats for the statement sql in the background, and returns the function to stop them.
+// The dialect must implement dialect.ProgressReporter, otherwise Start does nothing.
+func (h *Heartbeat) Start(d dialect.Dialect, sql string) (stop func()) {
+	reporter, ok := d.(dialect.ProgressReporter)
+	if h.Interval <= 0 || !ok {
+		return func() {}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(h.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			h.beat(ctx, reporter, sql)
+		}
+	}()
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
+func (h *Heartbeat) beat(ctx context.Context, reporter dialect.ProgressReporter, sql string) {
+	ctx, cancel := context.WithTimeout(ctx, h.Interval)
+	defer cancel()
+	progresses, err := reporter.Progress(ctx)
+	if err != nil {
+		// The error that is caused by stop is not a failure.
+		if ctx.Err() != context.Canceled && h.OnFailure != nil {
+			h.OnFailure(fmt.Errorf("migu: heartbeat failed: %w", err))
+		}
+		return
+	}
+	if h.OnProgress == nil {
+		return
+	}
+	for _, p := range progresses {
+		// The statement may be truncated by the server.
+		if p.Statement != "" && strings.HasPrefix(sql, p.Statement) {
+			h.OnProgress(p)
+		}
+	}
+}
@@ -0,0 +1,345 @@
+package migu
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/naoina/migu/dialect"
+)
+
+// MigrationsTable is the name of the table that records the versions of the applied migrations.
+const MigrationsTable = "migu_migrations"
+
+// isInternalTable reports whether the table is managed by Migu itself instead of Go's structs, such as the migrations
+// table and the freeze table.
+func isInternalTable(name string) bool {
+	return name == MigrationsTable || name == FreezeTable
+}
+
+// Migration is the versioned migration that is read from the migration files such as the ones written by
+// `migu generate`.
+type Migration struct {
+	Version string
+	Name    string
+
+	// Up and Down are the statements to apply and to revert the migration.
+	Up   []string
+	Down []string
+}
+
+// AppliedMigration is the record of the migration in the migrations table.
+type AppliedMigration struct {
+	Version   string
+	Name      string
+	AppliedAt time.Time
+}
+
+var (
+	migrationFileRegexp = regexp.MustCompile(`^(\d+)_(.*?)(\.up|\.down)?\.sql$`)
+
+	// flywayFileRegexp matches the versioned migrations and the undo migrations of Flyway such as V1.2__add_user.sql
+	// and U1.2__add_user.sql.
+	flywayFileRegexp = regexp.MustCompile(`^([VU])(\d+(?:[._]\d+)*)__(.*)\.sql$`)
+
+	// liquibaseFileRegexp matches the changelogs of Liquibase that are written by `migu generate`.
+	liquibaseFileRegexp = regexp.MustCompile(`^(\d+)_(.*?)\.(xml|yaml|yml)$`)
+)
+
+// ReadMigrations reads the migration files in the directory, and returns the migrations in order of the versions.
+// The files are VERSION_NAME.up.sql and VERSION_NAME.down.sql of golang-migrate, VERSION_NAME.sql that has the
+// Up and Down sections of sql-migrate or goose, VVERSION__NAME.sql and UVERSION__NAME.sql of Flyway, or
+// VERSION_NAME.xml and VERSION_NAME.yaml of Liquibase changelogs.
+func ReadMigrations(dir string) ([]*Migration, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	migrationMap := map[string]*Migration{}
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		var version, name string
+		var read func(b []byte) (up, down []string, err error)
+		filename := filepath.Join(dir, f.Name())
+		if m := migrationFileRegexp.FindStringSubmatch(f.Name()); m != nil {
+			version, name = m[1], m[2]
+			read = func(b []byte) (up, down []string, err error) {
+				switch m[3] {
+				case ".up":
+					up, err = splitSQLScript(string(b))
+				case ".down":
+					down, err = splitSQLScript(string(b))
+				default:
+					up, down, err = splitAnnotatedMigration(string(b))
+				}
+				return up, down, err
+			}
+		} else if m := flywayFileRegexp.FindStringSubmatch(f.Name()); m != nil {
+			version, name = strings.Replace(m[2], "_", ".", -1), m[3]
+			read = func(b []byte) (up, down []string, err error) {
+				if m[1] == "U" {
+					down, err = splitSQLScript(string(b))
+				} else {
+					up, err = splitSQLScript(string(b))
+				}
+				return up, down, err
+			}
+		} else if m := liquibaseFileRegexp.FindStringSubmatch(f.Name()); m != nil {
+			version, name = m[1], m[2]
+			read = func(b []byte) (up, down []string, err error) {
+				return readLiquibaseChangelog(filename, b, m[3] != "xml")
+			}
+		} else {
+			continue
+		}
+		b, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		migration := migrationMap[version]
+		if migration == nil {
+			migration = &Migration{Version: version, Name: name}
+			migrationMap[version] = migration
+		} else if migration.Name != name {
+			return nil, newError(ErrInvalidSource, "migu: %s: version %s is duplicated", filename, version)
+		}
+		up, down, err := read(b)
+		if err != nil {
+			return nil, newError(ErrInvalidSource, "migu: %s: %w", filename, err)
+		}
+		migration.Up = append(migration.Up, up...)
+		migration.Down = append(migration.Down, down...)
+	}
+	migrations := make([]*Migration, 0, len(migrationMap))
+	for _, m := range migrationMap {
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return lessVersion(migrations[i].Version, migrations[j].Version)
+	})
+	return migrations, nil
+}
+
+// migrationAnnotationRegexp matches the annotations of sql-migrate such as "-- +migrate Up" and goose such as
+// "-- +goose StatementBegin".
+var migrationAnnotationRegexp = regexp.MustCompile(`^--\s*\+(?:migrate|goose)\s+(Up|Down|StatementBegin|StatementEnd)\b`)
+
+// splitAnnotatedMigration splits the migration file of sql-migrate or goose into the statements of the Up and Down
+// sections. The statements between StatementBegin and StatementEnd are not split by semicolons.
+func splitAnnotatedMigration(s string) (up, down []string, err error) {
+	var section *[]string
+	var buf strings.Builder
+	inStatement := false
+	flush := func() error {
+		defer buf.Reset()
+		if section == nil {
+			return nil
+		}
+		if inStatement {
+			if stmt := strings.TrimRight(strings.TrimSpace(buf.String()), ";"); stmt != "" {
+				*section = append(*section, stmt)
+			}
+			return nil
+		}
+		stmts, err := splitSQLScript(buf.String())
+		*section = append(*section, stmts...)
+		return err
+	}
+	for _, line := range strings.SplitAfter(s, "\n") {
+		m := migrationAnnotationRegexp.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			buf.WriteString(line)
+			continue
+		}
+		if err := flush(); err != nil {
+			return nil, nil, err
+		}
+		switch m[1] {
+		case "Up":
+			section = &up
+		case "Down":
+			section = &down
+		case "StatementBegin":
+			inStatement = true
+		case "StatementEnd":
+			inStatement = false
+		}
+	}
+	if inStatement {
+		return nil, nil, fmt.Errorf("StatementBegin is not terminated by StatementEnd")
+	}
+	if err := flush(); err != nil {
+		return nil, nil, err
+	}
+	return up, down, nil
+}
+
+// lessVersion reports whether the version a is older than b. The versions are compared as the numbers, and the
+// dotted versions of Flyway such as 1.10 are compared part by part.
+func lessVersion(a, b string) bool {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		x, xerr := strconv.ParseUint(as[i], 10, 64)
+		y, yerr := strconv.ParseUint(bs[i], 10, 64)
+		if xerr != nil || yerr != nil {
+			if as[i] != bs[i] {
+				return as[i] < bs[i]
+			}
+			continue
+		}
+		if x != y {
+			return x < y
+		}
+	}
+	return len(as) < len(bs)
+}
+
+// AppliedMigrations returns the migrations that are recorded in the migrations table in order of the versions.
+// It returns nil if the migrations table does not exist.
+// The dialect must implement dialect.RowReader.
+func AppliedMigrations(d dialect.Dialect) ([]*AppliedMigration, error) {
+	applied, _, err := appliedMigrations(d)
+	return applied, err
+}
+
+// appliedMigrations is like AppliedMigrations, but also reports whether the migrations table exists.
+func appliedMigrations(d dialect.Dialect) (applied []*AppliedMigration, exists bool, err error) {
+	reader, ok := d.(dialect.RowReader)
+	if !ok {
+		return nil, false, newError(ErrUnsupportedFeature, "migu: the migration history is not supported by the dialect")
+	}
+	schemas, err := d.ColumnSchema(MigrationsTable)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(schemas) == 0 {
+		return nil, false, nil
+	}
+	if err := reader.ReadRows(MigrationsTable, []string{"version", "name", "applied_at"}, func(values []*string) error {
+		m := &AppliedMigration{}
+		if values[0] != nil {
+			m.Version = *values[0]
+		}
+		if values[1] != nil {
+			m.Name = *values[1]
+		}
+		if values[2] != nil {
+			t, err := time.Parse(time.RFC3339, *values[2])
+			if err != nil {
+				return fmt.Errorf("migu: invalid applied_at of version %s: %w", m.Version, err)
+			}
+			m.AppliedAt = t
+		}
+		applied = append(applied, m)
+		return nil
+	}); err != nil {
+		return nil, false, err
+	}
+	sort.Slice(applied, func(i, j int) bool {
+		return lessVersion(applied[i].Version, applied[j].Version)
+	})
+	return applied, true, nil
+}
+
+// MigrateChanges returns the changes to apply the migrations that have not been applied yet in order of the
+// versions, and to record them in the migrations table. The change to create the migrations table comes first if it
+// does not exist.
+// It returns an error if the migration that is older than the latest applied one has not been applied, because the
+// migrations must be applied in order.
+// The dialect must implement dialect.RowReader.
+func MigrateChanges(d dialect.Dialect, migrations []*Migration, opts ...Option) ([]*Change, error) {
+	applied, exists, err := appliedMigrations(d)
+	if err != nil {
+		return nil, err
+	}
+	var changes []*Change
+	appliedMap := make(map[string]struct{}, len(applied))
+	for _, m := range applied {
+		appliedMap[m.Version] = struct{}{}
+	}
+	opt := newOption(opts)
+	now := opt.now().UTC().Format(time.RFC3339)
+	for _, m := range migrations {
+		if _, ok := appliedMap[m.Version]; ok {
+			continue
+		}
+		if len(applied) > 0 && lessVersion(m.Version, applied[len(applied)-1].Version) {
+			return nil, newError(ErrRefused, "migu: version %s is older than the applied version %s", m.Version, applied[len(applied)-1].Version)
+		}
+		sqls := append([]string{}, m.Up...)
+		sqls = append(sqls, fmt.Sprintf("INSERT INTO %s (%s, %s, %s) VALUES (%s, %s, %s)",
+			d.Quote(MigrationsTable), d.Quote("version"), d.Quote("name"), d.Quote("applied_at"),
+			d.QuoteString(m.Version), d.QuoteString(m.Name), d.QuoteString(now)))
+		changes = append(changes, &Change{
+			Kind:      ApplyMigration,
+			Table:     MigrationsTable,
+			Migration: m.Version + "_" + m.Name,
+			SQLs:      sqls,
+		})
+	}
+	if len(changes) == 0 || exists {
+		return changes, nil
+	}
+	return append([]*Change{{
+		Kind:  CreateTable,
+		Table: MigrationsTable,
+		SQLs:  d.CreateTableSQL(migrationsTable(d)),
+	}}, changes...), nil
+}
+
+// RollbackChanges returns the changes to revert the last n applied migrations in the reverse order of the versions,
+// and to delete them from the migrations table.
+// It returns an error if the migration of the applied version is not found in migrations.
+// The dialect must implement dialect.RowReader.
+func RollbackChanges(d dialect.Dialect, migrations []*Migration, n int) ([]*Change, error) {
+	applied, err := AppliedMigrations(d)
+	if err != nil {
+		return nil, err
+	}
+	migrationMap := make(map[string]*Migration, len(migrations))
+	for _, m := range migrations {
+		migrationMap[m.Version] = m
+	}
+	var changes []*Change
+	for i := len(applied) - 1; i >= 0 && len(changes) < n; i-- {
+		m, ok := migrationMap[applied[i].Version]
+		if !ok {
+			return nil, fmt.Errorf("migu: the migration of the applied version %s is not found", applied[i].Version)
+		}
+		sqls := append([]string{}, m.Down...)
+		sqls = append(sqls, fmt.Sprintf("DELETE FROM %s WHERE %s = %s",
+			d.Quote(MigrationsTable), d.Quote("version"), d.QuoteString(m.Version)))
+		changes = append(changes, &Change{
+			Kind:      RollbackMigration,
+			Table:     MigrationsTable,
+			Migration: m.Version + "_" + m.Name,
+			SQLs:      sqls,
+		})
+	}
+	return changes, nil
+}
+
+// migrationsTable returns the definition of the migrations table. applied_at is in RFC 3339 so that it can be
+// stored in the same way by any database.
+func migrationsTable(d dialect.Dialect) dialect.Table {
+	fields := make([]dialect.Field, 0, 3)
+	for _, name := range []string{"version", "name", "applied_at"} {
+		fields = append(fields, dialect.Field{
+			Table: MigrationsTable,
+			Name:  name,
+			Type:  d.ColumnType("string"),
+		})
+	}
+	return dialect.Table{
+		Name:        MigrationsTable,
+		Fields:      fields,
+		PrimaryKeys: []string{"version"},
+	}
+}
@@ -0,0 +1,224 @@
+package migu
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+
+	"github.com/naoina/migu/dialect"
+)
+
+// builtinImports are the import paths of the packages of the Go types of the built-in column types by the names of
+// the packages.
+var builtinImports = map[string]string{
+	"big":     "math/big",
+	"civil":   "cloud.google.com/go/civil",
+	"json":    "encoding/json",
+	"mysql":   "github.com/go-sql-driver/mysql",
+	"pq":      "github.com/lib/pq",
+	"spanner": "cloud.google.com/go/spanner",
+	"sql":     "database/sql",
+	"time":    "time",
+}
+
+// goImports collects the imports of the packages of the Go types that are used by the generated Go code.
+type goImports struct {
+	d dialect.Dialect
+
+	// custom are the imports of the custom column types by the names that the Go types are qualified by.
+	custom map[string]dialect.Import
+
+	// used are the imports that are used by the generated Go code by the import paths.
+	used map[string]dialect.Import
+
+	fset *token.FileSet
+
+	// source is the importer of the packages from the source, which is shared by all the tables to import each
+	// package only once.
+	source types.ImporterFrom
+}
+
+func newGoImports(d dialect.Dialect) (*goImports, error) {
+	fset := token.NewFileSet()
+	g := &goImports{
+		d:      d,
+		custom: map[string]dialect.Import{},
+		used:   map[string]dialect.Import{},
+		fset:   fset,
+		source: importer.ForCompiler(fset, "source", nil).(types.ImporterFrom),
+	}
+	if importer, ok := d.(dialect.GoTypeImporter); ok {
+		imports, err := importer.GoTypeImports()
+		if err != nil {
+			return nil, newError(ErrUnsupportedType, "migu: %w", err)
+		}
+		for _, imp := range imports {
+			g.custom[imp.PackageName()] = imp
+		}
+	}
+	return g, nil
+}
+
+// structCode returns the Go code of the struct of the table. The packages of the Go types of the code are added to the
+// imports, and the code is type-checked with them so that the generated file compiles without fixing the imports.
+func (g *goImports) structCode(name string, schemas []dialect.ColumnSchema) ([]byte, error) {
+	decls, err := makeStructAST(g.d, name, schemas)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	fmt.Fprintln(&buf, commentPrefix+marker)
+	for _, decl := range decls {
+		if err := fprintln(&buf, decl); err != nil {
+			return nil, err
+		}
+	}
+	if err := g.check(name, buf.Bytes(), schemas); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// check resolves the packages that are referred by the code into the imports, and type-checks the code with them.
+func (g *goImports) check(table string, code []byte, schemas []dialect.ColumnSchema) error {
+	file, err := parser.ParseFile(g.fset, table+".go", append([]byte("package model\n\n"), code...), 0)
+	if err != nil {
+		return newError(ErrUnsupportedType, "migu: %s: invalid Go code is generated: %v", table, err)
+	}
+	// The names of the types that are referred by the names of the packages.
+	refs := map[string]map[string]bool{}
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if x, ok := sel.X.(*ast.Ident); ok {
+				if refs[x.Name] == nil {
+					refs[x.Name] = map[string]bool{}
+				}
+				refs[x.Name][sel.Sel.Name] = true
+			}
+		}
+		return true
+	})
+	names := make([]string, 0, len(refs))
+	for name := range refs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	imports := make([]dialect.Import, 0, len(names))
+	pkgs := fakePackages{}
+	decl := &ast.GenDecl{Tok: token.IMPORT}
+	for _, name := range names {
+		imp, ok := g.lookup(name, schemas)
+		if !ok {
+			return newError(ErrUnsupportedType, "migu: %s: unknown package %s of the Go type; add its import path to `imports` of the column type", table, name)
+		}
+		for path, used := range g.used {
+			if path != imp.Path && used.PackageName() == name {
+				return newError(ErrUnsupportedType, "migu: %s: package name %s is used by both %s and %s", table, name, path, imp.Path)
+			}
+		}
+		imports = append(imports, imp)
+		pkgs.add(imp.Path, refs[name])
+		decl.Specs = append(decl.Specs, importSpec(imp))
+	}
+	file.Decls = append([]ast.Decl{decl}, file.Decls...)
+	conf := types.Config{Importer: &packages{source: g.source, fakes: pkgs}}
+	if _, err := conf.Check("model", g.fset, []*ast.File{file}, nil); err != nil {
+		return newError(ErrUnsupportedType, "migu: %s: generated Go code does not compile: %v", table, err)
+	}
+	for _, imp := range imports {
+		g.used[imp.Path] = imp
+	}
+	return nil
+}
+
+// lookup returns the import of the package of the name in the order of the custom column types, the packages of the
+// column schemas that are given by the dialect, and the packages of the built-in column types.
+func (g *goImports) lookup(name string, schemas []dialect.ColumnSchema) (dialect.Import, bool) {
+	if imp, ok := g.custom[name]; ok {
+		return imp, true
+	}
+	for _, schema := range schemas {
+		if pkg := g.d.ImportPackage(schema); pkg != "" && dialect.ImportName(pkg) == name {
+			return dialect.Import{Path: pkg}, true
+		}
+	}
+	if path, ok := builtinImports[name]; ok {
+		return dialect.Import{Path: path}, true
+	}
+	return dialect.Import{}, false
+}
+
+// decl returns the import declaration of the used packages, or nil if no package is used.
+func (g *goImports) decl() ast.Decl {
+	if len(g.used) == 0 {
+		return nil
+	}
+	paths := make([]string, 0, len(g.used))
+	for path := range g.used {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	decl := &ast.GenDecl{Tok: token.IMPORT}
+	for _, path := range paths {
+		decl.Specs = append(decl.Specs, importSpec(g.used[path]))
+	}
+	return decl
+}
+
+func importSpec(imp dialect.Import) *ast.ImportSpec {
+	spec := &ast.ImportSpec{
+		Path: &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(imp.Path)},
+	}
+	if imp.Name != "" {
+		spec.Name = ast.NewIdent(imp.Name)
+	}
+	return spec
+}
+
+// packages is the importer of the type checker that imports the packages from the source in the module of the working
+// directory, so that the Go types that don't exist in the packages are detected. The packages that cannot be
+// imported, such as the ones of the custom Go types that are not required by the module, are replaced with the fake
+// packages.
+type packages struct {
+	source types.ImporterFrom
+	fakes  fakePackages
+}
+
+func (p *packages) Import(path string) (*types.Package, error) {
+	return p.ImportFrom(path, "", 0)
+}
+
+func (p *packages) ImportFrom(path, dir string, mode types.ImportMode) (*types.Package, error) {
+	if pkg, err := p.source.ImportFrom(path, dir, mode); err == nil {
+		return pkg, nil
+	}
+	return p.fakes.Import(path)
+}
+
+// fakePackages is the importer of the type checker that returns the packages that only have the types that are
+// referred by the generated code, since the packages of the custom Go types may not be available.
+type fakePackages map[string]*types.Package
+
+func (p fakePackages) add(path string, typeNames map[string]bool) {
+	pkg := types.NewPackage(path, dialect.ImportName(path))
+	for name := range typeNames {
+		tn := types.NewTypeName(token.NoPos, pkg, name, nil)
+		types.NewNamed(tn, types.NewStruct(nil, nil), nil)
+		pkg.Scope().Insert(tn)
+	}
+	pkg.MarkComplete()
+	p[path] = pkg
+}
+
+func (p fakePackages) Import(path string) (*types.Package, error) {
+	if pkg, ok := p[path]; ok {
+		return pkg, nil
+	}
+	return nil, fmt.Errorf("package %s is not imported", path)
+}
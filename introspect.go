@@ -0,0 +1,203 @@
+package migu
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/naoina/migu/dialect"
+)
+
+// PartialResultError is returned by FprintContext with WithPartialResults if the introspection stopped before all
+// the tables had been read. The structs of the tables that had been read are written in spite of the error.
+type PartialResultError struct {
+	// Tables is the number of the tables that had been written.
+	Tables int
+
+	// Err is the reason why the introspection stopped such as context.DeadlineExceeded.
+	Err error
+}
+
+func (e *PartialResultError) Error() string {
+	return fmt.Sprintf("migu: introspection stopped after %d tables: %v", e.Tables, e.Err)
+}
+
+func (e *PartialResultError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCode returns the code of PartialResultError.
+func (e *PartialResultError) ErrorCode() Code {
+	return "E113"
+}
+
+// WithPartialResults writes the structs of the tables that have been read by FprintContext even if ctx is done
+// before all the tables are read. FprintContext returns a *PartialResultError in that case.
+func WithPartialResults() Option {
+	return func(o *option) {
+		o.partialResults = true
+	}
+}
+
+// ColumnExclusion is a column that is excluded from the output of FprintContext and FprintSQL.
+type ColumnExclusion struct {
+	Table  string
+	Column string
+
+	// Reason is the reason of the exclusion such as "generated".
+	Reason string
+}
+
+// WithExcludeGeneratedColumns excludes the generated columns from the output of FprintContext and FprintSQL.
+// The column schemas of the dialect must implement dialect.GeneratedColumnSchema to be excluded.
+func WithExcludeGeneratedColumns() Option {
+	return func(o *option) {
+		o.excludeGenerated = true
+	}
+}
+
+// WithExcludeColumns excludes the columns whose names match the pattern from the output of FprintContext and
+// FprintSQL.
+func WithExcludeColumns(pattern *regexp.Regexp) Option {
+	return func(o *option) {
+		o.excludeColumns = pattern
+	}
+}
+
+// WithExclusionReport calls fn with each column that is excluded by WithExcludeGeneratedColumns or
+// WithExcludeColumns. The table that has no column after the exclusions is not output.
+func WithExclusionReport(fn func(e ColumnExclusion)) Option {
+	return func(o *option) {
+		o.onExclude = fn
+	}
+}
+
+// exclude returns the columns except the ones that are excluded by the options.
+func (o *option) exclude(columns []dialect.ColumnSchema) []dialect.ColumnSchema {
+	if !o.excludeGenerated && o.excludeColumns == nil {
+		return columns
+	}
+	result := make([]dialect.ColumnSchema, 0, len(columns))
+	for _, schema := range columns {
+		var reason string
+		if s, ok := schema.(dialect.GeneratedColumnSchema); ok && o.excludeGenerated && s.IsGenerated() {
+			reason = "generated"
+		} else if o.excludeColumns != nil && o.excludeColumns.MatchString(schema.ColumnName()) {
+			reason = fmt.Sprintf("matches %s", o.excludeColumns)
+		}
+		if reason == "" {
+			result = append(result, schema)
+			continue
+		}
+		if o.onExclude != nil {
+			o.onExclude(ColumnExclusion{
+				Table:  schema.TableName(),
+				Column: schema.ColumnName(),
+				Reason: reason,
+			})
+		}
+	}
+	return result
+}
+
+// excludeTableMap is the same as exclude, but for the columns of all tables in tableMap.
+func (o *option) excludeTableMap(tableMap map[string][]dialect.ColumnSchema) map[string][]dialect.ColumnSchema {
+	names := make([]string, 0, len(tableMap))
+	for name := range tableMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	result := make(map[string][]dialect.ColumnSchema, len(tableMap))
+	for _, name := range names {
+		if columns := o.exclude(tableMap[name]); len(columns) > 0 {
+			result[name] = columns
+		}
+	}
+	return result
+}
+
+// FprintContext is the same as Fprint except that the introspection stops when ctx is done.
+// If d implements dialect.ColumnSchemaStreamer, the tables are converted to Go's structs as they are read from the
+// database, so that the column schemas of all the tables are not loaded into memory at once.
+// Without WithPartialResults, nothing is written to output if ctx is done before all the tables are read.
+func FprintContext(ctx context.Context, output io.Writer, d dialect.Dialect, opts ...Option) error {
+	opt := newOption(opts)
+	s, ok := d.(dialect.ColumnSchemaStreamer)
+	if !ok {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		tableMap, err := getTableMap(d)
+		if err != nil {
+			return err
+		}
+		for name := range tableMap {
+			if isInternalTable(name) {
+				delete(tableMap, name)
+			}
+		}
+		return fprintTableMap(output, d, opt.excludeTableMap(opt.limitTableMap(opt.unprefixTableMap(tableMap))))
+	}
+	// The structs are written to the temporary file because the import declaration that depends on all the tables
+	// must be written first.
+	tmp, err := ioutil.TempFile("", "migu-dump-")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}()
+	imports, err := newGoImports(d)
+	if err != nil {
+		return err
+	}
+	var n int
+	streamErr := s.StreamColumnSchema(ctx, func(columns []dialect.ColumnSchema) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		name := columns[0].TableName()
+		if isInternalTable(name) || !strings.HasPrefix(name, opt.tablePrefix) {
+			return nil
+		}
+		if opt.tables != nil && !opt.tables[strings.TrimPrefix(name, opt.tablePrefix)] {
+			return nil
+		}
+		if columns = opt.exclude(columns); len(columns) == 0 {
+			return nil
+		}
+		code, err := imports.structCode(strings.TrimPrefix(name, opt.tablePrefix), columns)
+		if err != nil {
+			return err
+		}
+		if _, err := tmp.Write(code); err != nil {
+			return err
+		}
+		n++
+		return nil
+	})
+	if streamErr != nil && (!opt.partialResults || ctx.Err() == nil) {
+		return streamErr
+	}
+	if decl := imports.decl(); decl != nil {
+		if err := fprintln(output, decl); err != nil {
+			return err
+		}
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := io.Copy(output, tmp); err != nil {
+		return err
+	}
+	if streamErr != nil {
+		return &PartialResultError{Tables: n, Err: streamErr}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package migu
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/naoina/migu/dialect"
+)
+
+// InventoryHeader is the header row of the inventory written by WriteInventory.
+var InventoryHeader = []string{"table", "column", "type", "nullable", "default", "indexes"}
+
+// InventoryEntry represents a column in the schema inventory.
+type InventoryEntry struct {
+	Table    string
+	Column   string
+	Type     string
+	Nullable bool
+	Default  string
+	Indexes  []string
+}
+
+// Inventory returns the schema inventory from Go's structs.
+// The filename and src parameters are treated in the same way as Diff.
+func Inventory(d dialect.Dialect, filename string, src interface{}) ([]InventoryEntry, error) {
+	structMap, err := structTables(d, filename, src)
+	if err != nil {
+		return nil, err
+	}
+	return makeInventory(structMap), nil
+}
+
+// DatabaseInventory returns the schema inventory from the database.
+func DatabaseInventory(d dialect.Dialect) ([]InventoryEntry, error) {
+	tableMap, err := databaseTables(d)
+	if err != nil {
+		return nil, err
+	}
+	return makeInventory(tableMap), nil
+}
+
+// WriteInventory writes the inventory entries to output as CSV with the header.
+// If comma is not 0, it is used as the field delimiter. (e.g. '\t' for TSV)
+func WriteInventory(output io.Writer, entries []InventoryEntry, comma rune) error {
+	w := csv.NewWriter(output)
+	if comma != 0 {
+		w.Comma = comma
+	}
+	if err := w.Write(InventoryHeader); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := w.Write([]string{
+			e.Table,
+			e.Column,
+			e.Type,
+			strconv.FormatBool(e.Nullable),
+			e.Default,
+			strings.Join(e.Indexes, " "),
+		}); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func makeInventory(tableMap map[string]*table) []InventoryEntry {
+	names := make([]string, 0, len(tableMap))
+	for name := range tableMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var entries []InventoryEntry
+	for _, name := range names {
+		for _, f := range tableMap[name].Fields {
+			var indexes []string
+			if f.PrimaryKey {
+				indexes = append(indexes, "PRIMARY")
+			}
+			indexes = append(indexes, f.Indexes()...)
+			indexes = append(indexes, f.UniqueIndexes()...)
+			entries = append(entries, InventoryEntry{
+				Table:    name,
+				Column:   f.Column,
+				Type:     f.Type,
+				Nullable: f.Nullable,
+				Default:  f.Default,
+				Indexes:  indexes,
+			})
+		}
+	}
+	return entries
+}
@@ -0,0 +1,130 @@
+package migu
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/naoina/migu/dialect"
+)
+
+// checkSchemaLimits returns an error if the names or the numbers of the objects of the table exceed the limits of the
+// dialect, with all the problems of the table, so that it fails before the server rejects the DDL without the
+// context or truncates the identifiers silently.
+func checkSchemaLimits(d dialect.Dialect, name string, tbl *table) error {
+	l, ok := d.(dialect.SchemaLimiter)
+	if !ok {
+		return nil
+	}
+	limit := l.SchemaLimits(tbl.ToTable(name))
+	indexes, _ := makeIndexes(nil, tbl.Fields)
+	var problems []string
+	identifier := func(kind, ident string) {
+		if n := identifierLength(ident, limit); limit.MaxIdentifierLength > 0 && n > limit.MaxIdentifierLength {
+			unit := "characters"
+			if limit.IdentifierLengthInBytes {
+				unit = "bytes"
+			}
+			problems = append(problems, fmt.Sprintf("%s name %s is %d %s long, exceeding the maximum %d", kind, ident, n, unit, limit.MaxIdentifierLength))
+		}
+	}
+	identifier("table", name)
+	if n := len(tbl.Fields); limit.MaxColumns > 0 && n > limit.MaxColumns {
+		problems = append(problems, fmt.Sprintf("%d columns exceed the maximum %d", n, limit.MaxColumns))
+	}
+	for _, f := range tbl.Fields {
+		identifier("column", f.Column)
+		var kind string
+		var max int
+		switch typeBase(f.Type) {
+		case "ENUM":
+			kind, max = "ENUM", limit.MaxEnumElements
+		case "SET":
+			kind, max = "SET", limit.MaxSetElements
+		default:
+			continue
+		}
+		if n := len(enumElements(f.Type)); max > 0 && n > max {
+			problems = append(problems, fmt.Sprintf("column %s has %d elements of %s, exceeding the maximum %d", f.Column, n, kind, max))
+		}
+	}
+	if n := len(indexes); limit.MaxIndexes > 0 && n > limit.MaxIndexes {
+		problems = append(problems, fmt.Sprintf("%d indexes exceed the maximum %d", n, limit.MaxIndexes))
+	}
+	for _, index := range indexes {
+		identifier("index", index.Name)
+		if n := len(index.Columns); limit.MaxIndexColumns > 0 && n > limit.MaxIndexColumns {
+			problems = append(problems, fmt.Sprintf("index %s has %d columns, exceeding the maximum %d", index.Name, n, limit.MaxIndexColumns))
+		}
+	}
+	for _, fk := range tbl.ForeignKeys {
+		identifier("foreign key", fk.Name)
+	}
+	for _, c := range tbl.Checks {
+		identifier("check constraint", c.Name)
+	}
+	if len(problems) > 0 {
+		return newError(ErrLimitExceeded, "migu: %s exceeds the limits of the database:\n  %s", name, strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// identifierLength returns the length of the identifier in the unit of the limit.
+func identifierLength(ident string, limit dialect.SchemaLimits) int {
+	if limit.IdentifierLengthInBytes {
+		return len(ident)
+	}
+	return utf8.RuneCountInString(ident)
+}
+
+// enumElements returns the elements of ENUM or SET type such as ENUM('a','b').
+func enumElements(typ string) []string {
+	start, end := strings.IndexByte(typ, '('), strings.LastIndexByte(typ, ')')
+	if start < 0 || end < start {
+		return nil
+	}
+	params := typ[start+1 : end]
+	var elements []string
+	for i := 0; i < len(params); {
+		switch c := params[i]; c {
+		case ' ', '\t', ',':
+			i++
+		case '\'', '"':
+			s, n, err := unquoteSQL(params[i:])
+			if err != nil {
+				return append(elements, params[i:])
+			}
+			elements = append(elements, s)
+			i += n
+		default:
+			j := strings.IndexByte(params[i:], ',')
+			if j < 0 {
+				j = len(params) - i
+			}
+			elements = append(elements, strings.TrimSpace(params[i:i+j]))
+			i += j
+		}
+	}
+	return elements
+}
+
+// generatedIdentifierLength is the maximum length of the identifiers that are generated by Migu, which is the
+// maximum of MySQL.
+const generatedIdentifierLength = 64
+
+// truncateIdentifier returns name as is if it's not longer than max bytes, or the prefix of name followed by the hash
+// of name so that the different names are not truncated into the same one.
+func truncateIdentifier(name string, max int) string {
+	if len(name) <= max {
+		return name
+	}
+	sum := sha256.Sum256([]byte(name))
+	suffix := "_" + hex.EncodeToString(sum[:4])
+	prefix := name[:max-len(suffix)]
+	for !utf8.ValidString(prefix) {
+		prefix = prefix[:len(prefix)-1]
+	}
+	return prefix + suffix
+}
@@ -0,0 +1,144 @@
+package migu
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/naoina/migu/dialect"
+)
+
+const primaryKeyIndexName = "PRIMARY"
+
+// RedundantIndex represents an index that is covered by another index.
+type RedundantIndex struct {
+	Index     dialect.Index
+	CoveredBy dialect.Index
+}
+
+func (r *RedundantIndex) String() string {
+	return fmt.Sprintf("%s: index %s (%s) is redundant with %s (%s)",
+		r.Index.Table, r.Index.Name, strings.Join(r.Index.Columns, ", "),
+		r.CoveredBy.Name, strings.Join(r.CoveredBy.Columns, ", "))
+}
+
+// LintIndexes returns the redundant indexes declared by Go's structs.
+// The filename and src parameters are treated in the same way as Diff.
+func LintIndexes(d dialect.Dialect, filename string, src interface{}) ([]*RedundantIndex, error) {
+	structMap, err := structTables(d, filename, src)
+	if err != nil {
+		return nil, err
+	}
+	var indexes []dialect.Index
+	for name, tbl := range structMap {
+		indexes = append(indexes, tbl.allIndexes(name)...)
+	}
+	return redundantIndexes(indexes), nil
+}
+
+// LintDatabaseIndexes returns the redundant indexes in the database.
+func LintDatabaseIndexes(d dialect.Dialect) ([]*RedundantIndex, error) {
+	var indexes []dialect.Index
+	if r, ok := d.(dialect.IndexReader); ok {
+		var err error
+		if indexes, err = r.Indexes(); err != nil {
+			return nil, err
+		}
+	} else {
+		tableMap, err := databaseTables(d)
+		if err != nil {
+			return nil, err
+		}
+		for name, tbl := range tableMap {
+			indexes = append(indexes, tbl.allIndexes(name)...)
+		}
+	}
+	return redundantIndexes(indexes), nil
+}
+
+// allIndexes returns all indexes of the table including the primary key.
+func (t *table) allIndexes(name string) []dialect.Index {
+	var indexes []dialect.Index
+	pk := dialect.Index{
+		Table:  name,
+		Name:   primaryKeyIndexName,
+		Unique: true,
+	}
+	for _, f := range t.Fields {
+		if f.PrimaryKey {
+			pk.Columns = append(pk.Columns, f.Column)
+		}
+	}
+	if len(pk.Columns) > 0 {
+		indexes = append(indexes, pk)
+	}
+	addIndexes, _ := makeIndexes(nil, t.Fields)
+	for _, index := range addIndexes {
+		indexes = append(indexes, index.ToIndex())
+	}
+	return indexes
+}
+
+// redundantIndexes returns the indexes that are covered by another index in the same table.
+// An index is redundant if its columns are a prefix of the columns of another index. A unique index is redundant
+// only if another unique index (including the primary key) has exactly the same columns.
+func redundantIndexes(indexes []dialect.Index) []*RedundantIndex {
+	sort.SliceStable(indexes, func(i, j int) bool {
+		if indexes[i].Table != indexes[j].Table {
+			return indexes[i].Table < indexes[j].Table
+		}
+		// The primary key is preferred to be kept.
+		if (indexes[i].Name == primaryKeyIndexName) != (indexes[j].Name == primaryKeyIndexName) {
+			return indexes[i].Name == primaryKeyIndexName
+		}
+		return indexes[i].Name < indexes[j].Name
+	})
+	var result []*RedundantIndex
+	redundant := map[int]struct{}{}
+	for i, index := range indexes {
+		if index.Name == primaryKeyIndexName {
+			continue
+		}
+		for j, other := range indexes {
+			if i == j || index.Table != other.Table {
+				continue
+			}
+			if _, ok := redundant[j]; ok {
+				continue
+			}
+			if !isCoveredIndex(index, other, j < i) {
+				continue
+			}
+			redundant[i] = struct{}{}
+			result = append(result, &RedundantIndex{
+				Index:     index,
+				CoveredBy: other,
+			})
+			break
+		}
+	}
+	return result
+}
+
+// isCoveredIndex reports whether index is covered by other.
+// If both have the same definition, index is regarded as covered only if preferOther is true.
+func isCoveredIndex(index, other dialect.Index, preferOther bool) bool {
+	if len(index.Columns) > len(other.Columns) {
+		return false
+	}
+	for i, c := range index.Columns {
+		if other.Columns[i] != c {
+			return false
+		}
+	}
+	same := len(index.Columns) == len(other.Columns)
+	if index.Unique {
+		if !other.Unique || !same {
+			return false
+		}
+	}
+	if same && index.Unique == other.Unique {
+		return preferOther
+	}
+	return true
+}
@@ -2,6 +2,8 @@ package migu
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"fmt"
 	"go/ast"
 	"go/format"
@@ -14,6 +16,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/naoina/go-stringutil"
 	"github.com/naoina/migu/dialect"
@@ -36,28 +39,358 @@ const (
 // All query for synchronization will be performed within the transaction if
 // storage engine supports the transaction. (e.g. MySQL's MyISAM engine does
 // NOT support the transaction)
-func Sync(d dialect.Dialect, filename string, src interface{}) error {
-	sqls, err := Diff(d, filename, src)
+func Sync(d dialect.Dialect, filename string, src interface{}, opts ...Option) error {
+	return SyncContext(context.Background(), d, filename, src, opts...)
+}
+
+// SyncContext is like Sync, but stops applying the changes and rolls back when the context is done.
+// The progress is sent as the events if WithEvents is specified.
+func SyncContext(ctx context.Context, d dialect.Dialect, filename string, src interface{}, opts ...Option) error {
+	opt := newOption(opts)
+	return opt.redactor.RedactError(syncContext(ctx, d, filename, src, opts, opt))
+}
+
+func syncContext(ctx context.Context, d dialect.Dialect, filename string, src interface{}, opts []Option, opt *option) error {
+	if err := CheckFreeze(d); err != nil {
+		return err
+	}
+	changes, err := DiffChanges(d, filename, src, opts...)
 	if err != nil {
 		return err
 	}
+	if err := opt.sendEvent(ctx, &PlanComputed{Changes: changes}); err != nil {
+		return err
+	}
+	return applyChanges(ctx, d, changes, opt)
+}
+
+// applyChanges applies the changes in a transaction, and sends the events of the statements.
+func applyChanges(ctx context.Context, d dialect.Dialect, changes []*Change, opt *option) error {
 	tx, err := d.Begin()
 	if err != nil {
 		return err
 	}
-	for _, sql := range sqls {
-		if err := tx.Exec(sql); err != nil {
-			tx.Rollback()
-			return err
+	rollback := func(err error) error {
+		if rerr := tx.Rollback(); rerr != nil {
+			opt.sendEvent(ctx, &Warning{Message: fmt.Sprintf("failed to roll back: %v", rerr)})
+		}
+		return err
+	}
+	for _, c := range changes {
+		for _, sql := range c.SQLs {
+			if err := ctx.Err(); err != nil {
+				return rollback(err)
+			}
+			start := time.Now()
+			if err := opt.sendEvent(ctx, &StatementStarted{Change: c, SQL: sql, StartedAt: start}); err != nil {
+				return rollback(err)
+			}
+			err := tx.Exec(sql)
+			if serr := opt.sendEvent(ctx, &StatementFinished{Change: c, SQL: sql, Duration: time.Since(start), Err: err}); err == nil {
+				err = serr
+			}
+			if err != nil {
+				return rollback(err)
+			}
 		}
 	}
 	return tx.Commit()
 }
 
 // Diff returns SQLs for schema synchronous between database and Go's struct.
-func Diff(d dialect.Dialect, filename string, src interface{}) ([]string, error) {
+func Diff(d dialect.Dialect, filename string, src interface{}, opts ...Option) ([]string, error) {
+	changes, err := DiffChanges(d, filename, src, opts...)
+	if err != nil {
+		return nil, err
+	}
+	var migrations []string
+	for _, c := range changes {
+		migrations = append(migrations, c.SQLs...)
+	}
+	return migrations, nil
+}
+
+// DiffChanges is like Diff, but returns the changes with SQLs instead of only SQLs.
+func DiffChanges(d dialect.Dialect, filename string, src interface{}, opts ...Option) ([]*Change, error) {
+	opt := newOption(opts)
+	structMap, err := structTables(d, filename, src)
+	if err != nil {
+		return nil, err
+	}
+	structMap = opt.prefixTables(structMap)
+	selected := opt.taggedTables(structMap)
+	structMap = selectTables(structMap, selected)
+	var names []string
+	if !opt.archiveOrphans {
+		// Only the tables that are defined by Go's structs are compared in order to avoid dropping
+		// the tables that are not managed by Migu.
+		names = make([]string, 0, len(structMap))
+		for name := range structMap {
+			names = append(names, name)
+		}
+	}
+	tableMap, err := databaseTables(d, names...)
+	if err != nil {
+		return nil, err
+	}
+	return diffTables(d, selectTables(opt.scopeTables(tableMap), selected), structMap, opt)
+}
+
+// DiffStructs returns the changes to migrate the schema defined by the old Go's structs to the schema defined by
+// the new Go's structs. The database is not accessed.
+// The filename and src parameters are treated in the same way as Diff.
+func DiffStructs(d dialect.Dialect, oldFilename string, oldSrc interface{}, newFilename string, newSrc interface{}, opts ...Option) ([]*Change, error) {
+	oldMap, err := structTables(d, oldFilename, oldSrc)
+	if err != nil {
+		return nil, err
+	}
+	newMap, err := structTables(d, newFilename, newSrc)
+	if err != nil {
+		return nil, err
+	}
+	opt := newOption(opts)
+	oldMap, newMap = opt.prefixTables(oldMap), opt.prefixTables(newMap)
+	// The table whose tag is added or removed is also selected.
+	selected := opt.taggedTables(oldMap, newMap)
+	return diffTables(d, selectTables(oldMap, selected), selectTables(newMap, selected), opt)
+}
+
+// DiffStructsToDatabase returns the changes to migrate the schema defined by Go's structs to the current schema of
+// the database. It is the opposite direction of DiffChanges.
+func DiffStructsToDatabase(d dialect.Dialect, filename string, src interface{}, opts ...Option) ([]*Change, error) {
+	structMap, err := structTables(d, filename, src)
+	if err != nil {
+		return nil, err
+	}
+	tableMap, err := databaseTables(d)
+	if err != nil {
+		return nil, err
+	}
+	opt := newOption(opts)
+	structMap = opt.prefixTables(structMap)
+	selected := opt.taggedTables(structMap)
+	return diffTables(d, selectTables(structMap, selected), selectTables(opt.scopeTables(tableMap), selected), opt)
+}
+
+// diffTables returns the changes to migrate the schema from oldMap to newMap.
+func diffTables(d dialect.Dialect, oldMap, newMap map[string]*table, opt *option) ([]*Change, error) {
+	names := make([]string, 0, len(newMap))
+	for name := range newMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	tableMap := make(map[string]*table, len(oldMap))
+	for name, tbl := range oldMap {
+		// The temporary tables are not compared because they exist only in the session that creates them.
+		if tbl.Persistence != dialect.PersistenceTemporary {
+			tableMap[name] = tbl
+		}
+	}
+	opt.redactor.addSensitiveDefaults(newMap, tableMap)
+	if err := checkBudget(d, newMap, opt); err != nil {
+		return nil, err
+	}
+	var changes, fkDrops, fkAdds []*Change
+	droppedColumn := map[string]struct{}{}
+	for _, name := range names {
+		tbl := newMap[name]
+		if err := validateEncryption(d, name, tbl); err != nil {
+			return nil, err
+		}
+		if err := validatePersistence(d, name, tbl); err != nil {
+			return nil, err
+		}
+		if err := validateCompression(d, name, tbl); err != nil {
+			return nil, err
+		}
+		if err := validateForeignKeys(d, name, tbl); err != nil {
+			return nil, err
+		}
+		if err := validateChecks(d, name, tbl); err != nil {
+			return nil, err
+		}
+		if err := checkSchemaLimits(d, name, tbl); err != nil {
+			return nil, err
+		}
+		if tbl.Persistence == dialect.PersistenceTemporary {
+			delete(tableMap, name)
+			continue
+		}
+		var oldFields []*field
+		indexesUnknown := tbl.IndexesUnknown
+		if oldTbl, ok := tableMap[name]; ok {
+			indexesUnknown = indexesUnknown || oldTbl.IndexesUnknown
+			oldFields = oldTbl.Fields
+			if tbl.Partial {
+				oldFields = ownedFields(oldFields, tbl.Fields)
+			}
+			c, err := encryptionChange(d, name, oldTbl, tbl, opt)
+			if err != nil {
+				return nil, err
+			}
+			if c != nil {
+				changes = append(changes, c)
+			}
+			if c := compressionChange(d, name, oldTbl, tbl); c != nil {
+				changes = append(changes, c)
+			}
+			fields := makeAlterTableFields(oldFields, tbl.Fields, opt.comparison)
+			pkChange, err := primaryKeyChange(d, name, oldTbl, tbl, oldFields, opt)
+			if err != nil {
+				return nil, err
+			}
+			if pkChange != nil && gainsAutoIncrement(fields) {
+				changes = append(changes, pkChange)
+			}
+			for _, f := range fields {
+				switch {
+				case f.IsAdded():
+					newField := f.new.ToField()
+					changes = append(changes, &Change{
+						Kind:     AddColumn,
+						Table:    name,
+						Column:   f.new.Column,
+						NewField: &newField,
+						SQLs:     d.AddColumnSQL(newField),
+					})
+				case f.IsDropped():
+					dropped, err := dropColumnChanges(d, f.old, opt)
+					if err != nil {
+						return nil, err
+					}
+					changes = append(changes, dropped...)
+				case f.IsModified():
+					oldField, newField := f.old.ToField(), f.new.ToField()
+					changes = append(changes, &Change{
+						Kind:     ModifyColumn,
+						Table:    name,
+						Column:   f.new.Column,
+						OldField: &oldField,
+						NewField: &newField,
+						SQLs:     d.ModifyColumnSQL(oldField, newField),
+					})
+				}
+			}
+			if pkChange != nil && !gainsAutoIncrement(fields) {
+				changes = append(changes, pkChange)
+			}
+			for _, f := range fields {
+				if f.IsDropped() {
+					droppedColumn[f.old.Column] = struct{}{}
+				}
+			}
+			for _, f := range fields {
+				if f.IsAdded() || f.IsModified() {
+					if err := checkRowSize(d, name, tbl); err != nil {
+						return nil, err
+					}
+					break
+				}
+			}
+		} else {
+			if err := checkRowSize(d, name, tbl); err != nil {
+				return nil, err
+			}
+			changes = append(changes, &Change{
+				Kind:  CreateTable,
+				Table: name,
+				SQLs:  d.CreateTableSQL(tbl.ToTable(name)),
+			})
+		}
+		drops, adds := foreignKeyChanges(d, name, tableMap[name], tbl)
+		fkDrops, fkAdds = append(fkDrops, drops...), append(fkAdds, adds...)
+		drops, adds = checkChanges(d, name, tableMap[name], tbl)
+		fkDrops, fkAdds = append(fkDrops, drops...), append(fkAdds, adds...)
+		if indexesUnknown {
+			// The changes of the indexes cannot be computed without the existing indexes.
+			delete(tableMap, name)
+			continue
+		}
+		addIndexes, dropIndexes := makeIndexes(oldFields, tbl.Fields)
+		if err := checkIndexKeySize(d, name, tbl, addIndexes); err != nil {
+			return nil, err
+		}
+		for _, index := range dropIndexes {
+			// The indexes of the partially owned table may be added by the other processes.
+			if tbl.Partial && !ownsIndex(tableMap[name].Fields, tbl.Fields, index, addIndexes) {
+				continue
+			}
+			// If the column which has the index will be deleted, Migu will not delete the index related to the column
+			// because the index will be deleted when the column which related to the index will be deleted.
+			if _, ok := droppedColumn[index.Columns[0]]; !ok && !isForeignKeyIndex(tbl, index.Name) {
+				changes = append(changes, &Change{
+					Kind:   DropIndex,
+					Table:  name,
+					Index:  index.Name,
+					Unique: index.Unique,
+					SQLs:   d.DropIndexSQL(index.ToIndex()),
+				})
+			}
+		}
+		for _, index := range addIndexes {
+			changes = append(changes, &Change{
+				Kind:         CreateIndex,
+				Table:        name,
+				Index:        index.Name,
+				Unique:       index.Unique,
+				IndexColumns: index.Columns,
+				SQLs:         d.CreateIndexSQL(index.ToIndex()),
+			})
+		}
+		delete(tableMap, name)
+	}
+	orphans := make([]string, 0, len(tableMap))
+	for name := range tableMap {
+		orphans = append(orphans, name)
+	}
+	orphaned, err := orphanChanges(d, orphans, opt)
+	if err != nil {
+		return nil, err
+	}
+	// The foreign keys and the CHECK constraints are dropped before the columns are dropped, and are added after the
+	// referenced tables and columns are created.
+	changes = append(append(fkDrops, changes...), fkAdds...)
+	changes = filterPhases(finalizeChanges(d, append(changes, orphaned...)), opt.phases)
+	if err := dialectError(d); err != nil {
+		return nil, err
+	}
+	if opt.costOrder {
+		changes = orderByCost(d, changes, opt.costOverrides)
+	}
+	return changes, nil
+}
+
+// finalizeChanges replaces the SQLs of the changes with the ones that are post-processed by the dialect, if the
+// dialect implements dialect.StatementFinalizer.
+func finalizeChanges(d dialect.Dialect, changes []*Change) []*Change {
+	f, ok := d.(dialect.StatementFinalizer)
+	if !ok {
+		return changes
+	}
+	for _, c := range changes {
+		c.SQLs = f.FinalizeStatement(dialect.Statement{
+			Kind:  string(c.Kind),
+			Table: c.Table,
+			SQLs:  c.SQLs,
+		})
+	}
+	return changes
+}
+
+// dialectError returns the error that is kept by the dialect if it implements dialect.ErrorKeeper. It is checked
+// after generating the SQLs since they are incomplete if the dialect has failed.
+func dialectError(d dialect.Dialect) error {
+	if k, ok := d.(dialect.ErrorKeeper); ok {
+		return k.Err()
+	}
+	return nil
+}
+
+// structTables returns the table definitions that are declared by Go's structs.
+func structTables(d dialect.Dialect, filename string, src interface{}) (map[string]*table, error) {
 	var filenames []string
 	structASTMap := make(map[string]*structAST)
+	enums := newEnumTypes()
 	if src == nil {
 		files, err := collectFiles(filename)
 		if err != nil {
@@ -68,7 +401,7 @@ func Diff(d dialect.Dialect, filename string, src interface{}) ([]string, error)
 		filenames = append(filenames, filename)
 	}
 	for _, filename := range filenames {
-		m, err := makeStructASTMap(filename, src)
+		m, err := makeStructASTMap(filename, src, enums)
 		if err != nil {
 			return nil, err
 		}
@@ -76,6 +409,7 @@ func Diff(d dialect.Dialect, filename string, src interface{}) ([]string, error)
 			structASTMap[k] = v
 		}
 	}
+	enumTypeMap := enums.types()
 	structMap := map[string]*table{}
 	for name, structAST := range structASTMap {
 		for _, fld := range structAST.StructType.Fields.List {
@@ -83,7 +417,7 @@ func Diff(d dialect.Dialect, filename string, src interface{}) ([]string, error)
 			if err != nil {
 				return nil, err
 			}
-			f, err := newField(d, name, typeName, fld)
+			f, err := newField(d, name, typeName, fld, enumTypeMap)
 			if err != nil {
 				return nil, err
 			}
@@ -95,103 +429,182 @@ func Diff(d dialect.Dialect, filename string, src interface{}) ([]string, error)
 			}
 			if structMap[name] == nil {
 				structMap[name] = &table{
-					Option: structAST.Annotation.Option,
+					Option:      structAST.Annotation.Option,
+					Encryption:  structAST.Annotation.Encryption,
+					Partial:     structAST.Annotation.Ownership == ownershipPartial,
+					Persistence: structAST.Annotation.Persistence,
+					Compression: structAST.Annotation.Compression,
+					TableChecks: structAST.Annotation.Checks,
+					Tags:        structAST.Annotation.Tags,
 				}
 			}
 			structMap[name].Fields = append(structMap[name].Fields, f)
 		}
 	}
-	names := make([]string, 0, len(structMap))
-	for name := range structMap {
-		names = append(names, name)
+	for name, tbl := range structMap {
+		if err := tbl.validate(name); err != nil {
+			return nil, err
+		}
+		tbl.ForeignKeys = structForeignKeys(name, tbl.Fields)
+		tbl.Checks = structChecks(name, tbl)
 	}
-	tableMap, err := getTableMap(d, names...)
+	return structMap, nil
+}
+
+// databaseTables returns the table definitions of the database.
+// If tables are given, only the definitions of the tables will be returned.
+func databaseTables(d dialect.Dialect, tables ...string) (map[string]*table, error) {
+	schemaMap, err := getTableMap(d, tables...)
 	if err != nil {
 		return nil, err
 	}
-	sort.Strings(names)
-	var migrations []string
-	droppedColumn := map[string]struct{}{}
-	for _, name := range names {
-		tbl := structMap[name]
-		var oldFields []*field
-		if columns, ok := tableMap[name]; ok {
-			for _, c := range columns {
-				oldFieldAST, err := fieldAST(d, c)
-				if err != nil {
-					return nil, err
-				}
-				f, err := newField(d, name, fmt.Sprint(oldFieldAST.Type), oldFieldAST)
-				if err != nil {
-					return nil, err
-				}
-				oldFields = append(oldFields, f)
+	tableMap := make(map[string]*table, len(schemaMap))
+	for name, columns := range schemaMap {
+		if isInternalTable(name) {
+			continue
+		}
+		fields, err := schemaFields(d, name, columns)
+		if err != nil {
+			return nil, err
+		}
+		tableMap[name] = &table{Fields: fields}
+	}
+	if r, ok := d.(dialect.DegradationReporter); ok {
+		for _, dg := range r.Degradations() {
+			if dg.Feature != dialect.FeatureIndexes {
+				continue
 			}
-			fields := makeAlterTableFields(oldFields, tbl.Fields)
-			for _, f := range fields {
-				switch {
-				case f.IsAdded():
-					migrations = append(migrations, d.AddColumnSQL(f.new.ToField())...)
-				case f.IsDropped():
-					migrations = append(migrations, d.DropColumnSQL(f.old.ToField())...)
-				case f.IsModified():
-					migrations = append(migrations, d.ModifyColumnSQL(f.old.ToField(), f.new.ToField())...)
-				}
+			for _, tbl := range tableMap {
+				tbl.IndexesUnknown = true
 			}
-			if d, ok := d.(dialect.PrimaryKeyModifier); ok {
-				oldPks, newPks := makePrimaryKeyColumns(oldFields, tbl.Fields)
-				if len(oldPks) > 0 || len(newPks) > 0 {
-					oldPrimaryKeyFields := make([]dialect.Field, len(oldPks))
-					for i, pk := range oldPks {
-						oldPrimaryKeyFields[i] = pk.ToField()
-					}
-					newPrimaryKeyFields := make([]dialect.Field, len(newPks))
-					for i, pk := range newPks {
-						newPrimaryKeyFields[i] = pk.ToField()
-					}
-					migrations = append(migrations, d.ModifyPrimaryKeySQL(oldPrimaryKeyFields, newPrimaryKeyFields)...)
+		}
+	}
+	if err := readIndexes(d, tableMap, tables); err != nil {
+		return nil, err
+	}
+	if e, ok := d.(dialect.TableEncrypter); ok {
+		encryptions, err := e.TableEncryptions(tables...)
+		if err != nil {
+			return nil, err
+		}
+		for name, encrypted := range encryptions {
+			if tbl, ok := tableMap[name]; ok {
+				tbl.Encryption = encryptionNo
+				if encrypted {
+					tbl.Encryption = encryptionYes
 				}
 			}
-			for _, f := range fields {
-				if f.IsDropped() {
-					droppedColumn[f.old.Column] = struct{}{}
-				}
+		}
+	}
+	if m, ok := d.(dialect.ForeignKeyManager); ok {
+		fks, err := m.ForeignKeys(tables...)
+		if err != nil {
+			return nil, err
+		}
+		for _, fk := range fks {
+			if tbl, ok := tableMap[fk.Table]; ok {
+				tbl.ForeignKeys = append(tbl.ForeignKeys, fk)
 			}
-		} else {
-			fields := make([]dialect.Field, len(tbl.Fields))
-			for i, f := range tbl.Fields {
-				fields[i] = f.ToField()
-			}
-			_, newPks := makePrimaryKeyColumns(oldFields, tbl.Fields)
-			pkColumns := make([]string, len(newPks))
-			for i, pk := range newPks {
-				pkColumns[i] = pk.ToField().Name
-			}
-			migrations = append(migrations, d.CreateTableSQL(dialect.Table{
-				Name:        name,
-				Fields:      fields,
-				PrimaryKeys: pkColumns,
-				Option:      tbl.Option,
-			})...)
 		}
-		addIndexes, dropIndexes := makeIndexes(oldFields, tbl.Fields)
-		for _, index := range dropIndexes {
-			// If the column which has the index will be deleted, Migu will not delete the index related to the column
-			// because the index will be deleted when the column which related to the index will be deleted.
-			if _, ok := droppedColumn[index.Columns[0]]; !ok {
-				migrations = append(migrations, d.DropIndexSQL(index.ToIndex())...)
+	}
+	if m, ok := d.(dialect.CheckConstraintManager); ok {
+		checks, err := m.CheckConstraints(tables...)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range checks {
+			if tbl, ok := tableMap[c.Table]; ok {
+				tbl.Checks = append(tbl.Checks, c)
 			}
 		}
-		for _, index := range addIndexes {
-			migrations = append(migrations, d.CreateIndexSQL(index.ToIndex())...)
+	}
+	if c, ok := d.(dialect.TableCompressor); ok {
+		compressions, err := c.TableCompressions(tables...)
+		if err != nil {
+			return nil, err
+		}
+		for name, compression := range compressions {
+			if tbl, ok := tableMap[name]; ok {
+				tbl.Compression = compression
+			}
 		}
-		delete(structMap, name)
-		delete(tableMap, name)
 	}
-	for name := range tableMap {
-		migrations = append(migrations, fmt.Sprintf(`DROP TABLE %s`, d.Quote(name)))
+	return tableMap, nil
+}
+
+// readIndexes sets the indexes of the fields of the tables by dialect.IndexReader if the dialect implements it, since a
+// column schema has at most one index while a column may belong to many indexes such as its own index and a
+// multiple-column unique index. The positions of the columns in the indexes are also kept, since they may differ
+// from the order of the fields.
+func readIndexes(d dialect.Dialect, tableMap map[string]*table, tables []string) error {
+	r, ok := d.(dialect.IndexReader)
+	if !ok || len(tableMap) == 0 {
+		return nil
 	}
-	return migrations, nil
+	for _, tbl := range tableMap {
+		if tbl.IndexesUnknown {
+			return nil
+		}
+	}
+	indexes, err := r.Indexes(tables...)
+	if err != nil {
+		return err
+	}
+	fieldMap := map[string]map[string]*field{}
+	for name, tbl := range tableMap {
+		fieldMap[name] = make(map[string]*field, len(tbl.Fields))
+		for _, f := range tbl.Fields {
+			f.RawIndexes, f.RawUniques, f.indexPositions = nil, nil, nil
+			fieldMap[name][f.Column] = f
+		}
+	}
+	for _, index := range indexes {
+		if index.Name == "PRIMARY" {
+			continue
+		}
+		for i, column := range index.Columns {
+			f := fieldMap[index.Table][column]
+			if f == nil {
+				continue
+			}
+			if f.indexPositions == nil {
+				f.indexPositions = map[string]int{}
+			}
+			f.indexPositions[index.Name] = i
+			// The index that is named after the column is the default index in the same way as fieldAST.
+			name := index.Name
+			if name == column {
+				name = ""
+			}
+			if index.Unique {
+				f.RawUniques = append(f.RawUniques, name)
+			} else {
+				f.RawIndexes = append(f.RawIndexes, name)
+			}
+		}
+	}
+	return nil
+}
+
+// schemaFields converts the column schemas of the table into fields in order to compare with the fields of Go's struct.
+func schemaFields(d dialect.Dialect, tableName string, columns []dialect.ColumnSchema) ([]*field, error) {
+	fields := make([]*field, 0, len(columns))
+	for _, c := range columns {
+		fieldAST, err := fieldAST(d, c, "")
+		if err != nil {
+			return nil, err
+		}
+		f, err := newField(d, tableName, fmt.Sprint(fieldAST.Type), fieldAST, nil)
+		if err != nil {
+			return nil, err
+		}
+		if cc, ok := c.(dialect.CollatedColumnSchema); ok {
+			// The defaults of the table are also compared with the declared ones of Go's structs.
+			f.Charset, f.Collation, f.inheritedCollation = cc.Collation()
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
 }
 
 func collectFiles(path string) ([]string, error) {
@@ -228,6 +641,81 @@ func collectFiles(path string) ([]string, error) {
 type table struct {
 	Fields []*field
 	Option string
+
+	// Encryption is "Y" if the table is encrypted at rest, "N" if not, or empty if it is unmanaged or unknown.
+	Encryption string
+
+	// Partial reports whether Migu manages only the columns and the indexes that are declared by Go's struct.
+	Partial bool
+
+	// Persistence is "temporary", "unlogged", or empty for the normal table.
+	Persistence string
+
+	// Compression is the compression algorithm of the pages such as "zlib" or "none", or empty if it is unmanaged or
+	// unknown.
+	Compression string
+
+	// IndexesUnknown reports whether the indexes of the table could not be read from the database.
+	IndexesUnknown bool
+
+	// ForeignKeys are the foreign key constraints of the table.
+	ForeignKeys []dialect.ForeignKey
+
+	// Checks are the CHECK constraints of the table.
+	Checks []dialect.CheckConstraint
+
+	// TableChecks are the CHECK constraints that are declared by `check` annotations of Go's struct. Their Table is
+	// empty.
+	TableChecks []dialect.CheckConstraint
+
+	// Tags are the tags of Go's struct that are declared by `tags` annotation.
+	Tags []string
+}
+
+// validate returns an error if the table contains the identifiers or literals that cannot be embedded into SQL safely.
+func (t *table) validate(name string) error {
+	if err := dialect.ValidateIdentifier(name); err != nil {
+		return newError(ErrInvalidIdentifier, "migu: invalid table name: %w", err)
+	}
+	if err := dialect.ValidateLiteral(t.Option); err != nil {
+		return newError(ErrInvalidIdentifier, "migu: invalid table option of %s: %w", name, err)
+	}
+	for _, c := range t.TableChecks {
+		if err := dialect.ValidateIdentifier(c.Name); err != nil {
+			return newError(ErrInvalidIdentifier, "migu: invalid check constraint name of %s: %w", name, err)
+		}
+		if err := dialect.ValidateLiteral(c.Expression); err != nil {
+			return newError(ErrInvalidIdentifier, "migu: invalid check constraint of %s: %w", name, err)
+		}
+	}
+	for _, f := range t.Fields {
+		if err := f.validate(); err != nil {
+			return fmt.Errorf("migu: %s.%s: %w", name, f.Name, err)
+		}
+	}
+	return nil
+}
+
+// ToTable returns the definition of the table named name to create.
+func (t *table) ToTable(name string) dialect.Table {
+	fields := make([]dialect.Field, len(t.Fields))
+	for i, f := range t.Fields {
+		fields[i] = f.ToField()
+	}
+	_, pks := makePrimaryKeyColumns(nil, t.Fields)
+	pkColumns := make([]string, len(pks))
+	for i, pk := range pks {
+		pkColumns[i] = pk.Column
+	}
+	return dialect.Table{
+		Name:        name,
+		Fields:      fields,
+		PrimaryKeys: pkColumns,
+		Option:      t.Option,
+		Encrypted:   t.Encryption == encryptionYes,
+		Persistence: t.Persistence,
+		Compression: t.Compression,
+	}
 }
 
 type index struct {
@@ -260,10 +748,40 @@ type field struct {
 	Ignore        bool
 	Default       string
 	Extra         string
+	OnUpdate      string
 	Nullable      bool
+	Compressed    bool
+	Classes       []string
+
+	// SRID is the ID of the spatial reference system of the spatial column, or empty if it is not restricted.
+	SRID string
+
+	// Charset and Collation are the character set and the collation of the string column, or empty if they are not
+	// declared.
+	Charset   string
+	Collation string
+
+	// inheritedCollation reports whether Charset and Collation of the column in the database are the defaults of the
+	// table, so that they are compared but not regarded as declared.
+	inheritedCollation bool
+
+	// indexPositions is the positions of the column in the indexes of the database by the names of the indexes, which
+	// is nil for the fields of Go's structs.
+	indexPositions map[string]int
+
+	// RefTable and RefColumn are the table and the column that the column references by the foreign key.
+	RefTable  string
+	RefColumn string
+
+	// Check is the expression of the CHECK constraint of the column.
+	Check string
+
+	// NoDiff is the attributes that are not compared with the column in the database.
+	NoDiff []Attribute
 }
 
-func newField(d dialect.Dialect, tableName string, typeName string, f *ast.Field) (*field, error) {
+// newField returns the field of the struct field. The column types of the string types of enums are ENUM or SET.
+func newField(d dialect.Dialect, tableName string, typeName string, f *ast.Field, enums map[string]*enumType) (*field, error) {
 	ret := &field{
 		Table:  tableName,
 		GoType: typeName,
@@ -284,7 +802,9 @@ func newField(d dialect.Dialect, tableName string, typeName string, f *ast.Field
 		}
 	}
 	if f.Comment != nil {
-		ret.Comment = strings.TrimSpace(f.Comment.Text())
+		var classes []string
+		ret.Comment, classes = splitClassComment(strings.TrimSpace(f.Comment.Text()))
+		ret.Classes = append(ret.Classes, classes...)
 	}
 	if ret.Column == "" {
 		ret.Column = stringutil.ToSnakeCase(ret.Name)
@@ -299,10 +819,35 @@ func newField(d dialect.Dialect, tableName string, typeName string, f *ast.Field
 	var colType string
 	if ret.Type == "" {
 		colType = strings.TrimLeft(ret.GoType, "*")
+		if e, ok := enums[colType]; ok {
+			typ, err := e.columnType(d, colType)
+			if err != nil {
+				return nil, err
+			}
+			colType = typ
+		}
 	} else {
 		colType = ret.Type
 	}
 	ret.Type = d.ColumnType(colType)
+	if typ, ok := normalizeEnumColumnType(d, colType); ok {
+		ret.Type = typ
+	}
+	if ret.Charset != "" || ret.Collation != "" {
+		if _, ok := d.(dialect.CharsetConverter); !ok {
+			return nil, newError(ErrUnsupportedFeature, "migu: %s: `charset` and `collation` tags are not supported by the dialect", ret.Column)
+		}
+	}
+	if ret.SRID != "" {
+		typ, err := spatialColumnType(d, ret)
+		if err != nil {
+			return nil, err
+		}
+		ret.Type = typ
+	}
+	if err := normalizeTimestampField(d, ret); err != nil {
+		return nil, err
+	}
 	return ret, nil
 }
 
@@ -328,18 +873,56 @@ func (f *field) UniqueIndexes() []string {
 	return uniques
 }
 
-func (f *field) IsDifferent(another *field) bool {
-	if f == nil && another == nil {
-		return false
+func (f *field) validate() error {
+	if err := dialect.ValidateIdentifier(f.Column); err != nil {
+		return newError(ErrInvalidIdentifier, "invalid column name: %w", err)
+	}
+	for _, name := range append(f.Indexes(), f.UniqueIndexes()...) {
+		if err := dialect.ValidateIdentifier(name); err != nil {
+			return newError(ErrInvalidIdentifier, "invalid index name: %w", err)
+		}
+	}
+	if f.RefTable != "" {
+		if err := dialect.ValidateIdentifier(f.RefTable); err != nil {
+			return newError(ErrInvalidIdentifier, "invalid table name of the foreign key: %w", err)
+		}
+		if err := dialect.ValidateIdentifier(f.RefColumn); err != nil {
+			return newError(ErrInvalidIdentifier, "invalid column name of the foreign key: %w", err)
+		}
+	}
+	for _, v := range []struct {
+		name  string
+		value string
+	}{
+		{tagDefault, f.Default},
+		{tagExtra, f.Extra},
+		{tagOnUpdate, f.OnUpdate},
+		{tagCheck, f.Check},
+	} {
+		if err := dialect.ValidateLiteral(v.value); err != nil {
+			return newError(ErrInvalidIdentifier, "invalid %s: %w", v.name, err)
+		}
+	}
+	if err := dialect.ValidateString(f.Comment); err != nil {
+		return newError(ErrInvalidIdentifier, "invalid comment: %w", err)
+	}
+	for _, v := range []struct {
+		name  string
+		value string
+	}{
+		{tagCharset, f.Charset},
+		{tagCollation, f.Collation},
+	} {
+		if !isCharsetName(v.value) {
+			return newError(ErrInvalidIdentifier, "invalid %s: %q", v.name, v.value)
+		}
+	}
+	for _, class := range f.Classes {
+		if err := validateClass(class); err != nil {
+			return err
+		}
 	}
-	return ((f == nil && another != nil) || (f != nil && another == nil)) ||
-		f.Type != another.Type ||
-		f.Nullable != another.Nullable ||
-		f.Default != another.Default ||
-		f.Column != another.Column ||
-		f.Extra != another.Extra ||
-		f.Comment != another.Comment ||
-		f.AutoIncrement != another.AutoIncrement
+	return nil
 }
 
 func (f *field) IsEmbedded() bool {
@@ -347,15 +930,23 @@ func (f *field) IsEmbedded() bool {
 }
 
 func (f *field) ToField() dialect.Field {
+	charset, collation := f.Charset, f.Collation
+	if f.inheritedCollation {
+		charset, collation = "", ""
+	}
 	return dialect.Field{
 		Table:         f.Table,
 		Name:          f.Column,
 		Type:          f.Type,
-		Comment:       f.Comment,
+		Comment:       commentWithClasses(f.Comment, f.Classes),
 		AutoIncrement: f.AutoIncrement,
 		Default:       f.Default,
 		Extra:         f.Extra,
+		OnUpdate:      f.OnUpdate,
 		Nullable:      f.Nullable,
+		Compressed:    f.Compressed,
+		Charset:       charset,
+		Collation:     collation,
 	}
 }
 
@@ -385,82 +976,106 @@ func makePrimaryKeyColumns(oldFields, newFields []*field) (oldPks, newPks []*fie
 	return nil, nil
 }
 
+// makeIndexes returns the indexes to be added and to be dropped. The indexes that have the same name but the different
+// columns are dropped and added again. The columns are compared regardless of the order because the order of the
+// columns of the existing indexes is of the columns of the table.
 func makeIndexes(oldFields, newFields []*field) (addIndexes, dropIndexes []*index) {
-	var dropIndexNames []string
-	var addIndexNames []string
-	dropIndexMap := map[string]*index{}
-	addIndexMap := map[string]*index{}
-	m := make(map[string]*field, len(oldFields))
+	columns := make(map[string]struct{}, len(newFields))
+	for _, f := range newFields {
+		columns[f.Column] = struct{}{}
+	}
+	// The columns that are dropped are not in the existing indexes, since the indexes on them are dropped or shrunk
+	// along with the columns by the database.
+	remainingFields := make([]*field, 0, len(oldFields))
 	for _, f := range oldFields {
-		m[f.Column] = f
+		if _, ok := columns[f.Column]; ok {
+			remainingFields = append(remainingFields, f)
+		}
 	}
-	for _, f := range newFields {
-		oldField := m[f.Column]
-		if oldField == nil {
-			oldField = &field{}
-		}
-		oindexes, nindexes := oldField.Indexes(), f.Indexes()
-		oldUniqueIndexes, newUniqueIndexes := oldField.UniqueIndexes(), f.UniqueIndexes()
-		for _, name := range oindexes {
-			if !inStrings(nindexes, name) {
-				if dropIndexMap[name] == nil {
-					dropIndexMap[name] = &index{
-						Table:  f.Table,
-						Name:   name,
-						Unique: false,
-					}
-					dropIndexNames = append(dropIndexNames, name)
-				}
-				dropIndexMap[name].Columns = append(dropIndexMap[name].Columns, oldField.Column)
-			}
+	oldIndexes, newIndexes := fieldIndexes(remainingFields), fieldIndexes(newFields)
+	newMap := make(map[string]*index, len(newIndexes))
+	for _, index := range newIndexes {
+		newMap[index.Name] = index
+	}
+	oldMap := make(map[string]*index, len(oldIndexes))
+	for _, index := range oldIndexes {
+		oldMap[index.Name] = index
+		if newIndex, ok := newMap[index.Name]; !ok || !equalIndex(index, newIndex) {
+			dropIndexes = append(dropIndexes, index)
 		}
-		for _, name := range oldUniqueIndexes {
-			if !inStrings(newUniqueIndexes, name) {
-				if dropIndexMap[name] == nil {
-					dropIndexMap[name] = &index{
-						Table:  f.Table,
-						Name:   name,
-						Unique: true,
-					}
-					dropIndexNames = append(dropIndexNames, name)
-				}
-				dropIndexMap[name].Columns = append(dropIndexMap[name].Columns, oldField.Column)
-			}
+	}
+	for _, index := range newIndexes {
+		if oldIndex, ok := oldMap[index.Name]; !ok || !equalIndex(oldIndex, index) {
+			addIndexes = append(addIndexes, index)
 		}
-		for _, name := range nindexes {
-			if !inStrings(oindexes, name) {
-				if addIndexMap[name] == nil {
-					addIndexMap[name] = &index{
-						Table:  f.Table,
-						Name:   name,
-						Unique: false,
-					}
-					addIndexNames = append(addIndexNames, name)
-				}
-				addIndexMap[name].Columns = append(addIndexMap[name].Columns, f.Column)
+	}
+	return addIndexes, dropIndexes
+}
+
+// fieldIndexes returns the indexes of the fields in the order of the first fields of them. The columns of the
+// multiple-column indexes are in the order of the fields, or in the order of the database if the fields are read from
+// the database.
+func fieldIndexes(fields []*field) []*index {
+	var indexes []*index
+	m := map[string]*index{}
+	members := map[string][]*field{}
+	add := func(f *field, name string, unique bool) {
+		if m[name] == nil {
+			m[name] = &index{
+				Table:  f.Table,
+				Name:   name,
+				Unique: unique,
 			}
+			indexes = append(indexes, m[name])
 		}
-		for _, name := range newUniqueIndexes {
-			if !inStrings(oldUniqueIndexes, name) {
-				if addIndexMap[name] == nil {
-					addIndexMap[name] = &index{
-						Table:  f.Table,
-						Name:   name,
-						Unique: true,
-					}
-					addIndexNames = append(addIndexNames, name)
-				}
-				addIndexMap[name].Columns = append(addIndexMap[name].Columns, f.Column)
-			}
+		m[name].Columns = append(m[name].Columns, f.Column)
+		members[name] = append(members[name], f)
+	}
+	for _, f := range fields {
+		for _, name := range f.Indexes() {
+			add(f, name, false)
+		}
+		for _, name := range f.UniqueIndexes() {
+			add(f, name, true)
 		}
 	}
-	for _, name := range addIndexNames {
-		addIndexes = append(addIndexes, addIndexMap[name])
+	for _, index := range indexes {
+		fields := members[index.Name]
+		if !hasIndexPositions(fields, index.Name) {
+			continue
+		}
+		sort.SliceStable(fields, func(i, j int) bool {
+			return fields[i].indexPositions[index.Name] < fields[j].indexPositions[index.Name]
+		})
+		for i, f := range fields {
+			index.Columns[i] = f.Column
+		}
 	}
-	for _, name := range dropIndexNames {
-		dropIndexes = append(dropIndexes, dropIndexMap[name])
+	return indexes
+}
+
+// hasIndexPositions reports whether all the fields have the positions in the index of the database.
+func hasIndexPositions(fields []*field, name string) bool {
+	for _, f := range fields {
+		if _, ok := f.indexPositions[name]; !ok {
+			return false
+		}
 	}
-	return addIndexes, dropIndexes
+	return true
+}
+
+// equalIndex reports whether the indexes have the same columns in the same order, since the order of the columns
+// determines which queries can use the index.
+func equalIndex(a, b *index) bool {
+	if a.Unique != b.Unique || len(a.Columns) != len(b.Columns) {
+		return false
+	}
+	for i, c := range a.Columns {
+		if b.Columns[i] != c {
+			return false
+		}
+	}
+	return true
 }
 
 type modifiedField struct {
@@ -480,7 +1095,7 @@ func (f *modifiedField) IsModified() bool {
 	return f.old != nil && f.new != nil
 }
 
-func makeAlterTableFields(oldFields, newFields []*field) (fields []modifiedField) {
+func makeAlterTableFields(oldFields, newFields []*field, comparison ComparisonStrategy) (fields []modifiedField) {
 	oldTable := make(map[string]*field, len(oldFields))
 	for _, f := range oldFields {
 		oldTable[f.Column] = f
@@ -496,10 +1111,10 @@ func makeAlterTableFields(oldFields, newFields []*field) (fields []modifiedField
 		if oldF == nil {
 			oldF = oldTable[f.Name]
 		}
-		if oldF.IsDifferent(f) {
+		if isDifferent(comparison, oldF, f) {
 			fields = append(fields, modifiedField{
 				old: oldF,
-				new: f,
+				new: withNoDiff(oldF, f),
 			})
 		}
 	}
@@ -520,40 +1135,51 @@ func Fprint(output io.Writer, d dialect.Dialect) error {
 	if err != nil {
 		return err
 	}
-	pkgMap := map[string]struct{}{}
-	for _, schemas := range tableMap {
-		for _, schema := range schemas {
-			if pkg := d.ImportPackage(schema); pkg != "" {
-				pkgMap[pkg] = struct{}{}
-			}
-		}
+	return fprintTableMap(output, d, tableMap)
+}
+
+// FprintSQL generates Go's structs from the CREATE TABLE statements of MySQL such as the output of mysqldump, and writes
+// to output. The statements other than CREATE TABLE are ignored.
+// If src is not nil, FprintSQL reads the statements from src instead of the file.
+func FprintSQL(output io.Writer, d dialect.Dialect, filename string, src interface{}, opts ...Option) error {
+	tableMap, err := parseCreateTables(filename, src)
+	if err != nil {
+		return err
 	}
-	if len(pkgMap) != 0 {
-		pkgs := make([]string, 0, len(pkgMap))
-		for pkg := range pkgMap {
-			pkgs = append(pkgs, pkg)
-		}
-		sort.Strings(pkgs)
-		if err := fprintln(output, importAST(pkgs)); err != nil {
-			return err
-		}
+	opt := newOption(opts)
+	return fprintTableMap(output, d, opt.excludeTableMap(opt.limitTableMap(opt.unprefixTableMap(tableMap))))
+}
+
+// fprintTableMap generates Go's structs from the column schemas of the tables and writes to output. Nothing is written
+// if the generated code does not compile.
+func fprintTableMap(output io.Writer, d dialect.Dialect, tableMap map[string][]dialect.ColumnSchema) error {
+	imports, err := newGoImports(d)
+	if err != nil {
+		return err
 	}
 	names := make([]string, 0, len(tableMap))
 	for name := range tableMap {
 		names = append(names, name)
 	}
 	sort.Strings(names)
+	var buf bytes.Buffer
 	for _, name := range names {
-		s, err := makeStructAST(d, name, tableMap[name])
+		code, err := imports.structCode(name, tableMap[name])
 		if err != nil {
 			return err
 		}
-		fmt.Fprintln(output, commentPrefix+marker)
-		if err := fprintln(output, s); err != nil {
+		buf.Write(code)
+	}
+	if err := dialectError(d); err != nil {
+		return err
+	}
+	if decl := imports.decl(); decl != nil {
+		if err := fprintln(output, decl); err != nil {
 			return err
 		}
 	}
-	return nil
+	_, err = buf.WriteTo(output)
+	return err
 }
 
 const (
@@ -566,6 +1192,15 @@ const (
 	tagType          = "type"
 	tagNull          = "null"
 	tagExtra         = "extra"
+	tagOnUpdate      = "on_update"
+	tagClass         = "class"
+	tagCompressed    = "compressed"
+	tagSRID          = "srid"
+	tagCharset       = "charset"
+	tagCollation     = "collation"
+	tagNoDiff        = "nodiff"
+	tagForeignKey    = "fk"
+	tagCheck         = "check"
 	tagIgnore        = "-"
 )
 
@@ -582,7 +1217,17 @@ func getTableMap(d dialect.Dialect, tables ...string) (map[string][]dialect.Colu
 }
 
 func fprintln(output io.Writer, decl ast.Decl) error {
-	if err := format.Node(output, token.NewFileSet(), decl); err != nil {
+	var buf bytes.Buffer
+	if err := format.Node(&buf, token.NewFileSet(), decl); err != nil {
+		return err
+	}
+	// The types of the fields may be the anonymous structs that are given as the strings by the dialect, so that the
+	// declaration is formatted again to lay out them.
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	if _, err := output.Write(src); err != nil {
 		return err
 	}
 	fmt.Fprintf(output, "\n\n")
@@ -594,12 +1239,14 @@ type structAST struct {
 	Annotation *annotation
 }
 
-func makeStructASTMap(filename string, src interface{}) (map[string]*structAST, error) {
+// makeStructASTMap returns the structs that have the annotation in the file, and collects the enum types into enums.
+func makeStructASTMap(filename string, src interface{}, enums *enumTypes) (map[string]*structAST, error) {
 	fset := token.NewFileSet()
 	f, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
 	if err != nil {
 		return nil, err
 	}
+	enums.collect(f)
 	structASTMap := map[string]*structAST{}
 	for _, decl := range f.Decls {
 		d, ok := decl.(*ast.GenDecl)
@@ -660,40 +1307,52 @@ func detectTypeName(n ast.Node) (string, error) {
 			return "", err
 		}
 		return "[]" + name, nil
+	case *ast.StructType:
+		var fields []string
+		for _, f := range t.Fields.List {
+			name, err := detectTypeName(f.Type)
+			if err != nil {
+				return "", err
+			}
+			for _, n := range f.Names {
+				fields = append(fields, n.Name+" "+name)
+			}
+			if len(f.Names) == 0 {
+				fields = append(fields, name)
+			}
+		}
+		if len(fields) == 0 {
+			return "struct{}", nil
+		}
+		return "struct{ " + strings.Join(fields, "; ") + " }", nil
 	default:
-		return "", fmt.Errorf("migu: BUG: unknown type %T", t)
+		return "", newError(ErrUnsupportedType, "migu: unsupported type %T", t)
 	}
 }
 
-func importAST(pkgs []string) ast.Decl {
-	decl := &ast.GenDecl{
-		Tok: token.IMPORT,
-	}
-	for _, pkg := range pkgs {
-		decl.Specs = append(decl.Specs, &ast.ImportSpec{
-			Path: &ast.BasicLit{
-				Kind:  token.STRING,
-				Value: fmt.Sprintf(`"%s"`, pkg),
-			},
-		})
-	}
-	return decl
-}
-
-func makeStructAST(d dialect.Dialect, name string, schemas []dialect.ColumnSchema) (ast.Decl, error) {
+// makeStructAST returns the declaration of the struct of the table, followed by the declarations of the string types
+// that implement Enum or Set for ENUM and SET columns.
+func makeStructAST(d dialect.Dialect, name string, schemas []dialect.ColumnSchema) ([]ast.Decl, error) {
 	var fields []*ast.Field
+	var enumDecls []ast.Decl
+	structName := stringutil.ToUpperCamelCase(name)
 	for _, schema := range schemas {
-		f, err := fieldAST(d, schema)
+		var enumType string
+		if isEnumColumnType(d, schema.ColumnType()) {
+			enumType = structName + stringutil.ToUpperCamelCase(schema.ColumnName())
+			enumDecls = append(enumDecls, enumTypeDecls(enumType, schema.ColumnType())...)
+		}
+		f, err := fieldAST(d, schema, enumType)
 		if err != nil {
 			return nil, err
 		}
 		fields = append(fields, f)
 	}
-	return &ast.GenDecl{
+	return append([]ast.Decl{&ast.GenDecl{
 		Tok: token.TYPE,
 		Specs: []ast.Spec{
 			&ast.TypeSpec{
-				Name: ast.NewIdent(stringutil.ToUpperCamelCase(name)),
+				Name: ast.NewIdent(structName),
 				Type: &ast.StructType{
 					Fields: &ast.FieldList{
 						List: fields,
@@ -701,7 +1360,7 @@ func makeStructAST(d dialect.Dialect, name string, schemas []dialect.ColumnSchem
 				},
 			},
 		},
-	}, nil
+	}}, enumDecls...), nil
 }
 
 func parseStructTag(d dialect.Dialect, f *field, tag reflect.StructTag) error {
@@ -739,23 +1398,73 @@ func parseStructTag(d dialect.Dialect, f *field, tag reflect.StructTag) error {
 			f.Ignore = true
 		case tagColumn:
 			if len(optval) < 2 {
-				return fmt.Errorf("`column` tag must specify the parameter")
+				return newError(ErrInvalidTag, "`column` tag must specify the parameter")
 			}
 			f.Column = optval[1]
 		case tagType:
 			if len(optval) < 2 {
-				return fmt.Errorf("`type` tag must specify the parameter")
+				return newError(ErrInvalidTag, "`type` tag must specify the parameter")
 			}
 			f.Type = optval[1]
 		case tagNull:
 			f.Nullable = true
+		case tagCompressed:
+			f.Compressed = true
+		case tagCharset:
+			if len(optval) < 2 {
+				return newError(ErrInvalidTag, "`charset` tag must specify the parameter")
+			}
+			f.Charset = optval[1]
+		case tagCollation:
+			if len(optval) < 2 {
+				return newError(ErrInvalidTag, "`collation` tag must specify the parameter")
+			}
+			f.Collation = optval[1]
+		case tagSRID:
+			if len(optval) < 2 {
+				return newError(ErrInvalidTag, "`srid` tag must specify the parameter")
+			}
+			f.SRID = optval[1]
 		case tagExtra:
 			if len(optval) < 2 {
-				return fmt.Errorf("`extra` tag must specify the parameter")
+				return newError(ErrInvalidTag, "`extra` tag must specify the parameter")
 			}
 			f.Extra = optval[1]
+		case tagOnUpdate:
+			if len(optval) < 2 {
+				return newError(ErrInvalidTag, "`on_update` tag must specify the parameter")
+			}
+			f.OnUpdate = optval[1]
+		case tagClass:
+			if len(optval) < 2 {
+				return newError(ErrInvalidTag, "`class` tag must specify the parameter")
+			}
+			f.Classes = append(f.Classes, optval[1])
+		case tagForeignKey:
+			if len(optval) < 2 {
+				return newError(ErrInvalidTag, "`fk` tag must specify the parameter")
+			}
+			table, column, err := parseForeignKeyTag(optval[1])
+			if err != nil {
+				return err
+			}
+			f.RefTable, f.RefColumn = table, column
+		case tagCheck:
+			if len(optval) < 2 {
+				return newError(ErrInvalidTag, "`check` tag must specify the parameter")
+			}
+			f.Check = optval[1]
+		case tagNoDiff:
+			if len(optval) < 2 {
+				return newError(ErrInvalidTag, "`nodiff` tag must specify the parameter")
+			}
+			attr, err := parseAttribute(optval[1])
+			if err != nil {
+				return err
+			}
+			f.NoDiff = append(f.NoDiff, attr)
 		default:
-			return fmt.Errorf("unknown option: `%s'", opt)
+			return newError(ErrInvalidTag, "unknown option: `%s'", opt)
 		}
 	}
 	return scanner.Err()
@@ -763,30 +1472,51 @@ func parseStructTag(d dialect.Dialect, f *field, tag reflect.StructTag) error {
 
 func tagOptionSplit(data []byte, atEOF bool) (advance int, token []byte, err error) {
 	var inParenthesis bool
+	// The commas in the nested types such as STRUCT<a INT64, b STRING> of BigQuery are not the separators.
+	var angleDepth int
 	for i := 0; i < len(data); i++ {
 		switch data[i] {
 		case ',':
-			if !inParenthesis {
+			if !inParenthesis && angleDepth == 0 {
 				return i + 1, data[:i], nil
 			}
 		case '(':
 			inParenthesis = true
 		case ')':
 			inParenthesis = false
+		case '<':
+			if prefix := strings.ToUpper(string(data[:i])); strings.HasSuffix(prefix, "ARRAY") || strings.HasSuffix(prefix, "STRUCT") {
+				angleDepth++
+			}
+		case '>':
+			if angleDepth > 0 {
+				angleDepth--
+			}
 		}
 	}
 	return 0, data, bufio.ErrFinalToken
 }
 
-func fieldAST(d dialect.Dialect, schema dialect.ColumnSchema) (*ast.Field, error) {
+// fieldAST returns the struct field of the column. If enumType is not empty, the type of the field is enumType instead
+// of `type` struct field tag.
+func fieldAST(d dialect.Dialect, schema dialect.ColumnSchema, enumType string) (*ast.Field, error) {
+	goType := d.GoType(schema.ColumnType(), schema.IsNullable())
+	if enumType != "" {
+		goType = enumType
+		if schema.IsNullable() {
+			goType = "*" + enumType
+		}
+	}
 	field := &ast.Field{
 		Names: []*ast.Ident{
 			ast.NewIdent(stringutil.ToUpperCamelCase(schema.ColumnName())),
 		},
-		Type: ast.NewIdent(d.GoType(schema.ColumnType(), schema.IsNullable())),
+		Type: ast.NewIdent(goType),
 	}
 	var tags []string
-	tags = append(tags, fmt.Sprintf("%s:%s", tagType, schema.ColumnType()))
+	if enumType == "" {
+		tags = append(tags, fmt.Sprintf("%s:%s", tagType, schema.ColumnType()))
+	}
 	if v, ok := schema.Default(); ok {
 		tags = append(tags, tagDefault+":"+v)
 	}
@@ -812,8 +1542,38 @@ func fieldAST(d dialect.Dialect, schema dialect.ColumnSchema) (*ast.Field, error
 	if schema.IsNullable() {
 		tags = append(tags, tagNull)
 	}
-	if v, ok := schema.Extra(); ok {
-		tags = append(tags, fmt.Sprintf("%s:%s", tagExtra, v))
+	if c, ok := schema.(dialect.CompressedColumnSchema); ok && c.IsCompressed() {
+		tags = append(tags, tagCompressed)
+	}
+	if c, ok := schema.(dialect.CollatedColumnSchema); ok {
+		if charset, collation, isDefault := c.Collation(); charset != "" && !isDefault {
+			tags = append(tags, tagCharset+":"+charset, tagCollation+":"+collation)
+		}
+	}
+	if s, ok := schema.(dialect.SpatialColumnSchema); ok {
+		if srid, ok := s.SRID(); ok {
+			tags = append(tags, fmt.Sprintf("%s:%d", tagSRID, srid))
+		}
+	}
+	extra, _ := schema.Extra()
+	if c, ok := schema.(dialect.OnUpdateColumnSchema); ok {
+		if v, ok := c.OnUpdate(); ok {
+			if _, ok := d.(dialect.OnUpdater); ok {
+				tags = append(tags, tagOnUpdate+":"+v)
+			} else {
+				extra = strings.TrimSpace("ON UPDATE " + v + " " + extra)
+			}
+		}
+	}
+	if extra != "" {
+		tags = append(tags, fmt.Sprintf("%s:%s", tagExtra, extra))
+	}
+	comment, classes := "", []string(nil)
+	if v, ok := schema.Comment(); ok {
+		comment, classes = splitClassComment(v)
+	}
+	for _, class := range classes {
+		tags = append(tags, fmt.Sprintf("%s:%s", tagClass, class))
 	}
 	if len(tags) > 0 {
 		field.Tag = &ast.BasicLit{
@@ -822,10 +1582,10 @@ func fieldAST(d dialect.Dialect, schema dialect.ColumnSchema) (*ast.Field, error
 			ValuePos: 1,
 		}
 	}
-	if v, ok := schema.Comment(); ok {
+	if comment != "" {
 		field.Comment = &ast.CommentGroup{
 			List: []*ast.Comment{
-				{Text: "// " + v},
+				{Text: "// " + comment},
 			},
 		}
 	}
@@ -0,0 +1,223 @@
+package modelfile
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// Field is the struct field that is added by AddField.
+type Field struct {
+	Name string
+	Type string
+
+	// Tag is the struct field tag without the backquotes. (e.g. `migu:"size:255"`)
+	Tag string
+
+	// Comment is the line comment of the field without "//".
+	Comment string
+}
+
+// AddField returns the Go source src that the field is appended to the struct of typeName.
+// The comments and the other declarations in src are kept as they are, and the struct is formatted by gofmt.
+func AddField(src []byte, typeName string, field Field) ([]byte, error) {
+	if field.Name == "" || field.Type == "" {
+		return nil, fmt.Errorf("name and type of the field must be specified")
+	}
+	if strings.Contains(field.Tag, "`") {
+		return nil, fmt.Errorf("struct field tag must not contain the backquote: %s", field.Tag)
+	}
+	fset, st, err := findStruct(src, typeName)
+	if err != nil {
+		return nil, err
+	}
+	if findField(st, field.Name) != nil {
+		return nil, fmt.Errorf("field %s already exists in %s", field.Name, typeName)
+	}
+	decl := field.Name + " " + field.Type
+	if field.Tag != "" {
+		decl += " `" + field.Tag + "`"
+	}
+	if field.Comment != "" {
+		decl += " // " + field.Comment
+	}
+	offset := fset.Position(st.Fields.Closing).Offset
+	if offset > 0 && src[offset-1] != '\n' {
+		decl = "\n" + decl
+	}
+	return edit(fset, src, st, offset, offset, decl+"\n")
+}
+
+// SetTag returns the Go source src that the value of key in the struct field tag of the field is replaced with
+// value. The key is appended to the tag if it does not exist, and is removed from the tag if value is empty.
+// The comments and the other declarations in src are kept as they are, and the struct is formatted by gofmt.
+func SetTag(src []byte, typeName, fieldName, key, value string) ([]byte, error) {
+	if key == "" || strings.ContainsAny(key, " :\"`") {
+		return nil, fmt.Errorf("invalid key of struct field tag: %q", key)
+	}
+	fset, st, err := findStruct(src, typeName)
+	if err != nil {
+		return nil, err
+	}
+	f := findField(st, fieldName)
+	if f == nil {
+		return nil, fmt.Errorf("field %s is not found in %s", fieldName, typeName)
+	}
+	var pairs []tagPair
+	if f.Tag != nil {
+		tag, err := strconv.Unquote(f.Tag.Value)
+		if err != nil {
+			return nil, err
+		}
+		if pairs, err = parseTag(tag); err != nil {
+			return nil, fmt.Errorf("%s.%s: %v", typeName, fieldName, err)
+		}
+	}
+	pairs = setTagPair(pairs, key, value)
+	tag := formatTag(pairs)
+	if strings.Contains(tag, "`") {
+		return nil, fmt.Errorf("struct field tag must not contain the backquote: %s", tag)
+	}
+	if tag != "" {
+		tag = "`" + tag + "`"
+	}
+	if f.Tag != nil {
+		// The space before the tag is removed together with the tag, and then restored by gofmt.
+		start, end := fset.Position(f.Type.End()).Offset, fset.Position(f.Tag.End()).Offset
+		if tag != "" {
+			tag = " " + tag
+		}
+		return edit(fset, src, st, start, end, tag)
+	}
+	offset := fset.Position(f.Type.End()).Offset
+	if tag != "" {
+		tag = " " + tag
+	}
+	return edit(fset, src, st, offset, offset, tag)
+}
+
+// findStruct returns the struct type of typeName in src.
+func findStruct(src []byte, typeName string) (*token.FileSet, *ast.StructType, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+	var st *ast.StructType
+	ast.Inspect(file, func(n ast.Node) bool {
+		if spec, ok := n.(*ast.TypeSpec); ok && spec.Name.Name == typeName {
+			st, _ = spec.Type.(*ast.StructType)
+		}
+		return st == nil
+	})
+	if st == nil {
+		return nil, nil, fmt.Errorf("struct %s is not found", typeName)
+	}
+	return fset, st, nil
+}
+
+// findField returns the field of name in st, or nil if not found.
+func findField(st *ast.StructType, name string) *ast.Field {
+	for _, f := range st.Fields.List {
+		for _, ident := range f.Names {
+			if ident.Name == name {
+				return f
+			}
+		}
+	}
+	return nil
+}
+
+// edit returns src that src[start:end] in st is replaced with s. Only st is formatted so that the rest of src
+// such as the annotations in the doc comments is not rewritten by gofmt.
+func edit(fset *token.FileSet, src []byte, st *ast.StructType, start, end int, s string) ([]byte, error) {
+	stStart, stEnd := fset.Position(st.Pos()).Offset, fset.Position(st.End()).Offset
+	var body bytes.Buffer
+	body.Write(src[stStart:start])
+	body.WriteString(s)
+	body.Write(src[end:stEnd])
+	const header = "package p\n\ntype _ "
+	formatted, err := format.Source(append([]byte(header), body.Bytes()...))
+	if err != nil {
+		return nil, err
+	}
+	// The struct in the grouped declaration is indented by the indentation of the line where it starts.
+	lineStart := bytes.LastIndexByte(src[:stStart], '\n') + 1
+	indent := src[lineStart : lineStart+len(src[lineStart:stStart])-len(bytes.TrimLeft(src[lineStart:stStart], " \t"))]
+	lines := bytes.Split(bytes.TrimSuffix(formatted[len(header):], []byte("\n")), []byte("\n"))
+	for i := 1; i < len(lines); i++ {
+		if len(lines[i]) > 0 {
+			lines[i] = append(append([]byte{}, indent...), lines[i]...)
+		}
+	}
+	var buf bytes.Buffer
+	buf.Write(src[:stStart])
+	buf.Write(bytes.Join(lines, []byte("\n")))
+	buf.Write(src[stEnd:])
+	return buf.Bytes(), nil
+}
+
+type tagPair struct {
+	key   string
+	value string
+}
+
+// parseTag parses the struct field tag in the conventional format of reflect.StructTag.
+func parseTag(tag string) ([]tagPair, error) {
+	var pairs []tagPair
+	for tag = strings.TrimLeft(tag, " "); tag != ""; tag = strings.TrimLeft(tag, " ") {
+		i := strings.Index(tag, ":\"")
+		if i <= 0 || strings.ContainsAny(tag[:i], " \"") {
+			return nil, fmt.Errorf("malformed struct field tag: %s", tag)
+		}
+		key := tag[:i]
+		tag = tag[i+1:]
+		j := 1
+		for ; j < len(tag) && tag[j] != '"'; j++ {
+			if tag[j] == '\\' {
+				j++
+			}
+		}
+		if j >= len(tag) {
+			return nil, fmt.Errorf("malformed struct field tag: %s", tag)
+		}
+		value, err := strconv.Unquote(tag[:j+1])
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, tagPair{key: key, value: value})
+		tag = tag[j+1:]
+	}
+	return pairs, nil
+}
+
+// setTagPair returns pairs that the value of key is replaced with value, or removed if value is empty.
+func setTagPair(pairs []tagPair, key, value string) []tagPair {
+	for i, p := range pairs {
+		if p.key != key {
+			continue
+		}
+		if value == "" {
+			return append(pairs[:i:i], pairs[i+1:]...)
+		}
+		pairs[i].value = value
+		return pairs
+	}
+	if value == "" {
+		return pairs
+	}
+	return append(pairs, tagPair{key: key, value: value})
+}
+
+func formatTag(pairs []tagPair) string {
+	tags := make([]string, len(pairs))
+	for i, p := range pairs {
+		tags[i] = p.key + ":" + strconv.Quote(p.value)
+	}
+	return strings.Join(tags, " ")
+}
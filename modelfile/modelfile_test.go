@@ -0,0 +1,145 @@
+package modelfile_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/naoina/migu/modelfile"
+)
+
+const src = `package model
+
+// User is the user.
+//+migu
+type User struct {
+	ID   int64  ` + "`migu:\"pk,autoincrement\"`" + ` // the ID
+	Name string // the name
+
+	// Age is the age.
+	Age int ` + "`json:\"age\" migu:\"default:0\"`" + `
+}
+`
+
+func TestAddField(t *testing.T) {
+	for _, v := range []struct {
+		field  modelfile.Field
+		expect string
+	}{
+		{modelfile.Field{Name: "Email", Type: "string", Tag: `migu:"size:255"`, Comment: "the email"}, `package model
+
+// User is the user.
+//+migu
+type User struct {
+	ID   int64  ` + "`migu:\"pk,autoincrement\"`" + ` // the ID
+	Name string // the name
+
+	// Age is the age.
+	Age   int    ` + "`json:\"age\" migu:\"default:0\"`" + `
+	Email string ` + "`migu:\"size:255\"`" + ` // the email
+}
+`},
+		{modelfile.Field{Name: "Score", Type: "*float64"}, `package model
+
+// User is the user.
+//+migu
+type User struct {
+	ID   int64  ` + "`migu:\"pk,autoincrement\"`" + ` // the ID
+	Name string // the name
+
+	// Age is the age.
+	Age   int ` + "`json:\"age\" migu:\"default:0\"`" + `
+	Score *float64
+}
+`},
+	} {
+		actual, err := modelfile.AddField([]byte(src), "User", v.field)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(string(actual), v.expect); diff != "" {
+			t.Errorf("AddField(%#v) (-got +want)\n%v", v.field, diff)
+		}
+	}
+	if _, err := modelfile.AddField([]byte(src), "User", modelfile.Field{Name: "Name", Type: "string"}); err == nil {
+		t.Errorf("AddField with the existing field => nil; want error")
+	}
+	if _, err := modelfile.AddField([]byte(src), "Post", modelfile.Field{Name: "Title", Type: "string"}); err == nil {
+		t.Errorf("AddField to the unknown struct => nil; want error")
+	}
+}
+
+func TestSetTag(t *testing.T) {
+	for _, v := range []struct {
+		field, key, value string
+		expect            string
+	}{
+		{"Name", "migu", "size:64", `package model
+
+// User is the user.
+//+migu
+type User struct {
+	ID   int64  ` + "`migu:\"pk,autoincrement\"`" + ` // the ID
+	Name string ` + "`migu:\"size:64\"`" + `          // the name
+
+	// Age is the age.
+	Age int ` + "`json:\"age\" migu:\"default:0\"`" + `
+}
+`},
+		{"Age", "migu", "default:18,null", `package model
+
+// User is the user.
+//+migu
+type User struct {
+	ID   int64  ` + "`migu:\"pk,autoincrement\"`" + ` // the ID
+	Name string // the name
+
+	// Age is the age.
+	Age int ` + "`json:\"age\" migu:\"default:18,null\"`" + `
+}
+`},
+		{"Age", "db", "age", `package model
+
+// User is the user.
+//+migu
+type User struct {
+	ID   int64  ` + "`migu:\"pk,autoincrement\"`" + ` // the ID
+	Name string // the name
+
+	// Age is the age.
+	Age int ` + "`json:\"age\" migu:\"default:0\" db:\"age\"`" + `
+}
+`},
+		{"ID", "migu", "", `package model
+
+// User is the user.
+//+migu
+type User struct {
+	ID   int64  // the ID
+	Name string // the name
+
+	// Age is the age.
+	Age int ` + "`json:\"age\" migu:\"default:0\"`" + `
+}
+`},
+	} {
+		actual, err := modelfile.SetTag([]byte(src), "User", v.field, v.key, v.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(string(actual), v.expect); diff != "" {
+			t.Errorf("SetTag(%q, %q, %q) (-got +want)\n%v", v.field, v.key, v.value, diff)
+		}
+	}
+	grouped := "package model\n\ntype (\n\t//+migu\n\tPost struct {\n\t\tID int64\n\t}\n)\n"
+	actual, err := modelfile.SetTag([]byte(grouped), "Post", "ID", "migu", "pk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := "package model\n\ntype (\n\t//+migu\n\tPost struct {\n\t\tID int64 `migu:\"pk\"`\n\t}\n)\n"
+	if diff := cmp.Diff(string(actual), expect); diff != "" {
+		t.Errorf("SetTag in the grouped declaration (-got +want)\n%v", diff)
+	}
+	if _, err := modelfile.SetTag([]byte(src), "User", "Email", "migu", "size:255"); err == nil {
+		t.Errorf("SetTag to the unknown field => nil; want error")
+	}
+}
@@ -0,0 +1,82 @@
+package migu
+
+import (
+	"regexp"
+	"time"
+)
+
+// Option configures settings for computing differences of schemas.
+type Option func(*option)
+
+type option struct {
+	archiveOrphans    bool
+	archiveRetention  time.Duration
+	twoPhaseDrop      bool
+	dropGracePeriod   time.Duration
+	phases            []Phase
+	allowDecryption   bool
+	allowPKChange     bool
+	events            chan<- Event
+	budget            *Budget
+	budgetWarn        func(v *BudgetViolation)
+	comparison        ComparisonStrategy
+	now               func() time.Time
+	partialResults    bool
+	ignoreColumnOrder bool
+	excludeGenerated  bool
+	excludeColumns    *regexp.Regexp
+	onExclude         func(e ColumnExclusion)
+	tablePrefix       string
+	redactor          *Redactor
+	costOrder         bool
+	costOverrides     map[ChangeKind]Cost
+	tags              []string
+	tables            map[string]bool
+}
+
+func newOption(opts []Option) *option {
+	o := &option{
+		comparison: StrictComparison(),
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithAllowDecryption allows the changes that decrypt the encrypted tables.
+// Without it, the diff returns an error if the `encryption:"N"` annotation would decrypt the table.
+func WithAllowDecryption() Option {
+	return func(o *option) {
+		o.allowDecryption = true
+	}
+}
+
+// WithAllowPrimaryKeyChange allows the changes that modify the primary keys of the existing tables.
+// Without it, the diff returns an error if the primary keys of Go's struct differ from the ones of the table, because
+// the change rebuilds the whole table and may fail with the duplicate keys.
+func WithAllowPrimaryKeyChange() Option {
+	return func(o *option) {
+		o.allowPKChange = true
+	}
+}
+
+// WithArchiveOrphans renames the tables that exist in the database but are not defined by Go's structs with the
+// archived table prefix instead of dropping them. The archived tables are dropped after the retention period passed.
+func WithArchiveOrphans(retention time.Duration) Option {
+	return func(o *option) {
+		o.archiveOrphans = true
+		o.archiveRetention = retention
+	}
+}
+
+// WithTwoPhaseColumnDrop renames the columns that are removed from Go's structs with the deprecated column prefix
+// instead of dropping them. The deprecated columns are dropped after the grace period passed.
+// It protects against rollback of the application code that still reads the columns.
+func WithTwoPhaseColumnDrop(gracePeriod time.Duration) Option {
+	return func(o *option) {
+		o.twoPhaseDrop = true
+		o.dropGracePeriod = gracePeriod
+	}
+}
@@ -0,0 +1,108 @@
+package migu
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/naoina/migu/dialect"
+)
+
+const (
+	// ArchivedTablePrefix is the prefix of the table name that is archived by WithArchiveOrphans.
+	// The archived table name is ArchivedTablePrefix + "YYYYMMDDhhmmss_" + the original table name, which is
+	// truncated with its hash if the archived table name is longer than 64 bytes.
+	ArchivedTablePrefix = "_zzz_archived_"
+
+	archivedTimeLayout = "20060102150405"
+)
+
+// Orphans returns the names of the tables that exist in the database but are not defined by Go's structs.
+// The archived tables and the tables of Migu itself such as the migrations table are not included.
+// With WithTablePrefix, only the tables whose names begin with the prefix are reported.
+func Orphans(d dialect.Dialect, filename string, src interface{}, opts ...Option) ([]string, error) {
+	structMap, err := structTables(d, filename, src)
+	if err != nil {
+		return nil, err
+	}
+	opt := newOption(opts)
+	structMap = opt.prefixTables(structMap)
+	tableMap, err := getTableMap(d)
+	if err != nil {
+		return nil, err
+	}
+	var orphans []string
+	for name := range tableMap {
+		if _, ok := structMap[name]; ok || !opt.inScope(name) {
+			continue
+		}
+		if _, _, archived := parseArchivedTableName(name); archived || isInternalTable(name) {
+			continue
+		}
+		orphans = append(orphans, name)
+	}
+	sort.Strings(orphans)
+	return orphans, nil
+}
+
+func archivedTableName(name string, t time.Time) string {
+	return truncateIdentifier(ArchivedTablePrefix+t.UTC().Format(archivedTimeLayout)+"_"+name, generatedIdentifierLength)
+}
+
+// parseArchivedTableName returns the original table name and the archived time if name is an archived table.
+func parseArchivedTableName(name string) (original string, archivedAt time.Time, ok bool) {
+	if !strings.HasPrefix(name, ArchivedTablePrefix) {
+		return "", time.Time{}, false
+	}
+	s := name[len(ArchivedTablePrefix):]
+	i := strings.IndexByte(s, '_')
+	if i < 0 {
+		return "", time.Time{}, false
+	}
+	t, err := time.Parse(archivedTimeLayout, s[:i])
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	return s[i+1:], t, true
+}
+
+// orphanChanges returns the changes for the tables that are only in the old schema.
+// Archived tables are never dropped unless the retention period of WithArchiveOrphans passed.
+func orphanChanges(d dialect.Dialect, names []string, opt *option) ([]*Change, error) {
+	sort.Strings(names)
+	var changes []*Change
+	now := opt.now()
+	for _, name := range names {
+		if _, archivedAt, archived := parseArchivedTableName(name); archived {
+			if opt.archiveOrphans && !now.Before(archivedAt.Add(opt.archiveRetention)) {
+				changes = append(changes, dropTableChange(d, name))
+			}
+			continue
+		}
+		if !opt.archiveOrphans {
+			changes = append(changes, dropTableChange(d, name))
+			continue
+		}
+		r, ok := d.(dialect.TableRenamer)
+		if !ok {
+			return nil, newError(ErrUnsupportedFeature, "migu: archiving tables is not supported by the dialect")
+		}
+		newName := archivedTableName(name, now)
+		changes = append(changes, &Change{
+			Kind:    RenameTable,
+			Table:   name,
+			NewName: newName,
+			SQLs:    r.RenameTableSQL(name, newName),
+		})
+	}
+	return changes, nil
+}
+
+func dropTableChange(d dialect.Dialect, name string) *Change {
+	return &Change{
+		Kind:  DropTable,
+		Table: name,
+		SQLs:  []string{fmt.Sprintf(`DROP TABLE %s`, d.Quote(name))},
+	}
+}
@@ -0,0 +1,59 @@
+package migu
+
+const (
+	ownershipFull    = "full"
+	ownershipPartial = "partial"
+)
+
+// ownsIndex reports whether the existing index of the partially owned table is managed by Go's struct, that is, the
+// index is declared again with the same name by newFields and all of its columns in oldFields, which are all the
+// columns in the database, are declared by newFields. The other indexes may be added by the other processes.
+func ownsIndex(oldFields, newFields []*field, idx *index, addIndexes []*index) bool {
+	redeclared := false
+	for _, i := range addIndexes {
+		if i.Name == idx.Name {
+			redeclared = true
+			break
+		}
+	}
+	if !redeclared {
+		return false
+	}
+	owned := ownedFields(oldFields, newFields)
+	for _, i := range fieldIndexes(oldFields) {
+		if i.Name == idx.Name {
+			return len(i.Columns) == len(ownedIndexColumns(owned, i.Name))
+		}
+	}
+	return false
+}
+
+// ownedIndexColumns returns the columns of the index named name in the owned fields.
+func ownedIndexColumns(owned []*field, name string) []string {
+	for _, i := range fieldIndexes(owned) {
+		if i.Name == name {
+			return i.Columns
+		}
+	}
+	return nil
+}
+
+// ownedFields returns the fields of oldFields that are also declared in newFields.
+// It is used for the table with ownership:"partial" annotation, so that the columns added by the other processes
+// are neither dropped nor changed.
+func ownedFields(oldFields, newFields []*field) []*field {
+	declared := make(map[string]struct{}, len(newFields)*2)
+	for _, f := range newFields {
+		declared[f.Column] = struct{}{}
+		declared[f.Name] = struct{}{}
+	}
+	var owned []*field
+	for _, f := range oldFields {
+		_, column := declared[f.Column]
+		_, name := declared[f.Name]
+		if column || name {
+			owned = append(owned, f)
+		}
+	}
+	return owned
+}
@@ -0,0 +1,50 @@
+package migu
+
+import (
+	"sort"
+
+	"github.com/naoina/migu/dialect"
+)
+
+// validatePersistence returns an error if the table has the persistence annotation but the dialect does not support it.
+func validatePersistence(d dialect.Dialect, name string, tbl *table) error {
+	if tbl.Persistence == "" {
+		return nil
+	}
+	if p, ok := d.(dialect.TablePersister); !ok || !p.SupportsPersistence(tbl.Persistence) {
+		return newError(ErrUnsupportedFeature, "migu: %s: persistence annotation %q is not supported by the dialect", name, tbl.Persistence)
+	}
+	return nil
+}
+
+// TemporaryTableSQLs returns SQLs to create the temporary tables that are declared by Go's structs with
+// persistence:"temporary" annotation. The filename and src parameters are treated in the same way as Diff.
+//
+// Diff and Sync do not create the temporary tables because they exist only in the session that creates them, so that
+// the application executes the returned SQLs in its own session instead.
+func TemporaryTableSQLs(d dialect.Dialect, filename string, src interface{}) ([]string, error) {
+	structMap, err := structTables(d, filename, src)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for name, tbl := range structMap {
+		if tbl.Persistence == dialect.PersistenceTemporary {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var sqls []string
+	for _, name := range names {
+		tbl := structMap[name]
+		if err := validatePersistence(d, name, tbl); err != nil {
+			return nil, err
+		}
+		sqls = append(sqls, d.CreateTableSQL(tbl.ToTable(name))...)
+		addIndexes, _ := makeIndexes(nil, tbl.Fields)
+		for _, index := range addIndexes {
+			sqls = append(sqls, d.CreateIndexSQL(index.ToIndex())...)
+		}
+	}
+	return sqls, nil
+}
@@ -0,0 +1,243 @@
+package migu
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/naoina/migu/dialect"
+)
+
+// Phase represents the phase of the expand-contract (a.k.a. blue/green) migration.
+type Phase string
+
+const (
+	// PhaseExpand is the phase of backwards-compatible changes such as additions and widenings.
+	// It should be applied before the rollout of the application.
+	PhaseExpand Phase = "expand"
+
+	// PhaseContract is the phase of backwards-incompatible changes such as drops and narrowings.
+	// It should be applied after the rollout of the application.
+	PhaseContract Phase = "contract"
+
+	// PhaseIndexes is the phase of the creations and the drops of the non-unique indexes.
+	// It is useful to schedule the index builds of the large tables separately from the other changes.
+	PhaseIndexes Phase = "indexes"
+
+	// PhaseConstraints is the phase of the changes of the constraints such as the primary keys, the unique indexes, the
+	// foreign keys and the CHECK constraints.
+	PhaseConstraints Phase = "constraints"
+)
+
+// Phase returns the phase of the expand-contract migration that the change belongs to.
+func (c *Change) Phase() Phase {
+	switch c.Kind {
+	case CreateTable, AddColumn, CreateIndex, ModifyUser, ModifyDatabase, ModifyCompression, DropForeignKey, DropCheck:
+		return PhaseExpand
+	case ModifyEncryption:
+		if c.Encrypted {
+			return PhaseExpand
+		}
+	case ModifyColumn:
+		if c.OldField != nil && c.NewField != nil && isWidening(*c.OldField, *c.NewField) {
+			return PhaseExpand
+		}
+	}
+	return PhaseContract
+}
+
+// IsDestructive reports whether the change loses the data, such as the drops of the tables and the columns, and the
+// changes of the column types to the narrower ones. The statements of the SQL scripts are regarded as destructive
+// because their kinds are unknown.
+func (c *Change) IsDestructive() bool {
+	switch c.Kind {
+	case DropTable, DropColumn, Statement:
+		return true
+	case ModifyColumn:
+		return c.OldField != nil && c.NewField != nil && !isWideningType(c.OldField.Type, c.NewField.Type)
+	}
+	return false
+}
+
+// InPhase reports whether the change belongs to the phase.
+// Unlike Phase, it also reports the object class phases such as PhaseIndexes and PhaseConstraints.
+func (c *Change) InPhase(phase Phase) bool {
+	switch phase {
+	case PhaseIndexes:
+		return (c.Kind == CreateIndex || c.Kind == DropIndex) && !c.Unique
+	case PhaseConstraints:
+		switch c.Kind {
+		case ModifyPrimaryKey, AddForeignKey, DropForeignKey, AddCheck, DropCheck:
+			return true
+		}
+		return (c.Kind == CreateIndex || c.Kind == DropIndex) && c.Unique
+	}
+	return c.Phase() == phase
+}
+
+// WithPhase returns only the changes that belong to the phase.
+func WithPhase(phase Phase) Option {
+	return func(o *option) {
+		o.phases = append(o.phases, phase)
+	}
+}
+
+// SplitPhases splits the changes into the expand phase and the contract phase.
+func SplitPhases(changes []*Change) (expand, contract []*Change) {
+	for _, c := range changes {
+		if c.Phase() == PhaseExpand {
+			expand = append(expand, c)
+		} else {
+			contract = append(contract, c)
+		}
+	}
+	return expand, contract
+}
+
+func filterPhases(changes []*Change, phases []Phase) []*Change {
+	if len(phases) == 0 {
+		return changes
+	}
+	var filtered []*Change
+	for _, c := range changes {
+		for _, p := range phases {
+			if c.InPhase(p) {
+				filtered = append(filtered, c)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// integerTypes is the list of integer types in ascending order of the range.
+var integerTypes = []string{"TINYINT", "SMALLINT", "MEDIUMINT", "INT", "BIGINT"}
+
+// textTypes is the list of text types in ascending order of the maximum length.
+var textTypes = []string{"CHAR", "VARCHAR", "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT"}
+
+// binaryTypes is the list of binary types in ascending order of the maximum length.
+var binaryTypes = []string{"BINARY", "VARBINARY", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB"}
+
+// defaultSizes is the sizes of the types that are used when the size is omitted, e.g. CHAR is CHAR(1) and DATETIME
+// is DATETIME(0).
+var defaultSizes = map[string]int{
+	"CHAR":      1,
+	"BINARY":    1,
+	"DATETIME":  0,
+	"TIME":      0,
+	"TIMESTAMP": 0,
+}
+
+// byteCapacities is the maximum length in bytes of the text and binary types that cannot have a size.
+var byteCapacities = map[string]int64{
+	"TINYTEXT":   1<<8 - 1,
+	"TEXT":       1<<16 - 1,
+	"MEDIUMTEXT": 1<<24 - 1,
+	"LONGTEXT":   1<<32 - 1,
+	"TINYBLOB":   1<<8 - 1,
+	"BLOB":       1<<16 - 1,
+	"MEDIUMBLOB": 1<<24 - 1,
+	"LONGBLOB":   1<<32 - 1,
+}
+
+// isWidening reports whether newField accepts all values that oldField accepts, so that the change does not break
+// the application that depends on oldField.
+func isWidening(oldField, newField dialect.Field) bool {
+	if oldField.Name != newField.Name {
+		return false
+	}
+	if oldField.Nullable && !newField.Nullable {
+		return false
+	}
+	if oldField.AutoIncrement != newField.AutoIncrement {
+		return false
+	}
+	return isWideningType(oldField.Type, newField.Type)
+}
+
+func isWideningType(oldType, newType string) bool {
+	oldType, newType = strings.ToUpper(oldType), strings.ToUpper(newType)
+	if oldType == newType {
+		return true
+	}
+	oldBase, oldSize, oldUnsigned := parseColumnType(oldType)
+	newBase, newSize, newUnsigned := parseColumnType(newType)
+	if oldUnsigned != newUnsigned {
+		return false
+	}
+	if oldBase == newBase {
+		// The size of an integer type is the display width, which doesn't limit the values.
+		return indexOf(integerTypes, oldBase) >= 0 || isWideningSize(oldBase, oldSize, newBase, newSize)
+	}
+	for _, types := range [][]string{integerTypes, textTypes, {"FLOAT", "DOUBLE"}, binaryTypes} {
+		o, n := indexOf(types, oldBase), indexOf(types, newBase)
+		if o < 0 || n < 0 {
+			continue
+		}
+		if _, ok := byteCapacities[oldBase]; n < o && !ok {
+			// A type that cannot have a size may be narrowed to the one that can, e.g. TINYTEXT to VARCHAR(255).
+			return false
+		}
+		return indexOf(integerTypes, oldBase) >= 0 || isWideningSize(oldBase, oldSize, newBase, newSize)
+	}
+	return false
+}
+
+// isWideningSize reports whether the type newBase of newSize can store all values of the type oldBase of oldSize.
+// The sizes of the types that cannot have a size are their capacities in bytes, and the size of the text type that
+// can have a size is the number of characters, each of which takes up to 4 bytes. The size is unbounded if it is
+// still -1 after the default size is applied, e.g. STRING(MAX).
+func isWideningSize(oldBase string, oldSize int, newBase string, newSize int) bool {
+	if size, ok := defaultSizes[oldBase]; ok && oldSize < 0 {
+		oldSize = size
+	}
+	if size, ok := defaultSizes[newBase]; ok && newSize < 0 {
+		newSize = size
+	}
+	oldBytes, oldCapped := byteCapacities[oldBase]
+	newBytes, newCapped := byteCapacities[newBase]
+	switch {
+	case oldCapped && newCapped:
+		return newBytes >= oldBytes
+	case oldCapped:
+		return newSize < 0 || int64(newSize) >= oldBytes
+	case newCapped:
+		charBytes := int64(1)
+		if indexOf(textTypes, oldBase) >= 0 {
+			charBytes = 4
+		}
+		return oldSize >= 0 && int64(oldSize)*charBytes <= newBytes
+	}
+	return newSize < 0 || (oldSize >= 0 && newSize >= oldSize)
+}
+
+// parseColumnType parses the column type such as "VARCHAR(255)", "STRING(MAX)" or "INT UNSIGNED".
+// The size is -1 if the type does not have a size or the size is MAX.
+func parseColumnType(typ string) (base string, size int, unsigned bool) {
+	if strings.HasSuffix(typ, " UNSIGNED") {
+		typ, unsigned = strings.TrimSuffix(typ, " UNSIGNED"), true
+	}
+	start, end := strings.IndexByte(typ, '('), strings.LastIndexByte(typ, ')')
+	if start < 0 || end < start {
+		return strings.TrimSpace(typ), -1, unsigned
+	}
+	base = strings.TrimSpace(typ[:start])
+	n, err := strconv.Atoi(strings.TrimSpace(typ[start+1 : end]))
+	if err != nil {
+		// e.g. DECIMAL(10,2) and STRING(MAX).
+		if strings.TrimSpace(typ[start+1:end]) == "MAX" {
+			return base, -1, unsigned
+		}
+		return typ, -1, unsigned
+	}
+	return base, n, unsigned
+}
+
+func indexOf(a []string, s string) int {
+	for i, v := range a {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,63 @@
+package migu
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// ReadPlan reads the changes to be applied from the plan of the run report that is written by WriteRunReport, or
+// from the SQL script such as the output of `migu diff`.
+// Each statement of the SQL script is returned as the change of Statement kind.
+// The src parameter is treated in the same way as Diff.
+func ReadPlan(filename string, src interface{}) ([]*Change, error) {
+	b, err := readSource(filename, src)
+	if err != nil {
+		return nil, err
+	}
+	if isRunReport(b) {
+		r, err := ReadRunReport(filename, b)
+		if err != nil {
+			return nil, err
+		}
+		return r.Changes(), nil
+	}
+	stmts, err := splitSQLScript(string(b))
+	if err != nil {
+		return nil, newError(ErrInvalidSource, "migu: %s: %w", filename, err)
+	}
+	changes := make([]*Change, len(stmts))
+	for i, stmt := range stmts {
+		changes[i] = &Change{
+			Kind: Statement,
+			SQLs: []string{stmt},
+		}
+	}
+	return changes, nil
+}
+
+// ReadRunReport reads the report that is written by WriteRunReport of the current or the previous FormatVersion.
+// The report is returned as of the current FormatVersion. The src parameter is treated in the same way as Diff.
+func ReadRunReport(filename string, src interface{}) (*RunReport, error) {
+	b, err := readSource(filename, src)
+	if err != nil {
+		return nil, err
+	}
+	if !isRunReport(b) {
+		return nil, newError(ErrInvalidSource, "migu: %s: not a plan", filename)
+	}
+	var r RunReport
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, newError(ErrInvalidSource, "migu: %s: invalid plan: %w", filename, err)
+	}
+	if err := checkFormatVersion(r.FormatVersion); err != nil {
+		return nil, newError(ErrInvalidSource, "migu: %s: %w", filename, err)
+	}
+	r.FormatVersion = FormatVersion
+	return &r, nil
+}
+
+// isRunReport reports whether b is the report in JSON rather than the SQL script.
+func isRunReport(b []byte) bool {
+	b = bytes.TrimSpace(b)
+	return len(b) > 0 && b[0] == '{'
+}
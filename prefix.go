@@ -0,0 +1,77 @@
+package migu
+
+import (
+	"strings"
+
+	"github.com/naoina/migu/dialect"
+)
+
+// WithTablePrefix prefixes the table names of Go's structs with prefix such as "app1_", and limits the tables in the
+// database to the ones whose names begin with prefix, so that the applications that share a database can manage
+// their own tables without touching the others.
+// FprintContext and FprintSQL strip prefix from the table names.
+func WithTablePrefix(prefix string) Option {
+	return func(o *option) {
+		o.tablePrefix = prefix
+	}
+}
+
+// prefixTables returns the tables of Go's structs with the table prefix, including the tables that are referenced by
+// the foreign keys.
+func (o *option) prefixTables(structMap map[string]*table) map[string]*table {
+	if o.tablePrefix == "" {
+		return structMap
+	}
+	m := make(map[string]*table, len(structMap))
+	for name, tbl := range structMap {
+		name = o.tablePrefix + name
+		for _, f := range tbl.Fields {
+			f.Table = name
+			if f.RefTable != "" {
+				f.RefTable = o.tablePrefix + f.RefTable
+			}
+		}
+		tbl.ForeignKeys = structForeignKeys(name, tbl.Fields)
+		tbl.Checks = structChecks(name, tbl)
+		m[name] = tbl
+	}
+	return m
+}
+
+// scopeTables returns the tables of the database whose names begin with the table prefix.
+func (o *option) scopeTables(tableMap map[string]*table) map[string]*table {
+	if o.tablePrefix == "" {
+		return tableMap
+	}
+	m := make(map[string]*table, len(tableMap))
+	for name, tbl := range tableMap {
+		if o.inScope(name) {
+			m[name] = tbl
+		}
+	}
+	return m
+}
+
+// inScope reports whether the table of the database is managed with the table prefix.
+func (o *option) inScope(name string) bool {
+	// The archived tables have the prefix after the archived table prefix.
+	if original, _, ok := parseArchivedTableName(name); ok {
+		name = original
+	}
+	return strings.HasPrefix(name, o.tablePrefix)
+}
+
+// unprefixTableMap returns the column schemas of the tables whose names begin with the table prefix, by the names
+// without it.
+func (o *option) unprefixTableMap(tableMap map[string][]dialect.ColumnSchema) map[string][]dialect.ColumnSchema {
+	if o.tablePrefix == "" {
+		return tableMap
+	}
+	m := make(map[string][]dialect.ColumnSchema, len(tableMap))
+	for name, columns := range tableMap {
+		if strings.HasPrefix(name, o.tablePrefix) {
+			m[strings.TrimPrefix(name, o.tablePrefix)] = columns
+		}
+	}
+	return m
+}
@@ -0,0 +1,76 @@
+package migu
+
+import (
+	"github.com/naoina/migu/dialect"
+)
+
+// primaryKeyChange returns the change to modify the primary keys of the table, or nil if the primary keys are not
+// changed or the dialect can neither modify them nor recreate the table.
+// It returns an error if the primary keys are changed unless WithAllowPrimaryKeyChange is specified.
+func primaryKeyChange(d dialect.Dialect, name string, oldTbl, newTbl *table, oldFields []*field, opt *option) (*Change, error) {
+	oldPks, newPks := makePrimaryKeyColumns(oldFields, newTbl.Fields)
+	if len(oldPks) == 0 && len(newPks) == 0 {
+		return nil, nil
+	}
+	modifier, canModify := d.(dialect.PrimaryKeyModifier)
+	recreator, canRecreate := d.(dialect.TableRecreator)
+	if !canModify && !canRecreate {
+		return nil, nil
+	}
+	if !opt.allowPKChange {
+		return nil, newError(ErrRefused, "migu: %s: refusing to change the primary key; use WithAllowPrimaryKeyChange to change it", name)
+	}
+	if err := checkAutoIncrementKey(name, newTbl.Fields); err != nil {
+		return nil, err
+	}
+	if c, ok := d.(dialect.PrimaryKeyChecker); ok && canModify && len(oldPks) > 0 {
+		if err := c.CheckModifyPrimaryKey(name); err != nil {
+			return nil, newError(ErrUnsupportedFeature, "migu: %s: %v", name, err)
+		}
+	}
+	if !canModify {
+		old := *oldTbl
+		old.Fields = oldFields
+		return &Change{
+			Kind:  ModifyPrimaryKey,
+			Table: name,
+			SQLs:  recreator.RecreateTableSQL(old.ToTable(name), newTbl.ToTable(name)),
+		}, nil
+	}
+	oldPrimaryKeyFields := make([]dialect.Field, len(oldPks))
+	for i, pk := range oldPks {
+		oldPrimaryKeyFields[i] = pk.ToField()
+	}
+	newPrimaryKeyFields := make([]dialect.Field, len(newPks))
+	for i, pk := range newPks {
+		newPrimaryKeyFields[i] = pk.ToField()
+	}
+	return &Change{
+		Kind:  ModifyPrimaryKey,
+		Table: name,
+		SQLs:  modifier.ModifyPrimaryKeySQL(oldPrimaryKeyFields, newPrimaryKeyFields),
+	}, nil
+}
+
+// checkAutoIncrementKey returns an error if the auto-increment column is neither a primary key nor indexed after the
+// primary keys are changed, because the databases such as MySQL require the auto-increment column to be a key.
+func checkAutoIncrementKey(name string, fields []*field) error {
+	for _, f := range fields {
+		if f.AutoIncrement && !f.PrimaryKey && len(f.RawIndexes) == 0 && len(f.RawUniques) == 0 {
+			return newError(ErrInvalidTag, "migu: %s.%s: the auto-increment column must be a primary key or have an index", name, f.Column)
+		}
+	}
+	return nil
+}
+
+// gainsAutoIncrement reports whether any of the primary key columns becomes auto-increment by the modification of
+// the column. The primary key must be changed before such modification, because the auto-increment column must be
+// a key.
+func gainsAutoIncrement(fields []modifiedField) bool {
+	for _, f := range fields {
+		if f.IsModified() && f.new.PrimaryKey && f.new.AutoIncrement && !f.old.AutoIncrement {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,231 @@
+package migu
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// registryEnvironmentsPath is the path of the API of the schema registry for the environments.
+const registryEnvironmentsPath = "/api/environments"
+
+// SchemaReport is the hashes of the tables in the database of an environment that are reported to the schema registry.
+// It is encoded in JSON by PostSchemaReport with FormatVersion.
+type SchemaReport struct {
+	FormatVersion int               `json:"formatVersion"`
+	Environment   string            `json:"environment"`
+	Database      string            `json:"database"`
+	Hashes        map[string]string `json:"hashes"`
+	ReportedAt    time.Time         `json:"reportedAt"`
+}
+
+// SchemaDrift is the differences between the tables of an environment and the ones of Go's structs.
+type SchemaDrift struct {
+	Environment string    `json:"environment"`
+	Database    string    `json:"database"`
+	ReportedAt  time.Time `json:"reportedAt"`
+
+	// Drifted reports whether any table is missing or modified. The extra tables do not drift, since they may not be
+	// managed by migu.
+	Drifted bool `json:"drifted"`
+
+	// Stale reports whether the environment has not reported for the StaleAfter of Registry.
+	Stale bool `json:"stale"`
+
+	Missing  []string `json:"missing"`
+	Modified []string `json:"modified"`
+	Extra    []string `json:"extra"`
+}
+
+// SchemaDrifts is the drifts of all environments that are served by the API of the schema registry in JSON.
+type SchemaDrifts struct {
+	FormatVersion int            `json:"formatVersion"`
+	Environments  []*SchemaDrift `json:"environments"`
+}
+
+// Registry is the schema registry that compares the hashes of the tables that are reported by the environments with
+// the ones of Go's structs. It serves the API and the dashboard of the drifts over HTTP as follows.
+//
+//	GET  /                            the dashboard in HTML
+//	GET  /api/environments            the drifts of all environments in JSON as SchemaDrifts
+//	POST /api/environments/NAME       report SchemaReport of the environment NAME in JSON
+type Registry struct {
+	// StaleAfter is the duration after which the environment that has not reported is stale. 0 means never.
+	StaleAfter time.Duration
+
+	mu       sync.Mutex
+	expected map[string]string
+	reports  map[string]*SchemaReport
+}
+
+// NewRegistry returns a new Registry that compares the reported hashes with expected, which are computed by
+// TableHashes.
+func NewRegistry(expected map[string]string) *Registry {
+	return &Registry{
+		expected: expected,
+		reports:  map[string]*SchemaReport{},
+	}
+}
+
+// Report records the report of the environment. The previous report of the environment is replaced.
+func (r *Registry) Report(report *SchemaReport) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reports[report.Environment] = report
+}
+
+// Drifts returns the drifts of the environments that have reported in the order of the names.
+func (r *Registry) Drifts() []*SchemaDrift {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	drifts := make([]*SchemaDrift, 0, len(r.reports))
+	for _, report := range r.reports {
+		drift := &SchemaDrift{
+			Environment: report.Environment,
+			Database:    report.Database,
+			ReportedAt:  report.ReportedAt,
+			Stale:       r.StaleAfter > 0 && now.Sub(report.ReportedAt) > r.StaleAfter,
+			Missing:     []string{},
+			Modified:    []string{},
+			Extra:       []string{},
+		}
+		for name, hash := range r.expected {
+			switch h, ok := report.Hashes[name]; {
+			case !ok:
+				drift.Missing = append(drift.Missing, name)
+			case h != hash:
+				drift.Modified = append(drift.Modified, name)
+			}
+		}
+		for name := range report.Hashes {
+			if _, ok := r.expected[name]; !ok {
+				drift.Extra = append(drift.Extra, name)
+			}
+		}
+		sort.Strings(drift.Missing)
+		sort.Strings(drift.Modified)
+		sort.Strings(drift.Extra)
+		drift.Drifted = len(drift.Missing) > 0 || len(drift.Modified) > 0
+		drifts = append(drifts, drift)
+	}
+	sort.Slice(drifts, func(i, j int) bool {
+		return drifts[i].Environment < drifts[j].Environment
+	})
+	return drifts
+}
+
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	switch path := req.URL.Path; {
+	case path == "/":
+		if req.Method != http.MethodGet {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := dashboardTemplate.Execute(w, r.Drifts()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	case path == registryEnvironmentsPath:
+		if req.Method != http.MethodGet {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(&SchemaDrifts{
+			FormatVersion: FormatVersion,
+			Environments:  r.Drifts(),
+		})
+	case strings.HasPrefix(path, registryEnvironmentsPath+"/"):
+		if req.Method != http.MethodPost {
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		name := strings.TrimPrefix(path, registryEnvironmentsPath+"/")
+		if name == "" || strings.Contains(name, "/") {
+			http.NotFound(w, req)
+			return
+		}
+		var report SchemaReport
+		if err := json.NewDecoder(req.Body).Decode(&report); err != nil {
+			http.Error(w, fmt.Sprintf("invalid report: %v", err), http.StatusBadRequest)
+			return
+		}
+		if err := checkFormatVersion(report.FormatVersion); err != nil {
+			http.Error(w, fmt.Sprintf("invalid report: %v", err), http.StatusBadRequest)
+			return
+		}
+		report.FormatVersion = FormatVersion
+		report.Environment = name
+		if report.ReportedAt.IsZero() {
+			report.ReportedAt = time.Now()
+		}
+		r.Report(&report)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+// PostSchemaReport reports the hashes of the tables of the environment to the schema registry at the base URL.
+func PostSchemaReport(url string, report *SchemaReport) error {
+	v := *report
+	v.FormatVersion = FormatVersion
+	b, err := json.Marshal(&v)
+	if err != nil {
+		return err
+	}
+	url = strings.TrimRight(url, "/") + registryEnvironmentsPath + "/" + report.Environment
+	resp, err := http.Post(url, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("migu: failed to report the schema to %s: %s: %s", url, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+var dashboardTemplate = template.Must(template.New("dashboard").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>migu schema registry</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+.drifted { color: #c00; }
+.stale { color: #888; }
+</style>
+</head>
+<body>
+<h1>migu schema registry</h1>
+<table>
+<tr><th>Environment</th><th>Database</th><th>Status</th><th>Reported at</th><th>Missing</th><th>Modified</th><th>Extra</th></tr>
+{{- range .}}
+<tr>
+<td>{{.Environment}}</td>
+<td>{{.Database}}</td>
+<td>{{if .Drifted}}<span class="drifted">drifted</span>{{else}}in sync{{end}}{{if .Stale}} <span class="stale">(stale)</span>{{end}}</td>
+<td>{{.ReportedAt.Format "2006-01-02T15:04:05Z07:00"}}</td>
+<td>{{range .Missing}}{{.}}<br>{{end}}</td>
+<td>{{range .Modified}}{{.}}<br>{{end}}</td>
+<td>{{range .Extra}}{{.}}<br>{{end}}</td>
+</tr>
+{{- else}}
+<tr><td colspan="7">No environments have reported yet.</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
@@ -0,0 +1,145 @@
+package migu
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"time"
+)
+
+// RunReport is the machine-readable report of a run of applying the changes for archiving by deployment systems.
+// It is encoded in JSON by WriteRunReport with FormatVersion.
+type RunReport struct {
+	FormatVersion int `json:"formatVersion"`
+
+	Command    string                `json:"command"`
+	Database   string                `json:"database"`
+	Commit     string                `json:"commit,omitempty"`
+	DryRun     bool                  `json:"dryRun"`
+	StartedAt  time.Time             `json:"startedAt"`
+	FinishedAt time.Time             `json:"finishedAt"`
+	Duration   float64               `json:"durationSeconds"`
+	Summary    map[ChangeKind]int    `json:"summary"`
+	Plan       []*RunReportChange    `json:"plan"`
+	Statements []*RunReportStatement `json:"statements"`
+	Warnings   []string              `json:"warnings"`
+	Error      string                `json:"error,omitempty"`
+	Signature  string                `json:"signature,omitempty"`
+}
+
+// RunReportChange is a change in the plan of RunReport.
+type RunReportChange struct {
+	Kind          ChangeKind `json:"kind"`
+	Table         string     `json:"table"`
+	NewName       string     `json:"newName,omitempty"`
+	Column        string     `json:"column,omitempty"`
+	Index         string     `json:"index,omitempty"`
+	Constraint    string     `json:"constraint,omitempty"`
+	User          string     `json:"user,omitempty"`
+	SQLs          []string   `json:"sqls"`
+	EstimatedRows int64      `json:"estimatedRows,omitempty"`
+}
+
+// RunReportStatement is an executed statement in RunReport.
+type RunReportStatement struct {
+	Kind      ChangeKind `json:"kind"`
+	Table     string     `json:"table"`
+	SQL       string     `json:"sql"`
+	StartedAt time.Time  `json:"startedAt"`
+	Duration  float64    `json:"durationSeconds"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// NewRunReport returns a new RunReport that is started at now.
+func NewRunReport(command, database string, dryRun bool) *RunReport {
+	return &RunReport{
+		FormatVersion: FormatVersion,
+		Command:       command,
+		Database:      database,
+		DryRun:        dryRun,
+		StartedAt:     time.Now(),
+		Summary:       map[ChangeKind]int{},
+		Plan:          []*RunReportChange{},
+		Statements:    []*RunReportStatement{},
+		Warnings:      []string{},
+	}
+}
+
+// SetPlan sets the changes to be applied to the report.
+func (r *RunReport) SetPlan(changes []*Change) {
+	r.Summary = map[ChangeKind]int{}
+	r.Plan = make([]*RunReportChange, len(changes))
+	for i, c := range changes {
+		r.Summary[c.Kind]++
+		r.Plan[i] = &RunReportChange{
+			Kind:          c.Kind,
+			Table:         c.Table,
+			NewName:       c.NewName,
+			Column:        c.Column,
+			Index:         c.Index,
+			Constraint:    c.Constraint,
+			User:          c.User,
+			SQLs:          c.SQLs,
+			EstimatedRows: c.EstimatedRows,
+		}
+	}
+}
+
+// Changes returns the changes in the plan of the report.
+func (r *RunReport) Changes() []*Change {
+	changes := make([]*Change, len(r.Plan))
+	for i, c := range r.Plan {
+		changes[i] = &Change{
+			Kind:          c.Kind,
+			Table:         c.Table,
+			NewName:       c.NewName,
+			Column:        c.Column,
+			Index:         c.Index,
+			Constraint:    c.Constraint,
+			User:          c.User,
+			SQLs:          c.SQLs,
+			EstimatedRows: c.EstimatedRows,
+		}
+	}
+	return changes
+}
+
+// AddStatement adds the statement of the change that is executed from start to the report.
+// err is the error of the execution, or nil if it succeeded.
+func (r *RunReport) AddStatement(c *Change, sql string, start time.Time, err error) {
+	s := &RunReportStatement{
+		Kind:      c.Kind,
+		Table:     c.Table,
+		SQL:       sql,
+		StartedAt: start,
+		Duration:  time.Since(start).Seconds(),
+	}
+	if err != nil {
+		s.Error = err.Error()
+	}
+	r.Statements = append(r.Statements, s)
+}
+
+// Warn adds the warning message to the report.
+func (r *RunReport) Warn(msg string) {
+	r.Warnings = append(r.Warnings, msg)
+}
+
+// Finish sets the end of the run to the report. err is the error of the run, or nil if it succeeded.
+func (r *RunReport) Finish(err error) {
+	r.FinishedAt = time.Now()
+	r.Duration = r.FinishedAt.Sub(r.StartedAt).Seconds()
+	if err != nil {
+		r.Error = err.Error()
+	}
+}
+
+// WriteRunReport writes the report to the file in JSON.
+func WriteRunReport(filename string, r *RunReport) error {
+	v := *r
+	v.FormatVersion = FormatVersion
+	b, err := json.MarshalIndent(&v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, append(b, '\n'), 0644)
+}
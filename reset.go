@@ -0,0 +1,103 @@
+package migu
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/naoina/migu/dialect"
+)
+
+// ResetChanges returns the changes to drop the tables and to re-create them from Go's structs.
+// The tables that are declared by Go's structs are dropped, and all tables in the database are dropped if all is
+// true.
+// With WithTablePrefix, only the tables whose names begin with the prefix are dropped even if all is true.
+// The filename and src parameters are treated in the same way as Diff.
+func ResetChanges(d dialect.Dialect, filename string, src interface{}, all bool, opts ...Option) ([]*Change, error) {
+	structMap, err := structTables(d, filename, src)
+	if err != nil {
+		return nil, err
+	}
+	opt := newOption(opts)
+	structMap = opt.prefixTables(structMap)
+	tableMap, err := getTableMap(d)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for name := range tableMap {
+		if !opt.inScope(name) {
+			continue
+		}
+		if _, ok := structMap[name]; ok || all {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	changes := make([]*Change, 0, len(names)+len(structMap))
+	for _, name := range names {
+		changes = append(changes, dropTableChange(d, name))
+	}
+	created, err := diffTables(d, map[string]*table{}, structMap, opt)
+	if err != nil {
+		return nil, err
+	}
+	return append(changes, created...), nil
+}
+
+// SeedStatements returns the SQL statements in the seed file that are separated by semicolons.
+// If src is not nil, SeedStatements reads the statements from src instead of the file.
+func SeedStatements(filename string, src interface{}) ([]string, error) {
+	b, err := readSource(filename, src)
+	if err != nil {
+		return nil, err
+	}
+	stmts, err := splitSQLScript(string(b))
+	if err != nil {
+		return nil, newError(ErrInvalidSource, "migu: %s: %w", filename, err)
+	}
+	return stmts, nil
+}
+
+// splitSQLScript splits s into the statements by semicolons outside of the quoted strings and the comments.
+// The statements are returned as they are except the surrounding spaces, and the statements that have only comments
+// are omitted.
+func splitSQLScript(s string) ([]string, error) {
+	var stmts []string
+	start := 0
+	hasCode := false
+	add := func(end int) {
+		if stmt := strings.TrimSpace(s[start:end]); hasCode {
+			stmts = append(stmts, stmt)
+		}
+		start, hasCode = end+1, false
+	}
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == ';':
+			add(i)
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+		case c == '\'' || c == '"' || c == '`':
+			hasCode = true
+			_, n, err := unquoteSQL(s[i:])
+			if err != nil {
+				return nil, err
+			}
+			i += n - 1
+		case c == '#' || strings.HasPrefix(s[i:], "-- ") || strings.HasPrefix(s[i:], "--\n"):
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(s[i:], "/*"):
+			end := strings.Index(s[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("comment not terminated")
+			}
+			i += end + 3
+		default:
+			hasCode = true
+		}
+	}
+	add(len(s))
+	return stmts, nil
+}
@@ -0,0 +1,296 @@
+package migu
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/naoina/migu/dialect"
+)
+
+// maxCharWidth is the maximum number of bytes of a character of utf8mb4, which is the default character set.
+const maxCharWidth = 4
+
+// maxRowSizeSuggestions is the maximum number of the columns that are suggested to be converted to TEXT.
+const maxRowSizeSuggestions = 3
+
+// charWidths is the maximum number of bytes of a character of each column of a table.
+type charWidths struct {
+	// table is the width of the default character set of the table.
+	table int
+
+	// columns are the widths of the columns that declare their own character sets.
+	columns map[string]int
+}
+
+// column returns the maximum number of bytes of a character of the column.
+func (w charWidths) column(name string) int {
+	if n, ok := w.columns[name]; ok {
+		return n
+	}
+	return w.table
+}
+
+// charWidth returns the maximum number of bytes of a character of each column of the table.
+func charWidth(d dialect.Dialect, name string, tbl *table) charWidths {
+	if l, ok := d.(dialect.RowSizeLimiter); ok {
+		return columnCharWidths(d, tbl, l.RowSizeLimit(tbl.ToTable(name)).CharWidth)
+	}
+	return columnCharWidths(d, tbl, maxCharWidth)
+}
+
+// columnCharWidths returns the widths of the columns of the table whose default is width. The columns that declare
+// `charset` or `collation` tag have the width of the character set if it is known by the dialect.
+func columnCharWidths(d dialect.Dialect, tbl *table, width int) charWidths {
+	w := charWidths{table: width}
+	c, ok := d.(dialect.CharsetConverter)
+	if !ok {
+		return w
+	}
+	for _, f := range tbl.Fields {
+		charset := f.Charset
+		if charset == "" {
+			// The name of the collation starts with the name of its character set. (e.g. utf8mb4_bin)
+			charset = strings.SplitN(f.Collation, "_", 2)[0]
+		}
+		if n := c.CharWidth(charset); n > 0 {
+			if w.columns == nil {
+				w.columns = map[string]int{}
+			}
+			w.columns[f.Column] = n
+		}
+	}
+	return w
+}
+
+// checkRowSize returns an error if the estimated maximum size of a row of the table exceeds the limits of the
+// dialect, so that it fails before the server rejects the DDL with "Row size too large".
+func checkRowSize(d dialect.Dialect, name string, tbl *table) error {
+	l, ok := d.(dialect.RowSizeLimiter)
+	if !ok {
+		return nil
+	}
+	limit := l.RowSizeLimit(tbl.ToTable(name))
+	widths := columnCharWidths(d, tbl, limit.CharWidth)
+	if size := estimateRowSize(tbl, widths); size > limit.MaxRowSize {
+		return newError(ErrLimitExceeded, "migu: %s: estimated row size %d bytes exceeds the maximum row size %d bytes%s",
+			name, size, limit.MaxRowSize, rowSizeSuggestion(tbl, widths))
+	}
+	if limit.MaxInlineRowSize <= 0 {
+		return nil
+	}
+	if size := estimateInlineRowSize(tbl, limit, widths); size > limit.MaxInlineRowSize {
+		return newError(ErrLimitExceeded, "migu: %s: estimated in-page row size %d bytes exceeds the limit of the row format %d bytes%s",
+			name, size, limit.MaxInlineRowSize, rowSizeSuggestion(tbl, widths))
+	}
+	return nil
+}
+
+// checkIndexKeySize returns an error if the key of any index exceeds the maximum key size of the dialect, with the
+// exact numbers and the length of the columns to fit in it.
+func checkIndexKeySize(d dialect.Dialect, name string, tbl *table, indexes []*index) error {
+	l, ok := d.(dialect.RowSizeLimiter)
+	if !ok || len(indexes) == 0 {
+		return nil
+	}
+	limit := l.RowSizeLimit(tbl.ToTable(name))
+	if limit.MaxIndexKeySize <= 0 {
+		return nil
+	}
+	types := make(map[string]string, len(tbl.Fields))
+	for _, f := range tbl.Fields {
+		types[f.Column] = f.Type
+	}
+	widths := columnCharWidths(d, tbl, limit.CharWidth)
+	var problems []string
+	for _, index := range indexes {
+		columnTypes := make([]string, len(index.Columns))
+		columnWidths := make([]int, len(index.Columns))
+		for i, column := range index.Columns {
+			columnTypes[i] = types[column]
+			columnWidths[i] = widths.column(column)
+		}
+		if p := indexKeyProblem(index.ToIndex(), columnTypes, columnWidths, limit.MaxIndexKeySize); p != "" {
+			problems = append(problems, p)
+		}
+	}
+	if len(problems) > 0 {
+		return newError(ErrLimitExceeded, "migu: the indexes exceed the maximum key size:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// rowSizeSuggestion returns the suggestion to convert the largest string columns to TEXT or BLOB.
+func rowSizeSuggestion(tbl *table, widths charWidths) string {
+	type column struct {
+		f    *field
+		size int64
+	}
+	var columns []column
+	for _, f := range tbl.Fields {
+		switch typeBase(f.Type) {
+		case "CHAR", "VARCHAR", "BINARY", "VARBINARY":
+			columns = append(columns, column{f: f, size: columnSize(f.Type, widths.column(f.Column))})
+		}
+	}
+	if len(columns) == 0 {
+		return ""
+	}
+	sort.SliceStable(columns, func(i, j int) bool {
+		return columns[i].size > columns[j].size
+	})
+	if len(columns) > maxRowSizeSuggestions {
+		columns = columns[:maxRowSizeSuggestions]
+	}
+	suggestions := make([]string, len(columns))
+	for i, c := range columns {
+		suggestions[i] = fmt.Sprintf("%s %s (%d bytes)", c.f.Column, c.f.Type, c.size)
+	}
+	return "; consider converting the large columns to TEXT or BLOB: " + strings.Join(suggestions, ", ")
+}
+
+// estimateRowSize returns the estimated maximum size in bytes of a row of the table, in the same way as MySQL counts
+// it against the maximum row size (65,535 bytes). The TEXT and the BLOB types are counted as the pointers because
+// they are stored separately from the row.
+func estimateRowSize(tbl *table, widths charWidths) int64 {
+	var size int64
+	var nullables int64
+	for _, f := range tbl.Fields {
+		size += columnSize(f.Type, widths.column(f.Column))
+		if f.Nullable {
+			nullables++
+		}
+	}
+	return size + (nullables+7)/8
+}
+
+// estimateInlineRowSize returns the estimated maximum size in bytes of the part of a row that is stored in the page.
+// The long variable-length columns are counted as the part that remains in the page when they are stored off-page.
+func estimateInlineRowSize(tbl *table, limit dialect.RowSizeLimit, widths charWidths) int64 {
+	var size int64
+	var nullables int64
+	for _, f := range tbl.Fields {
+		n := columnSize(f.Type, widths.column(f.Column))
+		switch typeBase(f.Type) {
+		case "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB", "JSON":
+			n = limit.MaxInlineColumnSize
+		case "VARCHAR", "VARBINARY":
+			// The columns that may be longer than 255 bytes can be stored off-page.
+			if n > 255+2 && n > limit.MaxInlineColumnSize {
+				n = limit.MaxInlineColumnSize
+			}
+		default:
+			if isSpatialType(f.Type) {
+				n = limit.MaxInlineColumnSize
+			}
+		}
+		size += n
+		if f.Nullable {
+			nullables++
+		}
+	}
+	return size + (nullables+7)/8
+}
+
+// columnSize returns the maximum size in bytes of the value of the column type in a row, or 0 if it is unknown.
+func columnSize(typ string, charWidth int) int64 {
+	typ = strings.ToUpper(typ)
+	base := typeBase(typ)
+	if isSpatialType(base) {
+		// The spatial values are stored as LONGBLOB.
+		return 12
+	}
+	_, n, _ := parseColumnType(typ)
+	size := int64(n)
+	switch base {
+	case "TINYINT", "BOOL", "BOOLEAN", "YEAR":
+		return 1
+	case "SMALLINT":
+		return 2
+	case "MEDIUMINT", "DATE":
+		return 3
+	case "INT", "INTEGER", "FLOAT":
+		return 4
+	case "BIGINT", "INT64", "DOUBLE", "REAL", "FLOAT64":
+		return 8
+	case "DECIMAL", "NUMERIC":
+		return decimalSize(typ)
+	case "TIME":
+		return 3 + fractionalSecondsSize(size)
+	case "DATETIME":
+		return 5 + fractionalSecondsSize(size)
+	case "TIMESTAMP":
+		return 4 + fractionalSecondsSize(size)
+	case "CHAR":
+		if size < 0 {
+			size = 1
+		}
+		return size * int64(charWidth)
+	case "BINARY":
+		if size < 0 {
+			size = 1
+		}
+		return size
+	case "VARCHAR", "STRING":
+		if size < 0 {
+			return 0
+		}
+		return lengthPrefixed(size * int64(charWidth))
+	case "VARBINARY", "BYTES":
+		if size < 0 {
+			return 0
+		}
+		return lengthPrefixed(size)
+	case "TINYTEXT", "TINYBLOB":
+		return 9
+	case "TEXT", "BLOB":
+		return 10
+	case "MEDIUMTEXT", "MEDIUMBLOB":
+		return 11
+	case "LONGTEXT", "LONGBLOB", "JSON":
+		return 12
+	case "ENUM":
+		return 2
+	case "SET":
+		return 8
+	}
+	return 0
+}
+
+// lengthPrefixed returns the size of the variable-length value of n bytes with the length prefix.
+func lengthPrefixed(n int64) int64 {
+	if n > 255 {
+		return n + 2
+	}
+	return n + 1
+}
+
+// fractionalSecondsSize returns the size of the fractional seconds part of the fsp digits.
+func fractionalSecondsSize(fsp int64) int64 {
+	if fsp <= 0 {
+		return 0
+	}
+	return (fsp + 1) / 2
+}
+
+// decimalSize returns the size of DECIMAL(M,D) that is packed 9 digits into 4 bytes.
+func decimalSize(typ string) int64 {
+	precision, scale := 10, 0
+	if start, end := strings.IndexByte(typ, '('), strings.LastIndexByte(typ, ')'); start >= 0 && end > start {
+		ps := strings.Split(typ[start+1:end], ",")
+		if p, err := strconv.Atoi(strings.TrimSpace(ps[0])); err == nil {
+			precision = p
+		}
+		if len(ps) > 1 {
+			if s, err := strconv.Atoi(strings.TrimSpace(ps[1])); err == nil {
+				scale = s
+			}
+		}
+	}
+	digits := func(n int) int64 {
+		leftover := []int64{0, 1, 1, 2, 2, 3, 3, 4, 4, 4}
+		return int64(n/9*4) + leftover[n%9]
+	}
+	return digits(precision-scale) + digits(scale)
+}
@@ -0,0 +1,115 @@
+package migu
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/naoina/migu/dialect"
+)
+
+// WithTags limits the tables to the ones of Go's structs that are tagged with any of tags by `tags` annotation, and
+// the tables in the database with the same names. The other tables are neither created, modified nor dropped.
+func WithTags(tags ...string) Option {
+	return func(o *option) {
+		o.tags = append(o.tags, tags...)
+	}
+}
+
+// WithTables limits the tables in the database that are written by Fprint, FprintContext and FprintSQL to names.
+// The names are without the table prefix.
+func WithTables(names ...string) Option {
+	return func(o *option) {
+		if o.tables == nil {
+			o.tables = make(map[string]bool, len(names))
+		}
+		for _, name := range names {
+			o.tables[name] = true
+		}
+	}
+}
+
+// TaggedTables returns the names of the tables of Go's structs that are tagged with any of tags by `tags` annotation
+// in sorted order. The filename and src parameters are treated in the same way as Diff.
+func TaggedTables(d dialect.Dialect, filename string, src interface{}, tags ...string) ([]string, error) {
+	structMap, err := structTables(d, filename, src)
+	if err != nil {
+		return nil, err
+	}
+	opt := newOption([]Option{WithTags(tags...)})
+	names := []string{}
+	for name := range opt.taggedTables(structMap) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// parseTagsAnnotation parses the value of `tags` annotation in the form of comma-separated tags.
+func parseTagsAnnotation(s string) ([]string, error) {
+	tags := strings.Split(s, ",")
+	for i, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || strings.ContainsAny(tag, " \t\r\n") {
+			return nil, newError(ErrInvalidAnnotation, "migu: invalid tags annotation: %q (must be comma-separated tags)", s)
+		}
+		tags[i] = tag
+	}
+	return tags, nil
+}
+
+// taggedTables returns the names of the tables of structMaps that are tagged with any of the tags of WithTags, or nil
+// unless WithTags is specified.
+func (o *option) taggedTables(structMaps ...map[string]*table) map[string]bool {
+	if len(o.tags) == 0 {
+		return nil
+	}
+	selected := map[string]bool{}
+	for _, structMap := range structMaps {
+		for name, tbl := range structMap {
+			if hasAnyTag(tbl.Tags, o.tags) {
+				selected[name] = true
+			}
+		}
+	}
+	return selected
+}
+
+func hasAnyTag(tags, want []string) bool {
+	for _, tag := range tags {
+		for _, w := range want {
+			if tag == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// selectTables returns the tables of tableMap whose names are selected, or tableMap as is if selected is nil.
+func selectTables(tableMap map[string]*table, selected map[string]bool) map[string]*table {
+	if selected == nil {
+		return tableMap
+	}
+	m := make(map[string]*table, len(selected))
+	for name, tbl := range tableMap {
+		if selected[name] {
+			m[name] = tbl
+		}
+	}
+	return m
+}
+
+// limitTableMap returns the column schemas of the tables of WithTables, or tableMap as is unless WithTables is
+// specified.
+func (o *option) limitTableMap(tableMap map[string][]dialect.ColumnSchema) map[string][]dialect.ColumnSchema {
+	if o.tables == nil {
+		return tableMap
+	}
+	m := make(map[string][]dialect.ColumnSchema, len(o.tables))
+	for name, columns := range tableMap {
+		if o.tables[name] {
+			m[name] = columns
+		}
+	}
+	return m
+}
@@ -0,0 +1,75 @@
+package migu
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+const signaturePrefix = "hmac-sha256:"
+
+var (
+	// ErrUnsignedPlan is returned by VerifyPlan if the plan has no signature.
+	ErrUnsignedPlan error = &Error{Code: "E109", Err: errors.New("migu: the plan is not signed")}
+
+	// ErrInvalidSignature is returned by VerifyPlan if the plan has been changed after signing or is signed by
+	// another key.
+	ErrInvalidSignature error = &Error{Code: "E110", Err: errors.New("migu: the signature of the plan is invalid")}
+)
+
+// SignPlan signs the plan and the commit of the report with the project key.
+// The signature is stored in the report, so that VerifyPlan detects the changes of them after signing.
+func SignPlan(r *RunReport, key []byte) error {
+	sig, err := planSignature(r, key)
+	if err != nil {
+		return err
+	}
+	r.Signature = signaturePrefix + hex.EncodeToString(sig)
+	return nil
+}
+
+// VerifyPlan verifies the signature of the report by SignPlan with the project key.
+func VerifyPlan(r *RunReport, key []byte) error {
+	if r.Signature == "" {
+		return ErrUnsignedPlan
+	}
+	if !strings.HasPrefix(r.Signature, signaturePrefix) {
+		return ErrInvalidSignature
+	}
+	actual, err := hex.DecodeString(strings.TrimPrefix(r.Signature, signaturePrefix))
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	expect, err := planSignature(r, key)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal(actual, expect) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// planSignature returns the signature of the commit and the plan of the report.
+// The other fields are not signed because the same plan is applied to the multiple databases.
+func planSignature(r *RunReport, key []byte) ([]byte, error) {
+	if len(key) == 0 {
+		return nil, errors.New("migu: the key to sign the plan is empty")
+	}
+	b, err := json.Marshal(struct {
+		Commit string             `json:"commit"`
+		Plan   []*RunReportChange `json:"plan"`
+	}{
+		Commit: r.Commit,
+		Plan:   r.Plan,
+	})
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(b)
+	return mac.Sum(nil), nil
+}
@@ -0,0 +1,121 @@
+package migu
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/naoina/migu/dialect"
+)
+
+const skeemaExt = ".sql"
+
+// ImportSkeema generates Go's structs from the Skeema-style schema directory and writes to output.
+// The directory has a .sql file for each table that contains the CREATE TABLE statement of MySQL.
+func ImportSkeema(output io.Writer, d dialect.Dialect, dir string) error {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	tableMap := map[string][]dialect.ColumnSchema{}
+	for _, info := range infos {
+		if info.IsDir() || filepath.Ext(info.Name()) != skeemaExt {
+			continue
+		}
+		m, err := parseCreateTables(filepath.Join(dir, info.Name()), nil)
+		if err != nil {
+			return err
+		}
+		for name, columns := range m {
+			tableMap[name] = columns
+		}
+	}
+	return fprintTableMap(output, d, tableMap)
+}
+
+// ExportSkeema writes the tables that are declared by Go's structs into the directory as the Skeema-style schema files.
+// The filename and src parameters are treated in the same way as Diff.
+// It returns the filenames that are written.
+func ExportSkeema(d dialect.Dialect, dir string, filename string, src interface{}) ([]string, error) {
+	structMap, err := structTables(d, filename, src)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(structMap))
+	for name := range structMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	filenames := make([]string, 0, len(names))
+	for _, name := range names {
+		fname := filepath.Join(dir, name+skeemaExt)
+		if err := ioutil.WriteFile(fname, []byte(skeemaCreateTableSQL(d, name, structMap[name])+";\n"), 0644); err != nil {
+			return nil, err
+		}
+		filenames = append(filenames, fname)
+	}
+	return filenames, nil
+}
+
+// skeemaCreateTableSQL returns the CREATE TABLE statement that has the index definitions in itself
+// because Skeema allows only a CREATE TABLE statement in a file. The index definitions are added into the first
+// statement that is CREATE TABLE, and the following statements are left as they are.
+func skeemaCreateTableSQL(d dialect.Dialect, name string, tbl *table) string {
+	queries := d.CreateTableSQL(tbl.ToTable(name))
+	addIndexes, _ := makeIndexes(nil, tbl.Fields)
+	if len(addIndexes) == 0 || len(queries) == 0 {
+		return strings.Join(queries, ";\n")
+	}
+	keys := make([]string, len(addIndexes))
+	for i, index := range addIndexes {
+		columns := make([]string, len(index.Columns))
+		for i, c := range index.Columns {
+			columns[i] = d.Quote(c)
+		}
+		key := fmt.Sprintf("KEY %s (%s)", d.Quote(index.Name), strings.Join(columns, ","))
+		if index.Unique {
+			key = "UNIQUE " + key
+		}
+		keys[i] = key
+	}
+	query := queries[0]
+	if i := tableDefinitionEnd(query); i > 0 {
+		if query[i-1] == '\n' {
+			i--
+		}
+		queries[0] = query[:i] + ",\n  " + strings.Join(keys, ",\n  ") + query[i:]
+	}
+	return strings.Join(queries, ";\n")
+}
+
+// tableDefinitionEnd returns the index of the parenthesis that closes the definitions of the columns of the CREATE
+// TABLE statement, or -1 if not found. The parentheses in the quoted identifiers and strings are skipped.
+func tableDefinitionEnd(query string) int {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '`' || c == '"' || c == '\'':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
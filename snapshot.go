@@ -0,0 +1,66 @@
+package migu
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/naoina/migu/dialect"
+)
+
+const (
+	snapshotPackage    = "package snapshot\n\n"
+	snapshotTimeLayout = "20060102T150405Z"
+	snapshotExt        = ".go"
+)
+
+// WriteSnapshot writes the current schema of the database into the directory as a snapshot taken at t.
+// A snapshot is the Go's structs that is output by Fprint, so that it can be used in place of the model files.
+// It returns the filename of the snapshot.
+func WriteSnapshot(d dialect.Dialect, dir string, t time.Time) (string, error) {
+	var buf bytes.Buffer
+	buf.WriteString(snapshotPackage)
+	if err := Fprint(&buf, d); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	filename := filepath.Join(dir, t.UTC().Format(snapshotTimeLayout)+snapshotExt)
+	if err := ioutil.WriteFile(filename, buf.Bytes(), 0644); err != nil {
+		return "", err
+	}
+	return filename, nil
+}
+
+// FindSnapshot returns the filename of the latest snapshot in the directory that was taken at or before t.
+func FindSnapshot(dir string, t time.Time) (string, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	var names []string
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() || !strings.HasSuffix(name, snapshotExt) {
+			continue
+		}
+		taken, err := time.Parse(snapshotTimeLayout, strings.TrimSuffix(name, snapshotExt))
+		if err != nil {
+			continue
+		}
+		if !taken.After(t) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "", fmt.Errorf("migu: no snapshot found at or before %v in %s", t.UTC().Format(time.RFC3339), dir)
+	}
+	sort.Strings(names)
+	return filepath.Join(dir, names[len(names)-1]), nil
+}
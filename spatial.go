@@ -0,0 +1,72 @@
+package migu
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"strconv"
+
+	"github.com/naoina/migu/dialect"
+)
+
+func isSpatialType(typ string) bool {
+	return dialect.SpatialTypes[typeBase(typ)]
+}
+
+// spatialColumnType returns the column type of the field whose values are restricted to the spatial reference system
+// of `srid` struct field tag.
+func spatialColumnType(d dialect.Dialect, f *field) (string, error) {
+	t, ok := d.(dialect.SpatialTyper)
+	if !ok {
+		return "", newError(ErrUnsupportedFeature, "migu: %s: `srid` tag is not supported by the dialect", f.Column)
+	}
+	srid, err := strconv.ParseUint(f.SRID, 10, 32)
+	if err != nil {
+		return "", newError(ErrInvalidTag, "migu: %s: `srid` tag must be the ID of the spatial reference system: %s", f.Column, f.SRID)
+	}
+	typ, ok := t.SpatialColumnType(f.Type, uint32(srid))
+	if !ok {
+		return "", newError(ErrInvalidTag, "migu: %s: `srid` tag is specified to the column of non-spatial type %s", f.Column, f.Type)
+	}
+	return typ, nil
+}
+
+// fakeGeometry returns the SQL of the geometry of the spatial column type. The coordinates are between 0 and 10, so
+// that they are valid as the latitudes and the longitudes of the geographic spatial reference systems.
+func fakeGeometry(r *rand.Rand, f *field) string {
+	x, y := r.Intn(10), r.Intn(10)
+	point := fmt.Sprintf("%d %d", x, y)
+	line := fmt.Sprintf("%d %d, %d %d", x, y, x+1, y+1)
+	ring := fmt.Sprintf("(%d %d, %d %d, %d %d, %d %d)", x, y, x+1, y, x+1, y+1, x, y)
+	var wkt string
+	switch typeBase(f.Type) {
+	case "LINESTRING":
+		wkt = "LINESTRING(" + line + ")"
+	case "POLYGON":
+		wkt = "POLYGON(" + ring + ")"
+	case "MULTIPOINT":
+		wkt = "MULTIPOINT((" + point + "))"
+	case "MULTILINESTRING":
+		wkt = "MULTILINESTRING((" + line + "))"
+	case "MULTIPOLYGON":
+		wkt = "MULTIPOLYGON((" + ring + "))"
+	case "GEOMETRYCOLLECTION", "GEOMCOLLECTION":
+		wkt = "GEOMETRYCOLLECTION(POINT(" + point + "))"
+	default:
+		wkt = "POINT(" + point + ")"
+	}
+	srid := f.SRID
+	if srid == "" {
+		srid = "0"
+	}
+	return fmt.Sprintf("ST_GeomFromText('%s', %s)", wkt, srid)
+}
+
+// geometryLiteral returns the SQL of the geometry of the value in the internal format of MySQL, which is the SRID in
+// little-endian 4 bytes followed by WKB.
+func geometryLiteral(v string) (string, error) {
+	if len(v) < 4 {
+		return "", fmt.Errorf("invalid geometry value of %d bytes", len(v))
+	}
+	return fmt.Sprintf("ST_GeomFromWKB(X'%X', %d)", v[4:], binary.LittleEndian.Uint32([]byte(v[:4]))), nil
+}
@@ -0,0 +1,168 @@
+package migu
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/naoina/migu/dialect"
+)
+
+// SuggestionAction represents the action of the index suggestion.
+type SuggestionAction string
+
+const (
+	SuggestAddIndex  SuggestionAction = "add"
+	SuggestDropIndex SuggestionAction = "drop"
+)
+
+// IndexSuggestion represents an advisory suggestion about the index.
+// Suggestions are never applied automatically.
+type IndexSuggestion struct {
+	Action  SuggestionAction
+	Table   string
+	Index   string
+	Columns []string
+	Reason  string
+}
+
+func (s *IndexSuggestion) String() string {
+	if s.Index != "" {
+		return fmt.Sprintf("%s index %s on %s (%s): %s", s.Action, s.Index, s.Table, strings.Join(s.Columns, ", "), s.Reason)
+	}
+	return fmt.Sprintf("%s index on %s (%s): %s", s.Action, s.Table, strings.Join(s.Columns, ", "), s.Reason)
+}
+
+var (
+	queryTableRegexp  = regexp.MustCompile("(?i)\\b(?:FROM|JOIN|UPDATE|INTO)\\s+`?(\\w+)`?")
+	queryColumnRegexp = regexp.MustCompile("(?i)\\b(?:WHERE|AND|OR|ON|BY)\\s+(?:`?\\w+`?\\.)?`?(\\w+)`?\\s*(?:=|<=|>=|<|>|\\bIN\\b|\\bBETWEEN\\b|\\bLIKE\\b|,|$|\\bASC\\b|\\bDESC\\b|\\bLIMIT\\b)")
+)
+
+// SuggestIndexes compares the indexes declared by Go's structs with the statistics of the database, and returns
+// suggestions for additions and removals of indexes.
+// The queries are additional query texts (e.g. digests of the slow query log) to find the missing indexes.
+// The dialect must implement dialect.IndexAdvisor to use the statistics of the database.
+func SuggestIndexes(d dialect.Dialect, filename string, src interface{}, queries []string) ([]*IndexSuggestion, error) {
+	structMap, err := structTables(d, filename, src)
+	if err != nil {
+		return nil, err
+	}
+	var suggestions []*IndexSuggestion
+	if advisor, ok := d.(dialect.IndexAdvisor); ok {
+		unused, err := advisor.UnusedIndexes()
+		if err != nil {
+			return nil, err
+		}
+		for _, index := range unused {
+			tbl := structMap[index.Table]
+			if tbl == nil {
+				continue
+			}
+			columns := tbl.indexColumns(index.Name)
+			if len(columns) == 0 {
+				continue
+			}
+			suggestions = append(suggestions, &IndexSuggestion{
+				Action:  SuggestDropIndex,
+				Table:   index.Table,
+				Index:   index.Name,
+				Columns: columns,
+				Reason:  "the index has not been used since the server started",
+			})
+		}
+		serverQueries, err := advisor.NoIndexQueries()
+		if err != nil {
+			return nil, err
+		}
+		queries = append(serverQueries, queries...)
+	}
+	seen := map[string]struct{}{}
+	for _, query := range queries {
+		for _, s := range suggestMissingIndexes(structMap, query) {
+			key := s.Table + "." + s.Columns[0]
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			suggestions = append(suggestions, s)
+		}
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Table < suggestions[j].Table
+	})
+	return suggestions, nil
+}
+
+// suggestMissingIndexes returns the suggestions for the columns that are used for filtering or sorting in the query
+// but are not a leading column of any index.
+func suggestMissingIndexes(structMap map[string]*table, query string) []*IndexSuggestion {
+	var tables []string
+	for _, m := range queryTableRegexp.FindAllStringSubmatch(query, -1) {
+		if _, ok := structMap[m[1]]; ok && !inStrings(tables, m[1]) {
+			tables = append(tables, m[1])
+		}
+	}
+	var suggestions []*IndexSuggestion
+	for _, m := range queryColumnRegexp.FindAllStringSubmatch(query, -1) {
+		column := m[1]
+		for _, name := range tables {
+			tbl := structMap[name]
+			if !tbl.hasColumn(column) || tbl.isLeadingIndexColumn(column) {
+				continue
+			}
+			suggestions = append(suggestions, &IndexSuggestion{
+				Action:  SuggestAddIndex,
+				Table:   name,
+				Columns: []string{column},
+				Reason:  fmt.Sprintf("the column is used without an index in the query: %s", query),
+			})
+		}
+	}
+	return suggestions
+}
+
+func (t *table) hasColumn(column string) bool {
+	for _, f := range t.Fields {
+		if f.Column == column {
+			return true
+		}
+	}
+	return false
+}
+
+// indexColumns returns the columns of the index declared in the table.
+func (t *table) indexColumns(name string) []string {
+	var columns []string
+	for _, f := range t.Fields {
+		if inStrings(f.Indexes(), name) || inStrings(f.UniqueIndexes(), name) {
+			columns = append(columns, f.Column)
+		}
+	}
+	return columns
+}
+
+// isLeadingIndexColumn reports whether the column is the first column of the primary key or any index.
+func (t *table) isLeadingIndexColumn(column string) bool {
+	leading := map[string]struct{}{}
+	for _, f := range t.Fields {
+		if f.PrimaryKey {
+			if _, ok := leading["PRIMARY"]; !ok {
+				leading["PRIMARY"] = struct{}{}
+				if f.Column == column {
+					return true
+				}
+			}
+		}
+		for _, name := range append(f.Indexes(), f.UniqueIndexes()...) {
+			if _, ok := leading[name]; ok {
+				continue
+			}
+			leading[name] = struct{}{}
+			if f.Column == column {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package migu
+
+import (
+	"strings"
+)
+
+// statementTagPrefix is the head of the comment that is prepended by TagStatement.
+const statementTagPrefix = "migu"
+
+// StatementTag is the application metadata that is prepended to the executed statements as a comment.
+// It helps to trace the statements in the server logs back to the deploy.
+type StatementTag struct {
+	Key   string
+	Value string
+}
+
+func (t StatementTag) String() string {
+	if t.Value == "" {
+		return t.Key
+	}
+	return t.Key + "=" + t.Value
+}
+
+// ParseStatementTag parses s in the form of "key=value" or "key".
+func ParseStatementTag(s string) (StatementTag, error) {
+	kv := strings.SplitN(s, "=", 2)
+	tag := StatementTag{Key: kv[0]}
+	if len(kv) == 2 {
+		tag.Value = kv[1]
+	}
+	if err := tag.validate(); err != nil {
+		return StatementTag{}, err
+	}
+	return tag, nil
+}
+
+func (t StatementTag) validate() error {
+	if t.Key == "" {
+		return newError(ErrInvalidTag, "migu: invalid statement tag %q: empty key", t.String())
+	}
+	if strings.ContainsAny(t.Key, "= \t\r\n") || strings.ContainsAny(t.Value, " \t\r\n") {
+		return newError(ErrInvalidTag, "migu: invalid statement tag %q: must not contain whitespace", t.String())
+	}
+	if s := t.String(); strings.Contains(s, "*/") || strings.Contains(s, "/*") {
+		return newError(ErrInvalidTag, "migu: invalid statement tag %q: must not contain comment delimiters", s)
+	}
+	return nil
+}
+
+// TagStatement returns the statement that the comment of tags is prepended.
+// (e.g. "/* migu deploy=2024-05-01 ticket=OPS-123 */ ALTER TABLE ...")
+// If tags is empty, it returns sql as it is.
+func TagStatement(sql string, tags []StatementTag) (string, error) {
+	if len(tags) == 0 {
+		return sql, nil
+	}
+	comment := []string{statementTagPrefix}
+	for _, tag := range tags {
+		if err := tag.validate(); err != nil {
+			return "", err
+		}
+		comment = append(comment, tag.String())
+	}
+	return "/* " + strings.Join(comment, " ") + " */ " + sql, nil
+}
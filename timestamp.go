@@ -0,0 +1,79 @@
+package migu
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/naoina/migu/dialect"
+)
+
+// currentTimestampRE matches CURRENT_TIMESTAMP and its synonyms with the optional fractional seconds precision.
+var currentTimestampRE = regexp.MustCompile(`(?i)^(?:CURRENT_TIMESTAMP|NOW|LOCALTIME|LOCALTIMESTAMP)(?:\(\s*(\d*)\s*\))?$`)
+
+// normalizeCurrentTimestamp returns CURRENT_TIMESTAMP or CURRENT_TIMESTAMP(fsp) that is the same as expr, which is
+// reported by the database, and false unless expr is the current timestamp.
+func normalizeCurrentTimestamp(expr string) (string, bool) {
+	m := currentTimestampRE.FindStringSubmatch(strings.TrimSpace(expr))
+	if m == nil {
+		return "", false
+	}
+	if fsp := strings.TrimLeft(m[1], "0"); fsp != "" {
+		return "CURRENT_TIMESTAMP(" + fsp + ")", true
+	}
+	return "CURRENT_TIMESTAMP", true
+}
+
+func isTimestampType(typ string) bool {
+	switch typeBase(typ) {
+	case "DATETIME", "TIMESTAMP":
+		return true
+	}
+	return false
+}
+
+// normalizeTimestampField validates `on_update` tag of the field, and normalizes the current timestamps of `default`
+// and `on_update` tags so that they are compared with the ones in the database. ON UPDATE clause in `extra` tag is
+// also moved to `on_update` tag for the dialects that support it.
+func normalizeTimestampField(d dialect.Dialect, f *field) error {
+	if _, ok := d.(dialect.OnUpdater); !ok {
+		if f.OnUpdate != "" {
+			return newError(ErrUnsupportedFeature, "migu: %s: `on_update` tag is not supported by the dialect", f.Column)
+		}
+		return nil
+	}
+	if f.OnUpdate == "" {
+		f.OnUpdate, f.Extra = splitOnUpdate(f.Extra)
+	}
+	if f.OnUpdate != "" {
+		expr, ok := normalizeCurrentTimestamp(f.OnUpdate)
+		if !ok {
+			return newError(ErrInvalidTag, "migu: %s: `on_update` tag must be CURRENT_TIMESTAMP: %s", f.Column, f.OnUpdate)
+		}
+		if !isTimestampType(f.Type) {
+			return newError(ErrInvalidTag, "migu: %s: `on_update` tag is specified to the column of non-timestamp type %s", f.Column, f.Type)
+		}
+		f.OnUpdate = expr
+	}
+	if isTimestampType(f.Type) {
+		if expr, ok := normalizeCurrentTimestamp(f.Default); ok {
+			f.Default = expr
+		}
+	}
+	return nil
+}
+
+// splitOnUpdate returns the expression of ON UPDATE clause of the current timestamp in extra, and extra without it.
+// The expression is empty if extra doesn't have such a clause.
+func splitOnUpdate(extra string) (onUpdate, rest string) {
+	fields := strings.Fields(extra)
+	for i := 0; i+2 < len(fields); i++ {
+		if !strings.EqualFold(fields[i], "ON") || !strings.EqualFold(fields[i+1], "UPDATE") {
+			continue
+		}
+		if _, ok := normalizeCurrentTimestamp(fields[i+2]); !ok {
+			break
+		}
+		return fields[i+2], strings.Join(append(fields[:i:i], fields[i+3:]...), " ")
+	}
+	return "", extra
+}
@@ -0,0 +1,141 @@
+package migu
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/naoina/migu/dialect"
+)
+
+// UserChanges returns the changes to create the database users and the roles, and to grant the privileges to them.
+// The roles are created before the users so that they can be granted to the users.
+// The SQLs of the changes are idempotent, and the existing privileges that are not given are not revoked.
+// If the dialect implements dialect.UserReader, the existing users are changed only by the password, the roles and
+// the privileges that they don't have yet, and nothing is returned for the users that have them all.
+// The dialect must implement dialect.UserManager.
+func UserChanges(d dialect.Dialect, users []dialect.User) ([]*Change, error) {
+	if len(users) == 0 {
+		return nil, nil
+	}
+	m, ok := d.(dialect.UserManager)
+	if !ok {
+		return nil, newError(ErrUnsupportedFeature, "migu: managing users is not supported by the dialect")
+	}
+	for _, user := range users {
+		if err := validateUser(user); err != nil {
+			return nil, err
+		}
+	}
+	r, _ := d.(dialect.UserReader)
+	var changes []*Change
+	for _, role := range []bool{true, false} {
+		for _, user := range users {
+			if user.Role != role {
+				continue
+			}
+			sqls, err := userSQLs(m, r, user)
+			if err != nil {
+				return nil, err
+			}
+			if len(sqls) == 0 {
+				continue
+			}
+			changes = append(changes, &Change{
+				Kind: ModifyUser,
+				User: user.Name,
+				SQLs: sqls,
+			})
+		}
+	}
+	return finalizeChanges(d, changes), nil
+}
+
+// userSQLs returns the SQLs to create the user and to grant the privileges, or the ones to change the existing user
+// if r is not nil.
+func userSQLs(m dialect.UserManager, r dialect.UserReader, user dialect.User) ([]string, error) {
+	var state *dialect.UserState
+	if r != nil {
+		var err error
+		if state, err = r.ReadUser(user); err != nil {
+			return nil, fmt.Errorf("migu: user %s: %w", user.Name, err)
+		}
+	}
+	if state == nil {
+		sqls := m.CreateUserSQL(user)
+		for _, grant := range user.Grants {
+			sqls = append(sqls, m.GrantSQL(user, grant)...)
+		}
+		return sqls, nil
+	}
+	var roles []string
+	for _, role := range user.Roles {
+		if !inStrings(state.Roles, role) {
+			roles = append(roles, role)
+		}
+	}
+	var sqls []string
+	if changePassword := !user.Role && !state.PasswordMatches; changePassword || len(roles) > 0 {
+		u := user
+		u.Roles = roles
+		sqls = append(sqls, r.AlterUserSQL(u, changePassword)...)
+	}
+	for _, grant := range user.Grants {
+		var granted []string
+		for _, g := range state.Grants {
+			if g.On == grant.On {
+				granted = g.Privileges
+				break
+			}
+		}
+		var missing []string
+		for _, p := range grant.Privileges {
+			if !inStrings(granted, strings.ToUpper(p)) {
+				missing = append(missing, p)
+			}
+		}
+		if len(missing) > 0 {
+			sqls = append(sqls, m.GrantSQL(user, dialect.Grant{Privileges: missing, On: grant.On})...)
+		}
+	}
+	return sqls, nil
+}
+
+func validateUser(user dialect.User) error {
+	if err := dialect.ValidateIdentifier(user.Name); err != nil {
+		return newError(ErrInvalidIdentifier, "migu: invalid user name: %w", err)
+	}
+	for _, s := range append([]string{user.Host, user.Password}, user.Roles...) {
+		if err := dialect.ValidateLiteral(s); err != nil {
+			return fmt.Errorf("migu: user %s: %w", user.Name, err)
+		}
+	}
+	for _, grant := range user.Grants {
+		if len(grant.Privileges) == 0 {
+			return fmt.Errorf("migu: user %s: grant must specify the privileges", user.Name)
+		}
+		for _, p := range grant.Privileges {
+			if err := validatePrivilege(p); err != nil {
+				return fmt.Errorf("migu: user %s: %w", user.Name, err)
+			}
+		}
+		if grant.On != "*" {
+			if err := dialect.ValidateIdentifier(grant.On); err != nil {
+				return newError(ErrInvalidIdentifier, "migu: user %s: invalid grant target: %w", user.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validatePrivilege returns an error if the privilege cannot be embedded into SQL safely.
+func validatePrivilege(p string) error {
+	if p == "" {
+		return fmt.Errorf("invalid privilege: empty")
+	}
+	for _, c := range p {
+		if !(c == ' ' || c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')) {
+			return fmt.Errorf("invalid privilege: %q", p)
+		}
+	}
+	return nil
+}